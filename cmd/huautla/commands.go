@@ -0,0 +1,1545 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+type (
+	// every command gets its own flagset, so flags like -stage only mean
+	// something where they're declared
+	command struct {
+		args  string
+		help  string
+		flags func(*flag.FlagSet) func(types.DB) runner
+	}
+
+	runner func(ctx context.Context, args []string, cid types.CID) (any, error)
+
+	nouns map[string]map[string]command
+)
+
+var commands = nouns{
+	"lifecycle": {
+		"list": {
+			args: "[-status s,...] [-tag t,...]",
+			help: "list all lifecycles, newest first",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				status := statusFlag(fs)
+				tagged := tagFlag(fs)
+				return func(db types.DB) runner {
+					return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+						result, err := db.SelectLifecycleIndex(tagged(ctx), cid, status()...)
+						return lifecycles(result), err
+					}
+				}
+			},
+		},
+		"show": {
+			args: "<lifecycle-id>",
+			help: "show one lifecycle with its events",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return db.SelectLifecycle(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+		"events": {
+			args: "<lifecycle-id>",
+			help: "list the events for one lifecycle",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.SelectByObservable(ctx, types.UUID(id), cid)
+					return evts(result), err
+				})
+			}),
+		},
+		"forecast": {
+			args: "<lifecycle-id>",
+			help: "estimate colonization, pinning and harvest dates for one lifecycle",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return db.LifecycleForecast(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+	},
+	"generation": {
+		"list": {
+			args: "[-status s,...] [-tag t,...]",
+			help: "list all generations",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				status := statusFlag(fs)
+				tagged := tagFlag(fs)
+				return func(db types.DB) runner {
+					return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+						result, err := db.SelectGenerationIndex(tagged(ctx), cid, status()...)
+						return generations(result), err
+					}
+				}
+			},
+		},
+		"show": {
+			args: "<generation-id>",
+			help: "show one generation with its sources and events",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return db.SelectGeneration(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+		"promote": {
+			args: "[-species s] [-vendor vendor-id] [-inherit agreed|first|strict] [-note text] <generation-id> <name...>",
+			help: "make a strain from a generation, with a note on the generation that says where it came from",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				species := fs.String("species", "", "defaults to the species every parent strain has, if they agree")
+				vendor := fs.String("vendor", "", "defaults to "+string(types.SelfVendor))
+				inherit := fs.String("inherit", "", "which parent attributes to copy when parents disagree: agreed, first or strict; none when it's left out")
+				note := fs.String("note", "", "anything else worth saying about it")
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 2 {
+							return nil, fmt.Errorf("need a generation id and a name")
+						}
+						opts := types.PromoteOptions{
+							Name:    strings.Join(args[1:], " "),
+							Species: *species,
+							Inherit: types.InheritPolicy(*inherit),
+							Note:    *note,
+						}
+						if *vendor != "" {
+							opts.Vendor = (*types.UUID)(vendor)
+						}
+						return db.PromoteGeneration(ctx, types.UUID(args[0]), opts, cid)
+					}
+				}
+			},
+		},
+	},
+	"strain": {
+		"list": {
+			args: "[-tag t,...] [-depth n] [-root strain-id] [-attr name=v|v,name>=n,name<=n,...]",
+			help: "list all strains",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				tagged := tagFlag(fs)
+				filial := filialFlags(fs)
+				attrs := attrFlag(fs)
+				return func(db types.DB) runner {
+					return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+						ctx, err := attrs(filial(tagged(ctx)))
+						if err != nil {
+							return nil, err
+						}
+						result, err := db.SelectAllStrains(ctx, cid)
+						return strains(result), err
+					}
+				}
+			},
+		},
+		"show": {
+			args: "<strain-id>",
+			help: "show one strain with its attributes",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return db.SelectStrain(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+		"ancestry": {
+			args: "[-depth n] <strain-id>",
+			help: "list every strain this one came from, with the generations, sources and lifecycles in between",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				depth := fs.Int("depth", 0, "how many generations back, 0 for all of them")
+				return func(db types.DB) runner {
+					return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+						result, err := db.Ancestry(ctx, types.UUID(id), *depth, cid)
+						return lineage(result), err
+					})
+				}
+			},
+		},
+		"descendants": {
+			args: "[-depth n] <strain-id>",
+			help: "list every strain that came from this one, with the lifecycles, sources and generations in between",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				depth := fs.Int("depth", 0, "how many generations forward, 0 for all of them")
+				return func(db types.DB) runner {
+					return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+						result, err := db.Descendants(ctx, types.UUID(id), *depth, cid)
+						return lineage(result), err
+					})
+				}
+			},
+		},
+		"pedigree": {
+			args: "[-depth n] [-as graphml|dot|mermaid] [strain-id]",
+			help: "list a strain's ancestry and descendants together, or every strain when there's no id, optionally as a graph",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				depth := fs.Int("depth", 0, "how many generations either way, 0 for all of them")
+				as := fs.String("as", "", "graphml, dot or mermaid instead of a table")
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						var result types.Lineage
+						var err error
+						if len(args) > 1 {
+							return nil, fmt.Errorf("expected at most 1 argument, got %d", len(args))
+						} else if len(args) == 1 {
+							result, err = db.Pedigree(ctx, types.UUID(args[0]), *depth, cid)
+						} else {
+							result, err = db.Library(ctx, cid)
+						}
+
+						if err != nil || *as == "" {
+							return lineage(result), err
+						}
+
+						var b strings.Builder
+						err = result.Export(&b, types.LineageFormat(*as))
+						return graph(b.String()), err
+					}
+				}
+			},
+		},
+	},
+	"attribute": {
+		"list": {
+			help: "list every attribute a strain can have, with its type, unit, allowed values and synonyms",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.AttributeDefinitions(ctx, cid)
+					return attributes(result), err
+				}
+			}),
+		},
+		"define": {
+			args: "-type number|enum|boolean|text [-unit u] [-allowed v,...] <name...>",
+			help: "add an attribute strains can have; only numbers have a unit and only enums have allowed values",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				typ := fs.String("type", "", "number, enum, boolean or text")
+				unit := fs.String("unit", "", "what a number is measured in")
+				allowed := fs.String("allowed", "", "every value an enum can have, comma-separated")
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 1 {
+							return nil, fmt.Errorf("need a name")
+						}
+						d := types.AttributeDefinition{
+							Name: strings.Join(args, " "),
+							Type: types.AttributeType(*typ),
+							Unit: *unit,
+						}
+						for _, v := range strings.Split(*allowed, ",") {
+							if v = strings.TrimSpace(v); v != "" {
+								d.Allowed = append(d.Allowed, v)
+							}
+						}
+						result, err := db.AddAttributeDefinition(ctx, d, cid)
+						return attributes{result}, err
+					}
+				}
+			},
+		},
+		"synonym": {
+			args: "<attribute> <synonym...>",
+			help: "let another name stand for an attribute",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) < 2 {
+						return nil, fmt.Errorf("need an attribute and a synonym")
+					}
+					defs, err := db.AttributeDefinitions(ctx, cid)
+					if err != nil {
+						return nil, err
+					}
+					d, ok := types.FindAttribute(defs, args[0])
+					if !ok {
+						return nil, fmt.Errorf("unknown attribute: '%s'", args[0])
+					}
+					err = db.AddAttributeSynonym(ctx, &d, strings.Join(args[1:], " "), cid)
+					return attributes{d}, err
+				}
+			}),
+		},
+		"merge": {
+			args: "<from> <into>",
+			help: "move every strain's value for one attribute to another, and keep the first name as a synonym",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, from, into string, cid types.CID) (any, error) {
+					result, err := db.MergeAttributeNames(ctx, from, into, cid)
+					return attributes{result}, err
+				})
+			}),
+		},
+	},
+	"event": {
+		"add": {
+			args: "[-at when] [-stage name] [-temperature t] [-humidity h] [-measure name=value,...] <lifecycle-id> <event-type-name>",
+			help: "log an event against a lifecycle",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				at := fs.String("at", "", "when it happened, yyyy-mm-dd or RFC3339; defaults to now")
+				stage := fs.String("stage", "", "stage name, when the event type name alone is ambiguous")
+				temperature := fs.Float64("temperature", 0, "temperature when the event happened")
+				humidity := fs.Int("humidity", 0, "relative humidity when the event happened")
+				measure := measureFlag(fs)
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 2 {
+							return nil, fmt.Errorf("need a lifecycle id and an event type name")
+						}
+
+						et, err := eventTypeByName(ctx, db, strings.Join(args[1:], " "), *stage, cid)
+						if err != nil {
+							return nil, err
+						}
+
+						ms, err := measure()
+						if err != nil {
+							return nil, err
+						}
+
+						occurred, err := optTime("at", *at)
+						if err != nil {
+							return nil, err
+						}
+
+						lc, err := db.SelectLifecycle(ctx, types.UUID(args[0]), cid)
+						if err != nil {
+							return nil, err
+						} else if err = db.AddLifecycleEvent(ctx, &lc, types.Event{
+							Temperature:  float32(*temperature),
+							Humidity:     int8(*humidity),
+							EventType:    et,
+							Measurements: ms,
+							OccurredAt:   occurred,
+						}, cid); err != nil {
+							return nil, err
+						}
+
+						// a back-filled event doesn't sort first, but it was recorded last
+						return lastRecorded(lc.Events), nil
+					}
+				}
+			},
+		},
+	},
+	"harvest": {
+		"list": {
+			args: "<lifecycle-id>",
+			help: "list the harvests for one lifecycle, in date order",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					lc := types.Lifecycle{UUID: types.UUID(id)}
+					err := db.GetHarvests(ctx, &lc, cid)
+					return harvests(lc.Harvests), err
+				})
+			}),
+		},
+		"add": {
+			args: "[-date yyyy-mm-dd] [-dry g] [-count n] [-grade g] <lifecycle-id> <fresh-weight>",
+			help: "record a flush; the lifecycle's yield, count and gross are re-tallied",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				date := fs.String("date", "", "when it was picked, defaults to now")
+				dry := fs.Float64("dry", 0, "dry weight, if it's been dried")
+				count := fs.Int("count", 0, "how many fruits")
+				grade := fs.String("grade", "", "quality grade")
+
+				return func(db types.DB) runner {
+					return twoArgs(func(ctx context.Context, id, fresh string, cid types.CID) (any, error) {
+						h := types.Harvest{DryWeight: float32(*dry), Count: int16(*count), Grade: *grade}
+
+						f, err := strconv.ParseFloat(fresh, 32)
+						if err != nil {
+							return nil, fmt.Errorf("fresh weight isn't a number: '%s'", fresh)
+						}
+						h.FreshWeight = float32(f)
+
+						// left alone, AddHarvest defaults it to now
+						if *date != "" {
+							if h.Date, err = time.Parse(time.DateOnly, *date); err != nil {
+								return nil, fmt.Errorf("date isn't yyyy-mm-dd: '%s'", *date)
+							}
+						}
+
+						lc := types.Lifecycle{UUID: types.UUID(id)}
+						if err = db.AddHarvest(ctx, &lc, h, cid); err != nil {
+							return nil, err
+						}
+
+						// lc started out empty, so the only harvest is the new one
+						return lc.Harvests[0], nil
+					})
+				}
+			},
+		},
+		"remove": {
+			args: "<lifecycle-id> <harvest-id>",
+			help: "remove a harvest; the lifecycle's yield, count and gross are re-tallied",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, hid string, cid types.CID) (any, error) {
+					lc := types.Lifecycle{UUID: types.UUID(id)}
+					return nil, db.RemoveHarvest(ctx, &lc, types.UUID(hid), cid)
+				})
+			}),
+		},
+	},
+	"location": {
+		"list": {
+			help: "list all locations",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllLocations(ctx, cid)
+					return locations(result), err
+				}
+			}),
+		},
+		"add": {
+			args: "-kind <kind> [-capacity n] [-min-temperature t] ... <name>",
+			help: "add a location",
+			flags: locationFlags(func(ctx context.Context, db types.DB, args []string, apply func(*types.Location), cid types.CID) (any, error) {
+				if len(args) < 1 {
+					return nil, fmt.Errorf("need a name")
+				}
+				loc := types.Location{Name: strings.Join(args, " ")}
+				apply(&loc)
+				if loc.Kind == "" {
+					return nil, fmt.Errorf("need a -kind")
+				}
+				return db.InsertLocation(ctx, loc, cid)
+			}),
+		},
+		"update": {
+			args: "[-kind <kind>] [-capacity n] [-min-temperature t] ... <location-id> [name]",
+			help: "change a location; anything not given stays the same",
+			flags: locationFlags(func(ctx context.Context, db types.DB, args []string, apply func(*types.Location), cid types.CID) (any, error) {
+				if len(args) < 1 {
+					return nil, fmt.Errorf("need an id")
+				}
+				loc, err := db.SelectLocation(ctx, types.UUID(args[0]), cid)
+				if err != nil {
+					return nil, err
+				} else if len(args) > 1 {
+					loc.Name = strings.Join(args[1:], " ")
+				}
+				apply(&loc)
+				return loc, db.UpdateLocation(ctx, loc.UUID, loc, cid)
+			}),
+		},
+		"delete": {
+			args: "<location-id>",
+			help: "delete a location nothing has been kept in",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.DeleteLocation(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+		"occupancy": {
+			args: "<location-id>",
+			help: "show what's in a location now and how full it is",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.LocationOccupancy(ctx, types.UUID(id), cid)
+					return occupancy(result), err
+				})
+			}),
+		},
+	},
+	"reading": {
+		"ingest": {
+			args: "<file>",
+			help: "store a json array of sensor readings; re-sending a reading overwrites it",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, name string, cid types.CID) (any, error) {
+					b, err := os.ReadFile(name)
+					if err != nil {
+						return nil, err
+					}
+
+					var rs []types.Reading
+					if err = json.Unmarshal(b, &rs); err != nil {
+						return nil, fmt.Errorf("'%s' isn't a json array of readings: %w", name, err)
+					}
+
+					return nil, db.InsertReadings(ctx, rs, cid)
+				})
+			}),
+		},
+		"rollup": {
+			args: "[-resolution hour|day] [-from yyyy-mm-dd] [-to yyyy-mm-dd] <location>",
+			help: "summarize the readings at a location by hour or day",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				res := fs.String("resolution", string(types.HourResolution), "hour or day")
+				window := windowFlags(fs)
+
+				return func(db types.DB) runner {
+					return oneArg(func(ctx context.Context, location string, cid types.CID) (any, error) {
+						w, err := window()
+						if err != nil {
+							return nil, err
+						}
+						result, err := db.ReadingRollups(ctx, location, types.Resolution(*res), w, cid)
+						return rollups(result), err
+					})
+				}
+			},
+		},
+		"lifecycle": {
+			args: "[-resolution hour|day] <lifecycle-id>",
+			help: "summarize the readings for one lifecycle, from when it started until it ended",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				res := fs.String("resolution", string(types.DayResolution), "hour or day")
+
+				return func(db types.DB) runner {
+					return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+						result, err := db.LifecycleReadingRollups(ctx, types.UUID(id), types.Resolution(*res), cid)
+						return rollups(result), err
+					})
+				}
+			},
+		},
+	},
+	"note": {
+		"add": {
+			args: "<id> <text...>",
+			help: "attach a note to a lifecycle, generation, event or photo",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) < 2 {
+						return nil, fmt.Errorf("need an id and some text")
+					}
+					result, err := db.AddNote(ctx,
+						types.UUID(args[0]),
+						nil,
+						types.Note{Note: strings.Join(args[1:], " ")},
+						cid)
+					return notes(result), err
+				}
+			}),
+		},
+		"list": {
+			args: "<id>",
+			help: "list the notes attached to anything notable",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.GetNotes(ctx, types.UUID(id), cid)
+					return notes(result), err
+				})
+			}),
+		},
+	},
+	"tag": {
+		"known": {
+			help: "list every tag that's on something",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.KnownTags(ctx, cid)
+					return tags(result), err
+				}
+			}),
+		},
+		"list": {
+			args: "<id>",
+			help: "list the tags on a lifecycle, generation, strain, substrate, event or photo",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.GetTags(ctx, types.UUID(id), cid)
+					return tags(result), err
+				})
+			}),
+		},
+		"add": {
+			args: "<id> <tag...>",
+			help: "tag a lifecycle, generation, strain, substrate, event or photo",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) < 2 {
+						return nil, fmt.Errorf("need an id and a tag")
+					}
+					if err := db.AddTag(ctx, types.UUID(args[0]), strings.Join(args[1:], " "), cid); err != nil {
+						return nil, err
+					}
+					result, err := db.GetTags(ctx, types.UUID(args[0]), cid)
+					return tags(result), err
+				}
+			}),
+		},
+		"remove": {
+			args: "<id> <tag...>",
+			help: "take a tag off",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) < 2 {
+						return nil, fmt.Errorf("need an id and a tag")
+					}
+					if err := db.RemoveTag(ctx, types.UUID(args[0]), strings.Join(args[1:], " "), cid); err != nil {
+						return nil, err
+					}
+					result, err := db.GetTags(ctx, types.UUID(args[0]), cid)
+					return tags(result), err
+				}
+			}),
+		},
+	},
+	"report": {
+		"lifecycle":  report(func(db types.DB) reporter { return db.LifecycleReport }),
+		"generation": report(func(db types.DB) reporter { return db.GenerationReport }),
+		"strain":     report(func(db types.DB) reporter { return db.StrainReport }),
+		"substrate":  report(func(db types.DB) reporter { return db.SubstrateReport }),
+		"vendor":     report(func(db types.DB) reporter { return db.VendorReport }),
+		"eventtype":  report(func(db types.DB) reporter { return db.EventTypeReport }),
+	},
+	"source": {
+		"types": {
+			help: "list the kinds of source a generation can have, and how many of each",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SourceTypes(ctx, cid)
+					return sourcetypes(result), err
+				}
+			}),
+		},
+	},
+	"stage": {
+		"list": {
+			help: "list all stages",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllStages(ctx, cid)
+					return stages(result), err
+				}
+			}),
+		},
+		"add": {
+			args: "<name>",
+			help: "add a stage",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, name string, cid types.CID) (any, error) {
+					return db.InsertStage(ctx, types.Stage{Name: name}, cid)
+				})
+			}),
+		},
+		"rename": {
+			args: "<stage-id> <name>",
+			help: "rename a stage",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, name string, cid types.CID) (any, error) {
+					s := types.Stage{UUID: types.UUID(id), Name: name}
+					return s, db.UpdateStage(ctx, s.UUID, s, cid)
+				})
+			}),
+		},
+		"delete": {
+			args: "<stage-id>",
+			help: "delete an unused stage",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.DeleteStage(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+	},
+	"eventtype": {
+		"list": {
+			help: "list all event types",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllEventTypes(ctx, cid)
+					return eventtypes(result), err
+				}
+			}),
+		},
+		"add": {
+			args: "-stage <name> -severity <severity> <name>",
+			help: "add an event type",
+			flags: eventTypeFlags(func(ctx context.Context, db types.DB, args []string, et types.EventType, cid types.CID) (any, error) {
+				if len(args) < 1 {
+					return nil, fmt.Errorf("need a name")
+				}
+				et.Name = strings.Join(args, " ")
+				return db.InsertEventType(ctx, et, cid)
+			}),
+		},
+		"update": {
+			args: "-stage <name> -severity <severity> <eventtype-id> <name>",
+			help: "change an event type",
+			flags: eventTypeFlags(func(ctx context.Context, db types.DB, args []string, et types.EventType, cid types.CID) (any, error) {
+				if len(args) < 2 {
+					return nil, fmt.Errorf("need an id and a name")
+				}
+				et.UUID, et.Name = types.UUID(args[0]), strings.Join(args[1:], " ")
+				return et, db.UpdateEventType(ctx, et.UUID, et, cid)
+			}),
+		},
+		"delete": {
+			args: "<eventtype-id>",
+			help: "delete an unused event type",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.DeleteEventType(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+	},
+	"field": {
+		"list": {
+			args: "<eventtype-id>",
+			help: "list what an event of this type can measure",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					et := types.EventType{UUID: types.UUID(id)}
+					err := db.GetFields(ctx, &et, cid)
+					return fields(et.Fields), err
+				})
+			}),
+		},
+		"add": {
+			args: "[-unit u] [-min n] [-max n] <eventtype-id> <name> <number|integer|text|boolean>",
+			help: "add a field that events of this type can record with event add -measure",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				unit := fs.String("unit", "", "what the value is measured in, e.g. mm")
+				lo := fs.String("min", "", "smallest allowed value, for numbers and integers")
+				hi := fs.String("max", "", "largest allowed value, for numbers and integers")
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) != 3 {
+							return nil, fmt.Errorf("need an event type id, a name and a type")
+						}
+
+						f := types.Field{Name: args[1], Type: types.FieldType(args[2]), Unit: *unit}
+
+						var err error
+						if f.Min, err = optFloat("min", *lo); err != nil {
+							return nil, err
+						} else if f.Max, err = optFloat("max", *hi); err != nil {
+							return nil, err
+						}
+
+						et := types.EventType{UUID: types.UUID(args[0])}
+						return db.AddField(ctx, &et, f, cid)
+					}
+				}
+			},
+		},
+		"remove": {
+			args: "<eventtype-id> <field-id>",
+			help: "remove a field and every value recorded for it",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, fid string, cid types.CID) (any, error) {
+					et := types.EventType{UUID: types.UUID(id)}
+					return nil, db.RemoveField(ctx, &et, types.UUID(fid), cid)
+				})
+			}),
+		},
+	},
+	"followup": {
+		"list": {
+			args: "<eventtype-id>",
+			help: "list what to check on after an event of this type",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					et := types.EventType{UUID: types.UUID(id)}
+					err := db.GetFollowUps(ctx, &et, cid)
+					return followups(et.FollowUps), err
+				})
+			}),
+		},
+		"add": {
+			args: "-days n [-expects <eventtype-id>] <eventtype-id> <name>",
+			help: "add a follow-up; every later event of this type creates a task for it",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				days := fs.Int("days", 0, "how many days after the event it's due")
+				expects := fs.String("expects", "", "the event type that usually records the outcome")
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 2 {
+							return nil, fmt.Errorf("need an event type id and a name")
+						}
+
+						f := types.FollowUp{Name: strings.Join(args[1:], " "), Days: *days}
+						if *expects != "" {
+							f.Expects = &types.EventType{UUID: types.UUID(*expects)}
+						}
+
+						et := types.EventType{UUID: types.UUID(args[0])}
+						return db.AddFollowUp(ctx, &et, f, cid)
+					}
+				}
+			},
+		},
+		"remove": {
+			args: "<eventtype-id> <followup-id>",
+			help: "remove a follow-up and every task it created",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, fid string, cid types.CID) (any, error) {
+					et := types.EventType{UUID: types.UUID(id)}
+					return nil, db.RemoveFollowUp(ctx, &et, types.UUID(fid), cid)
+				})
+			}),
+		},
+	},
+	"task": {
+		"due": {
+			args: "[-by yyyy-mm-dd]",
+			help: "list open tasks for active lifecycles and generations, due within a day unless -by says otherwise",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				by := fs.String("by", "", "everything due before this date")
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+						until, err := optDate("by", *by)
+						if err != nil {
+							return nil, err
+						} else if until == nil {
+							t := time.Now().Add(24 * time.Hour)
+							until = &t
+						}
+						result, err := db.DueTasks(ctx, *until, cid)
+						return tasks(result), err
+					}
+				}
+			},
+		},
+		"list": {
+			args: "<lifecycle-or-generation-id>",
+			help: "list every task for one lifecycle or generation, done or not",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.ObservableTasks(ctx, types.UUID(id), cid)
+					return tasks(result), err
+				})
+			}),
+		},
+		"done": {
+			args: "[-record] [-event <event-type-name>] [-stage name] [-temperature t] [-humidity h] <task-id>",
+			help: "mark a task done, optionally recording the event it expects (or -event) at the same time",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				record := fs.Bool("record", false, "record an event too")
+				event := fs.String("event", "", "event type name to record instead of the one the task expects; implies -record")
+				stage := fs.String("stage", "", "stage name, when the event type name alone is ambiguous")
+				temperature := fs.Float64("temperature", 0, "temperature when the event happened")
+				humidity := fs.Int("humidity", 0, "relative humidity when the event happened")
+
+				return func(db types.DB) runner {
+					return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+						var e *types.Event
+						if *record || *event != "" {
+							e = &types.Event{Temperature: float32(*temperature), Humidity: int8(*humidity)}
+						}
+						if *event != "" {
+							et, err := eventTypeByName(ctx, db, *event, *stage, cid)
+							if err != nil {
+								return nil, err
+							}
+							e.EventType = et
+						}
+						t, err := db.CompleteTask(ctx, types.UUID(id), e, cid)
+						return tasks{t}, err
+					})
+				}
+			},
+		},
+	},
+	"protocol": {
+		"list": {
+			help: "list all protocols",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllProtocols(ctx, cid)
+					return protocols(result), err
+				}
+			}),
+		},
+		"show": {
+			args: "<protocol-id>",
+			help: "list a protocol's steps in day order",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.SelectProtocol(ctx, types.UUID(id), cid)
+					return steps(result.Steps), err
+				})
+			}),
+		},
+		"add": {
+			args: "<name>",
+			help: "add a protocol with no steps",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) < 1 {
+						return nil, fmt.Errorf("need a name")
+					}
+					return db.InsertProtocol(ctx, types.Protocol{Name: strings.Join(args, " ")}, cid)
+				}
+			}),
+		},
+		"delete": {
+			args: "<protocol-id>",
+			help: "delete a protocol, and its steps, that nothing follows",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.DeleteProtocol(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+		"step-add": {
+			args: "-day n [-tolerance n] [-stage name] <protocol-id> <event-type-name>",
+			help: "add a step, due -day days after the protocol's first step",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				day := fs.Int("day", 0, "days after the first step")
+				tolerance := fs.Int("tolerance", 0, "days either side of -day that still count as on time")
+				stage := fs.String("stage", "", "stage name, when the event type name alone is ambiguous")
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 2 {
+							return nil, fmt.Errorf("need a protocol id and an event type name")
+						}
+
+						et, err := eventTypeByName(ctx, db, strings.Join(args[1:], " "), *stage, cid)
+						if err != nil {
+							return nil, err
+						}
+
+						p := types.Protocol{UUID: types.UUID(args[0])}
+						return db.AddProtocolStep(ctx, &p, types.ProtocolStep{EventType: et, Day: *day, Tolerance: *tolerance}, cid)
+					}
+				}
+			},
+		},
+		"step-remove": {
+			args: "<protocol-id> <step-id>",
+			help: "remove a step",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, sid string, cid types.CID) (any, error) {
+					p := types.Protocol{UUID: types.UUID(id)}
+					return nil, db.RemoveProtocolStep(ctx, &p, types.UUID(sid), cid)
+				})
+			}),
+		},
+		"assign": {
+			args: "<lifecycle-or-generation-id> <protocol-id>",
+			help: "have a lifecycle or generation follow a protocol instead of whatever it followed before",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, pid string, cid types.CID) (any, error) {
+					p := types.UUID(pid)
+					return nil, db.AssignProtocol(ctx, types.UUID(id), &p, cid)
+				})
+			}),
+		},
+		"unassign": {
+			args: "<lifecycle-or-generation-id>",
+			help: "stop a lifecycle or generation following its protocol",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.AssignProtocol(ctx, types.UUID(id), nil, cid)
+				})
+			}),
+		},
+		"deviations": {
+			args: "<lifecycle-or-generation-id>",
+			help: "compare a lifecycle's or generation's events to its protocol",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.ProtocolDeviations(ctx, types.UUID(id), cid)
+					return deviations(result), err
+				})
+			}),
+		},
+	},
+	"batch": {
+		"list": {
+			help: "list all batches, newest first",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllBatches(ctx, cid)
+					return batches(result), err
+				}
+			}),
+		},
+		"show": {
+			args: "<batch-id>",
+			help: "list a batch's members and whether they're still in step",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.SelectBatch(ctx, types.UUID(id), cid)
+					return members(result.Members), err
+				})
+			}),
+		},
+		"create": {
+			args: "-count n <template-lifecycle-id> <name>",
+			help: "start -count lifecycles like the template, with the same location, strain, substrates and costs",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				count := fs.Int("count", 0, "how many lifecycles to start")
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 2 {
+							return nil, fmt.Errorf("need a template lifecycle id and a name")
+						}
+
+						template, err := db.SelectLifecycle(ctx, types.UUID(args[0]), cid)
+						if err != nil {
+							return nil, err
+						}
+
+						result, err := db.CreateBatch(ctx, strings.Join(args[1:], " "), template, *count, cid)
+						return members(result.Members), err
+					}
+				}
+			},
+		},
+		"event": {
+			args: "[-stage name] [-temperature t] [-humidity h] <batch-id> <event-type-name>",
+			help: "log an event against every member that's still in step",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				stage := fs.String("stage", "", "stage name, when the event type name alone is ambiguous")
+				temperature := fs.Float64("temperature", 0, "temperature when the event happened")
+				humidity := fs.Int("humidity", 0, "relative humidity when the event happened")
+
+				return func(db types.DB) runner {
+					return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+						if len(args) < 2 {
+							return nil, fmt.Errorf("need a batch id and an event type name")
+						}
+
+						et, err := eventTypeByName(ctx, db, strings.Join(args[1:], " "), *stage, cid)
+						if err != nil {
+							return nil, err
+						}
+
+						b := types.Batch{UUID: types.UUID(args[0])}
+						err = db.AddBatchEvent(ctx, &b, types.Event{
+							Temperature: float32(*temperature),
+							Humidity:    int8(*humidity),
+							EventType:   et,
+						}, cid)
+						return members(b.Members), err
+					}
+				}
+			},
+		},
+		"note": {
+			args: "<batch-id> <text...>",
+			help: "attach a note to every member that's still in step",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) < 2 {
+						return nil, fmt.Errorf("need a batch id and some text")
+					}
+					b := types.Batch{UUID: types.UUID(args[0])}
+					err := db.AddBatchNote(ctx, &b, types.Note{Note: strings.Join(args[1:], " ")}, cid)
+					return members(b.Members), err
+				}
+			}),
+		},
+		"photo": {
+			args: "<batch-id> <filename>",
+			help: "attach a photo to the latest event of every member that's still in step",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, filename string, cid types.CID) (any, error) {
+					b := types.Batch{UUID: types.UUID(id)}
+					err := db.AddBatchPhoto(ctx, &b, types.Photo{Filename: filename}, cid)
+					return members(b.Members), err
+				})
+			}),
+		},
+		"exclude": {
+			args: "<batch-id> <lifecycle-id>",
+			help: "leave a member out of batch-wide events, notes and photos from now on",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, lcid string, cid types.CID) (any, error) {
+					b := types.Batch{UUID: types.UUID(id)}
+					return nil, db.ExcludeBatchMember(ctx, &b, types.UUID(lcid), cid)
+				})
+			}),
+		},
+	},
+	"experiment": {
+		"list": {
+			help: "list all experiments",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllExperiments(ctx, cid)
+					return experiments(result), err
+				}
+			}),
+		},
+		"show": {
+			args: "<experiment-id>",
+			help: "list an experiment's arms and their lifecycles, control first",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.SelectExperiment(ctx, types.UUID(id), cid)
+					return arms(result.Arms), err
+				})
+			}),
+		},
+		"add": {
+			args: "<name> <variable>",
+			help: "add an experiment with no arms; variable is whatever the arms differ by",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, name, variable string, cid types.CID) (any, error) {
+					return db.InsertExperiment(ctx, types.Experiment{Name: name, Variable: variable}, cid)
+				})
+			}),
+		},
+		"delete": {
+			args: "<experiment-id>",
+			help: "delete an experiment and its arms, but not their lifecycles",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.DeleteExperiment(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+		"arm-add": {
+			args: "[-control] <experiment-id> <name>",
+			help: "add an arm; there can only be one control",
+			flags: func(fs *flag.FlagSet) func(types.DB) runner {
+				control := fs.Bool("control", false, "everything else is compared to this arm")
+
+				return func(db types.DB) runner {
+					return twoArgs(func(ctx context.Context, id, name string, cid types.CID) (any, error) {
+						e := types.Experiment{UUID: types.UUID(id)}
+						return db.AddExperimentArm(ctx, &e, types.ExperimentArm{Name: name, Control: *control}, cid)
+					})
+				}
+			},
+		},
+		"arm-remove": {
+			args: "<experiment-id> <arm-id>",
+			help: "remove an arm, which takes its lifecycles out of the experiment",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, aid string, cid types.CID) (any, error) {
+					e := types.Experiment{UUID: types.UUID(id)}
+					return nil, db.RemoveExperimentArm(ctx, &e, types.UUID(aid), cid)
+				})
+			}),
+		},
+		"assign": {
+			args: "<experiment-id> <arm-id> <lifecycle-id>",
+			help: "put a lifecycle in an arm, moving it out of any other arm of the same experiment",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+					if len(args) != 3 {
+						return nil, fmt.Errorf("need an experiment id, an arm id and a lifecycle id")
+					}
+					e := types.Experiment{UUID: types.UUID(args[0])}
+					return nil, db.AssignExperimentLifecycle(ctx, &e, types.UUID(args[1]), types.UUID(args[2]), cid)
+				}
+			}),
+		},
+		"unassign": {
+			args: "<experiment-id> <lifecycle-id>",
+			help: "take a lifecycle out of the experiment",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, lcid string, cid types.CID) (any, error) {
+					e := types.Experiment{UUID: types.UUID(id)}
+					return nil, db.UnassignExperimentLifecycle(ctx, &e, types.UUID(lcid), cid)
+				})
+			}),
+		},
+		"report": {
+			args: "<experiment-id>",
+			help: "compare yield, colonization days and contamination of every arm to the control",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					result, err := db.ExperimentReport(ctx, types.UUID(id), cid)
+					return expreport(result), err
+				})
+			}),
+		},
+	},
+	"ingredient": {
+		"list": {
+			help: "list all ingredients",
+			flags: noflags(func(db types.DB) runner {
+				return func(ctx context.Context, _ []string, cid types.CID) (any, error) {
+					result, err := db.SelectAllIngredients(ctx, cid)
+					return ingredients(result), err
+				}
+			}),
+		},
+		"add": {
+			args: "<name>",
+			help: "add an ingredient",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, name string, cid types.CID) (any, error) {
+					return db.InsertIngredient(ctx, types.Ingredient{Name: name}, cid)
+				})
+			}),
+		},
+		"rename": {
+			args: "<ingredient-id> <name>",
+			help: "rename an ingredient",
+			flags: noflags(func(db types.DB) runner {
+				return twoArgs(func(ctx context.Context, id, name string, cid types.CID) (any, error) {
+					i := types.Ingredient{UUID: types.UUID(id), Name: name}
+					return i, db.UpdateIngredient(ctx, i.UUID, i, cid)
+				})
+			}),
+		},
+		"delete": {
+			args: "<ingredient-id>",
+			help: "delete an unused ingredient",
+			flags: noflags(func(db types.DB) runner {
+				return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+					return nil, db.DeleteIngredient(ctx, types.UUID(id), cid)
+				})
+			}),
+		},
+	},
+}
+
+type reporter func(context.Context, types.UUID, types.CID) (types.Entity, error)
+
+func report(fn func(types.DB) reporter) command {
+	return command{
+		args: "<id>",
+		help: "print the full report",
+		flags: noflags(func(db types.DB) runner {
+			return oneArg(func(ctx context.Context, id string, cid types.CID) (any, error) {
+				return fn(db)(ctx, types.UUID(id), cid)
+			})
+		}),
+	}
+}
+
+func noflags(fn func(types.DB) runner) func(*flag.FlagSet) func(types.DB) runner {
+	return func(*flag.FlagSet) func(types.DB) runner { return fn }
+}
+
+// statusFlag takes a comma-separated list, so one flag can ask for e.g. every
+// lifecycle that's finished one way or another
+func statusFlag(fs *flag.FlagSet) func() []types.Status {
+	status := fs.String("status", "", "only these statuses: pending, active, contaminated, harvested or dead")
+	return func() []types.Status {
+		var result []types.Status
+		for _, s := range strings.Split(*status, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				result = append(result, types.Status(s))
+			}
+		}
+		return result
+	}
+}
+
+// filialFlags only filters by depth or root when they're given
+func filialFlags(fs *flag.FlagSet) func(context.Context) context.Context {
+	depth := fs.Int("depth", -1, "only strains this many sources from their vendor original, 0 for the originals")
+	root := fs.String("root", "", "only strains that came from this vendor original, including itself")
+	return func(ctx context.Context) context.Context {
+		var result types.FilialFilter
+		if *depth >= 0 {
+			result.Depth = depth
+		}
+		if *root != "" {
+			result.Root = (*types.UUID)(root)
+		}
+		return types.WithFilial(ctx, result)
+	}
+}
+
+// tagFlag only filters by tags when it's given
+func tagFlag(fs *flag.FlagSet) func(context.Context) context.Context {
+	tag := fs.String("tag", "", "only the ones with every one of these tags")
+	return func(ctx context.Context) context.Context {
+		var result []string
+		for _, t := range strings.Split(*tag, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				result = append(result, t)
+			}
+		}
+		if len(result) == 0 {
+			return ctx
+		}
+		return types.WithTags(ctx, result...)
+	}
+}
+
+// attrFlag only filters by attributes when it's given
+func attrFlag(fs *flag.FlagSet) func(context.Context) (context.Context, error) {
+	attr := fs.String("attr", "", "only strains with every one of these attributes, e.g. color=purple|white,headroom>=20")
+	return func(ctx context.Context) (context.Context, error) {
+		var result []types.AttributeFilter
+		for _, term := range strings.Split(*attr, ",") {
+			if term = strings.TrimSpace(term); term == "" {
+				continue
+			}
+
+			f, err := attrFilter(term)
+			if err != nil {
+				return ctx, err
+			}
+			result = append(result, f)
+		}
+		if len(result) == 0 {
+			return ctx, nil
+		}
+		return types.WithAttributes(ctx, result...), nil
+	}
+}
+
+// attrFilter is one term of -attr: name=value, where value can be several
+// values separated by |, or name>=n or name<=n for a range
+func attrFilter(term string) (types.AttributeFilter, error) {
+	for _, op := range []string{">=", "<="} {
+		k, v, ok := strings.Cut(term, op)
+		if !ok {
+			continue
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return types.AttributeFilter{}, fmt.Errorf("%s isn't a number: '%s'", strings.TrimSpace(k), strings.TrimSpace(v))
+		} else if op == ">=" {
+			return types.AttributeFilter{Name: strings.TrimSpace(k), Min: &x}, nil
+		}
+		return types.AttributeFilter{Name: strings.TrimSpace(k), Max: &x}, nil
+	}
+
+	k, v, ok := strings.Cut(term, "=")
+	if !ok {
+		return types.AttributeFilter{}, fmt.Errorf("attribute filter isn't name=value, name>=n or name<=n: '%s'", term)
+	}
+	return types.AttributeFilter{Name: strings.TrimSpace(k), Values: strings.Split(v, "|")}, nil
+}
+
+// measureFlag leaves every value as a string; the event type's fields decide
+// what it's parsed into
+func measureFlag(fs *flag.FlagSet) func() ([]types.Measurement, error) {
+	measure := fs.String("measure", "", "values for the event type's fields, e.g. diameter=12.5,color=white")
+	return func() ([]types.Measurement, error) {
+		var result []types.Measurement
+		for _, kv := range strings.Split(*measure, ",") {
+			if kv = strings.TrimSpace(kv); kv == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("measurement isn't name=value: '%s'", kv)
+			}
+			result = append(result, types.Measurement{Field: strings.TrimSpace(k), Value: strings.TrimSpace(v)})
+		}
+		return result, nil
+	}
+}
+
+// windowFlags leaves either end open when it isn't given
+func windowFlags(fs *flag.FlagSet) func() (types.Window, error) {
+	from := fs.String("from", "", "earliest date, inclusive")
+	to := fs.String("to", "", "latest date, exclusive")
+
+	return func() (types.Window, error) {
+		var err error
+		var result types.Window
+		if result.From, err = optDate("from", *from); err != nil {
+			return result, err
+		}
+		result.To, err = optDate("to", *to)
+		return result, err
+	}
+}
+
+func optDate(name, s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, fmt.Errorf("%s isn't yyyy-mm-dd: '%s'", name, s)
+	}
+	return &t, nil
+}
+
+// optTime takes a whole timestamp or just a date; the zero time is for
+// leaving it to the database
+func optTime(name, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	} else if t, err = time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s isn't yyyy-mm-dd or RFC3339: '%s'", name, s)
+}
+
+func lastRecorded(events []types.Event) types.Event {
+	result := events[0]
+	for _, e := range events[1:] {
+		if e.CTime.After(result.CTime) {
+			result = e
+		}
+	}
+	return result
+}
+
+func optFloat(name, s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s isn't a number: '%s'", name, s)
+	}
+	return &f, nil
+}
+
+func oneArg(fn func(context.Context, string, types.CID) (any, error)) runner {
+	return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected exactly 1 argument, got %d", len(args))
+		}
+		return fn(ctx, args[0], cid)
+	}
+}
+
+func twoArgs(fn func(context.Context, string, string, types.CID) (any, error)) runner {
+	return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+		}
+		return fn(ctx, args[0], args[1], cid)
+	}
+}
+
+func eventTypeFlags(fn func(context.Context, types.DB, []string, types.EventType, types.CID) (any, error)) func(*flag.FlagSet) func(types.DB) runner {
+	return func(fs *flag.FlagSet) func(types.DB) runner {
+		stage := fs.String("stage", "", "stage name (required)")
+		severity := fs.String("severity", "Info", "one of Begin, Info, Warn, Error, Fatal, RIP or Generation")
+
+		return func(db types.DB) runner {
+			return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+				s, err := stageByName(ctx, db, *stage, cid)
+				if err != nil {
+					return nil, err
+				}
+				return fn(ctx, db, args, types.EventType{Severity: *severity, Stage: s}, cid)
+			}
+		}
+	}
+}
+
+// locationFlags hands fn something that only applies the flags that were
+// actually given, so update can leave the rest of the location alone
+func locationFlags(fn func(context.Context, types.DB, []string, func(*types.Location), types.CID) (any, error)) func(*flag.FlagSet) func(types.DB) runner {
+	targets := map[string]func(*types.Environment) **float32{
+		"min-temperature": func(e *types.Environment) **float32 { return &e.MinTemperature },
+		"max-temperature": func(e *types.Environment) **float32 { return &e.MaxTemperature },
+		"min-humidity":    func(e *types.Environment) **float32 { return &e.MinHumidity },
+		"max-humidity":    func(e *types.Environment) **float32 { return &e.MaxHumidity },
+		"max-co2":         func(e *types.Environment) **float32 { return &e.MaxCO2 },
+	}
+
+	return func(fs *flag.FlagSet) func(types.DB) runner {
+		kind := fs.String("kind", "", "incubator, fruiting chamber or outdoor bed")
+		capacity := fs.Int("capacity", 0, "how many lifecycles fit at once, 0 for no limit")
+		values := map[string]*float64{}
+		for name := range targets {
+			values[name] = fs.Float64(name, 0, "target "+strings.Replace(name, "-", " ", 1))
+		}
+
+		apply := func(loc *types.Location) {
+			fs.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "kind":
+					loc.Kind = types.LocationKind(*kind)
+				case "capacity":
+					loc.Capacity = *capacity
+				default:
+					if target, ok := targets[f.Name]; ok {
+						v := float32(*values[f.Name])
+						*target(&loc.Target) = &v
+					}
+				}
+			})
+		}
+
+		return func(db types.DB) runner {
+			return func(ctx context.Context, args []string, cid types.CID) (any, error) {
+				return fn(ctx, db, args, apply, cid)
+			}
+		}
+	}
+}
+
+func stageByName(ctx context.Context, db types.DB, name string, cid types.CID) (types.Stage, error) {
+	all, err := db.SelectAllStages(ctx, cid)
+	if err != nil {
+		return types.Stage{}, err
+	}
+
+	for _, s := range all {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+	}
+
+	return types.Stage{}, fmt.Errorf("no stage named '%s'", name)
+}
+
+// eventTypeByName finds an event type by name; names are only unique within
+// a stage (there's a 'Mold' in more than one), so the stage breaks ties
+func eventTypeByName(ctx context.Context, db types.DB, name, stage string, cid types.CID) (types.EventType, error) {
+	all, err := db.SelectAllEventTypes(ctx, cid)
+	if err != nil {
+		return types.EventType{}, err
+	}
+
+	found := []types.EventType{}
+	for _, et := range all {
+		if !strings.EqualFold(et.Name, name) {
+			continue
+		} else if stage != "" && !strings.EqualFold(et.Stage.Name, stage) {
+			continue
+		}
+		found = append(found, et)
+	}
+
+	if len(found) == 1 {
+		return found[0], nil
+	} else if len(found) == 0 {
+		return types.EventType{}, fmt.Errorf("no event type named '%s'", name)
+	}
+
+	candidates := make([]string, len(found))
+	for i, et := range found {
+		candidates[i] = et.Stage.Name
+	}
+
+	return types.EventType{}, fmt.Errorf("'%s' is ambiguous, use -stage with one of: %s", name, strings.Join(candidates, ", "))
+}
+
+func (n nouns) usage(w io.Writer) {
+	names := make([]string, 0, len(n))
+	for k := range n {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, noun := range names {
+		verbs := make([]string, 0, len(n[noun]))
+		for k := range n[noun] {
+			verbs = append(verbs, k)
+		}
+		sort.Strings(verbs)
+
+		for _, verb := range verbs {
+			cmd := n[noun][verb]
+			fmt.Fprintf(w, "  %s %s %s\n      %s\n", noun, verb, cmd.args, cmd.help)
+		}
+	}
+}
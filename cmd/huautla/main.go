@@ -0,0 +1,126 @@
+// Command huautla is a thin admin client for the huautla database, for the
+// times when the web UI is more trouble than it's worth (e.g. over ssh)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/jsmit257/huautla"
+	"github.com/jsmit257/huautla/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type connector func(*types.Config, *log.Entry) (types.DB, error)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, huautla.New))
+}
+
+func run(args []string, stdout, stderr io.Writer, connect connector) int {
+	fs := flag.NewFlagSet("huautla", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	// same variables the system tests use; the password is only ever read
+	// from the environment so it doesn't end up in shell history
+	host := fs.String("host", os.Getenv("POSTGRES_HOST"), "postgres host ($POSTGRES_HOST)")
+	port := fs.Uint("port", envUint("POSTGRES_PORT", 5432), "postgres port ($POSTGRES_PORT)")
+	user := fs.String("user", envString("POSTGRES_USER", "postgres"), "postgres user ($POSTGRES_USER)")
+	ssl := fs.String("sslmode", envString("POSTGRES_SSLMODE", "disable"), "postgres sslmode ($POSTGRES_SSLMODE)")
+	transitions := fs.String("transitions", envString("HUAUTLA_TRANSITIONS", string(types.StrictTransitions)),
+		"what to do with an event that's out of order, one of 'strict', 'lenient' or 'ignore' ($HUAUTLA_TRANSITIONS)")
+	units := fs.String("units", envString("HUAUTLA_UNITS", string(types.MetricUnits)),
+		"what temperatures and weights are read and written in, one of 'metric' or 'imperial' ($HUAUTLA_UNITS)")
+	format := fs.String("format", tableFormat, "output format, one of 'table' or 'json'")
+	verbose := fs.Bool("v", false, "log every database call to stderr")
+
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: huautla [flags] <noun> <verb> [args...]\n\nflags:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(stderr, "\ncommands:\n")
+		commands.usage(stderr)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	} else if fs.NArg() < 2 {
+		fs.Usage()
+		return 2
+	}
+
+	noun, verb := fs.Arg(0), fs.Arg(1)
+	cmd, ok := commands[noun][verb]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: '%s %s'\n", noun, verb)
+		fs.Usage()
+		return 2
+	}
+
+	cmdfs := flag.NewFlagSet(noun+" "+verb, flag.ContinueOnError)
+	cmdfs.SetOutput(stderr)
+	bind := cmd.flags(cmdfs)
+	if err := cmdfs.Parse(fs.Args()[2:]); err != nil {
+		return 2
+	}
+
+	logger := log.New()
+	logger.SetOutput(stderr)
+	logger.SetLevel(log.WarnLevel)
+	if *verbose {
+		logger.SetLevel(log.InfoLevel)
+	}
+
+	cid := types.CID(uuid.New().String())
+	l := logger.WithField("cid", cid)
+
+	db, err := connect(&types.Config{
+		PGHost:      *host,
+		PGUser:      *user,
+		PGPass:      os.Getenv("POSTGRES_PASSWORD"),
+		PGPort:      *port,
+		PGSSL:       *ssl,
+		Transitions: types.TransitionPolicy(*transitions),
+		Units:       types.UnitSystem(*units),
+	}, l)
+	if err != nil {
+		fmt.Fprintf(stderr, "connecting: %v\n", err)
+		return 1
+	}
+
+	ctx := context.WithValue(context.Background(), types.Cid, cid)
+	ctx = context.WithValue(ctx, types.Log, l)
+
+	result, err := bind(db)(ctx, cmdfs.Args(), cid)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s %s: %v\n", noun, verb, err)
+		return 1
+	} else if result == nil {
+		return 0
+	} else if err = emit(stdout, *format, result); err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func envString(name, dflt string) string {
+	if result, ok := os.LookupEnv(name); ok {
+		return result
+	}
+	return dflt
+}
+
+func envUint(name string, dflt uint) uint {
+	if result, err := strconv.ParseUint(os.Getenv(name), 10, 32); err == nil {
+		return uint(result)
+	}
+	return dflt
+}
@@ -0,0 +1,763 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type fakeDB struct {
+	types.DB
+	added *types.Event
+}
+
+var _ets = []types.EventType{
+	{UUID: "3", Name: "Mold", Severity: "Error", Stage: types.Stage{UUID: "4", Name: "Any"}},
+	{UUID: "24", Name: "Mold", Severity: "Fatal", Stage: types.Stage{UUID: "3", Name: "Vacation"}},
+	{UUID: "15", Name: "Pinning", Severity: "Info", Stage: types.Stage{UUID: "2", Name: "Majority"}},
+}
+
+func (db *fakeDB) SelectAllStages(context.Context, types.CID) ([]types.Stage, error) {
+	return []types.Stage{{UUID: "0", Name: "Gestation"}}, nil
+}
+
+func (db *fakeDB) SourceTypes(context.Context, types.CID) ([]types.SourceType, error) {
+	return []types.SourceType{
+		{Name: types.CloneSource, Max: 1, Clonal: true},
+		{Name: types.SporeSource, Max: 2},
+	}, nil
+}
+
+func (db *fakeDB) AttributeDefinitions(context.Context, types.CID) ([]types.AttributeDefinition, error) {
+	return []types.AttributeDefinition{
+		{UUID: "ad0", Name: "color", Type: types.EnumAttribute, Allowed: []string{"purple", "white"}},
+		{UUID: "ad1", Name: "headroom", Type: types.NumberAttribute, Unit: "cm"},
+	}, nil
+}
+
+func (db *fakeDB) AddAttributeDefinition(_ context.Context, d types.AttributeDefinition, _ types.CID) (types.AttributeDefinition, error) {
+	d.UUID = "ad2"
+	return d, types.CheckAttributeDefinition(d)
+}
+
+func (db *fakeDB) AddAttributeSynonym(_ context.Context, d *types.AttributeDefinition, synonym string, _ types.CID) error {
+	d.Synonyms = append(d.Synonyms, synonym)
+	return nil
+}
+
+func (db *fakeDB) MergeAttributeNames(_ context.Context, from, into string, _ types.CID) (types.AttributeDefinition, error) {
+	return types.AttributeDefinition{UUID: "ad0", Name: into, Type: types.TextAttribute, Synonyms: []string{from}}, nil
+}
+
+func (db *fakeDB) PromoteGeneration(_ context.Context, id types.UUID, opts types.PromoteOptions, _ types.CID) (types.Strain, error) {
+	if _, err := types.InheritAttributes(nil, opts.Inherit); err != nil {
+		return types.Strain{}, err
+	}
+	result, err := opts.Strain(nil)
+	result.UUID = types.UUID("s9 from " + id)
+	return result, err
+}
+
+func (db *fakeDB) SelectAllEventTypes(context.Context, types.CID) ([]types.EventType, error) {
+	return _ets, nil
+}
+
+func (db *fakeDB) SelectLifecycle(_ context.Context, id types.UUID, _ types.CID) (types.Lifecycle, error) {
+	if id == "missing" {
+		return types.Lifecycle{}, fmt.Errorf("sql: no rows in result set")
+	}
+	return types.Lifecycle{UUID: id}, nil
+}
+
+// Ancestry only goes back one generation unless depth says otherwise
+func (db *fakeDB) Ancestry(_ context.Context, id types.UUID, depth int, _ types.CID) (types.Lineage, error) {
+	if depth != 1 {
+		return types.Lineage{}, fmt.Errorf("unexpected depth: %d", depth)
+	}
+	return types.Lineage{
+		Root: "strain#" + string(id),
+		Nodes: []types.LineageNode{
+			{ID: "strain#" + string(id), Kind: types.StrainNode, UUID: id, Label: "child"},
+			{ID: "generation#g0", Kind: types.GenerationNode, UUID: "g0", Label: "g0", Depth: 1},
+			{ID: "source#src0", Kind: types.SourceNode, UUID: "src0", Label: "Clone", Depth: 1},
+			{ID: "strain#s0", Kind: types.StrainNode, UUID: "s0", Label: "parent", Depth: 1},
+		},
+		Edges: []types.LineageEdge{
+			{From: "strain#s0", To: "source#src0"},
+			{From: "source#src0", To: "generation#g0"},
+			{From: "generation#g0", To: "strain#" + string(id)},
+		},
+	}, nil
+}
+
+func (db *fakeDB) Pedigree(ctx context.Context, id types.UUID, depth int, cid types.CID) (types.Lineage, error) {
+	return db.Ancestry(ctx, id, depth, cid)
+}
+
+// Library is one strain nothing came from
+func (db *fakeDB) Library(_ context.Context, _ types.CID) (types.Lineage, error) {
+	return types.Lineage{
+		Nodes: []types.LineageNode{{ID: "strain#s9", Kind: types.StrainNode, UUID: "s9", Label: "loner", Species: "P. cubensis"}},
+		Edges: []types.LineageEdge{},
+	}, nil
+}
+
+// SelectAllStrains is one F2 with a headroom of 25 and a purple color, as
+// long as the filters allow it
+func (db *fakeDB) SelectAllStrains(ctx context.Context, _ types.CID) ([]types.Strain, error) {
+	root := types.UUID("s0")
+	f := types.GetContextFilial(ctx)
+	if (f.Depth != nil && *f.Depth != 2) || (f.Root != nil && *f.Root != root) {
+		return []types.Strain{}, nil
+	}
+	attrs := map[string]string{"headroom": "25", "color": "purple"}
+	for _, a := range types.GetContextAttributes(ctx) {
+		matched := len(a.Values) == 0
+		for _, v := range a.Values {
+			matched = matched || v == attrs[a.Name]
+		}
+		if !matched || (a.Min != nil && *a.Min > 25) || (a.Max != nil && *a.Max < 25) {
+			return []types.Strain{}, nil
+		}
+	}
+	return []types.Strain{{
+		UUID:       "s2",
+		Name:       "grandchild",
+		Species:    "P. cubensis",
+		Vendor:     types.Vendor{Name: "vendor 0"},
+		Generation: &types.Generation{UUID: "g1"},
+		Filiation:  &types.Filiation{Depth: 2, Notation: "F2", Root: &root},
+		CTime:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+// only lc0 is a keeper
+func (db *fakeDB) SelectLifecycleIndex(ctx context.Context, _ types.CID, statuses ...types.Status) ([]types.Lifecycle, error) {
+	keepers := fmt.Sprint(types.GetContextTags(ctx)) == "[keeper]"
+	result := []types.Lifecycle{}
+	for _, lc := range []types.Lifecycle{
+		{UUID: "lc0", Location: types.Location{Name: "shelf"}, Status: types.ActiveStatus},
+		{UUID: "lc1", Location: types.Location{Name: "tub"}, Status: types.DeadStatus},
+	} {
+		if lc.Status.Matches(statuses) && (!keepers || lc.UUID == "lc0") {
+			result = append(result, lc)
+		}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) AddLifecycleEvent(_ context.Context, lc *types.Lifecycle, e types.Event, _ types.CID) error {
+	if lc.UUID == "retired" {
+		return types.TransitionError{EventType: e.EventType, Reason: "'Sunset' already ended it"}
+	}
+	e.UUID = "new event"
+	db.added = &e
+	lc.Events = append([]types.Event{e}, lc.Events...)
+	return nil
+}
+
+func (db *fakeDB) GetFields(_ context.Context, et *types.EventType, _ types.CID) error {
+	zero := 0.0
+	et.Fields = []types.Field{
+		{UUID: "fd0", Name: "diameter", Type: types.NumberField, Unit: "mm", Min: &zero},
+		{UUID: "fd1", Name: "color", Type: types.TextField},
+	}
+	return nil
+}
+
+func (db *fakeDB) GetHarvests(_ context.Context, lc *types.Lifecycle, _ types.CID) error {
+	lc.Harvests = []types.Harvest{
+		{UUID: "h0", Date: time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC), FreshWeight: 300, DryWeight: 30, Count: 10, Grade: "A"},
+		{UUID: "h1", Date: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), FreshWeight: 100},
+	}
+	return nil
+}
+
+func (db *fakeDB) AddHarvest(_ context.Context, lc *types.Lifecycle, h types.Harvest, _ types.CID) error {
+	if lc.UUID == "missing" {
+		return fmt.Errorf("foreign key violation")
+	}
+	h.UUID = "new harvest"
+	lc.Harvests = append(lc.Harvests, h)
+	return nil
+}
+
+func (db *fakeDB) InsertLocation(_ context.Context, loc types.Location, _ types.CID) (types.Location, error) {
+	loc.UUID = "new location"
+	return loc, nil
+}
+
+func (db *fakeDB) LocationOccupancy(_ context.Context, id types.UUID, _ types.CID) (types.Occupancy, error) {
+	return types.NewOccupancy(
+		types.Location{UUID: id, Name: "chamber b", Capacity: 4},
+		[]types.Lifecycle{{UUID: "lc0"}, {UUID: "lc2"}},
+	), nil
+}
+
+func (db *fakeDB) ReadingRollups(_ context.Context, _ string, res types.Resolution, w types.Window, _ types.CID) ([]types.ReadingRollup, error) {
+	if res != types.HourResolution {
+		return nil, fmt.Errorf("unknown resolution for reading rollups: '%s'", res)
+	}
+	low, high := float32(20), float32(22.5)
+	return []types.ReadingRollup{{
+		Bucket:      *w.From,
+		Count:       2,
+		Temperature: types.ReadingStats{Count: 2, Min: &low, Max: &high, Mean: &high},
+	}}, nil
+}
+
+func (db *fakeDB) DueTasks(_ context.Context, by time.Time, _ types.CID) ([]types.Task, error) {
+	if by.Before(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)) {
+		return []types.Task{}, nil
+	}
+	return []types.Task{{
+		UUID:           "t0",
+		FollowUp:       types.FollowUp{Name: "Check colonization", Days: 7, Expects: &_ets[2]},
+		ObservableType: types.LifecycleParent,
+		ObservableUUID: "lc0",
+		Due:            time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+		Overdue:        true,
+	}}, nil
+}
+
+func (db *fakeDB) CompleteTask(_ context.Context, id types.UUID, e *types.Event, _ types.CID) (types.Task, error) {
+	done := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	result := types.Task{
+		UUID:           id,
+		FollowUp:       types.FollowUp{Name: "Check colonization", Days: 7},
+		ObservableType: types.LifecycleParent,
+		ObservableUUID: "lc0",
+		Due:            time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+		Done:           &done,
+	}
+	if e != nil {
+		db.added = e
+		recorded := types.UUID("new event")
+		result.Result = &recorded
+	}
+	return result, nil
+}
+
+func (db *fakeDB) AddProtocolStep(_ context.Context, p *types.Protocol, s types.ProtocolStep, _ types.CID) (types.ProtocolStep, error) {
+	s.UUID = "new step"
+	p.Steps = append(p.Steps, s)
+	return s, nil
+}
+
+func (db *fakeDB) ProtocolDeviations(_ context.Context, id types.UUID, _ types.CID) (types.DeviationReport, error) {
+	if id == "missing" {
+		return types.DeviationReport{}, fmt.Errorf("observable doesn't follow a protocol: '%s'", id)
+	}
+	anchor := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	early, expected, missing := -2, anchor.AddDate(0, 0, 10), anchor.AddDate(0, 0, 20)
+	return types.DeviationReport{
+		Anchor: anchor,
+		Deviations: []types.Deviation{
+			{
+				Kind:     types.EarlyDeviation,
+				Step:     &types.ProtocolStep{Day: 10, EventType: _ets[2]},
+				Event:    &types.Event{EventType: _ets[2], CTime: anchor.AddDate(0, 0, 8)},
+				Expected: &expected,
+				Days:     &early,
+			},
+			{Kind: types.MissingDeviation, Step: &types.ProtocolStep{Day: 20, EventType: _ets[1]}, Expected: &missing},
+			{Kind: types.UnexpectedDeviation, Event: &types.Event{EventType: _ets[0], CTime: anchor.AddDate(0, 0, 3)}},
+		},
+	}, nil
+}
+
+func (db *fakeDB) CreateBatch(_ context.Context, name string, template types.Lifecycle, n int, _ types.CID) (types.Batch, error) {
+	if n < 1 {
+		return types.Batch{}, fmt.Errorf("a batch needs at least one member: %d", n)
+	}
+	b := types.Batch{UUID: "new batch", Name: name}
+	for i := 0; i < n; i++ {
+		lc := template
+		lc.UUID = types.UUID(fmt.Sprintf("lc%d", i))
+		b.Members = append(b.Members, types.BatchMember{Lifecycle: lc})
+	}
+	return b, nil
+}
+
+// the second member was excluded and the third got moldy, so only the first
+// one gets batch events
+func (db *fakeDB) AddBatchEvent(ctx context.Context, b *types.Batch, e types.Event, cid types.CID) error {
+	excluded := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	mold := types.Event{EventType: _ets[0]}
+	b.Members = []types.BatchMember{
+		{Lifecycle: types.Lifecycle{UUID: "lc0", Status: types.ActiveStatus}},
+		{Lifecycle: types.Lifecycle{UUID: "lc1", Status: types.ActiveStatus}, Excluded: &excluded},
+		{Lifecycle: types.Lifecycle{UUID: "lc2", Status: types.ContaminatedStatus, Events: []types.Event{mold}}},
+	}
+	return db.AddLifecycleEvent(ctx, &b.Members[0].Lifecycle, e, cid)
+}
+
+// x0 already has a control arm
+func (db *fakeDB) AddExperimentArm(_ context.Context, e *types.Experiment, a types.ExperimentArm, _ types.CID) (types.ExperimentArm, error) {
+	if e.UUID == "x0" && a.Control {
+		return a, fmt.Errorf("unique key violation: Key (experiment_uuid)=(x0) already exists.")
+	}
+	a.UUID = "new arm"
+	return a, nil
+}
+
+func (db *fakeDB) ExperimentReport(_ context.Context, id types.UUID, _ types.CID) (types.ExperimentReport, error) {
+	p := 0.0421
+	return types.ExperimentReport{
+		Experiment: types.Experiment{UUID: id},
+		Arms: []types.ArmSummary{
+			{
+				Arm:           types.ExperimentArm{UUID: "a0", Name: "rye", Control: true},
+				Yield:         types.SampleSummary{N: 3, Mean: 105, StdDev: 5},
+				Colonization:  types.SampleSummary{N: 3, Mean: 11, StdDev: 1},
+				Contamination: types.ProportionSummary{N: 4, Count: 1, Rate: 0.25},
+			},
+			{
+				Arm:           types.ExperimentArm{UUID: "a1", Name: "millet"},
+				Yield:         types.SampleSummary{N: 2, Mean: 130, StdDev: 14.142, P: &p},
+				Contamination: types.ProportionSummary{N: 3},
+			},
+		},
+	}, nil
+}
+
+func (db *fakeDB) AddTag(_ context.Context, id types.UUID, tag string, _ types.CID) error {
+	if tag == "keeper" {
+		return fmt.Errorf("unique key violation: Key (taggable_uuid, tag)=(%s, keeper) already exists.", id)
+	}
+	return nil
+}
+
+func (db *fakeDB) GetTags(context.Context, types.UUID, types.CID) ([]string, error) {
+	return []string{"keeper", "suspect batch"}, nil
+}
+
+func Test_run(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+		added  *types.Event
+	}{
+		"list_stages": {
+			args:   []string{"stage", "list"},
+			stdout: "ID  NAME\n0   Gestation\n",
+		},
+		"list_stages_json": {
+			args:   []string{"-format", "json", "stage", "list"},
+			stdout: "[\n  {\n    \"id\": \"0\",\n    \"name\": \"Gestation\"\n  }\n]\n",
+		},
+		"list_source_types": {
+			args: []string{"source", "types"},
+			stdout: "NAME   MAX  MIXABLE  CLONAL\n" +
+				"Clone  1    false    true\n" +
+				"Spore  2    false    false\n",
+		},
+		"list_lifecycles_by_status": {
+			args: []string{"lifecycle", "list", "-status", "dead, harvested"},
+			stdout: "ID   LOCATION  STRAIN  VENDOR  STATUS  LAST EVENT  MTIME\n" +
+				"lc1  tub                       dead                \n",
+		},
+		"list_lifecycles_by_tag": {
+			args: []string{"lifecycle", "list", "-tag", " Keeper,"},
+			stdout: "ID   LOCATION  STRAIN  VENDOR  STATUS  LAST EVENT  MTIME\n" +
+				"lc0  shelf                     active              \n",
+		},
+		"add_event": {
+			args:   []string{"-format", "json", "event", "add", "-temperature", "21.5", "lc0", "Pinning"},
+			stdout: "{\n  \"id\": \"new event\",\n  \"temperature\": 21.5,\n  \"event_type\": {\n    \"id\": \"15\",\n    \"name\": \"Pinning\",\n    \"severity\": \"Info\",\n    \"stage\": {\n      \"id\": \"2\",\n      \"name\": \"Majority\"\n    }\n  },\n  \"occurred_at\": \"0001-01-01T00:00:00Z\",\n  \"mtime\": \"0001-01-01T00:00:00Z\",\n  \"ctime\": \"0001-01-01T00:00:00Z\"\n}\n",
+			added:  &types.Event{UUID: "new event", Temperature: 21.5, EventType: _ets[2]},
+		},
+		"add_event_with_stage": {
+			args:  []string{"event", "add", "-stage", "vacation", "lc0", "mold"},
+			added: &types.Event{UUID: "new event", EventType: _ets[1]},
+			stdout: "KEY                    VALUE\n" +
+				"ctime                  0001-01-01T00:00:00Z\n" +
+				"event_type.id          24\n" +
+				"event_type.name        Mold\n" +
+				"event_type.severity    Fatal\n" +
+				"event_type.stage.id    3\n" +
+				"event_type.stage.name  Vacation\n" +
+				"id                     new event\n" +
+				"mtime                  0001-01-01T00:00:00Z\n" +
+				"occurred_at            0001-01-01T00:00:00Z\n" +
+				"temperature            0\n",
+		},
+		"add_measured_event": {
+			args: []string{"event", "add", "-measure", "diameter=12.5, color = white", "lc0", "Pinning"},
+			added: &types.Event{UUID: "new event", EventType: _ets[2], Measurements: []types.Measurement{
+				{Field: "diameter", Value: "12.5"},
+				{Field: "color", Value: "white"},
+			}},
+			stdout: "KEY                    VALUE\n" +
+				"ctime                  0001-01-01T00:00:00Z\n" +
+				"event_type.id          15\n" +
+				"event_type.name        Pinning\n" +
+				"event_type.severity    Info\n" +
+				"event_type.stage.id    2\n" +
+				"event_type.stage.name  Majority\n" +
+				"id                     new event\n" +
+				"measurements[0].field  diameter\n" +
+				"measurements[0].value  12.5\n" +
+				"measurements[1].field  color\n" +
+				"measurements[1].value  white\n" +
+				"mtime                  0001-01-01T00:00:00Z\n" +
+				"occurred_at            0001-01-01T00:00:00Z\n" +
+				"temperature            0\n",
+		},
+		"add_backfilled_event": {
+			args:   []string{"-format", "json", "event", "add", "-at", "2024-01-02T10:30:00Z", "lc0", "Pinning"},
+			added:  &types.Event{UUID: "new event", EventType: _ets[2], OccurredAt: time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)},
+			stdout: "{\n  \"id\": \"new event\",\n  \"temperature\": 0,\n  \"event_type\": {\n    \"id\": \"15\",\n    \"name\": \"Pinning\",\n    \"severity\": \"Info\",\n    \"stage\": {\n      \"id\": \"2\",\n      \"name\": \"Majority\"\n    }\n  },\n  \"occurred_at\": \"2024-01-02T10:30:00Z\",\n  \"mtime\": \"0001-01-01T00:00:00Z\",\n  \"ctime\": \"0001-01-01T00:00:00Z\"\n}\n",
+		},
+		"bad_occurred_at": {
+			args:   []string{"event", "add", "-at", "yesterday", "lc0", "Pinning"},
+			code:   1,
+			stderr: "event add: at isn't yyyy-mm-dd or RFC3339: 'yesterday'\n",
+		},
+		"bad_measurement": {
+			args:   []string{"event", "add", "-measure", "diameter", "lc0", "Pinning"},
+			code:   1,
+			stderr: "event add: measurement isn't name=value: 'diameter'\n",
+		},
+		"list_fields": {
+			args: []string{"field", "list", "15"},
+			stdout: "ID   NAME      TYPE    UNIT  MIN  MAX\n" +
+				"fd0  diameter  number  mm    0    -\n" +
+				"fd1  color     text          -    -\n",
+		},
+		"list_strains_by_filiation": {
+			args: []string{"strain", "list", "-depth", "2", "-root", "s0"},
+			stdout: "ID  NAME        SPECIES      VENDOR    GENERATION  FILIAL  CTIME\n" +
+				"s2  grandchild  P. cubensis  vendor 0  g1          F2      2024-01-01T00:00:00Z\n",
+		},
+		"list_strains_filtered_out": {
+			args:   []string{"strain", "list", "-depth", "0"},
+			stdout: "ID  NAME  SPECIES  VENDOR  GENERATION  FILIAL  CTIME\n",
+		},
+		"list_strains_by_attribute": {
+			args: []string{"strain", "list", "-attr", "headroom>=20, headroom<=30,color=white|purple"},
+			stdout: "ID  NAME        SPECIES      VENDOR    GENERATION  FILIAL  CTIME\n" +
+				"s2  grandchild  P. cubensis  vendor 0  g1          F2      2024-01-01T00:00:00Z\n",
+		},
+		"list_strains_attribute_filtered_out": {
+			args:   []string{"strain", "list", "-attr", "headroom>=30"},
+			stdout: "ID  NAME  SPECIES  VENDOR  GENERATION  FILIAL  CTIME\n",
+		},
+		"bad_attribute_filter": {
+			args:   []string{"strain", "list", "-attr", "headroom>tall"},
+			code:   1,
+			stderr: "strain list: attribute filter isn't name=value, name>=n or name<=n: 'headroom>tall'\n",
+		},
+		"attribute_range_isnt_a_number": {
+			args:   []string{"strain", "list", "-attr", "headroom>=tall"},
+			code:   1,
+			stderr: "strain list: headroom isn't a number: 'tall'\n",
+		},
+		"list_attributes": {
+			args: []string{"attribute", "list"},
+			stdout: "NAME      TYPE    UNIT  ALLOWED        SYNONYMS\n" +
+				"color     enum          purple, white  \n" +
+				"headroom  number  cm                   \n",
+		},
+		"define_attribute": {
+			args: []string{"attribute", "define", "-type", "enum", "-allowed", "low, high", "vigor"},
+			stdout: "NAME   TYPE  UNIT  ALLOWED    SYNONYMS\n" +
+				"vigor  enum        low, high  \n",
+		},
+		"define_bad_attribute": {
+			args:   []string{"attribute", "define", "-type", "enum", "vigor"},
+			code:   1,
+			stderr: "attribute define: an enum attribute needs allowed values: 'vigor'\n",
+		},
+		"attribute_synonym": {
+			args: []string{"attribute", "synonym", "color", "colour"},
+			stdout: "NAME   TYPE  UNIT  ALLOWED        SYNONYMS\n" +
+				"color  enum        purple, white  colour\n",
+		},
+		"unknown_attribute_synonym": {
+			args:   []string{"attribute", "synonym", "vigor", "oomph"},
+			code:   1,
+			stderr: "attribute synonym: unknown attribute: 'vigor'\n",
+		},
+		"merge_attributes": {
+			args: []string{"attribute", "merge", "colour", "color"},
+			stdout: "NAME   TYPE  UNIT  ALLOWED  SYNONYMS\n" +
+				"color  text                 colour\n",
+		},
+		"strain_ancestry": {
+			args: []string{"strain", "ancestry", "-depth", "1", "s1"},
+			stdout: "DEPTH  KIND        ID    LABEL   FROM\n" +
+				"0      strain      s1    child   generation#g0\n" +
+				"1      generation  g0    g0      source#src0\n" +
+				"1      source      src0  Clone   strain#s0\n" +
+				"1      strain      s0    parent  -\n",
+		},
+		"strain_pedigree_dot": {
+			args: []string{"strain", "pedigree", "-depth", "1", "-as", "dot", "s1"},
+			stdout: "digraph lineage {\n" +
+				"  \"strain#s1\" [label=\"child\", shape=box];\n" +
+				"  \"generation#g0\" [label=\"generation g0\", shape=ellipse];\n" +
+				"  \"strain#s0\" [label=\"parent\", shape=box];\n" +
+				"  \"strain#s0\" -> \"generation#g0\" [label=\"Clone\"];\n" +
+				"  \"generation#g0\" -> \"strain#s1\";\n" +
+				"}\n",
+		},
+		"library_mermaid": {
+			args:   []string{"strain", "pedigree", "-as", "mermaid"},
+			stdout: "flowchart TD\n  n0[\"loner<br/>P. cubensis\"]\n",
+		},
+		"promote_generation": {
+			args: []string{"generation", "promote", "-species", "P.cubensis", "-inherit", "agreed", "g0", "golden", "ghost"},
+			stdout: "KEY          VALUE\n" +
+				"ctime        0001-01-01T00:00:00Z\n" +
+				"id           s9 from g0\n" +
+				"name         golden ghost\n" +
+				"species      P.cubensis\n" +
+				"vendor.id    localhost\n" +
+				"vendor.name  \n",
+		},
+		"bad_inherit_policy": {
+			args:   []string{"generation", "promote", "-inherit", "last", "g0", "ghost"},
+			code:   1,
+			stderr: "generation promote: unknown inherit policy: 'last'\n",
+		},
+		"promote_without_name": {
+			args:   []string{"generation", "promote", "g0"},
+			code:   1,
+			stderr: "generation promote: need a generation id and a name\n",
+		},
+		"bad_pedigree_format": {
+			args:   []string{"strain", "pedigree", "-depth", "1", "-as", "svg", "s1"},
+			code:   1,
+			stderr: "strain pedigree: unknown lineage format: 'svg'\n",
+		},
+		"bad_field_bound": {
+			args:   []string{"field", "add", "-min", "low", "15", "diameter", "number"},
+			code:   1,
+			stderr: "field add: min isn't a number: 'low'\n",
+		},
+		"illegal_event": {
+			args:   []string{"event", "add", "retired", "Pinning"},
+			code:   1,
+			stderr: "event add: illegal transition to 'Pinning': 'Sunset' already ended it\n",
+		},
+		"list_harvests": {
+			args: []string{"harvest", "list", "lc0"},
+			stdout: "ID  DATE        FRESH   DRY    COUNT  GRADE\n" +
+				"h0  2024-01-11  300.00  30.00  10     A\n" +
+				"h1  2024-01-16  100.00  0.00   0      \n",
+		},
+		"add_harvest": {
+			args:   []string{"-format", "json", "harvest", "add", "-date", "2024-01-11", "-count", "10", "lc0", "300"},
+			stdout: "{\n  \"id\": \"new harvest\",\n  \"date\": \"2024-01-11T00:00:00Z\",\n  \"fresh_weight\": 300,\n  \"count\": 10,\n  \"mtime\": \"0001-01-01T00:00:00Z\",\n  \"ctime\": \"0001-01-01T00:00:00Z\"\n}\n",
+		},
+		"bad_harvest_weight": {
+			args:   []string{"harvest", "add", "lc0", "lots"},
+			code:   1,
+			stderr: "harvest add: fresh weight isn't a number: 'lots'\n",
+		},
+		"bad_harvest_date": {
+			args:   []string{"harvest", "add", "-date", "tuesday", "lc0", "300"},
+			code:   1,
+			stderr: "harvest add: date isn't yyyy-mm-dd: 'tuesday'\n",
+		},
+		"harvest_missing_lifecycle": {
+			args:   []string{"harvest", "add", "missing", "300"},
+			code:   1,
+			stderr: "harvest add: foreign key violation\n",
+		},
+		"reading_rollup": {
+			args:   []string{"reading", "rollup", "-from", "2024-01-11", "shelf"},
+			stdout: "BUCKET                COUNT  TEMPERATURE     HUMIDITY  CO2  LIGHT\n2024-01-11T00:00:00Z  2      20.0/22.5/22.5  -         -    -\n",
+		},
+		"reading_rollup_resolution": {
+			args:   []string{"reading", "rollup", "-resolution", "week", "-from", "2024-01-11", "shelf"},
+			code:   1,
+			stderr: "reading rollup: unknown resolution for reading rollups: 'week'\n",
+		},
+		"reading_rollup_bad_date": {
+			args:   []string{"reading", "rollup", "-to", "soon", "shelf"},
+			code:   1,
+			stderr: "reading rollup: to isn't yyyy-mm-dd: 'soon'\n",
+		},
+		"reading_ingest_missing_file": {
+			args:   []string{"reading", "ingest", "nowhere.json"},
+			code:   1,
+			stderr: "reading ingest: open nowhere.json: no such file or directory\n",
+		},
+		"add_location": {
+			args:   []string{"-format", "json", "location", "add", "-kind", "fruiting chamber", "-capacity", "4", "-max-humidity", "95", "chamber", "b"},
+			stdout: "{\n  \"id\": \"new location\",\n  \"name\": \"chamber b\",\n  \"kind\": \"fruiting chamber\",\n  \"capacity\": 4,\n  \"target\": {\n    \"max_humidity\": 95\n  }\n}\n",
+		},
+		"add_location_without_kind": {
+			args:   []string{"location", "add", "chamber b"},
+			code:   1,
+			stderr: "location add: need a -kind\n",
+		},
+		"location_occupancy": {
+			args:   []string{"location", "occupancy", "b"},
+			stdout: "LOCATION   OCCUPIED  CAPACITY  FILL  LIFECYCLES\nchamber b  2         4         50%   lc0,lc2\n",
+		},
+		"due_tasks": {
+			args:   []string{"task", "due", "-by", "2024-01-09"},
+			stdout: "ID  TASK                FOR            DUE         DONE     EXPECTS\nt0  Check colonization  lifecycle lc0  2024-01-08  overdue  Pinning\n",
+		},
+		"no_due_tasks": {
+			args:   []string{"task", "due", "-by", "2024-01-01"},
+			stdout: "ID  TASK  FOR  DUE  DONE  EXPECTS\n",
+		},
+		"complete_task": {
+			args:   []string{"task", "done", "t0"},
+			stdout: "ID  TASK                FOR            DUE         DONE        EXPECTS\nt0  Check colonization  lifecycle lc0  2024-01-08  2024-01-09  -\n",
+		},
+		"complete_task_with_event": {
+			args:   []string{"task", "done", "-event", "Mold", "-stage", "Any", "-humidity", "80", "t0"},
+			stdout: "ID  TASK                FOR            DUE         DONE        EXPECTS\nt0  Check colonization  lifecycle lc0  2024-01-08  2024-01-09  -\n",
+			added:  &types.Event{Humidity: 80, EventType: _ets[0]},
+		},
+		"complete_task_expected_event": {
+			args:   []string{"task", "done", "-record", "-temperature", "21.5", "t0"},
+			stdout: "ID  TASK                FOR            DUE         DONE        EXPECTS\nt0  Check colonization  lifecycle lc0  2024-01-08  2024-01-09  -\n",
+			added:  &types.Event{Temperature: 21.5},
+		},
+		"add_protocol_step": {
+			args:   []string{"-format", "json", "protocol", "step-add", "-day", "35", "-tolerance", "5", "p0", "Pinning"},
+			stdout: "{\n  \"id\": \"new step\",\n  \"event_type\": {\n    \"id\": \"15\",\n    \"name\": \"Pinning\",\n    \"severity\": \"Info\",\n    \"stage\": {\n      \"id\": \"2\",\n      \"name\": \"Majority\"\n    }\n  },\n  \"day\": 35,\n  \"tolerance\": 5\n}\n",
+		},
+		"add_protocol_step_without_event": {
+			args:   []string{"protocol", "step-add", "-day", "35", "p0"},
+			code:   1,
+			stderr: "protocol step-add: need a protocol id and an event type name\n",
+		},
+		"protocol_deviations": {
+			args: []string{"protocol", "deviations", "lc0"},
+			stdout: "KIND        STEP            EXPECTED    EVENT    HAPPENED    DAYS\n" +
+				"early       day 10 Pinning  2024-01-11  Pinning  2024-01-09  -2\n" +
+				"missing     day 20 Mold     2024-01-21  -        -           -\n" +
+				"unexpected  -               -           Mold     2024-01-04  -\n",
+		},
+		"no_protocol": {
+			args:   []string{"protocol", "deviations", "missing"},
+			code:   1,
+			stderr: "protocol deviations: observable doesn't follow a protocol: 'missing'\n",
+		},
+		"create_batch": {
+			args: []string{"batch", "create", "-count", "2", "lc9", "rye", "jars"},
+			stdout: "ID   STATUS  LAST EVENT  IN STEP  EXCLUDED\n" +
+				"lc0                      true     -\n" +
+				"lc1                      true     -\n",
+		},
+		"create_empty_batch": {
+			args:   []string{"batch", "create", "lc9", "rye jars"},
+			code:   1,
+			stderr: "batch create: a batch needs at least one member: 0\n",
+		},
+		"batch_event": {
+			args: []string{"batch", "event", "b0", "Pinning"},
+			stdout: "ID   STATUS        LAST EVENT  IN STEP  EXCLUDED\n" +
+				"lc0  active        Pinning     true     -\n" +
+				"lc1  active                    false    2024-01-02T00:00:00Z\n" +
+				"lc2  contaminated  Mold        false    -\n",
+			added: &types.Event{UUID: "new event", EventType: _ets[2]},
+		},
+		"experiment_report": {
+			args: []string{"experiment", "report", "x0"},
+			stdout: "ARM            YIELD             P      COLONIZATION    P  CONTAMINATION  P\n" +
+				"rye (control)  105.0±5.0 (n=3)   -      11.0±1.0 (n=3)  -  1/4            -\n" +
+				"millet         130.0±14.1 (n=2)  0.042  -               -  0/3            -\n",
+		},
+		"second_control": {
+			args:   []string{"experiment", "arm-add", "-control", "x0", "millet"},
+			code:   1,
+			stderr: "experiment arm-add: unique key violation: Key (experiment_uuid)=(x0) already exists.\n",
+		},
+		"add_tag": {
+			args:   []string{"tag", "add", "lc0", "suspect", "batch"},
+			stdout: "TAG\n" + "keeper\n" + "suspect batch\n",
+		},
+		"duplicate_tag": {
+			args:   []string{"tag", "add", "lc0", "keeper"},
+			code:   1,
+			stderr: "tag add: unique key violation: Key (taggable_uuid, tag)=(lc0, keeper) already exists.\n",
+		},
+		"ambiguous_event": {
+			args:   []string{"event", "add", "lc0", "Mold"},
+			code:   1,
+			stderr: "event add: 'Mold' is ambiguous, use -stage with one of: Any, Vacation\n",
+		},
+		"unknown_event": {
+			args:   []string{"event", "add", "lc0", "Sunburn"},
+			code:   1,
+			stderr: "event add: no event type named 'Sunburn'\n",
+		},
+		"missing_lifecycle": {
+			args:   []string{"event", "add", "missing", "Pinning"},
+			code:   1,
+			stderr: "event add: sql: no rows in result set\n",
+		},
+		"wrong_arg_count": {
+			args:   []string{"lifecycle", "show"},
+			code:   1,
+			stderr: "lifecycle show: expected exactly 1 argument, got 0\n",
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &fakeDB{}
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+			code := run(tc.args, stdout, stderr, func(*types.Config, *log.Entry) (types.DB, error) {
+				return db, nil
+			})
+
+			require.Equal(t, tc.code, code, stderr.String())
+			require.Equal(t, tc.stdout, stdout.String())
+			require.Equal(t, tc.stderr, stderr.String())
+			require.Equal(t, tc.added, db.added)
+		})
+	}
+}
+
+func Test_runUsage(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		args []string
+		code int
+	}{
+		"no_args":         {code: 2},
+		"unknown_command": {args: []string{"lifecycle", "explode"}, code: 2},
+		"bad_flag":        {args: []string{"-nope", "stage", "list"}, code: 2},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := &bytes.Buffer{}
+			code := run(tc.args, &bytes.Buffer{}, stderr, func(*types.Config, *log.Entry) (types.DB, error) {
+				return nil, fmt.Errorf("shouldn't connect")
+			})
+
+			require.Equal(t, tc.code, code)
+			require.Contains(t, stderr.String(), "usage: huautla")
+		})
+	}
+}
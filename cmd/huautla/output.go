@@ -0,0 +1,665 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+type (
+	// tabler is anything that knows how to lay itself out in columns; anything
+	// else printed as a table gets flattened into key/value pairs
+	tabler interface {
+		header() []string
+		rows() [][]string
+	}
+
+	lifecycles  []types.Lifecycle
+	generations []types.Generation
+	strains     []types.Strain
+	stages      []types.Stage
+	sourcetypes []types.SourceType
+	attributes  []types.AttributeDefinition
+	eventtypes  []types.EventType
+	ingredients []types.Ingredient
+	evts        []types.Event
+	notes       []types.Note
+	harvests    []types.Harvest
+	rollups     []types.ReadingRollup
+	locations   []types.Location
+	occupancy   types.Occupancy
+	fields      []types.Field
+	followups   []types.FollowUp
+	tasks       []types.Task
+	protocols   []types.Protocol
+	steps       []types.ProtocolStep
+	deviations  types.DeviationReport
+	batches     []types.Batch
+	members     []types.BatchMember
+	experiments []types.Experiment
+	arms        []types.ExperimentArm
+	expreport   types.ExperimentReport
+	tags        []string
+	lineage     types.Lineage
+
+	// graph is already in some other language, so it's printed as it is
+	graph string
+)
+
+const (
+	jsonFormat  = "json"
+	tableFormat = "table"
+)
+
+func emit(w io.Writer, format string, v any) error {
+	switch format {
+	case jsonFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case tableFormat:
+		return printTable(w, v)
+	}
+	return fmt.Errorf("unknown format: '%s', use one of '%s' or '%s'", format, jsonFormat, tableFormat)
+}
+
+func printTable(w io.Writer, v any) error {
+	var header []string
+	var rows [][]string
+
+	if g, ok := v.(graph); ok {
+		_, err := io.WriteString(w, string(g))
+		return err
+	} else if t, ok := v.(tabler); ok {
+		header, rows = t.header(), t.rows()
+	} else {
+		var flat map[string]any
+		if js, err := json.Marshal(v); err != nil {
+			return err
+		} else if err = json.Unmarshal(js, &flat); err != nil {
+			return fmt.Errorf("only objects can be printed as key/value tables: %w", err)
+		}
+
+		header = []string{"KEY", "VALUE"}
+		for _, kv := range flatten("", flat) {
+			rows = append(rows, []string{kv[0], kv[1]})
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// flatten turns nested reports into dotted paths, e.g. strain.vendor.name,
+// events[0].event_type.name; keys are sorted so output is stable
+func flatten(prefix string, v any) [][2]string {
+	switch T := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(T))
+		for k := range T {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		result := [][2]string{}
+		for _, k := range keys {
+			p := k
+			if prefix != "" {
+				p = prefix + "." + k
+			}
+			result = append(result, flatten(p, T[k])...)
+		}
+		return result
+	case []any:
+		result := [][2]string{}
+		for i, e := range T {
+			result = append(result, flatten(fmt.Sprintf("%s[%d]", prefix, i), e)...)
+		}
+		return result
+	case nil:
+		return [][2]string{{prefix, ""}}
+	}
+
+	return [][2]string{{prefix, fmt.Sprintf("%v", v)}}
+}
+
+func ts(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (lcs lifecycles) header() []string {
+	return []string{"ID", "LOCATION", "STRAIN", "VENDOR", "STATUS", "LAST EVENT", "MTIME"}
+}
+
+func (lcs lifecycles) rows() [][]string {
+	result := make([][]string, len(lcs))
+	for i, lc := range lcs {
+		last := ""
+		if len(lc.Events) > 0 {
+			last = lc.Events[0].EventType.Name
+		}
+		result[i] = []string{
+			string(lc.UUID),
+			lc.Location.Name,
+			lc.Strain.Name,
+			lc.Strain.Vendor.Name,
+			string(lc.Status),
+			last,
+			ts(lc.MTime),
+		}
+	}
+	return result
+}
+
+func (gens generations) header() []string {
+	return []string{"ID", "PLATING", "LIQUID", "SOURCES", "STATUS", "MTIME"}
+}
+
+func (gens generations) rows() [][]string {
+	result := make([][]string, len(gens))
+	for i, g := range gens {
+		srcs := make([]string, len(g.Sources))
+		for j, s := range g.Sources {
+			srcs[j] = fmt.Sprintf("%s(%s)", s.Strain.Name, s.Type)
+		}
+		result[i] = []string{
+			string(g.UUID),
+			g.PlatingSubstrate.Name,
+			g.LiquidSubstrate.Name,
+			strings.Join(srcs, ", "),
+			string(g.Status),
+			ts(g.MTime),
+		}
+	}
+	return result
+}
+
+func (strs strains) header() []string {
+	return []string{"ID", "NAME", "SPECIES", "VENDOR", "GENERATION", "FILIAL", "CTIME"}
+}
+
+func (strs strains) rows() [][]string {
+	result := make([][]string, len(strs))
+	for i, s := range strs {
+		gen, filial := "", ""
+		if s.Generation != nil {
+			gen = string(s.Generation.UUID)
+		}
+		if s.Filiation != nil {
+			filial = s.Filiation.Notation
+		}
+		result[i] = []string{
+			string(s.UUID),
+			s.Name,
+			s.Species,
+			s.Vendor.Name,
+			gen,
+			filial,
+			ts(s.CTime),
+		}
+	}
+	return result
+}
+
+func (ss stages) header() []string {
+	return []string{"ID", "NAME"}
+}
+
+func (ss stages) rows() [][]string {
+	result := make([][]string, len(ss))
+	for i, s := range ss {
+		result[i] = []string{string(s.UUID), s.Name}
+	}
+	return result
+}
+
+func (sts sourcetypes) header() []string {
+	return []string{"NAME", "MAX", "MIXABLE", "CLONAL"}
+}
+
+func (sts sourcetypes) rows() [][]string {
+	result := make([][]string, len(sts))
+	for i, st := range sts {
+		result[i] = []string{st.Name, fmt.Sprintf("%d", st.Max), fmt.Sprintf("%t", st.Mixable), fmt.Sprintf("%t", st.Clonal)}
+	}
+	return result
+}
+
+func (as attributes) header() []string {
+	return []string{"NAME", "TYPE", "UNIT", "ALLOWED", "SYNONYMS"}
+}
+
+func (as attributes) rows() [][]string {
+	result := make([][]string, len(as))
+	for i, a := range as {
+		result[i] = []string{a.Name, string(a.Type), a.Unit, strings.Join(a.Allowed, ", "), strings.Join(a.Synonyms, ", ")}
+	}
+	return result
+}
+
+func (ets eventtypes) header() []string {
+	return []string{"ID", "NAME", "SEVERITY", "STAGE"}
+}
+
+func (ets eventtypes) rows() [][]string {
+	result := make([][]string, len(ets))
+	for i, et := range ets {
+		result[i] = []string{string(et.UUID), et.Name, et.Severity, et.Stage.Name}
+	}
+	return result
+}
+
+func (is ingredients) header() []string {
+	return []string{"ID", "NAME"}
+}
+
+func (is ingredients) rows() [][]string {
+	result := make([][]string, len(is))
+	for i, ing := range is {
+		result[i] = []string{string(ing.UUID), ing.Name}
+	}
+	return result
+}
+
+func (es evts) header() []string {
+	return []string{"ID", "EVENT", "SEVERITY", "STAGE", "TEMPERATURE", "HUMIDITY", "OCCURRED"}
+}
+
+func (es evts) rows() [][]string {
+	result := make([][]string, len(es))
+	for i, e := range es {
+		result[i] = []string{
+			string(e.UUID),
+			e.EventType.Name,
+			e.EventType.Severity,
+			e.EventType.Stage.Name,
+			fmt.Sprintf("%.1f", e.Temperature),
+			fmt.Sprintf("%d", e.Humidity),
+			ts(e.OccurredAt),
+		}
+	}
+	return result
+}
+
+func (ns notes) header() []string {
+	return []string{"ID", "NOTE", "CTIME"}
+}
+
+func (ns notes) rows() [][]string {
+	result := make([][]string, len(ns))
+	for i, n := range ns {
+		result[i] = []string{string(n.UUID), n.Note, ts(n.CTime)}
+	}
+	return result
+}
+
+func (hs harvests) header() []string {
+	return []string{"ID", "DATE", "FRESH", "DRY", "COUNT", "GRADE"}
+}
+
+func (hs harvests) rows() [][]string {
+	result := make([][]string, len(hs))
+	for i, h := range hs {
+		result[i] = []string{
+			string(h.UUID),
+			h.Date.Format(time.DateOnly),
+			fmt.Sprintf("%.2f", h.FreshWeight),
+			fmt.Sprintf("%.2f", h.DryWeight),
+			fmt.Sprintf("%d", h.Count),
+			h.Grade,
+		}
+	}
+	return result
+}
+
+func (rs rollups) header() []string {
+	return []string{"BUCKET", "COUNT", "TEMPERATURE", "HUMIDITY", "CO2", "LIGHT"}
+}
+
+func (rs rollups) rows() [][]string {
+	result := make([][]string, len(rs))
+	for i, r := range rs {
+		result[i] = []string{
+			ts(r.Bucket),
+			fmt.Sprintf("%d", r.Count),
+			stats(r.Temperature),
+			stats(r.Humidity),
+			stats(r.CO2),
+			stats(r.Light),
+		}
+	}
+	return result
+}
+
+// stats squeezes min/mean/max into one column
+func stats(s types.ReadingStats) string {
+	if s.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f/%.1f/%.1f", *s.Min, *s.Mean, *s.Max)
+}
+
+func (ls locations) header() []string {
+	return []string{"ID", "NAME", "KIND", "CAPACITY", "TEMPERATURE", "HUMIDITY", "CO2"}
+}
+
+func (ls locations) rows() [][]string {
+	result := make([][]string, len(ls))
+	for i, l := range ls {
+		result[i] = []string{
+			string(l.UUID),
+			l.Name,
+			string(l.Kind),
+			fmt.Sprintf("%d", l.Capacity),
+			between(l.Target.MinTemperature, l.Target.MaxTemperature),
+			between(l.Target.MinHumidity, l.Target.MaxHumidity),
+			between(nil, l.Target.MaxCO2),
+		}
+	}
+	return result
+}
+
+// between leaves out whichever end of a target range isn't set
+func between(low, high *float32) string {
+	end := func(f *float32) string {
+		if f == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.1f", *f)
+	}
+	if low == nil && high == nil {
+		return "-"
+	}
+	return end(low) + ".." + end(high)
+}
+
+func (o occupancy) header() []string {
+	return []string{"LOCATION", "OCCUPIED", "CAPACITY", "FILL", "LIFECYCLES"}
+}
+
+func (o occupancy) rows() [][]string {
+	fill := "-"
+	if o.Fill != nil {
+		fill = fmt.Sprintf("%.0f%%", *o.Fill*100)
+	}
+
+	ids := make([]string, len(o.Lifecycles))
+	for i, lc := range o.Lifecycles {
+		ids[i] = string(lc.UUID)
+	}
+
+	return [][]string{{
+		o.Location.Name,
+		fmt.Sprintf("%d", o.Occupied),
+		fmt.Sprintf("%d", o.Location.Capacity),
+		fill,
+		strings.Join(ids, ","),
+	}}
+}
+
+func (fs fields) header() []string {
+	return []string{"ID", "NAME", "TYPE", "UNIT", "MIN", "MAX"}
+}
+
+func (fs fields) rows() [][]string {
+	bound := func(f *float64) string {
+		if f == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%v", *f)
+	}
+
+	result := make([][]string, len(fs))
+	for i, f := range fs {
+		result[i] = []string{string(f.UUID), f.Name, string(f.Type), f.Unit, bound(f.Min), bound(f.Max)}
+	}
+	return result
+}
+
+func (l lineage) header() []string {
+	return []string{"DEPTH", "KIND", "ID", "LABEL", "FROM"}
+}
+
+// rows has each node's parents by ID, which is all there is to the edges
+func (l lineage) rows() [][]string {
+	result := make([][]string, len(l.Nodes))
+	for i, n := range l.Nodes {
+		from := "-"
+		if parents := types.Lineage(l).Parents(n.ID); len(parents) > 0 {
+			from = strings.Join(parents, ",")
+		}
+		result[i] = []string{fmt.Sprintf("%d", n.Depth), string(n.Kind), string(n.UUID), n.Label, from}
+	}
+	return result
+}
+
+func (fs followups) header() []string {
+	return []string{"ID", "NAME", "DAYS", "EXPECTS"}
+}
+
+func (fs followups) rows() [][]string {
+	result := make([][]string, len(fs))
+	for i, f := range fs {
+		expects := "-"
+		if f.Expects != nil {
+			expects = f.Expects.Name
+		}
+		result[i] = []string{string(f.UUID), f.Name, fmt.Sprintf("%d", f.Days), expects}
+	}
+	return result
+}
+
+func (ps protocols) header() []string {
+	return []string{"ID", "NAME"}
+}
+
+func (ps protocols) rows() [][]string {
+	result := make([][]string, len(ps))
+	for i, p := range ps {
+		result[i] = []string{string(p.UUID), p.Name}
+	}
+	return result
+}
+
+func (ss steps) header() []string {
+	return []string{"ID", "DAY", "TOLERANCE", "EVENT", "STAGE"}
+}
+
+func (ss steps) rows() [][]string {
+	result := make([][]string, len(ss))
+	for i, s := range ss {
+		result[i] = []string{
+			string(s.UUID),
+			fmt.Sprintf("%d", s.Day),
+			fmt.Sprintf("±%d", s.Tolerance),
+			s.EventType.Name,
+			s.EventType.Stage.Name,
+		}
+	}
+	return result
+}
+
+func (dr deviations) header() []string {
+	return []string{"KIND", "STEP", "EXPECTED", "EVENT", "HAPPENED", "DAYS"}
+}
+
+// missing and pending steps have no event; unexpected events have no step
+func (dr deviations) rows() [][]string {
+	result := make([][]string, len(dr.Deviations))
+	for i, d := range dr.Deviations {
+		row := []string{string(d.Kind), "-", "-", "-", "-", "-"}
+		if d.Step != nil {
+			row[1] = fmt.Sprintf("day %d %s", d.Step.Day, d.Step.EventType.Name)
+		}
+		if d.Expected != nil {
+			row[2] = d.Expected.Format(time.DateOnly)
+		}
+		if d.Event != nil {
+			row[3], row[4] = d.Event.EventType.Name, d.Event.CTime.Format(time.DateOnly)
+		}
+		if d.Days != nil {
+			row[5] = fmt.Sprintf("%+d", *d.Days)
+		}
+		result[i] = row
+	}
+	return result
+}
+
+func (tks tasks) header() []string {
+	return []string{"ID", "TASK", "FOR", "DUE", "DONE", "EXPECTS"}
+}
+
+func (tks tasks) rows() [][]string {
+	result := make([][]string, len(tks))
+	for i, t := range tks {
+		done := "-"
+		if t.Done != nil {
+			done = t.Done.Format(time.DateOnly)
+		} else if t.Overdue {
+			done = "overdue"
+		}
+		expects := "-"
+		if t.FollowUp.Expects != nil {
+			expects = t.FollowUp.Expects.Name
+		}
+		result[i] = []string{
+			string(t.UUID),
+			t.FollowUp.Name,
+			fmt.Sprintf("%s %s", t.ObservableType, t.ObservableUUID),
+			t.Due.Format(time.DateOnly),
+			done,
+			expects,
+		}
+	}
+	return result
+}
+
+func (bs batches) header() []string {
+	return []string{"ID", "NAME", "CTIME"}
+}
+
+func (bs batches) rows() [][]string {
+	result := make([][]string, len(bs))
+	for i, b := range bs {
+		result[i] = []string{string(b.UUID), b.Name, ts(b.CTime)}
+	}
+	return result
+}
+
+func (ms members) header() []string {
+	return []string{"ID", "STATUS", "LAST EVENT", "IN STEP", "EXCLUDED"}
+}
+
+func (ms members) rows() [][]string {
+	result := make([][]string, len(ms))
+	for i, m := range ms {
+		last, excluded := "", "-"
+		if len(m.Lifecycle.Events) > 0 {
+			last = m.Lifecycle.Events[0].EventType.Name
+		}
+		if m.Excluded != nil {
+			excluded = ts(*m.Excluded)
+		}
+		result[i] = []string{
+			string(m.Lifecycle.UUID),
+			string(m.Lifecycle.Status),
+			last,
+			fmt.Sprintf("%t", m.InStep()),
+			excluded,
+		}
+	}
+	return result
+}
+
+func (es experiments) header() []string {
+	return []string{"ID", "NAME", "VARIABLE", "CTIME"}
+}
+
+func (es experiments) rows() [][]string {
+	result := make([][]string, len(es))
+	for i, e := range es {
+		result[i] = []string{string(e.UUID), e.Name, e.Variable, ts(e.CTime)}
+	}
+	return result
+}
+
+func (as arms) header() []string {
+	return []string{"ID", "NAME", "CONTROL", "LIFECYCLES"}
+}
+
+func (as arms) rows() [][]string {
+	result := make([][]string, len(as))
+	for i, a := range as {
+		ids := make([]string, len(a.Lifecycles))
+		for j, lc := range a.Lifecycles {
+			ids[j] = string(lc.UUID)
+		}
+		result[i] = []string{string(a.UUID), a.Name, fmt.Sprintf("%t", a.Control), strings.Join(ids, ",")}
+	}
+	return result
+}
+
+func (rpt expreport) header() []string {
+	return []string{"ARM", "YIELD", "P", "COLONIZATION", "P", "CONTAMINATION", "P"}
+}
+
+func (rpt expreport) rows() [][]string {
+	result := make([][]string, len(rpt.Arms))
+	for i, s := range rpt.Arms {
+		name := s.Arm.Name
+		if s.Arm.Control {
+			name += " (control)"
+		}
+		result[i] = []string{
+			name,
+			sample(s.Yield),
+			pvalue(s.Yield.P),
+			sample(s.Colonization),
+			pvalue(s.Colonization.P),
+			fmt.Sprintf("%d/%d", s.Contamination.Count, s.Contamination.N),
+			pvalue(s.Contamination.P),
+		}
+	}
+	return result
+}
+
+// sample squeezes mean±stddev and the sample size into one column
+func sample(s types.SampleSummary) string {
+	if s.N == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f±%.1f (n=%d)", s.Mean, s.StdDev, s.N)
+}
+
+func pvalue(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", *p)
+}
+
+func (tg tags) header() []string {
+	return []string{"TAG"}
+}
+
+func (tg tags) rows() [][]string {
+	result := make([][]string, len(tg))
+	for i, t := range tg {
+		result[i] = []string{t}
+	}
+	return result
+}
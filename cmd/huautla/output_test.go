@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_flatten(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		v      any
+		result [][2]string
+	}{
+		"nested": {
+			v: map[string]any{
+				"location": "shelf",
+				"strain":   map[string]any{"name": "strain 0", "vendor": map[string]any{"name": "vendor 0"}},
+				"events": []any{
+					map[string]any{"temperature": 21.5},
+					map[string]any{"temperature": nil},
+				},
+			},
+			result: [][2]string{
+				{"events[0].temperature", "21.5"},
+				{"events[1].temperature", ""},
+				{"location", "shelf"},
+				{"strain.name", "strain 0"},
+				{"strain.vendor.name", "vendor 0"},
+			},
+		},
+		"empty": {
+			v:      map[string]any{},
+			result: [][2]string{},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, flatten("", tc.v))
+		})
+	}
+}
+
+func Test_emit(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tcs := map[string]struct {
+		format string
+		v      any
+		result string
+		err    error
+	}{
+		"tabler": {
+			format: tableFormat,
+			v:      stages{{UUID: "0", Name: "Gestation"}, {UUID: "1", Name: "Colonization"}},
+			result: "ID  NAME\n0   Gestation\n1   Colonization\n",
+		},
+		"key_value": {
+			format: tableFormat,
+			v:      types.Note{UUID: "0", Note: "contaminated", CTime: epoch, MTime: epoch},
+			result: "KEY    VALUE\nctime  2024-01-01T00:00:00Z\nid     0\nmtime  2024-01-01T00:00:00Z\nnote   contaminated\n",
+		},
+		"json": {
+			format: jsonFormat,
+			v:      types.Stage{UUID: "0", Name: "Gestation"},
+			result: "{\n  \"id\": \"0\",\n  \"name\": \"Gestation\"\n}\n",
+		},
+		"not_an_object": {
+			format: tableFormat,
+			v:      []string{"a"},
+			err:    fmt.Errorf("only objects can be printed as key/value tables: json: cannot unmarshal array into Go value of type map[string]interface {}"),
+		},
+		"bad_format": {
+			format: "xml",
+			err:    fmt.Errorf("unknown format: 'xml', use one of 'json' or 'table'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := &bytes.Buffer{}
+			err := emit(w, tc.format, tc.v)
+			if tc.err != nil {
+				require.EqualError(t, err, tc.err.Error())
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.result, w.String())
+		})
+	}
+}
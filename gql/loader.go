@@ -0,0 +1,247 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+type (
+	// loader collects every key requested within a short window and resolves
+	// them with a single call to fetch; results are cached for the life of the
+	// loader, which is meant to be one request
+	loader[V any] struct {
+		fetch   fetcher[V]
+		wait    time.Duration
+		mtx     sync.Mutex
+		cache   map[types.UUID]*result[V]
+		pending map[types.UUID]*result[V]
+	}
+
+	fetcher[V any] func(context.Context, []types.UUID) (map[types.UUID]V, error)
+
+	result[V any] struct {
+		done chan struct{}
+		v    V
+		err  error
+	}
+
+	loaders struct {
+		vendors      *loader[types.Vendor]
+		substrates   *loader[types.Substrate]
+		stages       *loader[types.Stage]
+		eventtypes   *loader[types.EventType]
+		strains      *loader[types.Strain]
+		lifecycles   *loader[types.Lifecycle]
+		generations  *loader[types.Generation]
+		progeny      *loader[*types.Strain]
+		attributes   *loader[[]types.StrainAttribute]
+		ingredients  *loader[[]types.Ingredient]
+		notes        *loader[[]types.Note]
+		photos       *loader[[]types.Photo]
+		tags         *loader[[]string]
+		strainLCs    *loader[[]types.UUID]
+		vendorStrain *loader[[]types.Strain]
+		vendorSubs   *loader[[]types.Substrate]
+	}
+
+	ctxkey string
+)
+
+const loaderKey ctxkey = "loaders"
+
+// batchWait is how long a loader holds a batch open for sibling resolvers
+var batchWait = 2 * time.Millisecond
+
+func newLoader[V any](fetch fetcher[V]) *loader[V] {
+	return &loader[V]{
+		fetch: fetch,
+		wait:  batchWait,
+		cache: map[types.UUID]*result[V]{},
+	}
+}
+
+func (l *loader[V]) load(ctx context.Context, id types.UUID) (V, error) {
+	l.mtx.Lock()
+	r, ok := l.cache[id]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.cache[id] = r
+		if l.pending == nil {
+			l.pending = map[types.UUID]*result[V]{}
+			go l.dispatch(ctx)
+		}
+		l.pending[id] = r
+	}
+	l.mtx.Unlock()
+
+	<-r.done
+
+	return r.v, r.err
+}
+
+// loadAll is for loaders keyed by a parent, where a missing key just means
+// the parent has no children
+func (l *loader[V]) loadAll(ctx context.Context, id types.UUID) (V, error) {
+	v, err := l.load(ctx, id)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return v, err
+}
+
+func (l *loader[V]) dispatch(ctx context.Context) {
+	time.Sleep(l.wait)
+
+	l.mtx.Lock()
+	pending := l.pending
+	l.pending = nil
+	l.mtx.Unlock()
+
+	keys := make([]types.UUID, 0, len(pending))
+	for k := range pending {
+		keys = append(keys, k)
+	}
+
+	vals, err := l.fetch(ctx, keys)
+	for k, r := range pending {
+		if err != nil {
+			r.err = err
+		} else if v, ok := vals[k]; ok {
+			r.v = v
+		} else {
+			r.err = sql.ErrNoRows
+		}
+		close(r.done)
+	}
+}
+
+// all is for small reference tables, where one query for everything is
+// cheaper than one query per key; every batch fetches the whole table again,
+// so anything that grows with use belongs in some or byKey instead
+func all[V any](fn func(context.Context, types.CID) ([]V, error), key func(V) types.UUID) fetcher[V] {
+	return func(ctx context.Context, _ []types.UUID) (map[types.UUID]V, error) {
+		vals, err := fn(ctx, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[types.UUID]V, len(vals))
+		for _, v := range vals {
+			result[key(v)] = v
+		}
+
+		return result, nil
+	}
+}
+
+// group is like all, except many values share a key (e.g. strains by
+// vendor), so it has the same limits
+func group[V any](fn func(context.Context, types.CID) ([]V, error), key func(V) types.UUID) fetcher[[]V] {
+	return func(ctx context.Context, _ []types.UUID) (map[types.UUID][]V, error) {
+		vals, err := fn(ctx, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[types.UUID][]V, len(vals))
+		for _, v := range vals {
+			result[key(v)] = append(result[key(v)], v)
+		}
+
+		return result, nil
+	}
+}
+
+// some is like all, except only the keys in the batch are fetched, with one
+// query no matter how many there are
+func some[V any](fn func(context.Context, []types.UUID, types.CID) ([]V, error), key func(V) types.UUID) fetcher[V] {
+	return func(ctx context.Context, keys []types.UUID) (map[types.UUID]V, error) {
+		vals, err := fn(ctx, keys, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[types.UUID]V, len(vals))
+		for _, v := range vals {
+			result[key(v)] = v
+		}
+
+		return result, nil
+	}
+}
+
+// byKey is for the data layer's ...For methods, which already return their
+// values by key; a key with nothing to show for it just isn't there
+func byKey[V any](fn func(context.Context, []types.UUID, types.CID) (map[types.UUID]V, error)) fetcher[V] {
+	return func(ctx context.Context, keys []types.UUID) (map[types.UUID]V, error) {
+		return fn(ctx, keys, types.GetContextCID(ctx))
+	}
+}
+
+func newLoaders(db types.DB) *loaders {
+	return &loaders{
+		vendors: newLoader(all(db.SelectAllVendors, func(v types.Vendor) types.UUID {
+			return v.UUID
+		})),
+		substrates: newLoader(all(db.SelectAllSubstrates, func(s types.Substrate) types.UUID {
+			return s.UUID
+		})),
+		stages: newLoader(all(db.SelectAllStages, func(s types.Stage) types.UUID {
+			return s.UUID
+		})),
+		eventtypes: newLoader(all(db.SelectAllEventTypes, func(et types.EventType) types.UUID {
+			return et.UUID
+		})),
+		strains: newLoader(all(db.SelectAllStrains, func(s types.Strain) types.UUID {
+			return s.UUID
+		})),
+		lifecycles: newLoader(some(db.SelectLifecycles, func(lc types.Lifecycle) types.UUID {
+			return lc.UUID
+		})),
+		generations: newLoader(some(db.SelectGenerations, func(g types.Generation) types.UUID {
+			return g.UUID
+		})),
+		progeny: newLoader(byKey(func(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID]*types.Strain, error) {
+			strs, err := db.GeneratedStrains(ctx, ids, cid)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[types.UUID]*types.Strain, len(strs))
+			for id, s := range strs {
+				s := s
+				result[id] = &s
+			}
+			return result, nil
+		})),
+		attributes:  newLoader(byKey(db.GetAttributesFor)),
+		ingredients: newLoader(byKey(db.GetIngredientsFor)),
+		notes:       newLoader(byKey(db.GetNotesFor)),
+		photos:      newLoader(byKey(db.GetPhotosFor)),
+		tags:        newLoader(byKey(db.GetTagsFor)),
+		strainLCs:   newLoader(byKey(db.StrainLifecyclesFor)),
+		vendorStrain: newLoader(group(db.SelectAllStrains, func(s types.Strain) types.UUID {
+			return s.Vendor.UUID
+		})),
+		vendorSubs: newLoader(group(db.SelectAllSubstrates, func(s types.Substrate) types.UUID {
+			return s.Vendor.UUID
+		})),
+	}
+}
+
+// WithLoaders attaches a fresh set of loaders to a request context; anything
+// resolved with the returned context shares one cache
+func WithLoaders(ctx context.Context, db types.DB) context.Context {
+	return context.WithValue(ctx, loaderKey, newLoaders(db))
+}
+
+func getLoaders(ctx context.Context, db types.DB) *loaders {
+	if result, ok := ctx.Value(loaderKey).(*loaders); ok {
+		return result
+	}
+	// without a request-scoped cache nothing gets shared, but it still works
+	return newLoaders(db)
+}
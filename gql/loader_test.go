@@ -0,0 +1,111 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_loader(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		keys    []types.UUID
+		fetch   fetcher[string]
+		batches int32
+		result  map[types.UUID]string
+		err     error
+	}{
+		"happy_path": {
+			keys: []types.UUID{"0", "1", "2", "1", "0"},
+			fetch: func(_ context.Context, keys []types.UUID) (map[types.UUID]string, error) {
+				result := map[types.UUID]string{}
+				for _, k := range keys {
+					result[k] = "value " + string(k)
+				}
+				return result, nil
+			},
+			batches: 1,
+			result: map[types.UUID]string{
+				"0": "value 0",
+				"1": "value 1",
+				"2": "value 2",
+			},
+		},
+		"missing_key": {
+			keys: []types.UUID{"0"},
+			fetch: func(context.Context, []types.UUID) (map[types.UUID]string, error) {
+				return map[types.UUID]string{}, nil
+			},
+			batches: 1,
+			result:  map[types.UUID]string{"0": ""},
+			err:     sql.ErrNoRows,
+		},
+		"fetch_fails": {
+			keys: []types.UUID{"0", "1"},
+			fetch: func(context.Context, []types.UUID) (map[types.UUID]string, error) {
+				return nil, fmt.Errorf("some error")
+			},
+			batches: 1,
+			result:  map[types.UUID]string{"0": "", "1": ""},
+			err:     fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var batches int32
+			l := newLoader(func(ctx context.Context, keys []types.UUID) (map[types.UUID]string, error) {
+				atomic.AddInt32(&batches, 1)
+				return tc.fetch(ctx, keys)
+			})
+			l.wait = 50 * time.Millisecond // plenty of time for every goroutine to join the batch
+
+			var mtx sync.Mutex
+			var wg sync.WaitGroup
+			result := map[types.UUID]string{}
+			for _, k := range tc.keys {
+				wg.Add(1)
+				go func(k types.UUID) {
+					defer wg.Done()
+					v, err := l.load(context.Background(), k)
+					require.Equal(t, tc.err, err, name)
+					mtx.Lock()
+					result[k] = v
+					mtx.Unlock()
+				}(k)
+			}
+			wg.Wait()
+
+			require.Equal(t, tc.batches, batches)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_loadAll(t *testing.T) {
+	t.Parallel()
+
+	l := newLoader(func(context.Context, []types.UUID) (map[types.UUID][]string, error) {
+		return map[types.UUID][]string{"0": {"a", "b"}}, nil
+	})
+
+	result, err := l.loadAll(context.Background(), "0")
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b"}, result)
+
+	result, err = l.loadAll(context.Background(), "1")
+	require.Nil(t, err)
+	require.Nil(t, result)
+}
@@ -0,0 +1,1935 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+type (
+	root struct{ db types.DB }
+
+	vendorResolver struct {
+		r *root
+		v types.Vendor
+	}
+
+	strainResolver struct {
+		r *root
+		s types.Strain
+	}
+
+	attributeResolver struct{ a types.StrainAttribute }
+
+	filiationResolver struct {
+		r *root
+		f types.Filiation
+	}
+
+	substrateResolver struct {
+		r *root
+		s types.Substrate
+	}
+
+	ingredientResolver struct{ i types.Ingredient }
+
+	stageResolver struct{ s types.Stage }
+
+	eventTypeResolver struct {
+		r  *root
+		et types.EventType
+	}
+
+	followUpResolver struct {
+		r *root
+		f types.FollowUp
+	}
+
+	fieldResolver struct{ f types.Field }
+
+	measurementResolver struct{ m types.Measurement }
+
+	taskResolver struct {
+		r *root
+		t types.Task
+	}
+
+	// steps are only fetched when they're asked for and the protocol came from
+	// a list that didn't include them
+	protocolResolver struct {
+		r *root
+		p types.Protocol
+	}
+
+	protocolStepResolver struct {
+		r *root
+		s types.ProtocolStep
+	}
+
+	deviationReportResolver struct {
+		r  *root
+		dr types.DeviationReport
+	}
+
+	deviationResolver struct {
+		r *root
+		d types.Deviation
+	}
+
+	// members are only fetched when they're asked for and the batch came
+	// from a list that didn't include them
+	batchResolver struct {
+		r *root
+		b types.Batch
+	}
+
+	batchMemberResolver struct {
+		r *root
+		m types.BatchMember
+	}
+
+	// arms are only fetched when they're asked for and the experiment came
+	// from a list that didn't include them
+	experimentResolver struct {
+		r *root
+		e types.Experiment
+	}
+
+	// the same goes for an arm's lifecycles, which the report leaves out
+	experimentArmResolver struct {
+		r     *root
+		expID types.UUID
+		a     types.ExperimentArm
+	}
+
+	experimentReportResolver struct {
+		r   *root
+		rpt types.ExperimentReport
+	}
+
+	armSummaryResolver struct {
+		r     *root
+		expID types.UUID
+		s     types.ArmSummary
+	}
+
+	sampleSummaryResolver struct {
+		s types.SampleSummary
+	}
+
+	proportionSummaryResolver struct {
+		p types.ProportionSummary
+	}
+
+	eventResolver struct {
+		r *root
+		e types.Event
+	}
+
+	noteResolver struct{ n types.Note }
+
+	photoResolver struct {
+		r *root
+		p types.Photo
+	}
+
+	locationResolver struct {
+		r   *root
+		loc types.Location
+	}
+
+	environmentResolver struct{ e types.Environment }
+
+	occupancyResolver struct {
+		r *root
+		o types.Occupancy
+	}
+
+	// lifecycles and generations are expensive enough that they're only
+	// fetched once somebody asks for more than the id
+	lifecycleResolver struct {
+		r  *root
+		id types.UUID
+	}
+
+	generationResolver struct {
+		r  *root
+		id types.UUID
+	}
+
+	sourceResolver struct {
+		r *root
+		s types.Source
+	}
+
+	sourceTypeResolver struct{ st types.SourceType }
+
+	attributeDefinitionResolver struct{ d types.AttributeDefinition }
+
+	attributeFilterInput struct {
+		Name   string
+		Min    *float64
+		Max    *float64
+		Values *[]string
+	}
+
+	harvestResolver struct{ h types.Harvest }
+
+	flushResolver struct{ f types.Flush }
+
+	readingResolver struct {
+		r  *root
+		rd types.Reading
+	}
+
+	readingRollupResolver struct{ rr types.ReadingRollup }
+
+	readingStatsResolver struct{ s types.ReadingStats }
+
+	stageReadingsResolver struct {
+		r  *root
+		sr types.StageReadings
+	}
+
+	costsResolver struct{ c types.Costs }
+
+	costRollupResolver struct{ c types.CostRollup }
+
+	stageSpanResolver struct{ s types.StageSpan }
+
+	forecastResolver struct{ f types.Forecast }
+
+	estimateResolver struct{ e types.Estimate }
+
+	durationStatsResolver struct{ d types.DurationStats }
+
+	stageDurationsResolver struct{ d types.StageDurations }
+
+	measurementStatsResolver struct{ m types.MeasurementStats }
+
+	lineageResolver struct{ l types.Lineage }
+
+	lineageNodeResolver struct{ n types.LineageNode }
+
+	lineageEdgeResolver struct{ e types.LineageEdge }
+
+	contaminationRateResolver struct{ c types.ContaminationRate }
+
+	contaminationResolver struct {
+		r *root
+		c types.Contamination
+	}
+)
+
+func (r *root) readings(rds []types.Reading) []*readingResolver {
+	result := make([]*readingResolver, len(rds))
+	for i, rd := range rds {
+		result[i] = &readingResolver{r, rd}
+	}
+	return result
+}
+
+func readingRollups(rrs []types.ReadingRollup) []*readingRollupResolver {
+	result := make([]*readingRollupResolver, len(rrs))
+	for i, rr := range rrs {
+		result[i] = &readingRollupResolver{rr}
+	}
+	return result
+}
+
+func (r *root) loaders(ctx context.Context) *loaders {
+	return getLoaders(ctx, r.db)
+}
+
+func (r *root) Lifecycles(ctx context.Context, args struct {
+	Status *[]string
+	Tags   *[]string
+}) ([]*lifecycleResolver, error) {
+	lcs, err := r.db.SelectLifecycleIndex(tagged(ctx, args.Tags), types.GetContextCID(ctx), statuses(args.Status)...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*lifecycleResolver, len(lcs))
+	for i, lc := range lcs {
+		result[i] = &lifecycleResolver{r, lc.UUID}
+	}
+
+	return result, nil
+}
+
+func (r *root) Lifecycle(ctx context.Context, args struct{ ID graphql.ID }) (*lifecycleResolver, error) {
+	result := &lifecycleResolver{r, types.UUID(args.ID)}
+	if _, err := result.get(ctx); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *root) CostRollup(ctx context.Context, args struct {
+	By   string
+	From *graphql.Time
+	To   *graphql.Time
+}) ([]*costRollupResolver, error) {
+	rollups, err := r.db.CostRollup(ctx, types.Dimension(args.By), window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*costRollupResolver, len(rollups))
+	for i, c := range rollups {
+		result[i] = &costRollupResolver{c}
+	}
+
+	return result, nil
+}
+
+func (r *root) StageDurations(ctx context.Context, args struct {
+	By   string
+	From *graphql.Time
+	To   *graphql.Time
+}) ([]*stageDurationsResolver, error) {
+	durations, err := r.db.StageDurations(ctx, types.Dimension(args.By), window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*stageDurationsResolver, len(durations))
+	for i, d := range durations {
+		result[i] = &stageDurationsResolver{d}
+	}
+
+	return result, nil
+}
+
+func (r *root) MeasurementStats(ctx context.Context, args struct {
+	Field graphql.ID
+	By    string
+	From  *graphql.Time
+	To    *graphql.Time
+}) ([]*measurementStatsResolver, error) {
+	stats, err := r.db.MeasurementStats(ctx, types.UUID(args.Field), types.Dimension(args.By), window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*measurementStatsResolver, len(stats))
+	for i, m := range stats {
+		result[i] = &measurementStatsResolver{m}
+	}
+
+	return result, nil
+}
+
+func (r *root) ContaminationRates(ctx context.Context, args struct {
+	By   string
+	From *graphql.Time
+	To   *graphql.Time
+}) ([]*contaminationRateResolver, error) {
+	rates, err := r.db.ContaminationRates(ctx, types.Dimension(args.By), window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*contaminationRateResolver, len(rates))
+	for i, c := range rates {
+		result[i] = &contaminationRateResolver{c}
+	}
+
+	return result, nil
+}
+
+func (r *root) Readings(ctx context.Context, args struct {
+	Location string
+	From     *graphql.Time
+	To       *graphql.Time
+}) ([]*readingResolver, error) {
+	result, err := r.db.SelectReadings(ctx, args.Location, window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return r.readings(result), nil
+}
+
+func (r *root) ReadingRollups(ctx context.Context, args struct {
+	Location   string
+	Resolution string
+	From       *graphql.Time
+	To         *graphql.Time
+}) ([]*readingRollupResolver, error) {
+	result, err := r.db.ReadingRollups(ctx, args.Location, types.Resolution(args.Resolution), window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return readingRollups(result), nil
+}
+
+func (r *root) Tasks(ctx context.Context, args struct{ By *graphql.Time }) ([]*taskResolver, error) {
+	by := time.Now().Add(24 * time.Hour)
+	if args.By != nil {
+		by = args.By.Time
+	}
+
+	result, err := r.db.DueTasks(ctx, by, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return r.tasks(result), nil
+}
+
+func (r *root) tasks(ts []types.Task) []*taskResolver {
+	result := make([]*taskResolver, len(ts))
+	for i, t := range ts {
+		result[i] = &taskResolver{r, t}
+	}
+	return result
+}
+
+func (r *root) Contaminations(ctx context.Context, args struct {
+	By   string
+	Key  string
+	From *graphql.Time
+	To   *graphql.Time
+}) ([]*contaminationResolver, error) {
+	cs, err := r.db.Contaminations(ctx, types.Dimension(args.By), args.Key, window(args.From, args.To), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*contaminationResolver, len(cs))
+	for i, c := range cs {
+		result[i] = &contaminationResolver{r, c}
+	}
+
+	return result, nil
+}
+
+func (r *root) Generations(ctx context.Context, args struct {
+	Status *[]string
+	Tags   *[]string
+}) ([]*generationResolver, error) {
+	gens, err := r.db.SelectGenerationIndex(tagged(ctx, args.Tags), types.GetContextCID(ctx), statuses(args.Status)...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*generationResolver, len(gens))
+	for i, g := range gens {
+		result[i] = &generationResolver{r, g.UUID}
+	}
+
+	return result, nil
+}
+
+func (r *root) Generation(ctx context.Context, args struct{ ID graphql.ID }) (*generationResolver, error) {
+	result := &generationResolver{r, types.UUID(args.ID)}
+	if _, err := result.get(ctx); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *root) Strains(ctx context.Context, args struct {
+	Tags       *[]string
+	Depth      *int32
+	Root       *graphql.ID
+	Attributes *[]attributeFilterInput
+}) ([]*strainResolver, error) {
+	filial := types.FilialFilter{Root: (*types.UUID)(args.Root)}
+	if args.Depth != nil {
+		d := int(*args.Depth)
+		filial.Depth = &d
+	}
+
+	var attrs []types.AttributeFilter
+	if args.Attributes != nil {
+		for _, f := range *args.Attributes {
+			af := types.AttributeFilter{Name: f.Name, Min: f.Min, Max: f.Max}
+			if f.Values != nil {
+				af.Values = *f.Values
+			}
+			attrs = append(attrs, af)
+		}
+	}
+
+	strs, err := r.db.SelectAllStrains(
+		types.WithAttributes(types.WithFilial(tagged(ctx, args.Tags), filial), attrs...),
+		types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*strainResolver, len(strs))
+	for i, s := range strs {
+		result[i] = &strainResolver{r, s}
+	}
+
+	return result, nil
+}
+
+func (r *root) Strain(ctx context.Context, args struct{ ID graphql.ID }) (*strainResolver, error) {
+	s, err := r.loaders(ctx).strains.load(ctx, types.UUID(args.ID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &strainResolver{r, s}, nil
+}
+
+func (r *root) Ancestry(ctx context.Context, args struct {
+	Strain graphql.ID
+	Depth  *int32
+}) (*lineageResolver, error) {
+	return lineage(r.db.Ancestry(ctx, types.UUID(args.Strain), depth(args.Depth), types.GetContextCID(ctx)))
+}
+
+func (r *root) Descendants(ctx context.Context, args struct {
+	Strain graphql.ID
+	Depth  *int32
+}) (*lineageResolver, error) {
+	return lineage(r.db.Descendants(ctx, types.UUID(args.Strain), depth(args.Depth), types.GetContextCID(ctx)))
+}
+
+func (r *root) Pedigree(ctx context.Context, args struct {
+	Strain *graphql.ID
+	Depth  *int32
+}) (*lineageResolver, error) {
+	if args.Strain == nil {
+		return lineage(r.db.Library(ctx, types.GetContextCID(ctx)))
+	}
+	return lineage(r.db.Pedigree(ctx, types.UUID(*args.Strain), depth(args.Depth), types.GetContextCID(ctx)))
+}
+
+func lineage(l types.Lineage, err error) (*lineageResolver, error) {
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &lineageResolver{l}, nil
+}
+
+// depth is all the way when it's left out
+func depth(d *int32) int {
+	if d == nil {
+		return 0
+	}
+	return int(*d)
+}
+
+func (r *root) Substrates(ctx context.Context, args struct{ Tags *[]string }) ([]*substrateResolver, error) {
+	subs, err := r.db.SelectAllSubstrates(tagged(ctx, args.Tags), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*substrateResolver, len(subs))
+	for i, s := range subs {
+		result[i] = &substrateResolver{r, s}
+	}
+
+	return result, nil
+}
+
+func (r *root) Substrate(ctx context.Context, args struct{ ID graphql.ID }) (*substrateResolver, error) {
+	s, err := r.loaders(ctx).substrates.load(ctx, types.UUID(args.ID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &substrateResolver{r, s}, nil
+}
+
+func (r *root) Vendors(ctx context.Context) ([]*vendorResolver, error) {
+	vs, err := r.db.SelectAllVendors(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*vendorResolver, len(vs))
+	for i, v := range vs {
+		result[i] = &vendorResolver{r, v}
+	}
+
+	return result, nil
+}
+
+func (r *root) Vendor(ctx context.Context, args struct{ ID graphql.ID }) (*vendorResolver, error) {
+	v, err := r.loaders(ctx).vendors.load(ctx, types.UUID(args.ID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &vendorResolver{r, v}, nil
+}
+
+func (r *root) Locations(ctx context.Context) ([]*locationResolver, error) {
+	locs, err := r.db.SelectAllLocations(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*locationResolver, len(locs))
+	for i, loc := range locs {
+		result[i] = &locationResolver{r, loc}
+	}
+
+	return result, nil
+}
+
+func (r *root) Location(ctx context.Context, args struct{ ID graphql.ID }) (*locationResolver, error) {
+	loc, err := r.db.SelectLocation(ctx, types.UUID(args.ID), types.GetContextCID(ctx))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &locationResolver{r, loc}, nil
+}
+
+func (r *root) Protocols(ctx context.Context) ([]*protocolResolver, error) {
+	ps, err := r.db.SelectAllProtocols(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*protocolResolver, len(ps))
+	for i, p := range ps {
+		result[i] = &protocolResolver{r, p}
+	}
+
+	return result, nil
+}
+
+func (r *root) Protocol(ctx context.Context, args struct{ ID graphql.ID }) (*protocolResolver, error) {
+	p, err := r.db.SelectProtocol(ctx, types.UUID(args.ID), types.GetContextCID(ctx))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &protocolResolver{r, p}, nil
+}
+
+func (r *root) Batches(ctx context.Context) ([]*batchResolver, error) {
+	bs, err := r.db.SelectAllBatches(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*batchResolver, len(bs))
+	for i, b := range bs {
+		result[i] = &batchResolver{r, b}
+	}
+
+	return result, nil
+}
+
+func (r *root) Batch(ctx context.Context, args struct{ ID graphql.ID }) (*batchResolver, error) {
+	b, err := r.db.SelectBatch(ctx, types.UUID(args.ID), types.GetContextCID(ctx))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &batchResolver{r, b}, nil
+}
+
+func (r *root) Experiments(ctx context.Context) ([]*experimentResolver, error) {
+	es, err := r.db.SelectAllExperiments(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*experimentResolver, len(es))
+	for i, e := range es {
+		result[i] = &experimentResolver{r, e}
+	}
+
+	return result, nil
+}
+
+func (r *root) Experiment(ctx context.Context, args struct{ ID graphql.ID }) (*experimentResolver, error) {
+	e, err := r.db.SelectExperiment(ctx, types.UUID(args.ID), types.GetContextCID(ctx))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &experimentResolver{r, e}, nil
+}
+
+// observableProtocol is shared by lifecycles and generations
+func (r *root) observableProtocol(ctx context.Context, id types.UUID) (*protocolResolver, error) {
+	p, err := r.db.ObservableProtocol(ctx, id, types.GetContextCID(ctx))
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &protocolResolver{r, *p}, nil
+}
+
+func (r *root) deviations(ctx context.Context, id types.UUID) (*deviationReportResolver, error) {
+	if p, err := r.db.ObservableProtocol(ctx, id, types.GetContextCID(ctx)); err != nil || p == nil {
+		return nil, err
+	}
+
+	dr, err := r.db.ProtocolDeviations(ctx, id, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &deviationReportResolver{r, dr}, nil
+}
+
+func (r *root) EventTypes(ctx context.Context) ([]*eventTypeResolver, error) {
+	ets, err := r.db.SelectAllEventTypes(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*eventTypeResolver, len(ets))
+	for i, et := range ets {
+		result[i] = &eventTypeResolver{r, et}
+	}
+
+	return result, nil
+}
+
+func (r *root) Stages(ctx context.Context) ([]*stageResolver, error) {
+	stages, err := r.db.SelectAllStages(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*stageResolver, len(stages))
+	for i, s := range stages {
+		result[i] = &stageResolver{s}
+	}
+
+	return result, nil
+}
+
+func (r *root) SourceTypes(ctx context.Context) ([]*sourceTypeResolver, error) {
+	sts, err := r.db.SourceTypes(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*sourceTypeResolver, len(sts))
+	for i, st := range sts {
+		result[i] = &sourceTypeResolver{st}
+	}
+
+	return result, nil
+}
+
+func (r *root) AttributeDefinitions(ctx context.Context) ([]*attributeDefinitionResolver, error) {
+	defs, err := r.db.AttributeDefinitions(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*attributeDefinitionResolver, len(defs))
+	for i, d := range defs {
+		result[i] = &attributeDefinitionResolver{d}
+	}
+
+	return result, nil
+}
+
+func (r *root) Ingredients(ctx context.Context) ([]*ingredientResolver, error) {
+	ings, err := r.db.SelectAllIngredients(ctx, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return ingredientResolvers(ings), nil
+}
+
+func (v *vendorResolver) ID() graphql.ID  { return graphql.ID(v.v.UUID) }
+func (v *vendorResolver) Name() string    { return v.v.Name }
+func (v *vendorResolver) Website() string { return v.v.Website }
+
+func (v *vendorResolver) Strains(ctx context.Context) ([]*strainResolver, error) {
+	strs, err := v.r.loaders(ctx).vendorStrain.loadAll(ctx, v.v.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*strainResolver, len(strs))
+	for i, s := range strs {
+		result[i] = &strainResolver{v.r, s}
+	}
+
+	return result, nil
+}
+
+func (v *vendorResolver) Substrates(ctx context.Context) ([]*substrateResolver, error) {
+	subs, err := v.r.loaders(ctx).vendorSubs.loadAll(ctx, v.v.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*substrateResolver, len(subs))
+	for i, s := range subs {
+		result[i] = &substrateResolver{v.r, s}
+	}
+
+	return result, nil
+}
+
+func (s *strainResolver) ID() graphql.ID          { return graphql.ID(s.s.UUID) }
+func (s *strainResolver) Species() string         { return s.s.Species }
+func (s *strainResolver) Name() string            { return s.s.Name }
+func (s *strainResolver) Vendor() *vendorResolver { return &vendorResolver{s.r, s.s.Vendor} }
+func (s *strainResolver) Ctime() graphql.Time     { return graphql.Time{Time: s.s.CTime} }
+
+func (s *strainResolver) Dtime() *graphql.Time {
+	if s.s.DTime == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *s.s.DTime}
+}
+
+func (s *strainResolver) Attributes(ctx context.Context) ([]*attributeResolver, error) {
+	attrs, err := s.r.loaders(ctx).attributes.loadAll(ctx, s.s.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*attributeResolver, len(attrs))
+	for i, a := range attrs {
+		result[i] = &attributeResolver{a}
+	}
+
+	return result, nil
+}
+
+func (s *strainResolver) Generation() *generationResolver {
+	if s.s.Generation == nil {
+		return nil
+	}
+	return &generationResolver{s.r, s.s.Generation.UUID}
+}
+
+// Filiation only comes with strains that were selected as strains, anywhere
+// else it has to be looked up
+func (s *strainResolver) Filiation(ctx context.Context) (*filiationResolver, error) {
+	if s.s.Filiation != nil {
+		return &filiationResolver{s.r, *s.s.Filiation}, nil
+	}
+
+	str, err := s.r.loaders(ctx).strains.load(ctx, s.s.UUID)
+	if err != nil {
+		return nil, err
+	} else if str.Filiation == nil {
+		return &filiationResolver{s.r, types.NewFiliation(nil)}, nil
+	}
+	return &filiationResolver{s.r, *str.Filiation}, nil
+}
+
+func (s *strainResolver) Lifecycles(ctx context.Context) ([]*lifecycleResolver, error) {
+	ids, err := s.r.loaders(ctx).strainLCs.loadAll(ctx, s.s.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*lifecycleResolver, len(ids))
+	for i, id := range ids {
+		result[i] = &lifecycleResolver{s.r, id}
+	}
+
+	return result, nil
+}
+
+func (s *strainResolver) Photos(ctx context.Context) ([]*photoResolver, error) {
+	return s.r.photos(ctx, s.s.UUID)
+}
+
+func (s *strainResolver) Tags(ctx context.Context) ([]string, error) {
+	return s.r.tags(ctx, s.s.UUID)
+}
+
+func (f *filiationResolver) Depth() int32     { return int32(f.f.Depth) }
+func (f *filiationResolver) Notation() string { return f.f.Notation }
+
+func (f *filiationResolver) Root(ctx context.Context) (*strainResolver, error) {
+	if f.f.Root == nil {
+		return nil, nil
+	}
+
+	s, err := f.r.loaders(ctx).strains.load(ctx, *f.f.Root)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &strainResolver{f.r, s}, nil
+}
+
+func (a *attributeResolver) ID() graphql.ID { return graphql.ID(a.a.UUID) }
+func (a *attributeResolver) Name() string   { return a.a.Name }
+func (a *attributeResolver) Value() string  { return a.a.Value }
+
+func (s *substrateResolver) ID() graphql.ID          { return graphql.ID(s.s.UUID) }
+func (s *substrateResolver) Name() string            { return s.s.Name }
+func (s *substrateResolver) Type() string            { return string(s.s.Type) }
+func (s *substrateResolver) Vendor() *vendorResolver { return &vendorResolver{s.r, s.s.Vendor} }
+
+func (s *substrateResolver) Ingredients(ctx context.Context) ([]*ingredientResolver, error) {
+	ings, err := s.r.loaders(ctx).ingredients.loadAll(ctx, s.s.UUID)
+	if err != nil {
+		return nil, err
+	}
+	return ingredientResolvers(ings), nil
+}
+
+func (s *substrateResolver) Tags(ctx context.Context) ([]string, error) {
+	return s.r.tags(ctx, s.s.UUID)
+}
+
+func ingredientResolvers(ings []types.Ingredient) []*ingredientResolver {
+	result := make([]*ingredientResolver, len(ings))
+	for i, ing := range ings {
+		result[i] = &ingredientResolver{ing}
+	}
+	return result
+}
+
+func (i *ingredientResolver) ID() graphql.ID { return graphql.ID(i.i.UUID) }
+func (i *ingredientResolver) Name() string   { return i.i.Name }
+
+func (s *stageResolver) ID() graphql.ID { return graphql.ID(s.s.UUID) }
+func (s *stageResolver) Name() string   { return s.s.Name }
+
+func (et *eventTypeResolver) ID() graphql.ID        { return graphql.ID(et.et.UUID) }
+func (et *eventTypeResolver) Name() string          { return et.et.Name }
+func (et *eventTypeResolver) Severity() string      { return et.et.Severity }
+func (et *eventTypeResolver) Stage() *stageResolver { return &stageResolver{et.et.Stage} }
+
+func (et *eventTypeResolver) FollowUps(ctx context.Context) ([]*followUpResolver, error) {
+	e := et.et
+	if err := et.r.db.GetFollowUps(ctx, &e, types.GetContextCID(ctx)); err != nil {
+		return nil, err
+	}
+
+	result := make([]*followUpResolver, len(e.FollowUps))
+	for i, f := range e.FollowUps {
+		result[i] = &followUpResolver{et.r, f}
+	}
+
+	return result, nil
+}
+
+func (et *eventTypeResolver) Fields(ctx context.Context) ([]*fieldResolver, error) {
+	e := et.et
+	if err := et.r.db.GetFields(ctx, &e, types.GetContextCID(ctx)); err != nil {
+		return nil, err
+	}
+
+	result := make([]*fieldResolver, len(e.Fields))
+	for i, f := range e.Fields {
+		result[i] = &fieldResolver{f}
+	}
+
+	return result, nil
+}
+
+func (f *fieldResolver) ID() graphql.ID { return graphql.ID(f.f.UUID) }
+func (f *fieldResolver) Name() string   { return f.f.Name }
+func (f *fieldResolver) Type() string   { return string(f.f.Type) }
+func (f *fieldResolver) Unit() string   { return f.f.Unit }
+func (f *fieldResolver) Min() *float64  { return f.f.Min }
+func (f *fieldResolver) Max() *float64  { return f.f.Max }
+
+func (m *measurementResolver) Field() string { return m.m.Field }
+func (m *measurementResolver) Value() string { return fmt.Sprintf("%v", m.m.Value) }
+func (m *measurementResolver) Unit() string  { return m.m.Unit }
+
+func (f *followUpResolver) ID() graphql.ID { return graphql.ID(f.f.UUID) }
+func (f *followUpResolver) Name() string   { return f.f.Name }
+func (f *followUpResolver) Days() int32    { return int32(f.f.Days) }
+
+func (f *followUpResolver) Expects() *eventTypeResolver {
+	if f.f.Expects == nil {
+		return nil
+	}
+	return &eventTypeResolver{f.r, *f.f.Expects}
+}
+
+func (t *taskResolver) ID() graphql.ID              { return graphql.ID(t.t.UUID) }
+func (t *taskResolver) FollowUp() *followUpResolver { return &followUpResolver{t.r, t.t.FollowUp} }
+func (t *taskResolver) ObservableType() string      { return string(t.t.ObservableType) }
+func (t *taskResolver) ObservableId() graphql.ID    { return graphql.ID(t.t.ObservableUUID) }
+func (t *taskResolver) EventId() graphql.ID         { return graphql.ID(t.t.EventUUID) }
+func (t *taskResolver) Due() graphql.Time           { return graphql.Time{Time: t.t.Due} }
+func (t *taskResolver) Overdue() bool               { return t.t.Overdue }
+
+func (t *taskResolver) Done() *graphql.Time {
+	if t.t.Done == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t.t.Done}
+}
+
+func (t *taskResolver) ResultId() *graphql.ID {
+	if t.t.Result == nil {
+		return nil
+	}
+	id := graphql.ID(*t.t.Result)
+	return &id
+}
+
+func (p *protocolResolver) ID() graphql.ID { return graphql.ID(p.p.UUID) }
+func (p *protocolResolver) Name() string   { return p.p.Name }
+
+func (p *protocolResolver) Steps(ctx context.Context) ([]*protocolStepResolver, error) {
+	if p.p.Steps == nil {
+		result, err := p.r.db.SelectProtocol(ctx, p.p.UUID, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+		p.p.Steps = result.Steps
+	}
+
+	result := make([]*protocolStepResolver, len(p.p.Steps))
+	for i, s := range p.p.Steps {
+		result[i] = &protocolStepResolver{p.r, s}
+	}
+
+	return result, nil
+}
+
+func (s *protocolStepResolver) ID() graphql.ID { return graphql.ID(s.s.UUID) }
+func (s *protocolStepResolver) EventType() *eventTypeResolver {
+	return &eventTypeResolver{s.r, s.s.EventType}
+}
+func (s *protocolStepResolver) Day() int32       { return int32(s.s.Day) }
+func (s *protocolStepResolver) Tolerance() int32 { return int32(s.s.Tolerance) }
+
+func (b *batchResolver) ID() graphql.ID      { return graphql.ID(b.b.UUID) }
+func (b *batchResolver) Name() string        { return b.b.Name }
+func (b *batchResolver) Ctime() graphql.Time { return graphql.Time{Time: b.b.CTime} }
+
+func (b *batchResolver) Members(ctx context.Context) ([]*batchMemberResolver, error) {
+	if b.b.Members == nil {
+		result, err := b.r.db.SelectBatch(ctx, b.b.UUID, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+		b.b.Members = result.Members
+	}
+
+	result := make([]*batchMemberResolver, len(b.b.Members))
+	for i, m := range b.b.Members {
+		result[i] = &batchMemberResolver{b.r, m}
+	}
+
+	return result, nil
+}
+
+func (m *batchMemberResolver) Lifecycle() *lifecycleResolver {
+	return &lifecycleResolver{m.r, m.m.Lifecycle.UUID}
+}
+
+func (m *batchMemberResolver) Excluded() *graphql.Time {
+	if m.m.Excluded == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *m.m.Excluded}
+}
+
+func (m *batchMemberResolver) InStep() bool { return m.m.InStep() }
+
+func (e *experimentResolver) ID() graphql.ID      { return graphql.ID(e.e.UUID) }
+func (e *experimentResolver) Name() string        { return e.e.Name }
+func (e *experimentResolver) Variable() string    { return e.e.Variable }
+func (e *experimentResolver) Ctime() graphql.Time { return graphql.Time{Time: e.e.CTime} }
+
+func (e *experimentResolver) Arms(ctx context.Context) ([]*experimentArmResolver, error) {
+	if e.e.Arms == nil {
+		result, err := e.r.db.SelectExperiment(ctx, e.e.UUID, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+		e.e.Arms = result.Arms
+	}
+
+	result := make([]*experimentArmResolver, len(e.e.Arms))
+	for i, a := range e.e.Arms {
+		result[i] = &experimentArmResolver{e.r, e.e.UUID, a}
+	}
+
+	return result, nil
+}
+
+func (e *experimentResolver) Report(ctx context.Context) (*experimentReportResolver, error) {
+	result, err := e.r.db.ExperimentReport(ctx, e.e.UUID, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &experimentReportResolver{e.r, result}, nil
+}
+
+func (a *experimentArmResolver) ID() graphql.ID { return graphql.ID(a.a.UUID) }
+func (a *experimentArmResolver) Name() string   { return a.a.Name }
+func (a *experimentArmResolver) Control() bool  { return a.a.Control }
+
+func (a *experimentArmResolver) Lifecycles(ctx context.Context) ([]*lifecycleResolver, error) {
+	if a.a.Lifecycles == nil {
+		e, err := a.r.db.SelectExperiment(ctx, a.expID, types.GetContextCID(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, arm := range e.Arms {
+			if arm.UUID == a.a.UUID {
+				a.a.Lifecycles = arm.Lifecycles
+				break
+			}
+		}
+	}
+
+	result := make([]*lifecycleResolver, len(a.a.Lifecycles))
+	for i, lc := range a.a.Lifecycles {
+		result[i] = &lifecycleResolver{a.r, lc.UUID}
+	}
+
+	return result, nil
+}
+
+func (rpt *experimentReportResolver) Units() string { return string(rpt.rpt.Units) }
+
+func (rpt *experimentReportResolver) Arms() []*armSummaryResolver {
+	result := make([]*armSummaryResolver, len(rpt.rpt.Arms))
+	for i, s := range rpt.rpt.Arms {
+		result[i] = &armSummaryResolver{rpt.r, rpt.rpt.Experiment.UUID, s}
+	}
+	return result
+}
+
+func (s *armSummaryResolver) Arm() *experimentArmResolver {
+	return &experimentArmResolver{s.r, s.expID, s.s.Arm}
+}
+func (s *armSummaryResolver) Yield() *sampleSummaryResolver {
+	return &sampleSummaryResolver{s.s.Yield}
+}
+func (s *armSummaryResolver) Colonization() *sampleSummaryResolver {
+	return &sampleSummaryResolver{s.s.Colonization}
+}
+func (s *armSummaryResolver) Contamination() *proportionSummaryResolver {
+	return &proportionSummaryResolver{s.s.Contamination}
+}
+
+func (s *sampleSummaryResolver) N() int32        { return int32(s.s.N) }
+func (s *sampleSummaryResolver) Mean() float64   { return s.s.Mean }
+func (s *sampleSummaryResolver) StdDev() float64 { return s.s.StdDev }
+func (s *sampleSummaryResolver) P() *float64     { return s.s.P }
+
+func (p *proportionSummaryResolver) N() int32      { return int32(p.p.N) }
+func (p *proportionSummaryResolver) Count() int32  { return int32(p.p.Count) }
+func (p *proportionSummaryResolver) Rate() float64 { return p.p.Rate }
+func (p *proportionSummaryResolver) P() *float64   { return p.p.P }
+
+func (dr *deviationReportResolver) Protocol() *protocolResolver {
+	return &protocolResolver{dr.r, dr.dr.Protocol}
+}
+
+func (dr *deviationReportResolver) Anchor() graphql.Time { return graphql.Time{Time: dr.dr.Anchor} }
+
+func (dr *deviationReportResolver) Deviations() []*deviationResolver {
+	result := make([]*deviationResolver, len(dr.dr.Deviations))
+	for i, d := range dr.dr.Deviations {
+		result[i] = &deviationResolver{dr.r, d}
+	}
+	return result
+}
+
+func (d *deviationResolver) Kind() string { return string(d.d.Kind) }
+
+func (d *deviationResolver) Step() *protocolStepResolver {
+	if d.d.Step == nil {
+		return nil
+	}
+	return &protocolStepResolver{d.r, *d.d.Step}
+}
+
+func (d *deviationResolver) Event() *eventResolver {
+	if d.d.Event == nil {
+		return nil
+	}
+	return &eventResolver{d.r, *d.d.Event}
+}
+
+func (d *deviationResolver) Expected() *graphql.Time {
+	if d.d.Expected == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *d.d.Expected}
+}
+
+func (d *deviationResolver) Days() *int32 {
+	if d.d.Days == nil {
+		return nil
+	}
+	days := int32(*d.d.Days)
+	return &days
+}
+
+func (e *eventResolver) ID() graphql.ID                { return graphql.ID(e.e.UUID) }
+func (e *eventResolver) Temperature() float64          { return float64(e.e.Temperature) }
+func (e *eventResolver) Humidity() int32               { return int32(e.e.Humidity) }
+func (e *eventResolver) EventType() *eventTypeResolver { return &eventTypeResolver{e.r, e.e.EventType} }
+func (e *eventResolver) Units() string                 { return string(e.e.Units) }
+func (e *eventResolver) OccurredAt() graphql.Time      { return graphql.Time{Time: e.e.OccurredAt} }
+func (e *eventResolver) Mtime() graphql.Time           { return graphql.Time{Time: e.e.MTime} }
+func (e *eventResolver) Ctime() graphql.Time           { return graphql.Time{Time: e.e.CTime} }
+
+func (e *eventResolver) Measurements() []*measurementResolver {
+	result := make([]*measurementResolver, len(e.e.Measurements))
+	for i, m := range e.e.Measurements {
+		result[i] = &measurementResolver{m}
+	}
+	return result
+}
+
+func (e *eventResolver) Notes(ctx context.Context) ([]*noteResolver, error) {
+	return e.r.notes(ctx, e.e.UUID)
+}
+
+func (e *eventResolver) Photos(ctx context.Context) ([]*photoResolver, error) {
+	return e.r.photos(ctx, e.e.UUID)
+}
+
+func (e *eventResolver) Tags(ctx context.Context) ([]string, error) {
+	return e.r.tags(ctx, e.e.UUID)
+}
+
+func (r *root) events(evs []types.Event) []*eventResolver {
+	result := make([]*eventResolver, len(evs))
+	for i, e := range evs {
+		result[i] = &eventResolver{r, e}
+	}
+	return result
+}
+
+func (n *noteResolver) ID() graphql.ID      { return graphql.ID(n.n.UUID) }
+func (n *noteResolver) Note() string        { return n.n.Note }
+func (n *noteResolver) Mtime() graphql.Time { return graphql.Time{Time: n.n.MTime} }
+func (n *noteResolver) Ctime() graphql.Time { return graphql.Time{Time: n.n.CTime} }
+
+func (r *root) KnownTags(ctx context.Context) ([]string, error) {
+	return r.db.KnownTags(ctx, types.GetContextCID(ctx))
+}
+
+func (r *root) tags(ctx context.Context, id types.UUID) ([]string, error) {
+	result, err := r.loaders(ctx).tags.loadAll(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if result == nil {
+		return []string{}, nil
+	}
+	return result, nil
+}
+
+func (r *root) notes(ctx context.Context, id types.UUID) ([]*noteResolver, error) {
+	notes, err := r.loaders(ctx).notes.loadAll(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*noteResolver, len(notes))
+	for i, n := range notes {
+		result[i] = &noteResolver{n}
+	}
+
+	return result, nil
+}
+
+func (l *locationResolver) ID() graphql.ID               { return graphql.ID(l.loc.UUID) }
+func (l *locationResolver) Name() string                 { return l.loc.Name }
+func (l *locationResolver) Kind() string                 { return string(l.loc.Kind) }
+func (l *locationResolver) Capacity() int32              { return int32(l.loc.Capacity) }
+func (l *locationResolver) Target() *environmentResolver { return &environmentResolver{l.loc.Target} }
+
+func (l *locationResolver) Occupancy(ctx context.Context) (*occupancyResolver, error) {
+	o, err := l.r.db.LocationOccupancy(ctx, l.loc.UUID, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &occupancyResolver{l.r, o}, nil
+}
+
+func (e *environmentResolver) MinTemperature() *float64 { return optFloat(e.e.MinTemperature) }
+func (e *environmentResolver) MaxTemperature() *float64 { return optFloat(e.e.MaxTemperature) }
+func (e *environmentResolver) MinHumidity() *float64    { return optFloat(e.e.MinHumidity) }
+func (e *environmentResolver) MaxHumidity() *float64    { return optFloat(e.e.MaxHumidity) }
+func (e *environmentResolver) MaxCO2() *float64         { return optFloat(e.e.MaxCO2) }
+
+func (o *occupancyResolver) Occupied() int32 { return int32(o.o.Occupied) }
+func (o *occupancyResolver) Fill() *float64  { return optFloat(o.o.Fill) }
+
+func (o *occupancyResolver) Lifecycles() []*lifecycleResolver {
+	result := make([]*lifecycleResolver, len(o.o.Lifecycles))
+	for i, lc := range o.o.Lifecycles {
+		result[i] = &lifecycleResolver{o.r, lc.UUID}
+	}
+	return result
+}
+
+func (p *photoResolver) ID() graphql.ID      { return graphql.ID(p.p.UUID) }
+func (p *photoResolver) Image() string       { return p.p.Filename }
+func (p *photoResolver) Mtime() graphql.Time { return graphql.Time{Time: p.p.MTime} }
+func (p *photoResolver) Ctime() graphql.Time { return graphql.Time{Time: p.p.CTime} }
+
+func (p *photoResolver) Notes(ctx context.Context) ([]*noteResolver, error) {
+	return p.r.notes(ctx, p.p.UUID)
+}
+
+func (p *photoResolver) Tags(ctx context.Context) ([]string, error) {
+	return p.r.tags(ctx, p.p.UUID)
+}
+
+func (r *root) photos(ctx context.Context, id types.UUID) ([]*photoResolver, error) {
+	photos, err := r.loaders(ctx).photos.loadAll(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*photoResolver, len(photos))
+	for i, p := range photos {
+		result[i] = &photoResolver{r, p}
+	}
+
+	return result, nil
+}
+
+func (lc *lifecycleResolver) get(ctx context.Context) (types.Lifecycle, error) {
+	return lc.r.loaders(ctx).lifecycles.load(ctx, lc.id)
+}
+
+func (lc *lifecycleResolver) ID() graphql.ID { return graphql.ID(lc.id) }
+
+func (lc *lifecycleResolver) Location(ctx context.Context) (*locationResolver, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &locationResolver{lc.r, result.Location}, nil
+}
+
+func (lc *lifecycleResolver) StrainCost(ctx context.Context) (float64, error) {
+	result, err := lc.get(ctx)
+	return float64(result.StrainCost), err
+}
+
+func (lc *lifecycleResolver) GrainCost(ctx context.Context) (float64, error) {
+	result, err := lc.get(ctx)
+	return float64(result.GrainCost), err
+}
+
+func (lc *lifecycleResolver) BulkCost(ctx context.Context) (float64, error) {
+	result, err := lc.get(ctx)
+	return float64(result.BulkCost), err
+}
+
+func (lc *lifecycleResolver) Yield(ctx context.Context) (float64, error) {
+	result, err := lc.get(ctx)
+	return float64(result.Yield), err
+}
+
+func (lc *lifecycleResolver) Units(ctx context.Context) (string, error) {
+	result, err := lc.get(ctx)
+	return string(result.Units), err
+}
+
+func (lc *lifecycleResolver) Count(ctx context.Context) (int32, error) {
+	result, err := lc.get(ctx)
+	return int32(result.Count), err
+}
+
+func (lc *lifecycleResolver) Gross(ctx context.Context) (float64, error) {
+	result, err := lc.get(ctx)
+	return float64(result.Gross), err
+}
+
+func (lc *lifecycleResolver) Strain(ctx context.Context) (*strainResolver, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &strainResolver{lc.r, result.Strain}, nil
+}
+
+func (lc *lifecycleResolver) GrainSubstrate(ctx context.Context) (*substrateResolver, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &substrateResolver{lc.r, result.GrainSubstrate}, nil
+}
+
+func (lc *lifecycleResolver) BulkSubstrate(ctx context.Context) (*substrateResolver, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &substrateResolver{lc.r, result.BulkSubstrate}, nil
+}
+
+func (lc *lifecycleResolver) Events(ctx context.Context) ([]*eventResolver, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return lc.r.events(result.Events), nil
+}
+
+func (lc *lifecycleResolver) Notes(ctx context.Context) ([]*noteResolver, error) {
+	return lc.r.notes(ctx, lc.id)
+}
+
+func (lc *lifecycleResolver) Tags(ctx context.Context) ([]string, error) {
+	return lc.r.tags(ctx, lc.id)
+}
+
+func (lc *lifecycleResolver) Harvests(ctx context.Context) ([]*harvestResolver, error) {
+	result, err := lc.harvested(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	harvests := make([]*harvestResolver, len(result.Harvests))
+	for i, h := range result.Harvests {
+		harvests[i] = &harvestResolver{h}
+	}
+
+	return harvests, nil
+}
+
+func (lc *lifecycleResolver) Flushes(ctx context.Context) ([]*flushResolver, error) {
+	result, err := lc.harvested(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	flushes := types.NewFlushes(result.CTime, result.Harvests)
+	resolvers := make([]*flushResolver, len(flushes))
+	for i, f := range flushes {
+		resolvers[i] = &flushResolver{f}
+	}
+
+	return resolvers, nil
+}
+
+// harvested is a copy of the cached lifecycle, so harvests aren't loaded
+// unless somebody asks for them
+func (lc *lifecycleResolver) harvested(ctx context.Context) (types.Lifecycle, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return result, err
+	}
+	err = lc.r.db.GetHarvests(ctx, &result, types.GetContextCID(ctx))
+	return result, err
+}
+
+func (lc *lifecycleResolver) Costs(ctx context.Context) (*costsResolver, error) {
+	result, err := lc.get(ctx)
+	return &costsResolver{types.NewCosts(result)}, err
+}
+
+func (lc *lifecycleResolver) Stages(ctx context.Context) ([]*stageSpanResolver, error) {
+	result, err := lc.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stageSpans(result.Events), nil
+}
+
+func (lc *lifecycleResolver) Tasks(ctx context.Context) ([]*taskResolver, error) {
+	result, err := lc.r.db.ObservableTasks(ctx, lc.id, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return lc.r.tasks(result), nil
+}
+
+func (lc *lifecycleResolver) Protocol(ctx context.Context) (*protocolResolver, error) {
+	return lc.r.observableProtocol(ctx, lc.id)
+}
+
+func (lc *lifecycleResolver) Deviations(ctx context.Context) (*deviationReportResolver, error) {
+	return lc.r.deviations(ctx, lc.id)
+}
+
+func (lc *lifecycleResolver) Status(ctx context.Context) (string, error) {
+	result, err := lc.get(ctx)
+	return string(result.Status), err
+}
+
+func (lc *lifecycleResolver) Forecast(ctx context.Context) (*forecastResolver, error) {
+	result, err := lc.r.db.LifecycleForecast(ctx, lc.id, types.GetContextCID(ctx))
+	return &forecastResolver{result}, err
+}
+
+func (lc *lifecycleResolver) Readings(ctx context.Context) ([]*readingResolver, error) {
+	result, err := lc.r.db.LifecycleReadings(ctx, lc.id, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return lc.r.readings(result), nil
+}
+
+func (lc *lifecycleResolver) ReadingRollups(ctx context.Context, args struct{ Resolution string }) ([]*readingRollupResolver, error) {
+	result, err := lc.r.db.LifecycleReadingRollups(ctx, lc.id, types.Resolution(args.Resolution), types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return readingRollups(result), nil
+}
+
+func (lc *lifecycleResolver) StageReadings(ctx context.Context) ([]*stageReadingsResolver, error) {
+	srs, err := lc.r.db.StageReadings(ctx, lc.id, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*stageReadingsResolver, len(srs))
+	for i, sr := range srs {
+		result[i] = &stageReadingsResolver{lc.r, sr}
+	}
+
+	return result, nil
+}
+
+func (lc *lifecycleResolver) Mtime(ctx context.Context) (graphql.Time, error) {
+	result, err := lc.get(ctx)
+	return graphql.Time{Time: result.MTime}, err
+}
+
+func (lc *lifecycleResolver) Ctime(ctx context.Context) (graphql.Time, error) {
+	result, err := lc.get(ctx)
+	return graphql.Time{Time: result.CTime}, err
+}
+
+func (g *generationResolver) get(ctx context.Context) (types.Generation, error) {
+	return g.r.loaders(ctx).generations.load(ctx, g.id)
+}
+
+func (g *generationResolver) ID() graphql.ID { return graphql.ID(g.id) }
+
+func (g *generationResolver) PlatingSubstrate(ctx context.Context) (*substrateResolver, error) {
+	result, err := g.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &substrateResolver{g.r, result.PlatingSubstrate}, nil
+}
+
+func (g *generationResolver) LiquidSubstrate(ctx context.Context) (*substrateResolver, error) {
+	result, err := g.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &substrateResolver{g.r, result.LiquidSubstrate}, nil
+}
+
+func (g *generationResolver) Sources(ctx context.Context) ([]*sourceResolver, error) {
+	gen, err := g.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*sourceResolver, len(gen.Sources))
+	for i, s := range gen.Sources {
+		result[i] = &sourceResolver{g.r, s}
+	}
+
+	return result, nil
+}
+
+func (g *generationResolver) Events(ctx context.Context) ([]*eventResolver, error) {
+	result, err := g.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.r.events(result.Events), nil
+}
+
+func (g *generationResolver) Status(ctx context.Context) (string, error) {
+	result, err := g.get(ctx)
+	return string(result.Status), err
+}
+
+func (g *generationResolver) Tasks(ctx context.Context) ([]*taskResolver, error) {
+	result, err := g.r.db.ObservableTasks(ctx, g.id, types.GetContextCID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return g.r.tasks(result), nil
+}
+
+func (g *generationResolver) Protocol(ctx context.Context) (*protocolResolver, error) {
+	return g.r.observableProtocol(ctx, g.id)
+}
+
+func (g *generationResolver) Deviations(ctx context.Context) (*deviationReportResolver, error) {
+	return g.r.deviations(ctx, g.id)
+}
+
+func (g *generationResolver) Stages(ctx context.Context) ([]*stageSpanResolver, error) {
+	result, err := g.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stageSpans(result.Events), nil
+}
+
+func (g *generationResolver) Notes(ctx context.Context) ([]*noteResolver, error) {
+	return g.r.notes(ctx, g.id)
+}
+
+func (g *generationResolver) Tags(ctx context.Context) ([]string, error) {
+	return g.r.tags(ctx, g.id)
+}
+
+func (g *generationResolver) Progeny(ctx context.Context) (*strainResolver, error) {
+	result, err := g.r.loaders(ctx).progeny.loadAll(ctx, g.id)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return &strainResolver{g.r, *result}, nil
+}
+
+func (g *generationResolver) Mtime(ctx context.Context) (graphql.Time, error) {
+	result, err := g.get(ctx)
+	return graphql.Time{Time: result.MTime}, err
+}
+
+func (g *generationResolver) Ctime(ctx context.Context) (graphql.Time, error) {
+	result, err := g.get(ctx)
+	return graphql.Time{Time: result.CTime}, err
+}
+
+func (g *generationResolver) Dtime(ctx context.Context) (*graphql.Time, error) {
+	result, err := g.get(ctx)
+	if err != nil || result.DTime == nil {
+		return nil, err
+	}
+	return &graphql.Time{Time: *result.DTime}, nil
+}
+
+func (s *sourceResolver) ID() graphql.ID          { return graphql.ID(s.s.UUID) }
+func (s *sourceResolver) Type() string            { return s.s.Type }
+func (s *sourceResolver) Strain() *strainResolver { return &strainResolver{s.r, s.s.Strain} }
+
+func (s *sourceResolver) Lifecycle() *lifecycleResolver {
+	if s.s.Lifecycle == nil {
+		return nil
+	}
+	return &lifecycleResolver{s.r, s.s.Lifecycle.UUID}
+}
+
+func (s *sourceResolver) Event() *eventResolver {
+	if s.s.Lifecycle == nil || len(s.s.Lifecycle.Events) == 0 {
+		return nil
+	}
+	return &eventResolver{s.r, s.s.Lifecycle.Events[0]}
+}
+
+func (st *sourceTypeResolver) Name() string  { return st.st.Name }
+func (st *sourceTypeResolver) Max() int32    { return int32(st.st.Max) }
+func (st *sourceTypeResolver) Mixable() bool { return st.st.Mixable }
+func (st *sourceTypeResolver) Clonal() bool  { return st.st.Clonal }
+
+func (d *attributeDefinitionResolver) ID() graphql.ID { return graphql.ID(d.d.UUID) }
+func (d *attributeDefinitionResolver) Name() string   { return d.d.Name }
+func (d *attributeDefinitionResolver) Type() string   { return string(d.d.Type) }
+func (d *attributeDefinitionResolver) Unit() string   { return d.d.Unit }
+
+func (d *attributeDefinitionResolver) Allowed() []string {
+	if d.d.Allowed == nil {
+		return []string{}
+	}
+	return d.d.Allowed
+}
+
+func (d *attributeDefinitionResolver) Synonyms() []string {
+	if d.d.Synonyms == nil {
+		return []string{}
+	}
+	return d.d.Synonyms
+}
+
+func (h *harvestResolver) ID() graphql.ID       { return graphql.ID(h.h.UUID) }
+func (h *harvestResolver) Date() graphql.Time   { return graphql.Time{Time: h.h.Date} }
+func (h *harvestResolver) FreshWeight() float64 { return float64(h.h.FreshWeight) }
+func (h *harvestResolver) DryWeight() float64   { return float64(h.h.DryWeight) }
+func (h *harvestResolver) Count() int32         { return int32(h.h.Count) }
+func (h *harvestResolver) Grade() string        { return h.h.Grade }
+func (h *harvestResolver) Units() string        { return string(h.h.Units) }
+func (h *harvestResolver) Mtime() graphql.Time  { return graphql.Time{Time: h.h.MTime} }
+func (h *harvestResolver) Ctime() graphql.Time  { return graphql.Time{Time: h.h.CTime} }
+
+func (h *harvestResolver) Notes() []*noteResolver {
+	result := make([]*noteResolver, len(h.h.Notes))
+	for i, n := range h.h.Notes {
+		result[i] = &noteResolver{n}
+	}
+	return result
+}
+
+func (f *flushResolver) Number() int32             { return int32(f.f.Number) }
+func (f *flushResolver) Harvest() *harvestResolver { return &harvestResolver{f.f.Harvest} }
+func (f *flushResolver) Interval() float64         { return f.f.Interval.Hours() }
+func (f *flushResolver) Share() *float64           { return optFloat(f.f.Share) }
+func (f *flushResolver) Moisture() *float64        { return optFloat(f.f.Moisture) }
+func (f *flushResolver) MeanWeight() *float64      { return optFloat(f.f.MeanWeight) }
+
+func (rd *readingResolver) Sensor() string        { return rd.rd.Sensor }
+func (rd *readingResolver) Location() string      { return rd.rd.Location }
+func (rd *readingResolver) At() graphql.Time      { return graphql.Time{Time: rd.rd.At} }
+func (rd *readingResolver) Temperature() *float64 { return optFloat(rd.rd.Temperature) }
+func (rd *readingResolver) Humidity() *float64    { return optFloat(rd.rd.Humidity) }
+func (rd *readingResolver) Co2() *float64         { return optFloat(rd.rd.CO2) }
+func (rd *readingResolver) Light() *float64       { return optFloat(rd.rd.Light) }
+
+func (rd *readingResolver) Lifecycle() *lifecycleResolver {
+	if rd.rd.Lifecycle == nil {
+		return nil
+	}
+	return &lifecycleResolver{rd.r, *rd.rd.Lifecycle}
+}
+
+func (rr *readingRollupResolver) Bucket() graphql.Time { return graphql.Time{Time: rr.rr.Bucket} }
+func (rr *readingRollupResolver) Count() int32         { return int32(rr.rr.Count) }
+
+func (rr *readingRollupResolver) Temperature() *readingStatsResolver {
+	return &readingStatsResolver{rr.rr.Temperature}
+}
+
+func (rr *readingRollupResolver) Humidity() *readingStatsResolver {
+	return &readingStatsResolver{rr.rr.Humidity}
+}
+
+func (rr *readingRollupResolver) Co2() *readingStatsResolver {
+	return &readingStatsResolver{rr.rr.CO2}
+}
+
+func (rr *readingRollupResolver) Light() *readingStatsResolver {
+	return &readingStatsResolver{rr.rr.Light}
+}
+
+func (s *readingStatsResolver) Count() int32   { return int32(s.s.Count) }
+func (s *readingStatsResolver) Min() *float64  { return optFloat(s.s.Min) }
+func (s *readingStatsResolver) Max() *float64  { return optFloat(s.s.Max) }
+func (s *readingStatsResolver) Mean() *float64 { return optFloat(s.s.Mean) }
+
+func (sr *stageReadingsResolver) Span() *stageSpanResolver {
+	return &stageSpanResolver{sr.sr.StageSpan}
+}
+
+func (sr *stageReadingsResolver) Readings() []*readingResolver {
+	return sr.r.readings(sr.sr.Readings)
+}
+
+func (c *costsResolver) Strain() float64 { return float64(c.c.Strain) }
+func (c *costsResolver) Grain() float64  { return float64(c.c.Grain) }
+func (c *costsResolver) Bulk() float64   { return float64(c.c.Bulk) }
+func (c *costsResolver) Total() float64  { return float64(c.c.Total) }
+func (c *costsResolver) Yield() float64  { return float64(c.c.Yield) }
+func (c *costsResolver) Gross() float64  { return float64(c.c.Gross) }
+
+func (c *costsResolver) PerYieldGram() *float64 { return optFloat(c.c.PerYieldGram) }
+func (c *costsResolver) PerGrossGram() *float64 { return optFloat(c.c.PerGrossGram) }
+func (c *costsResolver) Moisture() *float64     { return optFloat(c.c.Moisture) }
+
+func optFloat(f *float32) *float64 {
+	if f == nil {
+		return nil
+	}
+	result := float64(*f)
+	return &result
+}
+
+func (c *costRollupResolver) Dimension() string     { return string(c.c.Dimension) }
+func (c *costRollupResolver) Key() string           { return c.c.Key }
+func (c *costRollupResolver) Label() string         { return c.c.Label }
+func (c *costRollupResolver) Count() int32          { return int32(c.c.Count) }
+func (c *costRollupResolver) Costs() *costsResolver { return &costsResolver{c.c.Costs} }
+
+func statuses(names *[]string) []types.Status {
+	if names == nil {
+		return nil
+	}
+	result := make([]types.Status, len(*names))
+	for i, n := range *names {
+		result[i] = types.Status(n)
+	}
+	return result
+}
+
+// tagged only filters by tags when they're asked for; loaders get the
+// request's own context, so nothing else is filtered
+func tagged(ctx context.Context, tags *[]string) context.Context {
+	if tags == nil {
+		return ctx
+	}
+	return types.WithTags(ctx, *tags...)
+}
+
+func window(from, to *graphql.Time) types.Window {
+	result := types.Window{}
+	if from != nil {
+		result.From = &from.Time
+	}
+	if to != nil {
+		result.To = &to.Time
+	}
+	return result
+}
+
+// the events are already loaded, so there's no need to go back to the database
+func stageSpans(evs []types.Event) []*stageSpanResolver {
+	spans := types.NewStageSpans(evs)
+	result := make([]*stageSpanResolver, len(spans))
+	for i, s := range spans {
+		result[i] = &stageSpanResolver{s}
+	}
+	return result
+}
+
+func (s *stageSpanResolver) Stage() *stageResolver { return &stageResolver{s.s.Stage} }
+func (s *stageSpanResolver) Begin() graphql.Time   { return graphql.Time{Time: s.s.Begin} }
+
+func (s *stageSpanResolver) End() *graphql.Time {
+	if s.s.End == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *s.s.End}
+}
+
+func (f *forecastResolver) Anchor() graphql.Time         { return graphql.Time{Time: f.f.Anchor} }
+func (f *forecastResolver) Colonized() *estimateResolver { return estimate(f.f.Colonized) }
+func (f *forecastResolver) Pinning() *estimateResolver   { return estimate(f.f.Pinning) }
+func (f *forecastResolver) Harvest() *estimateResolver   { return estimate(f.f.Harvest) }
+
+func (f *forecastResolver) Basis() []string {
+	result := make([]string, len(f.f.Basis))
+	for i, d := range f.f.Basis {
+		result[i] = string(d)
+	}
+	return result
+}
+
+func estimate(e *types.Estimate) *estimateResolver {
+	if e == nil {
+		return nil
+	}
+	return &estimateResolver{*e}
+}
+
+func (e *estimateResolver) Samples() int32         { return int32(e.e.Samples) }
+func (e *estimateResolver) Expected() graphql.Time { return graphql.Time{Time: e.e.Expected} }
+func (e *estimateResolver) Low() graphql.Time      { return graphql.Time{Time: e.e.Low} }
+func (e *estimateResolver) High() graphql.Time     { return graphql.Time{Time: e.e.High} }
+
+func (e *estimateResolver) Actual() *graphql.Time {
+	if e.e.Actual == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *e.e.Actual}
+}
+
+func (d *durationStatsResolver) Count() int32    { return int32(d.d.Count) }
+func (d *durationStatsResolver) Min() float64    { return d.d.Min.Hours() }
+func (d *durationStatsResolver) P25() float64    { return d.d.P25.Hours() }
+func (d *durationStatsResolver) Median() float64 { return d.d.Median.Hours() }
+func (d *durationStatsResolver) P75() float64    { return d.d.P75.Hours() }
+func (d *durationStatsResolver) P90() float64    { return d.d.P90.Hours() }
+func (d *durationStatsResolver) Max() float64    { return d.d.Max.Hours() }
+func (d *durationStatsResolver) Mean() float64   { return d.d.Mean.Hours() }
+
+func (d *stageDurationsResolver) Dimension() string { return string(d.d.Dimension) }
+func (d *stageDurationsResolver) Key() string       { return d.d.Key }
+func (d *stageDurationsResolver) Label() string     { return d.d.Label }
+
+func (d *stageDurationsResolver) Colonization() *durationStatsResolver {
+	return &durationStatsResolver{d.d.Colonization}
+}
+
+func (d *stageDurationsResolver) Fruiting() *durationStatsResolver {
+	return &durationStatsResolver{d.d.Fruiting}
+}
+
+func (l *lineageResolver) Root() graphql.ID { return graphql.ID(l.l.Root) }
+
+func (l *lineageResolver) Nodes() []*lineageNodeResolver {
+	result := make([]*lineageNodeResolver, len(l.l.Nodes))
+	for i, n := range l.l.Nodes {
+		result[i] = &lineageNodeResolver{n}
+	}
+	return result
+}
+
+func (l *lineageResolver) Export(args struct{ Format string }) (string, error) {
+	var b strings.Builder
+	err := l.l.Export(&b, types.LineageFormat(args.Format))
+	return b.String(), err
+}
+
+func (l *lineageResolver) Edges() []*lineageEdgeResolver {
+	result := make([]*lineageEdgeResolver, len(l.l.Edges))
+	for i, e := range l.l.Edges {
+		result[i] = &lineageEdgeResolver{e}
+	}
+	return result
+}
+
+func (n *lineageNodeResolver) ID() graphql.ID   { return graphql.ID(n.n.ID) }
+func (n *lineageNodeResolver) Kind() string     { return string(n.n.Kind) }
+func (n *lineageNodeResolver) UUID() graphql.ID { return graphql.ID(n.n.UUID) }
+func (n *lineageNodeResolver) Label() string    { return n.n.Label }
+func (n *lineageNodeResolver) Depth() int32     { return int32(n.n.Depth) }
+func (n *lineageNodeResolver) Species() string  { return n.n.Species }
+func (n *lineageNodeResolver) Vendor() string   { return n.n.Vendor }
+func (n *lineageNodeResolver) Outcome() string  { return string(n.n.Outcome) }
+
+func (e *lineageEdgeResolver) From() graphql.ID { return graphql.ID(e.e.From) }
+func (e *lineageEdgeResolver) To() graphql.ID   { return graphql.ID(e.e.To) }
+
+func (m *measurementStatsResolver) Dimension() string { return string(m.m.Dimension) }
+func (m *measurementStatsResolver) Key() string       { return m.m.Key }
+func (m *measurementStatsResolver) Label() string     { return m.m.Label }
+func (m *measurementStatsResolver) Count() int32      { return int32(m.m.Count) }
+func (m *measurementStatsResolver) Min() float64      { return m.m.Min }
+func (m *measurementStatsResolver) Median() float64   { return m.m.Median }
+func (m *measurementStatsResolver) Max() float64      { return m.m.Max }
+func (m *measurementStatsResolver) Mean() float64     { return m.m.Mean }
+func (m *measurementStatsResolver) StdDev() float64   { return m.m.StdDev }
+
+func (c *contaminationRateResolver) Dimension() string   { return string(c.c.Dimension) }
+func (c *contaminationRateResolver) Key() string         { return c.c.Key }
+func (c *contaminationRateResolver) Label() string       { return c.c.Label }
+func (c *contaminationRateResolver) Observed() int32     { return int32(c.c.Observed) }
+func (c *contaminationRateResolver) Contaminated() int32 { return int32(c.c.Contaminated) }
+func (c *contaminationRateResolver) Error() int32        { return int32(c.c.Error) }
+func (c *contaminationRateResolver) Fatal() int32        { return int32(c.c.Fatal) }
+func (c *contaminationRateResolver) Rip() int32          { return int32(c.c.RIP) }
+func (c *contaminationRateResolver) Rate() float64       { return float64(c.c.Rate) }
+
+func (c *contaminationResolver) ObservableType() string   { return string(c.c.ObservableType) }
+func (c *contaminationResolver) ObservableId() graphql.ID { return graphql.ID(c.c.ObservableUUID) }
+func (c *contaminationResolver) Stage() *stageResolver    { return &stageResolver{c.c.Stage} }
+func (c *contaminationResolver) Event() *eventResolver    { return &eventResolver{c.r, c.c.Event} }
@@ -0,0 +1,89 @@
+// Package gql exposes the object graph in docs/orm.graphml as a read-only
+// GraphQL schema, so clients can pick exactly the fields (and depth) they
+// need instead of stitching together endpoints or pulling whole reports
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/jsmit257/huautla/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+//go:embed schema.graphql
+var sdl string
+
+// DefaultMaxDepth keeps a careless lifecycle->strain->generation->sources->...
+// query from walking the whole library
+const DefaultMaxDepth = 12
+
+func NewSchema(db types.DB, opts ...graphql.SchemaOpt) (*graphql.Schema, error) {
+	return graphql.ParseSchema(sdl, &root{db: db}, append([]graphql.SchemaOpt{
+		graphql.MaxDepth(DefaultMaxDepth),
+	}, opts...)...)
+}
+
+// NewHandler serves POSTed graphql requests; every request gets its own
+// loaders so nothing is cached between requests
+func NewHandler(db types.DB, l *log.Entry, opts ...graphql.SchemaOpt) (http.Handler, error) {
+	schema, err := NewSchema(db, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// X-Units picks what temperatures and weights are in for this request,
+		// otherwise the db's default applies
+		var units types.UnitSystem
+		if h := r.Header.Get("X-Units"); h != "" {
+			if units, err = types.ParseUnitSystem(h); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		cid := types.CID(r.Header.Get("X-Request-Id"))
+		if cid == "" {
+			cid = types.CID(uuid.New().String())
+		}
+
+		ctx := context.WithValue(r.Context(), types.Cid, cid)
+		ctx = context.WithValue(ctx, types.Log, l.WithField("cid", cid))
+		if units != "" {
+			ctx = types.WithUnits(ctx, units)
+		}
+		ctx = WithLoaders(ctx, db)
+
+		js, err := json.Marshal(schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(js)
+	}), nil
+}
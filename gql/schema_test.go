@@ -0,0 +1,854 @@
+package gql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type fakeDB struct {
+	types.DB
+	mtx   sync.Mutex
+	calls map[string]int
+}
+
+var (
+	epoch   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_vendor = types.Vendor{UUID: "v0", Name: "vendor 0", Website: "website"}
+	_strain = types.Strain{
+		UUID:       "s0",
+		Name:       "strain 0",
+		Species:    "X.species",
+		Vendor:     _vendor,
+		Generation: &types.Generation{UUID: "g0"},
+		CTime:      epoch,
+	}
+	_grain  = types.Substrate{UUID: "gs", Name: "rye", Type: types.GrainType, Vendor: _vendor}
+	_bulk   = types.Substrate{UUID: "bs", Name: "coir", Type: types.BulkType, Vendor: _vendor}
+	_shelf0 = types.Location{UUID: "loc0", Name: "shelf 0", Kind: types.FruitingChamberLocation, Capacity: 4}
+	_shelf1 = types.Location{UUID: "loc1", Name: "shelf 1", Kind: types.IncubatorLocation}
+	_check  = types.FollowUp{UUID: "f0", Name: "check colonization", Days: 7, Expects: &types.EventType{UUID: "et2", Name: "100% colonization", Severity: "Info"}}
+	_tasks  = []types.Task{
+		{UUID: "t0", FollowUp: _check, ObservableType: types.LifecycleParent, ObservableUUID: "lc0", EventUUID: "e0", Due: epoch.Add(7 * 24 * time.Hour), Overdue: true},
+		{UUID: "t1", FollowUp: _check, ObservableType: types.GenerationParent, ObservableUUID: "g0", EventUUID: "e1", Due: epoch.Add(14 * 24 * time.Hour)},
+	}
+	_protocol = types.Protocol{UUID: "p0", Name: "agar to bulk", Steps: []types.ProtocolStep{
+		{UUID: "ps0", EventType: types.EventType{UUID: "et0", Name: "Innoculation", Severity: "Begin"}},
+		{UUID: "ps1", EventType: types.EventType{UUID: "et1", Name: "Binning", Severity: "Begin"}, Day: 14, Tolerance: 2},
+	}}
+	_batch = types.Batch{UUID: "b0", Name: "jars", CTime: epoch, Members: []types.BatchMember{
+		{Lifecycle: types.Lifecycle{UUID: "lc0"}},
+		{Lifecycle: types.Lifecycle{UUID: "lc1"}, Excluded: &epoch},
+	}}
+	_experiment = types.Experiment{UUID: "x0", Name: "rye vs millet", Variable: "grain", CTime: epoch, Arms: []types.ExperimentArm{
+		{UUID: "a0", Name: "rye", Control: true, Lifecycles: []types.Lifecycle{{UUID: "lc0"}}},
+		{UUID: "a1", Name: "millet", Lifecycles: []types.Lifecycle{{UUID: "lc1"}}},
+	}}
+	_tags = map[types.UUID][]string{
+		"lc0": {"competition", "keeper"},
+		"lc1": {"competition"},
+	}
+	_lcs = map[types.UUID]types.Lifecycle{
+		"lc0": {UUID: "lc0", Location: _shelf0, Yield: 1.5, Strain: _strain, GrainSubstrate: _grain, BulkSubstrate: _bulk, Status: types.ActiveStatus, CTime: epoch, MTime: epoch},
+		"lc1": {UUID: "lc1", Location: _shelf1, Yield: 2.5, Strain: _strain, GrainSubstrate: _grain, BulkSubstrate: _bulk, Status: types.HarvestedStatus, CTime: epoch, MTime: epoch},
+	}
+)
+
+func (db *fakeDB) called(fn string) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	db.calls[fn]++
+}
+
+func (db *fakeDB) SelectLifecycleIndex(ctx context.Context, _ types.CID, statuses ...types.Status) ([]types.Lifecycle, error) {
+	db.called("SelectLifecycleIndex")
+	result := []types.Lifecycle{}
+	for _, lc := range []types.Lifecycle{_lcs["lc0"], _lcs["lc1"]} {
+		if lc.Status.Matches(statuses) && hasTags(lc.UUID, types.GetContextTags(ctx)) {
+			result = append(result, lc)
+		}
+	}
+	return result, nil
+}
+
+// hasTags is the fake version of filtering by tags in the database
+func hasTags(id types.UUID, tags []string) bool {
+	for _, t := range tags {
+		found := false
+		for _, have := range _tags[id] {
+			found = found || have == t
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (db *fakeDB) KnownTags(context.Context, types.CID) ([]string, error) {
+	db.called("KnownTags")
+	return []string{"competition", "keeper"}, nil
+}
+
+func (db *fakeDB) GetTagsFor(_ context.Context, ids []types.UUID, _ types.CID) (map[types.UUID][]string, error) {
+	db.called("GetTagsFor")
+	result := map[types.UUID][]string{}
+	for _, id := range ids {
+		if tags, ok := _tags[id]; ok {
+			result[id] = tags
+		}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) SelectLifecycles(_ context.Context, ids []types.UUID, _ types.CID) ([]types.Lifecycle, error) {
+	db.called("SelectLifecycles")
+	result := []types.Lifecycle{}
+	for _, id := range ids {
+		if lc, ok := _lcs[id]; ok {
+			result = append(result, lc)
+		}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) StrainLifecyclesFor(_ context.Context, strains []types.UUID, _ types.CID) (map[types.UUID][]types.UUID, error) {
+	db.called("StrainLifecyclesFor")
+	result := map[types.UUID][]types.UUID{}
+	for _, s := range strains {
+		for _, id := range []types.UUID{"lc0", "lc1"} {
+			if _lcs[id].Strain.UUID == s {
+				result[s] = append(result[s], id)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) GetHarvests(_ context.Context, lc *types.Lifecycle, _ types.CID) error {
+	db.called("GetHarvests")
+	lc.Harvests = []types.Harvest{
+		{UUID: "h0", Date: lc.CTime.Add(240 * time.Hour), FreshWeight: 300, DryWeight: 30, Count: 10, Grade: "A"},
+		{UUID: "h1", Date: lc.CTime.Add(360 * time.Hour), FreshWeight: 100},
+	}
+	return nil
+}
+
+func (db *fakeDB) SelectAllLocations(context.Context, types.CID) ([]types.Location, error) {
+	db.called("SelectAllLocations")
+	return []types.Location{_shelf0, _shelf1}, nil
+}
+
+func (db *fakeDB) SelectLocation(_ context.Context, id types.UUID, _ types.CID) (types.Location, error) {
+	db.called("SelectLocation")
+	for _, loc := range []types.Location{_shelf0, _shelf1} {
+		if loc.UUID == id {
+			return loc, nil
+		}
+	}
+	return types.Location{}, sql.ErrNoRows
+}
+
+func (db *fakeDB) LocationOccupancy(_ context.Context, id types.UUID, _ types.CID) (types.Occupancy, error) {
+	db.called("LocationOccupancy")
+	lcs := []types.Lifecycle{}
+	for _, lc := range []types.Lifecycle{_lcs["lc0"], _lcs["lc1"]} {
+		if lc.Location.UUID == id {
+			lcs = append(lcs, lc)
+		}
+	}
+	return types.NewOccupancy(_shelf0, lcs), nil
+}
+
+func (db *fakeDB) SelectReadings(_ context.Context, location string, w types.Window, _ types.CID) ([]types.Reading, error) {
+	db.called("SelectReadings")
+	co2 := float32(800)
+	return []types.Reading{{Sensor: "s0", Location: location, At: *w.From, CO2: &co2}}, nil
+}
+
+func (db *fakeDB) ReadingRollups(_ context.Context, _ string, _ types.Resolution, w types.Window, _ types.CID) ([]types.ReadingRollup, error) {
+	db.called("ReadingRollups")
+	low, high := float32(20), float32(22)
+	return []types.ReadingRollup{{
+		Bucket:      *w.From,
+		Count:       60,
+		Temperature: types.ReadingStats{Count: 60, Min: &low, Max: &high, Mean: &low},
+	}}, nil
+}
+
+func (db *fakeDB) StageReadings(_ context.Context, id types.UUID, _ types.CID) ([]types.StageReadings, error) {
+	db.called("StageReadings")
+	lc := _lcs[id]
+	return []types.StageReadings{{
+		StageSpan: types.StageSpan{Stage: types.Stage{UUID: "st0", Name: "Colonization"}, Begin: epoch},
+		Readings:  []types.Reading{{Sensor: "s1", Location: lc.Location.Name, Lifecycle: &lc.UUID, At: epoch}},
+	}}, nil
+}
+
+// SelectAllStrains has _strain as an original and an F1 from it, narrowed
+// by depth and by a headroom of 25 for the original and 10 for the F1;
+// _strain only has a filiation when it comes from here
+func (db *fakeDB) SelectAllStrains(ctx context.Context, _ types.CID) ([]types.Strain, error) {
+	db.called("SelectAllStrains")
+
+	original, root := _strain, _strain.UUID
+	original.Filiation = &types.Filiation{Notation: types.OriginalNotation}
+	f1 := types.Strain{UUID: "s1", Name: "strain 1", Vendor: _vendor, Filiation: &types.Filiation{Depth: 1, Notation: "F1", Root: &root}}
+	headroom := map[types.UUID]float64{original.UUID: 25, f1.UUID: 10}
+
+	result := []types.Strain{}
+	for _, s := range []types.Strain{original, f1} {
+		if d := types.GetContextFilial(ctx).Depth; d != nil && *d != s.Filiation.Depth {
+			continue
+		}
+		matched := true
+		for _, f := range types.GetContextAttributes(ctx) {
+			if f.Name != "headroom" ||
+				(f.Min != nil && headroom[s.UUID] < *f.Min) ||
+				(f.Max != nil && headroom[s.UUID] > *f.Max) {
+				matched = false
+			}
+		}
+		if matched {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) SelectAllVendors(context.Context, types.CID) ([]types.Vendor, error) {
+	db.called("SelectAllVendors")
+	return []types.Vendor{_vendor}, nil
+}
+
+func (db *fakeDB) GetAttributesFor(_ context.Context, ids []types.UUID, _ types.CID) (map[types.UUID][]types.StrainAttribute, error) {
+	db.called("GetAttributesFor")
+	result := map[types.UUID][]types.StrainAttribute{}
+	for _, id := range ids {
+		result[id] = []types.StrainAttribute{{UUID: "a0", Name: "color", Value: "blue"}}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) GetIngredientsFor(_ context.Context, ids []types.UUID, _ types.CID) (map[types.UUID][]types.Ingredient, error) {
+	db.called("GetIngredientsFor")
+	result := map[types.UUID][]types.Ingredient{}
+	for _, id := range ids {
+		result[id] = []types.Ingredient{{UUID: "i0", Name: "ingredient " + string(id)}}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) GetNotesFor(context.Context, []types.UUID, types.CID) (map[types.UUID][]types.Note, error) {
+	db.called("GetNotesFor")
+	return map[types.UUID][]types.Note{}, nil
+}
+
+func (db *fakeDB) SelectGenerations(_ context.Context, ids []types.UUID, _ types.CID) ([]types.Generation, error) {
+	db.called("SelectGenerations")
+	result := make([]types.Generation, len(ids))
+	for i, id := range ids {
+		result[i] = types.Generation{UUID: id, Sources: []types.Source{{UUID: "src0", Type: "Spore", Strain: _strain}}}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) SourceTypes(context.Context, types.CID) ([]types.SourceType, error) {
+	db.called("SourceTypes")
+	return []types.SourceType{
+		{Name: types.CloneSource, Max: 1, Clonal: true},
+		{Name: types.SporeSource, Max: 2},
+	}, nil
+}
+
+func (db *fakeDB) AttributeDefinitions(context.Context, types.CID) ([]types.AttributeDefinition, error) {
+	db.called("AttributeDefinitions")
+	return []types.AttributeDefinition{
+		{UUID: "ad0", Name: "color", Type: types.EnumAttribute, Allowed: []string{"blue", "purple"}, Synonyms: []string{"colour"}},
+		{UUID: "ad1", Name: "headroom", Type: types.NumberAttribute, Unit: "cm"},
+	}, nil
+}
+
+func (db *fakeDB) CostRollup(_ context.Context, by types.Dimension, w types.Window, _ types.CID) ([]types.CostRollup, error) {
+	db.called("CostRollup")
+	return []types.CostRollup{{
+		Dimension: by,
+		Key:       w.From.Format("2006-01-02"),
+		Label:     w.From.Format("2006-01-02"),
+		Count:     2,
+		Costs:     types.NewCosts(types.Lifecycle{StrainCost: 10, Yield: 4}),
+	}}, nil
+}
+
+func (db *fakeDB) StageDurations(_ context.Context, by types.Dimension, _ types.Window, _ types.CID) ([]types.StageDurations, error) {
+	db.called("StageDurations")
+	return []types.StageDurations{{
+		Dimension:    by,
+		Key:          "gs",
+		Label:        "rye",
+		Colonization: types.NewDurationStats([]time.Duration{10 * 24 * time.Hour, 14 * 24 * time.Hour}),
+	}}, nil
+}
+
+func (db *fakeDB) MeasurementStats(_ context.Context, _ types.UUID, by types.Dimension, _ types.Window, _ types.CID) ([]types.MeasurementStats, error) {
+	db.called("MeasurementStats")
+	result := types.NewMeasurementStats([]float64{10, 12.5, 14})
+	result.Dimension, result.Key, result.Label = by, "gs", "rye"
+	return []types.MeasurementStats{result}, nil
+}
+
+// Ancestry knows one strain with a parent; depth is echoed back on the root
+// so the test can tell it was passed along
+func (db *fakeDB) Ancestry(_ context.Context, id types.UUID, depth int, _ types.CID) (types.Lineage, error) {
+	db.called("Ancestry")
+	if id != "s1" {
+		return types.Lineage{}, sql.ErrNoRows
+	}
+	return types.Lineage{
+		Root: types.NewLineageID(types.StrainNode, id),
+		Nodes: []types.LineageNode{
+			{ID: "strain#s1", Kind: types.StrainNode, UUID: "s1", Label: "child", Depth: depth},
+			{ID: "strain#s0", Kind: types.StrainNode, UUID: "s0", Label: "parent", Depth: 1},
+		},
+		Edges: []types.LineageEdge{{From: "strain#s0", To: "strain#s1"}},
+	}, nil
+}
+
+func (db *fakeDB) Descendants(_ context.Context, id types.UUID, _ int, _ types.CID) (types.Lineage, error) {
+	db.called("Descendants")
+	root := types.NewLineageID(types.StrainNode, id)
+	return types.Lineage{
+		Root:  root,
+		Nodes: []types.LineageNode{{ID: root, Kind: types.StrainNode, UUID: id, Label: "childless"}},
+		Edges: []types.LineageEdge{},
+	}, nil
+}
+
+func (db *fakeDB) Pedigree(ctx context.Context, id types.UUID, depth int, cid types.CID) (types.Lineage, error) {
+	db.called("Pedigree")
+	return db.Ancestry(ctx, id, depth, cid)
+}
+
+func (db *fakeDB) Library(_ context.Context, _ types.CID) (types.Lineage, error) {
+	db.called("Library")
+	return types.Lineage{
+		Nodes: []types.LineageNode{
+			{ID: "strain#s0", Kind: types.StrainNode, UUID: "s0", Label: "parent", Species: "P. cubensis", Vendor: "vendor 0"},
+			{ID: "generation#g0", Kind: types.GenerationNode, UUID: "g0", Label: "g0", Outcome: types.ContaminatedStatus},
+		},
+		Edges: []types.LineageEdge{},
+	}, nil
+}
+
+func (db *fakeDB) ContaminationRates(_ context.Context, by types.Dimension, _ types.Window, _ types.CID) ([]types.ContaminationRate, error) {
+	db.called("ContaminationRates")
+	return []types.ContaminationRate{{Dimension: by, Key: "gs", Label: "rye", Observed: 4, Contaminated: 1, Error: 1, Rate: .25}}, nil
+}
+
+func (db *fakeDB) Contaminations(_ context.Context, _ types.Dimension, key string, _ types.Window, _ types.CID) ([]types.Contamination, error) {
+	db.called("Contaminations")
+	return []types.Contamination{{
+		ObservableType: types.LifecycleParent,
+		ObservableUUID: "lc0",
+		Stage:          types.Stage{UUID: "1", Name: "Colonization"},
+		Event: types.Event{
+			UUID:         "e0",
+			EventType:    types.EventType{Name: "Mold", Severity: "Error"},
+			Measurements: []types.Measurement{{Field: "spots", Value: 3.0}, {Field: "color", Value: "green"}},
+			OccurredAt:   epoch.AddDate(0, 0, 3),
+			CTime:        epoch.AddDate(0, 0, 5),
+		},
+	}}, nil
+}
+
+func (db *fakeDB) SelectAllEventTypes(context.Context, types.CID) ([]types.EventType, error) {
+	db.called("SelectAllEventTypes")
+	return []types.EventType{{UUID: "et0", Name: "Innoculation", Severity: "Begin"}}, nil
+}
+
+func (db *fakeDB) GetFollowUps(_ context.Context, et *types.EventType, _ types.CID) error {
+	db.called("GetFollowUps")
+	et.FollowUps = []types.FollowUp{_check}
+	return nil
+}
+
+func (db *fakeDB) GetFields(_ context.Context, et *types.EventType, _ types.CID) error {
+	db.called("GetFields")
+	zero := 0.0
+	et.Fields = []types.Field{{UUID: "fd0", Name: "diameter", Type: types.NumberField, Unit: "mm", Min: &zero}}
+	return nil
+}
+
+func (db *fakeDB) DueTasks(_ context.Context, by time.Time, _ types.CID) ([]types.Task, error) {
+	db.called("DueTasks")
+	result := []types.Task{}
+	for _, t := range _tasks {
+		if !t.Due.After(by) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (db *fakeDB) ObservableTasks(_ context.Context, id types.UUID, _ types.CID) ([]types.Task, error) {
+	db.called("ObservableTasks")
+	done, result := epoch.Add(8*24*time.Hour), types.UUID("e2")
+	t := _tasks[0]
+	t.Done, t.Result, t.Overdue = &done, &result, false
+	return []types.Task{t}, nil
+}
+
+func (db *fakeDB) SelectAllProtocols(context.Context, types.CID) ([]types.Protocol, error) {
+	db.called("SelectAllProtocols")
+	return []types.Protocol{{UUID: _protocol.UUID, Name: _protocol.Name}}, nil
+}
+
+func (db *fakeDB) SelectProtocol(_ context.Context, id types.UUID, _ types.CID) (types.Protocol, error) {
+	db.called("SelectProtocol")
+	if id != _protocol.UUID {
+		return types.Protocol{}, sql.ErrNoRows
+	}
+	return _protocol, nil
+}
+
+// only lc0 follows a protocol
+func (db *fakeDB) ObservableProtocol(_ context.Context, id types.UUID, _ types.CID) (*types.Protocol, error) {
+	db.called("ObservableProtocol")
+	if id != "lc0" {
+		return nil, nil
+	}
+	return &_protocol, nil
+}
+
+func (db *fakeDB) ProtocolDeviations(_ context.Context, id types.UUID, _ types.CID) (types.DeviationReport, error) {
+	db.called("ProtocolDeviations")
+	late, expected := 3, epoch.Add(14*24*time.Hour)
+	return types.DeviationReport{
+		Protocol: _protocol,
+		Anchor:   epoch,
+		Deviations: []types.Deviation{
+			{Kind: types.LateDeviation, Step: &_protocol.Steps[1], Event: &types.Event{UUID: "e1"}, Expected: &expected, Days: &late},
+			{Kind: types.UnexpectedDeviation, Event: &types.Event{UUID: "e2"}},
+		},
+	}, nil
+}
+
+func (db *fakeDB) SelectAllBatches(context.Context, types.CID) ([]types.Batch, error) {
+	db.called("SelectAllBatches")
+	return []types.Batch{{UUID: _batch.UUID, Name: _batch.Name, CTime: _batch.CTime}}, nil
+}
+
+func (db *fakeDB) SelectBatch(_ context.Context, id types.UUID, _ types.CID) (types.Batch, error) {
+	db.called("SelectBatch")
+	if id != _batch.UUID {
+		return types.Batch{}, sql.ErrNoRows
+	}
+	return _batch, nil
+}
+
+func (db *fakeDB) SelectAllExperiments(context.Context, types.CID) ([]types.Experiment, error) {
+	db.called("SelectAllExperiments")
+	return []types.Experiment{{UUID: _experiment.UUID, Name: _experiment.Name, Variable: _experiment.Variable, CTime: _experiment.CTime}}, nil
+}
+
+func (db *fakeDB) SelectExperiment(_ context.Context, id types.UUID, _ types.CID) (types.Experiment, error) {
+	db.called("SelectExperiment")
+	if id != _experiment.UUID {
+		return types.Experiment{}, sql.ErrNoRows
+	}
+	return _experiment, nil
+}
+
+func (db *fakeDB) ExperimentReport(_ context.Context, id types.UUID, _ types.CID) (types.ExperimentReport, error) {
+	db.called("ExperimentReport")
+	p := 0.5
+	return types.ExperimentReport{
+		Experiment: types.Experiment{UUID: _experiment.UUID},
+		Arms: []types.ArmSummary{
+			{
+				Arm:           types.ExperimentArm{UUID: "a0", Name: "rye", Control: true},
+				Yield:         types.SampleSummary{N: 1, Mean: 1.5},
+				Contamination: types.ProportionSummary{N: 1},
+			},
+			{
+				Arm:           types.ExperimentArm{UUID: "a1", Name: "millet"},
+				Yield:         types.SampleSummary{N: 1, Mean: 2.5, P: &p},
+				Contamination: types.ProportionSummary{N: 1, Count: 1, Rate: 1, P: &p},
+			},
+		},
+		Units: types.MetricUnits,
+	}, nil
+}
+
+func (db *fakeDB) LifecycleForecast(_ context.Context, id types.UUID, _ types.CID) (types.Forecast, error) {
+	db.called("LifecycleForecast")
+	return types.Forecast{
+		Basis:  []types.Dimension{types.StrainDimension, types.GrainDimension},
+		Anchor: epoch,
+		Colonized: &types.Estimate{
+			Samples:  3,
+			Expected: epoch.Add(12 * 24 * time.Hour),
+			Low:      epoch.Add(10 * 24 * time.Hour),
+			High:     epoch.Add(14 * 24 * time.Hour),
+			Actual:   &epoch,
+		},
+	}, nil
+}
+
+func Test_Exec(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		query  string
+		result string
+		calls  map[string]int
+	}{
+		"lifecycles": {
+			query: `{
+				lifecycles {
+					id
+					location { name }
+					yield
+					strain { name vendor { name } attributes { name value } }
+					grainSubstrate { name ingredients { name } }
+				}
+			}`,
+			result: `{"lifecycles":[` +
+				`{"id":"lc0","location":{"name":"shelf 0"},"yield":1.5,"strain":{"name":"strain 0","vendor":{"name":"vendor 0"},"attributes":[{"name":"color","value":"blue"}]},"grainSubstrate":{"name":"rye","ingredients":[{"name":"ingredient gs"}]}},` +
+				`{"id":"lc1","location":{"name":"shelf 1"},"yield":2.5,"strain":{"name":"strain 0","vendor":{"name":"vendor 0"},"attributes":[{"name":"color","value":"blue"}]},"grainSubstrate":{"name":"rye","ingredients":[{"name":"ingredient gs"}]}}]}`,
+			calls: map[string]int{
+				"SelectLifecycleIndex": 1,
+				"SelectLifecycles":     1, // both lifecycles at once
+				"GetAttributesFor":     1, // same strain twice
+				"GetIngredientsFor":    1, // same substrate twice
+			},
+		},
+		"ids_only": {
+			query:  `{ lifecycles { id } }`,
+			result: `{"lifecycles":[{"id":"lc0"},{"id":"lc1"}]}`,
+			calls:  map[string]int{"SelectLifecycleIndex": 1},
+		},
+		"lifecycles_by_tag": {
+			query:  `{ lifecycles(tags: ["Keeper"]) { id tags } }`,
+			result: `{"lifecycles":[{"id":"lc0","tags":["competition","keeper"]}]}`,
+			calls:  map[string]int{"SelectLifecycleIndex": 1, "GetTagsFor": 1},
+		},
+		"known_tags": {
+			query:  `{ knownTags }`,
+			result: `{"knownTags":["competition","keeper"]}`,
+			calls:  map[string]int{"KnownTags": 1},
+		},
+		"lifecycles_by_status": {
+			query:  `{ lifecycles(status: ["harvested", "dead"]) { id status } }`,
+			result: `{"lifecycles":[{"id":"lc1","status":"harvested"}]}`,
+			calls:  map[string]int{"SelectLifecycleIndex": 1, "SelectLifecycles": 1},
+		},
+		"strains_by_depth": {
+			query:  `{ strains(depth: 1) { name filiation { depth notation root { name } } } }`,
+			result: `{"strains":[{"name":"strain 1","filiation":{"depth":1,"notation":"F1","root":{"name":"strain 0"}}}]}`,
+			calls:  map[string]int{"SelectAllStrains": 2},
+		},
+		"strains_by_attribute": {
+			query:  `{ strains(attributes: [{name: "headroom", min: 20, max: 30}]) { name } }`,
+			result: `{"strains":[{"name":"strain 0"}]}`,
+			calls:  map[string]int{"SelectAllStrains": 1},
+		},
+		"looked_up_filiation": {
+			query:  `{ lifecycle(id: "lc0") { strain { filiation { notation root { name } } } } }`,
+			result: `{"lifecycle":{"strain":{"filiation":{"notation":"P","root":null}}}}`,
+			calls:  map[string]int{"SelectLifecycles": 1, "SelectAllStrains": 1},
+		},
+		"strain_graph": {
+			query:  `{ strain(id: "s0") { name lifecycles { id } generation { sources { type strain { name } } } } }`,
+			result: `{"strain":{"name":"strain 0","lifecycles":[{"id":"lc0"},{"id":"lc1"}],"generation":{"sources":[{"type":"Spore","strain":{"name":"strain 0"}}]}}}`,
+			calls: map[string]int{
+				"SelectAllStrains":    1,
+				"StrainLifecyclesFor": 1,
+				"SelectGenerations":   1,
+			},
+		},
+		"lifecycle_costs": {
+			query:  `{ lifecycle(id: "lc0") { costs { total yield perYieldGram moisture } } }`,
+			result: `{"lifecycle":{"costs":{"total":0,"yield":1.5,"perYieldGram":0,"moisture":null}}}`,
+			calls:  map[string]int{"SelectLifecycles": 1},
+		},
+		"cost_rollup": {
+			query:  `{ costRollup(by: "month", from: "2024-01-01T00:00:00Z") { dimension key count costs { total perYieldGram } } }`,
+			result: `{"costRollup":[{"dimension":"month","key":"2024-01-01","count":2,"costs":{"total":10,"perYieldGram":2.5}}]}`,
+			calls:  map[string]int{"CostRollup": 1},
+		},
+		"stage_durations": {
+			query:  `{ stageDurations(by: "grain") { label colonization { count median } fruiting { count } } }`,
+			result: `{"stageDurations":[{"label":"rye","colonization":{"count":2,"median":288},"fruiting":{"count":0}}]}`,
+			calls:  map[string]int{"StageDurations": 1},
+		},
+		"measurement_stats": {
+			query:  `{ measurementStats(field: "fd0", by: "grain") { dimension label count min median max mean } }`,
+			result: `{"measurementStats":[{"dimension":"grain","label":"rye","count":3,"min":10,"median":12.5,"max":14,"mean":12.166666666666666}]}`,
+			calls:  map[string]int{"MeasurementStats": 1},
+		},
+		"ancestry": {
+			query:  `{ ancestry(strain: "s1", depth: 3) { root nodes { id kind uuid label depth } edges { from to } } }`,
+			result: `{"ancestry":{"root":"strain#s1","nodes":[{"id":"strain#s1","kind":"strain","uuid":"s1","label":"child","depth":3},{"id":"strain#s0","kind":"strain","uuid":"s0","label":"parent","depth":1}],"edges":[{"from":"strain#s0","to":"strain#s1"}]}}`,
+			calls:  map[string]int{"Ancestry": 1},
+		},
+		"missing_ancestry": {
+			query:  `{ ancestry(strain: "missing") { root } }`,
+			result: `{"ancestry":null}`,
+			calls:  map[string]int{"Ancestry": 1},
+		},
+		"descendants": {
+			query:  `{ descendants(strain: "s2") { root nodes { label depth } edges { from } } }`,
+			result: `{"descendants":{"root":"strain#s2","nodes":[{"label":"childless","depth":0}],"edges":[]}}`,
+			calls:  map[string]int{"Descendants": 1},
+		},
+		"pedigree": {
+			query:  `{ pedigree(strain: "s1") { root export(format: "mermaid") } }`,
+			result: `{"pedigree":{"root":"strain#s1","export":"flowchart TD\n  n0[\"child\"]\n  n1[\"parent\"]\n  n1 --> n0\n"}}`,
+			calls:  map[string]int{"Pedigree": 1, "Ancestry": 1},
+		},
+		"library": {
+			query:  `{ pedigree { root nodes { kind species vendor outcome } } }`,
+			result: `{"pedigree":{"root":"","nodes":[{"kind":"strain","species":"P. cubensis","vendor":"vendor 0","outcome":""},{"kind":"generation","species":"","vendor":"","outcome":"contaminated"}]}}`,
+			calls:  map[string]int{"Library": 1},
+		},
+		"contamination_rates": {
+			query:  `{ contaminationRates(by: "grain") { label observed contaminated rate } }`,
+			result: `{"contaminationRates":[{"label":"rye","observed":4,"contaminated":1,"rate":0.25}]}`,
+			calls:  map[string]int{"ContaminationRates": 1},
+		},
+		"contaminations": {
+			query:  `{ contaminations(by: "grain", key: "gs") { observableType observableId stage { name } event { eventType { name severity } } } }`,
+			result: `{"contaminations":[{"observableType":"lifecycle","observableId":"lc0","stage":{"name":"Colonization"},"event":{"eventType":{"name":"Mold","severity":"Error"}}}]}`,
+			calls:  map[string]int{"Contaminations": 1},
+		},
+		"event_measurements": {
+			query:  `{ contaminations(by: "grain", key: "gs") { event { measurements { field value unit } } } }`,
+			result: `{"contaminations":[{"event":{"measurements":[{"field":"spots","value":"3","unit":""},{"field":"color","value":"green","unit":""}]}}]}`,
+			calls:  map[string]int{"Contaminations": 1},
+		},
+		"event_occurred_at": {
+			query:  `{ contaminations(by: "grain", key: "gs") { event { occurredAt ctime } } }`,
+			result: `{"contaminations":[{"event":{"occurredAt":"2024-01-04T00:00:00Z","ctime":"2024-01-06T00:00:00Z"}}]}`,
+			calls:  map[string]int{"Contaminations": 1},
+		},
+		"lifecycle_forecast": {
+			query: `{ lifecycle(id: "lc0") { forecast { basis anchor colonized { samples expected low high actual } harvest { samples } } } }`,
+			result: `{"lifecycle":{"forecast":{"basis":["strain","grain"],"anchor":"2024-01-01T00:00:00Z",` +
+				`"colonized":{"samples":3,"expected":"2024-01-13T00:00:00Z","low":"2024-01-11T00:00:00Z","high":"2024-01-15T00:00:00Z","actual":"2024-01-01T00:00:00Z"},"harvest":null}}}`,
+			calls: map[string]int{"SelectLifecycles": 1, "LifecycleForecast": 1},
+		},
+		"lifecycle_harvests": {
+			query: `{ lifecycle(id: "lc0") { harvests { id date grade } flushes { number interval share moisture meanWeight harvest { id } } } }`,
+			result: `{"lifecycle":{"harvests":[{"id":"h0","date":"2024-01-11T00:00:00Z","grade":"A"},{"id":"h1","date":"2024-01-16T00:00:00Z","grade":""}],` +
+				`"flushes":[{"number":1,"interval":240,"share":0.75,"moisture":0.8999999761581421,"meanWeight":30,"harvest":{"id":"h0"}},` +
+				`{"number":2,"interval":120,"share":0.25,"moisture":null,"meanWeight":null,"harvest":{"id":"h1"}}]}}`,
+			calls: map[string]int{"SelectLifecycles": 1, "GetHarvests": 2},
+		},
+		"readings": {
+			query:  `{ readings(location: "shelf 0", from: "2024-01-01T00:00:00Z") { sensor location lifecycle { id } at temperature co2 } }`,
+			result: `{"readings":[{"sensor":"s0","location":"shelf 0","lifecycle":null,"at":"2024-01-01T00:00:00Z","temperature":null,"co2":800}]}`,
+			calls:  map[string]int{"SelectReadings": 1},
+		},
+		"reading_rollups": {
+			query:  `{ readingRollups(location: "shelf 0", resolution: "hour", from: "2024-01-01T00:00:00Z") { bucket count temperature { count min max mean } humidity { count min } } }`,
+			result: `{"readingRollups":[{"bucket":"2024-01-01T00:00:00Z","count":60,"temperature":{"count":60,"min":20,"max":22,"mean":20},"humidity":{"count":0,"min":null}}]}`,
+			calls:  map[string]int{"ReadingRollups": 1},
+		},
+		"stage_readings": {
+			query:  `{ lifecycle(id: "lc0") { stageReadings { span { stage { name } end } readings { sensor lifecycle { location { name } } } } } }`,
+			result: `{"lifecycle":{"stageReadings":[{"span":{"stage":{"name":"Colonization"},"end":null},"readings":[{"sensor":"s1","lifecycle":{"location":{"name":"shelf 0"}}}]}]}}`,
+			calls:  map[string]int{"StageReadings": 1, "SelectLifecycles": 1},
+		},
+		"source_types": {
+			query:  `{ sourceTypes { name max mixable clonal } }`,
+			result: `{"sourceTypes":[{"name":"Clone","max":1,"mixable":false,"clonal":true},{"name":"Spore","max":2,"mixable":false,"clonal":false}]}`,
+			calls:  map[string]int{"SourceTypes": 1},
+		},
+		"attribute_definitions": {
+			query:  `{ attributeDefinitions { id name type unit allowed synonyms } }`,
+			result: `{"attributeDefinitions":[{"id":"ad0","name":"color","type":"enum","unit":"","allowed":["blue","purple"],"synonyms":["colour"]},{"id":"ad1","name":"headroom","type":"number","unit":"cm","allowed":[],"synonyms":[]}]}`,
+			calls:  map[string]int{"AttributeDefinitions": 1},
+		},
+		"locations": {
+			query:  `{ locations { id name kind capacity target { minTemperature maxCO2 } } }`,
+			result: `{"locations":[{"id":"loc0","name":"shelf 0","kind":"fruiting chamber","capacity":4,"target":{"minTemperature":null,"maxCO2":null}},{"id":"loc1","name":"shelf 1","kind":"incubator","capacity":0,"target":{"minTemperature":null,"maxCO2":null}}]}`,
+			calls:  map[string]int{"SelectAllLocations": 1},
+		},
+		"location_occupancy": {
+			query:  `{ location(id: "loc0") { name occupancy { occupied fill lifecycles { id status } } } }`,
+			result: `{"location":{"name":"shelf 0","occupancy":{"occupied":1,"fill":0.25,"lifecycles":[{"id":"lc0","status":"active"}]}}}`,
+			calls:  map[string]int{"SelectLocation": 1, "LocationOccupancy": 1, "SelectLifecycles": 1},
+		},
+		"missing_location": {
+			query:  `{ location(id: "missing") { id } }`,
+			result: `{"location":null}`,
+			calls:  map[string]int{"SelectLocation": 1},
+		},
+		"due_tasks": {
+			query:  `{ tasks(by: "2024-01-09T00:00:00Z") { id observableType observableId eventId due done resultId overdue followUp { name days expects { name } } } }`,
+			result: `{"tasks":[{"id":"t0","observableType":"lifecycle","observableId":"lc0","eventId":"e0","due":"2024-01-08T00:00:00Z","done":null,"resultId":null,"overdue":true,"followUp":{"name":"check colonization","days":7,"expects":{"name":"100% colonization"}}}]}`,
+			calls:  map[string]int{"DueTasks": 1},
+		},
+		"event_type_follow_ups": {
+			query:  `{ eventTypes { name followUps { id name days } } }`,
+			result: `{"eventTypes":[{"name":"Innoculation","followUps":[{"id":"f0","name":"check colonization","days":7}]}]}`,
+			calls:  map[string]int{"SelectAllEventTypes": 1, "GetFollowUps": 1},
+		},
+		"event_type_fields": {
+			query:  `{ eventTypes { name fields { id name type unit min max } } }`,
+			result: `{"eventTypes":[{"name":"Innoculation","fields":[{"id":"fd0","name":"diameter","type":"number","unit":"mm","min":0,"max":null}]}]}`,
+			calls:  map[string]int{"SelectAllEventTypes": 1, "GetFields": 1},
+		},
+		"lifecycle_tasks": {
+			query:  `{ lifecycle(id: "lc0") { tasks { id done resultId overdue } } }`,
+			result: `{"lifecycle":{"tasks":[{"id":"t0","done":"2024-01-09T00:00:00Z","resultId":"e2","overdue":false}]}}`,
+			calls:  map[string]int{"SelectLifecycles": 1, "ObservableTasks": 1},
+		},
+		"protocols": {
+			query:  `{ protocols { id name steps { id day tolerance eventType { name } } } }`,
+			result: `{"protocols":[{"id":"p0","name":"agar to bulk","steps":[{"id":"ps0","day":0,"tolerance":0,"eventType":{"name":"Innoculation"}},{"id":"ps1","day":14,"tolerance":2,"eventType":{"name":"Binning"}}]}]}`,
+			calls:  map[string]int{"SelectAllProtocols": 1, "SelectProtocol": 1},
+		},
+		"missing_protocol": {
+			query:  `{ protocol(id: "missing") { id } }`,
+			result: `{"protocol":null}`,
+			calls:  map[string]int{"SelectProtocol": 1},
+		},
+		"lifecycle_deviations": {
+			query:  `{ lifecycle(id: "lc0") { protocol { name } deviations { anchor deviations { kind expected days step { id } event { id } } } } }`,
+			result: `{"lifecycle":{"protocol":{"name":"agar to bulk"},"deviations":{"anchor":"2024-01-01T00:00:00Z","deviations":[{"kind":"late","expected":"2024-01-15T00:00:00Z","days":3,"step":{"id":"ps1"},"event":{"id":"e1"}},{"kind":"unexpected","expected":null,"days":null,"step":null,"event":{"id":"e2"}}]}}}`,
+			calls:  map[string]int{"SelectLifecycles": 1, "ObservableProtocol": 2, "ProtocolDeviations": 1},
+		},
+		"lifecycle_without_protocol": {
+			query:  `{ lifecycle(id: "lc1") { protocol { name } deviations { anchor } } }`,
+			result: `{"lifecycle":{"protocol":null,"deviations":null}}`,
+			calls:  map[string]int{"SelectLifecycles": 1, "ObservableProtocol": 2},
+		},
+		"batches": {
+			query:  `{ batches { id name ctime members { excluded inStep lifecycle { id yield } } } }`,
+			result: `{"batches":[{"id":"b0","name":"jars","ctime":"2024-01-01T00:00:00Z","members":[{"excluded":null,"inStep":true,"lifecycle":{"id":"lc0","yield":1.5}},{"excluded":"2024-01-01T00:00:00Z","inStep":false,"lifecycle":{"id":"lc1","yield":2.5}}]}]}`,
+			calls:  map[string]int{"SelectAllBatches": 1, "SelectBatch": 1, "SelectLifecycles": 1},
+		},
+		"missing_batch": {
+			query:  `{ batch(id: "missing") { id } }`,
+			result: `{"batch":null}`,
+			calls:  map[string]int{"SelectBatch": 1},
+		},
+		"experiments": {
+			query:  `{ experiments { id name variable ctime arms { name control lifecycles { id yield } } } }`,
+			result: `{"experiments":[{"id":"x0","name":"rye vs millet","variable":"grain","ctime":"2024-01-01T00:00:00Z","arms":[{"name":"rye","control":true,"lifecycles":[{"id":"lc0","yield":1.5}]},{"name":"millet","control":false,"lifecycles":[{"id":"lc1","yield":2.5}]}]}]}`,
+			calls:  map[string]int{"SelectAllExperiments": 1, "SelectExperiment": 1, "SelectLifecycles": 1},
+		},
+		"experiment_report": {
+			query:  `{ experiment(id: "x0") { report { units arms { arm { id lifecycles { id } } yield { n mean p } contamination { count rate p } } } } }`,
+			result: `{"experiment":{"report":{"units":"metric","arms":[{"arm":{"id":"a0","lifecycles":[{"id":"lc0"}]},"yield":{"n":1,"mean":1.5,"p":null},"contamination":{"count":0,"rate":0,"p":null}},{"arm":{"id":"a1","lifecycles":[{"id":"lc1"}]},"yield":{"n":1,"mean":2.5,"p":0.5},"contamination":{"count":1,"rate":1,"p":0.5}}]}}}`,
+			calls:  map[string]int{"SelectExperiment": 3, "ExperimentReport": 1},
+		},
+		"missing_experiment": {
+			query:  `{ experiment(id: "missing") { id } }`,
+			result: `{"experiment":null}`,
+			calls:  map[string]int{"SelectExperiment": 1},
+		},
+		"missing_lifecycle": {
+			query:  `{ lifecycle(id: "missing") { id } }`,
+			result: `{"lifecycle":null}`,
+			calls:  map[string]int{"SelectLifecycles": 1},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &fakeDB{calls: map[string]int{}}
+
+			schema, err := NewSchema(db)
+			require.Nil(t, err)
+
+			ctx := context.WithValue(context.Background(), types.Cid, types.CID(name))
+			resp := schema.Exec(WithLoaders(ctx, db), tc.query, "", nil)
+
+			require.Empty(t, resp.Errors)
+			require.JSONEq(t, tc.result, string(resp.Data))
+			require.Equal(t, tc.calls, db.calls)
+		})
+	}
+}
+
+func Test_NewHandler(t *testing.T) {
+	t.Parallel()
+
+	h, err := NewHandler(&fakeDB{calls: map[string]int{}}, log.WithField("test", "NewHandler"))
+	require.Nil(t, err)
+
+	tcs := map[string]struct {
+		method string
+		units  string
+		body   string
+		sc     int
+	}{
+		"happy_path": {
+			method: http.MethodPost,
+			body:   `{"query": "{ vendors { name } }"}`,
+			sc:     http.StatusOK,
+		},
+		"imperial": {
+			method: http.MethodPost,
+			units:  "imperial",
+			body:   `{"query": "{ vendors { name } }"}`,
+			sc:     http.StatusOK,
+		},
+		"unknown_units": {
+			method: http.MethodPost,
+			units:  "furlongs",
+			body:   `{"query": "{ vendors { name } }"}`,
+			sc:     http.StatusBadRequest,
+		},
+		"wrong_method": {
+			method: http.MethodGet,
+			sc:     http.StatusMethodNotAllowed,
+		},
+		"bad_body": {
+			method: http.MethodPost,
+			body:   `{`,
+			sc:     http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/graphql", bytes.NewBufferString(tc.body))
+			if tc.units != "" {
+				r.Header.Set("X-Units", tc.units)
+			}
+			h.ServeHTTP(w, r)
+			require.Equal(t, tc.sc, w.Code)
+
+			if tc.sc == http.StatusOK {
+				result := map[string]any{}
+				require.Nil(t, json.Unmarshal(w.Body.Bytes(), &result))
+				require.Equal(t, map[string]any{"data": map[string]any{
+					"vendors": []any{map[string]any{"name": "vendor 0"}},
+				}}, result)
+			}
+		})
+	}
+}
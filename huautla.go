@@ -13,6 +13,18 @@ func New(cfg *types.Config, log *log.Entry) (types.DB, error) {
 	var cnxFmt = "host=%s port=%d user=%s password=%s dbname=huautla sslmode=%s"
 	var cnxInfo string
 
+	// an empty Units is left alone, so results stay unlabeled (i.e. metric)
+	// unless a caller asks for something
+	if _, err := types.ParseUnitSystem(string(cfg.Units)); err != nil {
+		return nil, err
+	}
+
+	// an empty Transitions is strict, and anything else has to be a policy
+	// the data layer knows about, or a typo would quietly be strict too
+	if _, err := types.ParseTransitionPolicy(string(cfg.Transitions)); err != nil {
+		return nil, err
+	}
+
 	if host := cfg.PGHost; host == "" {
 		return nil, fmt.Errorf("postgres connection needs hostname attribute")
 	} else if user := cfg.PGUser; user == "" {
@@ -25,5 +37,5 @@ func New(cfg *types.Config, log *log.Entry) (types.DB, error) {
 		cnxInfo = fmt.Sprintf(cnxFmt, host, port, user, pass, cfg.PGSSL)
 	}
 
-	return data.New(cnxInfo, log)
+	return data.New(cnxInfo, cfg.Transitions, cfg.Units, log)
 }
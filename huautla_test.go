@@ -52,6 +52,26 @@ func Test_New(t *testing.T) {
 			},
 			err: fmt.Errorf("postgres connection needs port attribute"),
 		},
+		"unknown_units": {
+			cfg: types.Config{
+				PGHost: "huautla",
+				PGUser: "postgres",
+				PGPass: "root",
+				PGPort: 5432,
+				Units:  "furlongs",
+			},
+			err: fmt.Errorf("unknown unit system: 'furlongs'"),
+		},
+		"unknown_transitions": {
+			cfg: types.Config{
+				PGHost:      "huautla",
+				PGUser:      "postgres",
+				PGPass:      "root",
+				PGPort:      5432,
+				Transitions: "whatever",
+			},
+			err: fmt.Errorf("unknown transition policy: 'whatever'"),
+		},
 		"missing_ssl": {
 			cfg: types.Config{
 				PGHost: "huautla",
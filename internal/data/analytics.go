@@ -0,0 +1,227 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+type (
+	// dimensionKey says which group a lifecycle belongs to for a given dimension
+	dimensionKey func(types.Lifecycle) (key, label string)
+
+	// generationKey is dimensionKey for generations, which only have substrates
+	// and timestamps in common with lifecycles
+	generationKey func(types.Generation) (key, label string)
+
+	// nullevent is what's left of an event after a left join
+	nullevent struct {
+		uuid                   *types.UUID
+		temperature            *float32
+		humidity               *int8
+		occurred, mtime, ctime *time.Time
+		etuuid                 *types.UUID
+		etname                 *string
+		severity               *string
+		stuuid                 *types.UUID
+		stname                 *string
+	}
+)
+
+var dimensions = map[types.Dimension]dimensionKey{
+	types.StrainDimension: func(lc types.Lifecycle) (string, string) {
+		return string(lc.Strain.UUID), lc.Strain.Name
+	},
+	// the vendor the strain came from; substrate vendors are covered by grain and bulk
+	types.VendorDimension: func(lc types.Lifecycle) (string, string) {
+		return string(lc.Strain.Vendor.UUID), lc.Strain.Vendor.Name
+	},
+	types.GrainDimension: func(lc types.Lifecycle) (string, string) {
+		return string(lc.GrainSubstrate.UUID), lc.GrainSubstrate.Name
+	},
+	types.BulkDimension: func(lc types.Lifecycle) (string, string) {
+		return string(lc.BulkSubstrate.UUID), lc.BulkSubstrate.Name
+	},
+	types.LocationDimension: func(lc types.Lifecycle) (string, string) {
+		return string(lc.Location.UUID), lc.Location.Name
+	},
+	types.DayDimension:   periodKey(types.DayDimension),
+	types.WeekDimension:  periodKey(types.WeekDimension),
+	types.MonthDimension: periodKey(types.MonthDimension),
+	types.YearDimension:  periodKey(types.YearDimension),
+}
+
+var generationDimensions = map[types.Dimension]generationKey{
+	types.PlatingDimension: func(g types.Generation) (string, string) {
+		return string(g.PlatingSubstrate.UUID), g.PlatingSubstrate.Name
+	},
+	types.LiquidDimension: func(g types.Generation) (string, string) {
+		return string(g.LiquidSubstrate.UUID), g.LiquidSubstrate.Name
+	},
+	types.DayDimension:   generationPeriodKey(types.DayDimension),
+	types.WeekDimension:  generationPeriodKey(types.WeekDimension),
+	types.MonthDimension: generationPeriodKey(types.MonthDimension),
+	types.YearDimension:  generationPeriodKey(types.YearDimension),
+}
+
+func periodKey(by types.Dimension) dimensionKey {
+	return func(lc types.Lifecycle) (string, string) {
+		start := period(by, lc.CTime)
+		return start, start
+	}
+}
+
+func generationPeriodKey(by types.Dimension) generationKey {
+	return func(g types.Generation) (string, string) {
+		start := period(by, g.CTime)
+		return start, start
+	}
+}
+
+// period is the start (in utc) of the day, week, month or year t falls in
+func period(by types.Dimension, t time.Time) string {
+	t = t.UTC()
+	y, m, d := t.Date()
+
+	switch by {
+	case types.WeekDimension: // weeks start on monday
+		d -= (int(t.Weekday()) + 6) % 7
+	case types.MonthDimension:
+		d = 1
+	case types.YearDimension:
+		m, d = time.January, 1
+	}
+
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
+
+// selectLifecycleEvents returns just enough of each lifecycle to group it, and
+// all of its events oldest first; strain is optional
+func (db *Conn) selectLifecycleEvents(ctx context.Context, w types.Window, strain *types.UUID, cid types.CID) ([]types.Lifecycle, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectLifecycleEvents", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	units := db.unitSystem(ctx)
+
+	rows, err := db.QueryContext(ctx, psqls["analytics"]["lifecycle-events"], w.From, w.To, strain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.Lifecycle, 0, 100)
+	for rows.Next() {
+		row, e := types.Lifecycle{}, nullevent{}
+		if err = rows.Scan(
+			&row.UUID,
+			&row.Location.UUID,
+			&row.Location.Name,
+			&row.CTime,
+			&row.Strain.UUID,
+			&row.Strain.Name,
+			&row.Strain.Vendor.UUID,
+			&row.Strain.Vendor.Name,
+			&row.GrainSubstrate.UUID,
+			&row.GrainSubstrate.Name,
+			&row.BulkSubstrate.UUID,
+			&row.BulkSubstrate.Name,
+			&e.uuid,
+			&e.temperature,
+			&e.humidity,
+			&e.occurred,
+			&e.mtime,
+			&e.ctime,
+			&e.etuuid,
+			&e.etname,
+			&e.severity,
+			&e.stuuid,
+			&e.stname,
+		); err != nil {
+			return result, err
+		}
+
+		if curr := len(result) - 1; curr < 0 || result[curr].UUID != row.UUID {
+			row.Events = e.append(row.Events, units)
+			result = append(result, row)
+		} else {
+			result[curr].Events = e.append(result[curr].Events, units)
+		}
+	}
+
+	return result, err
+}
+
+// selectGenerationEvents is selectLifecycleEvents for generations
+func (db *Conn) selectGenerationEvents(ctx context.Context, w types.Window, cid types.CID) ([]types.Generation, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectGenerationEvents", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	units := db.unitSystem(ctx)
+
+	rows, err := db.QueryContext(ctx, psqls["analytics"]["generation-events"], w.From, w.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.Generation, 0, 100)
+	for rows.Next() {
+		row, e := types.Generation{}, nullevent{}
+		if err = rows.Scan(
+			&row.UUID,
+			&row.CTime,
+			&row.PlatingSubstrate.UUID,
+			&row.PlatingSubstrate.Name,
+			&row.LiquidSubstrate.UUID,
+			&row.LiquidSubstrate.Name,
+			&e.uuid,
+			&e.temperature,
+			&e.humidity,
+			&e.occurred,
+			&e.mtime,
+			&e.ctime,
+			&e.etuuid,
+			&e.etname,
+			&e.severity,
+			&e.stuuid,
+			&e.stname,
+		); err != nil {
+			return result, err
+		}
+
+		if curr := len(result) - 1; curr < 0 || result[curr].UUID != row.UUID {
+			row.Events = e.append(row.Events, units)
+			result = append(result, row)
+		} else {
+			result[curr].Events = e.append(result[curr].Events, units)
+		}
+	}
+
+	return result, err
+}
+
+func (e nullevent) append(events []types.Event, units types.UnitSystem) []types.Event {
+	if e.uuid == nil {
+		return events
+	}
+	return append(events, types.Event{
+		UUID:        *e.uuid,
+		Temperature: *e.temperature,
+		Humidity:    *e.humidity,
+		OccurredAt:  *e.occurred,
+		MTime:       *e.mtime,
+		CTime:       *e.ctime,
+		EventType: types.EventType{
+			UUID:     *e.etuuid,
+			Name:     *e.etname,
+			Severity: *e.severity,
+			Stage: types.Stage{
+				UUID: *e.stuuid,
+				Name: *e.stname,
+			},
+		},
+	}.InUnits(units))
+}
@@ -0,0 +1,180 @@
+package data
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	lcEventFields = row{
+		"uuid",
+		"location_uuid",
+		"location_name",
+		"ctime",
+		"strain_uuid",
+		"strain_name",
+		"strain_vendor_uuid",
+		"strain_vendor_name",
+		"grain_substrate_uuid",
+		"grain_substrate_name",
+		"bulk_substrate_uuid",
+		"bulk_substrate_name",
+		"event_uuid",
+		"temperature",
+		"humidity",
+		"event_occurred_at",
+		"event_mtime",
+		"event_ctime",
+		"eventtype_uuid",
+		"eventtype_name",
+		"severity",
+		"stage_uuid",
+		"stage_name",
+	}
+	lcEventValues = xformer{
+		"lc0",
+		"shelf 0",
+		"shelf 0",
+		stageEpoch,
+		"strain 0",
+		"strain name 0",
+		"vendor 0",
+		"vendor name 0",
+		"grain 0",
+		"grain name 0",
+		"bulk 0",
+		"bulk name 0",
+		"e0",
+		20.0,
+		80,
+		stageEpoch,
+		stageEpoch,
+		stageEpoch,
+		"9",
+		"Innoculation",
+		types.BeginSeverity,
+		"1",
+		types.ColonizationStage,
+	}
+	genEventFields = row{
+		"uuid",
+		"ctime",
+		"plating_uuid",
+		"plating_name",
+		"liquid_uuid",
+		"liquid_name",
+		"event_uuid",
+		"temperature",
+		"humidity",
+		"event_occurred_at",
+		"event_mtime",
+		"event_ctime",
+		"eventtype_uuid",
+		"eventtype_name",
+		"severity",
+		"stage_uuid",
+		"stage_name",
+	}
+	genEventValues = xformer{
+		"g0",
+		stageEpoch,
+		"plating 0",
+		"plating name 0",
+		"liquid 0",
+		"liquid name 0",
+		"ge0",
+		20.0,
+		80,
+		stageEpoch,
+		stageEpoch,
+		stageEpoch,
+		"5",
+		"Liquid innoculation",
+		types.BeginSeverity,
+		"0",
+		"Gestation",
+	}
+	noEvent = xform{12: nil, 13: nil, 14: nil, 15: nil, 16: nil, 17: nil, 18: nil, 19: nil, 20: nil, 21: nil, 22: nil}
+)
+
+// colonized in `col` days, fruited for `fruit` days, then retired
+func lcEventRows(lc, location string, col, fruit int) [][]driver.Value {
+	return [][]driver.Value{
+		lcEventValues.replace(xform{0: lc, 1: location, 2: location, 12: lc + " e0"}),
+		lcEventValues.replace(xform{0: lc, 1: location, 2: location, 12: lc + " e1", 15: stageEpoch.AddDate(0, 0, col), 18: "13", 19: "Binning", 21: "2", 22: types.MajorityStage}),
+		lcEventValues.replace(xform{0: lc, 1: location, 2: location, 12: lc + " e2", 15: stageEpoch.AddDate(0, 0, col+fruit), 18: "sunset", 19: "Sunset", 20: types.RIPSeverity, 21: "2", 22: types.MajorityStage}),
+	}
+}
+
+func Test_dimensions(t *testing.T) {
+	t.Parallel()
+
+	// a sunday, so the week started 6 days earlier
+	lc := types.Lifecycle{
+		Location:       types.Location{UUID: "loc0", Name: "shelf 0"},
+		Strain:         types.Strain{UUID: "s0", Name: "strain 0", Vendor: types.Vendor{UUID: "v0", Name: "vendor 0"}},
+		GrainSubstrate: types.Substrate{UUID: "g0", Name: "rye"},
+		BulkSubstrate:  types.Substrate{UUID: "b0", Name: "coir"},
+		CTime:          time.Date(2024, time.June, 16, 23, 59, 0, 0, time.UTC),
+	}
+
+	tcs := map[types.Dimension]struct{ key, label string }{
+		types.StrainDimension:   {"s0", "strain 0"},
+		types.VendorDimension:   {"v0", "vendor 0"},
+		types.GrainDimension:    {"g0", "rye"},
+		types.BulkDimension:     {"b0", "coir"},
+		types.LocationDimension: {"loc0", "shelf 0"},
+		types.DayDimension:      {"2024-06-16", "2024-06-16"},
+		types.WeekDimension:     {"2024-06-10", "2024-06-10"},
+		types.MonthDimension:    {"2024-06-01", "2024-06-01"},
+		types.YearDimension:     {"2024-01-01", "2024-01-01"},
+	}
+
+	require.Equal(t, len(dimensions), len(tcs))
+
+	for by, tc := range tcs {
+		by, tc := by, tc
+		t.Run(string(by), func(t *testing.T) {
+			t.Parallel()
+			key, label := dimensions[by](lc)
+			require.Equal(t, tc.key, key)
+			require.Equal(t, tc.label, label)
+		})
+	}
+}
+
+func Test_generationDimensions(t *testing.T) {
+	t.Parallel()
+
+	g := types.Generation{
+		PlatingSubstrate: types.Substrate{UUID: "p0", Name: "agar"},
+		LiquidSubstrate:  types.Substrate{UUID: "l0", Name: "lme"},
+		CTime:            time.Date(2024, time.June, 16, 23, 59, 0, 0, time.UTC),
+	}
+
+	tcs := map[types.Dimension]struct{ key, label string }{
+		types.PlatingDimension: {"p0", "agar"},
+		types.LiquidDimension:  {"l0", "lme"},
+		types.DayDimension:     {"2024-06-16", "2024-06-16"},
+		types.WeekDimension:    {"2024-06-10", "2024-06-10"},
+		types.MonthDimension:   {"2024-06-01", "2024-06-01"},
+		types.YearDimension:    {"2024-01-01", "2024-01-01"},
+	}
+
+	require.Equal(t, len(generationDimensions), len(tcs))
+
+	for by, tc := range tcs {
+		by, tc := by, tc
+		t.Run(string(by), func(t *testing.T) {
+			t.Parallel()
+			key, label := generationDimensions[by](g)
+			require.Equal(t, tc.key, key)
+			require.Equal(t, tc.label, label)
+		})
+	}
+}
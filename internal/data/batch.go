@@ -0,0 +1,275 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) SelectAllBatches(ctx context.Context, cid types.CID) ([]types.Batch, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectAllBatches", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["batch"]["select-all"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.Batch, 0, 20)
+	for rows.Next() {
+		row := types.Batch{}
+		if err = rows.Scan(&row.UUID, &row.Name, &row.CTime); err != nil {
+			break
+		}
+		result = append(result, row)
+	}
+
+	return result, err
+}
+
+// SelectBatch is the only way to get a batch's members, oldest first
+func (db *Conn) SelectBatch(ctx context.Context, id types.UUID, cid types.CID) (types.Batch, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectBatch", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result := types.Batch{UUID: id}
+	if err = db.
+		QueryRowContext(ctx, psqls["batch"]["select"], id).
+		Scan(&result.UUID, &result.Name, &result.CTime); err != nil {
+		return result, err
+	}
+
+	result.Members, err = db.getBatchMembers(ctx, id, cid)
+
+	return result, err
+}
+
+func (db *Conn) getBatchMembers(ctx context.Context, id types.UUID, cid types.CID) ([]types.BatchMember, error) {
+	rows, err := db.query.QueryContext(ctx, psqls["batch"]["members"], id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	excluded := map[types.UUID]*time.Time{}
+	for rows.Next() {
+		var lcID types.UUID
+		var t *time.Time
+		if err = rows.Scan(&lcID, &t); err != nil {
+			return nil, err
+		}
+		excluded[lcID] = t
+	}
+
+	result := []types.BatchMember{}
+	if len(excluded) == 0 {
+		return result, nil
+	}
+
+	p, _ := types.NewReportAttrs(map[string][]string{"batch-id": {string(id)}})
+
+	lcs, err := db.selectLifecycles(ctx, p, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lc := range lcs {
+		result = append(result, types.BatchMember{Lifecycle: lc, Excluded: excluded[lc.UUID]})
+	}
+
+	return result, nil
+}
+
+// CreateBatch inserts n copies of template, or nothing at all if any of them
+// fails
+func (db *Conn) CreateBatch(ctx context.Context, name string, template types.Lifecycle, n int, cid types.CID) (types.Batch, error) {
+	var err error
+	deferred, l := initAccessFuncs("CreateBatch", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	if n < 1 {
+		err = fmt.Errorf("a batch needs at least one member: %d", n)
+		return types.Batch{}, err
+	}
+
+	var result types.Batch
+	err = db.inTx(ctx, func(tx *Conn) error {
+		b, err := tx.insertBatch(ctx, types.Batch{Name: name})
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < n; i++ {
+			lc, err := tx.InsertLifecycle(ctx, template, cid)
+			if err != nil {
+				return err
+			} else if err = tx.addBatchMember(ctx, b.UUID, lc.UUID); err != nil {
+				return err
+			}
+			b.Members = append(b.Members, types.BatchMember{Lifecycle: lc})
+		}
+
+		result = b
+		return nil
+	})
+
+	return result, err
+}
+
+func (db *Conn) insertBatch(ctx context.Context, b types.Batch) (types.Batch, error) {
+	b.UUID = types.UUID(db.generateUUID().String())
+	b.CTime = time.Now().UTC()
+
+	result, err := db.ExecContext(ctx, psqls["batch"]["insert"], b.UUID, b.Name, b.CTime)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.insertBatch(ctx, b)
+		}
+		return b, pqerr(err)
+	} else if rows, err := result.RowsAffected(); err != nil {
+		return b, err
+	} else if rows != 1 {
+		return b, fmt.Errorf("batch was not added")
+	}
+
+	return b, nil
+}
+
+func (db *Conn) addBatchMember(ctx context.Context, bID, lcID types.UUID) error {
+	result, err := db.ExecContext(ctx, psqls["batch"]["add-member"], bID, lcID)
+	if err != nil {
+		return pqerr(err)
+	} else if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		return fmt.Errorf("batch member was not added: '%s'", lcID)
+	}
+	return nil
+}
+
+func (db *Conn) UpdateBatch(ctx context.Context, id types.UUID, b types.Batch, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("UpdateBatch", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result, err := db.ExecContext(ctx, psqls["batch"]["update"], b.Name, id)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		return fmt.Errorf("batch was not updated: '%s'", id)
+	}
+	return nil
+}
+
+// DeleteBatch only breaks up the group, its lifecycles are left alone
+func (db *Conn) DeleteBatch(ctx context.Context, id types.UUID, cid types.CID) error {
+	return db.deleteByUUID(ctx, id, cid, "DeleteBatch", "batch", db.logger)
+}
+
+// AddBatchEvent adds e to every member that's still in step, going by the
+// batch as it is in the database rather than b, which gets refreshed on
+// success
+func (db *Conn) AddBatchEvent(ctx context.Context, b *types.Batch, e types.Event, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AddBatchEvent", db.logger, b.UUID, cid)
+	defer deferred(&err, l)
+
+	err = db.fanOut(ctx, b, cid, func(tx *Conn, lc *types.Lifecycle) error {
+		return tx.AddLifecycleEvent(ctx, lc, e, cid)
+	})
+
+	return err
+}
+
+func (db *Conn) AddBatchNote(ctx context.Context, b *types.Batch, n types.Note, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AddBatchNote", db.logger, b.UUID, cid)
+	defer deferred(&err, l)
+
+	err = db.fanOut(ctx, b, cid, func(tx *Conn, lc *types.Lifecycle) error {
+		_, err := tx.AddNote(ctx, lc.UUID, nil, n, cid)
+		return err
+	})
+
+	return err
+}
+
+// AddBatchPhoto puts p on each member's latest event, since lifecycles don't
+// have photos of their own
+func (db *Conn) AddBatchPhoto(ctx context.Context, b *types.Batch, p types.Photo, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AddBatchPhoto", db.logger, b.UUID, cid)
+	defer deferred(&err, l)
+
+	err = db.fanOut(ctx, b, cid, func(tx *Conn, lc *types.Lifecycle) error {
+		if len(lc.Events) == 0 {
+			return fmt.Errorf("batch member has no event to attach a photo to: '%s'", lc.UUID)
+		}
+		_, err := tx.AddPhoto(ctx, lc.Events[0].UUID, nil, p, cid)
+		return err
+	})
+
+	return err
+}
+
+// fanOut reloads b inside a transaction and runs fn for each member that's
+// still in step; either every member gets it or none of them do
+func (db *Conn) fanOut(ctx context.Context, b *types.Batch, cid types.CID, fn func(*Conn, *types.Lifecycle) error) error {
+	var fresh types.Batch
+	err := db.inTx(ctx, func(tx *Conn) (err error) {
+		if fresh, err = tx.SelectBatch(ctx, b.UUID, cid); err != nil {
+			return err
+		}
+
+		for _, i := range fresh.InStep() {
+			if err = fn(tx, &fresh.Members[i].Lifecycle); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err == nil {
+		*b = fresh
+	}
+
+	return err
+}
+
+// ExcludeBatchMember takes a lifecycle out of later batch-wide operations for
+// good; it's still a member
+func (db *Conn) ExcludeBatchMember(ctx context.Context, b *types.Batch, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("ExcludeBatchMember", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	excluded := time.Now().UTC()
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["batch"]["exclude"], excluded, b.UUID, id)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("batch member could not be excluded: '%s'", id)
+		return err
+	}
+
+	for i := range b.Members {
+		if b.Members[i].Lifecycle.UUID == id {
+			b.Members[i].Excluded = &excluded
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,579 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_batches = []types.Batch{
+		{UUID: "batch 0", Name: "batch 0", CTime: wwtbn},
+		{UUID: "batch 1", Name: "batch 1", CTime: wwtbn},
+	}
+	batchFields = row{"uuid", "name", "ctime"}
+	batchValues = [][]driver.Value{
+		{_batches[0].UUID, _batches[0].Name, _batches[0].CTime},
+		{_batches[1].UUID, _batches[1].Name, _batches[1].CTime},
+	}
+
+	memberFields = row{"lifecycle_uuid", "excluded"}
+
+	// the second member of every batch is excluded, so only _lc is in step
+	memberValues = [][]driver.Value{
+		{_lc.UUID, nil},
+		{"excluded", wwtbn},
+	}
+	excludedValues = xformer(lcValues).replace(xform{0: "excluded"})
+)
+
+// mockBatch is everything SelectBatch asks for, given lifecycles that match
+// memberValues
+func mockBatch(mock *mocker) *mocker {
+	return mock.add(
+		batchFields.set(batchValues[0]),
+		memberFields.set(memberValues...),
+		lcFields.set(lcValues, excludedValues),
+		eventFields.set(eventValues...),
+		eventFields.set())
+}
+
+func Test_SelectAllBatches(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectAllBatches")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Batch
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, batchFields.set(batchValues...))
+				return db
+			},
+			result: _batches,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, batchFields.fail())
+				return db
+			},
+			err: batchFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectAllBatches(context.Background(), "Test_SelectAllBatches")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_SelectBatch(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectBatch")
+
+	tcs := map[string]struct {
+		db       getMockDB
+		id       types.UUID
+		members  []types.UUID
+		excluded []bool
+		err      error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, mockBatch)
+				return db
+			},
+			id:       _batches[0].UUID,
+			members:  []types.UUID{_lc.UUID, "excluded"},
+			excluded: []bool{false, true},
+		},
+		"no_members": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					batchFields.set(batchValues[1]),
+					memberFields.set())
+				return db
+			},
+			id:       _batches[1].UUID,
+			members:  []types.UUID{},
+			excluded: []bool{},
+		},
+		"no_result": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, batchFields.set())
+				return db
+			},
+			id:  "missing",
+			err: sql.ErrNoRows,
+		},
+		"members_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					batchFields.set(batchValues[0]),
+					memberFields.fail())
+				return db
+			},
+			id:  _batches[0].UUID,
+			err: memberFields.err(),
+		},
+		"lifecycles_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					batchFields.set(batchValues[0]),
+					memberFields.set(memberValues...),
+					lcFields.fail())
+				return db
+			},
+			id:  _batches[0].UUID,
+			err: lcFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectBatch(context.Background(), tc.id, "Test_SelectBatch")
+
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+			members, excluded := []types.UUID{}, []bool{}
+			for _, m := range result.Members {
+				members = append(members, m.Lifecycle.UUID)
+				excluded = append(excluded, m.Excluded != nil)
+			}
+			require.Equal(t, tc.members, members)
+			require.Equal(t, tc.excluded, excluded)
+		})
+	}
+}
+
+func Test_CreateBatch(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "CreateBatch")
+
+	insertMember := func(mock *mocker) *mocker {
+		mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.add(lcFields.set(lcValues), eventFields.set())
+		mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+		return mock
+	}
+
+	tcs := map[string]struct {
+		db      getMockDB
+		n       int
+		members int
+		err     error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, insertMember, insertMember)
+				mock.ExpectCommit()
+				return db
+			},
+			n:       2,
+			members: 2,
+		},
+		"no_members": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			err: fmt.Errorf("a batch needs at least one member: 0"),
+		},
+		"batch_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			n:   2,
+			err: fmt.Errorf("some error"),
+		},
+		"lifecycle_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, insertMember)
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			n:   2,
+			err: fmt.Errorf("some error"),
+		},
+		"member_not_added": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, lcFields.set(lcValues), eventFields.set())
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			n:   1,
+			err: fmt.Errorf("batch member was not added: '%s'", _lc.UUID),
+		},
+		"begin_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin().WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			n:   1,
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).CreateBatch(context.Background(), "batch", types.Lifecycle(_lc), tc.n, "Test_CreateBatch")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.members, len(result.Members))
+		})
+	}
+}
+
+func Test_UpdateBatch(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "UpdateBatch")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("batch was not updated: 'batch 0'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).UpdateBatch(context.Background(), _batches[0].UUID, _batches[0], "Test_UpdateBatch")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_DeleteBatch(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "DeleteBatch")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("batch could not be deleted: 'batch 0'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).DeleteBatch(context.Background(), _batches[0].UUID, "Test_DeleteBatch")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_AddBatchEvent(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddBatchEvent")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		events int
+		err    error
+	}{
+		// only the first member is in step, so it's the only one that gets it
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, mockBatch, etFields.set(etValues[0]))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			events: len(eventValues) + 1,
+		},
+		"event_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, mockBatch, etFields.set(etValues[0]))
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+		"batch_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, batchFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			err: batchFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := types.Batch{UUID: _batches[0].UUID}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+				transitions:  types.IgnoreTransitions,
+			}).AddBatchEvent(context.Background(), &b, types.Event{EventType: types.EventType{UUID: "0"}}, "Test_AddBatchEvent")
+
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				require.Empty(t, b.Members)
+				return
+			}
+			require.Equal(t, tc.events, len(b.Members[0].Lifecycle.Events))
+			require.Empty(t, b.Members[1].Lifecycle.Events)
+		})
+	}
+}
+
+func Test_AddBatchNote(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddBatchNote")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, mockBatch)
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		"note_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, mockBatch)
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			err: fmt.Errorf("note was not added"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := types.Batch{UUID: _batches[0].UUID}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddBatchNote(context.Background(), &b, types.Note{Note: "note"}, "Test_AddBatchNote")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_AddBatchPhoto(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddBatchPhoto")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, mockBatch)
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		"no_events": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock,
+					batchFields.set(batchValues[0]),
+					memberFields.set(memberValues[0]),
+					lcFields.set(lcValues),
+					eventFields.set())
+				mock.ExpectRollback()
+				return db
+			},
+			err: fmt.Errorf("batch member has no event to attach a photo to: '%s'", _lc.UUID),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := types.Batch{UUID: _batches[0].UUID}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddBatchPhoto(context.Background(), &b, types.Photo{Filename: "batch.png"}, "Test_AddBatchPhoto")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_ExcludeBatchMember(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ExcludeBatchMember")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("batch member could not be excluded: '%s'", _lc.UUID),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := types.Batch{
+				UUID:    _batches[0].UUID,
+				Members: []types.BatchMember{{Lifecycle: types.Lifecycle{UUID: _lc.UUID}}},
+			}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ExcludeBatchMember(context.Background(), &b, _lc.UUID, "Test_ExcludeBatchMember")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.err == nil, b.Members[0].Excluded != nil)
+		})
+	}
+}
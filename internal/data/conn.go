@@ -27,6 +27,8 @@ type (
 		query
 		generateUUID uuidgen
 		logger       *log.Entry
+		transitions  types.TransitionPolicy
+		units        types.UnitSystem
 		// sql          map[string]map[string]string
 	}
 
@@ -37,7 +39,7 @@ type (
 	deferred func(*error, *log.Entry)
 )
 
-func New(cnxInfo string, log *log.Entry) (types.DB, error) {
+func New(cnxInfo string, transitions types.TransitionPolicy, units types.UnitSystem, log *log.Entry) (types.DB, error) {
 	var err error
 	var query *sql.DB
 
@@ -51,9 +53,31 @@ func New(cnxInfo string, log *log.Entry) (types.DB, error) {
 		query:        query,
 		generateUUID: uuid.New,
 		logger:       log,
+		transitions:  transitions,
+		units:        units,
 	}, nil
 }
 
+// unitSystem is what measurements are accepted and returned in for ctx: the
+// one it asked for, or else the connection's default; everything is stored
+// in types.MetricUnits regardless
+func (db *Conn) unitSystem(ctx context.Context) types.UnitSystem {
+	if s, ok := types.GetContextUnits(ctx); ok {
+		return s
+	}
+	return db.units
+}
+
+// uuidArray is ids the way lib/pq sends an array, for queries that take
+// `= any($n)`
+func uuidArray(ids []types.UUID) any {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = string(id)
+	}
+	return pq.Array(result)
+}
+
 func (db *Conn) deleteByUUID(ctx context.Context, id types.UUID, cid types.CID, method, table string, l *log.Entry) error {
 	var err error
 
@@ -75,6 +99,32 @@ func (db *Conn) deleteByUUID(ctx context.Context, id types.UUID, cid types.CID,
 	return err
 }
 
+// inTx runs fn against a copy of db that's bound to a single transaction, and
+// only commits if fn succeeds; a db that's already in a transaction (or can't
+// start one) just runs fn
+func (db *Conn) inTx(ctx context.Context, fn func(*Conn) error) error {
+	b, ok := db.query.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := b.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	txdb := *db
+	txdb.query = tx
+	if err = fn(&txdb); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func initAccessFuncs(fn string, l *log.Entry, id any, cid types.CID) (deferred, *log.Entry) {
 	start := time.Now()
 	l = l.WithFields(log.Fields{
@@ -30,4 +30,8 @@ func uuidptr(uuid types.UUID) *types.UUID {
 	return &uuid
 }
 
+func f32ptr(f float32) *float32 {
+	return &f
+}
+
 var wwtbn = time.Now() // time.Soon()
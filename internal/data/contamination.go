@@ -0,0 +1,198 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+// observed is a lifecycle or generation reduced to what the contamination
+// analytics need; key and label are empty when grouping by stage, since
+// that comes from each event instead
+type observed struct {
+	kind       types.ParentType
+	id         types.UUID
+	key, label string
+	events     []types.Event
+}
+
+var contaminants = map[string]struct{}{
+	types.ErrorSeverity: {},
+	types.FatalSeverity: {},
+	types.RIPSeverity:   {},
+}
+
+// ContaminationRates groups lifecycles by strain, vendor, grain, bulk or
+// location, generations by plating or liquid, and both of them by stage or
+// period. When grouping by stage, a lifecycle is only observed in the stages
+// it actually reached
+func (db *Conn) ContaminationRates(ctx context.Context, by types.Dimension, w types.Window, cid types.CID) ([]types.ContaminationRate, error) {
+	var err error
+	deferred, l := initAccessFuncs("ContaminationRates", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	obs, err := db.selectObserved(ctx, by, w, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.ContaminationRate, 0, len(obs))
+	index := make(map[string]int, len(obs))
+	tally := func(key, label string, severities map[string]struct{}) {
+		i, ok := index[key]
+		if !ok {
+			i, index[key] = len(result), len(result)
+			result = append(result, types.ContaminationRate{Dimension: by, Key: key, Label: label})
+		}
+
+		r := &result[i]
+		r.Observed++
+		if len(severities) > 0 {
+			r.Contaminated++
+		}
+		if _, ok := severities[types.ErrorSeverity]; ok {
+			r.Error++
+		}
+		if _, ok := severities[types.FatalSeverity]; ok {
+			r.Fatal++
+		}
+		if _, ok := severities[types.RIPSeverity]; ok {
+			r.RIP++
+		}
+	}
+
+	for _, o := range obs {
+		spans := types.NewStageSpans(o.events)
+		bad := o.contaminations(spans)
+
+		if by != types.StageDimension {
+			severities := map[string]struct{}{}
+			for _, c := range bad {
+				severities[c.Event.EventType.Severity] = struct{}{}
+			}
+			tally(o.key, o.label, severities)
+			continue
+		}
+
+		stages := make([]types.Stage, 0, len(spans)+len(bad))
+		severities := map[types.UUID]map[string]struct{}{}
+		reached := func(st types.Stage) {
+			if _, ok := severities[st.UUID]; !ok {
+				severities[st.UUID] = map[string]struct{}{}
+				stages = append(stages, st)
+			}
+		}
+		for _, s := range spans {
+			reached(s.Stage)
+		}
+		for _, c := range bad {
+			reached(c.Stage)
+			severities[c.Stage.UUID][c.Event.EventType.Severity] = struct{}{}
+		}
+		for _, st := range stages {
+			tally(string(st.UUID), st.Name, severities[st.UUID])
+		}
+	}
+
+	for i := range result {
+		result[i].Rate = float32(result[i].Contaminated) / float32(result[i].Observed)
+	}
+
+	return result, nil
+}
+
+// Contaminations returns the offending events for the group identified by
+// key, as returned by ContaminationRates, oldest observable first
+func (db *Conn) Contaminations(ctx context.Context, by types.Dimension, key string, w types.Window, cid types.CID) ([]types.Contamination, error) {
+	var err error
+	deferred, l := initAccessFuncs("Contaminations", db.logger, key, cid)
+	defer deferred(&err, l)
+
+	obs, err := db.selectObserved(ctx, by, w, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.Contamination, 0)
+	for _, o := range obs {
+		if by != types.StageDimension && o.key != key {
+			continue
+		}
+		for _, c := range o.contaminations(types.NewStageSpans(o.events)) {
+			if by != types.StageDimension || string(c.Stage.UUID) == key {
+				result = append(result, c)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (db *Conn) selectObserved(ctx context.Context, by types.Dimension, w types.Window, cid types.CID) ([]observed, error) {
+	lcKey, lcOK := dimensions[by]
+	genKey, genOK := generationDimensions[by]
+	if by == types.StageDimension {
+		lcOK, genOK = true, true
+	} else if !lcOK && !genOK {
+		return nil, fmt.Errorf("unknown dimension for contamination rates: '%s'", by)
+	}
+
+	result := make([]observed, 0, 100)
+
+	if lcOK {
+		lcs, err := db.selectLifecycleEvents(ctx, w, nil, cid)
+		if err != nil {
+			return nil, err
+		}
+		for _, lc := range lcs {
+			o := observed{kind: types.LifecycleParent, id: lc.UUID, events: lc.Events}
+			if lcKey != nil {
+				o.key, o.label = lcKey(lc)
+			}
+			result = append(result, o)
+		}
+	}
+
+	if genOK {
+		gens, err := db.selectGenerationEvents(ctx, w, cid)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range gens {
+			o := observed{kind: types.GenerationParent, id: g.UUID, events: g.Events}
+			if genKey != nil {
+				o.key, o.label = genKey(g)
+			}
+			result = append(result, o)
+		}
+	}
+
+	return result, nil
+}
+
+// contaminations blames events in the Any stage on whatever stage the
+// observable was in at the time, if it was in one at all
+func (o observed) contaminations(spans []types.StageSpan) []types.Contamination {
+	result := []types.Contamination{}
+	for _, e := range o.events {
+		if _, ok := contaminants[e.EventType.Severity]; !ok {
+			continue
+		}
+
+		st := e.EventType.Stage
+		if st.Name == types.AnyStage {
+			if at, ok := types.StageAt(spans, e.OccurredAt); ok {
+				st = at
+			}
+		}
+
+		result = append(result, types.Contamination{
+			ObservableType: o.kind,
+			ObservableUUID: o.id,
+			Stage:          st,
+			Event:          e,
+		})
+	}
+	return result
+}
@@ -0,0 +1,276 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_colonization = types.Stage{UUID: "1", Name: types.ColonizationStage}
+	_majority     = types.Stage{UUID: "2", Name: types.MajorityStage}
+	_gestation    = types.Stage{UUID: "0", Name: "Gestation"}
+
+	// lc0 gets moldy while colonizing and is retired after fruiting, lc1 is
+	// clean and g0 picks up bacteria
+	contaminationLCRows = [][]driver.Value{
+		lcEventValues,
+		lcEventValues.replace(xform{12: "mold", 15: stageEpoch.AddDate(0, 0, 3), 18: "3", 19: "Mold", 20: types.ErrorSeverity, 21: "4", 22: types.AnyStage}),
+		lcEventValues.replace(xform{12: "binning", 15: stageEpoch.AddDate(0, 0, 10), 18: "13", 19: "Binning", 21: "2", 22: types.MajorityStage}),
+		lcEventValues.replace(xform{12: "sunset", 15: stageEpoch.AddDate(0, 0, 20), 18: "sunset", 19: "Sunset", 20: types.RIPSeverity, 21: "2", 22: types.MajorityStage}),
+		lcEventValues.replace(xform{0: "lc1", 1: "shelf 1", 2: "shelf 1", 12: "lc1 e0"}),
+	}
+	contaminationGenRows = [][]driver.Value{
+		genEventValues,
+		genEventValues.replace(xform{6: "bacteria", 9: stageEpoch.AddDate(0, 0, 2), 12: "4", 13: "Agar bacteria", 14: types.ErrorSeverity}),
+	}
+
+	_mold = types.Contamination{
+		ObservableType: types.LifecycleParent,
+		ObservableUUID: "lc0",
+		Stage:          _colonization,
+		Event: types.Event{
+			UUID:        "mold",
+			Temperature: 20,
+			Humidity:    80,
+			OccurredAt:  stageEpoch.AddDate(0, 0, 3),
+			MTime:       stageEpoch,
+			CTime:       stageEpoch,
+			EventType:   types.EventType{UUID: "3", Name: "Mold", Severity: types.ErrorSeverity, Stage: types.Stage{UUID: "4", Name: types.AnyStage}},
+		},
+	}
+	_sunset = types.Contamination{
+		ObservableType: types.LifecycleParent,
+		ObservableUUID: "lc0",
+		Stage:          _majority,
+		Event: types.Event{
+			UUID:        "sunset",
+			Temperature: 20,
+			Humidity:    80,
+			OccurredAt:  stageEpoch.AddDate(0, 0, 20),
+			MTime:       stageEpoch,
+			CTime:       stageEpoch,
+			EventType:   types.EventType{UUID: "sunset", Name: "Sunset", Severity: types.RIPSeverity, Stage: _majority},
+		},
+	}
+	_bacteria = types.Contamination{
+		ObservableType: types.GenerationParent,
+		ObservableUUID: "g0",
+		Stage:          _gestation,
+		Event: types.Event{
+			UUID:        "bacteria",
+			Temperature: 20,
+			Humidity:    80,
+			OccurredAt:  stageEpoch.AddDate(0, 0, 2),
+			MTime:       stageEpoch,
+			CTime:       stageEpoch,
+			EventType:   types.EventType{UUID: "4", Name: "Agar bacteria", Severity: types.ErrorSeverity, Stage: _gestation},
+		},
+	}
+)
+
+func Test_ContaminationRates(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ContaminationRates")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		by     types.Dimension
+		result []types.ContaminationRate
+		err    error
+	}{
+		"by_location": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.set(contaminationLCRows...))
+				return db
+			},
+			by: types.LocationDimension,
+			result: []types.ContaminationRate{
+				{Dimension: types.LocationDimension, Key: "shelf 0", Label: "shelf 0", Observed: 1, Contaminated: 1, Error: 1, RIP: 1, Rate: 1},
+				{Dimension: types.LocationDimension, Key: "shelf 1", Label: "shelf 1", Observed: 1},
+			},
+		},
+		"by_plating": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, genEventFields.set(contaminationGenRows...))
+				return db
+			},
+			by: types.PlatingDimension,
+			result: []types.ContaminationRate{
+				{Dimension: types.PlatingDimension, Key: "plating 0", Label: "plating name 0", Observed: 1, Contaminated: 1, Error: 1, Rate: 1},
+			},
+		},
+		"by_stage": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcEventFields.set(contaminationLCRows...),
+					genEventFields.set(contaminationGenRows...))
+				return db
+			},
+			by: types.StageDimension,
+			result: []types.ContaminationRate{
+				{Dimension: types.StageDimension, Key: "1", Label: types.ColonizationStage, Observed: 2, Contaminated: 1, Error: 1, Rate: .5},
+				{Dimension: types.StageDimension, Key: "2", Label: types.MajorityStage, Observed: 1, Contaminated: 1, RIP: 1, Rate: 1},
+				{Dimension: types.StageDimension, Key: "0", Label: "Gestation", Observed: 1, Contaminated: 1, Error: 1, Rate: 1},
+			},
+		},
+		"by_month": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcEventFields.set(contaminationLCRows...),
+					genEventFields.set(contaminationGenRows...))
+				return db
+			},
+			by: types.MonthDimension,
+			result: []types.ContaminationRate{
+				{Dimension: types.MonthDimension, Key: "2024-05-01", Label: "2024-05-01", Observed: 3, Contaminated: 2, Error: 2, RIP: 1, Rate: float32(2) / 3},
+			},
+		},
+		"unknown_dimension": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			by:  "species",
+			err: fmt.Errorf("unknown dimension for contamination rates: 'species'"),
+		},
+		"lifecycles_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.fail())
+				return db
+			},
+			by:  types.StageDimension,
+			err: lcEventFields.err(),
+		},
+		"generations_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcEventFields.set(contaminationLCRows...),
+					genEventFields.fail())
+				return db
+			},
+			by:  types.StageDimension,
+			err: genEventFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ContaminationRates(context.Background(), tc.by, types.Window{}, "Test_ContaminationRates")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_Contaminations(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "Contaminations")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		by     types.Dimension
+		key    string
+		result []types.Contamination
+		err    error
+	}{
+		"by_location": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.set(contaminationLCRows...))
+				return db
+			},
+			by:     types.LocationDimension,
+			key:    "shelf 0",
+			result: []types.Contamination{_mold, _sunset},
+		},
+		// mold entered after binning is still blamed on colonization, since
+		// that's when it was seen
+		"backfilled": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				rows := append([][]driver.Value{}, contaminationLCRows...)
+				rows[1] = xformer(rows[1]).replace(xform{16: stageEpoch.AddDate(0, 0, 12), 17: stageEpoch.AddDate(0, 0, 12)})
+				newBuilder(mock, lcEventFields.set(rows...))
+				return db
+			},
+			by:  types.LocationDimension,
+			key: "shelf 0",
+			result: []types.Contamination{
+				func(c types.Contamination) types.Contamination {
+					c.Event.MTime = stageEpoch.AddDate(0, 0, 12)
+					c.Event.CTime = c.Event.MTime
+					return c
+				}(_mold),
+				_sunset,
+			},
+		},
+		"clean_location": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.set(contaminationLCRows...))
+				return db
+			},
+			by:     types.LocationDimension,
+			key:    "shelf 1",
+			result: []types.Contamination{},
+		},
+		"by_stage": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcEventFields.set(contaminationLCRows...),
+					genEventFields.set(contaminationGenRows...))
+				return db
+			},
+			by:     types.StageDimension,
+			key:    "1",
+			result: []types.Contamination{_mold},
+		},
+		"by_liquid": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, genEventFields.set(contaminationGenRows...))
+				return db
+			},
+			by:     types.LiquidDimension,
+			key:    "liquid 0",
+			result: []types.Contamination{_bacteria},
+		},
+		"unknown_dimension": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			by:  "species",
+			err: fmt.Errorf("unknown dimension for contamination rates: 'species'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).Contaminations(context.Background(), tc.by, tc.key, types.Window{}, "Test_Contaminations")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) LifecycleCost(ctx context.Context, id types.UUID, cid types.CID) (types.LifecycleCost, error) {
+	var err error
+	deferred, l := initAccessFuncs("LifecycleCost", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result, err := db.selectCosts(ctx, &id, types.Window{}, cid)
+	if err != nil {
+		return types.LifecycleCost{}, err
+	} else if len(result) == 0 {
+		err = sql.ErrNoRows
+		return types.LifecycleCost{}, err
+	}
+
+	return result[0], nil
+}
+
+func (db *Conn) SelectLifecycleCosts(ctx context.Context, w types.Window, cid types.CID) ([]types.LifecycleCost, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectLifecycleCosts", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	result, err := db.selectCosts(ctx, nil, w, cid)
+
+	return result, err
+}
+
+// CostRollup groups every lifecycle created inside the window by the requested
+// dimension; groups are ordered by the first lifecycle that landed in them, which
+// makes the period dimensions chronological
+func (db *Conn) CostRollup(ctx context.Context, by types.Dimension, w types.Window, cid types.CID) ([]types.CostRollup, error) {
+	var err error
+	deferred, l := initAccessFuncs("CostRollup", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	key, ok := dimensions[by]
+	if !ok {
+		err = fmt.Errorf("unknown dimension for cost rollup: '%s'", by)
+		return nil, err
+	}
+
+	lcs, err := db.selectCosts(ctx, nil, w, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.CostRollup, 0, len(lcs))
+	index := make(map[string]int, len(lcs))
+	for _, lc := range lcs {
+		k, label := key(types.Lifecycle{
+			Location:       lc.Location,
+			Strain:         lc.Strain,
+			GrainSubstrate: lc.GrainSubstrate,
+			BulkSubstrate:  lc.BulkSubstrate,
+			CTime:          lc.CTime,
+		})
+		i, ok := index[k]
+		if !ok {
+			i, index[k] = len(result), len(result)
+			result = append(result, types.CostRollup{Dimension: by, Key: k, Label: label})
+		}
+		result[i].Count++
+		result[i].Costs = result[i].Costs.Add(lc.Costs)
+	}
+
+	return result, nil
+}
+
+func (db *Conn) selectCosts(ctx context.Context, id *types.UUID, w types.Window, cid types.CID) ([]types.LifecycleCost, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectCosts", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	rows, err := db.QueryContext(ctx, psqls["cost"]["select"], id, w.From, w.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.LifecycleCost, 0, 100)
+	for rows.Next() {
+		lc := types.Lifecycle{}
+		if err = rows.Scan(
+			&lc.UUID,
+			&lc.Location.UUID,
+			&lc.Location.Name,
+			&lc.StrainCost,
+			&lc.GrainCost,
+			&lc.BulkCost,
+			&lc.Yield,
+			&lc.Gross,
+			&lc.CTime,
+			&lc.Strain.UUID,
+			&lc.Strain.Name,
+			&lc.Strain.Vendor.UUID,
+			&lc.Strain.Vendor.Name,
+			&lc.GrainSubstrate.UUID,
+			&lc.GrainSubstrate.Name,
+			&lc.BulkSubstrate.UUID,
+			&lc.BulkSubstrate.Name,
+		); err != nil {
+			return result, err
+		}
+
+		result = append(result, types.LifecycleCost{
+			UUID:           lc.UUID,
+			Location:       lc.Location,
+			Strain:         lc.Strain,
+			GrainSubstrate: lc.GrainSubstrate,
+			BulkSubstrate:  lc.BulkSubstrate,
+			Costs:          types.NewCosts(lc),
+			CTime:          lc.CTime,
+		})
+	}
+
+	return result, err
+}
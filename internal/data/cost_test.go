@@ -0,0 +1,273 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	costFields = row{
+		"uuid",
+		"location_uuid",
+		"location_name",
+		"strain_cost",
+		"grain_cost",
+		"bulk_cost",
+		"yield",
+		"gross",
+		"ctime",
+		"strain_uuid",
+		"strain_name",
+		"strain_vendor_uuid",
+		"strain_vendor_name",
+		"grain_substrate_uuid",
+		"grain_substrate_name",
+		"bulk_substrate_uuid",
+		"bulk_substrate_name",
+	}
+	// wednesday, the 15th
+	costEpoch  = time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)
+	costValues = xformer{
+		"0",
+		"shelf 0",
+		"shelf 0",
+		10.0,
+		20.0,
+		10.0,
+		40.0,
+		400.0,
+		costEpoch,
+		"strain 0",
+		"strain name 0",
+		"vendor 0",
+		"vendor name 0",
+		"grain 0",
+		"grain name 0",
+		"bulk 0",
+		"bulk name 0",
+	}
+	_costs = []types.LifecycleCost{
+		{
+			UUID:           "0",
+			Location:       types.Location{UUID: "shelf 0", Name: "shelf 0"},
+			Strain:         types.Strain{UUID: "strain 0", Name: "strain name 0", Vendor: types.Vendor{UUID: "vendor 0", Name: "vendor name 0"}},
+			GrainSubstrate: types.Substrate{UUID: "grain 0", Name: "grain name 0"},
+			BulkSubstrate:  types.Substrate{UUID: "bulk 0", Name: "bulk name 0"},
+			Costs:          types.NewCosts(types.Lifecycle{StrainCost: 10, GrainCost: 20, BulkCost: 10, Yield: 40, Gross: 400}),
+			CTime:          costEpoch,
+		},
+		{
+			UUID:           "1",
+			Location:       types.Location{UUID: "shelf 1", Name: "shelf 1"},
+			Strain:         types.Strain{UUID: "strain 0", Name: "strain name 0", Vendor: types.Vendor{UUID: "vendor 0", Name: "vendor name 0"}},
+			GrainSubstrate: types.Substrate{UUID: "grain 0", Name: "grain name 0"},
+			BulkSubstrate:  types.Substrate{UUID: "bulk 0", Name: "bulk name 0"},
+			Costs:          types.NewCosts(types.Lifecycle{StrainCost: 10, GrainCost: 20, BulkCost: 10}),
+			CTime:          costEpoch.AddDate(0, 1, 0),
+		},
+	}
+	costRows = [][]driver.Value{
+		costValues,
+		costValues.replace(xform{0: "1", 1: "shelf 1", 2: "shelf 1", 6: 0.0, 7: 0.0, 8: costEpoch.AddDate(0, 1, 0)}),
+	}
+)
+
+func Test_LifecycleCost(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "LifecycleCost")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.LifecycleCost
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set(costValues))
+				return db
+			},
+			result: _costs[0],
+		},
+		"no_rows": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set())
+				return db
+			},
+			err: sql.ErrNoRows,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.fail())
+				return db
+			},
+			err: costFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).LifecycleCost(context.Background(), "0", "Test_LifecycleCost")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_SelectLifecycleCosts(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectLifecycleCosts")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.LifecycleCost
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set(costRows...))
+				return db
+			},
+			result: _costs,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.fail())
+				return db
+			},
+			err: costFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectLifecycleCosts(context.Background(), types.Window{}, "Test_SelectLifecycleCosts")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_CostRollup(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "CostRollup")
+
+	both := _costs[0].Costs.Add(_costs[1].Costs)
+
+	tcs := map[string]struct {
+		db     getMockDB
+		by     types.Dimension
+		result []types.CostRollup
+		err    error
+	}{
+		"by_strain": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set(costRows...))
+				return db
+			},
+			by: types.StrainDimension,
+			result: []types.CostRollup{
+				{Dimension: types.StrainDimension, Key: "strain 0", Label: "strain name 0", Count: 2, Costs: both},
+			},
+		},
+		"by_location": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set(costRows...))
+				return db
+			},
+			by: types.LocationDimension,
+			result: []types.CostRollup{
+				{Dimension: types.LocationDimension, Key: "shelf 0", Label: "shelf 0", Count: 1, Costs: _costs[0].Costs},
+				{Dimension: types.LocationDimension, Key: "shelf 1", Label: "shelf 1", Count: 1, Costs: _costs[1].Costs},
+			},
+		},
+		"by_week": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set(costRows...))
+				return db
+			},
+			by: types.WeekDimension,
+			result: []types.CostRollup{
+				{Dimension: types.WeekDimension, Key: "2024-05-13", Label: "2024-05-13", Count: 1, Costs: _costs[0].Costs},
+				{Dimension: types.WeekDimension, Key: "2024-06-10", Label: "2024-06-10", Count: 1, Costs: _costs[1].Costs},
+			},
+		},
+		"by_year": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set(costRows...))
+				return db
+			},
+			by: types.YearDimension,
+			result: []types.CostRollup{
+				{Dimension: types.YearDimension, Key: "2024-01-01", Label: "2024-01-01", Count: 2, Costs: both},
+			},
+		},
+		"empty": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.set())
+				return db
+			},
+			by:     types.MonthDimension,
+			result: []types.CostRollup{},
+		},
+		"unknown_dimension": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			by:  "species",
+			err: fmt.Errorf("unknown dimension for cost rollup: 'species'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, costFields.fail())
+				return db
+			},
+			by:  types.VendorDimension,
+			err: costFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).CostRollup(context.Background(), tc.by, types.Window{}, "Test_CostRollup")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -336,7 +336,7 @@ func Test_EventTypeReport(t *testing.T) {
 				return db
 			},
 			result: func(e types.Entity) types.Entity {
-				e["lifecycles"] = []types.Entity{mustEntity(_lc)}
+				e["lifecycles"] = []types.Entity{lcEntity(types.PendingStatus)}
 
 				return e
 			}(mustEntity(_ets[0])),
@@ -366,7 +366,7 @@ func Test_EventTypeReport(t *testing.T) {
 				return db
 			},
 			result: func(e types.Entity) types.Entity {
-				e["generations"] = []types.Entity{mustEntity(_gen)}
+				e["generations"] = []types.Entity{genEntity(types.PendingStatus)}
 
 				return e
 			}(mustEntity(_ets[0])),
@@ -0,0 +1,307 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) SelectAllExperiments(ctx context.Context, cid types.CID) ([]types.Experiment, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectAllExperiments", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["experiment"]["select-all"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.Experiment, 0, 20)
+	for rows.Next() {
+		row := types.Experiment{}
+		if err = rows.Scan(&row.UUID, &row.Name, &row.Variable, &row.CTime); err != nil {
+			break
+		}
+		result = append(result, row)
+	}
+
+	return result, err
+}
+
+// SelectExperiment is the only way to get an experiment's arms and their
+// lifecycles; the control arm comes first
+func (db *Conn) SelectExperiment(ctx context.Context, id types.UUID, cid types.CID) (types.Experiment, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectExperiment", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result := types.Experiment{UUID: id}
+	if err = db.
+		QueryRowContext(ctx, psqls["experiment"]["select"], id).
+		Scan(&result.UUID, &result.Name, &result.Variable, &result.CTime); err != nil {
+		return result, err
+	}
+
+	result.Arms, err = db.getExperimentArms(ctx, id, cid)
+
+	return result, err
+}
+
+func (db *Conn) getExperimentArms(ctx context.Context, id types.UUID, cid types.CID) ([]types.ExperimentArm, error) {
+	rows, err := db.query.QueryContext(ctx, psqls["experiment"]["arms"], id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []types.ExperimentArm{}
+	for rows.Next() {
+		var a types.ExperimentArm
+		if err = rows.Scan(&a.UUID, &a.Name, &a.Control); err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	rows.Close()
+
+	arms, err := db.getExperimentLifecycles(ctx, id)
+	if err != nil || len(arms) == 0 {
+		return result, err
+	}
+
+	p, _ := types.NewReportAttrs(map[string][]string{"experiment-id": {string(id)}})
+
+	lcs, err := db.selectLifecycles(ctx, p, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lc := range lcs {
+		for i := range result {
+			if result[i].UUID == arms[lc.UUID] {
+				result[i].Lifecycles = append(result[i].Lifecycles, lc)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// getExperimentLifecycles maps each lifecycle in the experiment to its arm
+func (db *Conn) getExperimentLifecycles(ctx context.Context, id types.UUID) (map[types.UUID]types.UUID, error) {
+	rows, err := db.query.QueryContext(ctx, psqls["experiment"]["lifecycles"], id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[types.UUID]types.UUID{}
+	for rows.Next() {
+		var lcID, armID types.UUID
+		if err = rows.Scan(&lcID, &armID); err != nil {
+			return nil, err
+		}
+		result[lcID] = armID
+	}
+
+	return result, nil
+}
+
+func (db *Conn) InsertExperiment(ctx context.Context, e types.Experiment, cid types.CID) (types.Experiment, error) {
+	var err error
+	deferred, l := initAccessFuncs("InsertExperiment", db.logger, e.UUID, cid)
+	defer deferred(&err, l)
+
+	e.UUID = types.UUID(db.generateUUID().String())
+	e.CTime = time.Now().UTC()
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["experiment"]["insert"], e.UUID, e.Name, e.Variable, e.CTime)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.InsertExperiment(ctx, e, cid)
+		}
+		err = pqerr(err)
+		return e, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return e, err
+	} else if rows != 1 {
+		err = fmt.Errorf("experiment was not added")
+	}
+
+	return e, err
+}
+
+func (db *Conn) UpdateExperiment(ctx context.Context, id types.UUID, e types.Experiment, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("UpdateExperiment", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result, err := db.ExecContext(ctx, psqls["experiment"]["update"], e.Name, e.Variable, id)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		return fmt.Errorf("experiment was not updated: '%s'", id)
+	}
+	return nil
+}
+
+// DeleteExperiment takes its arms with it, but leaves the lifecycles alone
+func (db *Conn) DeleteExperiment(ctx context.Context, id types.UUID, cid types.CID) error {
+	return db.deleteByUUID(ctx, id, cid, "DeleteExperiment", "experiment", db.logger)
+}
+
+// AddExperimentArm fails with a unique key violation if a is a second control
+func (db *Conn) AddExperimentArm(ctx context.Context, e *types.Experiment, a types.ExperimentArm, cid types.CID) (types.ExperimentArm, error) {
+	var err error
+	deferred, l := initAccessFuncs("AddExperimentArm", db.logger, e.UUID, cid)
+	defer deferred(&err, l)
+
+	a.UUID = types.UUID(db.generateUUID().String())
+	a.Lifecycles = nil
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["experiment"]["add-arm"], a.UUID, e.UUID, a.Name, a.Control)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.AddExperimentArm(ctx, e, a, cid)
+		}
+		err = pqerr(err)
+		return a, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return a, err
+	} else if rows != 1 {
+		err = fmt.Errorf("experiment arm was not added")
+		return a, err
+	}
+
+	if a.Control {
+		e.Arms = append([]types.ExperimentArm{a}, e.Arms...)
+	} else {
+		e.Arms = append(e.Arms, a)
+	}
+
+	return a, err
+}
+
+// RemoveExperimentArm takes the arm's lifecycles out of the experiment too
+func (db *Conn) RemoveExperimentArm(ctx context.Context, e *types.Experiment, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveExperimentArm", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["experiment"]["remove-arm"], id, e.UUID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("experiment arm could not be removed: '%s'", id)
+		return err
+	}
+
+	for i := range e.Arms {
+		if e.Arms[i].UUID == id {
+			e.Arms = append(e.Arms[:i], e.Arms[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// AssignExperimentLifecycle puts the lifecycle in the arm, moving it out of
+// whichever arm of e it was in before
+func (db *Conn) AssignExperimentLifecycle(ctx context.Context, e *types.Experiment, armID, lcID types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AssignExperimentLifecycle", db.logger, lcID, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["experiment"]["assign"], e.UUID, armID, lcID)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("experiment lifecycle was not assigned: '%s'", lcID)
+		return err
+	}
+
+	lc, err := db.SelectLifecycle(ctx, lcID, cid)
+	if err != nil {
+		return err
+	}
+
+	removeExperimentLifecycle(e, lcID)
+	for i := range e.Arms {
+		if e.Arms[i].UUID == armID {
+			e.Arms[i].Lifecycles = append(e.Arms[i].Lifecycles, lc)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (db *Conn) UnassignExperimentLifecycle(ctx context.Context, e *types.Experiment, lcID types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("UnassignExperimentLifecycle", db.logger, lcID, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["experiment"]["unassign"], e.UUID, lcID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("experiment lifecycle could not be unassigned: '%s'", lcID)
+		return err
+	}
+
+	removeExperimentLifecycle(e, lcID)
+
+	return nil
+}
+
+func removeExperimentLifecycle(e *types.Experiment, lcID types.UUID) {
+	for i := range e.Arms {
+		lcs := e.Arms[i].Lifecycles
+		for j := range lcs {
+			if lcs[j].UUID == lcID {
+				e.Arms[i].Lifecycles = append(lcs[:j], lcs[j+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ExperimentReport compares every arm to the control arm, see
+// types.NewExperimentReport
+func (db *Conn) ExperimentReport(ctx context.Context, id types.UUID, cid types.CID) (types.ExperimentReport, error) {
+	var err error
+	deferred, l := initAccessFuncs("ExperimentReport", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	e, err := db.SelectExperiment(ctx, id, cid)
+	if err != nil {
+		return types.ExperimentReport{}, err
+	}
+
+	result := types.NewExperimentReport(e)
+	if result.Units == "" {
+		result.Units = db.unitSystem(ctx)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,643 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_experiments = []types.Experiment{
+		{UUID: "experiment 0", Name: "experiment 0", Variable: "bulk recipe", CTime: wwtbn},
+		{UUID: "experiment 1", Name: "experiment 1", Variable: "humidity", CTime: wwtbn},
+	}
+	experimentFields = row{"uuid", "name", "variable", "ctime"}
+	experimentValues = [][]driver.Value{
+		{_experiments[0].UUID, _experiments[0].Name, _experiments[0].Variable, _experiments[0].CTime},
+		{_experiments[1].UUID, _experiments[1].Name, _experiments[1].Variable, _experiments[1].CTime},
+	}
+
+	armFields = row{"uuid", "name", "control"}
+	armValues = [][]driver.Value{
+		{"control", "control", true},
+		{"treatment", "treatment", false},
+	}
+
+	// _lc is the control, "treated" is the treatment
+	assignmentFields = row{"lifecycle_uuid", "arm_uuid"}
+	assignmentValues = [][]driver.Value{
+		{_lc.UUID, "control"},
+		{"treated", "treatment"},
+	}
+	treatedValues = xformer(lcValues).replace(xform{0: "treated"})
+)
+
+// mockExperiment is everything SelectExperiment asks for, given lifecycles
+// that match assignmentValues
+func mockExperiment(mock *mocker) *mocker {
+	return mock.add(
+		experimentFields.set(experimentValues[0]),
+		armFields.set(armValues...),
+		assignmentFields.set(assignmentValues...),
+		lcFields.set(lcValues, treatedValues),
+		eventFields.set(eventValues...),
+		eventFields.set())
+}
+
+func Test_SelectAllExperiments(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectAllExperiments")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Experiment
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, experimentFields.set(experimentValues...))
+				return db
+			},
+			result: _experiments,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, experimentFields.fail())
+				return db
+			},
+			err: experimentFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectAllExperiments(context.Background(), "Test_SelectAllExperiments")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_SelectExperiment(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectExperiment")
+
+	tcs := map[string]struct {
+		db         getMockDB
+		id         types.UUID
+		arms       []types.UUID
+		lifecycles [][]types.UUID
+		err        error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, mockExperiment)
+				return db
+			},
+			id:         _experiments[0].UUID,
+			arms:       []types.UUID{"control", "treatment"},
+			lifecycles: [][]types.UUID{{_lc.UUID}, {"treated"}},
+		},
+		"no_lifecycles": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					experimentFields.set(experimentValues[1]),
+					armFields.set(armValues...),
+					assignmentFields.set())
+				return db
+			},
+			id:         _experiments[1].UUID,
+			arms:       []types.UUID{"control", "treatment"},
+			lifecycles: [][]types.UUID{{}, {}},
+		},
+		"no_result": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, experimentFields.set())
+				return db
+			},
+			id:  "missing",
+			err: sql.ErrNoRows,
+		},
+		"arms_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					experimentFields.set(experimentValues[0]),
+					armFields.fail())
+				return db
+			},
+			id:  _experiments[0].UUID,
+			err: armFields.err(),
+		},
+		"assignments_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					experimentFields.set(experimentValues[0]),
+					armFields.set(armValues...),
+					assignmentFields.fail())
+				return db
+			},
+			id:  _experiments[0].UUID,
+			err: assignmentFields.err(),
+		},
+		"lifecycles_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					experimentFields.set(experimentValues[0]),
+					armFields.set(armValues...),
+					assignmentFields.set(assignmentValues...),
+					lcFields.fail())
+				return db
+			},
+			id:  _experiments[0].UUID,
+			err: lcFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectExperiment(context.Background(), tc.id, "Test_SelectExperiment")
+
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+			arms, lifecycles := []types.UUID{}, [][]types.UUID{}
+			for _, a := range result.Arms {
+				lcs := []types.UUID{}
+				for _, lc := range a.Lifecycles {
+					lcs = append(lcs, lc.UUID)
+				}
+				arms, lifecycles = append(arms, a.UUID), append(lifecycles, lcs)
+			}
+			require.Equal(t, tc.arms, arms)
+			require.Equal(t, tc.lifecycles, lifecycles)
+		})
+	}
+}
+
+func Test_InsertExperiment(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "InsertExperiment")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("experiment was not added"),
+		},
+		"unique_violation": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(pqError("23505", "name", "experiments", "name", ""))
+				return db
+			},
+			err: fmt.Errorf("unique key violation: name"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).InsertExperiment(context.Background(), types.Experiment{Name: "new"}, "Test_InsertExperiment")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, types.UUID(mockUUIDGen().String()), result.UUID)
+			require.False(t, result.CTime.IsZero())
+		})
+	}
+}
+
+func Test_UpdateExperiment(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "UpdateExperiment")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("experiment was not updated: 'experiment 0'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).UpdateExperiment(context.Background(), _experiments[0].UUID, _experiments[0], "Test_UpdateExperiment")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_DeleteExperiment(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "DeleteExperiment")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("experiment could not be deleted: 'experiment 0'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).DeleteExperiment(context.Background(), _experiments[0].UUID, "Test_DeleteExperiment")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_AddExperimentArm(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddExperimentArm")
+
+	tcs := map[string]struct {
+		db   getMockDB
+		arm  types.ExperimentArm
+		arms []string
+		err  error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			arm:  types.ExperimentArm{Name: "new"},
+			arms: []string{"existing", "new"},
+		},
+		"control_goes_first": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			arm:  types.ExperimentArm{Name: "new", Control: true},
+			arms: []string{"new", "existing"},
+		},
+		"second_control": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(pqError("23505", "experiment_uuid", "experiment_arms", "", "experiment_arms_control"))
+				return db
+			},
+			arm:  types.ExperimentArm{Name: "new", Control: true},
+			arms: []string{"existing"},
+			err:  fmt.Errorf("unique key violation: experiment_uuid"),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			arm:  types.ExperimentArm{Name: "new"},
+			arms: []string{"existing"},
+			err:  fmt.Errorf("experiment arm was not added"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e := types.Experiment{UUID: _experiments[0].UUID, Arms: []types.ExperimentArm{{Name: "existing"}}}
+			_, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddExperimentArm(context.Background(), &e, tc.arm, "Test_AddExperimentArm")
+
+			require.Equal(t, tc.err, err)
+			arms := []string{}
+			for _, a := range e.Arms {
+				arms = append(arms, a.Name)
+			}
+			require.Equal(t, tc.arms, arms)
+		})
+	}
+}
+
+func Test_RemoveExperimentArm(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveExperimentArm")
+
+	tcs := map[string]struct {
+		db   getMockDB
+		arms int
+		err  error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			arms: 1,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			arms: 2,
+			err:  fmt.Errorf("experiment arm could not be removed: 'treatment'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			arms: 2,
+			err:  fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e := types.Experiment{UUID: _experiments[0].UUID, Arms: []types.ExperimentArm{{UUID: "control"}, {UUID: "treatment"}}}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveExperimentArm(context.Background(), &e, "treatment", "Test_RemoveExperimentArm")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.arms, len(e.Arms))
+		})
+	}
+}
+
+func Test_AssignExperimentLifecycle(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AssignExperimentLifecycle")
+
+	tcs := map[string]struct {
+		db         getMockDB
+		lifecycles []int
+		err        error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, lcFields.set(lcValues), eventFields.set(eventValues...))
+				return db
+			},
+			lifecycles: []int{0, 1},
+		},
+		"wrong_experiment": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(pqError("23503", "arm_uuid", "experiment_lifecycles", "arm_uuid", ""))
+				return db
+			},
+			lifecycles: []int{1, 0},
+			err:        fmt.Errorf("foreign key violation: arm_uuid, experiment_lifecycles.arm_uuid"),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			lifecycles: []int{1, 0},
+			err:        fmt.Errorf("experiment lifecycle was not assigned: '%s'", _lc.UUID),
+		},
+		"lifecycle_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, lcFields.fail())
+				return db
+			},
+			lifecycles: []int{1, 0},
+			err:        lcFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// moves _lc from control to treatment
+			e := types.Experiment{UUID: _experiments[0].UUID, Arms: []types.ExperimentArm{
+				{UUID: "control", Lifecycles: []types.Lifecycle{{UUID: _lc.UUID}}},
+				{UUID: "treatment"},
+			}}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AssignExperimentLifecycle(context.Background(), &e, "treatment", _lc.UUID, "Test_AssignExperimentLifecycle")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.lifecycles, []int{len(e.Arms[0].Lifecycles), len(e.Arms[1].Lifecycles)})
+		})
+	}
+}
+
+func Test_UnassignExperimentLifecycle(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "UnassignExperimentLifecycle")
+
+	tcs := map[string]struct {
+		db         getMockDB
+		lifecycles int
+		err        error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			lifecycles: 1,
+			err:        fmt.Errorf("experiment lifecycle could not be unassigned: '%s'", _lc.UUID),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			lifecycles: 1,
+			err:        fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e := types.Experiment{UUID: _experiments[0].UUID, Arms: []types.ExperimentArm{
+				{UUID: "control", Lifecycles: []types.Lifecycle{{UUID: _lc.UUID}}},
+			}}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).UnassignExperimentLifecycle(context.Background(), &e, _lc.UUID, "Test_UnassignExperimentLifecycle")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.lifecycles, len(e.Arms[0].Lifecycles))
+		})
+	}
+}
+
+func Test_ExperimentReport(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ExperimentReport")
+
+	tcs := map[string]struct {
+		db    getMockDB
+		arms  []types.UUID
+		units types.UnitSystem
+		err   error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, mockExperiment)
+				return db
+			},
+			arms: []types.UUID{"control", "treatment"},
+		},
+		"labeled": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, mockExperiment)
+				return db
+			},
+			arms:  []types.UUID{"control", "treatment"},
+			units: types.ImperialUnits,
+		},
+		"experiment_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, experimentFields.fail())
+				return db
+			},
+			err: experimentFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+				units:        tc.units,
+			}).ExperimentReport(context.Background(), _experiments[0].UUID, "Test_ExperimentReport")
+
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+			arms := []types.UUID{}
+			for _, a := range result.Arms {
+				arms = append(arms, a.Arm.UUID)
+				require.Equal(t, 1, a.Contamination.N)
+			}
+			require.Equal(t, tc.arms, arms)
+			require.Equal(t, tc.units, result.Units)
+			require.Equal(t, _experiments[0].UUID, result.Experiment.UUID)
+		})
+	}
+}
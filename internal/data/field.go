@@ -0,0 +1,147 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) GetFields(ctx context.Context, et *types.EventType, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("GetFields", db.logger, et.UUID, cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["field"]["get"], et.UUID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	result := []types.Field{}
+	for rows.Next() {
+		var f types.Field
+		if err = rows.Scan(
+			&f.UUID,
+			&f.Name,
+			&f.Type,
+			&f.Unit,
+			&f.Min,
+			&f.Max,
+		); err != nil {
+			return err
+		}
+		result = append(result, f)
+	}
+
+	et.Fields = result
+
+	return nil
+}
+
+func (db *Conn) AddField(ctx context.Context, et *types.EventType, f types.Field, cid types.CID) (types.Field, error) {
+	var err error
+	deferred, l := initAccessFuncs("AddField", db.logger, et.UUID, cid)
+	defer deferred(&err, l)
+
+	if err = types.CheckField(f); err != nil {
+		return f, err
+	}
+
+	f.UUID = types.UUID(db.generateUUID().String())
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["field"]["add"],
+		f.UUID,
+		f.Name,
+		f.Type,
+		f.Unit,
+		f.Min,
+		f.Max,
+		et.UUID,
+	)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.AddField(ctx, et, f, cid)
+		}
+		err = pqerr(err)
+		return f, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return f, err
+	} else if rows != 1 {
+		err = fmt.Errorf("field was not added")
+		return f, err
+	}
+
+	et.Fields = append(et.Fields, f)
+
+	return f, err
+}
+
+// ChangeField can rename a field, or change its unit or bounds, but not its
+// type; values that were recorded before the bounds changed are kept
+func (db *Conn) ChangeField(ctx context.Context, et *types.EventType, f types.Field, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("ChangeField", db.logger, f.UUID, cid)
+	defer deferred(&err, l)
+
+	if err = types.CheckField(f); err != nil {
+		return err
+	}
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["field"]["change"],
+		f.Name,
+		f.Unit,
+		f.Min,
+		f.Max,
+		f.UUID,
+		et.UUID,
+		f.Type,
+	)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("field was not changed, or its type was: '%s'", f.UUID)
+		return err
+	}
+
+	for i := range et.Fields {
+		if et.Fields[i].UUID == f.UUID {
+			et.Fields[i] = f
+			break
+		}
+	}
+
+	return nil
+}
+
+// RemoveField takes every value recorded for it along
+func (db *Conn) RemoveField(ctx context.Context, et *types.EventType, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveField", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["field"]["remove"], id, et.UUID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("field could not be removed: '%s'", id)
+		return err
+	}
+
+	for i := range et.Fields {
+		if et.Fields[i].UUID == id {
+			et.Fields = append(et.Fields[:i], et.Fields[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	zero    = 0.0
+	_fields = []types.Field{
+		{UUID: "field 0", Name: "diameter", Type: types.NumberField, Unit: "mm", Min: &zero},
+		{UUID: "field 1", Name: "color", Type: types.TextField},
+	}
+	fieldFields = row{"uuid", "name", "type", "unit", "min", "max"}
+	fieldValues = [][]driver.Value{
+		{_fields[0].UUID, _fields[0].Name, _fields[0].Type, _fields[0].Unit, zero, nil},
+		{_fields[1].UUID, _fields[1].Name, _fields[1].Type, _fields[1].Unit, nil, nil},
+	}
+)
+
+func Test_GetFields(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetFields")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Field
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.set(fieldValues...))
+				return db
+			},
+			result: _fields,
+		},
+		"no_fields": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.set())
+				return db
+			},
+			result: []types.Field{},
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.fail())
+				return db
+			},
+			err: fieldFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0"}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetFields(context.Background(), &et, "Test_GetFields")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, et.Fields)
+		})
+	}
+}
+
+func Test_AddField(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddField")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		f      types.Field
+		fields int
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			f:      _fields[0],
+			fields: 2,
+		},
+		"invalid_field": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			f:      types.Field{Name: "color", Type: types.TextField, Min: &zero},
+			fields: 1,
+			err:    fmt.Errorf("only number and integer fields can have bounds: 'color'"),
+		},
+		"duplicate_name": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(pqError("23505", "Key (name, eventtype_uuid)=(diameter, eventtype 0) already exists.", "eventtype_fields", "", "eventtype_fields_name_eventtype_uuid_key"))
+				return db
+			},
+			f:      _fields[0],
+			fields: 1,
+			err:    fmt.Errorf("unique key violation: Key (name, eventtype_uuid)=(diameter, eventtype 0) already exists."),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			f:      _fields[0],
+			fields: 1,
+			err:    fmt.Errorf("field was not added"),
+		},
+		"result_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				return db
+			},
+			f:      _fields[0],
+			fields: 1,
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0", Fields: []types.Field{_fields[1]}}
+			_, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddField(context.Background(), &et, tc.f, "Test_AddField")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.fields, len(et.Fields))
+		})
+	}
+}
+
+func Test_ChangeField(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ChangeField")
+
+	changed := _fields[1]
+	changed.Name = "colour"
+
+	tcs := map[string]struct {
+		db   getMockDB
+		f    types.Field
+		name string
+		err  error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WithArgs("colour", "", nil, nil, _fields[1].UUID, "eventtype 0", types.TextField).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			f:    changed,
+			name: "colour",
+		},
+		"invalid_field": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			f:    types.Field{UUID: _fields[1].UUID, Type: types.TextField},
+			name: "color",
+			err:  fmt.Errorf("a field needs a name"),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			f:    changed,
+			name: "color",
+			err:  fmt.Errorf("field was not changed, or its type was: 'field 1'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			f:    changed,
+			name: "color",
+			err:  fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0", Fields: append([]types.Field{}, _fields...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ChangeField(context.Background(), &et, tc.f, "Test_ChangeField")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.name, et.Fields[1].Name)
+		})
+	}
+}
+
+func Test_RemoveField(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveField")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		id     types.UUID
+		fields int
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			id:     _fields[0].UUID,
+			fields: 1,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			id:     "missing",
+			fields: 2,
+			err:    fmt.Errorf("field could not be removed: 'missing'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			id:     _fields[0].UUID,
+			fields: 2,
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0", Fields: append([]types.Field{}, _fields...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveField(context.Background(), &et, tc.id, "Test_RemoveField")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.fields, len(et.Fields))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) GetFollowUps(ctx context.Context, et *types.EventType, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("GetFollowUps", db.logger, et.UUID, cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["followup"]["get"], et.UUID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	result := []types.FollowUp{}
+	for rows.Next() {
+		var f types.FollowUp
+		var x nulleventtype
+		if err = rows.Scan(
+			&f.UUID,
+			&f.Name,
+			&f.Days,
+			&x.uuid,
+			&x.name,
+			&x.severity,
+			&x.stageUUID,
+			&x.stageName,
+		); err != nil {
+			return err
+		}
+		f.Expects = x.eventType()
+		result = append(result, f)
+	}
+
+	et.FollowUps = result
+
+	return nil
+}
+
+func (db *Conn) AddFollowUp(ctx context.Context, et *types.EventType, f types.FollowUp, cid types.CID) (types.FollowUp, error) {
+	var err error
+	deferred, l := initAccessFuncs("AddFollowUp", db.logger, et.UUID, cid)
+	defer deferred(&err, l)
+
+	f.UUID = types.UUID(db.generateUUID().String())
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["followup"]["add"],
+		f.UUID,
+		f.Name,
+		f.Days,
+		et.UUID,
+		expectsUUID(f),
+	)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.AddFollowUp(ctx, et, f, cid)
+		}
+		err = pqerr(err)
+		return f, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return f, err
+	} else if rows != 1 {
+		err = fmt.Errorf("follow-up was not added")
+		return f, err
+	}
+
+	et.FollowUps = append(et.FollowUps, f)
+
+	return f, err
+}
+
+// ChangeFollowUp moves the due date of any task that's still open, so
+// changing the days changes what's owed as well as what will be
+func (db *Conn) ChangeFollowUp(ctx context.Context, et *types.EventType, f types.FollowUp, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("ChangeFollowUp", db.logger, f.UUID, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["followup"]["change"],
+		f.Name,
+		f.Days,
+		expectsUUID(f),
+		f.UUID,
+		et.UUID,
+	)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("follow-up was not changed: '%s'", f.UUID)
+		return err
+	}
+
+	if _, err = db.ExecContext(ctx, psqls["followup"]["reschedule"], f.UUID); err != nil {
+		return err
+	}
+
+	for i := range et.FollowUps {
+		if et.FollowUps[i].UUID == f.UUID {
+			et.FollowUps[i] = f
+			break
+		}
+	}
+
+	return nil
+}
+
+// RemoveFollowUp takes its tasks with it, done or not
+func (db *Conn) RemoveFollowUp(ctx context.Context, et *types.EventType, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveFollowUp", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["followup"]["remove"], id, et.UUID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("follow-up could not be removed: '%s'", id)
+		return err
+	}
+
+	for i := range et.FollowUps {
+		if et.FollowUps[i].UUID == id {
+			et.FollowUps = append(et.FollowUps[:i], et.FollowUps[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func expectsUUID(f types.FollowUp) *types.UUID {
+	if f.Expects == nil {
+		return nil
+	}
+	return &f.Expects.UUID
+}
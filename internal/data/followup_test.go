@@ -0,0 +1,277 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_followups = []types.FollowUp{
+		{UUID: "followup 0", Name: "check colonization", Days: 7, Expects: &types.EventType{
+			UUID:     _ets[1].UUID,
+			Name:     _ets[1].Name,
+			Severity: _ets[1].Severity,
+			Stage:    _ets[1].Stage,
+		}},
+		{UUID: "followup 1", Name: "shake", Days: 10},
+	}
+	followupFields = row{
+		"uuid",
+		"name",
+		"days",
+		"expects_uuid",
+		"expects_name",
+		"expects_severity",
+		"expects_stage_uuid",
+		"expects_stage_name",
+	}
+	followupValues = [][]driver.Value{
+		{_followups[0].UUID, _followups[0].Name, _followups[0].Days, _ets[1].UUID, _ets[1].Name, _ets[1].Severity, _ets[1].Stage.UUID, _ets[1].Stage.Name},
+		{_followups[1].UUID, _followups[1].Name, _followups[1].Days, nil, nil, nil, nil, nil},
+	}
+)
+
+func Test_GetFollowUps(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetFollowUps")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.FollowUp
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, followupFields.set(followupValues...))
+				return db
+			},
+			result: _followups,
+		},
+		"no_followups": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, followupFields.set())
+				return db
+			},
+			result: []types.FollowUp{},
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, followupFields.fail())
+				return db
+			},
+			err: followupFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0"}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetFollowUps(context.Background(), &et, "Test_GetFollowUps")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, et.FollowUps)
+		})
+	}
+}
+
+func Test_AddFollowUp(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddFollowUp")
+
+	tcs := map[string]struct {
+		db        getMockDB
+		followups int
+		err       error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			followups: 2,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			followups: 1,
+			err:       fmt.Errorf("follow-up was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			followups: 1,
+			err:       fmt.Errorf("some error"),
+		},
+		"result_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				return db
+			},
+			followups: 1,
+			err:       fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0", FollowUps: []types.FollowUp{_followups[1]}}
+			f, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddFollowUp(context.Background(), &et, _followups[0], "Test_AddFollowUp")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.followups, len(et.FollowUps))
+			require.Equal(t, types.UUID("30313233-3435-3637-3839-616263646566"), f.UUID)
+		})
+	}
+}
+
+func Test_ChangeFollowUp(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ChangeFollowUp")
+
+	changed := _followups[1]
+	changed.Days = 3
+
+	tcs := map[string]struct {
+		db   getMockDB
+		days int
+		err  error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 4))
+				return db
+			},
+			days: 3,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			days: 10,
+			err:  fmt.Errorf("follow-up was not changed: 'followup 1'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			days: 10,
+			err:  fmt.Errorf("some error"),
+		},
+		"reschedule_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			days: 10,
+			err:  fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0", FollowUps: append([]types.FollowUp{}, _followups...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ChangeFollowUp(context.Background(), &et, changed, "Test_ChangeFollowUp")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.days, et.FollowUps[1].Days)
+		})
+	}
+}
+
+func Test_RemoveFollowUp(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveFollowUp")
+
+	tcs := map[string]struct {
+		db        getMockDB
+		id        types.UUID
+		followups int
+		err       error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			id:        _followups[0].UUID,
+			followups: 1,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			id:        "missing",
+			followups: 2,
+			err:       fmt.Errorf("follow-up could not be removed: 'missing'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			id:        _followups[0].UUID,
+			followups: 2,
+			err:       fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "eventtype 0", FollowUps: append([]types.FollowUp{}, _followups...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveFollowUp(context.Background(), &et, tc.id, "Test_RemoveFollowUp")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.followups, len(et.FollowUps))
+		})
+	}
+}
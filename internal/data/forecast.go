@@ -0,0 +1,33 @@
+package data
+
+import (
+	"context"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) LifecycleForecast(ctx context.Context, id types.UUID, cid types.CID) (types.Forecast, error) {
+	var err error
+	deferred, l := initAccessFuncs("LifecycleForecast", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	lc, err := db.SelectLifecycle(ctx, id, cid)
+	if err != nil {
+		return types.Forecast{}, err
+	}
+
+	return db.forecast(ctx, lc, cid)
+}
+
+func (db *Conn) forecast(ctx context.Context, lc types.Lifecycle, cid types.CID) (types.Forecast, error) {
+	var err error
+	deferred, l := initAccessFuncs("forecast", db.logger, lc.UUID, cid)
+	defer deferred(&err, l)
+
+	history, err := db.selectLifecycleEvents(ctx, types.Window{}, &lc.Strain.UUID, cid)
+	if err != nil {
+		return types.Forecast{}, err
+	}
+
+	return types.NewForecast(lc, history), nil
+}
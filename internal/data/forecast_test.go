@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_LifecycleForecast(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "LifecycleForecast")
+
+	// _lc has no stages of its own, so it's anchored on its ctime; history
+	// took 10 days to colonize
+	colonized := wwtbn.Add(10 * 24 * time.Hour)
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.Forecast
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					lcEventFields.set(
+						lcEventValues.replace(xform{1: _lc.Location.UUID, 2: _lc.Location.Name, 8: "gs", 10: "bs"}),
+						lcEventValues.replace(xform{1: _lc.Location.UUID, 2: _lc.Location.Name, 8: "gs", 10: "bs", 12: "e1", 15: stageEpoch.AddDate(0, 0, 10), 21: "2", 22: types.MajorityStage}),
+					))
+				return db
+			},
+			result: types.Forecast{
+				Basis:  []types.Dimension{types.StrainDimension, types.GrainDimension, types.BulkDimension, types.LocationDimension},
+				Anchor: wwtbn,
+				Colonized: &types.Estimate{
+					Samples:  1,
+					Expected: colonized,
+					Low:      colonized,
+					High:     colonized,
+				},
+			},
+		},
+		"no_history": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					lcEventFields.set())
+				return db
+			},
+			result: types.Forecast{Basis: []types.Dimension{}, Anchor: wwtbn},
+		},
+		"select_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcFields.fail())
+				return db
+			},
+			err: lcFields.err(),
+		},
+		"history_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					lcEventFields.fail())
+				return db
+			},
+			err: lcEventFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).LifecycleForecast(context.Background(), _lc.UUID, "Test_LifecycleForecast")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/jsmit257/huautla/types"
 )
 
-func (db *Conn) SelectGenerationIndex(ctx context.Context, cid types.CID) ([]types.Generation, error) {
+func (db *Conn) SelectGenerationIndex(ctx context.Context, cid types.CID, filter ...types.Status) ([]types.Generation, error) {
 	var err error
 
 	deferred, l := initAccessFuncs("SelectGenerationIndex", db.logger, "nil", cid)
@@ -20,7 +20,7 @@ func (db *Conn) SelectGenerationIndex(ctx context.Context, cid types.CID) ([]typ
 
 	result := make([]types.Generation, 0, 100)
 
-	rows, err = db.query.QueryContext(ctx, psqls["generation"]["ndx"])
+	rows, err = db.query.QueryContext(ctx, psqls["generation"]["ndx"], tagFilter(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +122,24 @@ func (db *Conn) SelectGenerationIndex(ctx context.Context, cid types.CID) ([]typ
 		result = append(result, *row)
 	}
 
-	return result, err
+	ids := make([]types.UUID, len(result))
+	for i, g := range result {
+		ids[i] = g.UUID
+	}
+
+	statuses, err := db.selectStatuses(ctx, psqls["event"]["generation-severities"], ids, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := result[:0]
+	for _, g := range result {
+		if g.Status = statuses.of(g.UUID); g.Status.Matches(filter) {
+			filtered = append(filtered, g)
+		}
+	}
+
+	return filtered, err
 }
 
 func (db *Conn) SelectGeneration(ctx context.Context, id types.UUID, cid types.CID) (types.Generation, error) {
@@ -149,25 +166,77 @@ func (db *Conn) SelectGeneration(ctx context.Context, id types.UUID, cid types.C
 	return types.Generation{}, err
 }
 
+// SelectGenerations is SelectGeneration for every one of ids, with one query
+// each for the generations, their events, their sources and the lifecycles
+// those came from; an id that doesn't exist isn't in the result
+func (db *Conn) SelectGenerations(ctx context.Context, ids []types.UUID, cid types.CID) ([]types.Generation, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectGenerations", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	result, err := db.scanGenerations(ctx, nil, nil, nil, nil, nil, uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := db.selectEventsFor(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := db.sourcesFor(ctx, ids, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		result[i].Events = events[result[i].UUID]
+		result[i].Status = types.NewStatus(result[i].Events)
+		result[i].Sources = sources[result[i].UUID]
+	}
+
+	return result, nil
+}
+
 func (db *Conn) selectGenerations(ctx context.Context, p types.ReportAttrs, cid types.CID) ([]types.Generation, error) {
 	var err error
 
 	deferred, l := initAccessFuncs("selectGenerations", db.logger, "nil", cid)
 	defer deferred(&err, l)
 
-	result := make([]types.Generation, 0, 100)
-
 	if !p.Contains("generation-id", "strain-id", "plating-id", "liquid-id", "eventtype-id") {
 		err = fmt.Errorf("request doesn't contain at least 1 required field")
-		return result, err
+		return make([]types.Generation, 0, 100), err
 	}
 
-	rows, err := db.query.QueryContext(ctx, psqls["generation"]["select"],
+	result, err := db.scanGenerations(ctx,
 		p.Get("generation-id"),
 		p.Get("strain-id"),
 		p.Get("plating-id"),
 		p.Get("liquid-id"),
-		p.Get("eventtype-id"))
+		p.Get("eventtype-id"),
+		nil)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range result {
+		if err = db.GetGenerationEvents(ctx, &result[i], cid); err != nil {
+			break
+		} else if err = db.GetSources(ctx, &result[i], cid); err != nil {
+			break
+		}
+	}
+
+	return result, err
+}
+
+// scanGenerations is the generation "select" query without any events or
+// sources; rows is closed before it returns, so those can be queried next
+func (db *Conn) scanGenerations(ctx context.Context, args ...any) ([]types.Generation, error) {
+	result := make([]types.Generation, 0, 100)
+
+	rows, err := db.query.QueryContext(ctx, psqls["generation"]["select"], args...)
 	if err != nil {
 		return nil, err
 	}
@@ -196,10 +265,6 @@ func (db *Conn) selectGenerations(ctx context.Context, p types.ReportAttrs, cid
 			&row.DTime,
 		); err != nil {
 			break
-		} else if err = db.GetGenerationEvents(ctx, &row, cid); err != nil {
-			break
-		} else if err = db.GetSources(ctx, &row, cid); err != nil {
-			break
 		}
 
 		result = append(result, row)
@@ -79,6 +79,13 @@ var (
 	}
 )
 
+// genEntity is what _gen looks like in a report, once its events have been counted
+func genEntity(status types.Status) types.Entity {
+	result := mustEntity(_gen)
+	result["status"] = string(status)
+	return result
+}
+
 func Test_SelectGenerationIndex(t *testing.T) {
 	t.Parallel()
 
@@ -113,8 +120,11 @@ func Test_SelectGenerationIndex(t *testing.T) {
 
 	l := log.WithField("test", "SelectGenerationIndex")
 
+	statuses := statusFields.set([]driver.Value{"happy_path 2", "Mold", types.ErrorSeverity})
+
 	tcs := map[string]struct {
 		db     getMockDB
+		filter []types.Status
 		result []types.Generation
 		err    error
 	}{
@@ -125,13 +135,17 @@ func Test_SelectGenerationIndex(t *testing.T) {
 					AddRow("happy_path", "plating_id", "plating_name", "plating_type", "plating_vendor_id", "plating_vendor_name", "plating_vendor_website", "liquid_id", "liquid_name", "liquid_type", "liquid_vendor_id", "liquid_vendor_name", "liquid_vendor_website", "source_uuid 0", "spore", "lifecycle_uuid", "strain_uuid", "strain_name", "strain_species", wwtbn, "strain_vendor_id", "strain_vendor_name", "strain_vendor_website", wwtbn, wwtbn, nil).
 					AddRow("happy_path", "plating_id", "plating_name", "plating_type", "plating_vendor_id", "plating_vendor_name", "plating_vendor_website", "liquid_id", "liquid_name", "liquid_type", "liquid_vendor_id", "liquid_vendor_name", "liquid_vendor_website", "source_uuid 1", "spore", "lifecycle_uuid", "strain_uuid", "strain_name", "strain_species", wwtbn, "strain_vendor_id", "strain_vendor_name", "strain_vendor_website", wwtbn, wwtbn, nil).
 					AddRow("happy_path 2", "plating_id", "plating_name", "plating_type", "plating_vendor_id", "plating_vendor_name", "plating_vendor_website", "liquid_id", "liquid_name", "liquid_type", "liquid_vendor_id", "liquid_vendor_name", "liquid_vendor_website", "source_uuid 0", "spore", "lifecycle_uuid", "strain_uuid", "strain_name", "strain_species", wwtbn, "strain_vendor_id", "strain_vendor_name", "strain_vendor_website", wwtbn, wwtbn, nil))
+				mock.ExpectQuery("").
+					WithArgs(`{"happy_path","happy_path 2"}`).
+					WillReturnRows(sqlmock.NewRows(statusFields).AddRow("happy_path 2", "Mold", types.ErrorSeverity))
 				return db
 			},
 			result: []types.Generation{
 				{
-					UUID:  "happy_path",
-					MTime: wwtbn,
-					CTime: wwtbn,
+					UUID:   "happy_path",
+					Status: types.PendingStatus,
+					MTime:  wwtbn,
+					CTime:  wwtbn,
 					PlatingSubstrate: types.Substrate{
 						UUID: "plating_id",
 						Name: "plating_name",
@@ -168,9 +182,10 @@ func Test_SelectGenerationIndex(t *testing.T) {
 					},
 				},
 				{
-					UUID:  "happy_path 2",
-					MTime: wwtbn,
-					CTime: wwtbn,
+					UUID:   "happy_path 2",
+					Status: types.ContaminatedStatus,
+					MTime:  wwtbn,
+					CTime:  wwtbn,
 					PlatingSubstrate: types.Substrate{
 						UUID: "plating_id",
 						Name: "plating_name",
@@ -202,6 +217,25 @@ func Test_SelectGenerationIndex(t *testing.T) {
 				},
 			},
 		},
+		"filtered_out": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").WillReturnRows(sqlmock.
+					NewRows(fields).
+					AddRow("happy_path", "plating_id", "plating_name", "plating_type", "plating_vendor_id", "plating_vendor_name", "plating_vendor_website", "liquid_id", "liquid_name", "liquid_type", "liquid_vendor_id", "liquid_vendor_name", "liquid_vendor_website", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, wwtbn, wwtbn, nil))
+				newBuilder(mock, statuses)
+				return db
+			},
+			filter: []types.Status{types.DeadStatus},
+			result: []types.Generation{},
+		},
+		"statuses_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").WillReturnRows(sqlmock.NewRows(fields))
+				newBuilder(mock, statusFields.fail())
+				return db
+			},
+			err: statusFields.err(),
+		},
 		"db_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				newBuilder(mock, genFields.fail()) // not really what we're sleecting, but it throws an error, so...
@@ -220,7 +254,7 @@ func Test_SelectGenerationIndex(t *testing.T) {
 				query:        v.db(sqlmock.New()),
 				generateUUID: mockUUIDGen,
 				logger:       l.WithField("name", k),
-			}).SelectGenerationIndex(context.Background(), "Test_SelectGenerationIndex")
+			}).SelectGenerationIndex(context.Background(), "Test_SelectGenerationIndex", v.filter...)
 
 			require.Equal(t, v.err, err)
 			require.Equal(t, mustObject(v.result), mustObject(result))
@@ -251,9 +285,10 @@ func Test_SelectGenerationsByStrain(t *testing.T) {
 			},
 			result: []types.Generation{
 				{
-					UUID:  "uuid",
-					MTime: wwtbn,
-					CTime: wwtbn,
+					UUID:   "uuid",
+					Status: types.PendingStatus,
+					MTime:  wwtbn,
+					CTime:  wwtbn,
 					PlatingSubstrate: types.Substrate{
 						UUID: "platingsubstrate_uuid",
 						Name: "platingsubstrate_name",
@@ -812,3 +847,93 @@ func Test_GenerationReport(t *testing.T) {
 		})
 	}
 }
+
+func Test_SelectGenerations(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectGenerations")
+
+	events := eventFields.keyed("observable_uuid")
+	sources := srcFields.keyed("generation_uuid")
+	// the second source came from an event in _lc
+	fromEvent := xformer(srcValues[0]).replace(xform{2: _events[1].UUID, 3: _lc.UUID})
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Generation
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					genFields.set(genValues),
+					events.set(keyed(_gen.UUID, eventValues...)...),
+					sources.set(keyed(_gen.UUID, srcValues[0], fromEvent)...),
+					lcFields.set(lcValues),
+					events.set(keyed(_lc.UUID, eventValues...)...))
+				return db
+			},
+			result: []types.Generation{
+				func(g generation) types.Generation {
+					g.Events = []types.Event{
+						types.Event(_events[0]),
+						types.Event(_events[1]),
+						types.Event(_events[2]),
+					}
+					g.Status = types.ActiveStatus
+					g.Sources = []types.Source{
+						_src,
+						func(s types.Source) types.Source {
+							lc := types.Lifecycle(_lc)
+							lc.Events = []types.Event{types.Event(_events[1])}
+							lc.Status = types.ActiveStatus
+							s.Lifecycle = &lc
+							return s
+						}(_src),
+					}
+					return types.Generation(g)
+				}(_gen),
+			},
+		},
+		"sources_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					genFields.set(genValues),
+					events.set(),
+					sources.fail())
+				return db
+			},
+			err: sources.err(),
+		},
+		"events_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, genFields.set(genValues), events.fail())
+				return db
+			},
+			err: events.err(),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, genFields.fail())
+				return db
+			},
+			err: genFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectGenerations(context.Background(), []types.UUID{_gen.UUID}, "Test_SelectGenerations")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
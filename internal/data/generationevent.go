@@ -16,6 +16,7 @@ func (db *Conn) GetGenerationEvents(ctx context.Context, g *types.Generation, ci
 	defer deferred(&err, l)
 
 	g.Events, err = db.selectEventsList(ctx, psqls["event"]["all-by-observable"], g.UUID, cid)
+	g.Status = types.NewStatus(g.Events)
 
 	return err
 }
@@ -26,7 +27,10 @@ func (db *Conn) AddGenerationEvent(ctx context.Context, g *types.Generation, e t
 	deferred, l := initAccessFuncs("AddGenerationEvent", db.logger, g.UUID, cid)
 	defer deferred(&err, l)
 
-	if g.Events, err = db.addEvent(ctx, g.UUID, g.Events, &e, cid); err != nil {
+	g.Events, err = db.addEvent(ctx, g.UUID, g.Events, &e, cid)
+	g.Status = types.NewStatus(g.Events)
+
+	if err != nil {
 		return err
 	} else if _, err = db.UpdateGenerationMTime(ctx, g, e.MTime, cid); err != nil {
 		return fmt.Errorf("couldn't update Generation.mtime")
@@ -40,9 +44,12 @@ func (db *Conn) ChangeGenerationEvent(ctx context.Context, g *types.Generation,
 	deferred, l := initAccessFuncs("ChangeEvent", db.logger, g.UUID, cid)
 	defer deferred(&err, l)
 
-	if g.Events, err = db.changeEvent(ctx, g.Events, &e, cid); err != nil {
+	g.Events, err = db.changeEvent(ctx, g.Events, &e, cid)
+	g.Status = types.NewStatus(g.Events)
+
+	if err != nil {
 		return e, err
-	} else if _, err = db.UpdateGenerationMTime(ctx, g, g.Events[0].MTime, cid); err != nil {
+	} else if _, err = db.UpdateGenerationMTime(ctx, g, e.MTime, cid); err != nil {
 		return e, err
 	}
 
@@ -55,7 +62,10 @@ func (db *Conn) RemoveGenerationEvent(ctx context.Context, g *types.Generation,
 	deferred, l := initAccessFuncs("RemoveEvent", db.logger, g.UUID, cid)
 	defer deferred(&err, l)
 
-	if g.Events, err = db.removeEvent(ctx, g.Events, id, cid); err != nil {
+	g.Events, err = db.removeEvent(ctx, g.Events, id, cid)
+	g.Status = types.NewStatus(g.Events)
+
+	if err != nil {
 		return err
 	} else if _, err = db.UpdateGenerationMTime(ctx, g, time.Now().UTC(), cid); err != nil {
 		return err
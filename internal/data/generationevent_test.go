@@ -30,10 +30,10 @@ func Test_GetGenerationEvents(t *testing.T) {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				mock.ExpectQuery("").
 					WillReturnRows(sqlmock.
-						NewRows([]string{"id", "temperature", "humidity", "mtime", "ctime", "eventtype_uuid", "event_severity", "eventtype_name", "stage_uuid", "stage_name"}).
-						AddRow(e0.UUID, e0.Temperature, e0.Humidity, e0.MTime, e0.CTime, e0.EventType.UUID, e0.EventType.Name, e0.EventType.Severity, e0.EventType.Stage.UUID, e0.EventType.Stage.Name).
-						AddRow(e1.UUID, e1.Temperature, e1.Humidity, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name).
-						AddRow(e2.UUID, e2.Temperature, e2.Humidity, e2.MTime, e2.CTime, e2.EventType.UUID, e2.EventType.Name, e2.EventType.Severity, e2.EventType.Stage.UUID, e2.EventType.Stage.Name))
+						NewRows([]string{"id", "temperature", "humidity", "occurred_at", "mtime", "ctime", "eventtype_uuid", "event_severity", "eventtype_name", "stage_uuid", "stage_name", "measurements"}).
+						AddRow(e0.UUID, e0.Temperature, e0.Humidity, e0.OccurredAt, e0.MTime, e0.CTime, e0.EventType.UUID, e0.EventType.Name, e0.EventType.Severity, e0.EventType.Stage.UUID, e0.EventType.Stage.Name, nil).
+						AddRow(e1.UUID, e1.Temperature, e1.Humidity, e1.OccurredAt, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name, nil).
+						AddRow(e2.UUID, e2.Temperature, e2.Humidity, e2.OccurredAt, e2.MTime, e2.CTime, e2.EventType.UUID, e2.EventType.Name, e2.EventType.Severity, e2.EventType.Stage.UUID, e2.EventType.Stage.Name, nil))
 				return db
 			},
 			result: []types.Event{e0, e1, e2},
@@ -84,8 +84,11 @@ func Test_AddGenerationEvent(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				eventFields.mock(mock)
 				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
@@ -95,8 +98,11 @@ func Test_AddGenerationEvent(t *testing.T) {
 		},
 		"modified_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				eventFields.mock(mock)
 				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
@@ -107,29 +113,48 @@ func Test_AddGenerationEvent(t *testing.T) {
 		},
 		"eventtype_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				eventFields.mock(mock)
 				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
 			err: fmt.Errorf("couldn't fetch eventtype"),
 		},
+		"events_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.fail())
+				return db
+			},
+			err: eventFields.err(),
+		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock)
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("event was not added"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock)
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock)
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
@@ -179,7 +204,9 @@ func Test_ChangeGenerationEvent(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				etFields.mock(mock, etValues[0])
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
@@ -191,7 +218,9 @@ func Test_ChangeGenerationEvent(t *testing.T) {
 		},
 		"modified_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				etFields.mock(mock, etValues[0])
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("couldn't update Generation.mtime"))
 				return db
@@ -203,7 +232,9 @@ func Test_ChangeGenerationEvent(t *testing.T) {
 		},
 		"eventtype_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
@@ -211,21 +242,27 @@ func Test_ChangeGenerationEvent(t *testing.T) {
 		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("event was not changed"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
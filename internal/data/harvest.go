@@ -0,0 +1,241 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) GetHarvests(ctx context.Context, lc *types.Lifecycle, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("GetHarvests", db.logger, lc.UUID, cid)
+	defer deferred(&err, l)
+
+	var rows *sql.Rows
+	result := []types.Harvest{}
+
+	rows, err = db.query.QueryContext(ctx, psqls["harvest"]["get"], lc.UUID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h types.Harvest
+		var noteid *types.UUID
+		var notetext *string
+		var notemtime, notectime *time.Time
+
+		if err = rows.Scan(
+			&h.UUID,
+			&h.Date,
+			&h.FreshWeight,
+			&h.DryWeight,
+			&h.Count,
+			&h.Grade,
+			&h.MTime,
+			&h.CTime,
+			&noteid,
+			&notetext,
+			&notemtime,
+			&notectime,
+		); err != nil {
+			return err
+		}
+
+		if noteid != nil {
+			h.Notes = []types.Note{{
+				UUID:  *noteid,
+				Note:  *notetext,
+				MTime: *notemtime,
+				CTime: *notectime,
+			}}
+		}
+
+		if curr := len(result) - 1; curr == -1 || result[curr].UUID != h.UUID {
+			result = append(result, h)
+		} else {
+			result[curr].Notes = append(result[curr].Notes, h.Notes...)
+		}
+	}
+
+	units := db.unitSystem(ctx)
+	for i := range result {
+		result[i] = result[i].InUnits(units)
+	}
+	lc.Harvests = result
+
+	return nil
+}
+
+func (db *Conn) AddHarvest(ctx context.Context, lc *types.Lifecycle, h types.Harvest, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AddHarvest", db.logger, lc.UUID, cid)
+	defer deferred(&err, l)
+
+	h.UUID = types.UUID(db.generateUUID().String())
+	h.CTime = time.Now().UTC()
+	h.MTime = h.CTime
+	if h.Date.IsZero() {
+		h.Date = h.CTime
+	}
+
+	fresh, dry := db.harvestWeights(ctx, &h)
+
+	tallied := *lc
+	err = db.inTx(ctx, func(tx *Conn) error {
+		result, err := tx.ExecContext(ctx, psqls["harvest"]["add"],
+			h.UUID,
+			h.Date,
+			fresh,
+			dry,
+			h.Count,
+			h.Grade,
+			lc.UUID,
+			h.MTime,
+			h.CTime,
+		)
+		if err != nil {
+			return err
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 {
+			return fmt.Errorf("harvest was not added")
+		}
+
+		return tx.tallyHarvests(ctx, &tallied, h.MTime, cid)
+	})
+	if isPrimaryKeyViolation(err) {
+		// the transaction is spoiled by now, so the retry needs its own
+		return db.AddHarvest(ctx, lc, h, cid)
+	} else if err != nil {
+		err = pqerr(err)
+		return err
+	}
+
+	*lc = tallied
+	lc.Harvests = append(lc.Harvests, h.InUnits(db.unitSystem(ctx)))
+	types.SortHarvests(lc.Harvests)
+
+	return nil
+}
+
+func (db *Conn) ChangeHarvest(ctx context.Context, lc *types.Lifecycle, h types.Harvest, cid types.CID) (types.Harvest, error) {
+	var err error
+	deferred, l := initAccessFuncs("ChangeHarvest", db.logger, h.UUID, cid)
+	defer deferred(&err, l)
+
+	h.MTime = time.Now().UTC()
+
+	fresh, dry := db.harvestWeights(ctx, &h)
+
+	tallied := *lc
+	if err = db.inTx(ctx, func(tx *Conn) error {
+		result, err := tx.ExecContext(ctx, psqls["harvest"]["change"],
+			h.Date,
+			fresh,
+			dry,
+			h.Count,
+			h.Grade,
+			h.MTime,
+			h.UUID,
+			lc.UUID,
+		)
+		if err != nil {
+			return err
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 {
+			return fmt.Errorf("harvest was not changed")
+		}
+
+		return tx.tallyHarvests(ctx, &tallied, h.MTime, cid)
+	}); err != nil {
+		return h, err
+	}
+
+	*lc = tallied
+	h = h.InUnits(db.unitSystem(ctx))
+
+	for i := range lc.Harvests {
+		if lc.Harvests[i].UUID == h.UUID {
+			h.CTime, h.Notes = lc.Harvests[i].CTime, lc.Harvests[i].Notes
+			lc.Harvests[i] = h
+			break
+		}
+	}
+	types.SortHarvests(lc.Harvests)
+
+	return h, nil
+}
+
+func (db *Conn) RemoveHarvest(ctx context.Context, lc *types.Lifecycle, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveHarvest", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	tallied := *lc
+	if err = db.inTx(ctx, func(tx *Conn) error {
+		result, err := tx.ExecContext(ctx, psqls["harvest"]["remove"], id, lc.UUID)
+		if err != nil {
+			return pqerr(err)
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 {
+			return fmt.Errorf("harvest could not be removed")
+		}
+
+		return tx.tallyHarvests(ctx, &tallied, time.Now().UTC(), cid)
+	}); err != nil {
+		return err
+	}
+
+	*lc = tallied
+	for i := range lc.Harvests {
+		if lc.Harvests[i].UUID == id {
+			lc.Harvests = append(lc.Harvests[:i], lc.Harvests[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// tallyHarvests makes the lifecycle's yield, count and gross agree with the
+// harvests table, whether or not lc.Harvests was loaded first; it has to run
+// in the same transaction as whatever changed the harvests
+func (db *Conn) tallyHarvests(ctx context.Context, lc *types.Lifecycle, modified time.Time, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("tallyHarvests", db.logger, lc.UUID, cid)
+	defer deferred(&err, l)
+
+	var yield, gross float32
+
+	if err = db.QueryRowContext(ctx, psqls["harvest"]["tally"], lc.UUID, modified).Scan(
+		&yield,
+		&lc.Count,
+		&gross,
+	); err != nil {
+		return err
+	}
+
+	if lc.Units == "" {
+		lc.Units = db.unitSystem(ctx)
+	}
+	lc.Yield, lc.Gross = lc.Units.Weight(yield), lc.Units.Weight(gross)
+	lc.MTime = modified
+
+	return nil
+}
+
+// harvestWeights are h's fresh and dry weights in grams, for storing; an
+// unlabeled h is taken to be in the units ctx asked for, and gets labeled
+func (db *Conn) harvestWeights(ctx context.Context, h *types.Harvest) (fresh, dry float32) {
+	if h.Units == "" {
+		h.Units = db.unitSystem(ctx)
+	}
+	return h.Units.Grams(h.FreshWeight), h.Units.Grams(h.DryWeight)
+}
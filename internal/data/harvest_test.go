@@ -0,0 +1,387 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_harvests = []types.Harvest{
+		{UUID: "harvest 0", Date: wwtbn, FreshWeight: 300, DryWeight: 30, Count: 10, Grade: "A", MTime: wwtbn, CTime: wwtbn},
+		{UUID: "harvest 1", Date: wwtbn.Add(168 * time.Hour), FreshWeight: 100, MTime: wwtbn, CTime: wwtbn, Notes: []types.Note{
+			types.Note(_notes[0]),
+			types.Note(_notes[2]),
+		}},
+	}
+	harvestFields = row{
+		"id",
+		"harvest_date",
+		"fresh_weight",
+		"dry_weight",
+		"headcount",
+		"grade",
+		"mtime",
+		"ctime",
+		"note_uuid",
+		"note",
+		"note_mtime",
+		"note_ctime",
+	}
+	harvestValues = [][]driver.Value{
+		{_harvests[0].UUID, _harvests[0].Date, _harvests[0].FreshWeight, _harvests[0].DryWeight, _harvests[0].Count, _harvests[0].Grade, _harvests[0].MTime, _harvests[0].CTime, nil, nil, nil, nil},
+		{_harvests[1].UUID, _harvests[1].Date, _harvests[1].FreshWeight, _harvests[1].DryWeight, _harvests[1].Count, _harvests[1].Grade, _harvests[1].MTime, _harvests[1].CTime, _notes[0].UUID, _notes[0].Note, _notes[0].MTime, _notes[0].CTime},
+		{_harvests[1].UUID, _harvests[1].Date, _harvests[1].FreshWeight, _harvests[1].DryWeight, _harvests[1].Count, _harvests[1].Grade, _harvests[1].MTime, _harvests[1].CTime, _notes[2].UUID, _notes[2].Note, _notes[2].MTime, _notes[2].CTime},
+	}
+	tallyFields = row{"yield", "headcount", "gross"}
+	tallyValues = []driver.Value{130, 10, 400}
+)
+
+func Test_GetHarvests(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetHarvests")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Harvest
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, harvestFields.set(harvestValues...))
+				return db
+			},
+			result: _harvests,
+		},
+		"no_harvests": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, harvestFields.set())
+				return db
+			},
+			result: []types.Harvest{},
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, harvestFields.fail())
+				return db
+			},
+			err: harvestFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: "lifecycle 0"}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetHarvests(context.Background(), &lc, "Test_GetHarvests")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, lc.Harvests)
+		})
+	}
+}
+
+func Test_AddHarvest(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddHarvest")
+
+	tcs := map[string]struct {
+		db       getMockDB
+		h        types.Harvest
+		units    types.UnitSystem
+		harvests int
+		yield    float32
+		gross    float32
+		err      error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.set(tallyValues))
+				mock.ExpectCommit()
+				return db
+			},
+			h:        types.Harvest{FreshWeight: 100},
+			harvests: 2,
+			yield:    130,
+			gross:    400,
+		},
+		"imperial": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").
+					WithArgs(
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						float64(float32(28.349524)),
+						float64(0),
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg(),
+						sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.set(xformer(tallyValues).replace(xform{0: 283.49524, 2: 2834.9524})))
+				mock.ExpectCommit()
+				return db
+			},
+			h:        types.Harvest{FreshWeight: 1},
+			units:    types.ImperialUnits,
+			harvests: 2,
+			yield:    10,
+			gross:    100,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			harvests: 1,
+			err:      fmt.Errorf("harvest was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			harvests: 1,
+			err:      fmt.Errorf("some error"),
+		},
+		"result_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				mock.ExpectRollback()
+				return db
+			},
+			harvests: 1,
+			err:      fmt.Errorf("some error"),
+		},
+		"tally_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			harvests: 1,
+			err:      tallyFields.err(),
+		},
+		"begin_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin().WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			harvests: 1,
+			err:      fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: "lifecycle 0", Harvests: []types.Harvest{_harvests[0]}}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+				units:        tc.units,
+			}).AddHarvest(context.Background(), &lc, tc.h, "Test_AddHarvest")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.harvests, len(lc.Harvests))
+			if tc.err == nil {
+				require.Equal(t, _harvests[0], lc.Harvests[0])
+				require.False(t, lc.Harvests[1].Date.IsZero())
+				require.Equal(t, tc.h.FreshWeight, lc.Harvests[1].FreshWeight)
+				require.Equal(t, tc.yield, lc.Yield)
+				require.Equal(t, int16(10), lc.Count)
+				require.Equal(t, tc.gross, lc.Gross)
+				require.Equal(t, tc.units, lc.Units)
+			}
+		})
+	}
+}
+
+func Test_ChangeHarvest(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ChangeHarvest")
+
+	// moved in front of the other one
+	early := types.Harvest{UUID: _harvests[1].UUID, Date: wwtbn.Add(-time.Hour), FreshWeight: 50}
+
+	tcs := map[string]struct {
+		db    getMockDB
+		h     types.Harvest
+		first types.UUID
+		err   error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.set(tallyValues))
+				mock.ExpectCommit()
+				return db
+			},
+			h:     early,
+			first: early.UUID,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			h:     early,
+			first: _harvests[0].UUID,
+			err:   fmt.Errorf("harvest was not changed"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			h:     early,
+			first: _harvests[0].UUID,
+			err:   fmt.Errorf("some error"),
+		},
+		"tally_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			h:     early,
+			first: _harvests[0].UUID,
+			err:   tallyFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: "lifecycle 0", Harvests: append([]types.Harvest{}, _harvests...)}
+			h, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ChangeHarvest(context.Background(), &lc, tc.h, "Test_ChangeHarvest")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.first, lc.Harvests[0].UUID)
+			if tc.err == nil {
+				require.Equal(t, _harvests[1].Notes, h.Notes)
+			}
+		})
+	}
+}
+
+func Test_RemoveHarvest(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveHarvest")
+
+	tcs := map[string]struct {
+		db       getMockDB
+		id       types.UUID
+		harvests int
+		err      error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.set(tallyValues))
+				mock.ExpectCommit()
+				return db
+			},
+			id:       _harvests[0].UUID,
+			harvests: 1,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			id:       "missing",
+			harvests: 2,
+			err:      fmt.Errorf("harvest could not be removed"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			id:       _harvests[0].UUID,
+			harvests: 2,
+			err:      fmt.Errorf("some error"),
+		},
+		"tally_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, tallyFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			id:       _harvests[0].UUID,
+			harvests: 2,
+			err:      tallyFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: "lifecycle 0", Harvests: append([]types.Harvest{}, _harvests...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveHarvest(context.Background(), &lc, tc.id, "Test_RemoveHarvest")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.harvests, len(lc.Harvests))
+		})
+	}
+}
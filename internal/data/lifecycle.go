@@ -10,14 +10,14 @@ import (
 	"github.com/jsmit257/huautla/types"
 )
 
-func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID) ([]types.Lifecycle, error) {
+func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID, filter ...types.Status) ([]types.Lifecycle, error) {
 	var err error
 	deferred, l := initAccessFuncs("SelectLifecycleIndex", db.logger, "nil", cid)
 	defer deferred(&err, l)
 
 	result := make([]types.Lifecycle, 0, 1000)
 
-	rows, err := db.query.QueryContext(ctx, psqls["lifecycle"]["index"])
+	rows, err := db.query.QueryContext(ctx, psqls["lifecycle"]["index"], tagFilter(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +26,7 @@ func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID) ([]type
 	for rows.Next() {
 		var eID, etID, stID *types.UUID
 		var etName, etSev, stName *string
-		var mtime, ctime *time.Time
+		var occurred, mtime, ctime *time.Time
 		var temp *float32
 		var hum *int8
 
@@ -34,7 +34,9 @@ func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID) ([]type
 
 		if err = rows.Scan(
 			&row.UUID,
-			&row.Location,
+			&row.Location.UUID,
+			&row.Location.Name,
+			&row.Location.Kind,
 			&row.MTime,
 			&row.CTime,
 			&row.Strain.UUID,
@@ -47,6 +49,7 @@ func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID) ([]type
 			&eID,
 			&temp,
 			&hum,
+			&occurred,
 			&mtime,
 			&ctime,
 			&etID,
@@ -63,6 +66,7 @@ func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID) ([]type
 				UUID:        *eID,
 				Temperature: *temp,
 				Humidity:    *hum,
+				OccurredAt:  *occurred,
 				MTime:       *mtime,
 				CTime:       *ctime,
 				EventType: types.EventType{
@@ -84,7 +88,29 @@ func (db *Conn) SelectLifecycleIndex(ctx context.Context, cid types.CID) ([]type
 		}
 	}
 
-	return result, err
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]types.UUID, len(result))
+	for i, lc := range result {
+		ids[i] = lc.UUID
+	}
+
+	statuses, err := db.selectStatuses(ctx, psqls["event"]["lifecycle-severities"], ids, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	units := db.unitSystem(ctx)
+	filtered := result[:0]
+	for _, lc := range result {
+		if lc.Status = statuses.of(lc.UUID); lc.Status.Matches(filter) {
+			filtered = append(filtered, lc.InUnits(units))
+		}
+	}
+
+	return filtered, err
 }
 
 func (db *Conn) SelectLifecycle(ctx context.Context, id types.UUID, cid types.CID) (types.Lifecycle, error) {
@@ -108,26 +134,103 @@ func (db *Conn) SelectLifecycle(ctx context.Context, id types.UUID, cid types.CI
 	return types.Lifecycle{}, err
 }
 
-func (db *Conn) selectLifecycles(ctx context.Context, p types.ReportAttrs, cid types.CID) ([]types.Lifecycle, error) {
+// SelectLifecycles is SelectLifecycle for every one of ids, with one query
+// for the lifecycles and one for all of their events; an id that doesn't
+// exist isn't in the result
+func (db *Conn) SelectLifecycles(ctx context.Context, ids []types.UUID, cid types.CID) ([]types.Lifecycle, error) {
 	var err error
-	deferred, l := initAccessFuncs("selectLifecycles", db.logger, "nil", cid)
+	deferred, l := initAccessFuncs("SelectLifecycles", db.logger, "nil", cid)
 	defer deferred(&err, l)
 
-	var generationID *types.UUID
+	result, err := db.scanLifecycles(ctx, nil, nil, nil, nil, nil, nil, nil, nil, uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
 
-	result := make([]types.Lifecycle, 0, 1000)
+	events, err := db.selectEventsFor(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	units := db.unitSystem(ctx)
+	for i := range result {
+		result[i].Events = events[result[i].UUID]
+		result[i].Status = types.NewStatus(result[i].Events)
+		result[i] = result[i].InUnits(units)
+	}
+
+	return result, nil
+}
+
+// StrainLifecyclesFor is the ids of every lifecycle of each of strains, with
+// one query, oldest first; a strain without any lifecycles isn't in the result
+func (db *Conn) StrainLifecyclesFor(ctx context.Context, strains []types.UUID, cid types.CID) (map[types.UUID][]types.UUID, error) {
+	var err error
+	deferred, l := initAccessFuncs("StrainLifecyclesFor", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["lifecycle"]["by-strain"], uuidArray(strains))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[types.UUID][]types.UUID, len(strains))
+	for rows.Next() {
+		var strain, id types.UUID
+		if err = rows.Scan(&strain, &id); err != nil {
+			return nil, err
+		}
+		result[strain] = append(result[strain], id)
+	}
+
+	return result, nil
+}
+
+func (db *Conn) selectLifecycles(ctx context.Context, p types.ReportAttrs, cid types.CID) ([]types.Lifecycle, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectLifecycles", db.logger, "nil", cid)
+	defer deferred(&err, l)
 
-	if !p.Contains("lifecycle-id", "strain-id", "grain-id", "bulk-id", "eventtype-id") {
+	if !p.Contains("lifecycle-id", "strain-id", "grain-id", "bulk-id", "eventtype-id", "location-id", "batch-id", "experiment-id") {
 		err = fmt.Errorf("request doesn't contain at least 1 required field")
-		return result, err
+		return []types.Lifecycle{}, err
 	}
 
-	rows, err := db.QueryContext(ctx, psqls["lifecycle"]["select"],
+	result, err := db.scanLifecycles(ctx,
 		p.Get("lifecycle-id"),
 		p.Get("strain-id"),
 		p.Get("grain-id"),
 		p.Get("bulk-id"),
-		p.Get("eventtype-id"))
+		p.Get("eventtype-id"),
+		p.Get("location-id"),
+		p.Get("batch-id"),
+		p.Get("experiment-id"),
+		nil)
+	if err != nil {
+		return result, err
+	}
+
+	units := db.unitSystem(ctx)
+	for i := range result {
+		if err = db.GetLifecycleEvents(ctx, &result[i], cid); err != nil {
+			break
+		}
+		result[i] = result[i].InUnits(units)
+	}
+
+	return result, err
+}
+
+// scanLifecycles is the lifecycle "select" query without any events; rows
+// is closed before it returns, since a transaction only has the one
+// connection and the events have to be queried next
+func (db *Conn) scanLifecycles(ctx context.Context, args ...any) ([]types.Lifecycle, error) {
+	var generationID *types.UUID
+
+	result := make([]types.Lifecycle, 0, 1000)
+
+	rows, err := db.QueryContext(ctx, psqls["lifecycle"]["select"], args...)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +241,15 @@ func (db *Conn) selectLifecycles(ctx context.Context, p types.ReportAttrs, cid t
 
 		if err = rows.Scan(
 			&row.UUID,
-			&row.Location,
+			&row.Location.UUID,
+			&row.Location.Name,
+			&row.Location.Kind,
+			&row.Location.Capacity,
+			&row.Location.Target.MinTemperature,
+			&row.Location.Target.MaxTemperature,
+			&row.Location.Target.MinHumidity,
+			&row.Location.Target.MaxHumidity,
+			&row.Location.Target.MaxCO2,
 			&row.StrainCost,
 			&row.GrainCost,
 			&row.BulkCost,
@@ -176,10 +287,6 @@ func (db *Conn) selectLifecycles(ctx context.Context, p types.ReportAttrs, cid t
 			row.Strain.Generation = &types.Generation{UUID: *generationID}
 		}
 
-		if err = db.GetLifecycleEvents(ctx, &row, cid); err != nil {
-			break
-		}
-
 		result = append(result, row)
 	}
 
@@ -198,15 +305,17 @@ func (db *Conn) InsertLifecycle(ctx context.Context, lc types.Lifecycle, cid typ
 	lc.MTime = time.Now().UTC()
 	lc.CTime = lc.MTime
 
+	yield, gross := db.weights(ctx, &lc)
+
 	result, err = db.ExecContext(ctx, psqls["lifecycle"]["insert"],
 		lc.UUID,
-		lc.Location,
+		lc.Location.UUID,
 		lc.StrainCost,
 		lc.GrainCost,
 		lc.BulkCost,
-		lc.Yield,
+		yield,
 		lc.Count,
-		lc.Gross,
+		gross,
 		lc.MTime,
 		lc.CTime,
 		lc.Strain.UUID,
@@ -232,33 +341,45 @@ func (db *Conn) UpdateLifecycle(ctx context.Context, lc types.Lifecycle, cid typ
 	deferred, l := initAccessFuncs("UpdateLifecycle", db.logger, lc.UUID, cid)
 	defer deferred(&err, l)
 
-	var result sql.Result
-	var rows int64
-
 	lc.MTime = time.Now().UTC()
 
-	if result, err = db.ExecContext(ctx, psqls["lifecycle"]["update"],
-		lc.Location,
-		lc.StrainCost,
-		lc.GrainCost,
-		lc.BulkCost,
-		lc.Yield,
-		lc.Count,
-		lc.Gross,
-		lc.MTime,
-		lc.Strain.UUID,
-		lc.GrainSubstrate.UUID,
-		lc.BulkSubstrate.UUID,
-		lc.UUID,
-	); err != nil {
+	yield, gross := db.weights(ctx, &lc)
+
+	// the totals that come back are the harvests', if there are any
+	if err = db.
+		QueryRowContext(ctx, psqls["lifecycle"]["update"],
+			lc.Location.UUID,
+			lc.StrainCost,
+			lc.GrainCost,
+			lc.BulkCost,
+			yield,
+			lc.Count,
+			gross,
+			lc.MTime,
+			lc.Strain.UUID,
+			lc.GrainSubstrate.UUID,
+			lc.BulkSubstrate.UUID,
+			lc.UUID,
+		).
+		Scan(&yield, &lc.Count, &gross); err == sql.ErrNoRows {
+		err = fmt.Errorf("one of strain, grain or bulk is not the right type")
 		return lc, err
-	} else if rows, err = result.RowsAffected(); err != nil {
+	} else if err != nil {
 		return lc, err
-	} else if rows != 1 {
-		err = fmt.Errorf("one of strain, grain or bulk is not the right type")
 	}
 
-	return lc, err
+	lc.Yield, lc.Gross, lc.Units = yield, gross, types.MetricUnits
+
+	return lc.InUnits(db.unitSystem(ctx)), err
+}
+
+// weights are lc's yield and gross in grams, for storing; an unlabeled lc is
+// taken to be in the units ctx asked for, and gets labeled as such
+func (db *Conn) weights(ctx context.Context, lc *types.Lifecycle) (yield, gross float32) {
+	if lc.Units == "" {
+		lc.Units = db.unitSystem(ctx)
+	}
+	return lc.Units.Grams(lc.Yield), lc.Units.Grams(lc.Gross)
 }
 
 func (db *Conn) UpdateLifecycleMTime(ctx context.Context, lc *types.Lifecycle, modified time.Time, cid types.CID) (*types.Lifecycle, error) {
@@ -281,6 +402,8 @@ func (lc lifecycle) children(db *Conn, ctx context.Context, cid types.CID, p *rp
 	deferred, l := initAccessFuncs("lifecycle::children", db.logger, lc.UUID, cid)
 	defer deferred(&err, l)
 
+	p.data["costs"] = types.NewCosts(types.Lifecycle(lc))
+
 	notes, err := db.notesReport(ctx, lc.UUID, cid, p)
 	if err != nil {
 		return err
@@ -295,6 +418,23 @@ func (lc lifecycle) children(db *Conn, ctx context.Context, cid types.CID, p *rp
 		p.data["strain"].(map[string]interface{})["photos"] = photos
 	}
 
+	// only worth the extra query when the lifecycle is what was asked for,
+	// not when it's a leaf in some other report
+	if p.parent == nil {
+		var forecast types.Forecast
+		if forecast, err = db.forecast(ctx, types.Lifecycle(lc), cid); err != nil {
+			return err
+		}
+		p.data["forecast"] = forecast
+
+		harvested := types.Lifecycle(lc)
+		if err = db.GetHarvests(ctx, &harvested, cid); err != nil {
+			return err
+		} else if len(harvested.Harvests) != 0 {
+			p.data["flushes"] = types.NewFlushes(lc.CTime, harvested.Harvests)
+		}
+	}
+
 	return nil
 }
 
@@ -17,7 +17,7 @@ import (
 var (
 	_lc = lifecycle{
 		UUID:       "30313233-3435-3637-3839-616263646566",
-		Location:   "location",
+		Location:   types.Location{UUID: "location", Name: "location", Kind: types.FruitingChamberLocation, Capacity: 4},
 		StrainCost: 0,
 		GrainCost:  0,
 		BulkCost:   0,
@@ -50,7 +50,15 @@ var (
 	}
 	lcFields = row{
 		"uuid",
-		"location",
+		"location_uuid",
+		"location_name",
+		"location_kind",
+		"location_capacity",
+		"min_temperature",
+		"max_temperature",
+		"min_humidity",
+		"max_humidity",
+		"max_co2",
 		"straincost",
 		"graincost",
 		"bulkcost",
@@ -83,7 +91,15 @@ var (
 	}
 	lcValues = []driver.Value{
 		_lc.UUID,
-		_lc.Location,
+		_lc.Location.UUID,
+		_lc.Location.Name,
+		_lc.Location.Kind,
+		_lc.Location.Capacity,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		_lc.StrainCost,
 		_lc.GrainCost,
 		_lc.BulkCost,
@@ -116,194 +132,247 @@ var (
 	}
 )
 
+// none of _events are in a real stage, so there's nothing to anchor on but ctime
+var _forecast = types.Forecast{Basis: []types.Dimension{}, Anchor: wwtbn}
+
+// lcEntity is what _lc looks like after lifecycle.children
+func lcEntity(status types.Status) types.Entity {
+	result := mustEntity(_lc)
+	result["costs"] = types.NewCosts(types.Lifecycle(_lc))
+	result["status"] = string(status)
+	return result
+}
+
 func Test_SelectLifecycleIndex(t *testing.T) {
 	t.Parallel()
 
 	l := log.WithField("test", "SelectLifecycleIndex")
 
-	tcs := map[string]struct {
-		db     getMockDB
-		result []types.Lifecycle
-		err    error
-	}{
-		"happy_path": {
-			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectQuery("").
-					WillReturnRows(sqlmock.
-						NewRows([]string{
-							"uuid",
-							"location",
-							"mtime",
-							"ctime",
-							"strain_uuid",
-							"strain_species",
-							"strain_name",
-							"strain_ctime",
-							"vendor_uuid",
-							"vendor_name",
-							"vendor_website",
-							"event_uuid",
-							"temp",
-							"humidity",
-							"event_mtime",
-							"event_ctime",
-							"et_uuid",
-							"et_name",
-							"et_sev",
-							"stage_uuid",
-							"stage_name"}).
-						AddRow(
-							"0",
-							"happy_path",
-							wwtbn,
-							wwtbn,
-							"strain 0",
-							"strain 0",
-							"strain 0",
-							wwtbn,
-							"vendor 0",
-							"vendor 0",
-							"vendor 0",
-							"event 0",
-							0,
-							0,
-							wwtbn,
-							wwtbn,
-							"type 0",
-							"type 0",
-							"type 0",
-							"stage 0",
-							"stage 0",
-						).
-						AddRow(
-							"1",
-							"happy_path 2",
-							wwtbn,
-							wwtbn,
-							"strain 0",
-							"strain 0",
-							"strain 0",
-							wwtbn,
-							"vendor 0",
-							"vendor 0",
-							"vendor 0",
-							"event 0",
-							0,
-							0,
-							wwtbn,
-							wwtbn,
-							"type 0",
-							"type 0",
-							"type 0",
-							"stage 0",
-							"stage 0",
-						).
-						AddRow(
-							"1",
-							"happy_path 2",
-							wwtbn,
-							wwtbn,
-							"strain 0",
-							"strain 0",
-							"strain 0",
-							wwtbn,
-							"vendor 0",
-							"vendor 0",
-							"vendor 0",
-							"event 1",
-							0,
-							0,
-							wwtbn,
-							wwtbn,
-							"type 0",
-							"type 0",
-							"type 0",
-							"stage 0",
-							"stage 0",
-						))
-				return db
+	statuses := statusFields.set([]driver.Value{"1", types.HarvestingEvent, types.InfoSeverity})
+	index := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("").
+			WillReturnRows(sqlmock.
+				NewRows([]string{
+					"uuid",
+					"location_uuid",
+					"location_name",
+					"location_kind",
+					"mtime",
+					"ctime",
+					"strain_uuid",
+					"strain_species",
+					"strain_name",
+					"strain_ctime",
+					"vendor_uuid",
+					"vendor_name",
+					"vendor_website",
+					"event_uuid",
+					"temp",
+					"humidity",
+					"event_occurred_at",
+					"event_mtime",
+					"event_ctime",
+					"et_uuid",
+					"et_name",
+					"et_sev",
+					"stage_uuid",
+					"stage_name"}).
+				AddRow(
+					"0",
+					"happy_path",
+					"happy_path",
+					types.IncubatorLocation,
+					wwtbn,
+					wwtbn,
+					"strain 0",
+					"strain 0",
+					"strain 0",
+					wwtbn,
+					"vendor 0",
+					"vendor 0",
+					"vendor 0",
+					"event 0",
+					0,
+					0,
+					wwtbn,
+					wwtbn,
+					wwtbn,
+					"type 0",
+					"type 0",
+					"type 0",
+					"stage 0",
+					"stage 0",
+				).
+				AddRow(
+					"1",
+					"happy_path 2",
+					"happy_path 2",
+					types.IncubatorLocation,
+					wwtbn,
+					wwtbn,
+					"strain 0",
+					"strain 0",
+					"strain 0",
+					wwtbn,
+					"vendor 0",
+					"vendor 0",
+					"vendor 0",
+					"event 0",
+					0,
+					0,
+					wwtbn,
+					wwtbn,
+					wwtbn,
+					"type 0",
+					"type 0",
+					"type 0",
+					"stage 0",
+					"stage 0",
+				).
+				AddRow(
+					"1",
+					"happy_path 2",
+					"happy_path 2",
+					types.IncubatorLocation,
+					wwtbn,
+					wwtbn,
+					"strain 0",
+					"strain 0",
+					"strain 0",
+					wwtbn,
+					"vendor 0",
+					"vendor 0",
+					"vendor 0",
+					"event 1",
+					0,
+					0,
+					wwtbn,
+					wwtbn,
+					wwtbn,
+					"type 0",
+					"type 0",
+					"type 0",
+					"stage 0",
+					"stage 0",
+				))
+	}
+	indexed := []types.Lifecycle{
+		{
+			UUID:     "0",
+			Location: types.Location{UUID: "happy_path", Name: "happy_path", Kind: types.IncubatorLocation},
+			Status:   types.PendingStatus,
+			MTime:    wwtbn,
+			CTime:    wwtbn,
+			Strain: types.Strain{
+				UUID:    "strain 0",
+				Name:    "strain 0",
+				Species: "strain 0",
+				CTime:   wwtbn,
+				Vendor: types.Vendor{
+					UUID:    "vendor 0",
+					Name:    "vendor 0",
+					Website: "vendor 0",
+				},
 			},
-			result: []types.Lifecycle{
-				{
-					UUID:     "0",
-					Location: "happy_path",
-					MTime:    wwtbn,
-					CTime:    wwtbn,
-					Strain: types.Strain{
-						UUID:    "strain 0",
-						Name:    "strain 0",
-						Species: "strain 0",
-						CTime:   wwtbn,
-						Vendor: types.Vendor{
-							UUID:    "vendor 0",
-							Name:    "vendor 0",
-							Website: "vendor 0",
-						},
+			Events: []types.Event{{
+				UUID:       "event 0",
+				OccurredAt: wwtbn,
+				MTime:      wwtbn,
+				CTime:      wwtbn,
+				EventType: types.EventType{
+					UUID:     "type 0",
+					Name:     "type 0",
+					Severity: "type 0",
+					Stage: types.Stage{
+						UUID: "stage 0",
+						Name: "stage 0",
 					},
-					Events: []types.Event{{
-						UUID:  "event 0",
-						MTime: wwtbn,
-						CTime: wwtbn,
-						EventType: types.EventType{
-							UUID:     "type 0",
-							Name:     "type 0",
-							Severity: "type 0",
-							Stage: types.Stage{
-								UUID: "stage 0",
-								Name: "stage 0",
-							},
-						},
-					}},
 				},
+			}},
+		},
+		{
+			UUID:     "1",
+			Location: types.Location{UUID: "happy_path 2", Name: "happy_path 2", Kind: types.IncubatorLocation},
+			Status:   types.HarvestedStatus,
+			MTime:    wwtbn,
+			CTime:    wwtbn,
+			Strain: types.Strain{
+				UUID:    "strain 0",
+				Name:    "strain 0",
+				Species: "strain 0",
+				CTime:   wwtbn,
+				Vendor: types.Vendor{
+					UUID:    "vendor 0",
+					Name:    "vendor 0",
+					Website: "vendor 0",
+				},
+			},
+			Events: []types.Event{
 				{
-					UUID:     "1",
-					Location: "happy_path 2",
-					MTime:    wwtbn,
-					CTime:    wwtbn,
-					Strain: types.Strain{
-						UUID:    "strain 0",
-						Name:    "strain 0",
-						Species: "strain 0",
-						CTime:   wwtbn,
-						Vendor: types.Vendor{
-							UUID:    "vendor 0",
-							Name:    "vendor 0",
-							Website: "vendor 0",
+					UUID:       "event 0",
+					OccurredAt: wwtbn,
+					MTime:      wwtbn,
+					CTime:      wwtbn,
+					EventType: types.EventType{
+						UUID:     "type 0",
+						Name:     "type 0",
+						Severity: "type 0",
+						Stage: types.Stage{
+							UUID: "stage 0",
+							Name: "stage 0",
 						},
 					},
-					Events: []types.Event{
-						{
-							UUID:  "event 0",
-							MTime: wwtbn,
-							CTime: wwtbn,
-							EventType: types.EventType{
-								UUID:     "type 0",
-								Name:     "type 0",
-								Severity: "type 0",
-								Stage: types.Stage{
-									UUID: "stage 0",
-									Name: "stage 0",
-								},
-							},
-						},
-						{
-							UUID:  "event 1",
-							MTime: wwtbn,
-							CTime: wwtbn,
-							EventType: types.EventType{
-								UUID:     "type 0",
-								Name:     "type 0",
-								Severity: "type 0",
-								Stage: types.Stage{
-									UUID: "stage 0",
-									Name: "stage 0",
-								},
-							},
+				},
+				{
+					UUID:       "event 1",
+					OccurredAt: wwtbn,
+					MTime:      wwtbn,
+					CTime:      wwtbn,
+					EventType: types.EventType{
+						UUID:     "type 0",
+						Name:     "type 0",
+						Severity: "type 0",
+						Stage: types.Stage{
+							UUID: "stage 0",
+							Name: "stage 0",
 						},
 					},
 				},
 			},
 		},
+	}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		filter []types.Status
+		result []types.Lifecycle
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				index(mock)
+				newBuilder(mock, statuses)
+				return db
+			},
+			result: indexed,
+		},
+		"filtered": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				index(mock)
+				newBuilder(mock, statuses)
+				return db
+			},
+			filter: []types.Status{types.HarvestedStatus, types.DeadStatus},
+			result: indexed[1:],
+		},
+		"statuses_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				index(mock)
+				newBuilder(mock, statusFields.fail())
+				return db
+			},
+			err: statusFields.err(),
+		},
 		"db_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
@@ -321,7 +390,7 @@ func Test_SelectLifecycleIndex(t *testing.T) {
 				query:        tc.db(sqlmock.New()),
 				generateUUID: mockUUIDGen,
 				logger:       l.WithField("name", name),
-			}).SelectLifecycleIndex(context.Background(), "Test_SelectLifecycleIndex")
+			}).SelectLifecycleIndex(context.Background(), "Test_SelectLifecycleIndex", tc.filter...)
 
 			require.Equal(t, tc.err, err)
 			require.Equal(t, tc.result, result)
@@ -353,6 +422,7 @@ func Test_SelectLifecycle(t *testing.T) {
 					types.Event(_events[1]),
 					types.Event(_events[2]),
 				}
+				lc.Status = types.ActiveStatus
 
 				return types.Lifecycle(lc)
 			}(_lc),
@@ -488,36 +558,34 @@ func Test_UpdateLifecycle(t *testing.T) {
 
 	l := log.WithField("test", "UpdateLifecycle")
 
+	totals := row{"yield", "headcount", "gross"}
+
 	tcs := map[string]struct {
-		db  getMockDB
-		err error
+		db     getMockDB
+		totals types.Lifecycle
+		err    error
 	}{
+		// the harvests' totals win over the ones that were sent
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, totals.set([]driver.Value{310.5, 12, 450}))
 				return db
 			},
+			totals: types.Lifecycle{Yield: 310.5, Count: 12, Gross: 450},
 		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				newBuilder(mock, totals.set())
 				return db
 			},
 			err: fmt.Errorf("one of strain, grain or bulk is not the right type"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				newBuilder(mock, totals.fail())
 				return db
 			},
-			err: fmt.Errorf("some error"),
-		},
-		"result_fails": {
-			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
-				return db
-			},
-			err: fmt.Errorf("some error"),
+			err: totals.err(),
 		},
 	}
 
@@ -527,15 +595,21 @@ func Test_UpdateLifecycle(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			// there's no good way to test the returned lifecycle, to start, the
-			// timestamps are non-deterministic; system tests will vet the rest
-			_, err := (&Conn{
+			// there's no good way to test the whole returned lifecycle, to start,
+			// the timestamps are non-deterministic; system tests will vet the rest
+			result, err := (&Conn{
 				query:        tc.db(sqlmock.New()),
 				generateUUID: mockUUIDGen,
 				logger:       l.WithField("name", name),
-			}).UpdateLifecycle(context.Background(), types.Lifecycle{}, "Test_UpdateLifecycle")
+			}).UpdateLifecycle(context.Background(), types.Lifecycle{Yield: 1, Count: 1, Gross: 1}, "Test_UpdateLifecycle")
 
 			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tc.totals.Yield, result.Yield)
+			require.Equal(t, tc.totals.Count, result.Count)
+			require.Equal(t, tc.totals.Gross, result.Gross)
 		})
 	}
 }
@@ -683,7 +757,9 @@ func Test_LifecycleReport(t *testing.T) {
 					ingFields.set(ingValues...),
 					napFields.set(),
 					noteFields.set(noteValues...),
-					photoFields.set())
+					photoFields.set(),
+					lcEventFields.set(),
+					harvestFields.set(harvestValues...))
 
 				return db
 			},
@@ -693,9 +769,11 @@ func Test_LifecycleReport(t *testing.T) {
 				lc["bulk_substrate"].(map[string]interface{})["ingredients"] = ingredients
 				lc["events"] = events
 				lc["notes"] = notes
+				lc["forecast"] = _forecast
+				lc["flushes"] = types.NewFlushes(_lc.CTime, _harvests)
 
 				return lc
-			}(mustEntity(_lc)),
+			}(lcEntity(types.ActiveStatus)),
 		},
 		"happy_photo_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -707,7 +785,9 @@ func Test_LifecycleReport(t *testing.T) {
 					ingFields.set(ingValues...),
 					napFields.set(),
 					noteFields.set(noteValues...),
-					photoFields.set(photoValues...))
+					photoFields.set(photoValues...),
+					lcEventFields.set(),
+					harvestFields.set())
 
 				return db
 			},
@@ -720,9 +800,45 @@ func Test_LifecycleReport(t *testing.T) {
 				lc["bulk_substrate"].(map[string]interface{})["ingredients"] = ingredients
 				lc["events"] = events
 				lc["notes"] = notes
+				lc["forecast"] = _forecast
 
 				return lc
-			}(mustEntity(_lc)),
+			}(lcEntity(types.ActiveStatus)),
+		},
+		"forecast_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					attrFields.set(attrValues...),
+					ingFields.set(ingValues...),
+					ingFields.set(ingValues...),
+					napFields.set(),
+					noteFields.set(noteValues...),
+					photoFields.set(),
+					lcEventFields.fail())
+
+				return db
+			},
+			err: lcEventFields.err(),
+		},
+		"harvests_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					attrFields.set(attrValues...),
+					ingFields.set(ingValues...),
+					ingFields.set(ingValues...),
+					napFields.set(),
+					noteFields.set(noteValues...),
+					photoFields.set(),
+					lcEventFields.set(),
+					harvestFields.fail())
+
+				return db
+			},
+			err: harvestFields.err(),
 		},
 		"photo_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -857,3 +973,130 @@ func Test_LifecycleReport(t *testing.T) {
 		})
 	}
 }
+
+func Test_SelectLifecycles(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectLifecycles")
+
+	events := eventFields.keyed("observable_uuid")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Lifecycle
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues, excludedValues),
+					events.set(keyed(_lc.UUID, eventValues...)...))
+				return db
+			},
+			result: []types.Lifecycle{
+				func(lc lifecycle) types.Lifecycle {
+					lc.Events = []types.Event{
+						types.Event(_events[0]),
+						types.Event(_events[1]),
+						types.Event(_events[2]),
+					}
+					lc.Status = types.ActiveStatus
+					return types.Lifecycle(lc)
+				}(_lc),
+				func(lc lifecycle) types.Lifecycle {
+					lc.UUID = "excluded"
+					lc.Status = types.PendingStatus
+					return types.Lifecycle(lc)
+				}(_lc),
+			},
+		},
+		"events_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcFields.set(lcValues), events.fail())
+				return db
+			},
+			err: events.err(),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcFields.fail())
+				return db
+			},
+			err: lcFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectLifecycles(context.Background(), []types.UUID{_lc.UUID, "excluded"}, "Test_SelectLifecycles")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_StrainLifecyclesFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "StrainLifecyclesFor")
+
+	fields := row{"strain_uuid", "uuid"}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID][]types.UUID
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(
+					[]driver.Value{"0", "lc0"},
+					[]driver.Value{"1", "lc1"},
+					[]driver.Value{"0", "lc2"}))
+				return db
+			},
+			result: map[types.UUID][]types.UUID{
+				"0": {"lc0", "lc2"},
+				"1": {"lc1"},
+			},
+		},
+		"scan_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, row{"strain_uuid"}.set([]driver.Value{"0"}))
+				return db
+			},
+			err: fmt.Errorf("sql: expected 1 destination arguments in Scan, not 2"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).StrainLifecyclesFor(context.Background(), []types.UUID{"0", "1", "2"}, "Test_StrainLifecyclesFor")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
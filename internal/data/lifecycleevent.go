@@ -15,6 +15,7 @@ func (db *Conn) GetLifecycleEvents(ctx context.Context, lc *types.Lifecycle, cid
 	defer deferred(&err, l)
 
 	lc.Events, err = db.selectEventsList(ctx, psqls["event"]["all-by-observable"], lc.UUID, cid)
+	lc.Status = types.NewStatus(lc.Events)
 
 	return err
 }
@@ -25,8 +26,11 @@ func (db *Conn) AddLifecycleEvent(ctx context.Context, lc *types.Lifecycle, e ty
 	deferred, l := initAccessFuncs("AddEvent", db.logger, lc.UUID, cid)
 	defer deferred(&err, l)
 
-	if lc.Events, err = db.addEvent(ctx, lc.UUID, lc.Events, &e, cid); err == nil {
-		_, err = db.updateMTime(ctx, "lifecycles", lc.Events[0].MTime, lc.UUID, cid)
+	lc.Events, err = db.addEvent(ctx, lc.UUID, lc.Events, &e, cid)
+	lc.Status = types.NewStatus(lc.Events)
+
+	if err == nil {
+		_, err = db.updateMTime(ctx, "lifecycles", e.MTime, lc.UUID, cid)
 	}
 
 	return err
@@ -38,8 +42,11 @@ func (db *Conn) ChangeLifecycleEvent(ctx context.Context, lc *types.Lifecycle, e
 	deferred, l := initAccessFuncs("ChangeEvent", db.logger, lc.UUID, cid)
 	defer deferred(&err, l)
 
-	if lc.Events, err = db.changeEvent(ctx, lc.Events, &e, cid); err == nil {
-		_, err = db.updateMTime(ctx, "lifecycles", lc.Events[0].MTime, lc.UUID, cid)
+	lc.Events, err = db.changeEvent(ctx, lc.Events, &e, cid)
+	lc.Status = types.NewStatus(lc.Events)
+
+	if err == nil {
+		_, err = db.updateMTime(ctx, "lifecycles", e.MTime, lc.UUID, cid)
 	}
 
 	return e, err
@@ -51,7 +58,10 @@ func (db *Conn) RemoveLifecycleEvent(ctx context.Context, lc *types.Lifecycle, i
 	deferred, l := initAccessFuncs("RemoveEvent", db.logger, lc.UUID, cid)
 	defer deferred(&err, l)
 
-	if lc.Events, err = db.removeEvent(ctx, lc.Events, id, cid); err == nil {
+	lc.Events, err = db.removeEvent(ctx, lc.Events, id, cid)
+	lc.Status = types.NewStatus(lc.Events)
+
+	if err == nil {
 		_, err = db.updateMTime(ctx, "lifecycles", time.Now().UTC(), lc.UUID, cid)
 	}
 
@@ -42,13 +42,13 @@ func Test_GetLifecycleEvents(t *testing.T) {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				mock.ExpectQuery("").
 					WillReturnRows(sqlmock.
-						NewRows([]string{"id", "temperature", "humidity", "mtime", "ctime", "eventtype_uuid", "event_severity", "eventtype_name", "stage_uuid", "stage_name"}).
-						AddRow(e0.UUID, e0.Temperature, e0.Humidity, e0.MTime, e0.CTime, e0.EventType.UUID, e0.EventType.Name, e0.EventType.Severity, e0.EventType.Stage.UUID, e0.EventType.Stage.Name).
-						AddRow(e1.UUID, e1.Temperature, e1.Humidity, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name).
-						AddRow("1 note", e1.Temperature, e1.Humidity, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name).
-						AddRow("2 notes", e1.Temperature, e1.Humidity, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name).
-						AddRow(e2.UUID, e2.Temperature, e2.Humidity, e2.MTime, e2.CTime, e2.EventType.UUID, e2.EventType.Name, e2.EventType.Severity, e2.EventType.Stage.UUID, e2.EventType.Stage.Name).
-						AddRow("photos", e2.Temperature, e2.Humidity, e2.MTime, e2.CTime, e2.EventType.UUID, e2.EventType.Name, e2.EventType.Severity, e2.EventType.Stage.UUID, e2.EventType.Stage.Name))
+						NewRows([]string{"id", "temperature", "humidity", "occurred_at", "mtime", "ctime", "eventtype_uuid", "event_severity", "eventtype_name", "stage_uuid", "stage_name", "measurements"}).
+						AddRow(e0.UUID, e0.Temperature, e0.Humidity, e0.OccurredAt, e0.MTime, e0.CTime, e0.EventType.UUID, e0.EventType.Name, e0.EventType.Severity, e0.EventType.Stage.UUID, e0.EventType.Stage.Name, nil).
+						AddRow(e1.UUID, e1.Temperature, e1.Humidity, e1.OccurredAt, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name, nil).
+						AddRow("1 note", e1.Temperature, e1.Humidity, e1.OccurredAt, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name, nil).
+						AddRow("2 notes", e1.Temperature, e1.Humidity, e1.OccurredAt, e1.MTime, e1.CTime, e1.EventType.UUID, e1.EventType.Name, e1.EventType.Severity, e1.EventType.Stage.UUID, e1.EventType.Stage.Name, nil).
+						AddRow(e2.UUID, e2.Temperature, e2.Humidity, e2.OccurredAt, e2.MTime, e2.CTime, e2.EventType.UUID, e2.EventType.Name, e2.EventType.Severity, e2.EventType.Stage.UUID, e2.EventType.Stage.Name, nil).
+						AddRow("photos", e2.Temperature, e2.Humidity, e2.OccurredAt, e2.MTime, e2.CTime, e2.EventType.UUID, e2.EventType.Name, e2.EventType.Severity, e2.EventType.Stage.UUID, e2.EventType.Stage.Name, nil))
 				mock.ExpectQuery("").
 					WillReturnRows(sqlmock.
 						NewRows([]string{"id", "filename", "mtime", "ctime", "note_uuid", "note", "note_mtime", "note_ctime"}).
@@ -113,8 +113,11 @@ func Test_AddLifecycleEvent(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				eventFields.mock(mock)
 				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
@@ -124,8 +127,11 @@ func Test_AddLifecycleEvent(t *testing.T) {
 		},
 		"modified_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				eventFields.mock(mock)
 				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("couldn't update Lifecycle.mtime"))
 				return db
 			},
@@ -134,31 +140,65 @@ func Test_AddLifecycleEvent(t *testing.T) {
 			result: []types.Event{e0, e1, e2},
 			err:    fmt.Errorf("couldn't update Lifecycle.mtime"),
 		},
+		// the lifecycle was ended since evts were loaded, so only what's
+		// stored knows about it
+		"illegal_transition": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock, xformer(eventValues[1]).replace(xform{7: "Sunset", 8: types.RIPSeverity}))
+				etFields.mock(mock, etValues[0])
+				return db
+			},
+			evts: []types.Event{e0},
+			evt:  e2,
+			err: types.TransitionError{
+				EventType: types.EventType(_ets[0]),
+				Reason:    "'Sunset' already ended it",
+			},
+		},
 		"eventtype_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				eventFields.mock(mock)
 				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
 			err: fmt.Errorf("couldn't fetch eventtype"),
 		},
+		"events_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.fail())
+				return db
+			},
+			err: eventFields.err(),
+		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock)
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("event was not added"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock)
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				eventFields.mock(mock)
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
@@ -208,7 +248,9 @@ func Test_ChangeLifecycleEvent(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				etFields.mock(mock, etValues[0])
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
@@ -219,7 +261,9 @@ func Test_ChangeLifecycleEvent(t *testing.T) {
 		},
 		"modified_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				etFields.mock(mock, etValues[0])
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("couldn't update Lifecycle.mtime"))
 				return db
@@ -231,7 +275,9 @@ func Test_ChangeLifecycleEvent(t *testing.T) {
 		},
 		"eventtype_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
@@ -239,21 +285,27 @@ func Test_ChangeLifecycleEvent(t *testing.T) {
 		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("event was not changed"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("some error"),
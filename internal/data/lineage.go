@@ -0,0 +1,315 @@
+package data
+
+import (
+	"context"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+type (
+	// lineage only keeps the first of any node or edge it's given, so the
+	// same ancestor reached through two sources shows up once
+	lineage struct {
+		types.Lineage
+		nodes map[string]bool
+		edges map[types.LineageEdge]bool
+	}
+
+	// lineagerow is one source, along with the strains on either side of it;
+	// the child is empty for a generation that hasn't produced a strain yet
+	lineagerow struct {
+		generation types.UUID
+		source     types.UUID
+		sourceType string
+		lifecycle  *types.UUID
+		parent     types.Strain
+		child      nullstrain
+	}
+
+	nullstrain struct {
+		uuid                      *types.UUID
+		name, species, vendorName *string
+	}
+)
+
+func (x nullstrain) strain() *types.Strain {
+	if x.uuid == nil {
+		return nil
+	}
+	return &types.Strain{
+		UUID:    *x.uuid,
+		Name:    *x.name,
+		Species: *x.species,
+		Vendor:  types.Vendor{Name: *x.vendorName},
+	}
+}
+
+func (db *Conn) Ancestry(ctx context.Context, strainID types.UUID, depth int, cid types.CID) (types.Lineage, error) {
+	var err error
+	deferred, l := initAccessFuncs("Ancestry", db.logger, strainID, cid)
+	defer deferred(&err, l)
+
+	result, err := db.walkLineage(ctx, psqls["lineage"]["ancestors"], strainID, depth, func(r lineagerow) *types.Strain {
+		return &r.parent
+	})
+
+	return result, err
+}
+
+func (db *Conn) Descendants(ctx context.Context, strainID types.UUID, depth int, cid types.CID) (types.Lineage, error) {
+	var err error
+	deferred, l := initAccessFuncs("Descendants", db.logger, strainID, cid)
+	defer deferred(&err, l)
+
+	result, err := db.walkLineage(ctx, psqls["lineage"]["descendants"], strainID, depth, func(r lineagerow) *types.Strain {
+		return r.child.strain()
+	})
+
+	return result, err
+}
+
+func (db *Conn) Pedigree(ctx context.Context, strainID types.UUID, depth int, cid types.CID) (types.Lineage, error) {
+	var err error
+	deferred, l := initAccessFuncs("Pedigree", db.logger, strainID, cid)
+	defer deferred(&err, l)
+
+	ancestry, err := db.Ancestry(ctx, strainID, depth, cid)
+	if err != nil {
+		return types.Lineage{}, err
+	}
+
+	descendants, err := db.Descendants(ctx, strainID, depth, cid)
+	if err != nil {
+		return types.Lineage{}, err
+	}
+
+	// the root is in both, so it's the only node they can share
+	result := newLineage()
+	for _, n := range append(ancestry.Nodes, descendants.Nodes...) {
+		result.add(n)
+	}
+	for _, e := range append(ancestry.Edges, descendants.Edges...) {
+		result.edge(e.From, e.To)
+	}
+	result.Root = ancestry.Root
+
+	err = db.outcomes(ctx, result.Lineage, cid)
+
+	return result.Lineage, err
+}
+
+// Library is every strain and every source, which is the only way to see
+// strains that nothing came from and that didn't come from anything; depth
+// doesn't mean anything without a root, so it's always zero
+func (db *Conn) Library(ctx context.Context, cid types.CID) (types.Lineage, error) {
+	var err error
+	deferred, l := initAccessFuncs("Library", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	result := newLineage()
+
+	rows, err := db.query.QueryContext(ctx, psqls["lineage"]["all-strains"])
+	if err != nil {
+		return result.Lineage, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s types.Strain
+		if err = rows.Scan(&s.UUID, &s.Name, &s.Species, &s.Vendor.Name); err != nil {
+			return result.Lineage, err
+		}
+		result.strain(s, 0)
+	}
+
+	sources, err := db.lineageRows(ctx, psqls["lineage"]["all-sources"])
+	if err != nil {
+		return result.Lineage, err
+	}
+	for _, r := range sources {
+		result.link(r, 0)
+	}
+
+	err = db.outcomes(ctx, result.Lineage, cid)
+
+	return result.Lineage, err
+}
+
+// walkLineage goes one generation at a time, breadth first, so every node
+// gets the shortest depth it can be reached by; next picks the strain on the
+// far side of a row, if there is one
+func (db *Conn) walkLineage(ctx context.Context, query string, strainID types.UUID, depth int, next func(lineagerow) *types.Strain) (types.Lineage, error) {
+	var root types.Strain
+	if err := db.
+		QueryRowContext(ctx, psqls["lineage"]["strain"], strainID).
+		Scan(&root.UUID, &root.Name, &root.Species, &root.Vendor.Name); err != nil {
+		return types.Lineage{}, err
+	}
+
+	result := newLineage()
+	result.Root = result.strain(root, 0)
+
+	frontier := []types.UUID{root.UUID}
+	for level := 1; len(frontier) > 0 && (depth <= 0 || level <= depth); level++ {
+		var found []types.UUID
+		for _, id := range frontier {
+			rows, err := db.lineageRows(ctx, query, id)
+			if err != nil {
+				return result.Lineage, err
+			}
+
+			for _, r := range rows {
+				result.link(r, level)
+
+				s := next(r)
+				if s == nil {
+					continue
+				} else if !result.nodes[types.NewLineageID(types.StrainNode, s.UUID)] {
+					// same as rpttree.cycle, a strain that's already in
+					// the graph has already been walked, or will be
+					found = append(found, s.UUID)
+				}
+				result.strain(*s, level)
+			}
+		}
+		frontier = found
+	}
+
+	return result.Lineage, nil
+}
+
+// outcomes fills in the status of every generation and lifecycle in l
+func (db *Conn) outcomes(ctx context.Context, l types.Lineage, cid types.CID) error {
+	var lcIDs, genIDs []types.UUID
+	for _, n := range l.Nodes {
+		switch n.Kind {
+		case types.LifecycleNode:
+			lcIDs = append(lcIDs, n.UUID)
+		case types.GenerationNode:
+			genIDs = append(genIDs, n.UUID)
+		}
+	}
+
+	lifecycles, err := db.selectStatuses(ctx, psqls["event"]["lifecycle-severities"], lcIDs, cid)
+	if err != nil {
+		return err
+	}
+
+	generations, err := db.selectStatuses(ctx, psqls["event"]["generation-severities"], genIDs, cid)
+	if err != nil {
+		return err
+	}
+
+	for i, n := range l.Nodes {
+		switch n.Kind {
+		case types.LifecycleNode:
+			l.Nodes[i].Outcome = lifecycles.of(n.UUID)
+		case types.GenerationNode:
+			l.Nodes[i].Outcome = generations.of(n.UUID)
+		}
+	}
+
+	return nil
+}
+
+func (db *Conn) lineageRows(ctx context.Context, query string, args ...any) ([]lineagerow, error) {
+	rows, err := db.query.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []lineagerow{}
+	for rows.Next() {
+		var r lineagerow
+		if err = rows.Scan(
+			&r.generation,
+			&r.source,
+			&r.sourceType,
+			&r.lifecycle,
+			&r.parent.UUID,
+			&r.parent.Name,
+			&r.parent.Species,
+			&r.parent.Vendor.Name,
+			&r.child.uuid,
+			&r.child.name,
+			&r.child.species,
+			&r.child.vendorName,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	return result, nil
+}
+
+func newLineage() *lineage {
+	return &lineage{
+		Lineage: types.Lineage{
+			Nodes: []types.LineageNode{},
+			Edges: []types.LineageEdge{},
+		},
+		nodes: map[string]bool{},
+		edges: map[types.LineageEdge]bool{},
+	}
+}
+
+// link adds everything in r except the strains on either side of it, which
+// the caller adds itself
+func (l *lineage) link(r lineagerow, depth int) {
+	parent := types.NewLineageID(types.StrainNode, r.parent.UUID)
+	generation := l.node(types.GenerationNode, r.generation, string(r.generation), depth)
+	source := l.node(types.SourceNode, r.source, r.sourceType, depth)
+
+	if r.lifecycle != nil {
+		lc := l.node(types.LifecycleNode, *r.lifecycle, string(*r.lifecycle), depth)
+		l.edge(parent, lc)
+		l.edge(lc, source)
+	} else {
+		l.edge(parent, source)
+	}
+	l.edge(source, generation)
+
+	if r.child.uuid != nil {
+		l.edge(generation, types.NewLineageID(types.StrainNode, *r.child.uuid))
+	}
+}
+
+func (l *lineage) node(kind types.LineageKind, id types.UUID, label string, depth int) string {
+	return l.add(types.LineageNode{
+		ID:    types.NewLineageID(kind, id),
+		Kind:  kind,
+		UUID:  id,
+		Label: label,
+		Depth: depth,
+	})
+}
+
+func (l *lineage) strain(s types.Strain, depth int) string {
+	return l.add(types.LineageNode{
+		ID:      types.NewLineageID(types.StrainNode, s.UUID),
+		Kind:    types.StrainNode,
+		UUID:    s.UUID,
+		Label:   s.Name,
+		Depth:   depth,
+		Species: s.Species,
+		Vendor:  s.Vendor.Name,
+	})
+}
+
+func (l *lineage) add(n types.LineageNode) string {
+	if !l.nodes[n.ID] {
+		l.nodes[n.ID] = true
+		l.Nodes = append(l.Nodes, n)
+	}
+	return n.ID
+}
+
+func (l *lineage) edge(from, to string) {
+	e := types.LineageEdge{From: from, To: to}
+	if !l.edges[e] {
+		l.edges[e] = true
+		l.Edges = append(l.Edges, e)
+	}
+}
@@ -0,0 +1,428 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	lineageStrainFields = row{"uuid", "name", "species", "vendor_name"}
+	lineageFields       = row{
+		"generation_uuid",
+		"source_uuid",
+		"source_type",
+		"lifecycle_uuid",
+		"parent_uuid",
+		"parent_name",
+		"parent_species",
+		"parent_vendor_name",
+		"child_uuid",
+		"child_name",
+		"child_species",
+		"child_vendor_name",
+	}
+	// grandparent is cloned straight into g0, which made parent; parent was
+	// grown in lc1, and a spore print from that made child through g1
+	lineageValues = [][]driver.Value{
+		{"g1", "src1", "Spore", "lc1", "s1", "parent", "", "v0", "s2", "child", "", "v0"},
+		{"g0", "src0", "Clone", nil, "s0", "grandparent", "", "v0", "s1", "parent", "", "v0"},
+	}
+)
+
+func lineageNode(kind types.LineageKind, id types.UUID, label string, depth int) types.LineageNode {
+	result := types.LineageNode{ID: types.NewLineageID(kind, id), Kind: kind, UUID: id, Label: label, Depth: depth}
+	if kind == types.StrainNode {
+		result.Vendor = "v0"
+	}
+	return result
+}
+
+func Test_Ancestry(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "Ancestry")
+
+	level1 := types.Lineage{
+		Root: "strain#s2",
+		Nodes: []types.LineageNode{
+			lineageNode(types.StrainNode, "s2", "child", 0),
+			lineageNode(types.GenerationNode, "g1", "g1", 1),
+			lineageNode(types.SourceNode, "src1", "Spore", 1),
+			lineageNode(types.LifecycleNode, "lc1", "lc1", 1),
+			lineageNode(types.StrainNode, "s1", "parent", 1),
+		},
+		Edges: []types.LineageEdge{
+			{From: "strain#s1", To: "lifecycle#lc1"},
+			{From: "lifecycle#lc1", To: "source#src1"},
+			{From: "source#src1", To: "generation#g1"},
+			{From: "generation#g1", To: "strain#s2"},
+		},
+	}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		depth  int
+		result types.Lineage
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s2", "child", "", "v0"}),
+					lineageFields.set(lineageValues[0]),
+					lineageFields.set(lineageValues[1]),
+					lineageFields.set())
+				return db
+			},
+			result: types.Lineage{
+				Root: "strain#s2",
+				Nodes: append(append([]types.LineageNode{}, level1.Nodes...),
+					lineageNode(types.GenerationNode, "g0", "g0", 2),
+					lineageNode(types.SourceNode, "src0", "Clone", 2),
+					lineageNode(types.StrainNode, "s0", "grandparent", 2)),
+				Edges: append(append([]types.LineageEdge{}, level1.Edges...),
+					types.LineageEdge{From: "strain#s0", To: "source#src0"},
+					types.LineageEdge{From: "source#src0", To: "generation#g0"},
+					types.LineageEdge{From: "generation#g0", To: "strain#s1"}),
+			},
+		},
+		"one_generation": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s2", "child", "", "v0"}),
+					lineageFields.set(lineageValues[0]))
+				return db
+			},
+			depth:  1,
+			result: level1,
+		},
+		"cycle": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s2", "child", "", "v0"}),
+					lineageFields.set(lineageValues[0]),
+					// nothing should ask about s2 again
+					lineageFields.set([]driver.Value{"g0", "src0", "Clone", nil, "s2", "child", "", "v0", "s1", "parent", "", "v0"}))
+				return db
+			},
+			result: types.Lineage{
+				Root: "strain#s2",
+				Nodes: append(append([]types.LineageNode{}, level1.Nodes...),
+					lineageNode(types.GenerationNode, "g0", "g0", 2),
+					lineageNode(types.SourceNode, "src0", "Clone", 2)),
+				Edges: append(append([]types.LineageEdge{}, level1.Edges...),
+					types.LineageEdge{From: "strain#s2", To: "source#src0"},
+					types.LineageEdge{From: "source#src0", To: "generation#g0"},
+					types.LineageEdge{From: "generation#g0", To: "strain#s1"}),
+			},
+		},
+		"missing_strain": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lineageStrainFields.set())
+				return db
+			},
+			err: sql.ErrNoRows,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s2", "child", "", "v0"}),
+					lineageFields.fail())
+				return db
+			},
+			result: types.Lineage{
+				Root:  "strain#s2",
+				Nodes: []types.LineageNode{lineageNode(types.StrainNode, "s2", "child", 0)},
+				Edges: []types.LineageEdge{},
+			},
+			err: lineageFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).Ancestry(context.Background(), "s2", tc.depth, "Test_Ancestry")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_Descendants(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "Descendants")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.Lineage
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s0", "grandparent", "", "v0"}),
+					lineageFields.set(
+						lineageValues[1],
+						// a generation that hasn't been made into a strain yet
+						[]driver.Value{"g2", "src2", "Spore", nil, "s0", "grandparent", "", "v0", nil, nil, nil, nil}),
+					lineageFields.set())
+				return db
+			},
+			result: types.Lineage{
+				Root: "strain#s0",
+				Nodes: []types.LineageNode{
+					lineageNode(types.StrainNode, "s0", "grandparent", 0),
+					lineageNode(types.GenerationNode, "g0", "g0", 1),
+					lineageNode(types.SourceNode, "src0", "Clone", 1),
+					lineageNode(types.StrainNode, "s1", "parent", 1),
+					lineageNode(types.GenerationNode, "g2", "g2", 1),
+					lineageNode(types.SourceNode, "src2", "Spore", 1),
+				},
+				Edges: []types.LineageEdge{
+					{From: "strain#s0", To: "source#src0"},
+					{From: "source#src0", To: "generation#g0"},
+					{From: "generation#g0", To: "strain#s1"},
+					{From: "strain#s0", To: "source#src2"},
+					{From: "source#src2", To: "generation#g2"},
+				},
+			},
+		},
+		"missing_strain": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lineageStrainFields.fail())
+				return db
+			},
+			err: lineageStrainFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).Descendants(context.Background(), "s0", 0, "Test_Descendants")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_Pedigree(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "Pedigree")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.Lineage
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s1", "parent", "", "v0"}),
+					lineageFields.set(lineageValues[1]),
+					lineageStrainFields.set([]driver.Value{"s1", "parent", "", "v0"}),
+					lineageFields.set(lineageValues[0]),
+					statusFields.set([]driver.Value{"lc1", types.HarvestingEvent, types.InfoSeverity}),
+					statusFields.set([]driver.Value{"g0", "Mold", types.ErrorSeverity}))
+				return db
+			},
+			result: types.Lineage{
+				Root: "strain#s1",
+				Nodes: []types.LineageNode{
+					lineageNode(types.StrainNode, "s1", "parent", 0),
+					func() types.LineageNode {
+						result := lineageNode(types.GenerationNode, "g0", "g0", 1)
+						result.Outcome = types.ContaminatedStatus
+						return result
+					}(),
+					lineageNode(types.SourceNode, "src0", "Clone", 1),
+					lineageNode(types.StrainNode, "s0", "grandparent", 1),
+					func() types.LineageNode {
+						result := lineageNode(types.GenerationNode, "g1", "g1", 1)
+						result.Outcome = types.PendingStatus
+						return result
+					}(),
+					lineageNode(types.SourceNode, "src1", "Spore", 1),
+					func() types.LineageNode {
+						result := lineageNode(types.LifecycleNode, "lc1", "lc1", 1)
+						result.Outcome = types.HarvestedStatus
+						return result
+					}(),
+					lineageNode(types.StrainNode, "s2", "child", 1),
+				},
+				Edges: []types.LineageEdge{
+					{From: "strain#s0", To: "source#src0"},
+					{From: "source#src0", To: "generation#g0"},
+					{From: "generation#g0", To: "strain#s1"},
+					{From: "strain#s1", To: "lifecycle#lc1"},
+					{From: "lifecycle#lc1", To: "source#src1"},
+					{From: "source#src1", To: "generation#g1"},
+					{From: "generation#g1", To: "strain#s2"},
+				},
+			},
+		},
+		"ancestry_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lineageStrainFields.fail())
+				return db
+			},
+			err: lineageStrainFields.err(),
+		},
+		"descendants_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lineageStrainFields.set([]driver.Value{"s1", "parent", "", "v0"}),
+					lineageFields.set(),
+					lineageStrainFields.set([]driver.Value{"s1", "parent", "", "v0"}),
+					lineageFields.fail())
+				return db
+			},
+			err: lineageFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).Pedigree(context.Background(), "s1", 1, "Test_Pedigree")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_Library(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "Library")
+
+	strains := lineageStrainFields.set(
+		[]driver.Value{"s0", "grandparent", "", "v0"},
+		[]driver.Value{"s1", "parent", "", "v0"},
+		[]driver.Value{"s2", "child", "", "v0"},
+		// nothing came from this one, and it didn't come from anything
+		[]driver.Value{"s3", "loner", "P. cubensis", "v1"})
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.Lineage
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					strains,
+					lineageFields.set(lineageValues[1], lineageValues[0]),
+					statusFields.set(),
+					statusFields.set())
+				return db
+			},
+			result: types.Lineage{
+				Nodes: []types.LineageNode{
+					lineageNode(types.StrainNode, "s0", "grandparent", 0),
+					lineageNode(types.StrainNode, "s1", "parent", 0),
+					lineageNode(types.StrainNode, "s2", "child", 0),
+					{ID: "strain#s3", Kind: types.StrainNode, UUID: "s3", Label: "loner", Species: "P. cubensis", Vendor: "v1"},
+					func() types.LineageNode {
+						result := lineageNode(types.GenerationNode, "g0", "g0", 0)
+						result.Outcome = types.PendingStatus
+						return result
+					}(),
+					lineageNode(types.SourceNode, "src0", "Clone", 0),
+					func() types.LineageNode {
+						result := lineageNode(types.GenerationNode, "g1", "g1", 0)
+						result.Outcome = types.PendingStatus
+						return result
+					}(),
+					lineageNode(types.SourceNode, "src1", "Spore", 0),
+					func() types.LineageNode {
+						result := lineageNode(types.LifecycleNode, "lc1", "lc1", 0)
+						result.Outcome = types.PendingStatus
+						return result
+					}(),
+				},
+				Edges: []types.LineageEdge{
+					{From: "strain#s0", To: "source#src0"},
+					{From: "source#src0", To: "generation#g0"},
+					{From: "generation#g0", To: "strain#s1"},
+					{From: "strain#s1", To: "lifecycle#lc1"},
+					{From: "lifecycle#lc1", To: "source#src1"},
+					{From: "source#src1", To: "generation#g1"},
+					{From: "generation#g1", To: "strain#s2"},
+				},
+			},
+		},
+		"strains_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lineageStrainFields.fail())
+				return db
+			},
+			result: types.Lineage{Nodes: []types.LineageNode{}, Edges: []types.LineageEdge{}},
+			err:    lineageStrainFields.err(),
+		},
+		"sources_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lineageStrainFields.set(), lineageFields.fail())
+				return db
+			},
+			result: types.Lineage{Nodes: []types.LineageNode{}, Edges: []types.LineageEdge{}},
+			err:    lineageFields.err(),
+		},
+		"statuses_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lineageStrainFields.set(), lineageFields.set(), statusFields.fail())
+				return db
+			},
+			result: types.Lineage{Nodes: []types.LineageNode{}, Edges: []types.LineageEdge{}},
+			err:    statusFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).Library(context.Background(), "Test_Library")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) SelectAllLocations(ctx context.Context, cid types.CID) ([]types.Location, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectAllLocations", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["location"]["select-all"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.Location, 0, 20)
+	for rows.Next() {
+		row := types.Location{}
+		if err = rows.Scan(
+			&row.UUID,
+			&row.Name,
+			&row.Kind,
+			&row.Capacity,
+			&row.Target.MinTemperature,
+			&row.Target.MaxTemperature,
+			&row.Target.MinHumidity,
+			&row.Target.MaxHumidity,
+			&row.Target.MaxCO2,
+		); err != nil {
+			break
+		}
+		result = append(result, row)
+	}
+
+	return result, err
+}
+
+func (db *Conn) SelectLocation(ctx context.Context, id types.UUID, cid types.CID) (types.Location, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectLocation", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result := types.Location{UUID: id}
+	err = db.
+		QueryRowContext(ctx, psqls["location"]["select"], id).
+		Scan(
+			&result.UUID,
+			&result.Name,
+			&result.Kind,
+			&result.Capacity,
+			&result.Target.MinTemperature,
+			&result.Target.MaxTemperature,
+			&result.Target.MinHumidity,
+			&result.Target.MaxHumidity,
+			&result.Target.MaxCO2,
+		)
+
+	return result, err
+}
+
+func (db *Conn) InsertLocation(ctx context.Context, loc types.Location, cid types.CID) (types.Location, error) {
+	var err error
+	deferred, l := initAccessFuncs("InsertLocation", db.logger, loc.UUID, cid)
+	defer deferred(&err, l)
+
+	loc.UUID = types.UUID(db.generateUUID().String())
+	// the database compares names without case or surrounding whitespace, see
+	// locations_by_name, so there's no point storing any
+	loc.Name = strings.TrimSpace(loc.Name)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["location"]["insert"],
+		loc.UUID,
+		loc.Name,
+		loc.Kind,
+		loc.Capacity,
+		loc.Target.MinTemperature,
+		loc.Target.MaxTemperature,
+		loc.Target.MinHumidity,
+		loc.Target.MaxHumidity,
+		loc.Target.MaxCO2)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.InsertLocation(ctx, loc, cid)
+		}
+		err = pqerr(err)
+		return loc, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return loc, err
+	} else if rows != 1 {
+		err = fmt.Errorf("location was not added")
+	}
+
+	return loc, err
+}
+
+func (db *Conn) UpdateLocation(ctx context.Context, id types.UUID, loc types.Location, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("UpdateLocation", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result, err := db.ExecContext(ctx, psqls["location"]["update"],
+		strings.TrimSpace(loc.Name),
+		loc.Kind,
+		loc.Capacity,
+		loc.Target.MinTemperature,
+		loc.Target.MaxTemperature,
+		loc.Target.MinHumidity,
+		loc.Target.MaxHumidity,
+		loc.Target.MaxCO2,
+		id)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		return fmt.Errorf("location was not updated: '%s'", id)
+	}
+	return nil
+}
+
+func (db *Conn) DeleteLocation(ctx context.Context, id types.UUID, cid types.CID) error {
+	return db.deleteByUUID(ctx, id, cid, "DeleteLocation", "location", db.logger)
+}
+
+func (db *Conn) LocationOccupancy(ctx context.Context, id types.UUID, cid types.CID) (types.Occupancy, error) {
+	var err error
+	deferred, l := initAccessFuncs("LocationOccupancy", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	loc, err := db.SelectLocation(ctx, id, cid)
+	if err != nil {
+		return types.Occupancy{}, err
+	}
+
+	p, _ := types.NewReportAttrs(map[string][]string{"location-id": {string(id)}})
+
+	lcs, err := db.selectLifecycles(ctx, p, cid)
+	if err != nil {
+		return types.Occupancy{}, err
+	}
+
+	return types.NewOccupancy(loc, lcs), nil
+}
@@ -0,0 +1,355 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_locs = []types.Location{
+		{
+			UUID:     "chamber b",
+			Name:     "chamber b",
+			Kind:     types.FruitingChamberLocation,
+			Capacity: 4,
+			Target:   types.Environment{MinTemperature: f32ptr(20), MaxTemperature: f32ptr(24), MinHumidity: f32ptr(85), MaxCO2: f32ptr(1000)},
+		},
+		{UUID: "back yard", Name: "back yard", Kind: types.OutdoorBedLocation},
+	}
+	locFields = row{
+		"uuid",
+		"name",
+		"kind",
+		"capacity",
+		"min_temperature",
+		"max_temperature",
+		"min_humidity",
+		"max_humidity",
+		"max_co2",
+	}
+	locValues = [][]driver.Value{
+		{_locs[0].UUID, _locs[0].Name, _locs[0].Kind, _locs[0].Capacity, 20, 24, 85, nil, 1000},
+		{_locs[1].UUID, _locs[1].Name, _locs[1].Kind, _locs[1].Capacity, nil, nil, nil, nil, nil},
+	}
+)
+
+func Test_SelectAllLocations(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectAllLocations")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Location
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.set(locValues...))
+				return db
+			},
+			result: _locs,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.fail())
+				return db
+			},
+			err: locFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectAllLocations(context.Background(), "Test_SelectAllLocations")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_SelectLocation(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectLocation")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		id     types.UUID
+		result types.Location
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.set(locValues[0]))
+				return db
+			},
+			id:     _locs[0].UUID,
+			result: _locs[0],
+		},
+		"no_result": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.set())
+				return db
+			},
+			id:     "missing",
+			result: types.Location{UUID: "missing"},
+			err:    sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectLocation(context.Background(), tc.id, "Test_SelectLocation")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_InsertLocation(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "InsertLocation")
+
+	added := _locs[0]
+	added.UUID = "30313233-3435-3637-3839-616263646566"
+
+	tcs := map[string]struct {
+		db     getMockDB
+		loc    types.Location
+		result types.Location
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			loc:    _locs[0],
+			result: added,
+		},
+		"untrimmed_name": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WithArgs(added.UUID, "chamber b", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			loc: func(loc types.Location) types.Location {
+				loc.Name = "  chamber b "
+				return loc
+			}(_locs[0]),
+			result: added,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			loc:    _locs[0],
+			result: added,
+			err:    fmt.Errorf("location was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			loc:    _locs[0],
+			result: added,
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).InsertLocation(context.Background(), tc.loc, "Test_InsertLocation")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_UpdateLocation(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "UpdateLocation")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("location was not updated: 'chamber b'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).UpdateLocation(context.Background(), _locs[0].UUID, _locs[0], "Test_UpdateLocation")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_DeleteLocation(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "DeleteLocation")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("location could not be deleted: 'back yard'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).DeleteLocation(context.Background(), _locs[1].UUID, "Test_DeleteLocation")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_LocationOccupancy(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "LocationOccupancy")
+
+	tcs := map[string]struct {
+		db       getMockDB
+		occupied int
+		err      error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					locFields.set(locValues[0]),
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...))
+				return db
+			},
+			occupied: 1,
+		},
+		"empty": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.set(locValues[0]), lcFields.set())
+				return db
+			},
+		},
+		"location_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.fail())
+				return db
+			},
+			err: locFields.err(),
+		},
+		"lifecycles_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, locFields.set(locValues[0]), lcFields.fail())
+				return db
+			},
+			err: lcFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).LocationOccupancy(context.Background(), _locs[0].UUID, "Test_LocationOccupancy")
+
+			require.Equal(t, tc.err, err)
+			if tc.err == nil {
+				require.Equal(t, _locs[0], result.Location)
+				require.Equal(t, tc.occupied, result.Occupied)
+				require.Equal(t, tc.occupied, len(result.Lifecycles))
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+// MeasurementStats only considers lifecycles, the same as StageDurations; a
+// lifecycle counts once for every event that recorded the field, and groups
+// without any values are left out
+func (db *Conn) MeasurementStats(ctx context.Context, fieldID types.UUID, by types.Dimension, w types.Window, cid types.CID) ([]types.MeasurementStats, error) {
+	var err error
+	deferred, l := initAccessFuncs("MeasurementStats", db.logger, fieldID, cid)
+	defer deferred(&err, l)
+
+	key, ok := dimensions[by]
+	if !ok {
+		err = fmt.Errorf("unknown dimension for measurement stats: '%s'", by)
+		return nil, err
+	}
+
+	var f types.Field
+	if err = db.
+		QueryRowContext(ctx, psqls["field"]["select"], fieldID).
+		Scan(&f.UUID, &f.Name, &f.Type, &f.Unit, &f.Min, &f.Max); err != nil {
+		return nil, err
+	} else if f.Type != types.NumberField && f.Type != types.IntegerField {
+		err = fmt.Errorf("only number and integer fields can be summarized: '%s'", f.Name)
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, psqls["analytics"]["lifecycle-measurements"], fieldID, w.From, w.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type group struct {
+		key, label string
+		values     []float64
+	}
+
+	groups := []*group{}
+	index := map[string]*group{}
+	for rows.Next() {
+		var lc types.Lifecycle
+		var value float64
+		if err = rows.Scan(
+			&lc.UUID,
+			&lc.Location.UUID,
+			&lc.Location.Name,
+			&lc.CTime,
+			&lc.Strain.UUID,
+			&lc.Strain.Name,
+			&lc.Strain.Vendor.UUID,
+			&lc.Strain.Vendor.Name,
+			&lc.GrainSubstrate.UUID,
+			&lc.GrainSubstrate.Name,
+			&lc.BulkSubstrate.UUID,
+			&lc.BulkSubstrate.Name,
+			&value,
+		); err != nil {
+			return nil, err
+		}
+
+		k, label := key(lc)
+		g, ok := index[k]
+		if !ok {
+			g = &group{key: k, label: label}
+			index[k] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, value)
+	}
+
+	result := make([]types.MeasurementStats, len(groups))
+	for i, g := range groups {
+		result[i] = types.NewMeasurementStats(g.values)
+		result[i].Dimension, result[i].Key, result[i].Label = by, g.key, g.label
+	}
+
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_MeasurementStats(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "MeasurementStats")
+
+	measurementFields := append(append(row{}, lcEventFields[:12]...), "value")
+	measurementValues := append(append(xformer{}, lcEventValues[:12]...), 1.0)
+
+	tcs := map[string]struct {
+		db     getMockDB
+		by     types.Dimension
+		result []types.MeasurementStats
+		err    error
+	}{
+		"by_location": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					fieldFields.set(fieldValues[0]),
+					measurementFields.set(
+						measurementValues.replace(xform{12: 10.0}),
+						measurementValues.replace(xform{12: 14.0}),
+						measurementValues.replace(xform{0: "lc1", 1: "shelf 1", 2: "shelf 1", 12: 12.0}),
+					))
+				return db
+			},
+			by: types.LocationDimension,
+			result: func() []types.MeasurementStats {
+				shelf0, shelf1 := types.NewMeasurementStats([]float64{10, 14}), types.NewMeasurementStats([]float64{12})
+				shelf0.Dimension, shelf0.Key, shelf0.Label = types.LocationDimension, "shelf 0", "shelf 0"
+				shelf1.Dimension, shelf1.Key, shelf1.Label = types.LocationDimension, "shelf 1", "shelf 1"
+				return []types.MeasurementStats{shelf0, shelf1}
+			}(),
+		},
+		"nothing_measured": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.set(fieldValues[0]), measurementFields.set())
+				return db
+			},
+			by:     types.StrainDimension,
+			result: []types.MeasurementStats{},
+		},
+		"unknown_dimension": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			by:  "species",
+			err: fmt.Errorf("unknown dimension for measurement stats: 'species'"),
+		},
+		"missing_field": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.set())
+				return db
+			},
+			by:  types.StrainDimension,
+			err: sql.ErrNoRows,
+		},
+		"text_field": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.set(fieldValues[1]))
+				return db
+			},
+			by:  types.StrainDimension,
+			err: fmt.Errorf("only number and integer fields can be summarized: 'color'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fieldFields.set(fieldValues[0]), measurementFields.fail())
+				return db
+			},
+			by:  types.StrainDimension,
+			err: measurementFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).MeasurementStats(context.Background(), "field 0", tc.by, types.Window{}, "Test_MeasurementStats")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
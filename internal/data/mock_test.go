@@ -123,3 +123,17 @@ func (m *mocker) add(fns ...func(*mocker) *mocker) *mocker {
 	}
 	return m
 }
+
+// keyed is r behind the column a multi-key query groups its rows by
+func (r row) keyed(key string) row {
+	return append(row{key}, r...)
+}
+
+// keyed is every one of rows behind the key it's grouped by
+func keyed(key driver.Value, rows ...[]driver.Value) [][]driver.Value {
+	result := make([][]driver.Value, len(rows))
+	for i, r := range rows {
+		result[i] = append([]driver.Value{key}, r...)
+	}
+	return result
+}
@@ -42,6 +42,41 @@ func (db *Conn) GetNotes(ctx context.Context, id types.UUID, cid types.CID) ([]t
 	return result, nil
 }
 
+// GetNotesFor is GetNotes for every one of ids with one query; an id that
+// has no notes isn't in the result
+func (db *Conn) GetNotesFor(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID][]types.Note, error) {
+	var err error
+	deferred, l := initAccessFuncs("GetNotesFor", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	var rows *sql.Rows
+	result := make(map[types.UUID][]types.Note, len(ids))
+
+	rows, err = db.query.QueryContext(ctx, psqls["note"]["get-for"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id types.UUID
+		note := types.Note{}
+		if err = rows.Scan(
+			&id,
+			&note.UUID,
+			&note.Note,
+			&note.MTime,
+			&note.CTime,
+		); err != nil {
+			return result, err
+		}
+		result[id] = append(result[id], note)
+	}
+
+	return result, nil
+}
+
 func (db *Conn) AddNote(ctx context.Context, oID types.UUID, notes []types.Note, n types.Note, cid types.CID) ([]types.Note, error) {
 	var err error
 	deferred, l := initAccessFuncs("AddNote", db.logger, oID, cid)
@@ -322,3 +322,60 @@ func Test_RemoveNote(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetNotesFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetNotesFor")
+
+	fields := noteFields.keyed("notable_uuid")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID][]types.Note
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(append(
+					keyed("0", noteValues[0], noteValues[1]),
+					keyed("1", noteValues[2])...)...))
+				return db
+			},
+			result: map[types.UUID][]types.Note{
+				"0": {types.Note(_notes[0]), types.Note(_notes[1])},
+				"1": {types.Note(_notes[2])},
+			},
+		},
+		"no_notes": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set())
+				return db
+			},
+			result: map[types.UUID][]types.Note{},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetNotesFor(context.Background(), []types.UUID{"0", "1", "2"}, "Test_GetNotesFor")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -3,7 +3,9 @@ package data
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jsmit257/huautla/types"
@@ -20,8 +22,34 @@ type (
 		filename     *string
 		ctime, mtime *time.Time
 	}
+	// whatever an outer join found for an optional event type
+	nulleventtype struct {
+		uuid, stageUUID           *types.UUID
+		name, severity, stageName *string
+	}
+
+	statusMap map[types.UUID]types.Status
 )
 
+func (x nulleventtype) eventType() *types.EventType {
+	if x.uuid == nil {
+		return nil
+	}
+	return &types.EventType{
+		UUID:     *x.uuid,
+		Name:     *x.name,
+		Severity: *x.severity,
+		Stage:    types.Stage{UUID: *x.stageUUID, Name: *x.stageName},
+	}
+}
+
+func (m statusMap) of(id types.UUID) types.Status {
+	if result, ok := m[id]; ok {
+		return result
+	}
+	return types.PendingStatus
+}
+
 func (db *Conn) SelectByObservable(ctx context.Context, oID types.UUID, cid types.CID) ([]types.Event, error) {
 	var err error
 	var result []types.Event
@@ -41,17 +69,20 @@ func (db *Conn) SelectByEventType(ctx context.Context, et types.EventType, cid t
 	deferred, l := initAccessFuncs("SelectByEventType", db.logger, et.UUID, cid)
 	defer deferred(&err, l)
 
-	result, err = db.selectEventsList(ctx, psqls["event"]["all-by-eventtype"], et.UUID, cid)
+	result, err = db.selectEventsList(ctx, psqls["event"]["all-by-eventtype"], et.UUID, cid, tagFilter(ctx))
 
 	return result, err
 }
 
-func (db *Conn) selectEventsList(ctx context.Context, query string, id types.UUID, _ types.CID) ([]types.Event, error) {
+// selectEventsList passes params along after id, for queries that need more
+func (db *Conn) selectEventsList(ctx context.Context, query string, id types.UUID, _ types.CID, params ...any) ([]types.Event, error) {
 	var err error
 	var rows *sql.Rows
 
+	units := db.unitSystem(ctx)
+
 	result := make([]types.Event, 0, 1000)
-	rows, err = db.query.QueryContext(ctx, query, id)
+	rows, err = db.query.QueryContext(ctx, query, append([]any{id}, params...)...)
 	if err != nil {
 		return result, err
 	}
@@ -59,35 +90,75 @@ func (db *Conn) selectEventsList(ctx context.Context, query string, id types.UUI
 	defer rows.Close()
 
 	for rows.Next() {
-		row := types.Event{}
-
-		if err = rows.Scan(
-			&row.UUID,
-			&row.Temperature,
-			&row.Humidity,
-			&row.MTime,
-			&row.CTime,
-			&row.EventType.UUID,
-			&row.EventType.Name,
-			&row.EventType.Severity,
-			&row.EventType.Stage.UUID,
-			&row.EventType.Stage.Name,
-		); err != nil {
+		var row types.Event
+		if row, err = scanEvent(rows, units); err != nil {
 			return result, err
 		}
-
 		result = append(result, row)
 	}
 
 	return result, err
 }
 
+// selectEventsFor is every event observed by any of ids, by observable, with
+// one query; an id that has no events isn't in the result
+func (db *Conn) selectEventsFor(ctx context.Context, ids []types.UUID) (map[types.UUID][]types.Event, error) {
+	units := db.unitSystem(ctx)
+
+	rows, err := db.query.QueryContext(ctx, psqls["event"]["all-by-observables"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[types.UUID][]types.Event, len(ids))
+	for rows.Next() {
+		var id types.UUID
+		var row types.Event
+		if row, err = scanEvent(rows, units, &id); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], row)
+	}
+
+	return result, nil
+}
+
+// scanEvent reads whatever comes ahead of the event's own columns into first
+func scanEvent(rows *sql.Rows, units types.UnitSystem, first ...any) (types.Event, error) {
+	row := types.Event{}
+	var ms []byte
+
+	err := rows.Scan(append(first,
+		&row.UUID,
+		&row.Temperature,
+		&row.Humidity,
+		&row.OccurredAt,
+		&row.MTime,
+		&row.CTime,
+		&row.EventType.UUID,
+		&row.EventType.Name,
+		&row.EventType.Severity,
+		&row.EventType.Stage.UUID,
+		&row.EventType.Stage.Name,
+		&ms,
+	)...)
+	if err != nil {
+		return row, err
+	} else if row.Measurements, err = unmarshalMeasurements(ms); err != nil {
+		return row, err
+	}
+
+	return row.InUnits(units), nil
+}
+
 func (db *Conn) SelectEvent(ctx context.Context, id types.UUID, cid types.CID) (types.Event, error) {
 	var err error
 	deferred, l := initAccessFuncs("SelectEvent", db.logger, id, cid)
 	defer deferred(&err, l)
 
 	result := types.Event{UUID: id}
+	var ms []byte
 
 	if err = db.
 		QueryRowContext(ctx, psqls["event"]["select"], id).
@@ -95,6 +166,7 @@ func (db *Conn) SelectEvent(ctx context.Context, id types.UUID, cid types.CID) (
 			&result.UUID,
 			&result.Temperature,
 			&result.Humidity,
+			&result.OccurredAt,
 			&result.MTime,
 			&result.CTime,
 			&result.EventType.UUID,
@@ -102,75 +174,102 @@ func (db *Conn) SelectEvent(ctx context.Context, id types.UUID, cid types.CID) (
 			&result.EventType.Severity,
 			&result.EventType.Stage.UUID,
 			&result.EventType.Stage.Name,
+			&ms,
 		); err != nil {
 		return result, err
+	} else if result.Measurements, err = unmarshalMeasurements(ms); err != nil {
+		return result, err
 	}
 
-	return result, err
+	return result.InUnits(db.unitSystem(ctx)), err
 }
 
 func (db *Conn) InsertEvent(ctx context.Context, oID types.UUID, e types.Event, cid types.CID) (types.Event, error) {
 	var err error
-	var result sql.Result
 
 	deferred, l := initAccessFuncs("InsertEvent", db.logger, oID, cid)
 	defer deferred(&err, l)
 
+	if err = db.checkStoredTransition(ctx, oID, &e, cid); err != nil {
+		return e, err
+	} else if err = db.checkMeasurements(ctx, &e, cid); err != nil {
+		return e, err
+	}
+
 	e.UUID = types.UUID(db.generateUUID().String())
 	e.MTime = time.Now().UTC()
 	e.CTime = e.MTime
 
-	if result, err = db.ExecContext(ctx, psqls["event"]["add"],
-		e.UUID,
-		e.Temperature,
-		e.Humidity,
-		e.MTime,
-		e.CTime,
-		oID,
-		e.EventType.UUID,
-	); err != nil {
-		if isPrimaryKeyViolation(err) {
-			return db.InsertEvent(ctx, oID, e, cid)
-		}
-		return e, err
-	} else if rows, err := result.RowsAffected(); err != nil {
+	if e.OccurredAt, err = occurredAt(e); err != nil {
 		return e, err
-	} else if rows != 1 { // most likely cause is a bad eventtype.uuid
-		return e, fmt.Errorf("event was not added")
 	}
 
-	err = db.UpdateObservableMtime(ctx, oID, e.UUID, e.MTime, cid)
+	err = db.inTx(ctx, func(tx *Conn) error {
+		if result, err := tx.ExecContext(ctx, psqls["event"]["add"],
+			e.UUID,
+			tx.temperature(ctx, &e),
+			e.Humidity,
+			e.MTime,
+			e.CTime,
+			oID,
+			e.EventType.UUID,
+			e.OccurredAt,
+		); err != nil {
+			return err
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 { // most likely cause is a bad eventtype.uuid
+			return fmt.Errorf("event was not added")
+		} else if err = tx.addMeasurements(ctx, e, cid); err != nil {
+			return err
+		}
+		return tx.UpdateObservableMtime(ctx, oID, e.UUID, e.MTime, cid)
+	})
+	if isPrimaryKeyViolation(err) {
+		// the transaction is spoiled by now, so the retry needs its own
+		return db.InsertEvent(ctx, oID, e, cid)
+	} else if err != nil {
+		return e, err
+	}
 
-	return e, err
+	return e.InUnits(db.unitSystem(ctx)), nil
 }
 
 func (db *Conn) UpdateEvent(ctx context.Context, oID types.UUID, e types.Event, cid types.CID) (types.Event, error) {
 	var err error
-	var result sql.Result
-	var rows int64
 
 	deferred, l := initAccessFuncs("UpdateEvent", db.logger, e.UUID, cid)
 	defer deferred(&err, l)
 
 	e.MTime = time.Now().UTC()
 
-	if err = db.UpdateObservableMtime(ctx, oID, e.UUID, e.MTime, cid); err != nil {
+	if err = db.checkMeasurements(ctx, &e, cid); err != nil {
 		return e, err
-	} else if result, err = db.ExecContext(ctx, psqls["event"]["change"],
-		e.Temperature,
-		e.Humidity,
-		e.MTime,
-		e.UUID,
-		e.EventType.UUID,
-	); err != nil {
+	} else if err = checkOccurredAt(e); err != nil {
 		return e, err
-	} else if rows, err = result.RowsAffected(); err != nil {
+	} else if err = db.inTx(ctx, func(tx *Conn) error {
+		if err := tx.UpdateObservableMtime(ctx, oID, e.UUID, e.MTime, cid); err != nil {
+			return err
+		} else if result, err := tx.ExecContext(ctx, psqls["event"]["change"],
+			tx.temperature(ctx, &e),
+			e.Humidity,
+			e.MTime,
+			e.UUID,
+			e.EventType.UUID,
+			nullTime(e.OccurredAt),
+		); err != nil {
+			return err
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 { // most likely cause is a bad eventtype.uuid
+			return fmt.Errorf("event was not changed")
+		}
+		return tx.addMeasurements(ctx, e, cid)
+	}); err != nil {
 		return e, err
-	} else if rows != 1 { // most likely cause is a bad eventtype.uuid
-		return e, fmt.Errorf("event was not changed")
 	}
 
-	return e, err
+	return e.InUnits(db.unitSystem(ctx)), err
 }
 
 func (db *Conn) DeleteEvent(ctx context.Context, oID types.UUID, evID types.UUID, cid types.CID) error {
@@ -213,6 +312,74 @@ func (db *Conn) UpdateObservableMtime(ctx context.Context, oID types.UUID, evID
 	return nil
 }
 
+// checkTransition fills in e's event type, since the rules need its severity
+// and stage, then holds it up to events under the connection's policy
+func (db *Conn) checkTransition(ctx context.Context, events []types.Event, e *types.Event, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("checkTransition", db.logger, e.EventType.UUID, cid)
+	defer deferred(&err, l)
+
+	if e.EventType, err = db.SelectEventType(ctx, e.EventType.UUID, cid); err != nil {
+		return fmt.Errorf("couldn't fetch eventtype")
+	} else if db.transitions == types.IgnoreTransitions {
+		return nil
+	} else if err = types.CheckTransition(events, e.EventType); err != nil && db.transitions == types.LenientTransitions {
+		l.WithError(err).Warn("accepting an illegal transition")
+		err = nil
+	}
+
+	return err
+}
+
+// checkStoredTransition is checkTransition against the events stored for
+// oID, rather than whatever copy the caller has, which may be stale; there's
+// no point loading them when the policy ignores them, but e still needs its
+// event type
+func (db *Conn) checkStoredTransition(ctx context.Context, oID types.UUID, e *types.Event, cid types.CID) error {
+	if db.transitions == types.IgnoreTransitions {
+		return db.checkTransition(ctx, nil, e, cid)
+	}
+
+	events, err := db.selectEventsList(ctx, psqls["event"]["all-by-observable"], oID, cid)
+	if err != nil {
+		return err
+	}
+
+	return db.checkTransition(ctx, events, e, cid)
+}
+
+// selectStatuses only needs enough of the events for ids to derive a status;
+// an observable without any events won't be in the result, which NewStatus
+// treats as pending anyway
+func (db *Conn) selectStatuses(ctx context.Context, query string, ids []types.UUID, cid types.CID) (statusMap, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectStatuses", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, query, uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := map[types.UUID][]types.Event{}
+	for rows.Next() {
+		var id types.UUID
+		e := types.Event{}
+		if err = rows.Scan(&id, &e.EventType.Name, &e.EventType.Severity); err != nil {
+			return nil, err
+		}
+		events[id] = append(events[id], e)
+	}
+
+	result := make(statusMap, len(events))
+	for id, e := range events {
+		result[id] = types.NewStatus(e)
+	}
+
+	return result, err
+}
+
 func (db *Conn) notesAndPhotos(ctx context.Context, e []types.Event, id types.UUID, cid types.CID) error {
 	var err error
 	deferred, l := initAccessFuncs("notesAndPhotos", db.logger, id, cid)
@@ -305,40 +472,160 @@ func (db *Conn) notesAndPhotos(ctx context.Context, e []types.Event, id types.UU
 	return nil
 }
 
+// checkMeasurements holds e's measurements up to its event type's fields, and
+// converts them, before anything gets written; see NewMeasurements
+func (db *Conn) checkMeasurements(ctx context.Context, e *types.Event, cid types.CID) error {
+	if len(e.Measurements) == 0 { // saves a hit to the db
+		return nil
+	}
+
+	et := types.EventType{UUID: e.EventType.UUID}
+	if err := db.GetFields(ctx, &et, cid); err != nil {
+		return err
+	}
+
+	ms, err := types.NewMeasurements(et.Fields, e.Measurements)
+	if err != nil {
+		return err
+	}
+	e.Measurements = ms
+
+	return nil
+}
+
+// addMeasurements expects e to be written already, and its measurements to be
+// checked; changing an event clears the ones it had before
+func (db *Conn) addMeasurements(ctx context.Context, e types.Event, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("addMeasurements", db.logger, e.UUID, cid)
+	defer deferred(&err, l)
+
+	for _, m := range e.Measurements {
+		var js []byte
+		var result sql.Result
+		var rows int64
+		if js, err = json.Marshal(m.Value); err != nil {
+			return err
+		} else if result, err = db.ExecContext(ctx, psqls["event"]["add-measurement"], e.UUID, m.Field, string(js)); err != nil {
+			err = pqerr(err)
+			return err
+		} else if rows, err = result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 {
+			err = fmt.Errorf("measurement was not added: '%s'", m.Field)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unmarshalMeasurements is for the json array the event queries aggregate,
+// which is null for an event that didn't measure anything
+func unmarshalMeasurements(js []byte) ([]types.Measurement, error) {
+	if js == nil {
+		return nil, nil
+	}
+	var result []types.Measurement
+	err := json.Unmarshal(js, &result)
+	return result, err
+}
+
+// temperature is e's temperature in celsius, for storing; an unlabeled e is
+// taken to be in the units ctx asked for, and gets labeled as such
+func (db *Conn) temperature(ctx context.Context, e *types.Event) float32 {
+	if e.Units == "" {
+		e.Units = db.unitSystem(ctx)
+	}
+	return e.Units.Celsius(e.Temperature)
+}
+
+// occurredAt is now for an event that doesn't say when it happened; the
+// database holds it to its observable's lifetime, but it can't know what time
+// it is for the caller
+func occurredAt(e types.Event) (time.Time, error) {
+	if e.OccurredAt.IsZero() {
+		return e.CTime, nil
+	}
+	return e.OccurredAt.UTC(), checkOccurredAt(e)
+}
+
+func checkOccurredAt(e types.Event) error {
+	if e.OccurredAt.After(time.Now().UTC()) {
+		return fmt.Errorf("event can't have occurred in the future: '%s'", e.OccurredAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// nullTime is for a change, where a zero time leaves the stored one alone
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// sortEvents keeps events the way all-by-observable orders them: the most
+// recent occurrence first, and the most recently recorded first for a tie
+func sortEvents(events []types.Event) []types.Event {
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].OccurredAt.Equal(events[j].OccurredAt) {
+			return events[i].OccurredAt.After(events[j].OccurredAt)
+		}
+		return events[i].CTime.After(events[j].CTime)
+	})
+	return events
+}
+
 // DEPREACTED: use InsertEvent instead, but there's some effort decoupling events
 // from their parents throughout all the tiers, so we're leaving them for now
 func (db *Conn) addEvent(ctx context.Context, oID types.UUID, events []types.Event, e *types.Event, cid types.CID) ([]types.Event, error) {
 	var err error
-	var result sql.Result
+
+	if err = db.checkStoredTransition(ctx, oID, e, cid); err != nil {
+		return events, err
+	} else if err = db.checkMeasurements(ctx, e, cid); err != nil {
+		return events, err
+	}
 
 	e.UUID = types.UUID(db.generateUUID().String())
 	e.MTime = time.Now().UTC()
 	e.CTime = e.MTime
 
-	if result, err = db.ExecContext(ctx, psqls["event"]["add"],
-		e.UUID,
-		e.Temperature,
-		e.Humidity,
-		e.MTime,
-		e.CTime,
-		oID,
-		e.EventType.UUID,
-	); err != nil {
-		if isPrimaryKeyViolation(err) {
-			return db.addEvent(ctx, oID, events, e, cid)
-		}
+	if e.OccurredAt, err = occurredAt(*e); err != nil {
 		return events, err
-	} else if rows, err := result.RowsAffected(); err != nil {
-		return events, err
-	} else if rows != 1 { // most likely cause is a bad eventtype.uuid
-		return events, fmt.Errorf("event was not added")
 	}
 
-	if e.EventType, err = db.SelectEventType(ctx, e.EventType.UUID, cid); err != nil {
-		return events, fmt.Errorf("couldn't fetch eventtype")
+	err = db.inTx(ctx, func(tx *Conn) error {
+		if result, err := tx.ExecContext(ctx, psqls["event"]["add"],
+			e.UUID,
+			tx.temperature(ctx, e),
+			e.Humidity,
+			e.MTime,
+			e.CTime,
+			oID,
+			e.EventType.UUID,
+			e.OccurredAt,
+		); err != nil {
+			return err
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 { // most likely cause is a bad eventtype.uuid
+			return fmt.Errorf("event was not added")
+		}
+		return tx.addMeasurements(ctx, *e, cid)
+	})
+	if isPrimaryKeyViolation(err) {
+		// the transaction is spoiled by now, so the retry needs its own
+		return db.addEvent(ctx, oID, events, e, cid)
+	} else if err != nil {
+		return events, err
 	}
 
-	return append([]types.Event{*e}, events...), err
+	*e = e.InUnits(db.unitSystem(ctx))
+
+	return sortEvents(append([]types.Event{*e}, events...)), err
 }
 
 // DEPREACTED: use UpdateEvent instead, but there's some effort decoupling events
@@ -348,30 +635,49 @@ func (db *Conn) changeEvent(ctx context.Context, events []types.Event, e *types.
 
 	e.MTime = time.Now().UTC()
 
-	if result, err := db.ExecContext(ctx, psqls["event"]["change"],
-		e.Temperature,
-		e.Humidity,
-		e.MTime,
-		e.UUID,
-		e.EventType.UUID,
-	); err != nil {
+	i, j := 0, len(events)
+	for i < j && events[i].UUID != e.UUID {
+		i++
+	}
+	if e.OccurredAt.IsZero() && i < j {
+		e.OccurredAt = events[i].OccurredAt
+	}
+
+	if err = db.checkMeasurements(ctx, e, cid); err != nil {
 		return events, err
-	} else if rows, err := result.RowsAffected(); err != nil {
+	} else if err = checkOccurredAt(*e); err != nil {
+		return events, err
+	} else if err = db.inTx(ctx, func(tx *Conn) error {
+		if result, err := tx.ExecContext(ctx, psqls["event"]["change"],
+			tx.temperature(ctx, e),
+			e.Humidity,
+			e.MTime,
+			e.UUID,
+			e.EventType.UUID,
+			nullTime(e.OccurredAt),
+		); err != nil {
+			return err
+		} else if rows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 { // most likely cause is a bad eventtype.uuid
+			return fmt.Errorf("event was not changed")
+		}
+		return tx.addMeasurements(ctx, *e, cid)
+	}); err != nil {
 		return events, err
-	} else if rows != 1 { // most likely cause is a bad eventtype.uuid
-		return events, fmt.Errorf("event was not changed")
 	}
 
 	if e.EventType, err = db.SelectEventType(ctx, e.EventType.UUID, cid); err != nil {
 		return events, fmt.Errorf("couldn't fetch eventtype")
 	}
 
-	i, j := 0, len(events)
-	for i < j && events[i].UUID != e.UUID {
-		i++
+	*e = e.InUnits(db.unitSystem(ctx))
+
+	if i == j {
+		return sortEvents(append([]types.Event{*e}, events...)), nil
 	}
 
-	return append(append([]types.Event{*e}, events[:i]...), events[i+1:]...), nil
+	return sortEvents(append(append([]types.Event{*e}, events[:i]...), events[i+1:]...)), nil
 }
 
 // DEPREACTED: use DeleteEvent instead, but there's some effort decoupling events
@@ -6,6 +6,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"testing"
+	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	log "github.com/sirupsen/logrus"
@@ -15,15 +16,17 @@ import (
 )
 
 var (
+	someday = time.Date(2999, time.January, 1, 0, 0, 0, 0, time.UTC)
 	_events = []event{
-		{UUID: "eventuuid 0", Temperature: -40.0, Humidity: 0, MTime: wwtbn, CTime: wwtbn, EventType: types.EventType{UUID: "typeuuid 0", Name: "Clone", Severity: "e0.EventType.Severity", Stage: types.Stage{UUID: "e0.EventType.Stage.UUID", Name: "e0.EventType.Stage.Name"}}},
-		{UUID: "eventuuid 1", Temperature: 451, Humidity: 50, MTime: wwtbn, CTime: wwtbn, EventType: types.EventType{UUID: "typeuuid 1", Name: "e1.EventType.Name", Severity: "e1.EventType.Severity", Stage: types.Stage{UUID: "e1.EventType.Stage.UUID", Name: "e1.EventType.Stage.Name"}}},
-		{UUID: "eventuuid 2", Temperature: 10.0, Humidity: 100, MTime: wwtbn, CTime: wwtbn, EventType: types.EventType{UUID: "typeuuid 2", Name: "e2.EventType.Name", Severity: "e2.EventType.Severity", Stage: types.Stage{UUID: "e2.EventType.Stage.UUID", Name: "e2.EventType.Stage.Name"}}},
+		{UUID: "eventuuid 0", Temperature: -40.0, Humidity: 0, OccurredAt: wwtbn, MTime: wwtbn, CTime: wwtbn, EventType: types.EventType{UUID: "typeuuid 0", Name: "Clone", Severity: "e0.EventType.Severity", Stage: types.Stage{UUID: "e0.EventType.Stage.UUID", Name: "e0.EventType.Stage.Name"}}},
+		{UUID: "eventuuid 1", Temperature: 451, Humidity: 50, OccurredAt: wwtbn, MTime: wwtbn, CTime: wwtbn, EventType: types.EventType{UUID: "typeuuid 1", Name: "e1.EventType.Name", Severity: "e1.EventType.Severity", Stage: types.Stage{UUID: "e1.EventType.Stage.UUID", Name: "e1.EventType.Stage.Name"}}},
+		{UUID: "eventuuid 2", Temperature: 10.0, Humidity: 100, OccurredAt: wwtbn, MTime: wwtbn, CTime: wwtbn, EventType: types.EventType{UUID: "typeuuid 2", Name: "e2.EventType.Name", Severity: "e2.EventType.Severity", Stage: types.Stage{UUID: "e2.EventType.Stage.UUID", Name: "e2.EventType.Stage.Name"}}},
 	}
 	eventFields = row{
 		"id",
 		"temperature",
 		"humidity",
+		"occurred_at",
 		"mtime",
 		"ctime",
 		"eventtype_uuid",
@@ -31,13 +34,17 @@ var (
 		"eventtype_name",
 		"stage_uuid",
 		"stage_name",
+		"measurements",
 	}
 	eventValues = [][]driver.Value{
-		{_events[0].UUID, _events[0].Temperature, _events[0].Humidity, _events[0].MTime, _events[0].CTime, _events[0].EventType.UUID, _events[0].EventType.Name, _events[0].EventType.Severity, _events[0].EventType.Stage.UUID, _events[0].EventType.Stage.Name},
-		{_events[1].UUID, _events[1].Temperature, _events[1].Humidity, _events[1].MTime, _events[1].CTime, _events[1].EventType.UUID, _events[1].EventType.Name, _events[1].EventType.Severity, _events[1].EventType.Stage.UUID, _events[1].EventType.Stage.Name},
-		{_events[2].UUID, _events[2].Temperature, _events[2].Humidity, _events[2].MTime, _events[2].CTime, _events[2].EventType.UUID, _events[2].EventType.Name, _events[2].EventType.Severity, _events[2].EventType.Stage.UUID, _events[2].EventType.Stage.Name},
+		{_events[0].UUID, _events[0].Temperature, _events[0].Humidity, _events[0].OccurredAt, _events[0].MTime, _events[0].CTime, _events[0].EventType.UUID, _events[0].EventType.Name, _events[0].EventType.Severity, _events[0].EventType.Stage.UUID, _events[0].EventType.Stage.Name, nil},
+		{_events[1].UUID, _events[1].Temperature, _events[1].Humidity, _events[1].OccurredAt, _events[1].MTime, _events[1].CTime, _events[1].EventType.UUID, _events[1].EventType.Name, _events[1].EventType.Severity, _events[1].EventType.Stage.UUID, _events[1].EventType.Stage.Name, nil},
+		{_events[2].UUID, _events[2].Temperature, _events[2].Humidity, _events[2].OccurredAt, _events[2].MTime, _events[2].CTime, _events[2].EventType.UUID, _events[2].EventType.Name, _events[2].EventType.Severity, _events[2].EventType.Stage.UUID, _events[2].EventType.Stage.Name, nil},
 	}
 
+	// just enough of every event to derive a status
+	statusFields = row{"observable_uuid", "name", "severity"}
+
 	// nap == NotesAndPhotos; it's not really implemented for test
 	napFields = row{"uuid", "note_uuid", "note_note", "note_mtime", "note_ctime", "photo_uuid", "filename", "photo_mtime", "photo_ctime", "photonote_uuid", "photonote_note", "photonote_mtime", "photonote_ctime"}
 	napValues = [][]driver.Value{
@@ -151,9 +158,17 @@ func Test_SelectEvent(t *testing.T) {
 
 	l := log.WithField("test", "SelectEvent")
 
+	imperial := types.Event(_events[2])
+	imperial.Temperature, imperial.Units = 50, types.ImperialUnits
+
+	measured := types.Event(_events[0])
+	measured.Measurements = []types.Measurement{{Field: "diameter", Value: 12.5, Unit: "mm"}}
+
 	tcs := map[string]struct {
 		db     getMockDB
 		id     types.UUID
+		units  types.UnitSystem
+		ctx    context.Context
 		result types.Event
 		err    error
 	}{
@@ -165,6 +180,35 @@ func Test_SelectEvent(t *testing.T) {
 			id:     "0",
 			result: types.Event(_events[0]),
 		},
+		"measured": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.set(xformer(eventValues[0]).replace(xform{
+					11: `[{"field": "diameter", "value": 12.5, "unit": "mm"}]`,
+				})))
+				return db
+			},
+			id:     "0",
+			result: measured,
+		},
+		"default_units": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.set(eventValues[2]))
+				return db
+			},
+			id:     "2",
+			units:  types.ImperialUnits,
+			result: imperial,
+		},
+		"requested_units": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.set(eventValues[2]))
+				return db
+			},
+			id:     "2",
+			units:  types.MetricUnits,
+			ctx:    types.WithUnits(context.Background(), types.ImperialUnits),
+			result: imperial,
+		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				newBuilder(mock, eventFields.fail())
@@ -179,11 +223,17 @@ func Test_SelectEvent(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
 			result, err := (&Conn{
 				query:        tc.db(sqlmock.New()),
 				generateUUID: mockUUIDGen,
 				logger:       l.WithField("name", name),
-			}).SelectEvent(context.Background(), tc.id, "Test_SelectEvent")
+				units:        tc.units,
+			}).SelectEvent(ctx, tc.id, "Test_SelectEvent")
 
 			require.Equal(t, tc.err, err)
 			require.Equal(t, tc.result, result)
@@ -196,53 +246,144 @@ func Test_InsertEvent(t *testing.T) {
 
 	l := log.WithField("test", "Test_InsertEvent")
 
+	// whatever the policy, checking a transition starts the same way
+	checked := func(mock sqlmock.Sqlmock, evts ...[]driver.Value) *mocker {
+		return newBuilder(mock, eventFields.set(evts...), etFields.set(etValues[0]))
+	}
+	rip := xformer(eventValues[1]).replace(xform{7: "Sunset", 8: types.RIPSeverity})
+	ripType := types.EventType(_ets[0])
+	ripType.Severity = types.RIPSeverity
+
 	tcs := map[string]struct {
-		db  getMockDB
-		err error
+		db        getMockDB
+		policy    types.TransitionPolicy
+		inserted  bool
+		eventType types.EventType
+		status    types.Status
+		err       error
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock, eventValues...)
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			inserted:  true,
+			eventType: types.EventType(_ets[0]),
+			status:    types.ActiveStatus,
+		},
+		"illegal_transition": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock, eventValues[0], rip)
+				return db
+			},
+			err: types.TransitionError{
+				EventType: types.EventType(_ets[0]),
+				Reason:    "'Sunset' already ended it",
+			},
+		},
+		"lenient_transition": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock, eventValues[0], rip)
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			policy:    types.LenientTransitions,
+			inserted:  true,
+			eventType: types.EventType(_ets[0]),
+			status:    types.ActiveStatus,
+		},
+		// the stored events aren't needed, but the event type still is
+		"ignored_transition": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, etFields.set(xformer(etValues[0]).replace(xform{2: types.RIPSeverity})))
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				return db
 			},
+			policy:    types.IgnoreTransitions,
+			inserted:  true,
+			eventType: ripType,
+			status:    types.DeadStatus,
+		},
+		"select_events_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.fail())
+				return db
+			},
+			err: eventFields.err(),
+		},
+		"select_eventtype_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.set(), etFields.fail())
+				return db
+			},
+			err: fmt.Errorf("couldn't fetch eventtype"),
 		},
 		"insert_event_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock)
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("insert_event_fails"))
+				mock.ExpectRollback()
 				return db
 			},
-			err: fmt.Errorf("insert_event_fails"),
+			inserted: true,
+			err:      fmt.Errorf("insert_event_fails"),
 		},
 		"insert_event_result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock)
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("insert_event_result_fails")))
+				mock.ExpectRollback()
 				return db
 			},
-			err: fmt.Errorf("insert_event_result_fails"),
+			inserted: true,
+			err:      fmt.Errorf("insert_event_result_fails"),
 		},
 		"no_update_event": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock)
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
-			err: fmt.Errorf("event was not added"),
+			inserted: true,
+			err:      fmt.Errorf("event was not added"),
 		},
 		"observable_mtime_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock)
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 				return db
 			},
-			err: fmt.Errorf("some error"),
+			inserted: true,
+			err:      fmt.Errorf("some error"),
 		},
 		"no_update_observable": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				checked(mock)
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
-			err: fmt.Errorf("observable was not changed"),
+			inserted: true,
+			err:      fmt.Errorf("observable was not changed"),
 		},
 	}
 
@@ -255,20 +396,195 @@ func Test_InsertEvent(t *testing.T) {
 				query:        tc.db(sqlmock.New()),
 				generateUUID: mockUUIDGen,
 				logger:       l.WithField("name", name),
+				transitions:  tc.policy,
 			}).InsertEvent(
 				context.Background(),
 				"UUID",
-				types.Event{},
+				types.Event{EventType: types.EventType{UUID: _ets[0].UUID}},
 				"Test_InsertEvent")
 
 			require.Equal(t, tc.err, err)
-			require.NotEmpty(t, evt.UUID)
-			require.NotEmpty(t, evt.MTime)
+			require.Equal(t, tc.inserted, evt.UUID != "")
 			require.Equal(t, evt.MTime, evt.CTime)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tc.eventType, evt.EventType)
+			require.Equal(t, tc.status, types.NewStatus([]types.Event{evt}))
+		})
+	}
+}
+
+func Test_InsertEventMeasurements(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "InsertEventMeasurements")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		ms  []types.Measurement
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, etFields.set(etValues[0]), fieldFields.set(fieldValues...))
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), "diameter", "12.5").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), "color", `"white"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			ms: []types.Measurement{
+				{Field: "color", Value: "white"},
+				{Field: "diameter", Value: "12.5"},
+			},
+		},
+		"invalid_measurement": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, etFields.set(etValues[0]), fieldFields.set(fieldValues...))
+				return db
+			},
+			ms:  []types.Measurement{{Field: "diameter", Value: -1}},
+			err: fmt.Errorf("'diameter' can't be less than 0: '-1'"),
+		},
+		"get_fields_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, etFields.set(etValues[0]), fieldFields.fail())
+				return db
+			},
+			ms:  []types.Measurement{{Field: "diameter", Value: 1}},
+			err: fieldFields.err(),
+		},
+		"measurement_not_added": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, etFields.set(etValues[0]), fieldFields.set(fieldValues...))
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			ms:  []types.Measurement{{Field: "diameter", Value: 1}},
+			err: fmt.Errorf("measurement was not added: 'diameter'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+				transitions:  types.IgnoreTransitions,
+			}).InsertEvent(
+				context.Background(),
+				"UUID",
+				types.Event{EventType: types.EventType{UUID: _ets[0].UUID}, Measurements: tc.ms},
+				"Test_InsertEventMeasurements")
+
+			require.Equal(t, tc.err, err)
 		})
 	}
 }
 
+func Test_InsertEventOccurredAt(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "InsertEventOccurredAt")
+
+	backfilled := wwtbn.Add(-time.Hour)
+
+	tcs := map[string]struct {
+		db         getMockDB
+		occurredAt time.Time
+		recorded   bool
+		err        error
+	}{
+		"defaults_to_now": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			recorded: true,
+		},
+		"backfilled": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				etFields.mock(mock, etValues[0])
+				mock.ExpectBegin()
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "UUID", _ets[0].UUID, backfilled.UTC()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			occurredAt: backfilled,
+		},
+		"in_the_future": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				etFields.mock(mock, etValues[0])
+				return db
+			},
+			occurredAt: someday,
+			err:        fmt.Errorf("event can't have occurred in the future: '2999-01-01T00:00:00Z'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			evt, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+				transitions:  types.IgnoreTransitions,
+			}).InsertEvent(
+				context.Background(),
+				"UUID",
+				types.Event{EventType: types.EventType{UUID: _ets[0].UUID}, OccurredAt: tc.occurredAt},
+				"Test_InsertEventOccurredAt")
+
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tc.recorded, evt.OccurredAt.Equal(evt.CTime))
+		})
+	}
+}
+
+func Test_sortEvents(t *testing.T) {
+	t.Parallel()
+
+	earlier, later := wwtbn.Add(-time.Hour), wwtbn
+	events := sortEvents([]types.Event{
+		{UUID: "recorded first", OccurredAt: earlier, CTime: earlier},
+		{UUID: "latest", OccurredAt: later, CTime: earlier},
+		{UUID: "backfilled", OccurredAt: earlier, CTime: later},
+	})
+
+	uuids := []types.UUID{}
+	for _, e := range events {
+		uuids = append(uuids, e.UUID)
+	}
+	require.Equal(t, []types.UUID{"latest", "backfilled", "recorded first"}, uuids)
+}
+
 func Test_UpdateEvent(t *testing.T) {
 	t.Parallel()
 
@@ -281,48 +597,67 @@ func Test_UpdateEvent(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 				return db
 			},
 		},
 		"update_event_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("update_event_fails"))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("update_event_fails"),
 		},
 		"update_event_result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("update_event_result_fails")))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("update_event_result_fails"),
 		},
 		"no_events_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("event was not changed"),
 		},
 		"update_observable_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("update_observable_fails"))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("update_observable_fails"),
 		},
 		"no_observables_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 				return db
 			},
 			err: fmt.Errorf("observable was not changed"),
 		},
+		"occurred_in_the_future": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			evt: types.Event{OccurredAt: someday},
+			err: fmt.Errorf("event can't have occurred in the future: '2999-01-01T00:00:00Z'"),
+		},
 	}
 
 	for name, tc := range tcs {
@@ -338,7 +673,7 @@ func Test_UpdateEvent(t *testing.T) {
 			}).UpdateEvent(
 				context.Background(),
 				"UUID",
-				types.Event{},
+				tc.evt,
 				"Test_UpdateEvent")
 
 			require.Equal(t, tc.err, err)
@@ -17,7 +17,7 @@ func (db *Conn) AllPhotos(ctx context.Context, cid types.CID) ([]types.Photo, er
 	var rows *sql.Rows
 	result := []types.Photo{}
 
-	rows, err = db.query.QueryContext(ctx, psqls["photo"]["all"])
+	rows, err = db.query.QueryContext(ctx, psqls["photo"]["all"], tagFilter(ctx))
 	if err != nil {
 		return result, err
 	}
@@ -97,6 +97,63 @@ func (db *Conn) GetPhotos(ctx context.Context, id types.UUID, cid types.CID) ([]
 	return result, nil
 }
 
+// GetPhotosFor is GetPhotos for every one of ids with one query; an id that
+// has no photos isn't in the result
+func (db *Conn) GetPhotosFor(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID][]types.Photo, error) {
+	var err error
+	deferred, l := initAccessFuncs("GetPhotosFor", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	var rows *sql.Rows
+	result := make(map[types.UUID][]types.Photo, len(ids))
+
+	rows, err = db.query.QueryContext(ctx, psqls["photo"]["get-for"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id types.UUID
+		var p types.Photo
+		var noteid *types.UUID
+		var notetext *string
+		var notemtime, notectime *time.Time
+
+		if err = rows.Scan(
+			&id,
+			&p.UUID,
+			&p.Filename,
+			&p.MTime,
+			&p.CTime,
+			&noteid,
+			&notetext,
+			&notemtime,
+			&notectime,
+		); err != nil {
+			return result, err
+		}
+
+		if noteid != nil {
+			p.Notes = []types.Note{{
+				UUID:  *noteid,
+				Note:  *notetext,
+				MTime: *notemtime,
+				CTime: *notectime,
+			}}
+		}
+
+		photos := result[id]
+		if curr := len(photos) - 1; curr == -1 || photos[curr].UUID != p.UUID {
+			result[id] = append(photos, p)
+		} else {
+			photos[curr].Notes = append(photos[curr].Notes, p.Notes...)
+		}
+	}
+
+	return result, nil
+}
+
 func (db *Conn) AddPhoto(ctx context.Context, id types.UUID, photos []types.Photo, p types.Photo, cid types.CID) ([]types.Photo, error) {
 	var err error
 	deferred, l := initAccessFuncs("AddPhoto", db.logger, id, cid)
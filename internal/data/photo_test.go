@@ -396,3 +396,53 @@ func Test_RemovePhoto(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetPhotosFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetPhotosFor")
+
+	fields := photoFields.keyed("photoable_uuid")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID][]types.Photo
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(append(
+					keyed("0", photoValues[0], photoValues[1], photoValues[2]),
+					keyed("1", photoValues[3])...)...))
+				return db
+			},
+			result: map[types.UUID][]types.Photo{
+				"0": {types.Photo(_photos[0]), types.Photo(_photos[1])},
+				"1": {types.Photo(_photos[2])},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetPhotosFor(context.Background(), []types.UUID{"0", "1"}, "Test_GetPhotosFor")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) SelectAllProtocols(ctx context.Context, cid types.CID) ([]types.Protocol, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectAllProtocols", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["protocol"]["select-all"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.Protocol, 0, 20)
+	for rows.Next() {
+		row := types.Protocol{}
+		if err = rows.Scan(&row.UUID, &row.Name); err != nil {
+			break
+		}
+		result = append(result, row)
+	}
+
+	return result, err
+}
+
+// SelectProtocol is the only way to get a protocol's steps
+func (db *Conn) SelectProtocol(ctx context.Context, id types.UUID, cid types.CID) (types.Protocol, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectProtocol", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result := types.Protocol{UUID: id}
+	if err = db.
+		QueryRowContext(ctx, psqls["protocol"]["select"], id).
+		Scan(&result.UUID, &result.Name); err != nil {
+		return result, err
+	}
+
+	result.Steps, err = db.getProtocolSteps(ctx, id)
+
+	return result, err
+}
+
+func (db *Conn) getProtocolSteps(ctx context.Context, id types.UUID) ([]types.ProtocolStep, error) {
+	rows, err := db.query.QueryContext(ctx, psqls["protocol"]["steps"], id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []types.ProtocolStep{}
+	for rows.Next() {
+		var s types.ProtocolStep
+		if err = rows.Scan(
+			&s.UUID,
+			&s.Day,
+			&s.Tolerance,
+			&s.EventType.UUID,
+			&s.EventType.Name,
+			&s.EventType.Severity,
+			&s.EventType.Stage.UUID,
+			&s.EventType.Stage.Name,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+func (db *Conn) InsertProtocol(ctx context.Context, p types.Protocol, cid types.CID) (types.Protocol, error) {
+	var err error
+	deferred, l := initAccessFuncs("InsertProtocol", db.logger, p.UUID, cid)
+	defer deferred(&err, l)
+
+	p.UUID = types.UUID(db.generateUUID().String())
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["protocol"]["insert"], p.UUID, p.Name)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.InsertProtocol(ctx, p, cid)
+		}
+		err = pqerr(err)
+		return p, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return p, err
+	} else if rows != 1 {
+		err = fmt.Errorf("protocol was not added")
+	}
+
+	return p, err
+}
+
+func (db *Conn) UpdateProtocol(ctx context.Context, id types.UUID, p types.Protocol, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("UpdateProtocol", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result, err := db.ExecContext(ctx, psqls["protocol"]["update"], p.Name, id)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		return fmt.Errorf("protocol was not updated: '%s'", id)
+	}
+	return nil
+}
+
+// DeleteProtocol takes its steps with it, but not while anything still
+// follows it
+func (db *Conn) DeleteProtocol(ctx context.Context, id types.UUID, cid types.CID) error {
+	return db.deleteByUUID(ctx, id, cid, "DeleteProtocol", "protocol", db.logger)
+}
+
+func (db *Conn) AddProtocolStep(ctx context.Context, p *types.Protocol, s types.ProtocolStep, cid types.CID) (types.ProtocolStep, error) {
+	var err error
+	deferred, l := initAccessFuncs("AddProtocolStep", db.logger, p.UUID, cid)
+	defer deferred(&err, l)
+
+	s.UUID = types.UUID(db.generateUUID().String())
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["protocol"]["add-step"],
+		s.UUID,
+		p.UUID,
+		s.EventType.UUID,
+		s.Day,
+		s.Tolerance,
+	)
+	if err != nil {
+		if isPrimaryKeyViolation(err) {
+			return db.AddProtocolStep(ctx, p, s, cid)
+		}
+		err = pqerr(err)
+		return s, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return s, err
+	} else if rows != 1 {
+		err = fmt.Errorf("protocol step was not added")
+		return s, err
+	}
+
+	p.Steps = append(p.Steps, s)
+
+	return s, err
+}
+
+func (db *Conn) ChangeProtocolStep(ctx context.Context, p *types.Protocol, s types.ProtocolStep, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("ChangeProtocolStep", db.logger, s.UUID, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["protocol"]["change-step"],
+		s.EventType.UUID,
+		s.Day,
+		s.Tolerance,
+		s.UUID,
+		p.UUID,
+	)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("protocol step was not changed: '%s'", s.UUID)
+		return err
+	}
+
+	for i := range p.Steps {
+		if p.Steps[i].UUID == s.UUID {
+			p.Steps[i] = s
+			break
+		}
+	}
+
+	return nil
+}
+
+func (db *Conn) RemoveProtocolStep(ctx context.Context, p *types.Protocol, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveProtocolStep", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["protocol"]["remove-step"], id, p.UUID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("protocol step could not be removed: '%s'", id)
+		return err
+	}
+
+	for i := range p.Steps {
+		if p.Steps[i].UUID == id {
+			p.Steps = append(p.Steps[:i], p.Steps[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// AssignProtocol replaces whatever protocol the observable followed before
+func (db *Conn) AssignProtocol(ctx context.Context, oID types.UUID, pID *types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AssignProtocol", db.logger, oID, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	var result sql.Result
+	if pID == nil {
+		result, err = db.ExecContext(ctx, psqls["protocol"]["unassign"], oID)
+	} else {
+		result, err = db.ExecContext(ctx, psqls["protocol"]["assign"], oID, *pID)
+	}
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 && pID == nil {
+		err = fmt.Errorf("protocol could not be unassigned: '%s'", oID)
+	} else if rows != 1 {
+		err = fmt.Errorf("protocol was not assigned: '%s'", oID)
+	}
+
+	return err
+}
+
+// ObservableProtocol is nil when the observable doesn't follow one
+func (db *Conn) ObservableProtocol(ctx context.Context, oID types.UUID, cid types.CID) (*types.Protocol, error) {
+	var err error
+	deferred, l := initAccessFuncs("ObservableProtocol", db.logger, oID, cid)
+	defer deferred(&err, l)
+
+	pID, _, err := db.assignedProtocol(ctx, oID)
+	if err != nil || pID == nil {
+		return nil, err
+	}
+
+	p, err := db.SelectProtocol(ctx, *pID, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (db *Conn) ProtocolDeviations(ctx context.Context, oID types.UUID, cid types.CID) (types.DeviationReport, error) {
+	var err error
+	deferred, l := initAccessFuncs("ProtocolDeviations", db.logger, oID, cid)
+	defer deferred(&err, l)
+
+	pID, start, err := db.assignedProtocol(ctx, oID)
+	if err != nil {
+		return types.DeviationReport{}, err
+	} else if pID == nil {
+		err = fmt.Errorf("observable doesn't follow a protocol: '%s'", oID)
+		return types.DeviationReport{}, err
+	}
+
+	p, err := db.SelectProtocol(ctx, *pID, cid)
+	if err != nil {
+		return types.DeviationReport{}, err
+	}
+
+	events, err := db.SelectByObservable(ctx, oID, cid)
+	if err != nil {
+		return types.DeviationReport{}, err
+	}
+
+	return types.NewDeviationReport(p, start, events, time.Now().UTC()), nil
+}
+
+func (db *Conn) assignedProtocol(ctx context.Context, oID types.UUID) (pID *types.UUID, start time.Time, err error) {
+	err = db.
+		QueryRowContext(ctx, psqls["protocol"]["assigned"], oID).
+		Scan(&pID, &start)
+	return pID, start, err
+}
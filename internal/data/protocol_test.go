@@ -0,0 +1,675 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_protocols = []types.Protocol{
+		{UUID: "agar to bulk", Name: "Agar to bulk"},
+		{UUID: "lc to bulk", Name: "LC to bulk"},
+	}
+	protocolFields = row{"uuid", "name"}
+	protocolValues = [][]driver.Value{
+		{_protocols[0].UUID, _protocols[0].Name},
+		{_protocols[1].UUID, _protocols[1].Name},
+	}
+
+	// steps for the first two of _events, both on day 0 so they're on time
+	_steps = []types.ProtocolStep{
+		{UUID: "step 0", EventType: _events[0].EventType},
+		{UUID: "step 1", EventType: _events[1].EventType, Tolerance: 2},
+	}
+	stepFields = row{
+		"uuid",
+		"day",
+		"tolerance",
+		"eventtype_uuid",
+		"eventtype_name",
+		"eventtype_severity",
+		"stage_uuid",
+		"stage_name",
+	}
+	stepValues = [][]driver.Value{
+		{_steps[0].UUID, _steps[0].Day, _steps[0].Tolerance, _steps[0].EventType.UUID, _steps[0].EventType.Name, _steps[0].EventType.Severity, _steps[0].EventType.Stage.UUID, _steps[0].EventType.Stage.Name},
+		{_steps[1].UUID, _steps[1].Day, _steps[1].Tolerance, _steps[1].EventType.UUID, _steps[1].EventType.Name, _steps[1].EventType.Severity, _steps[1].EventType.Stage.UUID, _steps[1].EventType.Stage.Name},
+	}
+
+	assignedFields = row{"protocol_uuid", "ctime"}
+)
+
+func Test_SelectAllProtocols(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectAllProtocols")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Protocol
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, protocolFields.set(protocolValues...))
+				return db
+			},
+			result: _protocols,
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, protocolFields.fail())
+				return db
+			},
+			err: protocolFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectAllProtocols(context.Background(), "Test_SelectAllProtocols")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_SelectProtocol(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectProtocol")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		id     types.UUID
+		result types.Protocol
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					protocolFields.set(protocolValues[0]),
+					stepFields.set(stepValues...))
+				return db
+			},
+			id:     _protocols[0].UUID,
+			result: types.Protocol{UUID: _protocols[0].UUID, Name: _protocols[0].Name, Steps: _steps},
+		},
+		"no_steps": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					protocolFields.set(protocolValues[1]),
+					stepFields.set())
+				return db
+			},
+			id:     _protocols[1].UUID,
+			result: types.Protocol{UUID: _protocols[1].UUID, Name: _protocols[1].Name, Steps: []types.ProtocolStep{}},
+		},
+		"no_result": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, protocolFields.set())
+				return db
+			},
+			id:     "missing",
+			result: types.Protocol{UUID: "missing"},
+			err:    sql.ErrNoRows,
+		},
+		"steps_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					protocolFields.set(protocolValues[0]),
+					stepFields.fail())
+				return db
+			},
+			id:     _protocols[0].UUID,
+			result: _protocols[0],
+			err:    stepFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectProtocol(context.Background(), tc.id, "Test_SelectProtocol")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_InsertProtocol(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "InsertProtocol")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.Protocol
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			result: types.Protocol{UUID: "30313233-3435-3637-3839-616263646566", Name: _protocols[0].Name},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			result: types.Protocol{UUID: "30313233-3435-3637-3839-616263646566", Name: _protocols[0].Name},
+			err:    fmt.Errorf("protocol was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			result: types.Protocol{UUID: "30313233-3435-3637-3839-616263646566", Name: _protocols[0].Name},
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).InsertProtocol(context.Background(), types.Protocol{Name: _protocols[0].Name}, "Test_InsertProtocol")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_UpdateProtocol(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "UpdateProtocol")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("protocol was not updated: '%s'", _protocols[0].UUID),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).UpdateProtocol(context.Background(), _protocols[0].UUID, _protocols[0], "Test_UpdateProtocol")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_DeleteProtocol(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "DeleteProtocol")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("protocol could not be deleted: '%s'", _protocols[0].UUID),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).DeleteProtocol(context.Background(), _protocols[0].UUID, "Test_DeleteProtocol")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_AddProtocolStep(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddProtocolStep")
+
+	added := types.ProtocolStep{UUID: "30313233-3435-3637-3839-616263646566", EventType: _events[2].EventType, Day: 10, Tolerance: 2}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.ProtocolStep
+		steps  int
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			result: added,
+			steps:  3,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			result: added,
+			steps:  2,
+			err:    fmt.Errorf("protocol step was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			result: added,
+			steps:  2,
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := types.Protocol{UUID: _protocols[0].UUID, Steps: append([]types.ProtocolStep{}, _steps...)}
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddProtocolStep(context.Background(), &p, types.ProtocolStep{EventType: added.EventType, Day: 10, Tolerance: 2}, "Test_AddProtocolStep")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+			require.Equal(t, tc.steps, len(p.Steps))
+		})
+	}
+}
+
+func Test_ChangeProtocolStep(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ChangeProtocolStep")
+
+	changed := types.ProtocolStep{UUID: _steps[1].UUID, EventType: _steps[1].EventType, Day: 10, Tolerance: 5}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.ProtocolStep
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			result: changed,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			result: _steps[1],
+			err:    fmt.Errorf("protocol step was not changed: '%s'", _steps[1].UUID),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			result: _steps[1],
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := types.Protocol{UUID: _protocols[0].UUID, Steps: append([]types.ProtocolStep{}, _steps...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ChangeProtocolStep(context.Background(), &p, changed, "Test_ChangeProtocolStep")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, p.Steps[1])
+		})
+	}
+}
+
+func Test_RemoveProtocolStep(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveProtocolStep")
+
+	tcs := map[string]struct {
+		db    getMockDB
+		id    types.UUID
+		steps int
+		err   error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			id:    _steps[0].UUID,
+			steps: 1,
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			id:    "missing",
+			steps: 2,
+			err:   fmt.Errorf("protocol step could not be removed: 'missing'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			id:    _steps[0].UUID,
+			steps: 2,
+			err:   fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := types.Protocol{UUID: _protocols[0].UUID, Steps: append([]types.ProtocolStep{}, _steps...)}
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveProtocolStep(context.Background(), &p, tc.id, "Test_RemoveProtocolStep")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.steps, len(p.Steps))
+		})
+	}
+}
+
+func Test_AssignProtocol(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AssignProtocol")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		pID *types.UUID
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			pID: &_protocols[0].UUID,
+		},
+		"unassign": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"nothing_to_unassign": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("protocol could not be unassigned: '%s'", _lc.UUID),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			pID: &_protocols[0].UUID,
+			err: fmt.Errorf("protocol was not assigned: '%s'", _lc.UUID),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			pID: &_protocols[0].UUID,
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AssignProtocol(context.Background(), _lc.UUID, tc.pID, "Test_AssignProtocol")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_ObservableProtocol(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ObservableProtocol")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result *types.Protocol
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					assignedFields.set([]driver.Value{_protocols[0].UUID, wwtbn}),
+					protocolFields.set(protocolValues[0]),
+					stepFields.set(stepValues...))
+				return db
+			},
+			result: &types.Protocol{UUID: _protocols[0].UUID, Name: _protocols[0].Name, Steps: _steps},
+		},
+		"unassigned": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, assignedFields.set([]driver.Value{nil, wwtbn}))
+				return db
+			},
+		},
+		"no_observable": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, assignedFields.set())
+				return db
+			},
+			err: sql.ErrNoRows,
+		},
+		"select_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					assignedFields.set([]driver.Value{_protocols[0].UUID, wwtbn}),
+					protocolFields.fail())
+				return db
+			},
+			err: protocolFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ObservableProtocol(context.Background(), _lc.UUID, "Test_ObservableProtocol")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_ProtocolDeviations(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ProtocolDeviations")
+
+	p := types.Protocol{UUID: _protocols[0].UUID, Name: _protocols[0].Name, Steps: _steps}
+	e0, e1, e2 := types.Event(_events[0]), types.Event(_events[1]), types.Event(_events[2])
+	zero, anchor := 0, wwtbn.Round(0)
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result types.DeviationReport
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					assignedFields.set([]driver.Value{_protocols[0].UUID, wwtbn}),
+					protocolFields.set(protocolValues[0]),
+					stepFields.set(stepValues...),
+					eventFields.set(eventValues...))
+				return db
+			},
+			result: types.DeviationReport{
+				Protocol: p,
+				Anchor:   anchor,
+				Deviations: []types.Deviation{
+					{Kind: types.OnTimeDeviation, Step: &p.Steps[0], Event: &e0, Expected: &anchor, Days: &zero},
+					{Kind: types.OnTimeDeviation, Step: &p.Steps[1], Event: &e1, Expected: &anchor, Days: &zero},
+					{Kind: types.UnexpectedDeviation, Event: &e2},
+				},
+			},
+		},
+		"unassigned": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, assignedFields.set([]driver.Value{nil, wwtbn}))
+				return db
+			},
+			err: fmt.Errorf("observable doesn't follow a protocol: '%s'", _lc.UUID),
+		},
+		"assigned_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, assignedFields.fail())
+				return db
+			},
+			err: assignedFields.err(),
+		},
+		"events_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					assignedFields.set([]driver.Value{_protocols[0].UUID, wwtbn}),
+					protocolFields.set(protocolValues[0]),
+					stepFields.set(stepValues...),
+					eventFields.fail())
+				return db
+			},
+			err: eventFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ProtocolDeviations(context.Background(), _lc.UUID, "Test_ProtocolDeviations")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+// readingChunk keeps each insert's bind parameters (8 per reading) well under
+// postgres' limit of 65535
+const readingChunk = 1000
+
+var resolutions = map[types.Resolution]bool{
+	types.HourResolution: true,
+	types.DayResolution:  true,
+}
+
+// InsertReadings stores a batch readingChunk rows at a time; if a chunk fails
+// the earlier ones stay stored, which is fine since sending them again only
+// overwrites them
+func (db *Conn) InsertReadings(ctx context.Context, rs []types.Reading, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("InsertReadings", db.logger, nil, cid)
+	defer deferred(&err, l)
+
+	// postgres won't update the same row twice in one statement, so the last
+	// duplicate wins the same way it would have across two batches
+	type key struct {
+		sensor string
+		at     int64
+	}
+	seen := make(map[key]int, len(rs))
+	batch := make([]types.Reading, 0, len(rs))
+	for i, r := range rs {
+		if r.Sensor == "" || r.Location == "" || r.At.IsZero() {
+			err = fmt.Errorf("reading %d needs a sensor, a location and a time", i)
+			return err
+		}
+		k := key{r.Sensor, r.At.UnixNano()}
+		if j, ok := seen[k]; ok {
+			batch[j] = r
+			continue
+		}
+		seen[k] = len(batch)
+		batch = append(batch, r)
+	}
+
+	for start := 0; start < len(batch); start += readingChunk {
+		end := start + readingChunk
+		if end > len(batch) {
+			end = len(batch)
+		}
+
+		values := make([]string, 0, end-start)
+		args := make([]any, 0, 8*(end-start))
+		for _, r := range batch[start:end] {
+			n := len(args)
+			values = append(values, fmt.Sprintf(psqls["reading"]["row"],
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8))
+			args = append(args,
+				r.Sensor,
+				r.Location,
+				r.Lifecycle,
+				r.At.UTC(),
+				r.Temperature,
+				r.Humidity,
+				r.CO2,
+				r.Light)
+		}
+
+		if _, err = db.ExecContext(ctx, fmt.Sprintf(psqls["reading"]["insert"], strings.Join(values, ", ")), args...); err != nil {
+			err = pqerr(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *Conn) SelectReadings(ctx context.Context, location string, w types.Window, cid types.CID) ([]types.Reading, error) {
+	var err error
+	deferred, l := initAccessFuncs("SelectReadings", db.logger, location, cid)
+	defer deferred(&err, l)
+
+	result, err := db.selectReadings(ctx, nil, location, w, cid)
+
+	return result, err
+}
+
+func (db *Conn) ReadingRollups(ctx context.Context, location string, res types.Resolution, w types.Window, cid types.CID) ([]types.ReadingRollup, error) {
+	var err error
+	deferred, l := initAccessFuncs("ReadingRollups", db.logger, location, cid)
+	defer deferred(&err, l)
+
+	result, err := db.readingRollups(ctx, nil, location, res, w, cid)
+
+	return result, err
+}
+
+func (db *Conn) LifecycleReadings(ctx context.Context, id types.UUID, cid types.CID) ([]types.Reading, error) {
+	var err error
+	deferred, l := initAccessFuncs("LifecycleReadings", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	lc, err := db.SelectLifecycle(ctx, id, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := db.selectReadings(ctx, &lc.UUID, lc.Location.Name, types.NewLifetime(lc), cid)
+
+	return result, err
+}
+
+func (db *Conn) LifecycleReadingRollups(ctx context.Context, id types.UUID, res types.Resolution, cid types.CID) ([]types.ReadingRollup, error) {
+	var err error
+	deferred, l := initAccessFuncs("LifecycleReadingRollups", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	lc, err := db.SelectLifecycle(ctx, id, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := db.readingRollups(ctx, &lc.UUID, lc.Location.Name, res, types.NewLifetime(lc), cid)
+
+	return result, err
+}
+
+func (db *Conn) StageReadings(ctx context.Context, id types.UUID, cid types.CID) ([]types.StageReadings, error) {
+	var err error
+	deferred, l := initAccessFuncs("StageReadings", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	lc, err := db.SelectLifecycle(ctx, id, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	readings, err := db.selectReadings(ctx, &lc.UUID, lc.Location.Name, types.NewLifetime(lc), cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewStageReadings(types.NewStageSpans(lc.Events), readings), nil
+}
+
+func (db *Conn) selectReadings(ctx context.Context, id *types.UUID, location string, w types.Window, cid types.CID) ([]types.Reading, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectReadings", db.logger, location, cid)
+	defer deferred(&err, l)
+
+	rows, err := db.QueryContext(ctx, psqls["reading"]["select"], id, location, w.From, w.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []types.Reading{}
+	for rows.Next() {
+		var r types.Reading
+		if err = rows.Scan(
+			&r.Sensor,
+			&r.Location,
+			&r.Lifecycle,
+			&r.At,
+			&r.Temperature,
+			&r.Humidity,
+			&r.CO2,
+			&r.Light,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	return result, nil
+}
+
+func (db *Conn) readingRollups(ctx context.Context, id *types.UUID, location string, res types.Resolution, w types.Window, cid types.CID) ([]types.ReadingRollup, error) {
+	var err error
+	deferred, l := initAccessFuncs("readingRollups", db.logger, location, cid)
+	defer deferred(&err, l)
+
+	if !resolutions[res] {
+		err = fmt.Errorf("unknown resolution for reading rollups: '%s'", res)
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, psqls["reading"]["rollup"], id, location, w.From, w.To, res)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []types.ReadingRollup{}
+	for rows.Next() {
+		var r types.ReadingRollup
+		if err = rows.Scan(
+			&r.Bucket,
+			&r.Count,
+			&r.Temperature.Count,
+			&r.Temperature.Min,
+			&r.Temperature.Max,
+			&r.Temperature.Mean,
+			&r.Humidity.Count,
+			&r.Humidity.Min,
+			&r.Humidity.Max,
+			&r.Humidity.Mean,
+			&r.CO2.Count,
+			&r.CO2.Min,
+			&r.CO2.Max,
+			&r.CO2.Mean,
+			&r.Light.Count,
+			&r.Light.Min,
+			&r.Light.Max,
+			&r.Light.Mean,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,431 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	_readings = []types.Reading{
+		{Sensor: "sensor 0", Location: _lc.Location.Name, At: wwtbn.Add(time.Minute), Temperature: f32ptr(21.5), Humidity: f32ptr(90)},
+		{Sensor: "sensor 1", Location: _lc.Location.Name, Lifecycle: &_lc.UUID, At: wwtbn.Add(time.Minute), CO2: f32ptr(800), Light: f32ptr(120)},
+	}
+	readingFields = row{
+		"sensor",
+		"location",
+		"lifecycle_uuid",
+		"read_at",
+		"temperature",
+		"humidity",
+		"co2",
+		"light",
+	}
+	readingValues = [][]driver.Value{
+		{_readings[0].Sensor, _readings[0].Location, nil, _readings[0].At, 21.5, 90, nil, nil},
+		{_readings[1].Sensor, _readings[1].Location, _lc.UUID, _readings[1].At, nil, nil, 800, 120},
+	}
+	rollupFields = row{
+		"bucket",
+		"count",
+		"temperature_count",
+		"temperature_min",
+		"temperature_max",
+		"temperature_avg",
+		"humidity_count",
+		"humidity_min",
+		"humidity_max",
+		"humidity_avg",
+		"co2_count",
+		"co2_min",
+		"co2_max",
+		"co2_avg",
+		"light_count",
+		"light_min",
+		"light_max",
+		"light_avg",
+	}
+	rollupValues = []driver.Value{wwtbn, 2, 1, 21.5, 21.5, 21.5, 1, 90, 90, 90, 1, 800, 800, 800, 1, 120, 120, 120}
+	_rollup      = types.ReadingRollup{
+		Bucket:      wwtbn,
+		Count:       2,
+		Temperature: types.ReadingStats{Count: 1, Min: f32ptr(21.5), Max: f32ptr(21.5), Mean: f32ptr(21.5)},
+		Humidity:    types.ReadingStats{Count: 1, Min: f32ptr(90), Max: f32ptr(90), Mean: f32ptr(90)},
+		CO2:         types.ReadingStats{Count: 1, Min: f32ptr(800), Max: f32ptr(800), Mean: f32ptr(800)},
+		Light:       types.ReadingStats{Count: 1, Min: f32ptr(120), Max: f32ptr(120), Mean: f32ptr(120)},
+	}
+)
+
+func Test_InsertReadings(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "InsertReadings")
+
+	many := make([]types.Reading, readingChunk+1)
+	for i := range many {
+		many[i] = types.Reading{Sensor: "sensor", Location: "location", At: wwtbn.Add(time.Duration(i) * time.Minute)}
+	}
+
+	tcs := map[string]struct {
+		db  getMockDB
+		rs  []types.Reading
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 2))
+				return db
+			},
+			rs: _readings,
+		},
+		"duplicates": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WithArgs(
+						_readings[0].Sensor, _readings[0].Location, nil, _readings[0].At.UTC(), 22.5, nil, nil, nil,
+					).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			rs: []types.Reading{
+				_readings[0],
+				{Sensor: _readings[0].Sensor, Location: _readings[0].Location, At: _readings[0].At, Temperature: f32ptr(22.5)},
+			},
+		},
+		"chunked": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, readingChunk))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			rs: many,
+		},
+		"nothing_to_do": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+		},
+		"no_sensor": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			rs:  []types.Reading{_readings[0], {Location: "location", At: wwtbn}},
+			err: fmt.Errorf("reading 1 needs a sensor, a location and a time"),
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			rs:  _readings,
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			err = (&Conn{
+				query:        tc.db(db, mock, err),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).InsertReadings(context.Background(), tc.rs, "Test_InsertReadings")
+
+			require.Equal(t, tc.err, err)
+			require.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_SelectReadings(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SelectReadings")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Reading
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, readingFields.set(readingValues...))
+				return db
+			},
+			result: _readings,
+		},
+		"no_readings": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, readingFields.set())
+				return db
+			},
+			result: []types.Reading{},
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, readingFields.fail())
+				return db
+			},
+			err: readingFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).SelectReadings(context.Background(), _lc.Location.Name, types.Window{}, "Test_SelectReadings")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_ReadingRollups(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ReadingRollups")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		res    types.Resolution
+		result []types.ReadingRollup
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, rollupFields.set(rollupValues))
+				return db
+			},
+			res:    types.HourResolution,
+			result: []types.ReadingRollup{_rollup},
+		},
+		"nothing_measured": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, rollupFields.set(
+					[]driver.Value{wwtbn, 1, 0, nil, nil, nil, 0, nil, nil, nil, 0, nil, nil, nil, 0, nil, nil, nil}))
+				return db
+			},
+			res:    types.DayResolution,
+			result: []types.ReadingRollup{{Bucket: wwtbn, Count: 1}},
+		},
+		"unknown_resolution": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			res: "fortnight",
+			err: fmt.Errorf("unknown resolution for reading rollups: 'fortnight'"),
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, rollupFields.fail())
+				return db
+			},
+			res: types.HourResolution,
+			err: rollupFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ReadingRollups(context.Background(), _lc.Location.Name, tc.res, types.Window{}, "Test_ReadingRollups")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_LifecycleReadings(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "LifecycleReadings")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.Reading
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					readingFields.set(readingValues...))
+				return db
+			},
+			result: _readings,
+		},
+		"select_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcFields.fail())
+				return db
+			},
+			err: lcFields.err(),
+		},
+		"readings_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					readingFields.fail())
+				return db
+			},
+			err: readingFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).LifecycleReadings(context.Background(), _lc.UUID, "Test_LifecycleReadings")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_LifecycleReadingRollups(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "LifecycleReadingRollups")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.ReadingRollup
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					rollupFields.set(rollupValues))
+				return db
+			},
+			result: []types.ReadingRollup{_rollup},
+		},
+		"select_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcFields.fail())
+				return db
+			},
+			err: lcFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).LifecycleReadingRollups(context.Background(), _lc.UUID, types.HourResolution, "Test_LifecycleReadingRollups")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_StageReadings(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "StageReadings")
+
+	events := make([]types.Event, len(_events))
+	for i, e := range _events {
+		events[i] = types.Event(e)
+	}
+	spans := types.NewStageSpans(events)
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.StageReadings
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					readingFields.set(readingValues...))
+				return db
+			},
+			// every event happened at wwtbn, so only the last span is still open
+			result: []types.StageReadings{
+				{StageSpan: spans[0], Readings: []types.Reading{}},
+				{StageSpan: spans[1], Readings: []types.Reading{}},
+				{StageSpan: spans[2], Readings: _readings},
+			},
+		},
+		"select_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcFields.fail())
+				return db
+			},
+			err: lcFields.err(),
+		},
+		"readings_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					lcFields.set(lcValues),
+					eventFields.set(eventValues...),
+					readingFields.fail())
+				return db
+			},
+			err: readingFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).StageReadings(context.Background(), _lc.UUID, "Test_StageReadings")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -21,51 +21,139 @@ func (db *Conn) GetSources(ctx context.Context, g *types.Generation, cid types.C
 
 	defer rows.Close()
 
-	var lcID *types.UUID
-	var progenitor types.UUID
+	type sourceRow struct {
+		progenitor types.UUID
+		lcID       *types.UUID
+		s          types.Source
+	}
 
+	var found []sourceRow
 	for rows.Next() {
-		row := types.Source{}
+		var row sourceRow
 
 		if err = rows.Scan(
-			&row.UUID,
-			&row.Type,
-			&progenitor,
-			&lcID,
-			&row.Strain.UUID,
-			&row.Strain.Name,
-			&row.Strain.Species,
-			&row.Strain.CTime,
-			&row.Strain.DTime,
-			&row.Strain.Vendor.UUID,
-			&row.Strain.Vendor.Name,
-			&row.Strain.Vendor.Website,
+			&row.s.UUID,
+			&row.s.Type,
+			&row.progenitor,
+			&row.lcID,
+			&row.s.Strain.UUID,
+			&row.s.Strain.Name,
+			&row.s.Strain.Species,
+			&row.s.Strain.CTime,
+			&row.s.Strain.DTime,
+			&row.s.Strain.Vendor.UUID,
+			&row.s.Strain.Vendor.Name,
+			&row.s.Strain.Vendor.Website,
 		); err != nil {
-			break
+			return err
 		}
 
-		if lcID != nil {
+		found = append(found, row)
+	}
+
+	// a transaction only has the one connection, so rows has to be done
+	// before the lifecycles can be queried
+	rows.Close()
+
+	g.Sources = nil
+	for _, row := range found {
+		if row.lcID != nil {
 			var lc types.Lifecycle
-			if lc, err = db.SelectLifecycle(ctx, *lcID, cid); err != nil {
-				break
-			} else {
-				row.Lifecycle = &lc
+			if lc, err = db.SelectLifecycle(ctx, *row.lcID, cid); err != nil {
+				return err
 			}
+			row.s.Lifecycle = &lc
 
-			for _, e := range row.Lifecycle.Events {
-				if e.UUID == progenitor {
-					row.Lifecycle.Events = []types.Event{e}
+			for _, e := range lc.Events {
+				if e.UUID == row.progenitor {
+					row.s.Lifecycle.Events = []types.Event{e}
 					break
 				}
 			}
 		}
 
-		g.Sources = append(g.Sources, row)
+		g.Sources = append(g.Sources, row.s)
 	}
 
 	return err
 }
 
+// sourcesFor is GetSources for every one of ids, by generation, with one
+// query for the sources and one for the lifecycles their events belong to
+func (db *Conn) sourcesFor(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID][]types.Source, error) {
+	type sourceRow struct {
+		generation, progenitor types.UUID
+		lcID                   *types.UUID
+		s                      types.Source
+	}
+
+	rows, err := db.query.QueryContext(ctx, psqls["source"]["get-for"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []sourceRow
+	var lcIDs []types.UUID
+	for rows.Next() {
+		var row sourceRow
+		if err = rows.Scan(
+			&row.generation,
+			&row.s.UUID,
+			&row.s.Type,
+			&row.progenitor,
+			&row.lcID,
+			&row.s.Strain.UUID,
+			&row.s.Strain.Name,
+			&row.s.Strain.Species,
+			&row.s.Strain.CTime,
+			&row.s.Strain.DTime,
+			&row.s.Strain.Vendor.UUID,
+			&row.s.Strain.Vendor.Name,
+			&row.s.Strain.Vendor.Website,
+		); err != nil {
+			return nil, err
+		}
+		if row.lcID != nil {
+			lcIDs = append(lcIDs, *row.lcID)
+		}
+		found = append(found, row)
+	}
+
+	// a transaction only has the one connection, so rows has to be done
+	// before the lifecycles can be queried
+	rows.Close()
+
+	lcs := map[types.UUID]types.Lifecycle{}
+	if len(lcIDs) > 0 {
+		all, err := db.SelectLifecycles(ctx, lcIDs, cid)
+		if err != nil {
+			return nil, err
+		}
+		for _, lc := range all {
+			lcs[lc.UUID] = lc
+		}
+	}
+
+	result := make(map[types.UUID][]types.Source, len(ids))
+	for _, row := range found {
+		if row.lcID != nil {
+			// the same as GetSources, only the progenitor event is kept
+			lc := lcs[*row.lcID]
+			for _, e := range lc.Events {
+				if e.UUID == row.progenitor {
+					lc.Events = []types.Event{e}
+					break
+				}
+			}
+			row.s.Lifecycle = &lc
+		}
+		result[row.generation] = append(result[row.generation], row.s)
+	}
+
+	return result, nil
+}
+
 func (db *Conn) InsertSource(ctx context.Context, genid types.UUID, origin string, s types.Source, cid types.CID) (types.Source, error) {
 	var err error
 	deferred, l := initAccessFuncs("InsertSource", db.logger, genid, cid)
@@ -73,11 +161,13 @@ func (db *Conn) InsertSource(ctx context.Context, genid types.UUID, origin strin
 
 	s.UUID = types.UUID(db.generateUUID().String())
 
-	progenitor := s.Strain.UUID
-	if origin == "event" {
-		progenitor = s.Lifecycle.Events[0].UUID
-	} else if origin != "strain" {
-		return types.Source{}, fmt.Errorf("only origins of type 'strain' and 'event' are allowed: '%s'", origin)
+	var progenitor types.UUID
+	if progenitor, err = progenitorOf(origin, s); err != nil {
+		return types.Source{}, err
+	}
+
+	if err = db.checkSource(ctx, psqls["source"]["siblings"], genid, s.Type, cid); err != nil {
+		return types.Source{}, err
 	}
 
 	var result sql.Result
@@ -105,21 +195,23 @@ func (db *Conn) UpdateSource(ctx context.Context, origin string, s types.Source,
 	deferred, l := initAccessFuncs("UpdateSource", db.logger, nil, cid)
 	defer deferred(&err, l)
 
-	_ = s.Strain.UUID
-	if origin == "event" {
-		_ = s.Lifecycle.Events[0].UUID
-	} else if origin != "strain" {
-		return fmt.Errorf("only origins of type 'strain' and 'event' are allowed: '%s'", origin)
+	var progenitor types.UUID
+	if progenitor, err = progenitorOf(origin, s); err != nil {
+		return err
+	}
+
+	if err = db.checkSource(ctx, psqls["source"]["siblings-of"], s.UUID, s.Type, cid); err != nil {
+		return err
 	}
 
 	var result sql.Result
 
-	result, err = db.ExecContext(ctx, psqls["source"]["change"], s.Type, s.UUID)
+	result, err = db.ExecContext(ctx, psqls["source"]["change"], s.Type, progenitor, s.UUID)
 	if err != nil {
 		return err
 	} else if rows, err := result.RowsAffected(); err != nil {
 		return err
-	} else if rows != 1 { // most likely cause is a bad eventtype.uuid
+	} else if rows != 1 { // most likely cause is a bad source.uuid
 		return fmt.Errorf("source was not changed")
 	}
 
@@ -144,3 +236,69 @@ func (db *Conn) RemoveSource(ctx context.Context, g *types.Generation, id types.
 
 	return err
 }
+
+func (db *Conn) SourceTypes(ctx context.Context, cid types.CID) ([]types.SourceType, error) {
+	var err error
+	deferred, l := initAccessFuncs("SourceTypes", db.logger, types.UUID("nil"), cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["source"]["types"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.SourceType, 0, 10)
+	for rows.Next() {
+		row := types.SourceType{}
+		if err = rows.Scan(&row.Name, &row.Max, &row.Mixable, &row.Clonal); err != nil {
+			return result, err
+		}
+		result = append(result, row)
+	}
+
+	return result, err
+}
+
+// progenitorOf is the strain or the generation event s came from, depending
+// on origin; an event comes from the only one in s.Lifecycle.Events
+func progenitorOf(origin string, s types.Source) (types.UUID, error) {
+	switch origin {
+	case "strain":
+		return s.Strain.UUID, nil
+	case "event":
+		if s.Lifecycle == nil || len(s.Lifecycle.Events) == 0 {
+			return "", fmt.Errorf("an event source needs the event it came from")
+		}
+		return s.Lifecycle.Events[0].UUID, nil
+	}
+	return "", fmt.Errorf("only origins of type 'strain' and 'event' are allowed: '%s'", origin)
+}
+
+// checkSource holds a source of type next up against the source types policy
+// and the other sources in its generation; query finds the others from id,
+// which is either the generation or the source itself. The trigger on
+// sources says the same thing, but not nearly as well
+func (db *Conn) checkSource(ctx context.Context, query string, id types.UUID, next string, cid types.CID) error {
+	policies, err := db.SourceTypes(ctx, cid)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.query.QueryContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	siblings := []string{}
+	for rows.Next() {
+		var sibling string
+		if err = rows.Scan(&sibling); err != nil {
+			return err
+		}
+		siblings = append(siblings, sibling)
+	}
+
+	return types.CheckSource(policies, siblings, next)
+}
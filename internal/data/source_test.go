@@ -22,6 +22,14 @@ var (
 	}
 	srcFields = row{"uuid", "type", "progenitor_uuid", "lifecycle_uuid", "strain_uuid", "strain_name", "&strain_species", "strain_ctime", "strain_dtime", "strain_vendor_id", "strain_vendor_name", "strain_vendor_website"}
 	srcValues = [][]driver.Value{{_src.UUID, _src.Type, "pgid", nil, _src.Strain.UUID, _src.Strain.Name, _src.Strain.Species, _strain.CTime, _strain.DTime, _strain.Vendor.UUID, _strain.Vendor.Name, _strain.Vendor.Website}}
+
+	srcTypeFields = row{"name", "max_sources", "mixable", "clonal"}
+	srcTypeValues = [][]driver.Value{
+		{"Clone", 1, false, true},
+		{"LC", 1, false, true},
+		{"Spore", 2, false, false},
+	}
+	siblingFields = row{"type"}
 )
 
 func Test_GetSources(t *testing.T) {
@@ -30,9 +38,10 @@ func Test_GetSources(t *testing.T) {
 	l := log.WithField("test", "GetSources")
 
 	tcs := map[string]struct {
-		db     getMockDB
-		result []types.Source
-		err    error
+		db      getMockDB
+		sources []types.Source
+		result  []types.Source
+		err     error
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -40,7 +49,7 @@ func Test_GetSources(t *testing.T) {
 					{"uuid", "type", "progenitor_uuid", "lifecycle_uuid", "strain_uuid", "strain_name", "strain_species", wwtbn, nil, "strain_vendor_id", "strain_vendor_name", "strain_vendor_website"},
 					{"uuid", "type", "progenitor_uuid", nil, "strain_uuid", "strain_name", "strain_species", wwtbn, nil, "strain_vendor_id", "strain_vendor_name", "strain_vendor_website"},
 				}...)
-				lcFields.mock(mock, []driver.Value{"uuid", "location", 0, 0, 0, 0, 0, 0, wwtbn, wwtbn, "0", "X.species", "strain 0", nil, wwtbn, nil, "x", "vendor x", "website", "gs", "gs", types.GrainType, "1", "vendor 1", "website", "bs", "bs", types.BulkType, "2", "vendor 2", "website"})
+				lcFields.mock(mock, []driver.Value{"uuid", "location", "location", types.OutdoorBedLocation, 0, nil, nil, nil, nil, nil, 0, 0, 0, 0, 0, 0, wwtbn, wwtbn, "0", "X.species", "strain 0", nil, wwtbn, nil, "x", "vendor x", "website", "gs", "gs", types.GrainType, "1", "vendor 1", "website", "bs", "bs", types.BulkType, "2", "vendor 2", "website"})
 				eventFields.mock(mock, eventValues...)
 				attrFields.mock(mock, attrValues...)
 				ingFields.mock(mock, ingValues...)
@@ -69,6 +78,20 @@ func Test_GetSources(t *testing.T) {
 			},
 			err: fmt.Errorf("some error"),
 		},
+		"refreshed": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				srcFields.mock(mock, []driver.Value{"uuid", "type", "progenitor_uuid", nil, "strain_uuid", "strain_name", "strain_species", wwtbn, nil, "strain_vendor_id", "strain_vendor_name", "strain_vendor_website"})
+				return db
+			},
+			sources: []types.Source{{UUID: "uuid", Type: "type"}, {UUID: "removed", Type: "type"}},
+			result: []types.Source{
+				{
+					UUID:   "uuid",
+					Type:   "type",
+					Strain: types.Strain(_strain),
+				},
+			},
+		},
 	}
 
 	for name, tc := range tcs {
@@ -76,7 +99,7 @@ func Test_GetSources(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			g := types.Generation{}
+			g := types.Generation{Sources: tc.sources}
 
 			err := (&Conn{
 				query:        tc.db(sqlmock.New()),
@@ -97,6 +120,7 @@ func Test_InsertSource(t *testing.T) {
 
 	src := types.Source{
 		UUID:      types.UUID(mockUUIDGen().String()),
+		Type:      "Spore",
 		Lifecycle: &types.Lifecycle{Events: []types.Event{{}}}}
 
 	tcs := map[string]struct {
@@ -108,6 +132,9 @@ func Test_InsertSource(t *testing.T) {
 	}{
 		"happy_event_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
@@ -117,6 +144,9 @@ func Test_InsertSource(t *testing.T) {
 		},
 		"happy_strain_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
@@ -131,28 +161,90 @@ func Test_InsertSource(t *testing.T) {
 			origin: "bad origin",
 			err:    fmt.Errorf("only origins of type 'strain' and 'event' are allowed: 'bad origin'"),
 		},
+		"no_event": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return nil
+			},
+			origin: "event",
+			s:      types.Source{Type: "Spore", Lifecycle: &types.Lifecycle{}},
+			err:    fmt.Errorf("an event source needs the event it came from"),
+		},
+		"too_many_sources": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}, []driver.Value{"Spore"}))
+				return db
+			},
+			origin: "strain",
+			s:      src,
+			err:    types.SourceError{Type: "Spore", Reason: "a generation can only have 2"},
+		},
+		"unknown_type": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set())
+				return db
+			},
+			origin: "strain",
+			s:      types.Source{Type: "Sector"},
+			err:    types.SourceError{Type: "Sector", Reason: "unknown source type"},
+		},
+		"types_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, srcTypeFields.fail())
+				return db
+			},
+			origin: "strain",
+			s:      src,
+			err:    srcTypeFields.err(),
+		},
+		"siblings_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.fail())
+				return db
+			},
+			origin: "strain",
+			s:      src,
+			err:    siblingFields.err(),
+		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
 				return db
 			},
 			origin: "strain",
+			s:      src,
 			err:    fmt.Errorf("source was not added"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
 			origin: "strain",
+			s:      src,
 			err:    fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
 				return db
 			},
 			origin: "strain",
+			s:      src,
 			err:    fmt.Errorf("some error"),
 		},
 	}
@@ -190,21 +282,70 @@ func Test_UpdateSource(t *testing.T) {
 		s      types.Source
 		err    error
 	}{
-		"happy_path": {
+		"happy_strain_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set())
+				mock.ExpectExec("").
+					WithArgs("Spore", "strain 0", "1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			origin: "strain",
+			s:      types.Source{UUID: "1", Type: "Spore", Strain: types.Strain{UUID: "strain 0"}},
+		},
+		"happy_event_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
+				mock.ExpectExec("").
+					WithArgs("Spore", "event 0", "1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			origin: "event",
+			s: types.Source{
+				UUID:      "1",
+				Type:      "Spore",
+				Strain:    types.Strain{UUID: "strain 0"},
+				Lifecycle: &types.Lifecycle{Events: []types.Event{{UUID: "event 0"}}},
+			},
+		},
+		"mixed_types": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set([]driver.Value{"Spore"}))
+				return db
+			},
+			origin: "strain",
+			s:      types.Source{UUID: "1", Type: "LC"},
+			err:    types.SourceError{Type: "LC", Reason: "can't be mixed with 'Spore'"},
+		},
+		"siblings_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.fail())
 				return db
 			},
 			origin: "strain",
 			s:      types.Source{UUID: "1", Type: "Spore"},
+			err:    siblingFields.err(),
 		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set())
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
 				return db
 			},
 			origin: "event",
 			s: types.Source{
+				Type:      "Clone",
 				Lifecycle: &types.Lifecycle{Events: []types.Event{{}}},
 			},
 			err: fmt.Errorf("source was not changed"),
@@ -216,20 +357,36 @@ func Test_UpdateSource(t *testing.T) {
 			origin: "bad origin",
 			err:    fmt.Errorf("only origins of type 'strain' and 'event' are allowed: 'bad origin'"),
 		},
+		"no_lifecycle": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return nil
+			},
+			origin: "event",
+			s:      types.Source{UUID: "1", Type: "Spore"},
+			err:    fmt.Errorf("an event source needs the event it came from"),
+		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set())
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
 			origin: "strain",
+			s:      types.Source{UUID: "1", Type: "Spore"},
 			err:    fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					srcTypeFields.set(srcTypeValues...),
+					siblingFields.set())
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
 				return db
 			},
 			origin: "strain",
+			s:      types.Source{UUID: "1", Type: "Spore"},
 			err:    fmt.Errorf("some error"),
 		},
 	}
@@ -330,3 +487,49 @@ func Test_RemoveSource(t *testing.T) {
 		})
 	}
 }
+
+func Test_SourceTypes(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "SourceTypes")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.SourceType
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, srcTypeFields.set(srcTypeValues...))
+				return db
+			},
+			result: []types.SourceType{
+				{Name: "Clone", Max: 1, Clonal: true},
+				{Name: "LC", Max: 1, Clonal: true},
+				{Name: "Spore", Max: 2},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, srcTypeFields.fail())
+				return db
+			},
+			err: srcTypeFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:  tc.db(sqlmock.New()),
+				logger: l.WithField("name", name),
+			}).SourceTypes(context.Background(), "Test_SourceTypes")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
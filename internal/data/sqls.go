@@ -4,18 +4,228 @@ type sqlMap map[string]map[string]string
 
 var psqls = sqlMap{
 
+	"analytics": {
+		// left joins so observables without any events still count towards totals
+		"lifecycle-events": `
+      select  lc.uuid,
+              loc.uuid as location_uuid,
+              loc.name as location_name,
+              lc.ctime at time zone 'utc',
+              s.uuid as strain_uuid,
+              s.name as strain_name,
+              sv.uuid as strain_vendor_uuid,
+              sv.name as strain_vendor_name,
+              gs.uuid as grain_substrate_uuid,
+              gs.name as grain_substrate_name,
+              bs.uuid as bulk_substrate_uuid,
+              bs.name as bulk_substrate_name,
+              e.uuid as event_uuid,
+              e.temperature,
+              e.humidity,
+              e.occurred_at at time zone 'utc',
+              e.mtime at time zone 'utc',
+              e.ctime at time zone 'utc',
+              et.uuid as eventtype_uuid,
+              et.name as eventtype_name,
+              et.severity,
+              st.uuid as stage_uuid,
+              st.name as stage_name
+        from  lifecycles lc
+        join  locations loc
+          on  lc.location_uuid = loc.uuid
+        join  strains s
+          on  lc.strain_uuid = s.uuid
+        join  vendors sv
+          on  s.vendor_uuid = sv.uuid
+        join  substrates gs
+          on  lc.grainsubstrate_uuid = gs.uuid
+        join  substrates bs
+          on  lc.bulksubstrate_uuid = bs.uuid
+        left
+        join  events e
+          on  lc.uuid = e.observable_uuid
+        left
+        join  event_types et
+          on  e.eventtype_uuid = et.uuid
+        left
+        join  stages st
+          on  et.stage_uuid = st.uuid
+       where  lc.ctime >= coalesce($1::timestamp, '-infinity')
+         and  lc.ctime < coalesce($2::timestamp, 'infinity')
+         and  s.uuid = coalesce($3, s.uuid)
+       order
+          by  lc.ctime, lc.uuid, e.occurred_at`,
+		"generation-events": `
+      select  g.uuid,
+              g.ctime at time zone 'utc',
+              ps.uuid as plating_uuid,
+              ps.name as plating_name,
+              ls.uuid as liquid_uuid,
+              ls.name as liquid_name,
+              e.uuid as event_uuid,
+              e.temperature,
+              e.humidity,
+              e.occurred_at at time zone 'utc',
+              e.mtime at time zone 'utc',
+              e.ctime at time zone 'utc',
+              et.uuid as eventtype_uuid,
+              et.name as eventtype_name,
+              et.severity,
+              st.uuid as stage_uuid,
+              st.name as stage_name
+        from  generations g
+        join  substrates ps
+          on  g.platingsubstrate_uuid = ps.uuid
+        join  substrates ls
+          on  g.liquidsubstrate_uuid = ls.uuid
+        left
+        join  events e
+          on  g.uuid = e.observable_uuid
+        left
+        join  event_types et
+          on  e.eventtype_uuid = et.uuid
+        left
+        join  stages st
+          on  et.stage_uuid = st.uuid
+       where  g.ctime >= coalesce($1::timestamp, '-infinity')
+         and  g.ctime < coalesce($2::timestamp, 'infinity')
+       order
+          by  g.ctime, g.uuid, e.occurred_at`,
+		// text and boolean values can't be summarized, so they never match
+		"lifecycle-measurements": `
+      select  lc.uuid,
+              loc.uuid as location_uuid,
+              loc.name as location_name,
+              lc.ctime at time zone 'utc',
+              s.uuid as strain_uuid,
+              s.name as strain_name,
+              sv.uuid as strain_vendor_uuid,
+              sv.name as strain_vendor_name,
+              gs.uuid as grain_substrate_uuid,
+              gs.name as grain_substrate_name,
+              bs.uuid as bulk_substrate_uuid,
+              bs.name as bulk_substrate_name,
+              (m.value #>> '{}')::float8 as value
+        from  measurements m
+        join  eventtype_fields f
+          on  m.field_uuid = f.uuid
+        join  events e
+          on  m.event_uuid = e.uuid
+        join  lifecycles lc
+          on  e.observable_uuid = lc.uuid
+        join  locations loc
+          on  lc.location_uuid = loc.uuid
+        join  strains s
+          on  lc.strain_uuid = s.uuid
+        join  vendors sv
+          on  s.vendor_uuid = sv.uuid
+        join  substrates gs
+          on  lc.grainsubstrate_uuid = gs.uuid
+        join  substrates bs
+          on  lc.bulksubstrate_uuid = bs.uuid
+       where  f.uuid = $1
+         and  f.type in ('number', 'integer')
+         and  lc.ctime >= coalesce($2::timestamp, '-infinity')
+         and  lc.ctime < coalesce($3::timestamp, 'infinity')
+       order
+          by  lc.ctime, lc.uuid, e.occurred_at`,
+	},
+
+	"batch": {
+		"select-all": `
+      select  uuid,
+              name,
+              ctime at time zone 'utc'
+        from  batches
+       order
+          by  ctime desc, name`,
+		"select": `
+      select  uuid,
+              name,
+              ctime at time zone 'utc'
+        from  batches
+       where  uuid = $1`,
+		"members": `
+      select  lifecycle_uuid,
+              excluded at time zone 'utc'
+        from  batch_members
+       where  batch_uuid = $1`,
+		"insert": `
+      insert
+        into  batches(uuid, name, ctime)
+      values  ($1, $2, $3)`,
+		"update": `
+      update  batches
+         set  name = $1,
+              mtime = current_timestamp
+       where  uuid = $2`,
+		"delete": `delete from batches where uuid = $1`,
+		"add-member": `
+      insert
+        into  batch_members(batch_uuid, lifecycle_uuid)
+      values  ($1, $2)`,
+		"exclude": `
+      update  batch_members
+         set  excluded = $1
+       where  batch_uuid = $2
+         and  lifecycle_uuid = $3
+         and  excluded is null`,
+	},
+
+	"cost": {
+		"select": `
+      select  lc.uuid,
+              loc.uuid as location_uuid,
+              loc.name as location_name,
+              lc.strain_cost,
+              lc.grain_cost,
+              lc.bulk_cost,
+              lc.yield,
+              lc.gross,
+              lc.ctime at time zone 'utc',
+              s.uuid as strain_uuid,
+              s.name as strain_name,
+              sv.uuid as strain_vendor_uuid,
+              sv.name as strain_vendor_name,
+              gs.uuid as grain_substrate_uuid,
+              gs.name as grain_substrate_name,
+              bs.uuid as bulk_substrate_uuid,
+              bs.name as bulk_substrate_name
+        from  lifecycles lc
+        join  locations loc
+          on  lc.location_uuid = loc.uuid
+        join  strains s
+          on  lc.strain_uuid = s.uuid
+        join  vendors sv
+          on  s.vendor_uuid = sv.uuid
+        join  substrates gs
+          on  lc.grainsubstrate_uuid = gs.uuid
+        join  substrates bs
+          on  lc.bulksubstrate_uuid = bs.uuid
+       where  lc.uuid = coalesce($1, lc.uuid)
+         and  lc.ctime >= coalesce($2::timestamp, '-infinity')
+         and  lc.ctime < coalesce($3::timestamp, 'infinity')
+       order
+          by  lc.ctime, lc.uuid`,
+	},
 	"event": {
 		"all-by-observable": `
       select e.uuid,
              e.temperature,
              e.humidity,
+             e.occurred_at at time zone 'utc',
              e.mtime at time zone 'utc',
              e.ctime at time zone 'utc',
              et.uuid as eventtype_uuid,
              et.name as eventtype_name,
              et.severity as eventtype_severity,
              s.uuid as stage_uuid,
-             s.name as stage_name
+             s.name as stage_name,
+             (select  jsonb_agg(jsonb_build_object('field', f.name, 'value', m.value, 'unit', f.unit) order by f.ctime, f.name)
+                from  measurements m
+                join  eventtype_fields f
+                  on  m.field_uuid = f.uuid
+               where  m.event_uuid = e.uuid) as measurements
        from  events e
        join  event_types et
          on  e.eventtype_uuid = et.uuid
@@ -23,24 +233,59 @@ var psqls = sqlMap{
          on  et.stage_uuid = s.uuid
       where  e.observable_uuid = $1
       order
-         by  e.mtime desc`,
+         by  e.occurred_at desc, e.ctime desc`,
+		"all-by-observables": `
+      select e.observable_uuid,
+             e.uuid,
+             e.temperature,
+             e.humidity,
+             e.occurred_at at time zone 'utc',
+             e.mtime at time zone 'utc',
+             e.ctime at time zone 'utc',
+             et.uuid as eventtype_uuid,
+             et.name as eventtype_name,
+             et.severity as eventtype_severity,
+             s.uuid as stage_uuid,
+             s.name as stage_name,
+             (select  jsonb_agg(jsonb_build_object('field', f.name, 'value', m.value, 'unit', f.unit) order by f.ctime, f.name)
+                from  measurements m
+                join  eventtype_fields f
+                  on  m.field_uuid = f.uuid
+               where  m.event_uuid = e.uuid) as measurements
+       from  events e
+       join  event_types et
+         on  e.eventtype_uuid = et.uuid
+       join  stages s
+         on  et.stage_uuid = s.uuid
+      where  e.observable_uuid = any($1)
+      order
+         by  e.observable_uuid, e.occurred_at desc, e.ctime desc`,
 		"all-by-eventtype": `
       select  e.uuid,
               e.temperature,
               e.humidity,
+              e.occurred_at,
               e.mtime,
               e.ctime,
               et.uuid as eventtype_uuid,
               et.name as eventtype_name,
               et.severity as eventtype_severity,
               s.uuid as stage_uuid,
-              s.name as stage_name
+              s.name as stage_name,
+              (select  jsonb_agg(jsonb_build_object('field', f.name, 'value', m.value, 'unit', f.unit) order by f.ctime, f.name)
+                 from  measurements m
+                 join  eventtype_fields f
+                   on  m.field_uuid = f.uuid
+                where  m.event_uuid = e.uuid) as measurements
         from  events e
         join  event_types et
           on  e.eventtype_uuid = et.uuid
         join  stages s
           on  et.stage_uuid = s.uuid
-       where  et.uuid = $1`,
+       where  et.uuid = $1
+         and  $2::varchar[] <@ array(select t.tag from tags t where t.taggable_uuid = e.uuid)
+       order
+          by  e.occurred_at desc, e.ctime desc`,
 		"notes-and-photos": `
       select  e.uuid as event_uuid,
               n.uuid as note_uuid,
@@ -73,13 +318,19 @@ var psqls = sqlMap{
       select e.uuid,
             e.temperature,
             e.humidity,
+            e.occurred_at,
             e.mtime,
             e.ctime,
             et.uuid as eventtype_uuid,
             et.name as eventtype_name,
             et.severity as eventtype_severity,
             s.uuid as stage_uuid,
-            s.name as stage_name
+            s.name as stage_name,
+            (select  jsonb_agg(jsonb_build_object('field', f.name, 'value', m.value, 'unit', f.unit) order by f.ctime, f.name)
+               from  measurements m
+               join  eventtype_fields f
+                 on  m.field_uuid = f.uuid
+              where  m.event_uuid = e.uuid) as measurements
         from events e
         join event_types et
           on e.eventtype_uuid = et.uuid
@@ -88,22 +339,63 @@ var psqls = sqlMap{
       where e.uuid = $1`,
 		"add": `
       insert
-        into  events(uuid, temperature, humidity, mtime, ctime, observable_uuid, eventtype_uuid)
-      select  $1, $2, $3, $4, $5, o.uuid, et.uuid
+        into  events(uuid, temperature, humidity, mtime, ctime, observable_uuid, eventtype_uuid, occurred_at)
+      select  $1, $2, $3, $4, $5, o.uuid, et.uuid, $8
         from  observables o
              ,event_types et
        where  o.uuid = $6
          and  et.uuid = $7`,
+		// the event's measurements are cleared and written again, since they
+		// belong to whatever event type it ends up with; a null occurred_at
+		// leaves it alone
 		"change": `
+        with  cleared as (
+                delete
+                  from  measurements m
+                 using  event_types et
+                 where  m.event_uuid = $4
+                   and  et.uuid = $5
+              )
       update  events e
          set  temperature = $1,
               humidity = $2,
               mtime = $3,
-              eventtype_uuid = et.uuid
+              eventtype_uuid = et.uuid,
+              occurred_at = coalesce($6, e.occurred_at)
         from  event_types et
        where  e.uuid = $4
          and  et.uuid = $5`,
 		"remove": `delete from events where uuid = $1`,
+		// by name, and only for a field of the event's own event type
+		"add-measurement": `
+      insert
+        into  measurements(event_uuid, field_uuid, value)
+      select  e.uuid, f.uuid, $3
+        from  events e
+        join  eventtype_fields f
+          on  e.eventtype_uuid = f.eventtype_uuid
+       where  e.uuid = $1
+         and  f.name = $2`,
+		"lifecycle-severities": `
+      select  e.observable_uuid,
+              et.name,
+              et.severity
+        from  events e
+        join  event_types et
+          on  e.eventtype_uuid = et.uuid
+        join  lifecycles l
+          on  e.observable_uuid = l.uuid
+       where  l.uuid = any($1)`,
+		"generation-severities": `
+      select  e.observable_uuid,
+              et.name,
+              et.severity
+        from  events e
+        join  event_types et
+          on  e.eventtype_uuid = et.uuid
+        join  generations g
+          on  e.observable_uuid = g.uuid
+       where  g.uuid = any($1)`,
 		"observable-mtime": `
       update  observables o
          set  mtime = $1
@@ -154,6 +446,155 @@ var psqls = sqlMap{
 		"delete": `delete from event_types where uuid = $1`,
 	},
 
+	"experiment": {
+		"select-all": `
+      select  uuid,
+              name,
+              variable,
+              ctime at time zone 'utc'
+        from  experiments
+       order
+          by  ctime desc, name`,
+		"select": `
+      select  uuid,
+              name,
+              variable,
+              ctime at time zone 'utc'
+        from  experiments
+       where  uuid = $1`,
+		"arms": `
+      select  uuid,
+              name,
+              control
+        from  experiment_arms
+       where  experiment_uuid = $1
+       order
+          by  control desc, ctime, name`,
+		"lifecycles": `
+      select  lifecycle_uuid,
+              arm_uuid
+        from  experiment_lifecycles
+       where  experiment_uuid = $1`,
+		"insert": `
+      insert
+        into  experiments(uuid, name, variable, ctime)
+      values  ($1, $2, $3, $4)`,
+		"update": `
+      update  experiments
+         set  name = $1,
+              variable = $2,
+              mtime = current_timestamp
+       where  uuid = $3`,
+		"delete": `delete from experiments where uuid = $1`,
+		"add-arm": `
+      insert
+        into  experiment_arms(uuid, experiment_uuid, name, control)
+      values  ($1, $2, $3, $4)`,
+		"remove-arm": `
+      delete
+        from  experiment_arms
+       where  uuid = $1
+         and  experiment_uuid = $2`,
+		"assign": `
+      insert
+        into  experiment_lifecycles(experiment_uuid, arm_uuid, lifecycle_uuid)
+      values  ($1, $2, $3)
+          on  conflict (experiment_uuid, lifecycle_uuid)
+          do  update
+         set  arm_uuid = excluded.arm_uuid`,
+		"unassign": `
+      delete
+        from  experiment_lifecycles
+       where  experiment_uuid = $1
+         and  lifecycle_uuid = $2`,
+	},
+
+	"field": {
+		"get": `
+      select  uuid,
+              name,
+              type,
+              unit,
+              min,
+              max
+        from  eventtype_fields
+       where  eventtype_uuid = $1
+       order
+          by  ctime, name`,
+		"select": `
+      select  uuid,
+              name,
+              type,
+              unit,
+              min,
+              max
+        from  eventtype_fields
+       where  uuid = $1`,
+		"add": `
+    insert
+      into eventtype_fields(uuid, name, type, unit, min, max, eventtype_uuid)
+    values ($1, $2, $3, $4, $5, $6, $7)`,
+		// the type has to match, since values recorded for the old type
+		// wouldn't make sense
+		"change": `
+    update eventtype_fields
+       set name = $1,
+           unit = $2,
+           min = $3,
+           max = $4,
+           mtime = current_timestamp
+     where uuid = $5
+       and eventtype_uuid = $6
+       and type = $7`,
+		"remove": `delete from eventtype_fields where uuid = $1 and eventtype_uuid = $2`,
+	},
+
+	"followup": {
+		"get": `
+      select  f.uuid,
+              f.name,
+              f.days,
+              x.uuid as expects_uuid,
+              x.name as expects_name,
+              x.severity as expects_severity,
+              s.uuid as expects_stage_uuid,
+              s.name as expects_stage_name
+        from  follow_ups f
+        left
+        join  event_types x
+          on  f.expects_uuid = x.uuid
+        left
+        join  stages s
+          on  x.stage_uuid = s.uuid
+       where  f.eventtype_uuid = $1
+       order
+          by  f.days, f.name`,
+		"add": `
+    insert
+      into follow_ups(uuid, name, days, eventtype_uuid, expects_uuid)
+    values ($1, $2, $3, $4, $5)`,
+		"change": `
+    update follow_ups
+       set name = $1,
+           days = $2,
+           expects_uuid = $3,
+           mtime = current_timestamp
+     where uuid = $4
+       and eventtype_uuid = $5`,
+		// open tasks follow the follow-up's new schedule, done ones stay put
+		"reschedule": `
+    update tasks t
+       set due = e.occurred_at + f.days * interval '1 day',
+           mtime = current_timestamp
+      from follow_ups f,
+           events e
+     where f.uuid = $1
+       and t.followup_uuid = f.uuid
+       and t.event_uuid = e.uuid
+       and t.done is null`,
+		"remove": `delete from follow_ups where uuid = $1 and eventtype_uuid = $2`,
+	},
+
 	"generation": {
 		"ndx": `
       select  g.uuid,
@@ -206,6 +647,7 @@ var psqls = sqlMap{
         left
         join  vendors stv
           on  st.vendor_uuid = stv.uuid
+       where  $1::varchar[] <@ array(select t.tag from tags t where t.taggable_uuid = g.uuid)
        order
           by  g.mtime`,
 		// just goes to show you can solve every problem with a union
@@ -275,6 +717,7 @@ var psqls = sqlMap{
          and  ss.strain_uuid = coalesce($2, ss.strain_uuid)
          and  ps.uuid = coalesce($3, ps.uuid)
          and  ls.uuid = coalesce($4, ls.uuid)
+         and  ($6::varchar[] is null or g.uuid = any($6))
        union
       select  distinct
               g.uuid,
@@ -311,7 +754,8 @@ var psqls = sqlMap{
          and  g.uuid = coalesce($1, g.uuid)
          and  $2 is null
          and  ps.uuid = coalesce($3, ps.uuid)
-         and  ls.uuid = coalesce($4, ls.uuid)`,
+         and  ls.uuid = coalesce($4, ls.uuid)
+         and  ($6::varchar[] is null or g.uuid = any($6))`,
 		"insert": `
       insert  into generations(uuid, platingsubstrate_uuid, liquidsubstrate_uuid, mtime, ctime)
       select  $1,
@@ -340,6 +784,62 @@ var psqls = sqlMap{
 		"delete": "update generations set dtime = current_timestamp where uuid = $1",
 	},
 
+	"harvest": {
+		"get": `
+      select  h.uuid,
+              h.harvest_date,
+              h.fresh_weight,
+              h.dry_weight,
+              h.headcount,
+              h.grade,
+              h.mtime,
+              h.ctime,
+              n.uuid as note_uuid,
+              n.note,
+              n.mtime as note_mtime,
+              n.ctime as note_ctime
+        from  harvests h
+        left
+        join  notes n
+          on  n.notable_uuid = h.uuid
+       where  h.lifecycle_uuid = $1
+       order
+          by  h.harvest_date, h.ctime, h.uuid, n.mtime desc`,
+		"add": `
+      insert into harvests(uuid, harvest_date, fresh_weight, dry_weight, headcount, grade, lifecycle_uuid, mtime, ctime)
+      values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		"change": `
+      update  harvests
+         set  harvest_date = $1,
+              fresh_weight = $2,
+              dry_weight = $3,
+              headcount = $4,
+              grade = $5,
+              mtime = $6
+       where  uuid = $7
+         and  lifecycle_uuid = $8`,
+		"remove": `delete from harvests where uuid = $1 and lifecycle_uuid = $2`,
+		// an aggregate with no group by always returns a row, so the last harvest
+		// to be removed zeroes the totals instead of leaving them stale
+		"tally": `
+      update  lifecycles
+         set  yield = h.yield,
+              headcount = h.headcount,
+              gross = h.gross,
+              mtime = $2
+        from  (
+                select  coalesce(sum(case when dry_weight > 0 then dry_weight else fresh_weight end), 0) as yield,
+                        coalesce(sum(headcount), 0) as headcount,
+                        coalesce(sum(fresh_weight), 0) as gross
+                  from  harvests
+                 where  lifecycle_uuid = $1
+              ) h
+       where  lifecycles.uuid = $1
+   returning  lifecycles.yield,
+              lifecycles.headcount,
+              lifecycles.gross`,
+	},
+
 	"ingredient": {
 		"select-all": `select uuid, name from ingredients order by name`,
 		"select":     `select name from ingredients where uuid = $1`,
@@ -353,7 +853,9 @@ var psqls = sqlMap{
 		"index": `
       select distinct 
              l.uuid,
-             l.location,
+             loc.uuid as location_uuid,
+             loc.name as location_name,
+             loc.kind as location_kind,
              l.mtime,
              l.ctime,
              s.uuid as strain_uuid,
@@ -366,6 +868,7 @@ var psqls = sqlMap{
              e.uuid as event_uuid,
              e.temperature,
              e.humidity,
+             e.occurred_at at time zone 'utc',
              e.mtime at time zone 'utc',
              e.ctime at time zone 'utc',
              et.uuid as eventtype_uuid,
@@ -374,6 +877,8 @@ var psqls = sqlMap{
              st.uuid as stage_uuid,
              st.name as stage_name
        from  lifecycles l
+       join  locations loc
+         on  l.location_uuid = loc.uuid
        join  strains s
          on  l.strain_uuid = s.uuid
        join  vendors v 
@@ -388,6 +893,7 @@ var psqls = sqlMap{
        left
        join  stages st
          on  et.stage_uuid = st.uuid
+      where  $1::varchar[] <@ array(select t.tag from tags t where t.taggable_uuid = l.uuid)
       order
          by  l.mtime desc, l.uuid`,
 		"select": `
@@ -406,7 +912,15 @@ var psqls = sqlMap{
          and  $5 is null
       )
       select  lc.uuid,
-              lc.location,
+              loc.uuid as location_uuid,
+              loc.name as location_name,
+              loc.kind as location_kind,
+              loc.capacity as location_capacity,
+              loc.min_temperature,
+              loc.max_temperature,
+              loc.min_humidity,
+              loc.max_humidity,
+              loc.max_co2,
               lc.strain_cost,
               lc.grain_cost,
               lc.bulk_cost,
@@ -440,6 +954,8 @@ var psqls = sqlMap{
         left
         join  event_filter ef
           on  lc.uuid = ef.lifecycle_uuid
+        join  locations loc
+          on  lc.location_uuid = loc.uuid
         join  strains s
           on  lc.strain_uuid = s.uuid
         join  vendors sv
@@ -455,12 +971,22 @@ var psqls = sqlMap{
        where  lc.uuid = coalesce($1, lc.uuid)
          and  s.uuid = coalesce($2, s.uuid)
          and  gs.uuid = coalesce($3, gs.uuid)
-         and  bs.uuid = coalesce($4, bs.uuid)`,
+         and  bs.uuid = coalesce($4, bs.uuid)
+         and  loc.uuid = coalesce($6, loc.uuid)
+         and  ($7::varchar is null or lc.uuid in (
+      select  lifecycle_uuid
+        from  batch_members
+       where  batch_uuid = $7))
+         and  ($8::varchar is null or lc.uuid in (
+      select  lifecycle_uuid
+        from  experiment_lifecycles
+       where  experiment_uuid = $8))
+         and  ($9::varchar[] is null or lc.uuid = any($9))`,
 		"insert": `
       insert
         into lifecycles(
              uuid,
-             location,
+             location_uuid,
              strain_cost,
              grain_cost,
              bulk_cost,
@@ -495,27 +1021,220 @@ var psqls = sqlMap{
         and  bs.type = 'bulk'`,
 		"update": `
       update lifecycles
-        set location = $1,
+        set location_uuid = $1,
             strain_cost = $2,
             grain_cost = $3,
             bulk_cost = $4,
-            yield = $5,
-            headcount = $6,
-            gross = $7,
+            yield = coalesce(h.yield, $5),
+            headcount = coalesce(h.headcount, $6),
+            gross = coalesce(h.gross, $7),
             mtime = $8,
             strain_uuid = s.uuid,
             grainsubstrate_uuid = gs.uuid,
             bulksubstrate_uuid = bs.uuid
         from strains s,
             substrates gs,
-            substrates bs
+            substrates bs,
+            -- once there are harvests, the totals come from them, see "harvest"."tally"
+            (
+              select sum(case when dry_weight > 0 then dry_weight else fresh_weight end) as yield,
+                     sum(headcount) as headcount,
+                     sum(fresh_weight) as gross
+                from harvests
+               where lifecycle_uuid = $12
+            ) h
       where s.uuid = $9
         and gs.uuid = $10
         and gs.type = 'grain'
         and bs.uuid = $11
         and bs.type = 'bulk'
-        and lifecycles.uuid = $12`,
+        and lifecycles.uuid = $12
+  returning lifecycles.yield,
+            lifecycles.headcount,
+            lifecycles.gross`,
 		"delete": `delete from lifecycles where uuid = $1`,
+		"by-strain": `
+      select  strain_uuid,
+              uuid
+        from  lifecycles
+       where  strain_uuid = any($1)
+       order
+          by  ctime,
+              uuid`,
+	},
+
+	"lineage": {
+		"strain": `
+      select  s.uuid,
+              s.name,
+              s.species,
+              v.name as vendor_name
+        from  strains s
+        join  vendors v
+          on  s.vendor_uuid = v.uuid
+       where  s.uuid = $1`,
+		"all-strains": `
+      select  s.uuid,
+              s.name,
+              s.species,
+              v.name as vendor_name
+        from  strains s
+        join  vendors v
+          on  s.vendor_uuid = v.uuid
+       order
+          by  s.ctime, s.uuid`,
+		// every source of the generation that produced $1; a source that's an
+		// event has the lifecycle it happened to in between
+		"ancestors": `
+      select  g.uuid as generation_uuid,
+              s.uuid as source_uuid,
+              s.type as source_type,
+              lc.uuid as lifecycle_uuid,
+              p.uuid as parent_uuid,
+              p.name as parent_name,
+              p.species as parent_species,
+              pv.name as parent_vendor_name,
+              c.uuid as child_uuid,
+              c.name as child_name,
+              c.species as child_species,
+              cv.name as child_vendor_name
+        from  strains c
+        join  vendors cv
+          on  c.vendor_uuid = cv.uuid
+        join  generations g
+          on  c.generation_uuid = g.uuid
+        join  sources s
+          on  g.uuid = s.generation_uuid
+        left
+        join  events e
+          on  s.progenitor_uuid = e.uuid
+        left
+        join  lifecycles lc
+          on  e.observable_uuid = lc.uuid
+        join  strains p
+          on  p.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
+        join  vendors pv
+          on  p.vendor_uuid = pv.uuid
+       where  c.uuid = $1
+       order
+          by  s.ctime, s.uuid`,
+		// every source that came from $1, directly or through one of its
+		// lifecycles; a generation doesn't always have a strain yet
+		"descendants": `
+      select  g.uuid as generation_uuid,
+              s.uuid as source_uuid,
+              s.type as source_type,
+              lc.uuid as lifecycle_uuid,
+              p.uuid as parent_uuid,
+              p.name as parent_name,
+              p.species as parent_species,
+              pv.name as parent_vendor_name,
+              c.uuid as child_uuid,
+              c.name as child_name,
+              c.species as child_species,
+              cv.name as child_vendor_name
+        from  sources s
+        join  generations g
+          on  s.generation_uuid = g.uuid
+        left
+        join  events e
+          on  s.progenitor_uuid = e.uuid
+        left
+        join  lifecycles lc
+          on  e.observable_uuid = lc.uuid
+        join  strains p
+          on  p.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
+        join  vendors pv
+          on  p.vendor_uuid = pv.uuid
+        left
+        join  strains c
+          on  g.uuid = c.generation_uuid
+        left
+        join  vendors cv
+          on  c.vendor_uuid = cv.uuid
+       where  p.uuid = $1
+       order
+          by  s.ctime, s.uuid`,
+		// the same as descendants, for every strain at once
+		"all-sources": `
+      select  g.uuid as generation_uuid,
+              s.uuid as source_uuid,
+              s.type as source_type,
+              lc.uuid as lifecycle_uuid,
+              p.uuid as parent_uuid,
+              p.name as parent_name,
+              p.species as parent_species,
+              pv.name as parent_vendor_name,
+              c.uuid as child_uuid,
+              c.name as child_name,
+              c.species as child_species,
+              cv.name as child_vendor_name
+        from  sources s
+        join  generations g
+          on  s.generation_uuid = g.uuid
+        left
+        join  events e
+          on  s.progenitor_uuid = e.uuid
+        left
+        join  lifecycles lc
+          on  e.observable_uuid = lc.uuid
+        join  strains p
+          on  p.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
+        join  vendors pv
+          on  p.vendor_uuid = pv.uuid
+        left
+        join  strains c
+          on  g.uuid = c.generation_uuid
+        left
+        join  vendors cv
+          on  c.vendor_uuid = cv.uuid
+       order
+          by  s.ctime, s.uuid`,
+	},
+
+	"location": {
+		"select-all": `
+      select  uuid,
+              name,
+              kind,
+              capacity,
+              min_temperature,
+              max_temperature,
+              min_humidity,
+              max_humidity,
+              max_co2
+        from  locations
+       order
+          by  name`,
+		"select": `
+      select  uuid,
+              name,
+              kind,
+              capacity,
+              min_temperature,
+              max_temperature,
+              min_humidity,
+              max_humidity,
+              max_co2
+        from  locations
+       where  uuid = $1`,
+		"insert": `
+      insert
+        into  locations(uuid, name, kind, capacity, min_temperature, max_temperature, min_humidity, max_humidity, max_co2)
+      values  ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		"update": `
+      update  locations
+         set  name = $1,
+              kind = $2,
+              capacity = $3,
+              min_temperature = $4,
+              max_temperature = $5,
+              min_humidity = $6,
+              max_humidity = $7,
+              max_co2 = $8,
+              mtime = current_timestamp
+       where  uuid = $9`,
+		"delete": `delete from locations where uuid = $1`,
 	},
 
 	"note": {
@@ -528,6 +1247,16 @@ var psqls = sqlMap{
        where  notable_uuid = $1
        order
           by  mtime desc`,
+		"get-for": `
+      select  notable_uuid,
+              uuid,
+              note,
+              mtime,
+              ctime
+        from  notes
+       where  notable_uuid = any($1)
+       order
+          by  notable_uuid, mtime desc`,
 		"add": `
       insert into notes(uuid, note, notable_uuid, mtime, ctime)
       values ($1, $2, $3, $4, $4)`,
@@ -574,8 +1303,10 @@ var psqls = sqlMap{
         select  'lifecycle'                   as parent_type
                ,e.uuid                        as owner_uuid
                ,lc.uuid                       as parent_uuid
-              ,lc.location || '->' || et.name as label
+              ,loc.name || '->' || et.name as label
           from  lifecycles lc
+          join  locations loc
+            on  lc.location_uuid = loc.uuid
           join  events e
             on  lc.uuid = e.observable_uuid
           join  event_types et
@@ -617,7 +1348,8 @@ var psqls = sqlMap{
              ,o.label
         from  photos p
         join  owners o
-          on  p.photoable_uuid = o.owner_uuid`,
+          on  p.photoable_uuid = o.owner_uuid
+       where  $1::varchar[] <@ array(select t.tag from tags t where t.taggable_uuid = p.uuid)`,
 		"get": `
       select  p.uuid,
               p.filename,
@@ -634,6 +1366,23 @@ var psqls = sqlMap{
        where  p.photoable_uuid = $1
        order
           by  p.mtime desc, p.uuid, n.mtime desc`,
+		"get-for": `
+      select  p.photoable_uuid,
+              p.uuid,
+              p.filename,
+              p.mtime,
+              p.ctime,
+              n.uuid as note_uuid,
+              n.note,
+              n.mtime as note_mtime,
+              n.ctime as note_ctime
+        from  photos p
+        left
+        join  notes n
+          on  n.notable_uuid = p.uuid
+       where  p.photoable_uuid = any($1)
+       order
+          by  p.photoable_uuid, p.mtime desc, p.uuid, n.mtime desc`,
 		"add": `
       insert into photos(uuid, filename, photoable_uuid, mtime, ctime)
       values ($1, $2, $3, $4, $5)`,
@@ -645,6 +1394,141 @@ var psqls = sqlMap{
 		"remove": `delete from photos where uuid = $1`,
 	},
 
+	"protocol": {
+		"select-all": `
+      select  uuid,
+              name
+        from  protocols
+       order
+          by  name`,
+		"select": `
+      select  uuid,
+              name
+        from  protocols
+       where  uuid = $1`,
+		"steps": `
+      select  ps.uuid,
+              ps.day,
+              ps.tolerance,
+              et.uuid as eventtype_uuid,
+              et.name as eventtype_name,
+              et.severity as eventtype_severity,
+              s.uuid as stage_uuid,
+              s.name as stage_name
+        from  protocol_steps ps
+        join  event_types et
+          on  ps.eventtype_uuid = et.uuid
+        join  stages s
+          on  et.stage_uuid = s.uuid
+       where  ps.protocol_uuid = $1
+       order
+          by  ps.day, et.name`,
+		"insert": `
+      insert
+        into  protocols(uuid, name)
+      values  ($1, $2)`,
+		"update": `
+      update  protocols
+         set  name = $1,
+              mtime = current_timestamp
+       where  uuid = $2`,
+		"delete": `delete from protocols where uuid = $1`,
+		"add-step": `
+      insert
+        into  protocol_steps(uuid, protocol_uuid, eventtype_uuid, day, tolerance)
+      values  ($1, $2, $3, $4, $5)`,
+		"change-step": `
+      update  protocol_steps
+         set  eventtype_uuid = $1,
+              day = $2,
+              tolerance = $3,
+              mtime = current_timestamp
+       where  uuid = $4
+         and  protocol_uuid = $5`,
+		"remove-step": `delete from protocol_steps where uuid = $1 and protocol_uuid = $2`,
+		"assign": `
+      insert
+        into  protocol_assignments(observable_uuid, protocol_uuid)
+      values  ($1, $2)
+          on  conflict (observable_uuid)
+          do  update set protocol_uuid = excluded.protocol_uuid`,
+		"unassign": `delete from protocol_assignments where observable_uuid = $1`,
+		// the observable's ctime is where a protocol starts counting until its
+		// first step has actually happened
+		"assigned": `
+      select  a.protocol_uuid,
+              o.ctime
+        from  observables o
+        left
+        join  protocol_assignments a
+          on  o.uuid = a.observable_uuid
+       where  o.uuid = $1`,
+	},
+
+	"reading": {
+		// the values list is built by InsertReadings, one "row" per reading
+		"insert": `
+      insert into readings(sensor, location, lifecycle_uuid, read_at, temperature, humidity, co2, light)
+      values %s
+          on conflict (sensor, read_at) do update
+         set location = excluded.location,
+             lifecycle_uuid = excluded.lifecycle_uuid,
+             temperature = excluded.temperature,
+             humidity = excluded.humidity,
+             co2 = excluded.co2,
+             light = excluded.light`,
+		// a sensor's location is stored the way the location spells it, see
+		// locations_by_name
+		"row": `
+      ($%[1]d, coalesce((select l.name from locations l where lower(trim(l.name)) = lower(trim($%[2]d))), $%[2]d), $%[3]d, $%[4]d, $%[5]d, $%[6]d, $%[7]d, $%[8]d)`,
+		// $1 is null for a location's readings, otherwise it leaves out sensors
+		// dedicated to other lifecycles at the same location
+		"select": `
+      select  r.sensor,
+              r.location,
+              r.lifecycle_uuid,
+              r.read_at,
+              r.temperature,
+              r.humidity,
+              r.co2,
+              r.light
+        from  readings r
+       where  r.location = (select l.name from locations l where lower(trim(l.name)) = lower(trim($2)))
+         and  ($1::varchar is null or r.lifecycle_uuid is null or r.lifecycle_uuid = $1)
+         and  r.read_at >= coalesce($3::timestamp, '-infinity')
+         and  r.read_at < coalesce($4::timestamp, 'infinity')
+       order
+          by  r.read_at, r.sensor`,
+		"rollup": `
+      select  date_trunc($5, r.read_at) as bucket,
+              count(*),
+              count(r.temperature),
+              min(r.temperature),
+              max(r.temperature),
+              avg(r.temperature),
+              count(r.humidity),
+              min(r.humidity),
+              max(r.humidity),
+              avg(r.humidity),
+              count(r.co2),
+              min(r.co2),
+              max(r.co2),
+              avg(r.co2),
+              count(r.light),
+              min(r.light),
+              max(r.light),
+              avg(r.light)
+        from  readings r
+       where  r.location = (select l.name from locations l where lower(trim(l.name)) = lower(trim($2)))
+         and  ($1::varchar is null or r.lifecycle_uuid is null or r.lifecycle_uuid = $1)
+         and  r.read_at >= coalesce($3::timestamp, '-infinity')
+         and  r.read_at < coalesce($4::timestamp, 'infinity')
+       group
+          by  1
+       order
+          by  1`,
+	},
+
 	"source": {
 		"get": `
       select  s.uuid,
@@ -670,7 +1554,37 @@ var psqls = sqlMap{
           on  st.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
         join  vendors v
           on  st.vendor_uuid = v.uuid
-       where  s.generation_uuid = $1`,
+       where  s.generation_uuid = $1
+       order
+          by  s.ctime, s.uuid`,
+		"get-for": `
+      select  s.generation_uuid,
+              s.uuid,
+              s.type,
+              s.progenitor_uuid,
+              lc.uuid as lifecycle_uuid,
+              st.uuid as strain_uuid,
+              st.name as strain_name,
+              st.species,
+              st.ctime as strain_ctime,
+              st.dtime as strain_dtime,
+              v.uuid as strain_vendor_uuid,
+              v.name as strain_vendor_name,
+              v.website as strain_vendor_website
+        from  sources s
+        left
+        join  events e
+          on  s.progenitor_uuid = e.uuid
+        left
+        join  lifecycles lc
+          on  e.observable_uuid = lc.uuid
+        join  strains st
+          on  st.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
+        join  vendors v
+          on  st.vendor_uuid = v.uuid
+       where  s.generation_uuid = any($1)
+       order
+          by  s.generation_uuid, s.ctime, s.uuid`,
 		"add": `
       insert
         into  sources(uuid, type, progenitor_uuid, generation_uuid)
@@ -678,15 +1592,29 @@ var psqls = sqlMap{
 		"change": `
       update  sources s
          set  type = $1,
-              mtime = current_timestamp
-       where  s.uuid = $2`,
-		"change-new": `
-      update  sources s
-         set  type = $1, 
-              progenitor_uuid = $2
+              progenitor_uuid = $2,
               mtime = current_timestamp
        where  s.uuid = $3`,
 		"delete": `delete from sources where uuid = $1`,
+		"types": `
+      select  name,
+              max_sources,
+              mixable,
+              clonal
+        from  source_types
+       order
+          by  name`,
+		"siblings": `
+      select  type
+        from  sources
+       where  generation_uuid = $1`,
+		"siblings-of": `
+      select  s.type
+        from  sources s
+        join  sources me
+          on  s.generation_uuid = me.generation_uuid
+       where  me.uuid = $1
+         and  s.uuid != me.uuid`,
 		"strain-from-event": `
       select  lc.strain_uuid
         from  lifecycles lc
@@ -713,10 +1641,29 @@ var psqls = sqlMap{
               v.uuid as vendor_uuid,
               v.name as vendor_name,
               v.website as vendor_website,
-              s.generation_uuid
+              s.generation_uuid,
+              s.filial_depth,
+              s.filial_notation,
+              s.root_strain_uuid
         from  strains s
         join  vendors v
           on  s.vendor_uuid = v.uuid
+       where  $1::varchar[] <@ array(select t.tag from tags t where t.taggable_uuid = s.uuid)
+         and  s.filial_depth = coalesce($2, s.filial_depth)
+         and  coalesce(s.root_strain_uuid, s.uuid) = coalesce($3, s.root_strain_uuid, s.uuid)
+         and  not exists (
+                select  1
+                  from  jsonb_array_elements($4::jsonb) f(attr)
+                 where  not exists (
+                          select  1
+                            from  strain_attributes sa
+                            join  attribute_definitions d
+                              on  sa.name = d.name
+                           where  sa.strain_uuid = s.uuid
+                             and  sa.name = f.attr->>'name'
+                             and  (f.attr->'min' is null or (case when d.type = 'number' then sa.value::float8 end) >= (f.attr->>'min')::float8)
+                             and  (f.attr->'max' is null or (case when d.type = 'number' then sa.value::float8 end) <= (f.attr->>'max')::float8)
+                             and  (f.attr->'values' is null or sa.value in (select jsonb_array_elements_text(f.attr->'values')))))
        order
           by  s.name`,
 		"select": `
@@ -728,7 +1675,10 @@ var psqls = sqlMap{
               v.uuid as vendor_uuid,
               v.name as vendor_name,
               v.website as vendor_website,
-              s.generation_uuid
+              s.generation_uuid,
+              s.filial_depth,
+              s.filial_notation,
+              s.root_strain_uuid
         from  strains s
         join  vendors v
           on  s.vendor_uuid = v.uuid
@@ -756,27 +1706,125 @@ var psqls = sqlMap{
               s.dtime,
               v.uuid as vendor_uuid,
               v.name as vendor_name,
-              v.website as vendor_website
+              v.website as vendor_website,
+              s.filial_depth,
+              s.filial_notation,
+              s.root_strain_uuid
         from  strains s 
         join  vendors v
           on  s.vendor_uuid = v.uuid
        where  s.generation_uuid = $1
        order
           by  s.name, s.ctime`,
+		"generated-strains": `
+      select  s.generation_uuid,
+              s.uuid,
+              s.species,
+              s.name,
+              s.ctime,
+              s.dtime,
+              v.uuid as vendor_uuid,
+              v.name as vendor_name,
+              v.website as vendor_website,
+              s.filial_depth,
+              s.filial_notation,
+              s.root_strain_uuid
+        from  strains s
+        join  vendors v
+          on  s.vendor_uuid = v.uuid
+       where  s.generation_uuid = any($1)
+       order
+          by  s.generation_uuid, s.name, s.ctime`,
+		// the strain each source of generation $1 came from, directly or
+		// through the lifecycle whose event was the source
+		"filial-sources": `
+      select  s.type,
+              st.clonal,
+              p.uuid,
+              p.filial_depth,
+              p.filial_notation,
+              p.root_strain_uuid
+        from  sources s
+        join  source_types st
+          on  s.type = st.name
+        left
+        join  events e
+          on  s.progenitor_uuid = e.uuid
+        left
+        join  lifecycles lc
+          on  e.observable_uuid = lc.uuid
+        join  strains p
+          on  p.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
+       where  s.generation_uuid = $1
+       order
+          by  s.ctime, s.uuid`,
 		"update-gen-strain": `
       update  strains
-         set  generation_uuid = $1
-       where  uuid = $2`,
+         set  generation_uuid = $1,
+              filial_depth = $2,
+              filial_notation = $3,
+              root_strain_uuid = $4
+       where  uuid = $5`,
 	},
 
 	"strainattribute": {
-		"get-unique-names": `select distinct name from strain_attributes order by name`,
+		"get-unique-names": `select name from attribute_definitions order by name`,
+		"definitions": `
+      select  d.uuid,
+              d.name,
+              d.type,
+              d.unit,
+              d.allowed,
+              array(select s.synonym from attribute_synonyms s where s.definition_uuid = d.uuid order by s.synonym)
+        from  attribute_definitions d
+       order
+          by  d.name`,
+		"add-definition": `
+    insert
+      into attribute_definitions (uuid, name, type, unit, allowed)
+    values ($1, $2, $3, $4, $5)`,
+		// the type is left alone on purpose, see ChangeAttributeDefinition
+		"change-definition": `
+    update attribute_definitions
+       set name = $1,
+           unit = $2,
+           allowed = $3,
+           mtime = current_timestamp
+     where uuid = $4`,
+		"remove-definition": `delete from attribute_definitions where uuid = $1`,
+		"add-synonym": `
+    insert
+      into attribute_synonyms (synonym, definition_uuid)
+    select $1, d.uuid
+      from attribute_definitions d
+     where d.uuid = $2`,
+		"remove-synonym": `delete from attribute_synonyms where synonym = $1 and definition_uuid = $2`,
+		"values": `
+      select uuid, value, strain_uuid
+        from strain_attributes
+       where name = $1`,
+		"move": `
+    update strain_attributes
+       set name = $1,
+           value = $2,
+           mtime = current_timestamp
+     where uuid = $3`,
+		"move-synonyms": `
+    update attribute_synonyms
+       set definition_uuid = $1
+     where definition_uuid = $2`,
 		"all": `
       select uuid, name, value
         from strain_attributes sa
        where strain_uuid = $1
        order
           by name`,
+		"all-for": `
+      select strain_uuid, uuid, name, value
+        from strain_attributes sa
+       where strain_uuid = any($1)
+       order
+          by strain_uuid, name`,
 		"add": `
     insert
       into strain_attributes (uuid, name, value, strain_uuid)
@@ -799,6 +1847,14 @@ var psqls = sqlMap{
       join substrate_ingredients si
         on i.uuid = si.ingredient_uuid
      where si.substrate_uuid = $1`,
+		"all-for": `
+    select si.substrate_uuid,
+           i.uuid,
+           i.name
+      from ingredients i
+      join substrate_ingredients si
+        on i.uuid = si.ingredient_uuid
+     where si.substrate_uuid = any($1)`,
 		"add": `
     insert
       into substrate_ingredients (uuid, substrate_uuid, ingredient_uuid)
@@ -830,6 +1886,7 @@ var psqls = sqlMap{
         from  substrates s
         join  vendors v
           on  s.vendor_uuid = v.uuid
+       where  $1::varchar[] <@ array(select t.tag from tags t where t.taggable_uuid = s.uuid)
        order
           by  s.name`,
 		"select": `
@@ -861,6 +1918,89 @@ var psqls = sqlMap{
 		"delete": `delete from substrates where uuid = $1`,
 	},
 
+	"tag": {
+		"known": `select distinct tag from tags order by tag`,
+		"get": `
+      select  tag
+        from  tags
+       where  taggable_uuid = $1
+       order
+          by  tag`,
+		"get-for": `
+      select  taggable_uuid,
+              tag
+        from  tags
+       where  taggable_uuid = any($1)
+       order
+          by  taggable_uuid, tag`,
+		// anything that isn't taggable just doesn't get a row
+		"add": `
+      insert
+        into  tags(taggable_uuid, tag)
+      select  t.uuid, $2
+        from  taggables t
+       where  t.uuid = $1`,
+		"remove": `delete from tags where taggable_uuid = $1 and tag = $2`,
+	},
+
+	"task": {
+		// every filter is optional: $1 is one task, $2 is one observable's
+		// tasks and $3 is everything still open and due by then, less the
+		// observables that are dead or finished (any RIP or Fatal event)
+		"select": `
+      select  t.uuid,
+              f.uuid as followup_uuid,
+              f.name as followup_name,
+              f.days,
+              x.uuid as expects_uuid,
+              x.name as expects_name,
+              x.severity as expects_severity,
+              xs.uuid as expects_stage_uuid,
+              xs.name as expects_stage_name,
+              case
+                when lc.uuid is null then 'generation'
+                else 'lifecycle'
+              end as observable_type,
+              t.observable_uuid,
+              t.event_uuid,
+              t.due,
+              t.done,
+              t.result_uuid
+        from  tasks t
+        join  follow_ups f
+          on  t.followup_uuid = f.uuid
+        left
+        join  event_types x
+          on  f.expects_uuid = x.uuid
+        left
+        join  stages xs
+          on  x.stage_uuid = xs.uuid
+        left
+        join  lifecycles lc
+          on  t.observable_uuid = lc.uuid
+       where  t.uuid = coalesce($1, t.uuid)
+         and  t.observable_uuid = coalesce($2, t.observable_uuid)
+         and  ($3::timestamp is null or (
+                t.done is null
+                and t.due <= $3
+                and not exists (
+                  select  1
+                    from  events e
+                    join  event_types et
+                      on  e.eventtype_uuid = et.uuid
+                   where  e.observable_uuid = t.observable_uuid
+                     and  et.severity in ('RIP', 'Fatal'))))
+       order
+          by  t.due, f.name`,
+		"complete": `
+    update tasks
+       set done = $1,
+           result_uuid = $2,
+           mtime = $1
+     where uuid = $3
+       and done is null`,
+	},
+
 	"timestamp": {
 		"touch":    `update %s set mtime = $1 where uuid = $2`,
 		"update":   `update %s set %s where uuid = $1`,
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) LifecycleStages(ctx context.Context, id types.UUID, cid types.CID) ([]types.StageSpan, error) {
+	return db.stageSpans(ctx, "LifecycleStages", id, cid)
+}
+
+func (db *Conn) GenerationStages(ctx context.Context, id types.UUID, cid types.CID) ([]types.StageSpan, error) {
+	return db.stageSpans(ctx, "GenerationStages", id, cid)
+}
+
+// stageSpans is LifecycleStages and GenerationStages, which only differ by the
+// name they log under, since both kinds of observable keep their events alike
+func (db *Conn) stageSpans(ctx context.Context, fn string, id types.UUID, cid types.CID) ([]types.StageSpan, error) {
+	var err error
+	deferred, l := initAccessFuncs(fn, db.logger, id, cid)
+	defer deferred(&err, l)
+
+	events, err := db.selectEventsList(ctx, psqls["event"]["all-by-observable"], id, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewStageSpans(events), nil
+}
+
+// StageDurations only considers lifecycles, since generations don't have a
+// grain, bulk or location to group by; a lifecycle only counts towards a stage
+// once it has moved past it
+func (db *Conn) StageDurations(ctx context.Context, by types.Dimension, w types.Window, cid types.CID) ([]types.StageDurations, error) {
+	var err error
+	deferred, l := initAccessFuncs("StageDurations", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	key, ok := dimensions[by]
+	if !ok {
+		err = fmt.Errorf("unknown dimension for stage durations: '%s'", by)
+		return nil, err
+	}
+
+	lcs, err := db.selectLifecycleEvents(ctx, w, nil, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		types.StageDurations
+		colonization, fruiting []time.Duration
+	}
+
+	groups := make([]*group, 0, len(lcs))
+	index := make(map[string]*group, len(lcs))
+	for _, lc := range lcs {
+		k, label := key(lc)
+		g, ok := index[k]
+		if !ok {
+			g = &group{StageDurations: types.StageDurations{Dimension: by, Key: k, Label: label}}
+			index[k] = g
+			groups = append(groups, g)
+		}
+
+		spans := types.NewStageSpans(lc.Events)
+		if d, ok := types.TimeIn(spans, types.ColonizationStage); ok {
+			g.colonization = append(g.colonization, d)
+		}
+		if d, ok := types.TimeIn(spans, types.MajorityStage); ok {
+			g.fruiting = append(g.fruiting, d)
+		}
+	}
+
+	result := make([]types.StageDurations, len(groups))
+	for i, g := range groups {
+		g.Colonization = types.NewDurationStats(g.colonization)
+		g.Fruiting = types.NewDurationStats(g.fruiting)
+		result[i] = g.StageDurations
+	}
+
+	return result, nil
+}
@@ -0,0 +1,209 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var stageEpoch = time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+func Test_LifecycleStages(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "LifecycleStages")
+
+	end := stageEpoch.AddDate(0, 0, 10)
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.StageSpan
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.set(
+					[]driver.Value{"1", 0.0, 0, end, end, end, "13", "Binning", types.BeginSeverity, "2", types.MajorityStage, nil},
+					[]driver.Value{"0", 0.0, 0, stageEpoch, stageEpoch, stageEpoch, "9", "Innoculation", types.BeginSeverity, "1", types.ColonizationStage, nil},
+				))
+				return db
+			},
+			result: []types.StageSpan{
+				{Stage: types.Stage{UUID: "1", Name: types.ColonizationStage}, Begin: stageEpoch, End: &end},
+				{Stage: types.Stage{UUID: "2", Name: types.MajorityStage}, Begin: end},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.fail())
+				return db
+			},
+			err: eventFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).LifecycleStages(context.Background(), "lc0", "Test_LifecycleStages")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_GenerationStages(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GenerationStages")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.StageSpan
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.set(
+					[]driver.Value{"0", 0.0, 0, stageEpoch, stageEpoch, stageEpoch, "5", "Liquid innoculation", types.BeginSeverity, "0", "Gestation", nil},
+				))
+				return db
+			},
+			result: []types.StageSpan{
+				{Stage: types.Stage{UUID: "0", Name: "Gestation"}, Begin: stageEpoch},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, eventFields.fail())
+				return db
+			},
+			err: eventFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GenerationStages(context.Background(), "g0", "Test_GenerationStages")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_StageDurations(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "StageDurations")
+
+	days := func(ns ...int) []time.Duration {
+		result := make([]time.Duration, len(ns))
+		for i, n := range ns {
+			result[i] = time.Duration(n) * 24 * time.Hour
+		}
+		return result
+	}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		by     types.Dimension
+		result []types.StageDurations
+		err    error
+	}{
+		"by_grain": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.set(append(
+					lcEventRows("lc0", "shelf 0", 10, 20),
+					lcEventRows("lc1", "shelf 1", 14, 30)...)...))
+				return db
+			},
+			by: types.GrainDimension,
+			result: []types.StageDurations{{
+				Dimension:    types.GrainDimension,
+				Key:          "grain 0",
+				Label:        "grain name 0",
+				Colonization: types.NewDurationStats(days(10, 14)),
+				Fruiting:     types.NewDurationStats(days(20, 30)),
+			}},
+		},
+		"by_location": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.set(append(
+					lcEventRows("lc0", "shelf 0", 10, 20),
+					// still colonizing, so it only shows up as an empty group
+					lcEventValues.replace(xform{0: "lc1", 1: "shelf 1", 2: "shelf 1"}))...))
+				return db
+			},
+			by: types.LocationDimension,
+			result: []types.StageDurations{
+				{
+					Dimension:    types.LocationDimension,
+					Key:          "shelf 0",
+					Label:        "shelf 0",
+					Colonization: types.NewDurationStats(days(10)),
+					Fruiting:     types.NewDurationStats(days(20)),
+				},
+				{
+					Dimension: types.LocationDimension,
+					Key:       "shelf 1",
+					Label:     "shelf 1",
+				},
+			},
+		},
+		"unknown_dimension": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			by:  "species",
+			err: fmt.Errorf("unknown dimension for stage durations: 'species'"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, lcEventFields.fail())
+				return db
+			},
+			by:  types.StrainDimension,
+			err: lcEventFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).StageDurations(context.Background(), tc.by, types.Window{}, "Test_StageDurations")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
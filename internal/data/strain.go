@@ -15,7 +15,17 @@ func (db *Conn) SelectAllStrains(ctx context.Context, cid types.CID) ([]types.St
 	deferred, l := initAccessFuncs("SelectAllStrains", db.logger, "nil", cid)
 	defer deferred(&err, l)
 
-	rows, err := db.query.QueryContext(ctx, psqls["strain"]["select-all"])
+	attrs, err := db.attributeFilter(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	filial := types.GetContextFilial(ctx)
+	rows, err := db.query.QueryContext(ctx, psqls["strain"]["select-all"],
+		tagFilter(ctx),
+		filial.Depth,
+		filial.Root,
+		attrs)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +34,7 @@ func (db *Conn) SelectAllStrains(ctx context.Context, cid types.CID) ([]types.St
 	var generationID *types.UUID
 	result := make([]types.Strain, 0, 100)
 	for rows.Next() {
-		row := types.Strain{}
+		row, f := types.Strain{}, types.Filiation{}
 
 		if err = rows.Scan(
 			&row.UUID,
@@ -36,6 +46,9 @@ func (db *Conn) SelectAllStrains(ctx context.Context, cid types.CID) ([]types.St
 			&row.Vendor.Name,
 			&row.Vendor.Website,
 			&generationID,
+			&f.Depth,
+			&f.Notation,
+			&f.Root,
 		); err != nil {
 			break
 		}
@@ -43,6 +56,7 @@ func (db *Conn) SelectAllStrains(ctx context.Context, cid types.CID) ([]types.St
 		if generationID != nil {
 			row.Generation = &types.Generation{UUID: *generationID}
 		}
+		row.Filiation = &f
 
 		result = append(result, row)
 	}
@@ -85,7 +99,7 @@ func (db *Conn) selectStrains(ctx context.Context, p types.ReportAttrs, cid type
 	var generationID *types.UUID
 	result := make([]types.Strain, 0, 100)
 	for rows.Next() {
-		row := types.Strain{}
+		row, f := types.Strain{}, types.Filiation{}
 
 		if err = rows.Scan(
 			&row.UUID,
@@ -97,6 +111,9 @@ func (db *Conn) selectStrains(ctx context.Context, p types.ReportAttrs, cid type
 			&row.Vendor.Name,
 			&row.Vendor.Website,
 			&generationID,
+			&f.Depth,
+			&f.Notation,
+			&f.Root,
 		); err != nil {
 			break
 		}
@@ -104,6 +121,7 @@ func (db *Conn) selectStrains(ctx context.Context, p types.ReportAttrs, cid type
 		if generationID != nil {
 			row.Generation = &types.Generation{UUID: *generationID}
 		}
+		row.Filiation = &f
 
 		err = db.GetAllAttributes(ctx, &row, cid)
 
@@ -165,9 +183,9 @@ func (db *Conn) GeneratedStrain(ctx context.Context, id types.UUID, cid types.CI
 	deferred, l := initAccessFuncs("GeneratedStrains", db.logger, id, cid)
 	defer deferred(&err, l)
 
-	result := types.Strain{}
+	result, f := types.Strain{}, types.Filiation{}
 
-	return result, db.
+	err = db.
 		QueryRowContext(ctx, psqls["strain"]["generated-strain"], id).
 		Scan(
 			&result.UUID,
@@ -178,16 +196,85 @@ func (db *Conn) GeneratedStrain(ctx context.Context, id types.UUID, cid types.CI
 			&result.Vendor.UUID,
 			&result.Vendor.Name,
 			&result.Vendor.Website,
+			&f.Depth,
+			&f.Notation,
+			&f.Root,
 		)
+	if err != nil {
+		return types.Strain{}, err
+	}
+	result.Filiation = &f
+
+	return result, nil
+}
+
+// GeneratedStrains is GeneratedStrain for every one of ids with one query; a
+// generation that hasn't been promoted isn't in the result
+func (db *Conn) GeneratedStrains(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID]types.Strain, error) {
+	var err error
+	deferred, l := initAccessFuncs("GeneratedStrains", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["strain"]["generated-strains"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[types.UUID]types.Strain, len(ids))
+	for rows.Next() {
+		var id types.UUID
+		s, f := types.Strain{}, types.Filiation{}
+		if err = rows.Scan(
+			&id,
+			&s.UUID,
+			&s.Species,
+			&s.Name,
+			&s.CTime,
+			&s.DTime,
+			&s.Vendor.UUID,
+			&s.Vendor.Name,
+			&s.Vendor.Website,
+			&f.Depth,
+			&f.Notation,
+			&f.Root,
+		); err != nil {
+			return nil, err
+		}
+		s.Filiation = &f
+
+		// the same one GeneratedStrain would have picked
+		if _, ok := result[id]; !ok {
+			result[id] = s
+		}
+	}
+
+	return result, nil
 }
 
+// UpdateGeneratedStrain promotes generation gid to strain sid, or demotes
+// sid back to an original when gid is nil; its filiation is derived from
+// gid's sources as they are right now, so strains promoted from sid before
+// this aren't changed
 func (db *Conn) UpdateGeneratedStrain(ctx context.Context, gid *types.UUID, sid types.UUID, cid types.CID) error {
 	var err error
 	deferred, l := initAccessFuncs("UpdateGeneratedStrain", db.logger, sid, cid)
 	defer deferred(&err, l)
 
+	f := types.NewFiliation(nil)
+	if gid != nil {
+		if f, err = db.filiation(ctx, *gid); err != nil {
+			return err
+		}
+	}
+
 	var rows int64
-	result, err := db.ExecContext(ctx, psqls["strain"]["update-gen-strain"], gid, sid)
+	result, err := db.ExecContext(ctx, psqls["strain"]["update-gen-strain"],
+		gid,
+		f.Depth,
+		f.Notation,
+		f.Root,
+		sid)
 	if err != nil {
 		return err
 	} else if rows, err = result.RowsAffected(); err != nil {
@@ -199,6 +286,123 @@ func (db *Conn) UpdateGeneratedStrain(ctx context.Context, gid *types.UUID, sid
 	return nil
 }
 
+// PromoteGeneration adds a strain for gid, links it to gid with its
+// filiation, gives it whatever attributes opts.Inherit says it gets from the
+// strains gid's sources came from, and notes where it came from on gid; all
+// of it happens or none of it does
+func (db *Conn) PromoteGeneration(ctx context.Context, gid types.UUID, opts types.PromoteOptions, cid types.CID) (types.Strain, error) {
+	var err error
+	deferred, l := initAccessFuncs("PromoteGeneration", db.logger, gid, cid)
+	defer deferred(&err, l)
+
+	// the sources, and the parents they name, are read in the transaction
+	// too, so a source that changes meanwhile can't end up in the promotion
+	var result types.Strain
+	err = db.inTx(ctx, func(tx *Conn) error {
+		sources, err := tx.sourcesFor(ctx, []types.UUID{gid}, cid)
+		if err != nil {
+			return err
+		}
+
+		parents, err := tx.parents(ctx, sources[gid], opts.Inherit, cid)
+		if err != nil {
+			return err
+		}
+
+		attrs, err := types.InheritAttributes(parents, opts.Inherit)
+		if err != nil {
+			return err
+		}
+
+		s, err := opts.Strain(parents)
+		if err != nil {
+			return err
+		}
+
+		if existing, err := tx.GeneratedStrain(ctx, gid, cid); err == nil {
+			return fmt.Errorf("generation was already promoted to '%s'", existing.UUID)
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		promoted, err := tx.InsertStrain(ctx, s, cid)
+		if err != nil {
+			return err
+		} else if err = tx.UpdateGeneratedStrain(ctx, &gid, promoted.UUID, cid); err != nil {
+			return err
+		}
+
+		for _, a := range attrs {
+			if _, err = tx.AddAttribute(ctx, &promoted, a, cid); err != nil {
+				return err
+			}
+		}
+
+		if _, err = tx.AddNote(ctx, gid, nil, types.Note{Note: types.PromotionNote(promoted, sources[gid], opts.Note)}, cid); err != nil {
+			return err
+		}
+
+		if result, err = tx.GeneratedStrain(ctx, gid, cid); err != nil {
+			return err
+		}
+		result.Generation = &types.Generation{UUID: gid}
+		result.Attributes = promoted.Attributes
+
+		return nil
+	})
+
+	return result, err
+}
+
+// parents are the distinct strains sources came from, in the same order,
+// with their attributes when inherit needs them
+func (db *Conn) parents(ctx context.Context, sources []types.Source, inherit types.InheritPolicy, cid types.CID) ([]types.Strain, error) {
+	seen := map[types.UUID]bool{}
+	result := make([]types.Strain, 0, len(sources))
+	for _, src := range sources {
+		if seen[src.Strain.UUID] {
+			continue
+		}
+		seen[src.Strain.UUID] = true
+
+		p := src.Strain
+		p.Attributes = nil
+		if inherit != types.InheritNone {
+			if err := db.GetAllAttributes(ctx, &p, cid); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (db *Conn) filiation(ctx context.Context, gid types.UUID) (types.Filiation, error) {
+	rows, err := db.query.QueryContext(ctx, psqls["strain"]["filial-sources"], gid)
+	if err != nil {
+		return types.Filiation{}, err
+	}
+	defer rows.Close()
+
+	sources := []types.FilialSource{}
+	for rows.Next() {
+		var src types.FilialSource
+		if err = rows.Scan(
+			&src.Type,
+			&src.Clonal,
+			&src.Parent,
+			&src.Depth,
+			&src.Notation,
+			&src.Root,
+		); err != nil {
+			return types.Filiation{}, err
+		}
+		sources = append(sources, src)
+	}
+
+	return types.NewFiliation(sources), nil
+}
+
 func (s strain) children(db *Conn, ctx context.Context, cid types.CID, p *rpttree) error {
 	var err error
 	deferred, l := initAccessFuncs("strain::children", db.logger, s.UUID, cid)
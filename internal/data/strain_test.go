@@ -26,6 +26,13 @@ var (
 			Website: "vendorwebsite 0",
 		},
 	}
+	// only the strain queries have a filiation, _strain is shared with
+	// everything that joins to strains
+	_filiation      = types.Filiation{Notation: types.OriginalNotation}
+	_filiatedStrain = func(s types.Strain) types.Strain {
+		s.Filiation = &_filiation
+		return s
+	}(types.Strain(_strain))
 	strainFields = row{
 		"uuid",
 		"species",
@@ -36,6 +43,9 @@ var (
 		"vendor_name",
 		"vendor_website",
 		"generation_uuid",
+		"filial_depth",
+		"filial_notation",
+		"root_strain_uuid",
 	}
 	strainValues = []driver.Value{
 		_strain.UUID,
@@ -47,35 +57,45 @@ var (
 		_strain.Vendor.Name,
 		_strain.Vendor.Website,
 		nil,
+		_filiation.Depth,
+		_filiation.Notation,
+		_filiation.Root,
 	}
+	// generated-strain doesn't need the generation it was asked for
+	generatedFields = append(append(row{}, strainFields[:8]...), strainFields[9:]...)
+	generatedValues = append(append([]driver.Value{}, strainValues[:8]...), strainValues[9:]...)
+	filialFields    = row{"type", "clonal", "uuid", "filial_depth", "filial_notation", "root_strain_uuid"}
 )
 
 func Test_SelectAllStrains(t *testing.T) {
 	t.Parallel()
 
 	whenwillthenbenow := time.Now() // time.Soon()
+	root := types.UUID("0")
 
 	l := log.WithField("test", "Test_SelectAllStrains")
 
 	tcs := map[string]struct {
 		db     getMockDB
 		id     types.UUID
+		filial types.FilialFilter
+		attrs  []types.AttributeFilter
 		result []types.Strain
 		err    error
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				strainFields.mock(mock,
-					[]driver.Value{"0", "X.species", "strain 0", whenwillthenbenow, nil, "0", "vendor 0", "website", nil},
-					[]driver.Value{"1", "X.species", "strain 1", whenwillthenbenow, nil, "1", "vendor 1", "website", nil},
-					[]driver.Value{"2", "X.species", "strain 2", whenwillthenbenow, nil, "1", "vendor 1", "website", "0"})
+					[]driver.Value{"0", "X.species", "strain 0", whenwillthenbenow, nil, "0", "vendor 0", "website", nil, 0, "P", nil},
+					[]driver.Value{"1", "X.species", "strain 1", whenwillthenbenow, nil, "1", "vendor 1", "website", nil, 0, "P", nil},
+					[]driver.Value{"2", "X.species", "strain 2", whenwillthenbenow, nil, "1", "vendor 1", "website", "0", 1, "F1", "0"})
 
 				return db
 			},
 			result: []types.Strain{
-				{UUID: "0", Species: "X.species", Name: "strain 0", CTime: whenwillthenbenow, Vendor: types.Vendor{UUID: "0", Name: "vendor 0", Website: "website"}, Attributes: nil},
-				{UUID: "1", Species: "X.species", Name: "strain 1", CTime: whenwillthenbenow, Vendor: types.Vendor{UUID: "1", Name: "vendor 1", Website: "website"}, Attributes: nil},
-				{UUID: "2", Species: "X.species", Name: "strain 2", CTime: whenwillthenbenow, Vendor: types.Vendor{UUID: "1", Name: "vendor 1", Website: "website"}, Attributes: nil, Generation: &types.Generation{UUID: "0"}},
+				{UUID: "0", Species: "X.species", Name: "strain 0", CTime: whenwillthenbenow, Vendor: types.Vendor{UUID: "0", Name: "vendor 0", Website: "website"}, Filiation: &types.Filiation{Notation: "P"}, Attributes: nil},
+				{UUID: "1", Species: "X.species", Name: "strain 1", CTime: whenwillthenbenow, Vendor: types.Vendor{UUID: "1", Name: "vendor 1", Website: "website"}, Filiation: &types.Filiation{Notation: "P"}, Attributes: nil},
+				{UUID: "2", Species: "X.species", Name: "strain 2", CTime: whenwillthenbenow, Vendor: types.Vendor{UUID: "1", Name: "vendor 1", Website: "website"}, Filiation: &types.Filiation{Depth: 1, Notation: "F1", Root: &root}, Attributes: nil, Generation: &types.Generation{UUID: "0"}},
 			},
 		},
 		// "scan_error": {
@@ -90,6 +110,46 @@ func Test_SelectAllStrains(t *testing.T) {
 		// 	},
 		// 	err: fmt.Errorf("some error"),
 		// },
+		"filtered": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").
+					WithArgs(sqlmock.AnyArg(), 1, "0", "[]").
+					WillReturnRows(sqlmock.NewRows(strainFields))
+				return db
+			},
+			filial: types.FilialFilter{Depth: func(i int) *int { return &i }(1), Root: &root},
+			result: []types.Strain{},
+		},
+		"attribute_filtered": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectQuery("").
+					WithArgs(sqlmock.AnyArg(), nil, nil, `[{"name":"headroom","min":20},{"name":"color","values":["purple"]}]`).
+					WillReturnRows(sqlmock.NewRows(strainFields))
+				return db
+			},
+			attrs: []types.AttributeFilter{
+				{Name: "headroom", Min: func(f float64) *float64 { return &f }(20)},
+				{Name: "colour", Values: []string{"Purple"}},
+			},
+			result: []types.Strain{},
+		},
+		"unknown_attribute": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			attrs: []types.AttributeFilter{{Name: "vigor"}},
+			err:   fmt.Errorf("unknown attribute: 'vigor'"),
+		},
+		"definitions_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.fail())
+				return db
+			},
+			attrs: []types.AttributeFilter{{Name: "headroom"}},
+			err:   defFields.err(),
+		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
 				newBuilder(mock, strainFields.fail())
@@ -108,7 +168,9 @@ func Test_SelectAllStrains(t *testing.T) {
 				query:        tc.db(sqlmock.New()),
 				generateUUID: mockUUIDGen,
 				logger:       l.WithField("name", name),
-			}).SelectAllStrains(context.Background(), "Test_SelectAllStrains")
+			}).SelectAllStrains(
+				types.WithAttributes(types.WithFilial(context.Background(), tc.filial), tc.attrs...),
+				"Test_SelectAllStrains")
 
 			require.Equal(t, tc.err, err)
 			require.Equal(t, tc.result, result)
@@ -159,7 +221,8 @@ func Test_SelectStrain(t *testing.T) {
 						Value: "attrvalue 2",
 					},
 				},
-				CTime: wwtbn,
+				Filiation: &_filiation,
+				CTime:     wwtbn,
 			},
 		},
 		"no_results_found": {
@@ -399,11 +462,11 @@ func Test_GeneratedStrain(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				strainFields[0:8].mock(mock, strainValues[0:8])
+				generatedFields.mock(mock, generatedValues)
 				return db
 			},
 			id:     "0",
-			result: types.Strain(_strain),
+			result: _filiatedStrain,
 		},
 		"no_results_found": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -445,45 +508,66 @@ func Test_UpdateGeneratedStrain(t *testing.T) {
 
 	l := log.WithField("test", "UpdateGeneratedStrain")
 
+	root := types.UUID("p0")
+
 	tcs := map[string]struct {
-		db getMockDB
-		gid,
-		sid types.UUID
+		db  getMockDB
+		gid *types.UUID
 		err error
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
-				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, filialFields.set(
+					[]driver.Value{"Clone", true, "p1", 0, "P", nil},
+					[]driver.Value{"Spore", false, "p2", 1, "clone of P", "p0"}))
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), 2, "F1", &root, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
-			gid: "0",
-			sid: "0",
+			gid: func(id types.UUID) *types.UUID { return &id }("0"),
+		},
+		"demoted": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WithArgs(nil, 0, types.OriginalNotation, nil, "0").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"sources_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, filialFields.fail())
+				return db
+			},
+			gid: func(id types.UUID) *types.UUID { return &id }("0"),
+			err: filialFields.err(),
 		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, filialFields.set())
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
 				return db
 			},
-			gid: "0",
-			sid: "0",
+			gid: func(id types.UUID) *types.UUID { return &id }("0"),
 			err: sql.ErrNoRows,
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, filialFields.set())
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
-			gid: "0",
-			sid: "0",
+			gid: func(id types.UUID) *types.UUID { return &id }("0"),
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, filialFields.set())
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
 				return db
 			},
-			gid: "0",
-			sid: "0",
+			gid: func(id types.UUID) *types.UUID { return &id }("0"),
 			err: fmt.Errorf("some error"),
 		},
 	}
@@ -499,8 +583,8 @@ func Test_UpdateGeneratedStrain(t *testing.T) {
 				logger:       l.WithField("name", name),
 			}).UpdateGeneratedStrain(
 				context.Background(),
-				&tc.gid,
-				tc.sid,
+				tc.gid,
+				"0",
 				"Test_UpdateStrains")
 
 			require.Equal(t, tc.err, err)
@@ -508,6 +592,225 @@ func Test_UpdateGeneratedStrain(t *testing.T) {
 	}
 }
 
+func Test_PromoteGeneration(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "PromoteGeneration")
+
+	id := types.UUID(mockUUIDGen().String())
+
+	srcFor := srcFields.keyed("generation_uuid")
+	// everything happens in the one transaction, starting with the sources
+	sources := func(mock *mocker) *mocker {
+		mock.ExpectBegin()
+		return mock.add(srcFor.set(keyed("gen 0",
+			[]driver.Value{"src 0", "Spore", "p0", nil, "p0", "parent 0", "X.species", wwtbn, nil, "v0", "vendor 0", ""},
+			[]driver.Value{"src 1", "Spore", "p1", nil, "p1", "parent 1", "X.species", wwtbn, nil, "v0", "vendor 0", ""})...))
+	}
+	parents := func(mock *mocker) *mocker {
+		return mock.add(
+			attrFields.set([]driver.Value{"a0", "potency", "high"}, []driver.Value{"a1", "color", "gold"}),
+			attrFields.set([]driver.Value{"a2", "potency", "low"}))
+	}
+	definitions := defFields.set(
+		[]driver.Value{"color", "color", "text", "", "{}", "{}"},
+		[]driver.Value{"potency", "potency", "enum", "", "{low,high}", "{}"})
+	promoted := func(mock *mocker) *mocker {
+		mock.add(generatedFields.set())
+		mock.ExpectExec("").
+			WithArgs(id, "X.species", "ghost", sqlmock.AnyArg(), types.SelfVendor).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.add(filialFields.set(
+			[]driver.Value{"Spore", false, "p0", 0, "P", nil},
+			[]driver.Value{"Spore", false, "p1", 0, "P", nil}))
+		mock.ExpectExec("").
+			WithArgs("gen 0", 1, "F1", sqlmock.AnyArg(), id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		return mock
+	}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		opts   types.PromoteOptions
+		result types.Strain
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, parents, promoted, definitions)
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), "color", "gold", id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, definitions)
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), "potency", "high", id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), "promoted to strain 'ghost' from Spore of 'parent 0', Spore of 'parent 1'\nkeeper", "gen 0", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, generatedFields.set(generatedValues))
+				mock.ExpectCommit()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost", Inherit: types.InheritFirst, Note: "keeper"},
+			result: func(s types.Strain) types.Strain {
+				s.Generation = &types.Generation{UUID: "gen 0"}
+				s.Attributes = []types.StrainAttribute{
+					{UUID: id, Name: "color", Value: "gold"},
+					{UUID: id, Name: "potency", Value: "high"},
+				}
+				return s
+			}(_filiatedStrain),
+		},
+		"nothing_inherited": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, promoted)
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, generatedFields.set(generatedValues))
+				mock.ExpectCommit()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			result: func(s types.Strain) types.Strain {
+				s.Generation = &types.Generation{UUID: "gen 0"}
+				return s
+			}(_filiatedStrain),
+		},
+		"already_promoted": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources)
+				newBuilder(mock, generatedFields.set(generatedValues))
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  fmt.Errorf("generation was already promoted to 'strainuuid 0'"),
+		},
+		"conflicting_attributes": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, parents)
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost", Inherit: types.InheritStrict},
+			err:  fmt.Errorf("parents disagree about attribute 'potency': 'high' and 'low'"),
+		},
+		"no_name": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources)
+				mock.ExpectRollback()
+				return db
+			},
+			err: fmt.Errorf("a promoted strain needs a name"),
+		},
+		"sources_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectBegin()
+				newBuilder(mock, srcFor.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  srcFor.err(),
+		},
+		"attributes_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, attrFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost", Inherit: types.InheritAgreed},
+			err:  attrFields.err(),
+		},
+		"check_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources)
+				newBuilder(mock, generatedFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  generatedFields.err(),
+		},
+		"insert_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources)
+				newBuilder(mock, generatedFields.set())
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  fmt.Errorf("some error"),
+		},
+		"link_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources)
+				newBuilder(mock, generatedFields.set())
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, filialFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  filialFields.err(),
+		},
+		"attribute_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, parents, promoted, definitions)
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost", Inherit: types.InheritAgreed},
+			err:  fmt.Errorf("some error"),
+		},
+		"note_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, promoted)
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  fmt.Errorf("some error"),
+		},
+		"reload_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, sources, promoted)
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				newBuilder(mock, generatedFields.fail())
+				mock.ExpectRollback()
+				return db
+			},
+			opts: types.PromoteOptions{Name: "ghost"},
+			err:  generatedFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			result, err := (&Conn{
+				query:        tc.db(db, mock, err),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).PromoteGeneration(
+				context.Background(),
+				"gen 0",
+				tc.opts,
+				"Test_PromoteGeneration")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+			require.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func Test_StrainReport(t *testing.T) {
 	t.Parallel()
 
@@ -534,7 +837,7 @@ func Test_StrainReport(t *testing.T) {
 				s["attributes"] = attributes
 				s["photos"] = album
 				return s
-			}(mustEntity(_strain)),
+			}(mustEntity(_filiatedStrain)),
 		},
 		"photos_report_error": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -570,7 +873,7 @@ func Test_StrainReport(t *testing.T) {
 			result: func(s types.Entity) types.Entity {
 				s["attributes"] = attributes
 
-				lc := mustEntity(_lc)
+				lc := lcEntity(types.ActiveStatus)
 				lc["strain"].(map[string]interface{})["attributes"] = attributes
 				lc["grain_substrate"].(map[string]interface{})["ingredients"] = ingredients
 				lc["bulk_substrate"].(map[string]interface{})["ingredients"] = ingredients
@@ -580,7 +883,7 @@ func Test_StrainReport(t *testing.T) {
 				s["lifecycles"] = []types.Entity{lc}
 
 				return s
-			}(mustEntity(_strain)),
+			}(mustEntity(_filiatedStrain)),
 		},
 		"lifecycles_report_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -613,7 +916,7 @@ func Test_StrainReport(t *testing.T) {
 				return db
 			},
 			result: func(s types.Entity) types.Entity {
-				gen := mustEntity(_gen)
+				gen := genEntity(types.ActiveStatus)
 
 				gen["plating_substrate"].(map[string]interface{})["ingredients"] = ingredients
 				gen["liquid_substrate"].(map[string]interface{})["ingredients"] = ingredients
@@ -643,7 +946,7 @@ func Test_StrainReport(t *testing.T) {
 				s["attributes"] = attributes
 
 				return s
-			}(mustEntity(_strain)),
+			}(mustEntity(_filiatedStrain)),
 		},
 		"generation_report_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -679,7 +982,7 @@ func Test_StrainReport(t *testing.T) {
 			result: func(s types.Entity) types.Entity {
 				s["attributes"] = attributes
 
-				s["generation"] = mustEntity(_gen)
+				s["generation"] = genEntity(types.ActiveStatus)
 
 				gen := s["generation"].(types.Entity)
 
@@ -709,7 +1012,7 @@ func Test_StrainReport(t *testing.T) {
 				}
 
 				return s
-			}(mustEntity(_strain)),
+			}(mustEntity(_filiatedStrain)),
 		},
 		"missing_progen_id": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
@@ -764,7 +1067,7 @@ func Test_StrainReport(t *testing.T) {
 				return db
 			},
 			result: func() types.Entity {
-				s := mustEntity(_strain)
+				s := mustEntity(_filiatedStrain)
 				s["attributes"] = attributes
 				return s
 			}(),
@@ -804,3 +1107,50 @@ func Test_StrainReport(t *testing.T) {
 		})
 	}
 }
+
+func Test_GeneratedStrains(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GeneratedStrains")
+
+	fields := generatedFields.keyed("generation_uuid")
+	other := xformer(generatedValues).replace(xform{0: "other"})
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID]types.Strain
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(keyed("0", generatedValues, other)...))
+				return db
+			},
+			// only the first strain a generation was promoted to counts
+			result: map[types.UUID]types.Strain{"0": _filiatedStrain},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GeneratedStrains(context.Background(), []types.UUID{"0", "1"}, "Test_GeneratedStrains")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -2,11 +2,23 @@ package data
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+
+	pq "github.com/lib/pq"
 
 	"github.com/jsmit257/huautla/types"
 )
 
+// attributeValue is one strain's value for an attribute, without the strain
+type attributeValue struct {
+	types.StrainAttribute
+	strain types.UUID
+}
+
+// KnownAttributeNames is every attribute that has a definition, not just the
+// ones some strain has; synonyms aren't included, see AttributeDefinitions
 func (db *Conn) KnownAttributeNames(ctx context.Context, cid types.CID) ([]string, error) {
 	var err error
 	deferred, l := initAccessFuncs("KnownAttributeNames", db.logger, "nil", cid)
@@ -57,6 +69,37 @@ func (db *Conn) GetAllAttributes(ctx context.Context, s *types.Strain, cid types
 	return err
 }
 
+// GetAttributesFor is GetAllAttributes for every one of ids with one query;
+// a strain that has no attributes isn't in the result
+func (db *Conn) GetAttributesFor(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID][]types.StrainAttribute, error) {
+	var err error
+	deferred, l := initAccessFuncs("GetAttributesFor", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["strainattribute"]["all-for"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[types.UUID][]types.StrainAttribute, len(ids))
+	for rows.Next() {
+		var id types.UUID
+		row := types.StrainAttribute{}
+		if err = rows.Scan(
+			&id,
+			&row.UUID,
+			&row.Name,
+			&row.Value); err != nil {
+
+			return nil, err
+		}
+		result[id] = append(result[id], row)
+	}
+
+	return result, err
+}
+
 func (db *Conn) AddAttribute(ctx context.Context, s *types.Strain, a types.StrainAttribute, cid types.CID) (types.StrainAttribute, error) {
 	var err error
 
@@ -65,6 +108,10 @@ func (db *Conn) AddAttribute(ctx context.Context, s *types.Strain, a types.Strai
 	deferred, l := initAccessFuncs("AddAttribute", db.logger, s.UUID, cid)
 	defer deferred(&err, l)
 
+	if a, err = db.checkAttribute(ctx, a, cid); err != nil {
+		return a, err
+	}
+
 	var rows int64
 	result, err := db.ExecContext(ctx, psqls["strainattribute"]["add"],
 		a.UUID,
@@ -93,6 +140,10 @@ func (db *Conn) ChangeAttribute(ctx context.Context, s *types.Strain, a types.St
 	deferred, l := initAccessFuncs("ChangeAttribute", db.logger, s.UUID, cid)
 	defer deferred(&err, l)
 
+	if a, err = db.checkAttribute(ctx, a, cid); err != nil {
+		return err
+	}
+
 	var rows int64
 	result, err := db.ExecContext(ctx, psqls["strainattribute"]["change"], a.Value, a.Name, a.UUID)
 	if err != nil {
@@ -136,3 +187,386 @@ func (db *Conn) RemoveAttribute(ctx context.Context, s *types.Strain, id types.U
 
 	return nil
 }
+
+// AttributeDefinitions is ordered by name, and each definition's synonyms
+// are too
+func (db *Conn) AttributeDefinitions(ctx context.Context, cid types.CID) ([]types.AttributeDefinition, error) {
+	var err error
+	deferred, l := initAccessFuncs("AttributeDefinitions", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["strainattribute"]["definitions"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]types.AttributeDefinition, 0, 100)
+	for rows.Next() {
+		row := types.AttributeDefinition{}
+		if err = rows.Scan(
+			&row.UUID,
+			&row.Name,
+			&row.Type,
+			&row.Unit,
+			pq.Array(&row.Allowed),
+			pq.Array(&row.Synonyms),
+		); err != nil {
+			return result, err
+		}
+		result = append(result, row)
+	}
+
+	return result, err
+}
+
+func (db *Conn) AddAttributeDefinition(ctx context.Context, d types.AttributeDefinition, cid types.CID) (types.AttributeDefinition, error) {
+	var err error
+
+	d.UUID = types.UUID(db.generateUUID().String())
+	// the database compares names without case or surrounding whitespace,
+	// see attribute_definitions_by_name, so there's no point storing any
+	d.Name = strings.TrimSpace(d.Name)
+
+	deferred, l := initAccessFuncs("AddAttributeDefinition", db.logger, d.UUID, cid)
+	defer deferred(&err, l)
+
+	if err = types.CheckAttributeDefinition(d); err != nil {
+		return d, err
+	} else if err = db.checkUnused(ctx, d.UUID, d.Name, cid); err != nil {
+		return d, err
+	}
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["strainattribute"]["add-definition"],
+		d.UUID,
+		d.Name,
+		d.Type,
+		d.Unit,
+		pq.Array(append([]string{}, d.Allowed...)), // nil would be null, not {}
+	)
+	if err != nil {
+		return d, err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return d, err
+	} else if rows != 1 {
+		return d, fmt.Errorf("attribute definition was not added")
+	}
+
+	d.Synonyms = nil
+
+	return d, err
+}
+
+// ChangeAttributeDefinition can rename d, and change its unit and allowed
+// values, but not its type; every value strains already have has to fit the
+// new definition, and they're re-spelled the way it spells them
+func (db *Conn) ChangeAttributeDefinition(ctx context.Context, d types.AttributeDefinition, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("ChangeAttributeDefinition", db.logger, d.UUID, cid)
+	defer deferred(&err, l)
+
+	d.Name = strings.TrimSpace(d.Name)
+
+	defs, err := db.AttributeDefinitions(ctx, cid)
+	if err != nil {
+		return err
+	}
+
+	i, j := 0, len(defs)
+	for i < j && defs[i].UUID != d.UUID {
+		i++
+	}
+	if i == j {
+		err = fmt.Errorf("attribute definition was not changed")
+		return err
+	} else if d.Type != defs[i].Type {
+		err = fmt.Errorf("an attribute's type can't be changed: '%s'", defs[i].Name)
+		return err
+	} else if err = types.CheckAttributeDefinition(d); err != nil {
+		return err
+	} else if err = db.checkUnused(ctx, d.UUID, d.Name, cid); err != nil {
+		return err
+	}
+
+	vals, err := db.attributeValues(ctx, defs[i].Name)
+	if err != nil {
+		return err
+	}
+
+	var a types.StrainAttribute
+	respelled := make([]types.StrainAttribute, 0, len(vals))
+	for _, v := range vals {
+		a, err = types.CheckAttribute([]types.AttributeDefinition{d}, types.StrainAttribute{
+			UUID:  v.UUID,
+			Name:  d.Name,
+			Value: v.Value,
+		})
+		if err != nil {
+			return err
+		} else if a.Value != v.Value {
+			respelled = append(respelled, a)
+		}
+	}
+
+	err = db.inTx(ctx, func(tx *Conn) error {
+		var rows int64
+		result, err := tx.ExecContext(ctx, psqls["strainattribute"]["change-definition"],
+			d.Name,
+			d.Unit,
+			pq.Array(append([]string{}, d.Allowed...)),
+			d.UUID)
+		if err != nil {
+			return err
+		} else if rows, err = result.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 {
+			return fmt.Errorf("attribute definition was not changed")
+		}
+
+		for _, a := range respelled {
+			if err = tx.moveAttribute(ctx, a); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// RemoveAttributeDefinition only works for a definition no strain uses;
+// its synonyms go with it
+func (db *Conn) RemoveAttributeDefinition(ctx context.Context, id types.UUID, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveAttributeDefinition", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["strainattribute"]["remove-definition"], id)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("attribute definition was not removed")
+		return err
+	}
+
+	return nil
+}
+
+// AddAttributeSynonym lets synonym stand in for d wherever an attribute
+// name is given; it can't already mean something else
+func (db *Conn) AddAttributeSynonym(ctx context.Context, d *types.AttributeDefinition, synonym string, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AddAttributeSynonym", db.logger, d.UUID, cid)
+	defer deferred(&err, l)
+
+	synonym = strings.TrimSpace(synonym)
+	if synonym == "" {
+		err = fmt.Errorf("a synonym can't be empty")
+		return err
+	} else if err = db.checkUnused(ctx, "", synonym, cid); err != nil {
+		return err
+	}
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["strainattribute"]["add-synonym"], synonym, d.UUID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("synonym was not added")
+		return err
+	}
+
+	d.Synonyms = append(d.Synonyms, synonym)
+
+	return nil
+}
+
+func (db *Conn) RemoveAttributeSynonym(ctx context.Context, d *types.AttributeDefinition, synonym string, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveAttributeSynonym", db.logger, d.UUID, cid)
+	defer deferred(&err, l)
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["strainattribute"]["remove-synonym"], synonym, d.UUID)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("synonym was not removed")
+		return err
+	}
+
+	i, j := 0, len(d.Synonyms)
+	for i < j && d.Synonyms[i] != synonym {
+		i++
+	}
+	if i < j {
+		d.Synonyms = append(d.Synonyms[:i], d.Synonyms[i+1:]...)
+	}
+
+	return nil
+}
+
+// MergeAttributeNames folds the definition from names into the one into
+// names, and from, along with its synonyms, becomes a synonym of into. A
+// strain that had both keeps its value for into; every other value for from
+// has to fit into, or nothing is merged
+func (db *Conn) MergeAttributeNames(ctx context.Context, from, into string, cid types.CID) (types.AttributeDefinition, error) {
+	var err error
+	deferred, l := initAccessFuncs("MergeAttributeNames", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	defs, err := db.AttributeDefinitions(ctx, cid)
+	if err != nil {
+		return types.AttributeDefinition{}, err
+	}
+
+	src, ok := types.FindAttribute(defs, from)
+	if !ok {
+		err = fmt.Errorf("unknown attribute: '%s'", from)
+		return types.AttributeDefinition{}, err
+	}
+	dst, ok := types.FindAttribute(defs, into)
+	if !ok {
+		err = fmt.Errorf("unknown attribute: '%s'", into)
+		return types.AttributeDefinition{}, err
+	} else if src.UUID == dst.UUID {
+		err = fmt.Errorf("'%s' and '%s' are already the same attribute", from, into)
+		return types.AttributeDefinition{}, err
+	}
+
+	kept, err := db.attributeValues(ctx, dst.Name)
+	if err != nil {
+		return types.AttributeDefinition{}, err
+	}
+	has := make(map[types.UUID]bool, len(kept))
+	for _, v := range kept {
+		has[v.strain] = true
+	}
+
+	vals, err := db.attributeValues(ctx, src.Name)
+	if err != nil {
+		return types.AttributeDefinition{}, err
+	}
+	var a types.StrainAttribute
+	moved, dropped := make([]types.StrainAttribute, 0, len(vals)), make([]types.UUID, 0, len(vals))
+	for _, v := range vals {
+		if has[v.strain] {
+			dropped = append(dropped, v.UUID)
+			continue
+		}
+		a, err = types.CheckAttribute([]types.AttributeDefinition{dst}, types.StrainAttribute{
+			UUID:  v.UUID,
+			Name:  dst.Name,
+			Value: v.Value,
+		})
+		if err != nil {
+			return types.AttributeDefinition{}, err
+		}
+		moved = append(moved, a)
+	}
+
+	err = db.inTx(ctx, func(tx *Conn) error {
+		for _, id := range dropped {
+			if _, err := tx.ExecContext(ctx, psqls["strainattribute"]["remove"], id); err != nil {
+				return err
+			}
+		}
+
+		for _, a := range moved {
+			if err := tx.moveAttribute(ctx, a); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, psqls["strainattribute"]["move-synonyms"], dst.UUID, src.UUID); err != nil {
+			return err
+		} else if _, err = tx.ExecContext(ctx, psqls["strainattribute"]["remove-definition"], src.UUID); err != nil {
+			return err
+		} else if _, err = tx.ExecContext(ctx, psqls["strainattribute"]["add-synonym"], src.Name, dst.UUID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return types.AttributeDefinition{}, err
+	}
+
+	dst.Synonyms = append(dst.Synonyms, src.Name)
+	dst.Synonyms = append(dst.Synonyms, src.Synonyms...)
+
+	return dst, nil
+}
+
+// checkAttribute returns a the way CheckAttribute spells it
+func (db *Conn) checkAttribute(ctx context.Context, a types.StrainAttribute, cid types.CID) (types.StrainAttribute, error) {
+	defs, err := db.AttributeDefinitions(ctx, cid)
+	if err != nil {
+		return a, err
+	}
+	return types.CheckAttribute(defs, a)
+}
+
+// attributeFilter is the json for select-all's attribute filters; the
+// definitions are only read when there's something to check against them
+func (db *Conn) attributeFilter(ctx context.Context, cid types.CID) (string, error) {
+	fs := types.GetContextAttributes(ctx)
+	if len(fs) == 0 {
+		return "[]", nil
+	}
+
+	defs, err := db.AttributeDefinitions(ctx, cid)
+	if err != nil {
+		return "", err
+	} else if fs, err = types.CheckAttributeFilters(defs, fs); err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(fs)
+	return string(b), err
+}
+
+// checkUnused makes sure name doesn't already mean some definition other
+// than id, either as its name or as one of its synonyms
+func (db *Conn) checkUnused(ctx context.Context, id types.UUID, name string, cid types.CID) error {
+	defs, err := db.AttributeDefinitions(ctx, cid)
+	if err != nil {
+		return err
+	} else if d, ok := types.FindAttribute(defs, name); ok && d.UUID != id {
+		return fmt.Errorf("'%s' already means '%s'", name, d.Name)
+	}
+	return nil
+}
+
+func (db *Conn) attributeValues(ctx context.Context, name string) ([]attributeValue, error) {
+	rows, err := db.query.QueryContext(ctx, psqls["strainattribute"]["values"], name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]attributeValue, 0, 100)
+	for rows.Next() {
+		row := attributeValue{StrainAttribute: types.StrainAttribute{Name: name}}
+		if err = rows.Scan(&row.UUID, &row.Value, &row.strain); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (db *Conn) moveAttribute(ctx context.Context, a types.StrainAttribute) error {
+	_, err := db.ExecContext(ctx, psqls["strainattribute"]["move"], a.Name, a.Value, a.UUID)
+	return err
+}
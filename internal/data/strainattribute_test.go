@@ -25,6 +25,13 @@ var (
 		{_attrs[1].UUID, _attrs[1].Name, _attrs[1].Value},
 		{_attrs[2].UUID, _attrs[2].Name, _attrs[2].Value},
 	}
+	defFields = row{"uuid", "name", "type", "unit", "allowed", "synonyms"}
+	defValues = [][]driver.Value{
+		{"color", "color", "enum", "", "{purple,white}", "{colour}"},
+		{"headroom", "headroom", "number", "cm", "{}", "{}"},
+		{"notes", "notes", "text", "", "{}", "{}"},
+	}
+	attrValueFields = row{"uuid", "value", "strain_uuid"}
 )
 
 func Test_KnownAttributeNames(t *testing.T) {
@@ -145,36 +152,62 @@ func Test_AddAttribute(t *testing.T) {
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
 			id: "0",
+			a:  types.StrainAttribute{Name: "Colour", Value: "PURPLE"},
 			result: []types.StrainAttribute{
-				{UUID: "30313233-3435-3637-3839-616263646566"},
+				{UUID: "30313233-3435-3637-3839-616263646566", Name: "color", Value: "purple"},
 			},
 		},
+		"definitions_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.fail())
+				return db
+			},
+			id:  "0",
+			a:   types.StrainAttribute{Name: "color", Value: "purple"},
+			err: defFields.err(),
+		},
+		"invalid_value": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			id:  "0",
+			a:   types.StrainAttribute{Name: "headroom", Value: "tall"},
+			err: fmt.Errorf("number value for 'headroom' isn't valid: 'tall'"),
+		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
 				return db
 			},
 			id:  "0",
+			a:   types.StrainAttribute{Name: "color", Value: "purple"},
 			err: fmt.Errorf("attribute was not added"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
 			id:  "0",
+			a:   types.StrainAttribute{Name: "color", Value: "purple"},
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
 				return db
 			},
 			id:  "0",
+			a:   types.StrainAttribute{Name: "color", Value: "purple"},
 			err: fmt.Errorf("some error"),
 		},
 	}
@@ -194,7 +227,7 @@ func Test_AddAttribute(t *testing.T) {
 			}).AddAttribute(
 				context.Background(),
 				s,
-				types.StrainAttribute{},
+				tc.a,
 				"Test_InsertStrains")
 
 			require.Equal(t, tc.err, err)
@@ -211,50 +244,75 @@ func Test_ChangeAttribute(t *testing.T) {
 	l := log.WithField("test", "RemoveAttribute")
 
 	tcs := map[string]struct {
-		db    getMockDB
-		attrs []types.StrainAttribute
-		id    types.UUID
-		n, v  string
-		err   error
+		db     getMockDB
+		attrs  []types.StrainAttribute
+		id     types.UUID
+		n, v   string
+		result []types.StrainAttribute
+		err    error
 	}{
 		"happy_path": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
 				return db
 			},
 			attrs: []types.StrainAttribute{
-				{UUID: "0", Name: "Mojo", Value: "Lost"},
-				{UUID: "1", Name: "Yield", Value: "Some"},
+				{UUID: "0", Name: "notes", Value: "Lost"},
+				{UUID: "1", Name: "headroom", Value: "20"},
 			},
 			id: "1",
-			n:  "Yield",
-			v:  "Lots!!",
+			n:  "headroom",
+			v:  "25.0",
+			result: []types.StrainAttribute{
+				{UUID: "0", Name: "notes", Value: "Lost"},
+				{UUID: "1", Name: "headroom", Value: "25"},
+			},
+		},
+		"invalid_value": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			attrs: []types.StrainAttribute{
+				{UUID: "1", Name: "color", Value: "white"},
+			},
+			id:  "1",
+			n:   "color",
+			v:   "albino",
+			err: fmt.Errorf("'color' has to be one of purple, white: 'albino'"),
+			result: []types.StrainAttribute{
+				{UUID: "1", Name: "color", Value: "white"},
+			},
 		},
 		"no_rows_affected": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
 				return db
 			},
-			n:   "Yield",
-			v:   "Lots!!",
+			n:   "headroom",
+			v:   "25",
 			err: fmt.Errorf("attribute was not changed"),
 		},
 		"query_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
 				return db
 			},
-			n:   "Yield",
-			v:   "Lots!!",
+			n:   "headroom",
+			v:   "25",
 			err: fmt.Errorf("some error"),
 		},
 		"result_fails": {
 			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
 				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
 				return db
 			},
-			n:   "Yield",
-			v:   "Lots!!",
+			n:   "headroom",
+			v:   "25",
 			err: fmt.Errorf("some error"),
 		},
 	}
@@ -277,6 +335,7 @@ func Test_ChangeAttribute(t *testing.T) {
 				"Test_RemoveAttribute")
 
 			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, s.Attributes)
 		})
 	}
 
@@ -351,3 +410,641 @@ func Test_RemoveAttribute(t *testing.T) {
 		})
 	}
 }
+
+func Test_AttributeDefinitions(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AttributeDefinitions")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []types.AttributeDefinition
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			result: []types.AttributeDefinition{
+				{UUID: "color", Name: "color", Type: types.EnumAttribute, Allowed: []string{"purple", "white"}, Synonyms: []string{"colour"}},
+				{UUID: "headroom", Name: "headroom", Type: types.NumberAttribute, Unit: "cm", Allowed: []string{}, Synonyms: []string{}},
+				{UUID: "notes", Name: "notes", Type: types.TextAttribute, Allowed: []string{}, Synonyms: []string{}},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.fail())
+				return db
+			},
+			err: defFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AttributeDefinitions(context.Background(), "Test_AttributeDefinitions")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_AddAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddAttributeDefinition")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		d      types.AttributeDefinition
+		result types.AttributeDefinition
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			d: types.AttributeDefinition{Name: "vigor", Type: types.EnumAttribute, Allowed: []string{"low", "high"}},
+			result: types.AttributeDefinition{
+				UUID:    "30313233-3435-3637-3839-616263646566",
+				Name:    "vigor",
+				Type:    types.EnumAttribute,
+				Allowed: []string{"low", "high"},
+			},
+		},
+		"untrimmed_name": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").
+					WithArgs(sqlmock.AnyArg(), "vigor", types.TextAttribute, "", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			d: types.AttributeDefinition{Name: " vigor\t", Type: types.TextAttribute},
+			result: types.AttributeDefinition{
+				UUID: "30313233-3435-3637-3839-616263646566",
+				Name: "vigor",
+				Type: types.TextAttribute,
+			},
+		},
+		"invalid_definition": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			d: types.AttributeDefinition{Name: "vigor", Type: types.EnumAttribute},
+			result: types.AttributeDefinition{
+				UUID: "30313233-3435-3637-3839-616263646566",
+				Name: "vigor",
+				Type: types.EnumAttribute,
+			},
+			err: fmt.Errorf("an enum attribute needs allowed values: 'vigor'"),
+		},
+		"name_is_a_synonym": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			d: types.AttributeDefinition{Name: "Colour", Type: types.TextAttribute},
+			result: types.AttributeDefinition{
+				UUID: "30313233-3435-3637-3839-616263646566",
+				Name: "Colour",
+				Type: types.TextAttribute,
+			},
+			err: fmt.Errorf("'Colour' already means 'color'"),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			d: types.AttributeDefinition{Name: "vigor", Type: types.TextAttribute},
+			result: types.AttributeDefinition{
+				UUID: "30313233-3435-3637-3839-616263646566",
+				Name: "vigor",
+				Type: types.TextAttribute,
+			},
+			err: fmt.Errorf("attribute definition was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			d: types.AttributeDefinition{Name: "vigor", Type: types.TextAttribute},
+			result: types.AttributeDefinition{
+				UUID: "30313233-3435-3637-3839-616263646566",
+				Name: "vigor",
+				Type: types.TextAttribute,
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddAttributeDefinition(context.Background(), tc.d, "Test_AddAttributeDefinition")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_ChangeAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ChangeAttributeDefinition")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		d   types.AttributeDefinition
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					defFields.set(defValues...),
+					attrValueFields.set(
+						[]driver.Value{"0", "purple", "strain 0"},
+						[]driver.Value{"1", "white", "strain 1"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("").
+					WithArgs("colour", "", sqlmock.AnyArg(), "color").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs("colour", "Purple", "0").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			d: types.AttributeDefinition{
+				UUID:     "color",
+				Name:     "colour",
+				Type:     types.EnumAttribute,
+				Allowed:  []string{"Purple", "white", "blue"},
+				Synonyms: []string{"colour"},
+			},
+		},
+		"missing": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			d:   types.AttributeDefinition{UUID: "missing", Name: "missing", Type: types.TextAttribute},
+			err: fmt.Errorf("attribute definition was not changed"),
+		},
+		"changed_type": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			d:   types.AttributeDefinition{UUID: "notes", Name: "notes", Type: types.NumberAttribute},
+			err: fmt.Errorf("an attribute's type can't be changed: 'notes'"),
+		},
+		"name_is_taken": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					defFields.set(defValues...))
+				return db
+			},
+			d:   types.AttributeDefinition{UUID: "notes", Name: "headroom", Type: types.TextAttribute},
+			err: fmt.Errorf("'headroom' already means 'headroom'"),
+		},
+		"value_doesnt_fit": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					defFields.set(defValues...),
+					attrValueFields.set([]driver.Value{"0", "white", "strain 0"}))
+				return db
+			},
+			d:   types.AttributeDefinition{UUID: "color", Name: "color", Type: types.EnumAttribute, Allowed: []string{"purple"}},
+			err: fmt.Errorf("'color' has to be one of purple: 'white'"),
+		},
+		"values_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					defFields.set(defValues...),
+					attrValueFields.fail())
+				return db
+			},
+			d:   types.AttributeDefinition{UUID: "notes", Name: "notes", Type: types.TextAttribute},
+			err: attrValueFields.err(),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					defFields.set(defValues...),
+					attrValueFields.set())
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			d:   types.AttributeDefinition{UUID: "notes", Name: "notes", Type: types.TextAttribute},
+			err: fmt.Errorf("attribute definition was not changed"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+
+			err = (&Conn{
+				query:        tc.db(db, mock, err),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).ChangeAttributeDefinition(context.Background(), tc.d, "Test_ChangeAttributeDefinition")
+
+			require.Equal(t, tc.err, err)
+			require.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_RemoveAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveAttributeDefinition")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			err: fmt.Errorf("attribute definition was not removed"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveAttributeDefinition(context.Background(), "0", "Test_RemoveAttributeDefinition")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_AddAttributeSynonym(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddAttributeSynonym")
+
+	tcs := map[string]struct {
+		db      getMockDB
+		synonym string
+		result  []string
+		err     error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").
+					WithArgs("height", "headroom").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			synonym: " height ",
+			result:  []string{"height"},
+		},
+		"empty": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			synonym: " ",
+			err:     fmt.Errorf("a synonym can't be empty"),
+		},
+		"already_means_something": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			synonym: "colour",
+			err:     fmt.Errorf("'colour' already means 'color'"),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			synonym: "height",
+			err:     fmt.Errorf("synonym was not added"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			synonym: "height",
+			err:     fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &types.AttributeDefinition{UUID: "headroom", Name: "headroom", Type: types.NumberAttribute}
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddAttributeSynonym(context.Background(), d, tc.synonym, "Test_AddAttributeSynonym")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, d.Synonyms)
+		})
+	}
+}
+
+func Test_RemoveAttributeSynonym(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveAttributeSynonym")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []string
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			result: []string{"hue"},
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			result: []string{"colour", "hue"},
+			err:    fmt.Errorf("synonym was not removed"),
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			result: []string{"colour", "hue"},
+			err:    fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &types.AttributeDefinition{UUID: "color", Name: "color", Synonyms: []string{"colour", "hue"}}
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveAttributeSynonym(context.Background(), d, "colour", "Test_RemoveAttributeSynonym")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, d.Synonyms)
+		})
+	}
+}
+
+func Test_MergeAttributeNames(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "MergeAttributeNames")
+
+	tcs := map[string]struct {
+		db         getMockDB
+		from, into string
+		result     types.AttributeDefinition
+		err        error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					attrValueFields.set([]driver.Value{"1", "white", "strain 1"}),
+					attrValueFields.set(
+						[]driver.Value{"2", "Purple", "strain 0"},
+						[]driver.Value{"3", "purple", "strain 1"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("").
+					WithArgs("3").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs("color", "purple", "2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs("color", "notes").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("").
+					WithArgs("notes").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").
+					WithArgs("notes", "color").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			from: "notes",
+			into: "Colour",
+			result: types.AttributeDefinition{
+				UUID:     "color",
+				Name:     "color",
+				Type:     types.EnumAttribute,
+				Allowed:  []string{"purple", "white"},
+				Synonyms: []string{"colour", "notes"},
+			},
+		},
+		"unknown_from": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			from: "vigor",
+			into: "color",
+			err:  fmt.Errorf("unknown attribute: 'vigor'"),
+		},
+		"unknown_into": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			from: "color",
+			into: "vigor",
+			err:  fmt.Errorf("unknown attribute: 'vigor'"),
+		},
+		"same_attribute": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.set(defValues...))
+				return db
+			},
+			from: "colour",
+			into: "color",
+			err:  fmt.Errorf("'colour' and 'color' are already the same attribute"),
+		},
+		"value_doesnt_fit": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					attrValueFields.set(),
+					attrValueFields.set([]driver.Value{"2", "tall", "strain 0"}))
+				return db
+			},
+			from: "notes",
+			into: "headroom",
+			err:  fmt.Errorf("number value for 'headroom' isn't valid: 'tall'"),
+		},
+		"definitions_fail": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, defFields.fail())
+				return db
+			},
+			from: "notes",
+			into: "color",
+			err:  defFields.err(),
+		},
+		"merge_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock,
+					defFields.set(defValues...),
+					attrValueFields.set(),
+					attrValueFields.set())
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			from: "notes",
+			into: "color",
+			err:  fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+
+			result, err := (&Conn{
+				query:        tc.db(db, mock, err),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).MergeAttributeNames(context.Background(), tc.from, tc.into, "Test_MergeAttributeNames")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+			require.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_GetAttributesFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetAttributesFor")
+
+	fields := attrFields.keyed("strain_uuid")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID][]types.StrainAttribute
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(append(
+					keyed("0", attrValues[0], attrValues[1]),
+					keyed("1", attrValues[2])...)...))
+				return db
+			},
+			result: map[types.UUID][]types.StrainAttribute{
+				"0": {types.StrainAttribute(_attrs[0]), types.StrainAttribute(_attrs[1])},
+				"1": {types.StrainAttribute(_attrs[2])},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetAttributesFor(context.Background(), []types.UUID{"0", "1"}, "Test_GetAttributesFor")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -14,7 +14,7 @@ func (db *Conn) SelectAllSubstrates(ctx context.Context, cid types.CID) ([]types
 	deferred, l := initAccessFuncs("SelectAllSubstrates", db.logger, "nil", cid)
 	defer deferred(&err, l)
 
-	rows, err := db.query.QueryContext(ctx, psqls["substrate"]["select-all"])
+	rows, err := db.query.QueryContext(ctx, psqls["substrate"]["select-all"], tagFilter(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -404,7 +404,7 @@ func Test_SubstrateReport(t *testing.T) {
 
 			result: func(s types.Entity) types.Entity {
 
-				g := mustEntity(_gen)
+				g := genEntity(types.ActiveStatus)
 
 				g["events"] = events
 				g["plating_substrate"].(map[string]interface{})["ingredients"] = ingredients
@@ -452,7 +452,7 @@ func Test_SubstrateReport(t *testing.T) {
 				return db
 			},
 			result: func(s types.Entity) types.Entity {
-				s["lifecycles"] = []types.Entity{mustEntity(_lc)}
+				s["lifecycles"] = []types.Entity{lcEntity(types.PendingStatus)}
 				return s
 			}(mustEntity(_subs[1])),
 		},
@@ -34,6 +34,36 @@ func (db *Conn) GetAllIngredients(ctx context.Context, s *types.Substrate, cid t
 	return nil
 }
 
+// GetIngredientsFor is GetAllIngredients for every one of ids with one
+// query; a substrate that has no ingredients isn't in the result
+func (db *Conn) GetIngredientsFor(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID][]types.Ingredient, error) {
+	var err error
+	deferred, l := initAccessFuncs("GetIngredientsFor", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["substrate-ingredient"]["all-for"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[types.UUID][]types.Ingredient, len(ids))
+	for rows.Next() {
+		var id types.UUID
+		row := types.Ingredient{}
+		if err = rows.Scan(
+			&id,
+			&row.UUID,
+			&row.Name,
+		); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], row)
+	}
+
+	return result, nil
+}
+
 func (db *Conn) AddIngredient(ctx context.Context, s *types.Substrate, i types.Ingredient, cid types.CID) error {
 	var err error
 	deferred, l := initAccessFuncs("AddIngredient", db.logger, "nil", cid)
@@ -273,3 +273,53 @@ func Test_RemoveIngredient(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetIngredientsFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetIngredientsFor")
+
+	fields := ingFields.keyed("substrate_uuid")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID][]types.Ingredient
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(append(
+					keyed("0", ingValues[0], ingValues[1]),
+					keyed("1", ingValues[2])...)...))
+				return db
+			},
+			result: map[types.UUID][]types.Ingredient{
+				"0": {types.Ingredient(_ingredients[0]), types.Ingredient(_ingredients[1])},
+				"1": {types.Ingredient(_ingredients[2])},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetIngredientsFor(context.Background(), []types.UUID{"0", "1"}, "Test_GetIngredientsFor")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
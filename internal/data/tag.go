@@ -0,0 +1,135 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	pq "github.com/lib/pq"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func (db *Conn) KnownTags(ctx context.Context, cid types.CID) ([]string, error) {
+	var err error
+	deferred, l := initAccessFuncs("KnownTags", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["tag"]["known"])
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, err := scanTags(rows)
+
+	return result, err
+}
+
+func (db *Conn) GetTags(ctx context.Context, id types.UUID, cid types.CID) ([]string, error) {
+	var err error
+	deferred, l := initAccessFuncs("GetTags", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["tag"]["get"], id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, err := scanTags(rows)
+
+	return result, err
+}
+
+func scanTags(rows *sql.Rows) ([]string, error) {
+	result := []string{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// GetTagsFor is GetTags for every one of ids with one query; an id that has
+// no tags isn't in the result
+func (db *Conn) GetTagsFor(ctx context.Context, ids []types.UUID, cid types.CID) (map[types.UUID][]string, error) {
+	var err error
+	deferred, l := initAccessFuncs("GetTagsFor", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["tag"]["get-for"], uuidArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[types.UUID][]string, len(ids))
+	for rows.Next() {
+		var id types.UUID
+		var t string
+		if err = rows.Scan(&id, &t); err != nil {
+			return nil, err
+		}
+		result[id] = append(result[id], t)
+	}
+
+	return result, nil
+}
+
+// AddTag fails with a unique key violation if id already has tag
+func (db *Conn) AddTag(ctx context.Context, id types.UUID, tag string, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("AddTag", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	if tag, err = types.NormalizeTag(tag); err != nil {
+		return err
+	}
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["tag"]["add"], id, tag)
+	if err != nil {
+		err = pqerr(err)
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("tag was not added, '%s' isn't taggable", id)
+		return err
+	}
+
+	return nil
+}
+
+func (db *Conn) RemoveTag(ctx context.Context, id types.UUID, tag string, cid types.CID) error {
+	var err error
+	deferred, l := initAccessFuncs("RemoveTag", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	if tag, err = types.NormalizeTag(tag); err != nil {
+		return err
+	}
+
+	var rows int64
+	result, err := db.ExecContext(ctx, psqls["tag"]["remove"], id, tag)
+	if err != nil {
+		return err
+	} else if rows, err = result.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		err = fmt.Errorf("tag could not be removed: '%s'", tag)
+		return err
+	}
+
+	return nil
+}
+
+// tagFilter is the array parameter for queries that only return taggables
+// with every tag in ctx; with no tags in ctx, everything matches
+func tagFilter(ctx context.Context) any {
+	return pq.Array(types.GetContextTags(ctx))
+}
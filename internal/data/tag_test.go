@@ -0,0 +1,316 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jsmit257/huautla/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KnownTags(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "KnownTags")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []string
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").
+					WillReturnRows(sqlmock.
+						NewRows([]string{"tag"}).
+						AddRow("competition").
+						AddRow("keeper"))
+				return db
+			},
+			result: []string{"competition", "keeper"},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).KnownTags(context.Background(), "Test_KnownTags")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_GetTags(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetTags")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result []string
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").
+					WithArgs("lc0").
+					WillReturnRows(sqlmock.
+						NewRows([]string{"tag"}).
+						AddRow("keeper"))
+				return db
+			},
+			result: []string{"keeper"},
+		},
+		"no_tags": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").WillReturnRows(sqlmock.NewRows([]string{"tag"}))
+				return db
+			},
+			result: []string{},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectQuery("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetTags(context.Background(), "lc0", "Test_GetTags")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_AddTag(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "AddTag")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		tag string
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WithArgs("lc0", "suspect batch").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			tag: " Suspect Batch",
+		},
+		"empty_tag": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				return db
+			},
+			tag: "  ",
+			err: fmt.Errorf("a tag can't be empty"),
+		},
+		"duplicate_tag": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WillReturnError(pqError("23505", "Key (taggable_uuid, tag)=(lc0, keeper) already exists.", "tags", "", "tags_pkey"))
+				return db
+			},
+			tag: "keeper",
+			err: fmt.Errorf("unique key violation: Key (taggable_uuid, tag)=(lc0, keeper) already exists."),
+		},
+		"not_taggable": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			tag: "keeper",
+			err: fmt.Errorf("tag was not added, 'lc0' isn't taggable"),
+		},
+		"result_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+				return db
+			},
+			tag: "keeper",
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).AddTag(context.Background(), "lc0", tc.tag, "Test_AddTag")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_RemoveTag(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "RemoveTag")
+
+	tcs := map[string]struct {
+		db  getMockDB
+		tag string
+		err error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").
+					WithArgs("lc0", "keeper").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			tag: "Keeper",
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			tag: "keeper",
+			err: fmt.Errorf("tag could not be removed: 'keeper'"),
+		},
+		"exec_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				return db
+			},
+			tag: "keeper",
+			err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).RemoveTag(context.Background(), "lc0", tc.tag, "Test_RemoveTag")
+
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func Test_tagFilter(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		ctx    context.Context
+		result driver.Value
+	}{
+		"no_tags": {
+			ctx:    context.Background(),
+			result: "{}",
+		},
+		"tags": {
+			ctx:    types.WithTags(context.Background(), "Keeper", "competition"),
+			result: `{"keeper","competition"}`,
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := tagFilter(tc.ctx).(driver.Valuer).Value()
+			require.Nil(t, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_GetTagsFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "GetTagsFor")
+
+	fields := row{"taggable_uuid", "tag"}
+
+	tcs := map[string]struct {
+		db     getMockDB
+		result map[types.UUID][]string
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.set(
+					[]driver.Value{"0", "competition"},
+					[]driver.Value{"0", "keeper"},
+					[]driver.Value{"1", "keeper"}))
+				return db
+			},
+			result: map[types.UUID][]string{
+				"0": {"competition", "keeper"},
+				"1": {"keeper"},
+			},
+		},
+		"query_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, fields.fail())
+				return db
+			},
+			err: fields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).GetTagsFor(context.Background(), []types.UUID{"0", "1"}, "Test_GetTagsFor")
+
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+// DueTasks is every open task due by the given time for a lifecycle or
+// generation that isn't dead or finished, oldest first; anything already past
+// due is marked overdue
+func (db *Conn) DueTasks(ctx context.Context, by time.Time, cid types.CID) ([]types.Task, error) {
+	var err error
+	deferred, l := initAccessFuncs("DueTasks", db.logger, "nil", cid)
+	defer deferred(&err, l)
+
+	by = by.UTC()
+	result, err := db.selectTasks(ctx, nil, nil, &by, cid)
+
+	return result, err
+}
+
+func (db *Conn) ObservableTasks(ctx context.Context, id types.UUID, cid types.CID) ([]types.Task, error) {
+	var err error
+	deferred, l := initAccessFuncs("ObservableTasks", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result, err := db.selectTasks(ctx, nil, &id, nil, cid)
+
+	return result, err
+}
+
+// CompleteTask marks a task done; with an event, it also records that event
+// for the task's observable, using the follow-up's expected event type when
+// e doesn't name one
+func (db *Conn) CompleteTask(ctx context.Context, id types.UUID, e *types.Event, cid types.CID) (types.Task, error) {
+	var err error
+	deferred, l := initAccessFuncs("CompleteTask", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	result := types.Task{UUID: id}
+
+	tasks, err := db.selectTasks(ctx, &id, nil, nil, cid)
+	if err != nil {
+		return result, err
+	} else if len(tasks) == 0 {
+		err = sql.ErrNoRows
+		return result, err
+	}
+
+	result = tasks[0]
+	if result.Done != nil {
+		err = fmt.Errorf("task was already done: '%s'", id)
+		return result, err
+	}
+
+	if e != nil && e.EventType.UUID == "" {
+		if result.FollowUp.Expects == nil {
+			err = fmt.Errorf("task doesn't expect an event type, so the event needs one")
+			return result, err
+		}
+		e.EventType = *result.FollowUp.Expects
+	}
+
+	done := time.Now().UTC()
+
+	// the event and the task go together, or a retry would record it twice
+	var recorded *types.UUID
+	if err = db.inTx(ctx, func(tx *Conn) error {
+		if e != nil {
+			evt, err := tx.InsertEvent(ctx, result.ObservableUUID, *e, cid)
+			if err != nil {
+				return err
+			}
+			recorded = &evt.UUID
+		}
+
+		res, err := tx.ExecContext(ctx, psqls["task"]["complete"], done, recorded, id)
+		if err != nil {
+			return err
+		} else if rows, err := res.RowsAffected(); err != nil {
+			return err
+		} else if rows != 1 {
+			return fmt.Errorf("task was not completed: '%s'", id)
+		}
+		return nil
+	}); err != nil {
+		return result, err
+	}
+
+	result.Result, result.Done, result.Overdue = recorded, &done, false
+
+	return result, nil
+}
+
+func (db *Conn) selectTasks(ctx context.Context, id, oID *types.UUID, by *time.Time, cid types.CID) ([]types.Task, error) {
+	var err error
+	deferred, l := initAccessFuncs("selectTasks", db.logger, id, cid)
+	defer deferred(&err, l)
+
+	rows, err := db.query.QueryContext(ctx, psqls["task"]["select"], id, oID, by)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now().UTC()
+
+	result := []types.Task{}
+	for rows.Next() {
+		var t types.Task
+		var x nulleventtype
+		if err = rows.Scan(
+			&t.UUID,
+			&t.FollowUp.UUID,
+			&t.FollowUp.Name,
+			&t.FollowUp.Days,
+			&x.uuid,
+			&x.name,
+			&x.severity,
+			&x.stageUUID,
+			&x.stageName,
+			&t.ObservableType,
+			&t.ObservableUUID,
+			&t.EventUUID,
+			&t.Due,
+			&t.Done,
+			&t.Result,
+		); err != nil {
+			return nil, err
+		}
+		t.FollowUp.Expects = x.eventType()
+		t.Overdue = t.Done == nil && t.Due.Before(now)
+		result = append(result, t)
+	}
+
+	return result, nil
+}
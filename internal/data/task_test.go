@@ -0,0 +1,263 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+var (
+	taskFields = row{
+		"uuid",
+		"followup_uuid",
+		"followup_name",
+		"days",
+		"expects_uuid",
+		"expects_name",
+		"expects_severity",
+		"expects_stage_uuid",
+		"expects_stage_name",
+		"observable_type",
+		"observable_uuid",
+		"event_uuid",
+		"due",
+		"done",
+		"result_uuid",
+	}
+	taskValues = [][]driver.Value{
+		// overdue
+		{"task 0", _followups[0].UUID, _followups[0].Name, _followups[0].Days, _ets[1].UUID, _ets[1].Name, _ets[1].Severity, _ets[1].Stage.UUID, _ets[1].Stage.Name, types.LifecycleParent, "lifecycle 0", "event 0", wwtbn.Add(-time.Hour), nil, nil},
+		// due, but not yet
+		{"task 1", _followups[1].UUID, _followups[1].Name, _followups[1].Days, nil, nil, nil, nil, nil, types.GenerationParent, "generation 0", "event 1", wwtbn.Add(time.Hour), nil, nil},
+		// done
+		{"task 2", _followups[1].UUID, _followups[1].Name, _followups[1].Days, nil, nil, nil, nil, nil, types.LifecycleParent, "lifecycle 0", "event 2", wwtbn.Add(-time.Hour), wwtbn, "event 3"},
+	}
+)
+
+func Test_DueTasks(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "DueTasks")
+
+	tcs := map[string]struct {
+		db      getMockDB
+		result  []types.UUID
+		overdue []bool
+		err     error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[:2]...))
+				return db
+			},
+			result:  []types.UUID{"task 0", "task 1"},
+			overdue: []bool{true, false},
+		},
+		"nothing_due": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set())
+				return db
+			},
+			result:  []types.UUID{},
+			overdue: []bool{},
+		},
+		"db_error": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.fail())
+				return db
+			},
+			err: taskFields.err(),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+			}).DueTasks(context.Background(), wwtbn.Add(24*time.Hour), "Test_DueTasks")
+
+			require.Equal(t, tc.err, err)
+			if tc.err != nil {
+				return
+			}
+			ids, overdue := []types.UUID{}, []bool{}
+			for _, task := range result {
+				ids, overdue = append(ids, task.UUID), append(overdue, task.Overdue)
+			}
+			require.Equal(t, tc.result, ids)
+			require.Equal(t, tc.overdue, overdue)
+		})
+	}
+}
+
+func Test_ObservableTasks(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "ObservableTasks")
+
+	result, err := (&Conn{
+		query: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+			newBuilder(mock, taskFields.set(taskValues[0], taskValues[2]))
+			return db
+		}(sqlmock.New()),
+		generateUUID: mockUUIDGen,
+		logger:       l,
+	}).ObservableTasks(context.Background(), "lifecycle 0", "Test_ObservableTasks")
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(result))
+	require.Equal(t, types.Task{
+		UUID:           "task 0",
+		FollowUp:       _followups[0],
+		ObservableType: types.LifecycleParent,
+		ObservableUUID: "lifecycle 0",
+		EventUUID:      "event 0",
+		Due:            wwtbn.Add(-time.Hour),
+		Overdue:        true,
+	}, result[0])
+	require.NotNil(t, result[1].Done)
+	require.Equal(t, types.UUID("event 3"), *result[1].Result)
+	require.False(t, result[1].Overdue)
+}
+
+func Test_CompleteTask(t *testing.T) {
+	t.Parallel()
+
+	l := log.WithField("test", "CompleteTask")
+
+	mockUUID := types.UUID("30313233-3435-3637-3839-616263646566")
+
+	tcs := map[string]struct {
+		db     getMockDB
+		e      *types.Event
+		result *types.UUID
+		err    error
+	}{
+		"happy_path": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[0]))
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+		},
+		"expected_event": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[0]))
+				mock.ExpectBegin()
+				newBuilder(mock, etFields.set(etValues[0]))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1)) // event
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1)) // observable mtime
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			e:      &types.Event{Temperature: 21},
+			result: &mockUUID,
+		},
+		// the event is rolled back with the task, so a retry won't record
+		// it twice
+		"complete_fails_after_event": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[0]))
+				mock.ExpectBegin()
+				newBuilder(mock, etFields.set(etValues[0]))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			e:   &types.Event{},
+			err: fmt.Errorf("some error"),
+		},
+		"no_event_type": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[1]))
+				return db
+			},
+			e:   &types.Event{},
+			err: fmt.Errorf("task doesn't expect an event type, so the event needs one"),
+		},
+		"event_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[0]))
+				mock.ExpectBegin()
+				newBuilder(mock, etFields.set(etValues[0]))
+				mock.ExpectExec("").WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
+				return db
+			},
+			e:   &types.Event{},
+			err: fmt.Errorf("some error"),
+		},
+		"already_done": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[2]))
+				return db
+			},
+			err: fmt.Errorf("task was already done: 'task 0'"),
+		},
+		"missing_task": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set())
+				return db
+			},
+			err: sql.ErrNoRows,
+		},
+		"select_fails": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.fail())
+				return db
+			},
+			err: taskFields.err(),
+		},
+		"no_rows_affected": {
+			db: func(db *sql.DB, mock sqlmock.Sqlmock, err error) *sql.DB {
+				newBuilder(mock, taskFields.set(taskValues[0]))
+				mock.ExpectBegin()
+				mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return db
+			},
+			err: fmt.Errorf("task was not completed: 'task 0'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := (&Conn{
+				query:        tc.db(sqlmock.New()),
+				generateUUID: mockUUIDGen,
+				logger:       l.WithField("name", name),
+				transitions:  types.IgnoreTransitions,
+			}).CompleteTask(context.Background(), "task 0", tc.e, "Test_CompleteTask")
+
+			require.Equal(t, tc.err, err)
+			if tc.err != nil {
+				return
+			}
+			require.NotNil(t, result.Done)
+			require.False(t, result.Overdue)
+			require.Equal(t, tc.result, result.Result)
+		})
+	}
+}
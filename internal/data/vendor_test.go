@@ -359,9 +359,9 @@ func Test_VendorReport(t *testing.T) {
 				return db
 			},
 			result: func(v types.Entity) types.Entity {
-				str := mustEntity(_strain)
-				str["lifecycles"] = []types.Entity{mustEntity(_lc)}
-				str["generations"] = []types.Entity{mustEntity(_gen)}
+				str := mustEntity(_filiatedStrain)
+				str["lifecycles"] = []types.Entity{lcEntity(types.PendingStatus)}
+				str["generations"] = []types.Entity{genEntity(types.PendingStatus)}
 
 				v["strains"] = []types.Entity{str}
 
@@ -403,7 +403,7 @@ func Test_VendorReport(t *testing.T) {
 			},
 			result: func(v types.Entity) types.Entity {
 				sub := mustEntity(_subs[1])
-				sub["lifecycles"] = []types.Entity{mustObject(_lc)}
+				sub["lifecycles"] = []types.Entity{lcEntity(types.PendingStatus)}
 
 				v["substrates"] = []types.Entity{sub}
 
@@ -445,7 +445,7 @@ func Test_VendorReport(t *testing.T) {
 				return db
 			},
 			result: func(v types.Entity) types.Entity {
-				gen := mustEntity(_gen)
+				gen := genEntity(types.PendingStatus)
 				gen["sources"] = []interface{}{mustObject(_src)}
 
 				sub := mustEntity(_subs[0])
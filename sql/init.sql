@@ -21,6 +21,9 @@ begin; /** base table creation */
   -- don't abuse this! not everything needs its picture taken
   create table photoables() inherits(uuids);
 
+  -- free-form labels, like 'keeper' or 'suspect batch'
+  create table taggables() inherits(uuids);
+
   begin; /** base table constraints */
     create function noinsert()
     returns trigger
@@ -61,6 +64,12 @@ begin; /** base table creation */
         on photoables
       for each statement
     execute function noinsert();
+
+    create trigger TaggableInserter
+    before insert
+        on taggables
+      for each statement
+    execute function noinsert();
   end;
 end;
 
@@ -76,7 +85,7 @@ create table substrates (
   type        varchar(25)  not null check (type in ('plating', 'liquid', 'grain', 'bulk')),
   vendor_uuid varchar(40)  not null references vendors(uuid),
   unique(name, vendor_uuid)
-) inherits(uuids);
+) inherits(taggables);
 
 create table ingredients (
   uuid            varchar(40)  not null primary key,
@@ -96,11 +105,32 @@ create table strains (
   name        varchar(512) not null,
   vendor_uuid varchar(40)  not null references vendors(uuid),
   unique(name, vendor_uuid, ctime)
-) inherits(progenitors, photoables);
+) inherits(progenitors, photoables, taggables);
+
+-- the vocabulary strain attributes are checked against; only enums have
+-- allowed values and only numbers have a unit, see CheckAttributeDefinition
+create table attribute_definitions (
+  uuid    varchar(40)    not null primary key,
+  name    varchar(40)    not null unique,
+  type    varchar(8)     not null check (type in ('number', 'enum', 'boolean', 'text')),
+  unit    varchar(32)    not null default '',
+  allowed varchar(512)[] not null default '{}'
+) inherits(uuids);
+
+-- names that only differ by case or surrounding whitespace are the same
+-- attribute
+create unique index attribute_definitions_by_name on attribute_definitions(lower(trim(name)));
+
+-- other names that mean the same attribute, like the ones left behind when
+-- two definitions are merged
+create table attribute_synonyms (
+  synonym         varchar(40) not null primary key,
+  definition_uuid varchar(40) not null references attribute_definitions(uuid) on delete cascade
+);
 
 create table strain_attributes (
   uuid         varchar(40)  not null primary key,
-  name         varchar(40)  not null,
+  name         varchar(40)  not null references attribute_definitions(name) on update cascade,
   value        varchar(512) not null,
   strain_uuid  varchar(40)  not null references strains(uuid),
   unique(name, strain_uuid)
@@ -119,48 +149,117 @@ create table event_types (
   unique(name, stage_uuid)
 ) inherits(uuids);
 
+-- somewhere lifecycles are kept; capacity is how many fit at once, 0 for no
+-- limit, and a null target means nothing to aim for at that end of the range
+create table locations (
+  uuid            varchar(40)  not null primary key,
+  name            varchar(128) not null unique,
+  kind            varchar(20)  not null check (kind in ('incubator', 'fruiting chamber', 'outdoor bed')),
+  capacity        int          not null default 0 check (capacity >= 0),
+  min_temperature numeric(4,1) null,
+  max_temperature numeric(4,1) null,
+  min_humidity    numeric(5,2) null,
+  max_humidity    numeric(5,2) null,
+  max_co2         numeric(7,1) null
+) inherits(uuids);
+
+-- names that only differ by case or surrounding whitespace are the same place
+create unique index locations_by_name on locations(lower(trim(name)));
+
 create table lifecycles (
   uuid                varchar(40)  not null primary key,
-  location            varchar(128) not null,
+  location_uuid       varchar(40)  not null references locations(uuid),
   strain_cost         decimal(8,2) not null default 0.0,
   grain_cost          decimal(8,2) not null default 0.0,
   bulk_cost           decimal(8,2) not null default 0.0,
-  -- the net weight, fresh or dried; for dried, 1.0-(yield/gross) is how much water they typically contain
+  -- the net weight in grams, fresh or dried; for dried, 1.0-(yield/gross) is how much water they typically contain
   yield               decimal(8,2) not null default 0,
   headcount           decimal(6)   not null default 0,
-  -- gross the fresh weight, regardless of whether they're sold fresh or dry (see yield)
+  -- gross the fresh weight in grams, regardless of whether they're sold fresh or dry (see yield)
   gross               decimal(8,2) not null default 0,
   strain_uuid         varchar(40)  not null references strains(uuid),
   grainsubstrate_uuid varchar(40)  not null references substrates(uuid),
   bulksubstrate_uuid  varchar(40)  not null references substrates(uuid),
-  unique(location, ctime)
-) inherits(observables, notables);
+  unique(location_uuid, ctime)
+) inherits(observables, notables, taggables);
+
+-- one flush; once a lifecycle has any, its yield, headcount and gross are
+-- tallied from them (dry_weight when it's been dried, fresh_weight otherwise);
+-- weights are in grams
+create table harvests (
+  uuid           varchar(40)  not null primary key,
+  harvest_date   timestamp    not null default current_timestamp,
+  fresh_weight   decimal(8,2) not null default 0,
+  dry_weight     decimal(8,2) not null default 0,
+  headcount      decimal(6)   not null default 0,
+  grade          varchar(40)  not null default '',
+  lifecycle_uuid varchar(40)  not null references lifecycles(uuid)
+) inherits(notables);
 
+-- one row per sensor per sample, so there's no uuid and nothing inherited;
+-- (sensor, read_at) is what makes re-sending a batch harmless
+create table readings (
+  sensor         varchar(128) not null,
+  -- by name, since that's what a sensor gets configured with
+  location       varchar(128) not null references locations(name) on update cascade,
+  -- only for a sensor that's dedicated to one lifecycle, e.g. inside a tub
+  lifecycle_uuid varchar(40)  null references lifecycles(uuid),
+  read_at        timestamp    not null,
+  temperature    numeric(5,2) null,
+  humidity       numeric(5,2) null,
+  co2            numeric(7,1) null,
+  light          numeric(9,1) null,
+  primary key (sensor, read_at)
+);
+
+create index readings_by_location on readings(location, read_at);
+
+-- occurred_at is when it actually happened, which can be before it was
+-- recorded, but never before its observable was created
 create table events (
   uuid            varchar(40)  not null primary key,
+  -- celsius
   temperature     numeric(4,1) not null default 0.0,
   humidity        int          not null default 0,
+  occurred_at     timestamp    not null default current_timestamp,
   observable_uuid varchar(40)  not null,
   eventtype_uuid  varchar(40)  not null references event_types(uuid)
-) inherits(progenitors, notables, photoables);
+) inherits(progenitors, notables, photoables, taggables);
 
+-- a batch photo is one file shared by every member's latest event, so a
+-- filename is only unique per photoable
 create table photos (
   uuid           varchar(40) not null primary key,
-  filename       varchar(45) not null unique,
-  photoable_uuid varchar(40) not null
-) inherits(notables);
+  filename       varchar(45) not null,
+  photoable_uuid varchar(40) not null,
+  unique(photoable_uuid, filename)
+) inherits(notables, taggables);
 
 create table generations (
   uuid                  varchar(40) not null primary key,
   platingsubstrate_uuid varchar(40) not null references substrates(uuid),
   liquidsubstrate_uuid  varchar(40) not null references substrates(uuid)
-) inherits(observables, notables);
+) inherits(observables, notables, taggables);
 
 alter table strains add generation_uuid varchar(40) null references generations(uuid) unique;
+-- see types.NewFiliation, these are only ever set when a strain is promoted
+alter table strains add filial_depth int not null default 0;
+alter table strains add filial_notation varchar(512) not null default 'P';
+alter table strains add root_strain_uuid varchar(40) null references strains(uuid);
+
+-- a generation can have up to max_sources of one type, and can only mix
+-- types that are all mixable; a clonal type copies its parent instead of
+-- starting a new filial generation, see types.SourceType
+create table source_types (
+  name        varchar(40) not null primary key,
+  max_sources int         not null check (max_sources > 0),
+  mixable     boolean     not null default false,
+  clonal      boolean     not null default false
+);
 
 create table sources (
   uuid            varchar(40) not null primary key,
-  type            varchar(8)  not null check (type in ('Clone', 'Spore')),
+  type            varchar(40) not null references source_types(name),
   progenitor_uuid varchar(40) not null /*references progenitors(uuid)*/,
   generation_uuid varchar(40) not null references generations(uuid),
   unique(progenitor_uuid, generation_uuid)
@@ -172,6 +271,131 @@ create table notes (
   notable_uuid varchar(40) not null
 ) inherits(uuids);
 
+-- tags are lowercase, and can only be added to something taggable; they go
+-- away with whatever they're on
+create table tags (
+  taggable_uuid varchar(40)  not null,
+  tag           varchar(128) not null,
+  primary key (taggable_uuid, tag)
+);
+
+create index tags_by_tag on tags(tag);
+
+-- something to check on days after an event of eventtype_uuid; how the check
+-- went is usually recorded as an event of expects_uuid
+create table follow_ups (
+  uuid           varchar(40)  not null primary key,
+  name           varchar(512) not null,
+  days           int          not null check (days >= 0),
+  eventtype_uuid varchar(40)  not null references event_types(uuid),
+  expects_uuid   varchar(40)  null references event_types(uuid),
+  unique(name, eventtype_uuid)
+) inherits(uuids);
+
+-- one follow-up owed by the observable that recorded event_uuid; these are
+-- only ever created by the EventTasks triggers below
+create table tasks (
+  uuid            varchar(40) not null primary key,
+  followup_uuid   varchar(40) not null references follow_ups(uuid) on delete cascade,
+  observable_uuid varchar(40) not null,
+  event_uuid      varchar(40) not null references events(uuid) on delete cascade,
+  due             timestamp   not null,
+  done            timestamp   null,
+  result_uuid     varchar(40) null references events(uuid) on delete set null,
+  unique(followup_uuid, event_uuid)
+) inherits(uuids);
+
+create index tasks_by_due on tasks(due) where done is null;
+
+-- an extra measurement that events of eventtype_uuid can record, like a colony
+-- diameter; the bounds are inclusive, and only numbers and integers have them
+create table eventtype_fields (
+  uuid           varchar(40) not null primary key,
+  name           varchar(64) not null,
+  type           varchar(8)  not null check (type in ('number', 'integer', 'text', 'boolean')),
+  unit           varchar(32) not null default '',
+  min            float8      null,
+  max            float8      null,
+  eventtype_uuid varchar(40) not null references event_types(uuid) on delete cascade,
+  unique(name, eventtype_uuid)
+) inherits(uuids);
+
+-- one event's value for one of its event type's fields, as json so it keeps
+-- its type
+create table measurements (
+  event_uuid varchar(40) not null references events(uuid) on delete cascade,
+  field_uuid varchar(40) not null references eventtype_fields(uuid) on delete cascade,
+  value      jsonb       not null,
+  primary key (event_uuid, field_uuid)
+);
+
+-- the plan a lifecycle or generation is expected to follow
+create table protocols (
+  uuid varchar(40)  not null primary key,
+  name varchar(512) not null unique
+) inherits(uuids);
+
+-- an event of eventtype_uuid is due day days after the protocol's first step,
+-- give or take tolerance days; steps are ordered by day
+create table protocol_steps (
+  uuid           varchar(40) not null primary key,
+  protocol_uuid  varchar(40) not null references protocols(uuid) on delete cascade,
+  eventtype_uuid varchar(40) not null references event_types(uuid),
+  day            int         not null check (day >= 0),
+  tolerance      int         not null default 0 check (tolerance >= 0)
+) inherits(uuids);
+
+-- an observable follows at most one protocol at a time
+create table protocol_assignments (
+  observable_uuid varchar(40) not null primary key,
+  protocol_uuid   varchar(40) not null references protocols(uuid)
+);
+
+-- lifecycles started together from one template, like a run of grain jars
+-- from one syringe
+create table batches (
+  uuid varchar(40)  not null primary key,
+  name varchar(512) not null unique
+) inherits(uuids);
+
+-- a lifecycle belongs to at most one batch; excluded is when it was taken out
+-- of batch-wide events, notes and photos by hand
+create table batch_members (
+  batch_uuid     varchar(40) not null references batches(uuid) on delete cascade,
+  lifecycle_uuid varchar(40) not null primary key references lifecycles(uuid) on delete cascade,
+  excluded       timestamp   null
+);
+
+-- an experiment tests one variable, like a new bulk recipe, by comparing
+-- arms of lifecycles that differ in it
+create table experiments (
+  uuid     varchar(40)  not null primary key,
+  name     varchar(512) not null unique,
+  variable varchar(512) not null
+) inherits(uuids);
+
+-- the control arm is what the others are compared to; there's at most one
+create table experiment_arms (
+  uuid            varchar(40)  not null primary key,
+  experiment_uuid varchar(40)  not null references experiments(uuid) on delete cascade,
+  name            varchar(512) not null,
+  control         boolean      not null default false,
+  unique(experiment_uuid, name),
+  unique(experiment_uuid, uuid)
+) inherits(uuids);
+
+create unique index experiment_arms_control on experiment_arms(experiment_uuid) where control;
+
+-- a lifecycle is in at most one arm of an experiment, but it can be in any
+-- number of experiments
+create table experiment_lifecycles (
+  experiment_uuid varchar(40) not null,
+  arm_uuid        varchar(40) not null,
+  lifecycle_uuid  varchar(40) not null references lifecycles(uuid) on delete cascade,
+  primary key (experiment_uuid, lifecycle_uuid),
+  foreign key (experiment_uuid, arm_uuid) references experiment_arms(experiment_uuid, uuid) on delete cascade
+);
+
 begin; /** progenitor constraints */
   create function progenitordelete()
   returns trigger
@@ -246,6 +470,12 @@ begin; /** notable constraints */
       for each row
   execute function notabledelete();
 
+  create trigger HarvestNotableDelete
+    before delete
+        on harvests
+      for each row
+  execute function notabledelete();
+
   create trigger EventNotableDelete
     before delete
         on events
@@ -292,6 +522,56 @@ begin; /** photoable constraints */
    execute  function photoabledelete();
 end;
 
+begin; /** taggable constraints */
+  -- unlike notes and photos, tags don't keep anything from being deleted
+  create function taggabledelete()
+  returns trigger
+  language plpgsql
+  as
+  $$
+  begin
+    delete from tags t where t.taggable_uuid = old.uuid;
+    return old;
+  end
+  $$;
+
+  create trigger LifecycleTaggableDelete
+    before delete
+        on lifecycles
+      for each row
+  execute function taggabledelete();
+
+  create trigger GenerationTaggableDelete
+    before delete
+        on generations
+      for each row
+  execute function taggabledelete();
+
+  create trigger StrainTaggableDelete
+    before delete
+        on strains
+      for each row
+  execute function taggabledelete();
+
+  create trigger SubstrateTaggableDelete
+    before delete
+        on substrates
+      for each row
+  execute function taggabledelete();
+
+  create trigger EventTaggableDelete
+    before delete
+        on events
+      for each row
+  execute function taggabledelete();
+
+  create trigger PhotoTaggableDelete
+    before delete
+        on photos
+      for each row
+  execute function taggabledelete();
+end;
+
 begin; /** source constraints */
   create function sourcechange() 
   returns trigger
@@ -303,27 +583,27 @@ begin; /** source constraints */
     elsif exists (
       select  1
         from  sources s
+        join  source_types st
+          on  s.type = st.name
+        join  source_types nt
+          on  nt.name = new.type
        where  s.generation_uuid = new.generation_uuid
          and  s.type != new.type
          and  s.uuid != new.uuid
+         and  not (st.mixable and nt.mixable)
     ) then
       raise exception 'source types can''t be mixed';
     elsif exists (
       select  1
-        from  sources s
-        join  (
-                select  2 as cap,
-                        'Spore' as type
-                union
-                select  1,
-                        'Clone'
-              ) limits
-          on  s.type = limits.type
-       where  s.generation_uuid = new.generation_uuid
-         and  s.uuid != new.uuid
-       group
-          by  limits.cap
-      having  limits.cap = count(s.uuid)
+        from  source_types st
+       where  st.name = new.type
+         and  st.max_sources <= (
+                select  count(s.uuid)
+                  from  sources s
+                 where  s.generation_uuid = new.generation_uuid
+                   and  s.type = new.type
+                   and  s.uuid != new.uuid
+              )
     ) then
       raise exception 'too many sources for this generation';
     elsif exists (
@@ -368,6 +648,30 @@ begin; /** event constraints */
       on  events
      for  each row
  execute function eventchange();
+
+  create function eventoccurred()
+  returns  trigger
+      as
+  $$
+  begin
+    if exists (
+      select  1
+        from  observables o
+       where  o.uuid = new.observable_uuid
+         and  o.ctime > new.occurred_at
+    ) then
+      raise exception 'event can''t have occurred before its observable began';
+    end if;
+    return new;
+  end
+  $$
+  language plpgsql;
+
+  create trigger CheckOccurredAt
+  before  insert or update of occurred_at, observable_uuid
+      on  events
+     for  each row
+ execute function eventoccurred();
 end;
 
 begin; /** note constraints */
@@ -411,4 +715,80 @@ begin; /** photo constraints */
         on photos
        for each row
    execute function photochange();
-end;
\ No newline at end of file
+end;
+
+begin; /** task generation */
+  -- every event owes whatever follow-ups its event type has, due that many
+  -- days after the event; the uuid is derived so the same follow-up is never
+  -- owed twice for one event
+  create function eventtasks()
+  returns trigger
+  language plpgsql
+  as
+  $$
+  begin
+    if tg_op = 'UPDATE' then
+      delete from tasks t where t.event_uuid = new.uuid and t.done is null;
+    end if;
+
+    insert into tasks(uuid, followup_uuid, observable_uuid, event_uuid, due)
+    select md5(new.uuid || f.uuid)::uuid::varchar
+          ,f.uuid
+          ,new.observable_uuid
+          ,new.uuid
+          ,new.occurred_at + f.days * interval '1 day'
+      from follow_ups f
+     where f.eventtype_uuid = new.eventtype_uuid
+        on conflict do nothing;
+
+    return new;
+  end
+  $$;
+
+  create trigger EventTasks
+    after insert
+       on events
+      for each row
+  execute function eventtasks();
+
+  -- a changed event type swaps out the tasks that haven't been done yet, and
+  -- a changed occurred_at moves them
+  create trigger EventTypeTasks
+    after update of eventtype_uuid, occurred_at
+       on events
+      for each row
+     when (old.eventtype_uuid is distinct from new.eventtype_uuid
+        or old.occurred_at is distinct from new.occurred_at)
+  execute function eventtasks();
+end;
+
+begin; /** protocol assignment constraints */
+  create trigger CheckAssignedObservable
+  before  insert or update of observable_uuid
+      on  protocol_assignments
+     for  each row
+ execute function eventchange();
+
+  create function protocolunassign()
+  returns trigger
+  language plpgsql
+  as
+  $$
+  begin
+    delete from protocol_assignments a where a.observable_uuid = old.uuid;
+    return old;
+  end
+  $$;
+
+  create trigger LifecycleProtocolDelete
+    after delete
+       on lifecycles
+      for each row
+  execute function protocolunassign();
+
+  create trigger GenerationProtocolDelete
+    after delete
+       on generations
+      for each row
+  execute function protocolunassign();
+end;
@@ -0,0 +1,68 @@
+-- run this once against a database created before strain attributes were
+-- checked against definitions; every name that's already in use becomes a
+-- text attribute, so nothing stops working, and it can be changed or merged
+-- into a better definition from there. Names that only differ by case or
+-- surrounding whitespace become one definition, spelled the way most strains
+-- spell it.
+--
+-- This DELETES strain attributes, so look before running it:
+--   - every one with an empty value, since no definition accepts one
+--       select * from strain_attributes where trim(value) = '';
+--   - all but one value of a strain that has more than one spelling of the
+--     same name; the value under the definition's spelling is the one kept
+--       select strain_uuid, lower(trim(name)), count(*)
+--         from strain_attributes
+--        group by strain_uuid, lower(trim(name))
+--       having count(*) > 1;
+
+\c huautla
+
+begin;
+  create table attribute_definitions (
+    uuid    varchar(40)    not null primary key,
+    name    varchar(40)    not null unique,
+    type    varchar(8)     not null check (type in ('number', 'enum', 'boolean', 'text')),
+    unit    varchar(32)    not null default '',
+    allowed varchar(512)[] not null default '{}'
+  ) inherits(uuids);
+
+  create unique index attribute_definitions_by_name on attribute_definitions(lower(trim(name)));
+
+  create table attribute_synonyms (
+    synonym         varchar(40) not null primary key,
+    definition_uuid varchar(40) not null references attribute_definitions(uuid) on delete cascade
+  );
+
+  delete from strain_attributes
+   where trim(value) = '';
+
+  insert into attribute_definitions(uuid, name, type)
+  select gen_random_uuid()::varchar, n.name, 'text'
+    from (
+  select distinct on (lower(trim(name))) trim(name) as name
+    from strain_attributes
+   group by trim(name)
+   order by lower(trim(name)), count(*) desc, trim(name)) n;
+
+  delete from strain_attributes
+   where uuid in (
+  select r.uuid
+    from (
+  select sa.uuid,
+         row_number() over (
+           partition by sa.strain_uuid, d.uuid
+           order by sa.name = d.name desc, sa.name) as n
+    from strain_attributes sa
+    join attribute_definitions d
+      on lower(trim(sa.name)) = lower(d.name)) r
+   where r.n > 1);
+
+  update strain_attributes sa
+     set name = d.name
+    from attribute_definitions d
+   where lower(trim(sa.name)) = lower(d.name)
+     and sa.name <> d.name;
+
+  alter table strain_attributes
+    add foreign key (name) references attribute_definitions(name) on update cascade;
+commit;
@@ -0,0 +1,35 @@
+-- run this against a database created before batches; it adds the tables,
+-- and lets one photo file be shared by every member of a batch, so a filename
+-- only has to be unique for its photoable
+--
+-- it's safe to run more than once
+
+\c huautla
+
+begin;
+  create table if not exists batches (
+    uuid varchar(40)  not null primary key,
+    name varchar(512) not null unique
+  ) inherits(uuids);
+
+  create table if not exists batch_members (
+    batch_uuid     varchar(40) not null references batches(uuid) on delete cascade,
+    lifecycle_uuid varchar(40) not null primary key references lifecycles(uuid) on delete cascade,
+    excluded       timestamp   null
+  );
+
+  alter table photos drop constraint if exists photos_filename_key;
+
+  do
+  $$
+  begin
+    if not exists (
+      select  1
+        from  pg_constraint
+       where  conname = 'photos_photoable_uuid_filename_key'
+    ) then
+      alter table photos add unique(photoable_uuid, filename);
+    end if;
+  end
+  $$;
+commit;
@@ -0,0 +1,33 @@
+-- run this against a database created before experiments; it only adds
+-- tables, so nothing that's already there changes
+--
+-- it's safe to run more than once
+
+\c huautla
+
+begin;
+  create table if not exists experiments (
+    uuid     varchar(40)  not null primary key,
+    name     varchar(512) not null unique,
+    variable varchar(512) not null
+  ) inherits(uuids);
+
+  create table if not exists experiment_arms (
+    uuid            varchar(40)  not null primary key,
+    experiment_uuid varchar(40)  not null references experiments(uuid) on delete cascade,
+    name            varchar(512) not null,
+    control         boolean      not null default false,
+    unique(experiment_uuid, name),
+    unique(experiment_uuid, uuid)
+  ) inherits(uuids);
+
+  create unique index if not exists experiment_arms_control on experiment_arms(experiment_uuid) where control;
+
+  create table if not exists experiment_lifecycles (
+    experiment_uuid varchar(40) not null,
+    arm_uuid        varchar(40) not null,
+    lifecycle_uuid  varchar(40) not null references lifecycles(uuid) on delete cascade,
+    primary key (experiment_uuid, lifecycle_uuid),
+    foreign key (experiment_uuid, arm_uuid) references experiment_arms(experiment_uuid, uuid) on delete cascade
+  );
+commit;
@@ -0,0 +1,85 @@
+-- run this once against a database created before strains had a filiation;
+-- every strain starts out as a vendor original, the same way init.sql does
+-- for a new database, then every promoted strain is worked out from the
+-- strains its generation's sources came from, the same way
+-- types.NewFiliation does, one level at a time
+
+\c huautla
+
+begin;
+  alter table strains add filial_depth int not null default 0;
+  alter table strains add filial_notation varchar(512) not null default 'P';
+  alter table strains add root_strain_uuid varchar(40) null references strains(uuid);
+
+  create temporary table unfiliated on commit drop as
+  select  s.uuid
+    from  strains s
+   where  exists (select 1 from sources src where src.generation_uuid = s.generation_uuid);
+
+  do
+  $$
+  declare
+    n int;
+  begin
+    loop
+      with parents as (
+        select  c.uuid as child_uuid,
+                s.type,
+                s.ctime,
+                s.uuid as source_uuid,
+                p.uuid,
+                p.filial_depth,
+                p.filial_notation,
+                p.root_strain_uuid
+          from  strains c
+          join  sources s
+            on  s.generation_uuid = c.generation_uuid
+          left
+          join  events e
+            on  s.progenitor_uuid = e.uuid
+          left
+          join  lifecycles lc
+            on  e.observable_uuid = lc.uuid
+          join  strains p
+            on  p.uuid = coalesce(lc.strain_uuid, s.progenitor_uuid)
+         where  c.uuid in (select uuid from unfiliated)
+      ), ready as (
+        -- nothing can be worked out until all of its parents have been
+        select  child_uuid
+          from  parents
+         group
+            by  child_uuid
+        having  bool_and(uuid not in (select uuid from unfiliated))
+      ), deepest as (
+        select  distinct on (p.child_uuid)
+                p.*,
+                bool_or(p.type <> 'Clone') over (partition by p.child_uuid) as spore
+          from  parents p
+          join  ready r
+         using  (child_uuid)
+         order
+            by  p.child_uuid, p.filial_depth desc, p.ctime, p.source_uuid
+      ), filiated as (
+        update  strains c
+           set  filial_depth = d.filial_depth + 1,
+                filial_notation = case
+                  when d.spore then 'F' || (coalesce(substring(d.filial_notation from 'F(\d+)$'), '0')::int + 1)
+                  else 'clone of ' || d.filial_notation
+                end,
+                root_strain_uuid = coalesce(d.root_strain_uuid, d.uuid)
+          from  deepest d
+         where  c.uuid = d.child_uuid
+     returning  c.uuid
+      )
+      delete
+        from  unfiliated u
+       using  filiated f
+       where  u.uuid = f.uuid;
+
+      -- a loop in the data never gets ready, so it's left as an original
+      get diagnostics n = row_count;
+      exit when n = 0;
+    end loop;
+  end
+  $$;
+commit;
@@ -0,0 +1,25 @@
+-- run this against a database created before harvests; it only adds the
+-- table, so existing lifecycles keep the yield, headcount and gross they were
+-- given by hand until their first flush is recorded
+--
+-- it's safe to run more than once, and migrate-units.sql runs it first
+
+\c huautla
+
+begin;
+  create table if not exists harvests (
+    uuid           varchar(40)  not null primary key,
+    harvest_date   timestamp    not null default current_timestamp,
+    fresh_weight   decimal(8,2) not null default 0,
+    dry_weight     decimal(8,2) not null default 0,
+    headcount      decimal(6)   not null default 0,
+    grade          varchar(40)  not null default '',
+    lifecycle_uuid varchar(40)  not null references lifecycles(uuid)
+  ) inherits(notables);
+
+  create or replace trigger HarvestNotableDelete
+    before delete
+        on harvests
+      for each row
+  execute function notabledelete();
+commit;
@@ -0,0 +1,64 @@
+-- run this once against a database created before locations were their own
+-- table; it turns the free-text lifecycles.location into rows in locations,
+-- points each lifecycle at one and then drops the old column
+--
+-- values that only differ by case or surrounding whitespace become the same
+-- location. Every location starts out as a 'fruiting chamber' with no capacity
+-- and no target environment, so review them afterwards (e.g. with
+-- `huautla location list` and `huautla location update`)
+
+-- readings are pointed at their locations too, so they have to exist
+\ir migrate-readings.sql
+
+\c huautla
+
+begin;
+  create table locations (
+    uuid            varchar(40)  not null primary key,
+    name            varchar(128) not null unique,
+    kind            varchar(20)  not null check (kind in ('incubator', 'fruiting chamber', 'outdoor bed')),
+    capacity        int          not null default 0 check (capacity >= 0),
+    min_temperature numeric(4,1) null,
+    max_temperature numeric(4,1) null,
+    min_humidity    numeric(5,2) null,
+    max_humidity    numeric(5,2) null,
+    max_co2         numeric(7,1) null
+  ) inherits(uuids);
+
+  insert into locations(uuid, name, kind)
+  select gen_random_uuid()::varchar, min(trim(location)), 'fruiting chamber'
+    from lifecycles
+   group by lower(trim(location));
+
+  -- anything a sensor reported that doesn't match a name above, by the same
+  -- rules, gets a location of its own
+  insert into locations(uuid, name, kind)
+  select gen_random_uuid()::varchar, min(trim(r.location)), 'fruiting chamber'
+    from readings r
+   where not exists (select 1 from locations l where lower(trim(l.name)) = lower(trim(r.location)))
+   group by lower(trim(r.location));
+
+  create unique index locations_by_name on locations(lower(trim(name)));
+
+  alter table lifecycles add column location_uuid varchar(40) null references locations(uuid);
+
+  update lifecycles lc
+     set location_uuid = l.uuid
+    from locations l
+   where lower(trim(lc.location)) = lower(trim(l.name));
+
+  alter table lifecycles alter column location_uuid set not null;
+  alter table lifecycles drop constraint lifecycles_location_ctime_key;
+  alter table lifecycles drop column location;
+  alter table lifecycles add unique(location_uuid, ctime);
+
+  -- the foreign key wants the name exactly as the location spells it
+  update readings r
+     set location = l.name
+    from locations l
+   where lower(trim(r.location)) = lower(trim(l.name))
+     and r.location <> l.name;
+
+  alter table readings
+    add foreign key (location) references locations(name) on update cascade;
+commit;
@@ -0,0 +1,24 @@
+-- run this once against a database created before event types had fields;
+-- it only adds tables, so existing events are left as they were
+
+\c huautla
+
+begin;
+  create table eventtype_fields (
+    uuid           varchar(40) not null primary key,
+    name           varchar(64) not null,
+    type           varchar(8)  not null check (type in ('number', 'integer', 'text', 'boolean')),
+    unit           varchar(32) not null default '',
+    min            float8      null,
+    max            float8      null,
+    eventtype_uuid varchar(40) not null references event_types(uuid) on delete cascade,
+    unique(name, eventtype_uuid)
+  ) inherits(uuids);
+
+  create table measurements (
+    event_uuid varchar(40) not null references events(uuid) on delete cascade,
+    field_uuid varchar(40) not null references eventtype_fields(uuid) on delete cascade,
+    value      jsonb       not null,
+    primary key (event_uuid, field_uuid)
+  );
+commit;
@@ -0,0 +1,74 @@
+-- run this once against a database created before events had an occurred_at;
+-- every existing event is taken to have happened when it was recorded, and
+-- follow-up tasks are due from occurred_at from now on, the same way init.sql
+-- does for a new database
+
+-- the task triggers are replaced below, so they have to exist
+\ir migrate-tasks.sql
+
+\c huautla
+
+begin;
+  alter table events add column occurred_at timestamp;
+  update events set occurred_at = ctime;
+  alter table events alter column occurred_at set not null;
+  alter table events alter column occurred_at set default current_timestamp;
+
+  create function eventoccurred()
+  returns  trigger
+      as
+  $$
+  begin
+    if exists (
+      select  1
+        from  observables o
+       where  o.uuid = new.observable_uuid
+         and  o.ctime > new.occurred_at
+    ) then
+      raise exception 'event can''t have occurred before its observable began';
+    end if;
+    return new;
+  end
+  $$
+  language plpgsql;
+
+  create trigger CheckOccurredAt
+  before  insert or update of occurred_at, observable_uuid
+      on  events
+     for  each row
+ execute function eventoccurred();
+
+  create or replace function eventtasks()
+  returns trigger
+  language plpgsql
+  as
+  $$
+  begin
+    if tg_op = 'UPDATE' then
+      delete from tasks t where t.event_uuid = new.uuid and t.done is null;
+    end if;
+
+    insert into tasks(uuid, followup_uuid, observable_uuid, event_uuid, due)
+    select md5(new.uuid || f.uuid)::uuid::varchar
+          ,f.uuid
+          ,new.observable_uuid
+          ,new.uuid
+          ,new.occurred_at + f.days * interval '1 day'
+      from follow_ups f
+     where f.eventtype_uuid = new.eventtype_uuid
+        on conflict do nothing;
+
+    return new;
+  end
+  $$;
+
+  drop trigger EventTypeTasks on events;
+
+  create trigger EventTypeTasks
+    after update of eventtype_uuid, occurred_at
+       on events
+      for each row
+     when (old.eventtype_uuid is distinct from new.eventtype_uuid
+        or old.occurred_at is distinct from new.occurred_at)
+  execute function eventtasks();
+commit;
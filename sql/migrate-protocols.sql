@@ -0,0 +1,56 @@
+-- run this against a database created before protocols; it only adds tables
+-- and the triggers that keep assignments pointed at real observables, so
+-- nothing follows a protocol until it's assigned one
+--
+-- it's safe to run more than once
+
+\c huautla
+
+begin;
+  create table if not exists protocols (
+    uuid varchar(40)  not null primary key,
+    name varchar(512) not null unique
+  ) inherits(uuids);
+
+  create table if not exists protocol_steps (
+    uuid           varchar(40) not null primary key,
+    protocol_uuid  varchar(40) not null references protocols(uuid) on delete cascade,
+    eventtype_uuid varchar(40) not null references event_types(uuid),
+    day            int         not null check (day >= 0),
+    tolerance      int         not null default 0 check (tolerance >= 0)
+  ) inherits(uuids);
+
+  create table if not exists protocol_assignments (
+    observable_uuid varchar(40) not null primary key,
+    protocol_uuid   varchar(40) not null references protocols(uuid)
+  );
+
+  create or replace trigger CheckAssignedObservable
+  before  insert or update of observable_uuid
+      on  protocol_assignments
+     for  each row
+ execute function eventchange();
+
+  create or replace function protocolunassign()
+  returns trigger
+  language plpgsql
+  as
+  $$
+  begin
+    delete from protocol_assignments a where a.observable_uuid = old.uuid;
+    return old;
+  end
+  $$;
+
+  create or replace trigger LifecycleProtocolDelete
+    after delete
+       on lifecycles
+      for each row
+  execute function protocolunassign();
+
+  create or replace trigger GenerationProtocolDelete
+    after delete
+       on generations
+      for each row
+  execute function protocolunassign();
+commit;
@@ -0,0 +1,22 @@
+-- run this against a database created before sensor readings; it only adds
+-- the table, so nothing that's already there changes
+--
+-- it's safe to run more than once, and migrate-locations.sql runs it first
+
+\c huautla
+
+begin;
+  create table if not exists readings (
+    sensor         varchar(128) not null,
+    location       varchar(128) not null,
+    lifecycle_uuid varchar(40)  null references lifecycles(uuid),
+    read_at        timestamp    not null,
+    temperature    numeric(5,2) null,
+    humidity       numeric(5,2) null,
+    co2            numeric(7,1) null,
+    light          numeric(9,1) null,
+    primary key (sensor, read_at)
+  );
+
+  create index if not exists readings_by_location on readings(location, read_at);
+commit;
@@ -0,0 +1,75 @@
+-- run this once against a database created before source types were a table;
+-- Spore and Clone keep working the way they always did, the other types are
+-- the same ones seed.sql adds to a new database, and the sourcechange trigger
+-- is replaced with the one in init.sql that reads its limits from the table
+
+\c huautla
+
+begin;
+  create table source_types (
+    name        varchar(40) not null primary key,
+    max_sources int         not null check (max_sources > 0),
+    mixable     boolean     not null default false,
+    clonal      boolean     not null default false
+  );
+
+  insert into source_types(name, max_sources, mixable, clonal)
+  values('Spore', 2, false, false),
+        ('Clone', 1, false, true),
+        ('Tissue', 1, false, true),
+        ('Sector', 1, false, true),
+        ('LC', 1, false, true);
+
+  alter table sources drop constraint sources_type_check;
+  alter table sources alter column type type varchar(40);
+  alter table sources add foreign key (type) references source_types(name);
+
+  create or replace function sourcechange()
+  returns trigger
+      as
+  $$
+  begin
+    if not exists (select 1 from progenitors p where p.uuid = new.progenitor_uuid) then
+      raise exception 'no existing progenitor';
+    elsif exists (
+      select  1
+        from  sources s
+        join  source_types st
+          on  s.type = st.name
+        join  source_types nt
+          on  nt.name = new.type
+       where  s.generation_uuid = new.generation_uuid
+         and  s.type != new.type
+         and  s.uuid != new.uuid
+         and  not (st.mixable and nt.mixable)
+    ) then
+      raise exception 'source types can''t be mixed';
+    elsif exists (
+      select  1
+        from  source_types st
+       where  st.name = new.type
+         and  st.max_sources <= (
+                select  count(s.uuid)
+                  from  sources s
+                 where  s.generation_uuid = new.generation_uuid
+                   and  s.type = new.type
+                   and  s.uuid != new.uuid
+              )
+    ) then
+      raise exception 'too many sources for this generation';
+    elsif exists (
+      select  1
+        from  events e
+        join  event_types t
+          on  e.eventtype_uuid = t.uuid
+       where  e.uuid = new.progenitor_uuid
+         and  t.severity != 'Generation'
+    ) then
+      raise exception 'event is not a generation type';
+    end if;
+    return new;
+  end
+  $$
+  language plpgsql
+  ;
+commit;
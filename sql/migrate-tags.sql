@@ -0,0 +1,80 @@
+-- run this once against a database created before tags; it makes lifecycles,
+-- generations, strains, substrates, events and photos taggable, the same way
+-- init.sql does for a new database
+
+\c huautla
+
+begin;
+  create table taggables() inherits(uuids);
+
+  create trigger TaggableInserter
+  before insert
+      on taggables
+    for each statement
+  execute function noinsert();
+
+  -- substrates inherited uuids directly, and taggables already does that
+  alter table substrates no inherit uuids;
+
+  alter table substrates inherit taggables;
+  alter table lifecycles inherit taggables;
+  alter table generations inherit taggables;
+  alter table strains inherit taggables;
+  alter table events inherit taggables;
+  alter table photos inherit taggables;
+
+  create table tags (
+    taggable_uuid varchar(40)  not null,
+    tag           varchar(128) not null,
+    primary key (taggable_uuid, tag)
+  );
+
+  create index tags_by_tag on tags(tag);
+
+  create function taggabledelete()
+  returns trigger
+  language plpgsql
+  as
+  $$
+  begin
+    delete from tags t where t.taggable_uuid = old.uuid;
+    return old;
+  end
+  $$;
+
+  create trigger LifecycleTaggableDelete
+    before delete
+        on lifecycles
+      for each row
+  execute function taggabledelete();
+
+  create trigger GenerationTaggableDelete
+    before delete
+        on generations
+      for each row
+  execute function taggabledelete();
+
+  create trigger StrainTaggableDelete
+    before delete
+        on strains
+      for each row
+  execute function taggabledelete();
+
+  create trigger SubstrateTaggableDelete
+    before delete
+        on substrates
+      for each row
+  execute function taggabledelete();
+
+  create trigger EventTaggableDelete
+    before delete
+        on events
+      for each row
+  execute function taggabledelete();
+
+  create trigger PhotoTaggableDelete
+    before delete
+        on photos
+      for each row
+  execute function taggabledelete();
+commit;
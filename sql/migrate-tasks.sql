@@ -0,0 +1,79 @@
+-- run this against a database created before follow-ups; it adds the tables
+-- and the triggers that owe tasks, so only events recorded from now on owe
+-- any, and existing events are left as they were
+--
+-- it's safe to run more than once, and migrate-occurred-at.sql runs it first;
+-- the triggers are only created when eventtasks doesn't exist yet, so running
+-- this again never undoes the version migrate-occurred-at.sql replaces it with
+
+\c huautla
+
+begin;
+  create table if not exists follow_ups (
+    uuid           varchar(40)  not null primary key,
+    name           varchar(512) not null,
+    days           int          not null check (days >= 0),
+    eventtype_uuid varchar(40)  not null references event_types(uuid),
+    expects_uuid   varchar(40)  null references event_types(uuid),
+    unique(name, eventtype_uuid)
+  ) inherits(uuids);
+
+  create table if not exists tasks (
+    uuid            varchar(40) not null primary key,
+    followup_uuid   varchar(40) not null references follow_ups(uuid) on delete cascade,
+    observable_uuid varchar(40) not null,
+    event_uuid      varchar(40) not null references events(uuid) on delete cascade,
+    due             timestamp   not null,
+    done            timestamp   null,
+    result_uuid     varchar(40) null references events(uuid) on delete set null,
+    unique(followup_uuid, event_uuid)
+  ) inherits(uuids);
+
+  create index if not exists tasks_by_due on tasks(due) where done is null;
+
+  do
+  $$
+  begin
+    if to_regprocedure('eventtasks()') is not null then
+      return;
+    end if;
+
+    create function eventtasks()
+    returns trigger
+    language plpgsql
+    as
+    $fn$
+    begin
+      if tg_op = 'UPDATE' then
+        delete from tasks t where t.event_uuid = new.uuid and t.done is null;
+      end if;
+
+      insert into tasks(uuid, followup_uuid, observable_uuid, event_uuid, due)
+      select md5(new.uuid || f.uuid)::uuid::varchar
+            ,f.uuid
+            ,new.observable_uuid
+            ,new.uuid
+            ,new.ctime + f.days * interval '1 day'
+        from follow_ups f
+       where f.eventtype_uuid = new.eventtype_uuid
+          on conflict do nothing;
+
+      return new;
+    end
+    $fn$;
+
+    create trigger EventTasks
+      after insert
+         on events
+        for each row
+    execute function eventtasks();
+
+    create trigger EventTypeTasks
+      after update of eventtype_uuid
+         on events
+        for each row
+       when (old.eventtype_uuid is distinct from new.eventtype_uuid)
+    execute function eventtasks();
+  end
+  $$;
+commit;
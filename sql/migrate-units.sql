@@ -0,0 +1,39 @@
+-- run this once against a database created before measurements had units;
+-- everything is stored in metric now (celsius and grams), so rows that were
+-- recorded in fahrenheit and ounces have to be converted in place
+--
+-- there's no way to tell what units an old row was recorded in, so the whole
+-- database is taken to be in the declared default, e.g.:
+--
+--   psql -v units=imperial -f sql/migrate-units.sql
+--
+-- anything other than 'imperial' (including leaving it out) declares the rows
+-- metric already and changes nothing. Readings and location targets were
+-- always celsius, so they're left alone
+
+-- harvest weights are converted too, so the table has to exist
+\ir migrate-harvests.sql
+
+\c huautla
+
+\if :{?units}
+\else
+  \set units metric
+\endif
+
+-- running it twice converts twice, so don't
+begin;
+  update events
+     set temperature = round((temperature - 32) * 5 / 9, 1)
+   where :'units' = 'imperial';
+
+  update lifecycles
+     set yield = round(yield * 28.349523125, 2),
+         gross = round(gross * 28.349523125, 2)
+   where :'units' = 'imperial';
+
+  update harvests
+     set fresh_weight = round(fresh_weight * 28.349523125, 2),
+         dry_weight   = round(dry_weight * 28.349523125, 2)
+   where :'units' = 'imperial';
+commit;
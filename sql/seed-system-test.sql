@@ -57,7 +57,36 @@ values('0', 'M.esculenta', 'Morel', 'localhost'),
       ('change attribute', '', 'change attribute', 'localhost'),
       ('remove attribute', '', 'remove attribute', 'localhost'),
       ('update me!', '', 'update me!', 'localhost'),
-      ('delete me!', '', 'delete me!', 'localhost');
+      ('delete me!', '', 'delete me!', 'localhost'),
+      ('lineage 0', 'X.test', 'lineage grandparent', 'localhost'),
+      ('lineage 1', 'X.test', 'lineage parent', 'localhost'),
+      ('lineage 2', 'X.test', 'lineage child', 'localhost'),
+      ('filial parent', 'X.test', 'filial parent', 'localhost'),
+      ('filial', 'X.test', 'filial', 'localhost'),
+      ('promote parent 0', 'X.test', 'promote parent 0', 'localhost'),
+      ('promote parent 1', 'X.test', 'promote parent 1', 'localhost'),
+      ('filter attribute', 'X.test', 'filter attribute', 'localhost'),
+      ('merge attribute 0', 'X.test', 'merge attribute 0', 'localhost'),
+      ('merge attribute 1', 'X.test', 'merge attribute 1', 'localhost');
+
+insert into attribute_definitions(uuid, name, type, unit, allowed)
+values('contamination resistance', 'contamination resistance', 'enum', '', '{low,medium,high}'),
+      ('headroom (cm)', 'headroom (cm)', 'number', 'cm', '{}'),
+      ('color', 'color', 'text', '', '{}'),
+      ('existing', 'existing', 'text', '', '{}'),
+      ('energy', 'energy', 'text', '', '{}'),
+      ('preferred substrate', 'preferred substrate', 'text', '', '{}'),
+      ('vigor', 'vigor', 'enum', '', '{low,medium,high}'),
+      ('new name', 'new name', 'text', '', '{}'),
+      ('rhizomorphic', 'rhizomorphic', 'boolean', '', '{}'),
+      ('change me!', 'change me!', 'enum', '', '{small,large}'),
+      ('delete me!', 'delete me!', 'text', '', '{}'),
+      ('merge from', 'merge from', 'text', '', '{}'),
+      ('merge into', 'merge into', 'number', 'g', '{}');
+
+insert into attribute_synonyms(synonym, definition_uuid)
+values('colour', 'color'),
+      ('remove me!', 'energy');
 
 insert into strain_attributes(uuid, name, value, strain_uuid)
 values('0', 'contamination resistance', 'high', '0'),
@@ -67,13 +96,85 @@ values('0', 'contamination resistance', 'high', '0'),
       ('change attribute', 'color', 'albino', 'change attribute'),
       ('remove attribute 1', 'color', 'red', 'remove attribute'),
       ('remove attribute 2', 'energy', 'pure', 'remove attribute'),
-      ('remove attribute 3', 'preferred substrate', 'cats', 'remove attribute');
+      ('remove attribute 3', 'preferred substrate', 'cats', 'remove attribute'),
+      ('promote 0 color', 'color', 'gold', 'promote parent 0'),
+      ('promote 0 vigor', 'vigor', 'high', 'promote parent 0'),
+      ('promote 1 color', 'color', 'gold', 'promote parent 1'),
+      ('promote 1 vigor', 'vigor', 'low', 'promote parent 1'),
+      ('filter attribute 0', 'headroom (cm)', '40', 'filter attribute'),
+      ('filter attribute 1', 'contamination resistance', 'low', 'filter attribute'),
+      ('change me!', 'change me!', 'small', 'filter attribute'),
+      ('merge attribute 0', 'merge from', '3', 'merge attribute 0'),
+      ('merge attribute 1 from', 'merge from', '4', 'merge attribute 1'),
+      ('merge attribute 1 into', 'merge into', '5', 'merge attribute 1');
 
 insert into event_types(uuid, name, severity, stage_uuid)
 values('update me!', 'update me!', 'Info', '1'),
-      ('delete me!', 'delete me!', 'Info', '1');
+      ('delete me!', 'delete me!', 'Info', '1'),
+      ('follow ups', 'follow ups', 'Info', '1'),
+      ('measured', 'measured', 'Info', '1'),
+      ('fields', 'fields', 'Info', '1');
+
+insert into eventtype_fields(uuid, name, type, unit, min, max, eventtype_uuid)
+values('diameter', 'diameter', 'number', 'mm', 0, null, 'measured'),
+      ('color', 'color', 'text', '', null, null, 'measured'),
+      ('change field', 'change field', 'integer', '', null, null, 'fields'),
+      ('remove field', 'remove field', 'boolean', '', null, null, 'fields');
 
-insert into lifecycles(uuid, location, strain_cost, grain_cost, bulk_cost, yield, headcount, gross, strain_uuid, grainsubstrate_uuid, bulksubstrate_uuid)
+insert into follow_ups(uuid, name, days, eventtype_uuid, expects_uuid)
+values('check colonization', 'Check colonization', 7, '9', '2'),
+      ('expect pinning', 'Expect pinning', 10, '13', '15'),
+      ('change follow up', 'change follow up', 1, 'follow ups', null),
+      ('remove follow up', 'remove follow up', 1, 'follow ups', null);
+
+insert into locations(uuid, name, kind, capacity)
+values('reference implementation', 'reference implementation', 'fruiting chamber', 0),
+      ('reference implementation 2', 'reference implementation 2', 'fruiting chamber', 0),
+      ('spore', 'spore', 'fruiting chamber', 0),
+      ('spore 2', 'spore 2', 'fruiting chamber', 0),
+      ('clone', 'clone', 'fruiting chamber', 0),
+      ('add lc event', 'add lc event', 'fruiting chamber', 0),
+      ('lc insert event', 'lc insert event', 'fruiting chamber', 0),
+      ('lc change event', 'lc change event', 'fruiting chamber', 0),
+      ('remove event', 'remove event', 'fruiting chamber', 0),
+      ('lc delete event', 'lc delete event', 'fruiting chamber', 0),
+      ('add event source', 'add event source', 'fruiting chamber', 0),
+      ('notable', 'notable', 'fruiting chamber', 0),
+      ('delete notable', 'delete notable', 'fruiting chamber', 0),
+      ('update photo', 'update photo', 'fruiting chamber', 0),
+      ('measured', 'measured', 'fruiting chamber', 0),
+      ('occurred', 'occurred', 'fruiting chamber', 0),
+      ('lineage', 'lineage', 'fruiting chamber', 0),
+      ('delete photo', 'delete photo', 'fruiting chamber', 0),
+      ('update me!', 'update me!', 'fruiting chamber', 0),
+      ('delete me!', 'delete me!', 'fruiting chamber', 0),
+      ('retired', 'retired', 'fruiting chamber', 0),
+      ('begun', 'begun', 'fruiting chamber', 0),
+      ('harvested', 'harvested', 'fruiting chamber', 0),
+      ('add harvest', 'add harvest', 'fruiting chamber', 0),
+      ('change harvest', 'change harvest', 'fruiting chamber', 0),
+      ('remove harvest', 'remove harvest', 'fruiting chamber', 0),
+      ('inserted record', 'inserted record', 'fruiting chamber', 0),
+      ('failed insert', 'failed insert', 'fruiting chamber', 0),
+      ('updated', 'updated', 'fruiting chamber', 0),
+      ('sensor shelf', 'sensor shelf', 'fruiting chamber', 0),
+      ('insert readings', 'insert readings', 'fruiting chamber', 0),
+      ('insert readings fail', 'insert readings fail', 'fruiting chamber', 0),
+      ('update location', 'update location', 'fruiting chamber', 0),
+      ('delete location', 'delete location', 'fruiting chamber', 0),
+      ('complete task', 'complete task', 'fruiting chamber', 0),
+      ('complete task event', 'complete task event', 'fruiting chamber', 0),
+      ('batch template', 'batch template', 'fruiting chamber', 0),
+      ('batch', 'batch', 'fruiting chamber', 0),
+      ('batch note', 'batch note', 'fruiting chamber', 0),
+      ('batch photo', 'batch photo', 'fruiting chamber', 0),
+      ('atomic batch', 'atomic batch', 'fruiting chamber', 0),
+      ('exclude batch', 'exclude batch', 'fruiting chamber', 0),
+      ('delete batch', 'delete batch', 'fruiting chamber', 0),
+      ('chamber b', 'chamber b', 'fruiting chamber', 4),
+      ('incubator a', 'incubator a', 'incubator', 0);
+
+insert into lifecycles(uuid, location_uuid, strain_cost, grain_cost, bulk_cost, yield, headcount, gross, strain_uuid, grainsubstrate_uuid, bulksubstrate_uuid)
 values('0', 'reference implementation', 8, 1, 2, 3, 4, 5, '1', '4', 'no-op3'),
       ('1', 'reference implementation 2', 7, 0, 0, 0, 0, 0, '0', '0', '2'),
       ('spore', 'spore', 1.1, 0, 0, 0, 0, 0, '0', '0', '2'),
@@ -90,10 +191,43 @@ values('0', 'reference implementation', 8, 1, 2, 3, 4, 5, '1', '4', 'no-op3'),
       ('update photo', 'update photo', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
       ('delete photo', 'delete photo', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
       ('update me!', 'update me!', 3, 0, 0, 0, 0, 0, '0', '0', '1'),
-      ('delete me!', 'delete me!', 2, 0, 0, 0, 0, 0, '0', '0', '0');
+      ('delete me!', 'delete me!', 2, 0, 0, 0, 0, 0, '0', '0', '0'),
+      ('retired', 'retired', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('begun', 'begun', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('harvested', 'harvested', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('add harvest', 'add harvest', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('change harvest', 'change harvest', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('remove harvest', 'remove harvest', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('complete task', 'complete task', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('complete task event', 'complete task event', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('measured', 'measured', 0, 0, 0, 0, 0, 0, '0', '0', '1'),
+      ('lineage', 'lineage', 0, 0, 0, 0, 0, 0, 'lineage 1', '0', '1');
+
+insert into lifecycles(uuid, location_uuid, strain_uuid, grainsubstrate_uuid, bulksubstrate_uuid, ctime)
+values('occupant', 'chamber b', '0', '0', '1', '2024-02-01'),
+      ('evicted', 'chamber b', '0', '0', '1', '2024-01-01'),
+      ('occurred', 'occurred', '0', '0', '1', '2024-01-01');
+
+-- batch members share a location, so they need their own ctimes
+insert into lifecycles(uuid, location_uuid, strain_uuid, grainsubstrate_uuid, bulksubstrate_uuid, ctime)
+values('batch template', 'batch template', '0', '0', '1', '2024-03-01'),
+      ('batch 0', 'batch', '0', '0', '1', '2024-03-01'),
+      ('batch 1', 'batch', '0', '0', '1', '2024-03-02'),
+      ('batch 2', 'batch', '0', '0', '1', '2024-03-03'),
+      ('batch note 0', 'batch note', '0', '0', '1', '2024-03-01'),
+      ('batch photo 0', 'batch photo', '0', '0', '1', '2024-03-01'),
+      ('atomic batch 0', 'atomic batch', '0', '0', '1', '2024-03-01'),
+      ('atomic batch 1', 'atomic batch', '0', '0', '1', '2024-03-02'),
+      ('exclude batch 0', 'exclude batch', '0', '0', '1', '2024-03-01'),
+      ('delete batch 0', 'delete batch', '0', '0', '1', '2024-03-01');
 
 insert into generations(uuid, platingsubstrate_uuid, liquidsubstrate_uuid)
 values('0', '2', '3'),
+      ('lineage 1', '2', '3'),
+      ('lineage 2', '2', '3'),
+      ('filial', '2', '3'),
+      ('promote', '2', '3'),
+      ('promote strict', '2', '3'),
       ('1', '2', '3'),
       ('2', '2', '3'),
       ('3', '2', '3'),
@@ -118,7 +252,10 @@ values('0', '2', '3'),
       ('update me!', '2', '3'),
       ('delete me!', '2', '3'),
       ('by-plating', 'no-op', '3'),
-      ('by-liquid', 'no-op', 'no-op2');
+      ('by-liquid', 'no-op', 'no-op2'),
+      ('protocol', '2', '3'),
+      ('assign protocol', '2', '3'),
+      ('unassign protocol', '2', '3');
 
 insert into events(uuid, temperature, humidity, observable_uuid, eventtype_uuid)
 values('0', 2, 1, '0', '1'),
@@ -146,6 +283,9 @@ values('0', 2, 1, '0', '1'),
       ('add spore event source 0', 0, 8, 'add event source lc', 'sporeprint'),
       ('add spore event source 1', 0, 8, 'add event source lc', 'sporeprint'),
       ('add spore event source 2', 0, 8, 'add event source lc', 'sporeprint'),
+      ('measured', 20, 80, 'measured', 'measured'),
+      ('measured 2', 20, 80, 'measured', 'measured'),
+      ('lineage print', 20, 80, 'lineage', 'sporeprint'),
       ('add clone event source 0', 0, 8, 'add event source lc', 'clone'),
       ('add clone event source 1', 0, 8, 'add event source lc', 'clone'),
       ('notable lifecycle', 0, 0, 'notable', '0'),
@@ -156,7 +296,22 @@ values('0', 2, 1, '0', '1'),
       ('add photo event 0', 0, 0, 'add photo', '28'),
       ('change photo event', 0, 0, 'update photo', '28'),
       ('delete photo event 0', 0, 0, 'delete photo', '28'),
-      ('update me!', 0, 8, 'update me!', '0');
+      ('update me!', 0, 8, 'update me!', '0'),
+      ('retired begin', 0, 0, 'retired', '9'),
+      ('retired harvest', 0, 0, 'retired', '17'),
+      ('retired sunset', 0, 0, 'retired', 'sunset'),
+      ('evicted sunset', 0, 0, 'evicted', 'sunset'),
+      ('complete task begin', 0, 0, 'complete task', '9'),
+      ('complete task event begin', 0, 0, 'complete task event', '9'),
+      ('begun', 0, 0, 'begun', '9'),
+      ('protocol agar', 0, 0, 'protocol', '0'),
+      ('batch 0 begin', 0, 0, 'batch 0', '9'),
+      ('batch 2 mold', 0, 0, 'batch 2', '3'),
+      ('batch photo begin', 0, 0, 'batch photo 0', '9'),
+      ('atomic batch begin', 0, 0, 'atomic batch 1', '9');
+
+insert into events(uuid, temperature, humidity, observable_uuid, eventtype_uuid, occurred_at)
+values('occurred', 0, 0, 'occurred', '9', '2024-01-10');
 
 insert into sources(uuid, type, progenitor_uuid, generation_uuid)
 values('0', 'Spore', 'spore print', '0'),
@@ -170,7 +325,46 @@ values('0', 'Spore', 'spore print', '0'),
       ('change source 0', 'Spore', 'change strain source 0', 'change source'),
       ('change source 1', 'Spore', 'change strain source 1', 'change source'),
       ('change_source_fail_type 0', 'Spore', 'change strain source 1', 'change_source_fail_type'),
-      ('delete me!', 'Spore', 'remove strain source', 'remove source');
+      ('delete me!', 'Spore', 'remove strain source', 'remove source'),
+      ('lineage clone', 'Clone', 'lineage 0', 'lineage 1'),
+      ('lineage print', 'Spore', 'lineage print', 'lineage 2'),
+      ('filial clone', 'Clone', 'filial parent', 'filial'),
+      ('promote 0', 'Spore', 'promote parent 0', 'promote'),
+      ('promote 1', 'Spore', 'promote parent 1', 'promote'),
+      ('promote strict 0', 'Spore', 'promote parent 0', 'promote strict'),
+      ('promote strict 1', 'Spore', 'promote parent 1', 'promote strict');
+
+-- lineage 0 was cloned into lineage 1, which was grown in the lineage
+-- lifecycle and printed into lineage 2
+update strains
+   set generation_uuid = 'lineage 1',
+       filial_depth = 1,
+       filial_notation = 'clone of P',
+       root_strain_uuid = 'lineage 0'
+ where uuid = 'lineage 1';
+update strains
+   set generation_uuid = 'lineage 2',
+       filial_depth = 2,
+       filial_notation = 'F1',
+       root_strain_uuid = 'lineage 0'
+ where uuid = 'lineage 2';
+
+insert into harvests(uuid, harvest_date, fresh_weight, dry_weight, headcount, grade, lifecycle_uuid)
+values('second flush', '2024-01-16', 100, 0, 4, 'B', 'harvested'),
+      ('first flush', '2024-01-11', 300, 30, 10, 'A', 'harvested'),
+      ('change flush', '2024-01-11', 200, 0, 5, '', 'change harvest'),
+      ('remove flush 0', '2024-01-11', 200, 0, 5, '', 'remove harvest'),
+      ('remove flush 1', '2024-01-16', 50, 5, 1, '', 'remove harvest');
+
+insert into readings(sensor, location, lifecycle_uuid, read_at, temperature, humidity, co2, light)
+values('shelf sensor', 'sensor shelf', null, '2024-01-01 00:00:00', 20, 80, null, null),
+      ('shelf sensor', 'sensor shelf', null, '2024-01-01 00:30:00', 22, 90, null, null),
+      ('shelf sensor', 'sensor shelf', null, '2024-01-01 01:00:00', 21, 85, null, null),
+      ('co2 sensor', 'sensor shelf', null, '2024-01-02 00:00:00', null, null, 800, null),
+      -- lifecycles start now, so their readings come a little later
+      ('begun sensor', 'begun', null, current_timestamp + interval '1 hour', 24, 95, null, null),
+      ('begun tub', 'begun', 'begun', current_timestamp + interval '2 hours', 25, 97, 1200, null),
+      ('retired tub', 'begun', 'retired', current_timestamp + interval '2 hours', 30, 50, null, null);
 
 insert into photos(uuid, filename, photoable_uuid)
 values('gen photo 0', 'gen photo 0', 'generation photo'),
@@ -188,4 +382,77 @@ values('notable lifecycle 0', 'notable lifecycle 0', 'notable lifecycle'),
       ('photo foreign key', 'photo foreign key', 'gen photo 2'),
       ('event foreign key', 'event foreign key', 'remove gen event 0'),
       ('photoable generation 0', 'photoable generation 0', 'gen photo 0'),
-      ('photoable generation 1', 'photoable generation 1', 'gen photo 0');
+      ('photoable generation 1', 'photoable generation 1', 'gen photo 0'),
+      ('harvest note', 'harvest note', 'first flush');
+
+insert into protocols(uuid, name)
+values('agar to bulk', 'Agar to bulk'),
+      ('update protocol', 'update protocol'),
+      ('delete protocol', 'delete protocol'),
+      ('protocol steps', 'protocol steps');
+
+insert into protocol_steps(uuid, protocol_uuid, eventtype_uuid, day, tolerance)
+values('agar to bulk 0', 'agar to bulk', '0', 0, 0),
+      ('agar to bulk 1', 'agar to bulk', '5', 10, 2),
+      ('agar to bulk 2', 'agar to bulk', '9', 20, 3),
+      ('agar to bulk 3', 'agar to bulk', '13', 35, 5),
+      ('change step', 'protocol steps', '0', 0, 0),
+      ('remove step', 'protocol steps', '9', 20, 3);
+
+insert into protocol_assignments(observable_uuid, protocol_uuid)
+values('protocol', 'agar to bulk'),
+      ('unassign protocol', 'agar to bulk');
+
+insert into batches(uuid, name)
+values('batch', 'batch'),
+      ('batch note', 'batch note'),
+      ('batch photo', 'batch photo'),
+      ('atomic batch', 'atomic batch'),
+      ('exclude batch', 'exclude batch'),
+      ('update batch', 'update batch'),
+      ('delete batch', 'delete batch');
+
+-- 'batch 1' was excluded and 'batch 2' got moldy, so only 'batch 0' is in step
+insert into batch_members(batch_uuid, lifecycle_uuid, excluded)
+values('batch', 'batch 0', null),
+      ('batch', 'batch 1', '2024-03-04'),
+      ('batch', 'batch 2', null),
+      ('batch note', 'batch note 0', null),
+      ('batch photo', 'batch photo 0', null),
+      ('atomic batch', 'atomic batch 0', null),
+      ('atomic batch', 'atomic batch 1', null),
+      ('exclude batch', 'exclude batch 0', null),
+      ('delete batch', 'delete batch 0', null);
+
+insert into experiments(uuid, name, variable)
+values('experiment', 'experiment', 'grain'),
+      ('update experiment', 'update experiment', 'grain'),
+      ('delete experiment', 'delete experiment', 'grain'),
+      ('arm experiment', 'arm experiment', 'grain'),
+      ('assign experiment', 'assign experiment', 'grain');
+
+insert into experiment_arms(uuid, experiment_uuid, name, control)
+values('control', 'experiment', 'rye', true),
+      ('treatment', 'experiment', 'millet', false),
+      ('arm control', 'arm experiment', 'rye', true),
+      ('remove arm', 'arm experiment', 'millet', false),
+      ('assign a', 'assign experiment', 'a', false),
+      ('assign b', 'assign experiment', 'b', false),
+      ('delete arm', 'delete experiment', 'rye', true);
+
+insert into experiment_lifecycles(experiment_uuid, arm_uuid, lifecycle_uuid)
+values('experiment', 'control', '0'),
+      ('experiment', 'treatment', '1'),
+      ('assign experiment', 'assign a', 'occupant'),
+      ('delete experiment', 'delete arm', 'evicted');
+
+insert into measurements(event_uuid, field_uuid, value)
+values('measured', 'diameter', '12.5'),
+      ('measured', 'color', '"white"'),
+      ('measured 2', 'diameter', '14');
+
+insert into tags(taggable_uuid, tag)
+values('0', 'keeper'),
+      ('0', 'competition'),
+      ('1', 'competition'),
+      ('1', 'remove me');
@@ -7,6 +7,15 @@
 insert into vendors(uuid, name, website)
 values('localhost', '127.0.0.1', 'https://localhost:8080/');
 
+-- Spore and Clone are how sources always worked, the rest are all clonal
+-- transfers of one kind or another
+insert into source_types(name, max_sources, mixable, clonal)
+values('Spore', 2, false, false),
+      ('Clone', 1, false, true),
+      ('Tissue', 1, false, true),
+      ('Sector', 1, false, true),
+      ('LC', 1, false, true);
+
 insert into stages(uuid, name)
 values('0', 'Gestation'),
       ('1', 'Colonization'),
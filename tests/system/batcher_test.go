@@ -0,0 +1,319 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SelectAllBatches(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		contains types.UUID
+		err      error
+	}{
+		"happy_path": {
+			contains: "batch",
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectAllBatches(context.Background(), types.CID(k))
+			require.Equal(t, v.err, err)
+			ids := []types.UUID{}
+			for _, b := range result {
+				ids = append(ids, b.UUID)
+			}
+			require.Contains(t, ids, v.contains)
+		})
+	}
+}
+
+func Test_SelectBatch(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id      types.UUID
+		members []types.UUID
+		inStep  []types.UUID
+		err     error
+	}{
+		"happy_path": {
+			id:      "batch",
+			members: []types.UUID{"batch 0", "batch 1", "batch 2"},
+			inStep:  []types.UUID{"batch 0"},
+		},
+		"no_row_returned": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectBatch(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			members, inStep := []types.UUID{}, []types.UUID{}
+			for _, m := range result.Members {
+				members = append(members, m.Lifecycle.UUID)
+			}
+			for _, i := range result.InStep() {
+				inStep = append(inStep, result.Members[i].Lifecycle.UUID)
+			}
+			require.ElementsMatch(t, v.members, members)
+			require.Equal(t, v.inStep, inStep)
+		})
+	}
+}
+
+func Test_CreateBatch(t *testing.T) {
+	t.Parallel()
+
+	template, err := db.SelectLifecycle(context.Background(), "batch template", "Test_CreateBatch")
+	require.Nil(t, err)
+
+	missingStrain := template
+	missingStrain.Strain.UUID = "missing"
+
+	set := map[string]struct {
+		name     string
+		template types.Lifecycle
+		n        int
+		err      error
+	}{
+		"happy_path": {
+			name:     "created batch",
+			template: template,
+			n:        3,
+		},
+		"no_members": {
+			name:     "empty batch",
+			template: template,
+			err:      fmt.Errorf("a batch needs at least one member: 0"),
+		},
+		// the batch itself was inserted before the lifecycle failed, so the
+		// rollback has to take it away again
+		"rolled_back": {
+			name:     "rolled back batch",
+			template: missingStrain,
+			n:        2,
+			err:      fmt.Errorf("lifecycle was not added: 0"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.CreateBatch(context.Background(), v.name, v.template, v.n, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+
+			all, err := db.SelectAllBatches(context.Background(), types.CID(k))
+			require.Nil(t, err)
+			names := []string{}
+			for _, b := range all {
+				names = append(names, b.Name)
+			}
+
+			if v.err != nil {
+				require.NotContains(t, names, v.name)
+				return
+			}
+			require.Contains(t, names, v.name)
+			require.Equal(t, v.n, len(result.Members))
+
+			b, err := db.SelectBatch(context.Background(), result.UUID, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, v.n, len(b.Members))
+		})
+	}
+}
+
+func Test_UpdateBatch(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		b   types.Batch
+		err error
+	}{
+		"happy_path": {
+			id: "update batch",
+			b:  types.Batch{Name: "updated batch"},
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			b:   types.Batch{Name: "missing"},
+			err: fmt.Errorf("batch was not updated: 'missing'"),
+		},
+		"duplicate_name_violation": {
+			id:  "update batch",
+			b:   types.Batch{Name: "batch"},
+			err: fmt.Errorf("unique key violation: Key (name)=(batch) already exists."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.UpdateBatch(context.Background(), v.id, v.b, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_DeleteBatch(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "delete batch",
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			err: fmt.Errorf("batch could not be deleted: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.DeleteBatch(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+
+	// the members outlive their batch
+	_, err := db.SelectLifecycle(context.Background(), "delete batch 0", "Test_DeleteBatch")
+	require.Nil(t, err)
+}
+
+func Test_AddBatchEvent(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		et     types.UUID
+		events map[types.UUID]int
+		err    error
+	}{
+		// 'batch 1' was excluded and 'batch 2' is contaminated
+		"happy_path": {
+			id:     "batch",
+			et:     "2",
+			events: map[types.UUID]int{"batch 0": 2, "batch 1": 0, "batch 2": 1},
+		},
+		// 'atomic batch 0' could have begun colonizing, but 'atomic batch 1'
+		// already did, so neither of them gets the event
+		"rolled_back": {
+			id:     "atomic batch",
+			et:     "9",
+			events: map[types.UUID]int{"atomic batch 0": 0, "atomic batch 1": 1},
+			err:    fmt.Errorf("illegal transition to 'Innoculation': stage 'Colonization' already began"),
+		},
+		"missing_batch": {
+			id:  "missing",
+			et:  "2",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			b := types.Batch{UUID: v.id}
+			err := db.AddBatchEvent(context.Background(), &b, types.Event{EventType: types.EventType{UUID: v.et}}, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			for id, n := range v.events {
+				lc, err := db.SelectLifecycle(context.Background(), id, types.CID(k))
+				require.Nil(t, err)
+				require.Equal(t, n, len(lc.Events), id)
+			}
+		})
+	}
+}
+
+func Test_AddBatchNote(t *testing.T) {
+	t.Parallel()
+
+	b := types.Batch{UUID: "batch note"}
+	err := db.AddBatchNote(context.Background(), &b, types.Note{Note: "batch note"}, "Test_AddBatchNote")
+	require.Nil(t, err)
+
+	notes, err := db.GetNotes(context.Background(), "batch note 0", "Test_AddBatchNote")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(notes))
+}
+
+func Test_AddBatchPhoto(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "batch photo",
+		},
+		"no_events": {
+			id:  "batch note",
+			err: fmt.Errorf("batch member has no event to attach a photo to: 'batch note 0'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			b := types.Batch{UUID: v.id}
+			err := db.AddBatchPhoto(context.Background(), &b, types.Photo{Filename: "batch.png"}, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_ExcludeBatchMember(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		bID  types.UUID
+		lcID types.UUID
+		err  error
+	}{
+		"happy_path": {
+			bID:  "exclude batch",
+			lcID: "exclude batch 0",
+		},
+		"already_excluded": {
+			bID:  "batch",
+			lcID: "batch 1",
+			err:  fmt.Errorf("batch member could not be excluded: 'batch 1'"),
+		},
+		"not_a_member": {
+			bID:  "exclude batch",
+			lcID: "batch 0",
+			err:  fmt.Errorf("batch member could not be excluded: 'batch 0'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			b := types.Batch{UUID: v.bID}
+			err := db.ExcludeBatchMember(context.Background(), &b, v.lcID, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
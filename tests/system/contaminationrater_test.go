@@ -0,0 +1,49 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContaminationRates(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		by  types.Dimension
+		err error
+	}{
+		"by_grain":   {by: types.GrainDimension},
+		"by_plating": {by: types.PlatingDimension},
+		"by_stage":   {by: types.StageDimension},
+		"by_month":   {by: types.MonthDimension},
+		"bogus":      {by: "bogus", err: fmt.Errorf("unknown dimension for contamination rates: 'bogus'")},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.ContaminationRates(context.Background(), v.by, types.Window{}, types.CID(k))
+			require.Equal(t, v.err, err)
+
+			for _, r := range result {
+				require.LessOrEqual(t, r.Contaminated, r.Observed)
+				require.LessOrEqual(t, r.Rate, float32(1))
+
+				// the drill-down has to agree with the totals
+				cs, err := db.Contaminations(context.Background(), v.by, r.Key, types.Window{}, types.CID(k))
+				require.Nil(t, err)
+
+				observables := map[types.UUID]struct{}{}
+				for _, c := range cs {
+					observables[c.ObservableUUID] = struct{}{}
+				}
+				require.Equal(t, r.Contaminated, len(observables), "%s: %s", v.by, r.Key)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LifecycleCost(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		result types.Costs
+		err    error
+	}{
+		"happy_path": {
+			id: "0",
+			result: types.NewCosts(types.Lifecycle{
+				StrainCost: 8,
+				GrainCost:  1,
+				BulkCost:   2,
+				Yield:      3,
+				Gross:      5,
+			}),
+		},
+		"no_harvest": {
+			id:     "1",
+			result: types.NewCosts(types.Lifecycle{StrainCost: 7}),
+		},
+		"missing_lifecycle": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.LifecycleCost(context.Background(), v.id, types.CID(k))
+			require.Equal(t, v.err, err)
+			require.Equal(t, v.result, result.Costs)
+		})
+	}
+}
+
+func Test_CostRollup(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		by  types.Dimension
+		err error
+	}{
+		"by_strain":   {by: types.StrainDimension},
+		"by_vendor":   {by: types.VendorDimension},
+		"by_location": {by: types.LocationDimension},
+		"by_month":    {by: types.MonthDimension},
+		"bogus":       {by: "bogus", err: fmt.Errorf("unknown dimension for cost rollup: 'bogus'")},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.CostRollup(context.Background(), v.by, types.Window{}, types.CID(k))
+			require.Equal(t, v.err, err)
+			if err != nil {
+				return
+			}
+
+			// every lifecycle lands in exactly one group, whatever the dimension
+			lcs, err := db.SelectLifecycleCosts(context.Background(), types.Window{}, types.CID(k))
+			require.Nil(t, err)
+
+			count := 0
+			for _, r := range result {
+				require.Equal(t, v.by, r.Dimension)
+				count += r.Count
+			}
+			require.Equal(t, len(lcs), count)
+		})
+	}
+}
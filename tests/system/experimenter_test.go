@@ -0,0 +1,275 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SelectAllExperiments(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		contains types.UUID
+		err      error
+	}{
+		"happy_path": {
+			contains: "experiment",
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectAllExperiments(context.Background(), types.CID(k))
+			require.Equal(t, v.err, err)
+			ids := []types.UUID{}
+			for _, e := range result {
+				ids = append(ids, e.UUID)
+			}
+			require.Contains(t, ids, v.contains)
+		})
+	}
+}
+
+func Test_SelectExperiment(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id   types.UUID
+		arms map[types.UUID][]types.UUID
+		err  error
+	}{
+		"happy_path": {
+			id: "experiment",
+			arms: map[types.UUID][]types.UUID{
+				"control":   {"0"},
+				"treatment": {"1"},
+			},
+		},
+		"no_row_returned": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectExperiment(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			require.True(t, result.Arms[0].Control)
+			arms := map[types.UUID][]types.UUID{}
+			for _, a := range result.Arms {
+				arms[a.UUID] = []types.UUID{}
+				for _, lc := range a.Lifecycles {
+					arms[a.UUID] = append(arms[a.UUID], lc.UUID)
+				}
+			}
+			require.Equal(t, v.arms, arms)
+		})
+	}
+}
+
+func Test_InsertExperiment(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		e   types.Experiment
+		err error
+	}{
+		"happy_path": {
+			e: types.Experiment{Name: "inserted experiment", Variable: "bulk"},
+		},
+		"duplicate_name_violation": {
+			e:   types.Experiment{Name: "experiment", Variable: "bulk"},
+			err: fmt.Errorf("unique key violation: Key (name)=(experiment) already exists."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.InsertExperiment(context.Background(), v.e, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			e, err := db.SelectExperiment(context.Background(), result.UUID, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, v.e.Name, e.Name)
+			require.Empty(t, e.Arms)
+		})
+	}
+}
+
+func Test_UpdateExperiment(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		e   types.Experiment
+		err error
+	}{
+		"happy_path": {
+			id: "update experiment",
+			e:  types.Experiment{Name: "updated experiment", Variable: "bulk"},
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			e:   types.Experiment{Name: "missing"},
+			err: fmt.Errorf("experiment was not updated: 'missing'"),
+		},
+		"duplicate_name_violation": {
+			id:  "update experiment",
+			e:   types.Experiment{Name: "experiment"},
+			err: fmt.Errorf("unique key violation: Key (name)=(experiment) already exists."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.UpdateExperiment(context.Background(), v.id, v.e, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_DeleteExperiment(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "delete experiment",
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			err: fmt.Errorf("experiment could not be deleted: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.DeleteExperiment(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+
+	// the lifecycles outlive their experiment
+	_, err := db.SelectLifecycle(context.Background(), "evicted", "Test_DeleteExperiment")
+	require.Nil(t, err)
+}
+
+func Test_AddExperimentArm(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		a   types.ExperimentArm
+		err error
+	}{
+		"happy_path": {
+			a: types.ExperimentArm{Name: "sorghum"},
+		},
+		"second_control": {
+			a:   types.ExperimentArm{Name: "oats", Control: true},
+			err: fmt.Errorf("unique key violation: Key (experiment_uuid)=(arm experiment) already exists."),
+		},
+		"duplicate_name_violation": {
+			a:   types.ExperimentArm{Name: "rye"},
+			err: fmt.Errorf("unique key violation: Key (experiment_uuid, name)=(arm experiment, rye) already exists."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			e := types.Experiment{UUID: "arm experiment"}
+			_, err := db.AddExperimentArm(context.Background(), &e, v.a, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_RemoveExperimentArm(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "remove arm",
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			err: fmt.Errorf("experiment arm could not be removed: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			e := types.Experiment{UUID: "arm experiment"}
+			err := db.RemoveExperimentArm(context.Background(), &e, v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+// every step moves the same lifecycle around, so they have to run in order
+func Test_AssignExperimentLifecycle(t *testing.T) {
+	t.Parallel()
+
+	arms := func(t *testing.T) map[types.UUID]int {
+		e, err := db.SelectExperiment(context.Background(), "assign experiment", "Test_AssignExperimentLifecycle")
+		require.Nil(t, err)
+		result := map[types.UUID]int{}
+		for _, a := range e.Arms {
+			result[a.UUID] = len(a.Lifecycles)
+		}
+		return result
+	}
+
+	e := types.Experiment{UUID: "assign experiment"}
+
+	err := db.AssignExperimentLifecycle(context.Background(), &e, "assign b", "occupant", "move")
+	require.Nil(t, err)
+	require.Equal(t, map[types.UUID]int{"assign a": 0, "assign b": 1}, arms(t))
+
+	err = db.AssignExperimentLifecycle(context.Background(), &e, "missing", "occupant", "missing_arm")
+	equalErrorMessages(t, fmt.Errorf("foreign key violation: Key (experiment_uuid, arm_uuid)=(assign experiment, missing) is not present in table \"experiment_arms\"., experiment_lifecycles."), err)
+
+	err = db.UnassignExperimentLifecycle(context.Background(), &e, "occupant", "unassign")
+	require.Nil(t, err)
+	require.Equal(t, map[types.UUID]int{"assign a": 0, "assign b": 0}, arms(t))
+
+	err = db.UnassignExperimentLifecycle(context.Background(), &e, "occupant", "unassign_again")
+	equalErrorMessages(t, fmt.Errorf("experiment lifecycle could not be unassigned: 'occupant'"), err)
+}
+
+func Test_ExperimentReport(t *testing.T) {
+	t.Parallel()
+
+	result, err := db.ExperimentReport(context.Background(), "experiment", "Test_ExperimentReport")
+	require.Nil(t, err)
+	require.Equal(t, types.UUID("experiment"), result.Experiment.UUID)
+	require.Len(t, result.Arms, 2)
+	require.Equal(t, types.ExperimentArm{UUID: "control", Name: "rye", Control: true}, result.Arms[0].Arm)
+	require.Equal(t, 1, result.Arms[0].Contamination.N)
+	require.Nil(t, result.Arms[1].Yield.P)
+
+	_, err = db.ExperimentReport(context.Background(), "missing", "Test_ExperimentReport")
+	equalErrorMessages(t, fmt.Errorf("sql: no rows in result set"), err)
+}
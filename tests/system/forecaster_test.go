@@ -0,0 +1,41 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LifecycleForecast(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {id: "0"},
+		"missing_lifecycle": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.LifecycleForecast(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			for _, e := range []*types.Estimate{result.Colonized, result.Pinning, result.Harvest} {
+				if e == nil {
+					continue
+				}
+				require.Positive(t, e.Samples)
+				require.False(t, e.Low.After(e.Expected))
+				require.False(t, e.Expected.After(e.High))
+			}
+		})
+	}
+}
@@ -82,7 +82,7 @@ func Test_AddGenerationEvent(t *testing.T) {
 		"no_rows_affected_eventtype": {
 			e:     types.Event{EventType: types.EventType{UUID: "missing"}},
 			count: 1,
-			err:   fmt.Errorf("event was not added"),
+			err:   fmt.Errorf("couldn't fetch eventtype"),
 		},
 	}
 	for k, v := range set {
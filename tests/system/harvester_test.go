@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_GetHarvests(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		result []types.UUID
+		notes  []int
+	}{
+		"happy_path": {
+			id:     "harvested",
+			result: []types.UUID{"first flush", "second flush"},
+			notes:  []int{1, 0},
+		},
+		"no_harvests": {
+			id:     "begun",
+			result: []types.UUID{},
+			notes:  []int{},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: v.id}
+			err := db.GetHarvests(context.Background(), &lc, types.CID(k))
+			require.Nil(t, err)
+
+			ids, notes := []types.UUID{}, []int{}
+			for _, h := range lc.Harvests {
+				ids, notes = append(ids, h.UUID), append(notes, len(h.Notes))
+			}
+			require.Equal(t, v.result, ids)
+			require.Equal(t, v.notes, notes)
+		})
+	}
+}
+
+func Test_AddHarvest(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id    types.UUID
+		h     types.Harvest
+		yield float32
+		count int16
+		gross float32
+		err   error
+	}{
+		"happy_path": {
+			id:    "add harvest",
+			h:     types.Harvest{Date: time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC), FreshWeight: 250, DryWeight: 25, Count: 8, Grade: "A"},
+			yield: 25,
+			count: 8,
+			gross: 250,
+		},
+		"missing_lifecycle": {
+			id:  "missing",
+			h:   types.Harvest{FreshWeight: 1},
+			err: fmt.Errorf("foreign key violation: Key (lifecycle_uuid)=(missing) is not present in table \"lifecycles\"., harvests."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: v.id}
+			err := db.AddHarvest(context.Background(), &lc, v.h, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+
+			require.Equal(t, v.yield, lc.Yield)
+			require.Equal(t, v.count, lc.Count)
+			require.Equal(t, v.gross, lc.Gross)
+
+			saved, err := db.SelectLifecycle(context.Background(), v.id, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, v.gross, saved.Gross)
+		})
+	}
+}
+
+func Test_ChangeHarvest(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id    types.UUID
+		h     types.Harvest
+		gross float32
+		err   error
+	}{
+		"happy_path": {
+			id:    "change harvest",
+			h:     types.Harvest{UUID: "change flush", Date: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), FreshWeight: 220, Count: 5},
+			gross: 220,
+		},
+		"wrong_lifecycle": {
+			id:  "harvested",
+			h:   types.Harvest{UUID: "change flush", FreshWeight: 1},
+			err: fmt.Errorf("harvest was not changed"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: v.id}
+			_, err := db.ChangeHarvest(context.Background(), &lc, v.h, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err == nil {
+				require.Equal(t, v.gross, lc.Gross)
+			}
+		})
+	}
+}
+
+func Test_RemoveHarvest(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id    types.UUID
+		hid   types.UUID
+		yield float32
+		gross float32
+		err   error
+	}{
+		"happy_path": {
+			id:    "remove harvest",
+			hid:   "remove flush 0",
+			yield: 5,
+			gross: 50,
+		},
+		"missing_harvest": {
+			id:  "remove harvest",
+			hid: "missing",
+			err: fmt.Errorf("harvest could not be removed"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: v.id}
+			err := db.RemoveHarvest(context.Background(), &lc, v.hid, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err == nil {
+				require.Equal(t, v.yield, lc.Yield)
+				require.Equal(t, v.gross, lc.Gross)
+			}
+		})
+	}
+}
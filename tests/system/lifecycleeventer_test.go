@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -60,7 +61,7 @@ func Test_AddLifecycleEvent(t *testing.T) {
 		"no_rows_affected_eventtype": {
 			e:     types.Event{EventType: types.EventType{UUID: "missing"}},
 			count: 1,
-			err:   fmt.Errorf("event was not added"),
+			err:   fmt.Errorf("couldn't fetch eventtype"),
 		},
 	}
 	for k, v := range set {
@@ -75,6 +76,49 @@ func Test_AddLifecycleEvent(t *testing.T) {
 	}
 }
 
+func Test_LifecycleEventOccurredAt(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		at  time.Time
+		err error
+	}{
+		"backfilled": {
+			at: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		"before_it_began": {
+			at:  time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			err: fmt.Errorf("pq: event can't have occurred before its observable began"),
+		},
+		"in_the_future": {
+			at:  time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC),
+			err: fmt.Errorf("event can't have occurred in the future: '2999-01-01T00:00:00Z'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			lc, err := db.SelectLifecycle(context.Background(), "occurred", types.CID(k))
+			require.Nil(t, err)
+
+			err = db.AddLifecycleEvent(context.Background(), &lc, types.Event{
+				EventType:  types.EventType{UUID: "measured"},
+				OccurredAt: v.at,
+			}, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if err != nil {
+				return
+			}
+
+			// the new event was recorded last, but it happened first
+			require.Equal(t, types.UUID("occurred"), lc.Events[0].UUID)
+			require.Equal(t, v.at, lc.Events[1].OccurredAt)
+		})
+	}
+}
+
 func Test_ChangeLifecycleEvent(t *testing.T) {
 	t.Parallel()
 
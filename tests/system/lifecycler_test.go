@@ -50,6 +50,43 @@ func Test_SelectLifecycleIndex(t *testing.T) {
 	}
 }
 
+func Test_SelectLifecycleIndexByStatus(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		filter   []types.Status
+		contains types.UUID
+		excludes types.UUID
+	}{
+		"harvested": {
+			filter:   []types.Status{types.HarvestedStatus},
+			contains: "retired",
+			excludes: "begun",
+		},
+		"active_or_dead": {
+			filter:   []types.Status{types.ActiveStatus, types.DeadStatus},
+			contains: "begun",
+			excludes: "retired",
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectLifecycleIndex(context.Background(), types.CID(k), v.filter...)
+			require.Nil(t, err)
+
+			ids := map[types.UUID]types.Status{}
+			for _, lc := range result {
+				require.Contains(t, v.filter, lc.Status)
+				ids[lc.UUID] = lc.Status
+			}
+			require.Contains(t, ids, v.contains)
+			require.NotContains(t, ids, v.excludes)
+		})
+	}
+}
+
 func Test_SelectLifecycle(t *testing.T) {
 	t.Parallel()
 
@@ -90,7 +127,7 @@ func Test_InsertLifecycle(t *testing.T) {
 	}{
 		"happy_path": {
 			lc: types.Lifecycle{
-				Location:       "inserted record",
+				Location:       types.Location{UUID: "inserted record"},
 				GrainCost:      1,
 				BulkCost:       2,
 				Yield:          3,
@@ -101,7 +138,7 @@ func Test_InsertLifecycle(t *testing.T) {
 				BulkSubstrate:  substrates[types.BulkType][0],
 			},
 			result: types.Lifecycle{
-				Location:       "inserted record",
+				Location:       types.Location{UUID: "inserted record"},
 				GrainCost:      1,
 				BulkCost:       2,
 				Yield:          3,
@@ -114,7 +151,7 @@ func Test_InsertLifecycle(t *testing.T) {
 		},
 		"no_rows_affected_grain": {
 			lc: types.Lifecycle{
-				Location:       "failed insert",
+				Location:       types.Location{UUID: "failed insert"},
 				Strain:         strains[1],
 				GrainSubstrate: types.Substrate{UUID: "foobar"},
 				BulkSubstrate:  substrates[types.BulkType][0],
@@ -124,7 +161,7 @@ func Test_InsertLifecycle(t *testing.T) {
 		},
 		"no_rows_affected_bulk": {
 			lc: types.Lifecycle{
-				Location:       "failed insert",
+				Location:       types.Location{UUID: "failed insert"},
 				Strain:         strains[1],
 				GrainSubstrate: substrates[types.GrainType][0],
 				BulkSubstrate:  types.Substrate{UUID: "foobar"},
@@ -134,7 +171,7 @@ func Test_InsertLifecycle(t *testing.T) {
 		},
 		"no_rows_affected_strain": {
 			lc: types.Lifecycle{
-				Location:       "failed insert",
+				Location:       types.Location{UUID: "failed insert"},
 				Strain:         types.Strain{UUID: "foobar"},
 				GrainSubstrate: substrates[types.GrainType][0],
 				BulkSubstrate:  substrates[types.BulkType][0],
@@ -144,7 +181,7 @@ func Test_InsertLifecycle(t *testing.T) {
 		},
 		"no_rows_affected_check_grain_type": {
 			lc: types.Lifecycle{
-				Location:       "failed insert",
+				Location:       types.Location{UUID: "failed insert"},
 				Strain:         strains[0],
 				GrainSubstrate: substrates[types.BulkType][0],
 				BulkSubstrate:  substrates[types.BulkType][0],
@@ -154,7 +191,7 @@ func Test_InsertLifecycle(t *testing.T) {
 		},
 		"no_rows_affected_check_bulk_type": {
 			lc: types.Lifecycle{
-				Location:       "failed insert",
+				Location:       types.Location{UUID: "failed insert"},
 				Strain:         strains[0],
 				GrainSubstrate: substrates[types.GrainType][0],
 				BulkSubstrate:  substrates[types.GrainType][0],
@@ -188,7 +225,7 @@ func Test_UpdateLifecycle(t *testing.T) {
 	}{
 		"happy_path": {
 			xform: func(lc types.Lifecycle) types.Lifecycle {
-				lc.Location = "updated"
+				lc.Location = types.Location{UUID: "updated"}
 				return lc
 			},
 		},
@@ -229,10 +266,10 @@ func Test_UpdateLifecycle(t *testing.T) {
 		},
 		"unique_key_violation": {
 			xform: func(lc types.Lifecycle) types.Lifecycle {
-				lc.Location = "reference implementation 2"
+				lc.Location = types.Location{UUID: "reference implementation 2"}
 				return lc
 			},
-			err: fmt.Errorf(uniqueKeyViolation, "lifecycles_location_ctime_key"),
+			err: fmt.Errorf(uniqueKeyViolation, "lifecycles_location_uuid_ctime_key"),
 		},
 	}
 	for k, v := range set {
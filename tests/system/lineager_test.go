@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_Ancestry(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id      types.UUID
+		depth   int
+		strains []string
+		err     error
+	}{
+		"all_the_way": {
+			id:      "lineage 2",
+			strains: []string{"lineage child", "lineage parent", "lineage grandparent"},
+		},
+		"one_generation": {
+			id:      "lineage 2",
+			depth:   1,
+			strains: []string{"lineage child", "lineage parent"},
+		},
+		"no_generation": {
+			id:      "lineage 0",
+			strains: []string{"lineage grandparent"},
+		},
+		"missing_strain": {
+			id:  "missing",
+			err: sql.ErrNoRows,
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.Ancestry(context.Background(), v.id, v.depth, types.CID(k))
+			require.Equal(t, v.err, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, v.strains, lineageStrains(result))
+		})
+	}
+
+	t.Run("through_a_lifecycle", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := db.Ancestry(context.Background(), "lineage 2", 1, "through_a_lifecycle")
+		require.Nil(t, err)
+		require.Equal(t, []string{"lifecycle#lineage"}, result.Parents("source#lineage print"))
+		require.Equal(t, []string{"strain#lineage 1"}, result.Parents("lifecycle#lineage"))
+	})
+}
+
+func Test_Descendants(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id      types.UUID
+		depth   int
+		strains []string
+	}{
+		"all_the_way": {
+			id:      "lineage 0",
+			strains: []string{"lineage grandparent", "lineage parent", "lineage child"},
+		},
+		"one_generation": {
+			id:      "lineage 0",
+			depth:   1,
+			strains: []string{"lineage grandparent", "lineage parent"},
+		},
+		"no_descendants": {
+			id:      "lineage 2",
+			strains: []string{"lineage child"},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.Descendants(context.Background(), v.id, v.depth, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, v.strains, lineageStrains(result))
+		})
+	}
+}
+
+func lineageStrains(l types.Lineage) []string {
+	result := []string{}
+	for _, n := range l.Nodes {
+		if n.Kind == types.StrainNode {
+			result = append(result, n.Label)
+		}
+	}
+	return result
+}
+
+func Test_Pedigree(t *testing.T) {
+	t.Parallel()
+
+	result, err := db.Pedigree(context.Background(), "lineage 1", 0, "Test_Pedigree")
+	require.Nil(t, err)
+	require.Equal(t, "strain#lineage 1", result.Root)
+	require.Equal(t, []string{"lineage parent", "lineage grandparent", "lineage child"}, lineageStrains(result))
+
+	outcomes := map[string]types.Status{}
+	for _, n := range result.Nodes {
+		if n.Kind == types.StrainNode {
+			require.Equal(t, "X.test", n.Species)
+			require.Equal(t, "127.0.0.1", n.Vendor)
+		} else if n.Kind != types.SourceNode {
+			outcomes[n.ID] = n.Outcome
+		}
+	}
+	require.Equal(t, map[string]types.Status{
+		"generation#lineage 1": types.PendingStatus,
+		"generation#lineage 2": types.PendingStatus,
+		"lifecycle#lineage":    types.ActiveStatus,
+	}, outcomes)
+
+	_, err = db.Pedigree(context.Background(), "missing", 0, "Test_Pedigree")
+	require.Equal(t, sql.ErrNoRows, err)
+}
+
+func Test_Library(t *testing.T) {
+	t.Parallel()
+
+	result, err := db.Library(context.Background(), "Test_Library")
+	require.Nil(t, err)
+	require.Subset(t, lineageStrains(result), []string{"lineage grandparent", "lineage parent", "lineage child", "Morel"})
+	require.Equal(t, []string{"lifecycle#lineage"}, result.Parents("source#lineage print"))
+	require.Equal(t, []string{"strain#lineage 0"}, result.Parents("source#lineage clone"))
+}
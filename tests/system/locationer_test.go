@@ -0,0 +1,216 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SelectAllLocations(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		contains types.UUID
+		err      error
+	}{
+		"happy_path": {
+			contains: "chamber b",
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectAllLocations(context.Background(), types.CID(k))
+			require.Equal(t, v.err, err)
+			ids := []types.UUID{}
+			for _, loc := range result {
+				ids = append(ids, loc.UUID)
+			}
+			require.Contains(t, ids, v.contains)
+		})
+	}
+}
+
+func Test_SelectLocation(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		result types.Location
+		err    error
+	}{
+		"happy_path": {
+			id: "incubator a",
+			result: types.Location{
+				UUID: "incubator a",
+				Name: "incubator a",
+				Kind: types.IncubatorLocation,
+			},
+		},
+		"no_row_returned": {
+			id:     "missing",
+			result: types.Location{UUID: "missing"},
+			err:    fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectLocation(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			require.Equal(t, v.result, result)
+		})
+	}
+}
+
+func Test_InsertLocation(t *testing.T) {
+	t.Parallel()
+
+	hot := float32(30)
+
+	set := map[string]struct {
+		loc types.Location
+		err error
+	}{
+		"happy_path": {
+			loc: types.Location{
+				Name:     "inserted location",
+				Kind:     types.OutdoorBedLocation,
+				Capacity: 2,
+				Target:   types.Environment{MaxTemperature: &hot},
+			},
+		},
+		"duplicate_name_violation": {
+			loc: types.Location{Name: "chamber b", Kind: types.FruitingChamberLocation},
+			err: fmt.Errorf("unique key violation: Key (name)=(chamber b) already exists."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.InsertLocation(context.Background(), v.loc, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			require.NotEmpty(t, result.UUID)
+			if v.err != nil {
+				return
+			}
+			saved, err := db.SelectLocation(context.Background(), result.UUID, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, result, saved)
+		})
+	}
+}
+
+func Test_UpdateLocation(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		loc types.Location
+		err error
+	}{
+		"happy_path": {
+			id:  "update location",
+			loc: types.Location{Name: "updated location", Kind: types.IncubatorLocation, Capacity: 12},
+		},
+		"duplicate_name_violation": {
+			id:  "update location",
+			loc: types.Location{Name: "chamber b", Kind: types.IncubatorLocation},
+			err: fmt.Errorf("unique key violation: Key (name)=(chamber b) already exists."),
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			loc: types.Location{Name: "missing", Kind: types.IncubatorLocation},
+			err: fmt.Errorf("location was not updated: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.UpdateLocation(context.Background(), v.id, v.loc, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_DeleteLocation(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "delete location",
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			err: fmt.Errorf("location could not be deleted: 'missing'"),
+		},
+		"referential_violation": {
+			id:  "chamber b",
+			err: fmt.Errorf("foreign key violation: Key (uuid)=(chamber b) is still referenced from table \"lifecycles\"., lifecycles."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.DeleteLocation(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_LocationOccupancy(t *testing.T) {
+	t.Parallel()
+
+	fill := float32(0.25)
+
+	set := map[string]struct {
+		id         types.UUID
+		lifecycles []types.UUID
+		fill       *float32
+		err        error
+	}{
+		"happy_path": {
+			id:         "chamber b",
+			lifecycles: []types.UUID{"occupant"},
+			fill:       &fill,
+		},
+		"empty": {
+			id:         "incubator a",
+			lifecycles: []types.UUID{},
+		},
+		"missing_location": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.LocationOccupancy(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			ids := []types.UUID{}
+			for _, lc := range result.Lifecycles {
+				ids = append(ids, lc.UUID)
+			}
+			require.Equal(t, v.lifecycles, ids)
+			require.Equal(t, len(v.lifecycles), result.Occupied)
+			require.Equal(t, v.fill, result.Fill)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_GetFields(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		result []string
+	}{
+		"happy_path": {
+			id:     "measured",
+			result: []string{"color", "diameter"},
+		},
+		"no_fields": {
+			id:     "0",
+			result: []string{},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: v.id}
+			err := db.GetFields(context.Background(), &et, types.CID(k))
+			require.Nil(t, err)
+
+			names := []string{}
+			for _, f := range et.Fields {
+				names = append(names, f.Name)
+			}
+			require.Equal(t, v.result, names)
+		})
+	}
+}
+
+func Test_AddField(t *testing.T) {
+	t.Parallel()
+
+	zero := 0.0
+
+	set := map[string]struct {
+		id  types.UUID
+		f   types.Field
+		err error
+	}{
+		"happy_path": {
+			id: "fields",
+			f:  types.Field{Name: "added field", Type: types.IntegerField, Min: &zero},
+		},
+		"duplicate_name": {
+			id:  "measured",
+			f:   types.Field{Name: "diameter", Type: types.NumberField},
+			err: fmt.Errorf("unique key violation: Key (name, eventtype_uuid)=(diameter, measured) already exists."),
+		},
+		"missing_eventtype": {
+			id:  "missing",
+			f:   types.Field{Name: "added field", Type: types.TextField},
+			err: fmt.Errorf("foreign key violation: Key (eventtype_uuid)=(missing) is not present in table \"event_types\"., eventtype_fields."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: v.id}
+			_, err := db.AddField(context.Background(), &et, v.f, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_ChangeField(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		f   types.Field
+		err error
+	}{
+		"happy_path": {
+			f: types.Field{UUID: "change field", Name: "changed field", Type: types.IntegerField, Unit: "count"},
+		},
+		"type_changed": {
+			f:   types.Field{UUID: "change field", Name: "changed field", Type: types.TextField},
+			err: fmt.Errorf("field was not changed, or its type was: 'change field'"),
+		},
+		"missing_field": {
+			f:   types.Field{UUID: "missing", Name: "missing", Type: types.TextField},
+			err: fmt.Errorf("field was not changed, or its type was: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "fields"}
+			err := db.ChangeField(context.Background(), &et, v.f, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_RemoveField(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "remove field",
+		},
+		"missing_field": {
+			id:  "missing",
+			err: fmt.Errorf("field could not be removed: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "fields"}
+			err := db.RemoveField(context.Background(), &et, v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_EventMeasurements(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		ms     []types.Measurement
+		result []types.Measurement
+		err    error
+	}{
+		"happy_path": {
+			ms:     []types.Measurement{{Field: "color", Value: "tan"}},
+			result: []types.Measurement{{Field: "color", Value: "tan"}},
+		},
+		"unknown_field": {
+			ms:  []types.Measurement{{Field: "weight", Value: 1}},
+			err: fmt.Errorf("'weight' isn't a field of this event type"),
+		},
+		"out_of_bounds": {
+			ms:  []types.Measurement{{Field: "diameter", Value: -1}},
+			err: fmt.Errorf("'diameter' can't be less than 0: '-1'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			lc := types.Lifecycle{UUID: "measured"}
+			err := db.AddLifecycleEvent(context.Background(), &lc, types.Event{
+				EventType:    types.EventType{UUID: "measured"},
+				Measurements: v.ms,
+			}, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if err != nil {
+				return
+			}
+
+			e, err := db.SelectEvent(context.Background(), lc.Events[0].UUID, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, v.result, e.Measurements)
+		})
+	}
+
+	t.Run("seeded", func(t *testing.T) {
+		t.Parallel()
+
+		e, err := db.SelectEvent(context.Background(), "measured", "seeded")
+		require.Nil(t, err)
+		require.Equal(t, []types.Measurement{
+			{Field: "diameter", Value: 12.5, Unit: "mm"},
+			{Field: "color", Value: "white"},
+		}, e.Measurements)
+	})
+}
+
+func Test_MeasurementStats(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		field types.UUID
+		by    types.Dimension
+		err   error
+	}{
+		"by_location": {field: "diameter", by: types.LocationDimension},
+		"by_strain":   {field: "diameter", by: types.StrainDimension},
+		"text_field":  {field: "color", by: types.StrainDimension, err: fmt.Errorf("only number and integer fields can be summarized: 'color'")},
+		"bogus":       {field: "diameter", by: "bogus", err: fmt.Errorf("unknown dimension for measurement stats: 'bogus'")},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.MeasurementStats(context.Background(), v.field, v.by, types.Window{}, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			for _, r := range result {
+				require.Equal(t, v.by, r.Dimension)
+				require.LessOrEqual(t, r.Min, r.Median)
+				require.LessOrEqual(t, r.Median, r.Max)
+			}
+		})
+	}
+
+	t.Run("seeded", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := db.MeasurementStats(context.Background(), "diameter", types.LocationDimension, types.Window{}, "seeded")
+		require.Nil(t, err)
+		require.Equal(t, 1, len(result))
+		require.Equal(t, "measured", result[0].Key)
+		require.Equal(t, 2, result[0].Count)
+		require.Equal(t, 13.25, result[0].Median)
+	})
+}
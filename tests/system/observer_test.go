@@ -155,7 +155,23 @@ func Test_InsertEvent(t *testing.T) {
 		"missing_event_type": {
 			oid: "lc insert event",
 			e:   types.Event{UUID: "lc insert fails", EventType: types.EventType{UUID: "missing"}},
-			err: fmt.Errorf("event was not added"),
+			err: fmt.Errorf("couldn't fetch eventtype"),
+		},
+		"after_rip": {
+			oid: "retired",
+			e:   types.Event{EventType: types.EventType{UUID: "15"}},
+			err: types.TransitionError{
+				EventType: types.EventType{UUID: "15", Name: "Pinning", Severity: "Info", Stage: stages["Majority"]},
+				Reason:    "'Sunset' already ended it",
+			},
+		},
+		"second_begin": {
+			oid: "begun",
+			e:   types.Event{EventType: types.EventType{UUID: "9"}},
+			err: types.TransitionError{
+				EventType: types.EventType{UUID: "9", Name: "Innoculation", Severity: "Begin", Stage: stages["Colonization"]},
+				Reason:    "stage 'Colonization' already began",
+			},
 		},
 		"missing observable": { // dunno how this would happen, but whatever
 			e:   types.Event{UUID: "lc insert fails", EventType: eventtypes[1]},
@@ -173,6 +189,30 @@ func Test_InsertEvent(t *testing.T) {
 	}
 }
 
+func Test_EventUnits(t *testing.T) {
+	t.Parallel()
+
+	imperial := types.WithUnits(context.Background(), types.ImperialUnits)
+
+	e, err := db.InsertEvent(imperial, "lc insert event", types.Event{
+		Temperature: 68,
+		EventType:   eventtypes[1],
+	}, "Test_EventUnits")
+	require.Nil(t, err)
+	require.Equal(t, float32(68), e.Temperature)
+	require.Equal(t, types.ImperialUnits, e.Units)
+
+	e, err = db.SelectEvent(context.Background(), e.UUID, "Test_EventUnits")
+	require.Nil(t, err)
+	require.Equal(t, float32(20), e.Temperature)
+	require.Equal(t, types.UnitSystem(""), e.Units)
+
+	e, err = db.SelectEvent(imperial, e.UUID, "Test_EventUnits")
+	require.Nil(t, err)
+	require.Equal(t, float32(68), e.Temperature)
+	require.Equal(t, types.ImperialUnits, e.Units)
+}
+
 func Test_UpdateEvent(t *testing.T) {
 	t.Parallel()
 
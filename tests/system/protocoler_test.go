@@ -0,0 +1,330 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SelectAllProtocols(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		contains types.UUID
+		err      error
+	}{
+		"happy_path": {
+			contains: "agar to bulk",
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectAllProtocols(context.Background(), types.CID(k))
+			require.Equal(t, v.err, err)
+			ids := []types.UUID{}
+			for _, p := range result {
+				ids = append(ids, p.UUID)
+			}
+			require.Contains(t, ids, v.contains)
+		})
+	}
+}
+
+func Test_SelectProtocol(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id    types.UUID
+		name  string
+		steps []types.UUID
+		err   error
+	}{
+		"happy_path": {
+			id:    "agar to bulk",
+			name:  "Agar to bulk",
+			steps: []types.UUID{"agar to bulk 0", "agar to bulk 1", "agar to bulk 2", "agar to bulk 3"},
+		},
+		"no_row_returned": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.SelectProtocol(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			require.Equal(t, v.name, result.Name)
+			ids := []types.UUID{}
+			for _, s := range result.Steps {
+				ids = append(ids, s.UUID)
+			}
+			require.Equal(t, v.steps, ids)
+		})
+	}
+}
+
+func Test_InsertProtocol(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		p   types.Protocol
+		err error
+	}{
+		"happy_path": {
+			p: types.Protocol{Name: "inserted protocol"},
+		},
+		"duplicate_name_violation": {
+			p:   types.Protocol{Name: "Agar to bulk"},
+			err: fmt.Errorf("unique key violation: Key (name)=(Agar to bulk) already exists."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.InsertProtocol(context.Background(), v.p, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			require.NotEmpty(t, result.UUID)
+		})
+	}
+}
+
+func Test_UpdateProtocol(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		p   types.Protocol
+		err error
+	}{
+		"happy_path": {
+			id: "update protocol",
+			p:  types.Protocol{Name: "updated protocol"},
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			p:   types.Protocol{Name: "missing"},
+			err: fmt.Errorf("protocol was not updated: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.UpdateProtocol(context.Background(), v.id, v.p, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_DeleteProtocol(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "delete protocol",
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			err: fmt.Errorf("protocol could not be deleted: 'missing'"),
+		},
+		"referential_violation": {
+			id:  "agar to bulk",
+			err: fmt.Errorf("foreign key violation: Key (uuid)=(agar to bulk) is still referenced from table \"protocol_assignments\"., protocol_assignments."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.DeleteProtocol(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+// every step test works on its own step of 'protocol steps', so they can run
+// in parallel
+func Test_ProtocolSteps(t *testing.T) {
+	t.Parallel()
+
+	p, err := db.SelectProtocol(context.Background(), "protocol steps", "Test_ProtocolSteps")
+	require.Nil(t, err)
+
+	t.Run("add", func(t *testing.T) {
+		t.Parallel()
+		p := p
+		s, err := db.AddProtocolStep(context.Background(), &p, types.ProtocolStep{
+			EventType: types.EventType{UUID: "13"},
+			Day:       35,
+			Tolerance: 5,
+		}, "Test_ProtocolSteps/add")
+		require.Nil(t, err)
+		require.NotEmpty(t, s.UUID)
+	})
+	t.Run("add_unknown_event_type", func(t *testing.T) {
+		t.Parallel()
+		p := p
+		_, err := db.AddProtocolStep(context.Background(), &p, types.ProtocolStep{
+			EventType: types.EventType{UUID: "missing"},
+		}, "Test_ProtocolSteps/add_unknown_event_type")
+		equalErrorMessages(t, fmt.Errorf("foreign key violation: Key (eventtype_uuid)=(missing) is not present in table \"event_types\"., protocol_steps."), err)
+	})
+	t.Run("change", func(t *testing.T) {
+		t.Parallel()
+		p := p
+		err := db.ChangeProtocolStep(context.Background(), &p, types.ProtocolStep{
+			UUID:      "change step",
+			EventType: types.EventType{UUID: "5"},
+			Day:       10,
+			Tolerance: 2,
+		}, "Test_ProtocolSteps/change")
+		require.Nil(t, err)
+	})
+	t.Run("remove", func(t *testing.T) {
+		t.Parallel()
+		p := p
+		err := db.RemoveProtocolStep(context.Background(), &p, "remove step", "Test_ProtocolSteps/remove")
+		require.Nil(t, err)
+	})
+	t.Run("remove_missing", func(t *testing.T) {
+		t.Parallel()
+		p := p
+		err := db.RemoveProtocolStep(context.Background(), &p, "missing", "Test_ProtocolSteps/remove_missing")
+		equalErrorMessages(t, fmt.Errorf("protocol step could not be removed: 'missing'"), err)
+	})
+}
+
+func Test_AssignProtocol(t *testing.T) {
+	t.Parallel()
+
+	pID := types.UUID("agar to bulk")
+	missing := types.UUID("missing")
+
+	set := map[string]struct {
+		oID types.UUID
+		pID *types.UUID
+		err error
+	}{
+		"happy_path": {
+			oID: "assign protocol",
+			pID: &pID,
+		},
+		"unassign": {
+			oID: "unassign protocol",
+		},
+		"nothing_to_unassign": {
+			oID: "missing",
+			err: fmt.Errorf("protocol could not be unassigned: 'missing'"),
+		},
+		"missing_observable": {
+			oID: "missing",
+			pID: &pID,
+			err: fmt.Errorf("pq: foreign key violation"),
+		},
+		"missing_protocol": {
+			oID: "assign protocol",
+			pID: &missing,
+			err: fmt.Errorf("foreign key violation: Key (protocol_uuid)=(missing) is not present in table \"protocols\"., protocol_assignments."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.AssignProtocol(context.Background(), v.oID, v.pID, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_ObservableProtocol(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		oID    types.UUID
+		result types.UUID
+		err    error
+	}{
+		"happy_path": {
+			oID:    "protocol",
+			result: "agar to bulk",
+		},
+		"unassigned": {
+			oID: "0",
+		},
+		"missing_observable": {
+			oID: "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.ObservableProtocol(context.Background(), v.oID, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.result == "" {
+				require.Nil(t, result)
+				return
+			}
+			require.Equal(t, v.result, result.UUID)
+		})
+	}
+}
+
+func Test_ProtocolDeviations(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		oID   types.UUID
+		kinds []types.DeviationKind
+		err   error
+	}{
+		// the agar sampling happened just now, so everything after it is
+		// still pending
+		"happy_path": {
+			oID: "protocol",
+			kinds: []types.DeviationKind{
+				types.OnTimeDeviation,
+				types.PendingDeviation,
+				types.PendingDeviation,
+				types.PendingDeviation,
+			},
+		},
+		"unassigned": {
+			oID: "0",
+			err: fmt.Errorf("observable doesn't follow a protocol: '0'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.ProtocolDeviations(context.Background(), v.oID, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			kinds := []types.DeviationKind{}
+			for _, d := range result.Deviations {
+				kinds = append(kinds, d.Kind)
+			}
+			require.Equal(t, v.kinds, kinds)
+		})
+	}
+}
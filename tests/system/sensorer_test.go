@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_InsertReadings(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	temp, hot := float32(20), float32(26)
+	missing := types.UUID("missing")
+
+	set := map[string]struct {
+		location string
+		rs       []types.Reading
+		result   []float32
+		err      error
+	}{
+		"happy_path": {
+			location: "insert readings",
+			rs: []types.Reading{
+				{Sensor: "insert sensor", Location: "insert readings", At: at, Temperature: &temp},
+				{Sensor: "insert sensor", Location: "insert readings", At: at.Add(time.Minute), Temperature: &temp},
+				// the same reading again overwrites the first one
+				{Sensor: "insert sensor", Location: "insert readings", At: at, Temperature: &hot},
+			},
+			result: []float32{hot, temp},
+		},
+		"missing_lifecycle": {
+			location: "insert readings fail",
+			rs: []types.Reading{
+				{Sensor: "insert fail sensor", Location: "insert readings fail", Lifecycle: &missing, At: at},
+			},
+			result: []float32{},
+			err:    fmt.Errorf("foreign key violation: Key (lifecycle_uuid)=(missing) is not present in table \"lifecycles\"., readings."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			err := db.InsertReadings(context.Background(), v.rs, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+
+			rs, err := db.SelectReadings(context.Background(), v.location, types.Window{}, types.CID(k))
+			require.Nil(t, err)
+
+			temps := []float32{}
+			for _, r := range rs {
+				temps = append(temps, *r.Temperature)
+			}
+			require.Equal(t, v.result, temps)
+		})
+	}
+}
+
+func Test_ReadingRollups(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	set := map[string]struct {
+		res    types.Resolution
+		w      types.Window
+		counts []int
+		err    error
+	}{
+		"hourly": {
+			res:    types.HourResolution,
+			counts: []int{2, 1, 1},
+		},
+		"daily": {
+			res:    types.DayResolution,
+			counts: []int{3, 1},
+		},
+		"windowed": {
+			res:    types.DayResolution,
+			w:      types.Window{From: &from, To: &to},
+			counts: []int{3},
+		},
+		"unknown_resolution": {
+			res:    "week",
+			counts: []int{},
+			err:    fmt.Errorf("unknown resolution for reading rollups: 'week'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			rrs, err := db.ReadingRollups(context.Background(), "sensor shelf", v.res, v.w, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+
+			counts := []int{}
+			for _, rr := range rrs {
+				counts = append(counts, rr.Count)
+			}
+			require.Equal(t, v.counts, counts)
+		})
+	}
+}
+
+func Test_LifecycleReadings(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id      types.UUID
+		sensors []string
+		err     error
+	}{
+		"happy_path": {
+			id:      "begun",
+			sensors: []string{"begun sensor", "begun tub"},
+		},
+		"missing_lifecycle": {
+			id:  "missing",
+			err: fmt.Errorf("sql: no rows in result set"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			rs, err := db.LifecycleReadings(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+
+			sensors := []string{}
+			for _, r := range rs {
+				sensors = append(sensors, r.Sensor)
+			}
+			require.Equal(t, v.sensors, sensors)
+
+			srs, err := db.StageReadings(context.Background(), v.id, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, 1, len(srs))
+			require.Equal(t, len(v.sensors), len(srs[0].Readings))
+		})
+	}
+}
@@ -44,7 +44,7 @@ func Test_AddStrainSource(t *testing.T) {
 					Type:   "Clone",
 					Strain: strains[2],
 				},
-				err: fmt.Errorf("pq: source types can't be mixed"),
+				err: types.SourceError{Type: "Clone", Reason: "can't be mixed with 'Spore'"},
 			},
 		},
 		{
@@ -55,14 +55,14 @@ func Test_AddStrainSource(t *testing.T) {
 					Strain: strains[2],
 				},
 				g:   &failsourcecheck,
-				err: fmt.Errorf(checkConstraintViolation, "sources", "sources_type_check"),
+				err: types.SourceError{Type: "Fail", Reason: "unknown source type"},
 			},
 		},
 		{
 			k: "no_rows_affected_strain",
 			v: v{
 				s: types.Source{
-					Type:   "Clone",
+					Type:   "Spore",
 					Strain: types.Strain{UUID: "missing"},
 				},
 				err: fmt.Errorf("pq: no existing progenitor"),
@@ -85,7 +85,17 @@ func Test_AddStrainSource(t *testing.T) {
 					Type:   "Spore",
 					Strain: strains[4],
 				},
-				err: fmt.Errorf("pq: too many sources for this generation"),
+				err: types.SourceError{Type: "Spore", Reason: "a generation can only have 2"},
+			},
+		},
+		{
+			k: "tissue_transfer",
+			v: v{
+				s: types.Source{
+					Type:   "Tissue",
+					Strain: strains[3],
+				},
+				g: &failsourcecheck,
 			},
 		},
 	}
@@ -184,26 +194,22 @@ func Test_ChangeSource(t *testing.T) {
 	set := map[string]struct {
 		s      types.Source
 		origin string
-		result []types.Source
 		err    error
 	}{
 		"happy_path": {
 			origin: "strain",
 			s: func(s types.Source) types.Source {
-				// s.Type = "Clone"
-				s.Strain = strains[3]
+				s.Strain = strains[4]
 				return s
 			}(g.Sources[0]),
-			result: append(
-				[]types.Source{
-					func(s types.Source) types.Source {
-						// s.Type = "Clone"
-						s.Strain = strains[3]
-						return s
-					}(g.Sources[0]),
-				},
-				g.Sources[1],
-			),
+		},
+		"missing_progenitor": {
+			origin: "strain",
+			s: func(s types.Source) types.Source {
+				s.Strain = types.Strain{UUID: "missing"}
+				return s
+			}(g.Sources[1]),
+			err: fmt.Errorf("pq: no existing progenitor"),
 		},
 		"cant_mix_types": {
 			origin: "strain",
@@ -211,8 +217,7 @@ func Test_ChangeSource(t *testing.T) {
 				s.Type = "Clone"
 				return s
 			}(g.Sources[0]),
-			result: g.Sources,
-			err:    fmt.Errorf("pq: source types can't be mixed"),
+			err: types.SourceError{Type: "Clone", Reason: "can't be mixed with 'Spore'"},
 		},
 		"fail_type": {
 			origin: "strain",
@@ -220,8 +225,7 @@ func Test_ChangeSource(t *testing.T) {
 				s.Type = "Fail"
 				return s
 			}(g2.Sources[0]),
-			result: g2.Sources,
-			err:    fmt.Errorf(checkConstraintViolation, "sources", "sources_type_check"),
+			err: types.SourceError{Type: "Fail", Reason: "unknown source type"},
 		},
 	}
 	for name, tc := range set {
@@ -230,6 +234,17 @@ func Test_ChangeSource(t *testing.T) {
 			t.Parallel()
 			err := db.UpdateSource(context.Background(), tc.origin, tc.s, types.CID(name))
 			equalErrorMessages(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			refreshed := types.Generation{UUID: g.UUID}
+			require.Nil(t, db.GetSources(context.Background(), &refreshed, types.CID(name)))
+			for _, s := range refreshed.Sources {
+				if s.UUID == tc.s.UUID {
+					require.Equal(t, tc.s.Strain.UUID, s.Strain.UUID)
+				}
+			}
 		})
 	}
 }
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LifecycleStages(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path":        {id: "0"},
+		"missing_lifecycle": {id: "missing"},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.LifecycleStages(context.Background(), v.id, types.CID(k))
+			require.Equal(t, v.err, err)
+			for i, s := range result {
+				// spans never overlap and only the last one can still be open
+				require.NotEqual(t, types.AnyStage, s.Stage.Name)
+				if i < len(result)-1 {
+					require.NotNil(t, s.End)
+					require.Equal(t, *s.End, result[i+1].Begin)
+				}
+			}
+		})
+	}
+}
+
+func Test_StageDurations(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		by  types.Dimension
+		err error
+	}{
+		"by_strain":   {by: types.StrainDimension},
+		"by_grain":    {by: types.GrainDimension},
+		"by_bulk":     {by: types.BulkDimension},
+		"by_location": {by: types.LocationDimension},
+		"bogus":       {by: "bogus", err: fmt.Errorf("unknown dimension for stage durations: 'bogus'")},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.StageDurations(context.Background(), v.by, types.Window{}, types.CID(k))
+			require.Equal(t, v.err, err)
+			for _, r := range result {
+				require.Equal(t, v.by, r.Dimension)
+				require.LessOrEqual(t, r.Colonization.Min, r.Colonization.Median)
+				require.LessOrEqual(t, r.Colonization.Median, r.Colonization.Max)
+			}
+		})
+	}
+}
@@ -84,14 +84,27 @@ func Test_AddAttribute(t *testing.T) {
 		},
 		"no_rows_affected": {
 			s:   types.Strain{UUID: "missing"},
+			a:   types.StrainAttribute{Name: "new name", Value: "new value"},
 			err: fmt.Errorf("attribute was not added"),
 		},
 		"unique_key_violation": {
 			s:      strain,
-			a:      types.StrainAttribute{Name: "existing"},
+			a:      types.StrainAttribute{Name: "existing", Value: "again"},
 			result: 1,
 			err:    fmt.Errorf(uniqueKeyViolation, "strain_attributes_name_strain_uuid_key"),
 		},
+		"unknown_attribute": {
+			s:      strain,
+			a:      types.StrainAttribute{Name: "effervescence", Value: "fuzzy"},
+			result: 1,
+			err:    fmt.Errorf("unknown attribute: 'effervescence'"),
+		},
+		"invalid_value": {
+			s:      strain,
+			a:      types.StrainAttribute{Name: "headroom (cm)", Value: "tall"},
+			result: 1,
+			err:    fmt.Errorf("number value for 'headroom (cm)' isn't valid: 'tall'"),
+		},
 	}
 	for k, v := range set {
 		k, v := k, v
@@ -131,10 +144,15 @@ func Test_ChangeAttribute(t *testing.T) {
 			},
 		},
 		"no_rows_affected": {
-			a:      types.StrainAttribute{Name: "effervescence", Value: "fuzzy"},
+			a:      types.StrainAttribute{Name: "color", Value: "fuzzy"},
 			result: strain.Attributes[:],
 			err:    fmt.Errorf("attribute was not changed"),
 		},
+		"unknown_attribute": {
+			a:      types.StrainAttribute{UUID: strain.Attributes[0].UUID, Name: "effervescence", Value: "fuzzy"},
+			result: strain.Attributes[:],
+			err:    fmt.Errorf("unknown attribute: 'effervescence'"),
+		},
 	}
 	for k, v := range set {
 		k, v, strain := k, v, strain
@@ -180,3 +198,261 @@ func Test_RemoveAttribute(t *testing.T) {
 		})
 	}
 }
+
+func Test_AttributeDefinitions(t *testing.T) {
+	t.Parallel()
+
+	result, err := db.AttributeDefinitions(context.Background(), "Test_AttributeDefinitions")
+	require.Nil(t, err)
+	require.Subset(t, result, []types.AttributeDefinition{
+		{
+			UUID:     "contamination resistance",
+			Name:     "contamination resistance",
+			Type:     types.EnumAttribute,
+			Allowed:  []string{"low", "medium", "high"},
+			Synonyms: []string{},
+		},
+		{
+			UUID:     "headroom (cm)",
+			Name:     "headroom (cm)",
+			Type:     types.NumberAttribute,
+			Unit:     "cm",
+			Allowed:  []string{},
+			Synonyms: []string{},
+		},
+		{
+			UUID:     "color",
+			Name:     "color",
+			Type:     types.TextAttribute,
+			Allowed:  []string{},
+			Synonyms: []string{"colour"},
+		},
+	})
+}
+
+func Test_AddAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		d   types.AttributeDefinition
+		err error
+	}{
+		"happy_path": {
+			d: types.AttributeDefinition{Name: "fruiting temperature", Type: types.NumberAttribute, Unit: "C"},
+		},
+		"invalid_definition": {
+			d:   types.AttributeDefinition{Name: "cap shape", Type: types.EnumAttribute},
+			err: fmt.Errorf("an enum attribute needs allowed values: 'cap shape'"),
+		},
+		"name_is_a_synonym": {
+			d:   types.AttributeDefinition{Name: "Colour", Type: types.TextAttribute},
+			err: fmt.Errorf("'Colour' already means 'color'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			d, err := db.AddAttributeDefinition(context.Background(), v.d, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			require.NotEmpty(t, d.UUID)
+		})
+	}
+}
+
+func Test_ChangeAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		d      types.AttributeDefinition
+		result types.StrainAttribute
+		err    error
+	}{
+		"happy_path": {
+			d: types.AttributeDefinition{
+				UUID:    "change me!",
+				Name:    "changed!",
+				Type:    types.EnumAttribute,
+				Allowed: []string{"Small", "Large", "huge"},
+			},
+			result: types.StrainAttribute{UUID: "change me!", Name: "changed!", Value: "Small"},
+		},
+		"changed_type": {
+			d:   types.AttributeDefinition{UUID: "energy", Name: "energy", Type: types.NumberAttribute},
+			err: fmt.Errorf("an attribute's type can't be changed: 'energy'"),
+		},
+		"value_doesnt_fit": {
+			d:   types.AttributeDefinition{UUID: "vigor", Name: "vigor", Type: types.EnumAttribute, Allowed: []string{"medium", "high"}},
+			err: fmt.Errorf("'vigor' has to be one of medium, high: 'low'"),
+		},
+		"missing": {
+			d:   types.AttributeDefinition{UUID: "missing", Name: "missing", Type: types.TextAttribute},
+			err: fmt.Errorf("attribute definition was not changed"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.ChangeAttributeDefinition(context.Background(), v.d, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if err != nil {
+				return
+			}
+
+			s := types.Strain{UUID: "filter attribute"}
+			err = db.GetAllAttributes(context.Background(), &s, types.CID(k))
+			require.Nil(t, err)
+			require.Contains(t, s.Attributes, v.result)
+		})
+	}
+}
+
+func Test_RemoveAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "delete me!",
+		},
+		"in_use": {
+			id:  "preferred substrate",
+			err: fmt.Errorf(foreignKeyViolation1toMany, "attribute_definitions", "strain_attributes_name_fkey", "strain_attributes"),
+		},
+		"no_rows_affected": {
+			id:  "missing",
+			err: fmt.Errorf("attribute definition was not removed"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.RemoveAttributeDefinition(context.Background(), v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_AttributeSynonyms(t *testing.T) {
+	t.Parallel()
+
+	d := types.AttributeDefinition{UUID: "rhizomorphic", Name: "rhizomorphic", Type: types.BooleanAttribute}
+
+	err := db.AddAttributeSynonym(context.Background(), &d, "ropey", "Test_AttributeSynonyms")
+	require.Nil(t, err)
+	require.Equal(t, []string{"ropey"}, d.Synonyms)
+
+	err = db.AddAttributeSynonym(context.Background(), &d, "colour", "Test_AttributeSynonyms")
+	require.Equal(t, fmt.Errorf("'colour' already means 'color'"), err)
+
+	s, err := db.SelectStrain(context.Background(), "add attribute", "Test_AttributeSynonyms")
+	require.Nil(t, err)
+	a, err := db.AddAttribute(context.Background(), &s, types.StrainAttribute{Name: "Ropey", Value: "yes"}, "Test_AttributeSynonyms")
+	require.Nil(t, err)
+	require.Equal(t, "rhizomorphic", a.Name)
+	require.Equal(t, "true", a.Value)
+
+	err = db.RemoveAttributeSynonym(context.Background(), &d, "ropey", "Test_AttributeSynonyms")
+	require.Nil(t, err)
+	require.Equal(t, []string{}, d.Synonyms)
+
+	err = db.RemoveAttributeSynonym(context.Background(), &d, "remove me!", "Test_AttributeSynonyms")
+	require.Equal(t, fmt.Errorf("synonym was not removed"), err)
+
+	energy := types.AttributeDefinition{UUID: "energy", Name: "energy", Type: types.TextAttribute, Synonyms: []string{"remove me!"}}
+	err = db.RemoveAttributeSynonym(context.Background(), &energy, "remove me!", "Test_AttributeSynonyms")
+	require.Nil(t, err)
+	require.Equal(t, []string{}, energy.Synonyms)
+}
+
+func Test_MergeAttributeNames(t *testing.T) {
+	t.Parallel()
+
+	_, err := db.MergeAttributeNames(context.Background(), "merge from", "merge from", "Test_MergeAttributeNames")
+	require.Equal(t, fmt.Errorf("'merge from' and 'merge from' are already the same attribute"), err)
+
+	result, err := db.MergeAttributeNames(context.Background(), "merge from", "merge into", "Test_MergeAttributeNames")
+	require.Nil(t, err)
+	require.Equal(t, "merge into", result.Name)
+	require.Contains(t, result.Synonyms, "merge from")
+
+	for id, value := range map[types.UUID]string{"merge attribute 0": "3", "merge attribute 1": "5"} {
+		s := types.Strain{UUID: id}
+		err = db.GetAllAttributes(context.Background(), &s, "Test_MergeAttributeNames")
+		require.Nil(t, err)
+		require.Equal(t, 1, len(s.Attributes), "strain: %s", id)
+		require.Equal(t, "merge into", s.Attributes[0].Name)
+		require.Equal(t, value, s.Attributes[0].Value)
+	}
+
+	defs, err := db.AttributeDefinitions(context.Background(), "Test_MergeAttributeNames")
+	require.Nil(t, err)
+	d, ok := types.FindAttribute(defs, "merge from")
+	require.True(t, ok)
+	require.Equal(t, "merge into", d.Name)
+}
+
+func Test_SelectStrainsByAttribute(t *testing.T) {
+	t.Parallel()
+
+	lo, hi := 20.0, 30.0
+	set := map[string]struct {
+		filters []types.AttributeFilter
+		names   []string
+		err     error
+	}{
+		"range": {
+			filters: []types.AttributeFilter{{Name: "headroom (cm)", Min: &lo, Max: &hi}},
+			names:   []string{"Morel"},
+		},
+		"open_range": {
+			filters: []types.AttributeFilter{{Name: "headroom (cm)", Min: &hi}},
+			names:   []string{"filter attribute"},
+		},
+		"values": {
+			filters: []types.AttributeFilter{{Name: "contamination resistance", Values: []string{"HIGH", "medium"}}},
+			names:   []string{"Morel"},
+		},
+		"synonym": {
+			filters: []types.AttributeFilter{{Name: "colour", Values: []string{"purple"}}},
+			names:   []string{"Hens o' the Wood"},
+		},
+		"every_filter": {
+			filters: []types.AttributeFilter{
+				{Name: "headroom (cm)", Min: &lo},
+				{Name: "contamination resistance", Values: []string{"low"}},
+			},
+			names: []string{"filter attribute"},
+		},
+		"unknown_attribute": {
+			filters: []types.AttributeFilter{{Name: "effervescence"}},
+			err:     fmt.Errorf("unknown attribute: 'effervescence'"),
+		},
+		"range_on_text": {
+			filters: []types.AttributeFilter{{Name: "color", Min: &lo}},
+			err:     fmt.Errorf("only number attributes can have a range: 'color'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.SelectAllStrains(types.WithAttributes(context.Background(), v.filters...), types.CID(k))
+			require.Equal(t, v.err, err)
+			if err != nil {
+				return
+			}
+
+			names := []string{}
+			for _, s := range result {
+				names = append(names, s.Name)
+			}
+			require.Equal(t, v.names, names)
+		})
+	}
+}
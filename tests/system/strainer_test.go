@@ -199,3 +199,104 @@ func Test_DeleteStrain(t *testing.T) {
 		})
 	}
 }
+
+func Test_StrainFiliation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("promoted", func(t *testing.T) {
+		t.Parallel()
+
+		gid, root := types.UUID("filial"), types.UUID("filial parent")
+
+		err := db.UpdateGeneratedStrain(context.Background(), &gid, "filial", "promoted")
+		require.Nil(t, err)
+
+		result, err := db.SelectStrain(context.Background(), "filial", "promoted")
+		require.Nil(t, err)
+		require.Equal(t, &types.Filiation{Depth: 1, Notation: "clone of P", Root: &root}, result.Filiation)
+	})
+
+	depth, root := 2, types.UUID("lineage 0")
+	set := map[string]struct {
+		filter types.FilialFilter
+		names  []string
+	}{
+		"by_root": {
+			filter: types.FilialFilter{Root: &root},
+			names:  []string{"lineage child", "lineage grandparent", "lineage parent"},
+		},
+		"by_depth_and_root": {
+			filter: types.FilialFilter{Depth: &depth, Root: &root},
+			names:  []string{"lineage child"},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.SelectAllStrains(types.WithFilial(context.Background(), v.filter), types.CID(k))
+			require.Nil(t, err)
+
+			names := []string{}
+			for _, s := range result {
+				names = append(names, s.Name)
+			}
+			require.Equal(t, v.names, names)
+		})
+	}
+}
+
+func Test_PromoteGeneration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("promoted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, root := context.Background(), types.UUID("promote parent 0")
+
+		result, err := db.PromoteGeneration(ctx, "promote", types.PromoteOptions{
+			Name:    "promoted",
+			Inherit: types.InheritAgreed,
+			Note:    "keeper",
+		}, "promoted")
+		require.Nil(t, err)
+		require.Equal(t, "X.test", result.Species)
+		require.Equal(t, types.SelfVendor, result.Vendor.UUID)
+		require.Equal(t, &types.Filiation{Depth: 1, Notation: "F1", Root: &root}, result.Filiation)
+
+		s, err := db.SelectStrain(ctx, result.UUID, "promoted")
+		require.Nil(t, err)
+		require.Equal(t, types.UUID("promote"), s.Generation.UUID)
+		require.Equal(t, 1, len(s.Attributes))
+		require.Equal(t, "color", s.Attributes[0].Name)
+		require.Equal(t, "gold", s.Attributes[0].Value)
+
+		notes, err := db.GetNotes(ctx, "promote", "promoted")
+		require.Nil(t, err)
+		require.Equal(t, 1, len(notes))
+		require.Equal(t, "promoted to strain 'promoted' from Spore of 'promote parent 0', Spore of 'promote parent 1'\nkeeper", notes[0].Note)
+
+		_, err = db.PromoteGeneration(ctx, "promote", types.PromoteOptions{Name: "again"}, "promoted")
+		equalErrorMessages(t, fmt.Errorf("generation was already promoted to '%s'", result.UUID), err)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		_, err := db.PromoteGeneration(ctx, "promote strict", types.PromoteOptions{
+			Name:    "strict",
+			Inherit: types.InheritStrict,
+		}, "strict")
+		equalErrorMessages(t, fmt.Errorf("parents disagree about attribute 'vigor': 'high' and 'low'"), err)
+
+		_, err = db.GeneratedStrain(ctx, "promote strict", "strict")
+		require.Equal(t, sql.ErrNoRows, err)
+
+		notes, err := db.GetNotes(ctx, "promote strict", "strict")
+		require.Nil(t, err)
+		require.Empty(t, notes)
+	})
+}
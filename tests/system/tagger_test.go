@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jsmit257/huautla/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KnownTags(t *testing.T) {
+	t.Parallel()
+
+	result, err := db.KnownTags(context.Background(), "Test_KnownTags")
+	require.Nil(t, err)
+	require.Contains(t, result, "keeper")
+	require.Contains(t, result, "competition")
+}
+
+func Test_GetTags(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		result []string
+	}{
+		"happy_path": {
+			id:     "0",
+			result: []string{"competition", "keeper"},
+		},
+		"untagged": {
+			id:     "missing",
+			result: []string{},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			result, err := db.GetTags(context.Background(), v.id, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, v.result, result)
+		})
+	}
+}
+
+func Test_AddTag(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		tag string
+		err error
+	}{
+		"happy_path": {
+			id:  "spore",
+			tag: " Added Tag",
+		},
+		"duplicate_tag": {
+			id:  "0",
+			tag: "Keeper",
+			err: fmt.Errorf("unique key violation: Key (taggable_uuid, tag)=(0, keeper) already exists."),
+		},
+		"not_taggable": {
+			id:  "missing",
+			tag: "keeper",
+			err: fmt.Errorf("tag was not added, 'missing' isn't taggable"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.AddTag(context.Background(), v.id, v.tag, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+			if v.err != nil {
+				return
+			}
+			tags, err := db.GetTags(context.Background(), v.id, types.CID(k))
+			require.Nil(t, err)
+			require.Contains(t, tags, "added tag")
+		})
+	}
+}
+
+func Test_RemoveTag(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		tag string
+		err error
+	}{
+		"happy_path": {
+			id:  "1",
+			tag: "remove me",
+		},
+		"no_rows_affected": {
+			id:  "1",
+			tag: "missing",
+			err: fmt.Errorf("tag could not be removed: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			err := db.RemoveTag(context.Background(), v.id, v.tag, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_SelectLifecycleIndexByTag(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		tags     []string
+		contains types.UUID
+		excludes types.UUID
+	}{
+		"one_tag": {
+			tags:     []string{"competition"},
+			contains: "1",
+			excludes: "clone",
+		},
+		"every_tag": {
+			tags:     []string{"Competition", "keeper"},
+			contains: "0",
+			excludes: "1",
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+			ctx := types.WithTags(context.Background(), v.tags...)
+			result, err := db.SelectLifecycleIndex(ctx, types.CID(k))
+			require.Nil(t, err)
+
+			ids := []types.UUID{}
+			for _, lc := range result {
+				ids = append(ids, lc.UUID)
+			}
+			require.Contains(t, ids, v.contains)
+			require.NotContains(t, ids, v.excludes)
+		})
+	}
+}
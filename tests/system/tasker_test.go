@@ -0,0 +1,226 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsmit257/huautla/types"
+)
+
+func Test_GetFollowUps(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		result []string
+	}{
+		"happy_path": {
+			id:     "9",
+			result: []string{"Check colonization"},
+		},
+		"no_follow_ups": {
+			id:     "0",
+			result: []string{},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: v.id}
+			err := db.GetFollowUps(context.Background(), &et, types.CID(k))
+			require.Nil(t, err)
+
+			names := []string{}
+			for _, f := range et.FollowUps {
+				names = append(names, f.Name)
+			}
+			require.Equal(t, v.result, names)
+		})
+	}
+}
+
+func Test_AddFollowUp(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		f   types.FollowUp
+		err error
+	}{
+		"happy_path": {
+			id: "follow ups",
+			f:  types.FollowUp{Name: "added follow up", Days: 3, Expects: &types.EventType{UUID: "2"}},
+		},
+		"missing_eventtype": {
+			id:  "missing",
+			f:   types.FollowUp{Name: "added follow up"},
+			err: fmt.Errorf("foreign key violation: Key (eventtype_uuid)=(missing) is not present in table \"event_types\"., follow_ups."),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: v.id}
+			_, err := db.AddFollowUp(context.Background(), &et, v.f, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_ChangeFollowUp(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		f   types.FollowUp
+		err error
+	}{
+		"happy_path": {
+			f: types.FollowUp{UUID: "change follow up", Name: "changed follow up", Days: 2},
+		},
+		"missing_follow_up": {
+			f:   types.FollowUp{UUID: "missing", Name: "missing"},
+			err: fmt.Errorf("follow-up was not changed: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "follow ups"}
+			err := db.ChangeFollowUp(context.Background(), &et, v.f, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_RemoveFollowUp(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id  types.UUID
+		err error
+	}{
+		"happy_path": {
+			id: "remove follow up",
+		},
+		"missing_follow_up": {
+			id:  "missing",
+			err: fmt.Errorf("follow-up could not be removed: 'missing'"),
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			et := types.EventType{UUID: "follow ups"}
+			err := db.RemoveFollowUp(context.Background(), &et, v.id, types.CID(k))
+			equalErrorMessages(t, v.err, err)
+		})
+	}
+}
+
+func Test_DueTasks(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		by       time.Time
+		contains []types.UUID
+		excludes []types.UUID
+	}{
+		"due_next_week": {
+			by:       time.Now().Add(8 * 24 * time.Hour),
+			contains: []types.UUID{"begun"},
+			// retired has a RIP event, so it doesn't owe anything
+			excludes: []types.UUID{"retired"},
+		},
+		"nothing_due_yet": {
+			by:       time.Now(),
+			excludes: []types.UUID{"begun", "retired"},
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := db.DueTasks(context.Background(), v.by, types.CID(k))
+			require.Nil(t, err)
+
+			owners := []types.UUID{}
+			for _, task := range result {
+				owners = append(owners, task.ObservableUUID)
+				require.Nil(t, task.Done)
+			}
+			for _, id := range v.contains {
+				require.Contains(t, owners, id)
+			}
+			for _, id := range v.excludes {
+				require.NotContains(t, owners, id)
+			}
+		})
+	}
+}
+
+func Test_ObservableTasks(t *testing.T) {
+	t.Parallel()
+
+	result, err := db.ObservableTasks(context.Background(), "retired", "Test_ObservableTasks")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(result))
+	require.Equal(t, "Check colonization", result[0].FollowUp.Name)
+	require.Equal(t, types.LifecycleParent, result[0].ObservableType)
+	require.Equal(t, types.UUID("retired begin"), result[0].EventUUID)
+}
+
+func Test_CompleteTask(t *testing.T) {
+	t.Parallel()
+
+	set := map[string]struct {
+		id     types.UUID
+		e      *types.Event
+		record bool
+	}{
+		"happy_path": {
+			id: "complete task",
+		},
+		"expected_event": {
+			id:     "complete task event",
+			e:      &types.Event{Temperature: 21},
+			record: true,
+		},
+	}
+	for k, v := range set {
+		k, v := k, v
+		t.Run(k, func(t *testing.T) {
+			t.Parallel()
+
+			tasks, err := db.ObservableTasks(context.Background(), v.id, types.CID(k))
+			require.Nil(t, err)
+			require.Equal(t, 1, len(tasks))
+
+			result, err := db.CompleteTask(context.Background(), tasks[0].UUID, v.e, types.CID(k))
+			require.Nil(t, err)
+			require.NotNil(t, result.Done)
+			require.Equal(t, v.record, result.Result != nil)
+
+			if v.record {
+				e, err := db.SelectEvent(context.Background(), *result.Result, types.CID(k))
+				require.Nil(t, err)
+				require.Equal(t, types.UUID("2"), e.EventType.UUID)
+			}
+
+			_, err = db.CompleteTask(context.Background(), tasks[0].UUID, nil, types.CID(k))
+			equalErrorMessages(t, fmt.Errorf("task was already done: '%s'", tasks[0].UUID), err)
+		})
+	}
+}
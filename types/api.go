@@ -2,29 +2,76 @@ package types
 
 import (
 	"context"
+	"time"
 )
 
 type (
 	DB interface {
+		Batcher
+		ContaminationRater
+		Coster
 		EventTyper
+		Experimenter
+		Forecaster
 		Generationer
 		GenerationEventer
+		Harvester
 		Ingredienter
 		LifecycleEventer
 		Lifecycler
+		Lineager
+		Locationer
+		Measurer
 		Noter
 		Observer
 		Photoer
+		Protocoler
+		Sensorer
 		Sourcer
 		Stager
+		StageTimer
 		StrainAttributer
 		Strainer
 		SubstrateIngredienter
 		Substrater
+		Tagger
+		Tasker
 		Timestamper
 		Vendorer
 	}
 
+	// Batcher groups lifecycles that were started together. CreateBatch makes
+	// n lifecycles from template all at once; batch events, notes and photos
+	// are all-or-nothing for the members that are still in step, see
+	// BatchMember.InStep, and a photo goes on each member's latest event
+	Batcher interface {
+		SelectAllBatches(ctx context.Context, cid CID) ([]Batch, error)
+		SelectBatch(ctx context.Context, id UUID, cid CID) (Batch, error)
+		CreateBatch(ctx context.Context, name string, template Lifecycle, n int, cid CID) (Batch, error)
+		UpdateBatch(ctx context.Context, id UUID, b Batch, cid CID) error
+		DeleteBatch(ctx context.Context, id UUID, cid CID) error
+		AddBatchEvent(ctx context.Context, b *Batch, e Event, cid CID) error
+		AddBatchNote(ctx context.Context, b *Batch, n Note, cid CID) error
+		AddBatchPhoto(ctx context.Context, b *Batch, p Photo, cid CID) error
+		ExcludeBatchMember(ctx context.Context, b *Batch, id UUID, cid CID) error
+	}
+
+	// ContaminationRater counts lifecycles and generations that have at least
+	// one Error, Fatal or RIP event; Contaminations is the drill-down for a
+	// single group from ContaminationRates
+	ContaminationRater interface {
+		ContaminationRates(ctx context.Context, by Dimension, w Window, cid CID) ([]ContaminationRate, error)
+		Contaminations(ctx context.Context, by Dimension, key string, w Window, cid CID) ([]Contamination, error)
+	}
+
+	// Coster is read-only; every figure is derived from the cost, yield and
+	// gross columns already on lifecycles
+	Coster interface {
+		LifecycleCost(ctx context.Context, id UUID, cid CID) (LifecycleCost, error)
+		SelectLifecycleCosts(ctx context.Context, w Window, cid CID) ([]LifecycleCost, error)
+		CostRollup(ctx context.Context, by Dimension, w Window, cid CID) ([]CostRollup, error)
+	}
+
 	EventTyper interface {
 		SelectAllEventTypes(ctx context.Context, cid CID) ([]EventType, error)
 		SelectEventType(ctx context.Context, id UUID, cid CID) (EventType, error)
@@ -34,9 +81,32 @@ type (
 		EventTypeReport(context.Context, UUID, CID) (Entity, error)
 	}
 
+	// Experimenter manages experiments, their arms and which arm each of their
+	// lifecycles is in; assigning a lifecycle that's already in another arm
+	// moves it. ExperimentReport compares the arms, see NewExperimentReport
+	Experimenter interface {
+		SelectAllExperiments(ctx context.Context, cid CID) ([]Experiment, error)
+		SelectExperiment(ctx context.Context, id UUID, cid CID) (Experiment, error)
+		InsertExperiment(ctx context.Context, e Experiment, cid CID) (Experiment, error)
+		UpdateExperiment(ctx context.Context, id UUID, e Experiment, cid CID) error
+		DeleteExperiment(ctx context.Context, id UUID, cid CID) error
+		AddExperimentArm(ctx context.Context, e *Experiment, a ExperimentArm, cid CID) (ExperimentArm, error)
+		RemoveExperimentArm(ctx context.Context, e *Experiment, id UUID, cid CID) error
+		AssignExperimentLifecycle(ctx context.Context, e *Experiment, armID, lcID UUID, cid CID) error
+		UnassignExperimentLifecycle(ctx context.Context, e *Experiment, lcID UUID, cid CID) error
+		ExperimentReport(ctx context.Context, id UUID, cid CID) (ExperimentReport, error)
+	}
+
+	// Forecaster estimates a lifecycle's milestones from past lifecycles of the
+	// same strain, see NewForecast
+	Forecaster interface {
+		LifecycleForecast(ctx context.Context, id UUID, cid CID) (Forecast, error)
+	}
+
 	Generationer interface {
-		SelectGenerationIndex(context.Context, CID) ([]Generation, error)
+		SelectGenerationIndex(context.Context, CID, ...Status) ([]Generation, error)
 		SelectGeneration(context.Context, UUID, CID) (Generation, error)
+		SelectGenerations(ctx context.Context, ids []UUID, cid CID) ([]Generation, error)
 		InsertGeneration(context.Context, Generation, CID) (Generation, error)
 		UpdateGeneration(context.Context, Generation, CID) (Generation, error)
 		DeleteGeneration(context.Context, UUID, CID) error
@@ -50,6 +120,15 @@ type (
 		RemoveGenerationEvent(ctx context.Context, g *Generation, id UUID, cid CID) error
 	}
 
+	// Harvester keeps a lifecycle's flushes in date order; every change
+	// re-tallies the lifecycle's yield, count and gross from what's left
+	Harvester interface {
+		GetHarvests(ctx context.Context, lc *Lifecycle, cid CID) error
+		AddHarvest(ctx context.Context, lc *Lifecycle, h Harvest, cid CID) error
+		ChangeHarvest(ctx context.Context, lc *Lifecycle, h Harvest, cid CID) (Harvest, error)
+		RemoveHarvest(ctx context.Context, lc *Lifecycle, id UUID, cid CID) error
+	}
+
 	Ingredienter interface {
 		SelectAllIngredients(ctx context.Context, cid CID) ([]Ingredient, error)
 		SelectIngredient(ctx context.Context, id UUID, cid CID) (Ingredient, error)
@@ -66,16 +145,56 @@ type (
 	}
 
 	Lifecycler interface {
-		SelectLifecycleIndex(ctx context.Context, cid CID) ([]Lifecycle, error)
+		SelectLifecycleIndex(ctx context.Context, cid CID, statuses ...Status) ([]Lifecycle, error)
 		SelectLifecycle(ctx context.Context, id UUID, cid CID) (Lifecycle, error)
+		SelectLifecycles(ctx context.Context, ids []UUID, cid CID) ([]Lifecycle, error)
+		StrainLifecyclesFor(ctx context.Context, strains []UUID, cid CID) (map[UUID][]UUID, error)
 		InsertLifecycle(ctx context.Context, lc Lifecycle, cid CID) (Lifecycle, error)
 		UpdateLifecycle(ctx context.Context, lc Lifecycle, cid CID) (Lifecycle, error)
 		DeleteLifecycle(ctx context.Context, id UUID, cid CID) error
 		LifecycleReport(context.Context, UUID, CID) (Entity, error)
 	}
 
+	// Lineager walks strains through the generations that produced them, up
+	// to depth generations away, or all the way when depth isn't positive; a
+	// node that's already been reached isn't walked again, so a cycle in the
+	// data can't send it around forever; Pedigree is both directions at once
+	// and Library is every strain there is, and only those two fill in the
+	// outcome of generations and lifecycles
+	Lineager interface {
+		Ancestry(ctx context.Context, strainID UUID, depth int, cid CID) (Lineage, error)
+		Descendants(ctx context.Context, strainID UUID, depth int, cid CID) (Lineage, error)
+		Pedigree(ctx context.Context, strainID UUID, depth int, cid CID) (Lineage, error)
+		Library(ctx context.Context, cid CID) (Lineage, error)
+	}
+
+	// Locationer manages the places lifecycles are kept; LocationOccupancy is
+	// what's in one right now, see NewOccupancy
+	Locationer interface {
+		SelectAllLocations(ctx context.Context, cid CID) ([]Location, error)
+		SelectLocation(ctx context.Context, id UUID, cid CID) (Location, error)
+		InsertLocation(ctx context.Context, loc Location, cid CID) (Location, error)
+		UpdateLocation(ctx context.Context, id UUID, loc Location, cid CID) error
+		DeleteLocation(ctx context.Context, id UUID, cid CID) error
+		LocationOccupancy(ctx context.Context, id UUID, cid CID) (Occupancy, error)
+	}
+
+	// Measurer manages the fields an event type declares; events record values
+	// for them in Measurements, which are checked against the fields whenever
+	// an event is added or changed, see NewMeasurements. A field's type can't
+	// be changed, and removing a field removes every value recorded for it.
+	// MeasurementStats summarizes a number or integer field across lifecycles
+	Measurer interface {
+		GetFields(ctx context.Context, et *EventType, cid CID) error
+		AddField(ctx context.Context, et *EventType, f Field, cid CID) (Field, error)
+		ChangeField(ctx context.Context, et *EventType, f Field, cid CID) error
+		RemoveField(ctx context.Context, et *EventType, id UUID, cid CID) error
+		MeasurementStats(ctx context.Context, fieldID UUID, by Dimension, w Window, cid CID) ([]MeasurementStats, error)
+	}
+
 	Noter interface {
 		GetNotes(ctx context.Context, id UUID, cid CID) ([]Note, error)
+		GetNotesFor(ctx context.Context, ids []UUID, cid CID) (map[UUID][]Note, error)
 		AddNote(ctx context.Context, id UUID, notes []Note, n Note, cid CID) ([]Note, error)
 		ChangeNote(ctx context.Context, notes []Note, n Note, cid CID) ([]Note, error)
 		RemoveNote(ctx context.Context, notes []Note, id UUID, cid CID) ([]Note, error)
@@ -93,22 +212,59 @@ type (
 	Photoer interface {
 		AllPhotos(ctx context.Context, cid CID) ([]Photo, error)
 		GetPhotos(ctx context.Context, id UUID, cid CID) ([]Photo, error)
+		GetPhotosFor(ctx context.Context, ids []UUID, cid CID) (map[UUID][]Photo, error)
 		AddPhoto(ctx context.Context, id UUID, photos []Photo, p Photo, cid CID) ([]Photo, error)
 		ChangePhoto(ctx context.Context, photos []Photo, p Photo, cid CID) ([]Photo, error)
 		RemovePhoto(ctx context.Context, photos []Photo, id UUID, cid CID) ([]Photo, error)
 	}
 
+	// Protocoler manages protocols and their steps, and which one a lifecycle
+	// or generation follows; assigning a nil protocol takes it away again.
+	// ProtocolDeviations compares the observable's events to its protocol,
+	// see NewDeviationReport
+	Protocoler interface {
+		SelectAllProtocols(ctx context.Context, cid CID) ([]Protocol, error)
+		SelectProtocol(ctx context.Context, id UUID, cid CID) (Protocol, error)
+		InsertProtocol(ctx context.Context, p Protocol, cid CID) (Protocol, error)
+		UpdateProtocol(ctx context.Context, id UUID, p Protocol, cid CID) error
+		DeleteProtocol(ctx context.Context, id UUID, cid CID) error
+		AddProtocolStep(ctx context.Context, p *Protocol, s ProtocolStep, cid CID) (ProtocolStep, error)
+		ChangeProtocolStep(ctx context.Context, p *Protocol, s ProtocolStep, cid CID) error
+		RemoveProtocolStep(ctx context.Context, p *Protocol, id UUID, cid CID) error
+		AssignProtocol(ctx context.Context, oID UUID, pID *UUID, cid CID) error
+		ObservableProtocol(ctx context.Context, oID UUID, cid CID) (*Protocol, error)
+		ProtocolDeviations(ctx context.Context, oID UUID, cid CID) (DeviationReport, error)
+	}
+
 	ReportAttrs interface {
 		Contains(names ...string) bool
 		Get(name string) *UUID
 		Set(name string, value string) error
 	}
 
+	// Sensorer stores sensor readings by location; a lifecycle's readings are
+	// the ones from its location, less any sensors dedicated to some other
+	// lifecycle, between its ctime and its last stage ending
+	Sensorer interface {
+		InsertReadings(ctx context.Context, rs []Reading, cid CID) error
+		SelectReadings(ctx context.Context, location string, w Window, cid CID) ([]Reading, error)
+		ReadingRollups(ctx context.Context, location string, res Resolution, w Window, cid CID) ([]ReadingRollup, error)
+		LifecycleReadings(ctx context.Context, id UUID, cid CID) ([]Reading, error)
+		LifecycleReadingRollups(ctx context.Context, id UUID, res Resolution, cid CID) ([]ReadingRollup, error)
+		StageReadings(ctx context.Context, id UUID, cid CID) ([]StageReadings, error)
+	}
+
+	// Sourcer adds and changes a generation's sources, within the limits of
+	// the SourceTypes policies, see CheckSource. A source's progenitor is a
+	// strain or a generation event, depending on origin, and UpdateSource
+	// can move it from one to the other. GetSources replaces g.Sources with
+	// what's stored, so it's safe to call again after a change
 	Sourcer interface {
-		// GetSources(ctx context.Context, g *Generation, cid CID) error
+		GetSources(ctx context.Context, g *Generation, cid CID) error
 		InsertSource(context.Context, UUID, string, Source, CID) (Source, error)
 		UpdateSource(context.Context, string, Source, CID) error
 		RemoveSource(context.Context, *Generation, UUID, CID) error
+		SourceTypes(ctx context.Context, cid CID) ([]SourceType, error)
 	}
 
 	Stager interface {
@@ -119,12 +275,32 @@ type (
 		DeleteStage(ctx context.Context, id UUID, cid CID) error
 	}
 
+	// StageTimer works out when each stage began and ended from the events an
+	// observable has collected, see NewStageSpans
+	StageTimer interface {
+		LifecycleStages(ctx context.Context, id UUID, cid CID) ([]StageSpan, error)
+		GenerationStages(ctx context.Context, id UUID, cid CID) ([]StageSpan, error)
+		StageDurations(ctx context.Context, by Dimension, w Window, cid CID) ([]StageDurations, error)
+	}
+
+	// StrainAttributer keeps strain attributes to the vocabulary in
+	// AttributeDefinitions; AddAttribute and ChangeAttribute reject anything
+	// CheckAttribute does, and SelectAllStrains can filter on attributes, see
+	// WithAttributes
 	StrainAttributer interface {
 		KnownAttributeNames(ctx context.Context, cid CID) ([]string, error)
 		GetAllAttributes(ctx context.Context, s *Strain, cid CID) error
+		GetAttributesFor(ctx context.Context, ids []UUID, cid CID) (map[UUID][]StrainAttribute, error)
 		AddAttribute(ctx context.Context, s *Strain, a StrainAttribute, cid CID) (StrainAttribute, error)
 		ChangeAttribute(ctx context.Context, s *Strain, a StrainAttribute, cid CID) error
 		RemoveAttribute(ctx context.Context, s *Strain, id UUID, cid CID) error
+		AttributeDefinitions(ctx context.Context, cid CID) ([]AttributeDefinition, error)
+		AddAttributeDefinition(ctx context.Context, d AttributeDefinition, cid CID) (AttributeDefinition, error)
+		ChangeAttributeDefinition(ctx context.Context, d AttributeDefinition, cid CID) error
+		RemoveAttributeDefinition(ctx context.Context, id UUID, cid CID) error
+		AddAttributeSynonym(ctx context.Context, d *AttributeDefinition, synonym string, cid CID) error
+		RemoveAttributeSynonym(ctx context.Context, d *AttributeDefinition, synonym string, cid CID) error
+		MergeAttributeNames(ctx context.Context, from, into string, cid CID) (AttributeDefinition, error)
 	}
 
 	Strainer interface {
@@ -134,12 +310,15 @@ type (
 		UpdateStrain(ctx context.Context, id UUID, s Strain, cid CID) error
 		DeleteStrain(ctx context.Context, id UUID, cid CID) error
 		GeneratedStrain(ctx context.Context, id UUID, cid CID) (Strain, error)
+		GeneratedStrains(ctx context.Context, ids []UUID, cid CID) (map[UUID]Strain, error)
 		UpdateGeneratedStrain(ctx context.Context, gid *UUID, sid UUID, cid CID) error
+		PromoteGeneration(ctx context.Context, gid UUID, opts PromoteOptions, cid CID) (Strain, error)
 		StrainReport(context.Context, UUID, CID) (Entity, error)
 	}
 
 	SubstrateIngredienter interface {
 		GetAllIngredients(ctx context.Context, s *Substrate, cid CID) error
+		GetIngredientsFor(ctx context.Context, ids []UUID, cid CID) (map[UUID][]Ingredient, error)
 		AddIngredient(ctx context.Context, s *Substrate, i Ingredient, cid CID) error
 		ChangeIngredient(ctx context.Context, s *Substrate, oldI, newI Ingredient, cid CID) error
 		RemoveIngredient(ctx context.Context, s *Substrate, i Ingredient, cid CID) error
@@ -154,6 +333,32 @@ type (
 		SubstrateReport(context.Context, UUID, CID) (Entity, error)
 	}
 
+	// Tagger labels lifecycles, generations, strains, substrates, events and
+	// photos; tags are normalized, see NormalizeTag. The lifecycle and
+	// generation indexes, SelectAllStrains, SelectAllSubstrates,
+	// SelectByEventType and AllPhotos only return the ones that have every tag
+	// in the context, see WithTags
+	Tagger interface {
+		KnownTags(ctx context.Context, cid CID) ([]string, error)
+		GetTags(ctx context.Context, id UUID, cid CID) ([]string, error)
+		GetTagsFor(ctx context.Context, ids []UUID, cid CID) (map[UUID][]string, error)
+		AddTag(ctx context.Context, id UUID, tag string, cid CID) error
+		RemoveTag(ctx context.Context, id UUID, tag string, cid CID) error
+	}
+
+	// Tasker manages the follow-ups an event type calls for and the tasks they
+	// turn into; tasks are created by the database whenever an event is
+	// recorded, so there's nothing here to add one directly
+	Tasker interface {
+		GetFollowUps(ctx context.Context, et *EventType, cid CID) error
+		AddFollowUp(ctx context.Context, et *EventType, f FollowUp, cid CID) (FollowUp, error)
+		ChangeFollowUp(ctx context.Context, et *EventType, f FollowUp, cid CID) error
+		RemoveFollowUp(ctx context.Context, et *EventType, id UUID, cid CID) error
+		DueTasks(ctx context.Context, by time.Time, cid CID) ([]Task, error)
+		ObservableTasks(ctx context.Context, id UUID, cid CID) ([]Task, error)
+		CompleteTask(ctx context.Context, id UUID, e *Event, cid CID) (Task, error)
+	}
+
 	Timestamper interface {
 		Undelete(context.Context, string, UUID) error
 		UpdateTimestamps(context.Context, string, UUID, Timestamp) error
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CheckAttributeDefinition is for a definition that's about to be added or
+// changed
+func CheckAttributeDefinition(d AttributeDefinition) error {
+	if strings.TrimSpace(d.Name) == "" {
+		return fmt.Errorf("an attribute needs a name")
+	} else if d.Unit != "" && d.Type != NumberAttribute {
+		return fmt.Errorf("only number attributes can have a unit: '%s'", d.Name)
+	}
+
+	switch d.Type {
+	case EnumAttribute:
+		if len(d.Allowed) == 0 {
+			return fmt.Errorf("an enum attribute needs allowed values: '%s'", d.Name)
+		}
+		seen := make(map[string]struct{}, len(d.Allowed))
+		for _, a := range d.Allowed {
+			k := strings.ToLower(strings.TrimSpace(a))
+			if k == "" {
+				return fmt.Errorf("'%s' can't allow an empty value", d.Name)
+			} else if _, ok := seen[k]; ok {
+				return fmt.Errorf("'%s' is allowed more than once for '%s'", a, d.Name)
+			}
+			seen[k] = struct{}{}
+		}
+	case NumberAttribute, BooleanAttribute, TextAttribute:
+		if len(d.Allowed) != 0 {
+			return fmt.Errorf("only enum attributes can have allowed values: '%s'", d.Name)
+		}
+	default:
+		return fmt.Errorf("unknown attribute type: '%s'", d.Type)
+	}
+
+	return nil
+}
+
+// FindAttribute looks name up by the definitions' names and synonyms, without
+// regard to case
+func FindAttribute(defs []AttributeDefinition, name string) (AttributeDefinition, bool) {
+	name = strings.TrimSpace(name)
+	for _, d := range defs {
+		if strings.EqualFold(d.Name, name) {
+			return d, true
+		}
+		for _, s := range d.Synonyms {
+			if strings.EqualFold(s, name) {
+				return d, true
+			}
+		}
+	}
+	return AttributeDefinition{}, false
+}
+
+// CheckAttribute returns a with the name of the definition it matches and
+// its value the way that definition spells it: numbers without trailing
+// zeros, booleans as true or false and enums as they're allowed, so values
+// compare the same way no matter who typed them
+func CheckAttribute(defs []AttributeDefinition, a StrainAttribute) (StrainAttribute, error) {
+	d, ok := FindAttribute(defs, a.Name)
+	if !ok {
+		return a, fmt.Errorf("unknown attribute: '%s'", a.Name)
+	}
+
+	v, err := d.normalize(a.Value)
+	if err != nil {
+		return a, err
+	}
+
+	return StrainAttribute{UUID: a.UUID, Name: d.Name, Value: v}, nil
+}
+
+// CheckAttributeFilters returns fs with their names and values normalized
+// the same way CheckAttribute does; only number attributes can have a range
+func CheckAttributeFilters(defs []AttributeDefinition, fs []AttributeFilter) ([]AttributeFilter, error) {
+	result := make([]AttributeFilter, 0, len(fs))
+	for _, f := range fs {
+		d, ok := FindAttribute(defs, f.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute: '%s'", f.Name)
+		} else if (f.Min != nil || f.Max != nil) && d.Type != NumberAttribute {
+			return nil, fmt.Errorf("only number attributes can have a range: '%s'", d.Name)
+		} else if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return nil, fmt.Errorf("filter for '%s' has a min greater than its max", d.Name)
+		}
+
+		checked := AttributeFilter{Name: d.Name, Min: f.Min, Max: f.Max}
+		for _, v := range f.Values {
+			n, err := d.normalize(v)
+			if err != nil {
+				return nil, err
+			}
+			checked.Values = append(checked.Values, n)
+		}
+		result = append(result, checked)
+	}
+	return result, nil
+}
+
+// WithAttributes asks SelectAllStrains to only return strains that match
+// every one of fs, see AttributeFilter
+func WithAttributes(ctx context.Context, fs ...AttributeFilter) context.Context {
+	return context.WithValue(ctx, Attrs, fs)
+}
+
+// GetContextAttributes is never nil, but its filters haven't been checked,
+// see CheckAttributeFilters
+func GetContextAttributes(ctx context.Context) []AttributeFilter {
+	if result, ok := ctx.Value(Attrs).([]AttributeFilter); ok && result != nil {
+		return result
+	}
+	return []AttributeFilter{}
+}
+
+func (d AttributeDefinition) normalize(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	invalid := fmt.Errorf("%s value for '%s' isn't valid: '%s'", d.Type, d.Name, v)
+
+	switch d.Type {
+	case TextAttribute:
+		if v != "" {
+			return v, nil
+		}
+	case BooleanAttribute:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return strconv.FormatBool(b), nil
+		}
+	case NumberAttribute:
+		if x, err := strconv.ParseFloat(v, 64); err == nil && !math.IsNaN(x) && !math.IsInf(x, 0) {
+			return strconv.FormatFloat(x, 'f', -1, 64), nil
+		}
+	case EnumAttribute:
+		for _, a := range d.Allowed {
+			if strings.EqualFold(a, v) {
+				return a, nil
+			}
+		}
+		return "", fmt.Errorf("'%s' has to be one of %s: '%s'", d.Name, strings.Join(d.Allowed, ", "), v)
+	}
+
+	return "", invalid
+}
@@ -0,0 +1,231 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _attributeDefinitions = []AttributeDefinition{
+	{Name: "headroom", Type: NumberAttribute, Unit: "cm", Synonyms: []string{"headroom (cm)"}},
+	{Name: "color", Type: EnumAttribute, Allowed: []string{"purple", "Golden Teacher"}, Synonyms: []string{"colour"}},
+	{Name: "rhizomorphic", Type: BooleanAttribute},
+	{Name: "notes", Type: TextAttribute},
+}
+
+func Test_CheckAttributeDefinition(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		d   AttributeDefinition
+		err error
+	}{
+		"happy_number": {
+			d: AttributeDefinition{Name: "headroom", Type: NumberAttribute, Unit: "cm"},
+		},
+		"happy_enum": {
+			d: AttributeDefinition{Name: "color", Type: EnumAttribute, Allowed: []string{"purple", "white"}},
+		},
+		"no_name": {
+			d:   AttributeDefinition{Name: " ", Type: TextAttribute},
+			err: fmt.Errorf("an attribute needs a name"),
+		},
+		"unknown_type": {
+			d:   AttributeDefinition{Name: "color", Type: "colour"},
+			err: fmt.Errorf("unknown attribute type: 'colour'"),
+		},
+		"unit_on_text": {
+			d:   AttributeDefinition{Name: "color", Type: TextAttribute, Unit: "nm"},
+			err: fmt.Errorf("only number attributes can have a unit: 'color'"),
+		},
+		"enum_without_values": {
+			d:   AttributeDefinition{Name: "color", Type: EnumAttribute},
+			err: fmt.Errorf("an enum attribute needs allowed values: 'color'"),
+		},
+		"enum_with_empty_value": {
+			d:   AttributeDefinition{Name: "color", Type: EnumAttribute, Allowed: []string{"purple", " "}},
+			err: fmt.Errorf("'color' can't allow an empty value"),
+		},
+		"enum_with_duplicates": {
+			d:   AttributeDefinition{Name: "color", Type: EnumAttribute, Allowed: []string{"purple", "Purple"}},
+			err: fmt.Errorf("'Purple' is allowed more than once for 'color'"),
+		},
+		"allowed_on_number": {
+			d:   AttributeDefinition{Name: "headroom", Type: NumberAttribute, Allowed: []string{"25"}},
+			err: fmt.Errorf("only enum attributes can have allowed values: 'headroom'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.err, CheckAttributeDefinition(tc.d))
+		})
+	}
+}
+
+func Test_FindAttribute(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		name   string
+		result string
+		ok     bool
+	}{
+		"by_name": {
+			name:   "color",
+			result: "color",
+			ok:     true,
+		},
+		"by_synonym": {
+			name:   "Colour ",
+			result: "color",
+			ok:     true,
+		},
+		"missing": {
+			name: "vigor",
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			d, ok := FindAttribute(_attributeDefinitions, tc.name)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.result, d.Name)
+		})
+	}
+}
+
+func Test_CheckAttribute(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		a      StrainAttribute
+		result StrainAttribute
+		err    error
+	}{
+		"number": {
+			a:      StrainAttribute{UUID: "0", Name: "headroom (cm)", Value: " 25.0"},
+			result: StrainAttribute{UUID: "0", Name: "headroom", Value: "25"},
+		},
+		"enum": {
+			a:      StrainAttribute{Name: "Colour", Value: "golden teacher"},
+			result: StrainAttribute{Name: "color", Value: "Golden Teacher"},
+		},
+		"boolean": {
+			a:      StrainAttribute{Name: "rhizomorphic", Value: "T"},
+			result: StrainAttribute{Name: "rhizomorphic", Value: "true"},
+		},
+		"text": {
+			a:      StrainAttribute{Name: "notes", Value: " dense "},
+			result: StrainAttribute{Name: "notes", Value: "dense"},
+		},
+		"unknown_attribute": {
+			a:      StrainAttribute{Name: "vigor", Value: "high"},
+			result: StrainAttribute{Name: "vigor", Value: "high"},
+			err:    fmt.Errorf("unknown attribute: 'vigor'"),
+		},
+		"bad_number": {
+			a:      StrainAttribute{Name: "headroom", Value: "tall"},
+			result: StrainAttribute{Name: "headroom", Value: "tall"},
+			err:    fmt.Errorf("number value for 'headroom' isn't valid: 'tall'"),
+		},
+		"infinite_number": {
+			a:      StrainAttribute{Name: "headroom", Value: "Inf"},
+			result: StrainAttribute{Name: "headroom", Value: "Inf"},
+			err:    fmt.Errorf("number value for 'headroom' isn't valid: 'Inf'"),
+		},
+		"bad_boolean": {
+			a:      StrainAttribute{Name: "rhizomorphic", Value: "sort of"},
+			result: StrainAttribute{Name: "rhizomorphic", Value: "sort of"},
+			err:    fmt.Errorf("boolean value for 'rhizomorphic' isn't valid: 'sort of'"),
+		},
+		"bad_enum": {
+			a:      StrainAttribute{Name: "color", Value: "albino"},
+			result: StrainAttribute{Name: "color", Value: "albino"},
+			err:    fmt.Errorf("'color' has to be one of purple, Golden Teacher: 'albino'"),
+		},
+		"empty_text": {
+			a:      StrainAttribute{Name: "notes"},
+			result: StrainAttribute{Name: "notes"},
+			err:    fmt.Errorf("text value for 'notes' isn't valid: ''"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := CheckAttribute(_attributeDefinitions, tc.a)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_CheckAttributeFilters(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		fs     []AttributeFilter
+		result []AttributeFilter
+		err    error
+	}{
+		"happy_path": {
+			fs: []AttributeFilter{
+				{Name: "headroom (cm)", Min: f64(20), Max: f64(30)},
+				{Name: "colour", Values: []string{"PURPLE", "golden teacher"}},
+				{Name: "notes"},
+			},
+			result: []AttributeFilter{
+				{Name: "headroom", Min: f64(20), Max: f64(30)},
+				{Name: "color", Values: []string{"purple", "Golden Teacher"}},
+				{Name: "notes"},
+			},
+		},
+		"none": {
+			result: []AttributeFilter{},
+		},
+		"unknown_attribute": {
+			fs:  []AttributeFilter{{Name: "vigor"}},
+			err: fmt.Errorf("unknown attribute: 'vigor'"),
+		},
+		"range_on_enum": {
+			fs:  []AttributeFilter{{Name: "color", Min: f64(1)}},
+			err: fmt.Errorf("only number attributes can have a range: 'color'"),
+		},
+		"min_over_max": {
+			fs:  []AttributeFilter{{Name: "headroom", Min: f64(30), Max: f64(20)}},
+			err: fmt.Errorf("filter for 'headroom' has a min greater than its max"),
+		},
+		"bad_value": {
+			fs:  []AttributeFilter{{Name: "rhizomorphic", Values: []string{"sort of"}}},
+			err: fmt.Errorf("boolean value for 'rhizomorphic' isn't valid: 'sort of'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := CheckAttributeFilters(_attributeDefinitions, tc.fs)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_GetContextAttributes(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []AttributeFilter{}, GetContextAttributes(context.Background()))
+	require.Equal(t,
+		[]AttributeFilter{{Name: "color", Values: []string{"purple"}}},
+		GetContextAttributes(WithAttributes(context.Background(),
+			AttributeFilter{Name: "color", Values: []string{"purple"}})))
+}
@@ -0,0 +1,27 @@
+package types
+
+// InStep is false for a member that was excluded by hand, or that diverged
+// from the rest of the batch on its own by getting contaminated or dying;
+// harvesting doesn't count, since the whole batch is expected to get there
+func (m BatchMember) InStep() bool {
+	if m.Excluded != nil {
+		return false
+	}
+	switch NewStatus(m.Lifecycle.Events) {
+	case ContaminatedStatus, DeadStatus:
+		return false
+	}
+	return true
+}
+
+// InStep is the members that batch-wide operations still apply to, as indexes
+// into Members so callers can update them in place
+func (b Batch) InStep() []int {
+	result := make([]int, 0, len(b.Members))
+	for i, m := range b.Members {
+		if m.InStep() {
+			result = append(result, i)
+		}
+	}
+	return result
+}
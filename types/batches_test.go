@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BatchInStep(t *testing.T) {
+	t.Parallel()
+
+	event := func(severity, name string) Event {
+		return Event{EventType: EventType{Name: name, Severity: severity}}
+	}
+	excluded := time.Now()
+
+	b := Batch{Members: []BatchMember{
+		{Lifecycle: Lifecycle{UUID: "pending"}},
+		{Lifecycle: Lifecycle{UUID: "active", Events: []Event{event(BeginSeverity, "Inoculated")}}},
+		{Lifecycle: Lifecycle{UUID: "moldy", Events: []Event{event(ErrorSeverity, "Mold")}}},
+		{Lifecycle: Lifecycle{UUID: "dead", Events: []Event{event(FatalSeverity, "Mold")}}},
+		{Lifecycle: Lifecycle{UUID: "flushing", Events: []Event{event(InfoSeverity, HarvestingEvent)}}},
+		{Lifecycle: Lifecycle{UUID: "excluded"}, Excluded: &excluded},
+	}}
+
+	tcs := map[string]struct {
+		b      Batch
+		result []int
+	}{
+		"empty": {
+			result: []int{},
+		},
+		"mixed": {
+			b:      b,
+			result: []int{0, 1, 4},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, tc.b.InStep())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+// NewCosts totals up what was spent on a lifecycle and what came out of it;
+// costs are always per gram, whatever units the lifecycle is in
+func NewCosts(lc Lifecycle) Costs {
+	return Costs{
+		Strain: lc.StrainCost,
+		Grain:  lc.GrainCost,
+		Bulk:   lc.BulkCost,
+		Yield:  lc.Units.Grams(lc.Yield),
+		Gross:  lc.Units.Grams(lc.Gross),
+	}.derive()
+}
+
+// Add sums the raw figures and re-derives the ratios from the sums, so a
+// roll-up's cost per gram is weighted by yield and not an average of averages
+func (c Costs) Add(o Costs) Costs {
+	return Costs{
+		Strain: c.Strain + o.Strain,
+		Grain:  c.Grain + o.Grain,
+		Bulk:   c.Bulk + o.Bulk,
+		Yield:  c.Yield + o.Yield,
+		Gross:  c.Gross + o.Gross,
+	}.derive()
+}
+
+func (c Costs) derive() Costs {
+	c.Total = c.Strain + c.Grain + c.Bulk
+	c.PerYieldGram = ratio(c.Total, c.Yield)
+	c.PerGrossGram = ratio(c.Total, c.Gross)
+	if r := ratio(c.Yield, c.Gross); r != nil {
+		// see the comment on lifecycles.yield in init.sql
+		*r = 1 - *r
+		c.Moisture = r
+	}
+	return c
+}
+
+func ratio(num, denom float32) *float32 {
+	if denom == 0 {
+		return nil
+	}
+	result := num / denom
+	return &result
+}
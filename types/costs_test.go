@@ -0,0 +1,92 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func f32(f float32) *float32 { return &f }
+
+func Test_NewCosts(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		lc     Lifecycle
+		result Costs
+	}{
+		"happy_path": {
+			lc: Lifecycle{StrainCost: 10, GrainCost: 20, BulkCost: 10, Yield: 40, Gross: 400},
+			result: Costs{
+				Strain:       10,
+				Grain:        20,
+				Bulk:         10,
+				Total:        40,
+				Yield:        40,
+				Gross:        400,
+				PerYieldGram: f32(1),
+				PerGrossGram: f32(0.1),
+				Moisture:     f32(0.9),
+			},
+		},
+		"imperial": {
+			lc: Lifecycle{StrainCost: 10, Yield: 1, Gross: 10, Units: ImperialUnits},
+			result: Costs{
+				Strain:       10,
+				Total:        10,
+				Yield:        28.349524,
+				Gross:        283.49524,
+				PerYieldGram: f32(10 / 28.349524),
+				PerGrossGram: f32(10 / 283.49524),
+				Moisture:     f32(0.9),
+			},
+		},
+		"no_harvest": {
+			lc:     Lifecycle{StrainCost: 10, GrainCost: 20},
+			result: Costs{Strain: 10, Grain: 20, Total: 30},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewCosts(tc.lc))
+		})
+	}
+}
+
+func Test_AddCosts(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		c, o   Costs
+		result Costs
+	}{
+		"weighted": {
+			c: NewCosts(Lifecycle{StrainCost: 10, Yield: 10, Gross: 100}),
+			o: NewCosts(Lifecycle{StrainCost: 30, Yield: 10, Gross: 100}),
+			result: Costs{
+				Strain:       40,
+				Total:        40,
+				Yield:        20,
+				Gross:        200,
+				PerYieldGram: f32(2),
+				PerGrossGram: f32(0.2),
+				Moisture:     f32(0.9),
+			},
+		},
+		"from_zero": {
+			o:      NewCosts(Lifecycle{BulkCost: 5}),
+			result: Costs{Bulk: 5, Total: 5},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, tc.c.Add(tc.o))
+		})
+	}
+}
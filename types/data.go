@@ -15,12 +15,58 @@ type (
 
 	Entity map[string]any
 
+	// AttributeType is what sort of value a strain attribute holds, see
+	// vars.go
+	AttributeType string
+
+	// DeviationKind is how an event compares to its protocol step, see vars.go
+	DeviationKind string
+
+	// Dimension is what analytics results get grouped by, see vars.go
+	Dimension string
+
+	// FieldType is what sort of value a field holds, see vars.go
+	FieldType string
+
+	// InheritPolicy is how a promoted strain settles its parents'
+	// attributes, see vars.go
+	InheritPolicy string
+
+	// LineageKind is what a lineage node stands for, see vars.go
+	LineageKind string
+
+	// LineageFormat is a graph language a lineage can be exported to, see
+	// vars.go
+	LineageFormat string
+
+	// LocationKind is what sort of place a location is, see vars.go
+	LocationKind string
+
+	// Resolution is how finely sensor readings are rolled up, see vars.go
+	Resolution string
+
+	// Status is derived from an observable's events, see NewStatus
+	Status string
+
+	// TransitionPolicy decides what happens to an event that breaks the
+	// ordering rules in CheckTransition; the zero value is StrictTransitions
+	TransitionPolicy string
+
+	// UnitSystem is what temperatures and weights are given in, see vars.go;
+	// everything is stored in MetricUnits
+	UnitSystem string
+
 	Config struct {
-		PGHost string
-		PGUser string
-		PGPass string
-		PGPort uint
-		PGSSL  string
+		PGHost      string
+		PGUser      string
+		PGPass      string
+		PGPort      uint
+		PGSSL       string
+		Transitions TransitionPolicy
+		// Units is the default for requests that don't ask for a system of
+		// their own, see WithUnits; when it's empty, results are metric and
+		// unlabeled
+		Units UnitSystem
 	}
 
 	Timestamp struct {
@@ -32,22 +78,292 @@ type (
 		Origin *time.Time `json:"utc,omitempty"`
 	}
 
+	// AttributeDefinition is the vocabulary strain attributes are checked
+	// against, see CheckAttribute; Allowed is only for enums and Unit is only
+	// for numbers. Synonyms are other names that mean this attribute, like
+	// the ones left behind by merging two definitions
+	AttributeDefinition struct {
+		UUID     `json:"id"`
+		Name     string        `json:"name"`
+		Type     AttributeType `json:"type"`
+		Unit     string        `json:"unit,omitempty"`
+		Allowed  []string      `json:"allowed,omitempty"`
+		Synonyms []string      `json:"synonyms,omitempty"`
+	}
+
+	// AttributeFilter matches strains that have attribute Name; Min and Max
+	// are inclusive and only match numbers, and a strain matches Values if
+	// its value is any one of them, see WithAttributes
+	AttributeFilter struct {
+		Name   string   `json:"name"`
+		Min    *float64 `json:"min,omitempty"`
+		Max    *float64 `json:"max,omitempty"`
+		Values []string `json:"values,omitempty"`
+	}
+
+	// Batch is lifecycles that were started together from one template;
+	// batch-wide events, notes and photos go to every member that's still in
+	// step, see BatchMember.InStep
+	Batch struct {
+		UUID    `json:"id"`
+		Name    string        `json:"name"`
+		Members []BatchMember `json:"members,omitempty"`
+		CTime   time.Time     `json:"ctime"`
+	}
+
+	// BatchMember is Excluded when it was taken out of the batch by hand
+	BatchMember struct {
+		Lifecycle Lifecycle  `json:"lifecycle"`
+		Excluded  *time.Time `json:"excluded,omitempty"`
+	}
+
+	// Contamination is one offending event; Stage is where the observable was
+	// when it happened, which is only different from the event type's stage
+	// for events in the Any stage
+	Contamination struct {
+		ObservableType ParentType `json:"observable_type"`
+		ObservableUUID UUID       `json:"observable_id"`
+		Stage          Stage      `json:"stage"`
+		Event          Event      `json:"event"`
+	}
+
+	// ContaminationRate counts observables, not events: a lifecycle with three
+	// Error events and a RIP adds one to Error, RIP and Contaminated
+	ContaminationRate struct {
+		Dimension    Dimension `json:"dimension"`
+		Key          string    `json:"key"`
+		Label        string    `json:"label"`
+		Observed     int       `json:"observed"`
+		Contaminated int       `json:"contaminated"`
+		Error        int       `json:"error"`
+		Fatal        int       `json:"fatal"`
+		RIP          int       `json:"rip"`
+		Rate         float32   `json:"rate"`
+	}
+
+	// Costs are summable; the ratios are nil when their denominator is zero
+	// rather than pretending a lifecycle that never fruited was free
+	Costs struct {
+		Strain       float32  `json:"strain"`
+		Grain        float32  `json:"grain"`
+		Bulk         float32  `json:"bulk"`
+		Total        float32  `json:"total"`
+		Yield        float32  `json:"yield"`
+		Gross        float32  `json:"gross"`
+		PerYieldGram *float32 `json:"per_yield_gram,omitempty"`
+		PerGrossGram *float32 `json:"per_gross_gram,omitempty"`
+		Moisture     *float32 `json:"moisture,omitempty"`
+	}
+
+	CostRollup struct {
+		Dimension Dimension `json:"dimension"`
+		// Key is a uuid, a location or the start of a period, depending on Dimension
+		Key   string `json:"key"`
+		Label string `json:"label"`
+		Count int    `json:"count"`
+		Costs Costs  `json:"costs"`
+	}
+
+	// DurationStats are computed from closed spans only; percentiles
+	// interpolate between ranks the same way postgres' percentile_cont does
+	DurationStats struct {
+		Count  int           `json:"count"`
+		Min    time.Duration `json:"min"`
+		P25    time.Duration `json:"p25"`
+		Median time.Duration `json:"median"`
+		P75    time.Duration `json:"p75"`
+		P90    time.Duration `json:"p90"`
+		Max    time.Duration `json:"max"`
+		Mean   time.Duration `json:"mean"`
+	}
+
+	// Event's Measurements are in the same order as its event type's fields,
+	// see NewMeasurements. OccurredAt is when it actually happened, and it's
+	// what events are ordered and timed by; MTime and CTime are only when it
+	// was recorded
 	Event struct {
-		UUID        `json:"id"`
-		Temperature float32   `json:"temperature"`
-		Humidity    int8      `json:"humidity,omitempty"`
-		EventType   EventType `json:"event_type"`
-		Photos      []Photo   `json:"photos,omitempty"`
-		Notes       []Note    `json:"notes,omitempty"`
-		MTime       time.Time `json:"mtime"`
-		CTime       time.Time `json:"ctime"`
+		UUID         `json:"id"`
+		Temperature  float32       `json:"temperature"`
+		Humidity     int8          `json:"humidity,omitempty"`
+		EventType    EventType     `json:"event_type"`
+		Measurements []Measurement `json:"measurements,omitempty"`
+		Photos       []Photo       `json:"photos,omitempty"`
+		Notes        []Note        `json:"notes,omitempty"`
+		Units        UnitSystem    `json:"units,omitempty"`
+		OccurredAt   time.Time     `json:"occurred_at"`
+		MTime        time.Time     `json:"mtime"`
+		CTime        time.Time     `json:"ctime"`
+	}
+
+	// Deviation is one protocol step and the event that carried it out, or an
+	// event that wasn't in the protocol at all; Days is how many days late the
+	// event was (negative for early), and Expected is when the step was due
+	Deviation struct {
+		Kind     DeviationKind `json:"kind"`
+		Step     *ProtocolStep `json:"step,omitempty"`
+		Event    *Event        `json:"event,omitempty"`
+		Expected *time.Time    `json:"expected,omitempty"`
+		Days     *int          `json:"days,omitempty"`
+	}
+
+	// DeviationReport holds up an observable's events against its protocol;
+	// every step's day is counted from Anchor
+	DeviationReport struct {
+		Protocol   Protocol    `json:"protocol"`
+		Anchor     time.Time   `json:"anchor"`
+		Deviations []Deviation `json:"deviations"`
+	}
+
+	// Environment is what a location is meant to be kept at; nil means there's
+	// no target for that end of the range
+	Environment struct {
+		MinTemperature *float32 `json:"min_temperature,omitempty"`
+		MaxTemperature *float32 `json:"max_temperature,omitempty"`
+		MinHumidity    *float32 `json:"min_humidity,omitempty"`
+		MaxHumidity    *float32 `json:"max_humidity,omitempty"`
+		MaxCO2         *float32 `json:"max_co2,omitempty"`
+	}
+
+	// Estimate is a prediction interval at ForecastConfidence; Actual is set
+	// once the milestone has happened
+	Estimate struct {
+		Samples  int        `json:"samples"`
+		Expected time.Time  `json:"expected"`
+		Low      time.Time  `json:"low"`
+		High     time.Time  `json:"high"`
+		Actual   *time.Time `json:"actual,omitempty"`
 	}
 
 	EventType struct {
+		UUID      `json:"id"`
+		Name      string `json:"name"`
+		Severity  string `json:"severity"`
+		Stage     `json:"stage"`
+		FollowUps []FollowUp `json:"follow_ups,omitempty"`
+		Fields    []Field    `json:"fields,omitempty"`
+	}
+
+	// Experiment tests one Variable, like a new bulk recipe, by comparing arms
+	// of lifecycles that differ in it; a lifecycle is in at most one arm
+	Experiment struct {
 		UUID     `json:"id"`
-		Name     string `json:"name"`
-		Severity string `json:"severity"`
-		Stage    `json:"stage"`
+		Name     string          `json:"name"`
+		Variable string          `json:"variable"`
+		Arms     []ExperimentArm `json:"arms,omitempty"`
+		CTime    time.Time       `json:"ctime"`
+	}
+
+	// ExperimentArm is Control when it's what the other arms are compared
+	// to; an experiment has at most one
+	ExperimentArm struct {
+		UUID       `json:"id"`
+		Name       string      `json:"name"`
+		Control    bool        `json:"control"`
+		Lifecycles []Lifecycle `json:"lifecycles,omitempty"`
+	}
+
+	// ExperimentReport summarizes every arm; yield is in Units, and
+	// colonization is in days, see NewExperimentReport
+	ExperimentReport struct {
+		Experiment Experiment   `json:"experiment"`
+		Arms       []ArmSummary `json:"arms"`
+		Units      UnitSystem   `json:"units,omitempty"`
+	}
+
+	ArmSummary struct {
+		Arm           ExperimentArm     `json:"arm"`
+		Yield         SampleSummary     `json:"yield"`
+		Colonization  SampleSummary     `json:"colonization"`
+		Contamination ProportionSummary `json:"contamination"`
+	}
+
+	// SampleSummary's P is the two-sided p-value of a Welch's t-test against
+	// the control arm; it's nil for the control arm itself, and whenever the
+	// test can't be done
+	SampleSummary struct {
+		N      int      `json:"n"`
+		Mean   float64  `json:"mean"`
+		StdDev float64  `json:"std_dev"`
+		P      *float64 `json:"p,omitempty"`
+	}
+
+	// ProportionSummary is Count out of N; P is the two-sided p-value of a
+	// two-proportion z-test against the control arm, nil like SampleSummary's
+	ProportionSummary struct {
+		N     int      `json:"n"`
+		Count int      `json:"count"`
+		Rate  float64  `json:"rate"`
+		P     *float64 `json:"p,omitempty"`
+	}
+
+	// Field is an extra measurement that events of one type can record, like a
+	// colony diameter; Unit is just a label, the value is stored the way it
+	// was given. Min and Max are inclusive, and only numbers and integers can
+	// have them
+	Field struct {
+		UUID `json:"id"`
+		Name string    `json:"name"`
+		Type FieldType `json:"type"`
+		Unit string    `json:"unit,omitempty"`
+		Min  *float64  `json:"min,omitempty"`
+		Max  *float64  `json:"max,omitempty"`
+	}
+
+	// Filiation is where a strain sits relative to the vendor original it
+	// came from, see NewFiliation; Depth counts every source between them,
+	// and Root is nil for a vendor original
+	Filiation struct {
+		Depth    int    `json:"depth"`
+		Notation string `json:"notation"`
+		Root     *UUID  `json:"root,omitempty"`
+	}
+
+	// FilialSource is one source of the generation a strain was promoted
+	// from, along with the filiation of the strain the source came from
+	FilialSource struct {
+		Type   string
+		Clonal bool
+		Parent UUID
+		Filiation
+	}
+
+	// FilialFilter narrows SelectAllStrains to strains that many sources
+	// from their vendor original, or that came from Root, see WithFilial
+	FilialFilter struct {
+		Depth *int
+		Root  *UUID
+	}
+
+	// FollowUp is something to check on Days after an event of the event type
+	// it belongs to; Expects is the event type that usually records how the
+	// check went, if there is one
+	FollowUp struct {
+		UUID    `json:"id"`
+		Name    string     `json:"name"`
+		Days    int        `json:"days"`
+		Expects *EventType `json:"expects,omitempty"`
+	}
+
+	// Forecast estimates are nil when there's no history to go on; Basis is
+	// what the history had in common with the lifecycle
+	Forecast struct {
+		Basis     []Dimension `json:"basis"`
+		Anchor    time.Time   `json:"anchor"`
+		Colonized *Estimate   `json:"colonized,omitempty"`
+		Pinning   *Estimate   `json:"pinning,omitempty"`
+		Harvest   *Estimate   `json:"harvest,omitempty"`
+	}
+
+	// Flush is a harvest measured against the rest of its lifecycle's harvests;
+	// Interval is from the previous flush, or from the lifecycle's ctime for
+	// the first one, and the ratios are nil when their denominator is zero
+	Flush struct {
+		Number     int           `json:"number"`
+		Harvest    Harvest       `json:"harvest"`
+		Interval   time.Duration `json:"interval"`
+		Share      *float32      `json:"share,omitempty"`
+		Moisture   *float32      `json:"moisture,omitempty"`
+		MeanWeight *float32      `json:"mean_weight,omitempty"`
 	}
 
 	Generation struct {
@@ -56,11 +372,26 @@ type (
 		LiquidSubstrate  Substrate  `json:"liquid_substrate"`
 		Sources          []Source   `json:"sources,omitempty"`
 		Events           []Event    `json:"events,omitempty"`
+		Status           Status     `json:"status,omitempty"`
 		MTime            time.Time  `json:"mtime"`
 		CTime            time.Time  `json:"ctime"`
 		DTime            *time.Time `json:",omitempty"`
 	}
 
+	// Harvest is one flush; DryWeight stays zero until it's been dried
+	Harvest struct {
+		UUID        `json:"id"`
+		Date        time.Time  `json:"date"`
+		FreshWeight float32    `json:"fresh_weight"`
+		DryWeight   float32    `json:"dry_weight,omitempty"`
+		Count       int16      `json:"count,omitempty"`
+		Grade       string     `json:"grade,omitempty"`
+		Notes       []Note     `json:"notes,omitempty"`
+		Units       UnitSystem `json:"units,omitempty"`
+		MTime       time.Time  `json:"mtime"`
+		CTime       time.Time  `json:"ctime"`
+	}
+
 	Ingredient struct {
 		UUID `json:"id"`
 		Name string `json:"name"`
@@ -68,21 +399,95 @@ type (
 
 	Lifecycle struct {
 		UUID           `json:"id"`
-		Location       string  `json:"location"`
-		StrainCost     float32 `json:"strain_cost,omitempty"`
-		GrainCost      float32 `json:"grain_cost,omitempty"`
-		BulkCost       float32 `json:"bulk_cost,omitempty"`
-		Yield          float32 `json:"yield,omitempty"`
-		Count          int16   `json:"count,omitempty"`
-		Gross          float32 `json:"gross,omitempty"`
+		Location       Location `json:"location"`
+		StrainCost     float32  `json:"strain_cost,omitempty"`
+		GrainCost      float32  `json:"grain_cost,omitempty"`
+		BulkCost       float32  `json:"bulk_cost,omitempty"`
+		Yield          float32  `json:"yield,omitempty"`
+		Count          int16    `json:"count,omitempty"`
+		Gross          float32  `json:"gross,omitempty"`
 		Strain         `json:"strain,omitempty"`
-		GrainSubstrate Substrate `json:"grain_substrate,omitempty"`
-		BulkSubstrate  Substrate `json:"bulk_substrate,omitempty"`
-		Events         []Event   `json:"events,omitempty"`
-		MTime          time.Time `json:"mtime,omitempty"`
+		GrainSubstrate Substrate  `json:"grain_substrate,omitempty"`
+		BulkSubstrate  Substrate  `json:"bulk_substrate,omitempty"`
+		Events         []Event    `json:"events,omitempty"`
+		Harvests       []Harvest  `json:"harvests,omitempty"`
+		Status         Status     `json:"status,omitempty"`
+		Units          UnitSystem `json:"units,omitempty"`
+		MTime          time.Time  `json:"mtime,omitempty"`
+		CTime          time.Time  `json:"ctime"`
+	}
+
+	// Lineage is a DAG of strains, the generations that produced them, those
+	// generations' sources, and the lifecycles whose events were sources;
+	// edges always point from the older node to the newer one, whichever
+	// direction it was walked in
+	Lineage struct {
+		Root  string        `json:"root"`
+		Nodes []LineageNode `json:"nodes"`
+		Edges []LineageEdge `json:"edges"`
+	}
+
+	// LineageNode.ID is unique across kinds, see NewLineageID; Depth is how
+	// many generations away from the root it is; only strains have a species
+	// and vendor, and only generations and lifecycles have an outcome
+	LineageNode struct {
+		ID      string      `json:"id"`
+		Kind    LineageKind `json:"kind"`
+		UUID    UUID        `json:"uuid"`
+		Label   string      `json:"label"`
+		Depth   int         `json:"depth"`
+		Species string      `json:"species,omitempty"`
+		Vendor  string      `json:"vendor,omitempty"`
+		Outcome Status      `json:"outcome,omitempty"`
+	}
+
+	LineageEdge struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	LifecycleCost struct {
+		UUID           `json:"id"`
+		Location       Location  `json:"location"`
+		Strain         Strain    `json:"strain"`
+		GrainSubstrate Substrate `json:"grain_substrate"`
+		BulkSubstrate  Substrate `json:"bulk_substrate"`
+		Costs          Costs     `json:"costs"`
 		CTime          time.Time `json:"ctime"`
 	}
 
+	// Location is somewhere lifecycles are kept; Capacity is how many fit at
+	// once, zero for no limit
+	Location struct {
+		UUID     `json:"id"`
+		Name     string       `json:"name"`
+		Kind     LocationKind `json:"kind,omitempty"`
+		Capacity int          `json:"capacity,omitempty"`
+		Target   Environment  `json:"target"`
+	}
+
+	// Measurement is an event's value for one of its event type's fields,
+	// by name; Unit comes from the field
+	Measurement struct {
+		Field string `json:"field"`
+		Value any    `json:"value"`
+		Unit  string `json:"unit,omitempty"`
+	}
+
+	// MeasurementStats summarizes one field's values across a group of
+	// lifecycles, one value per event that recorded it
+	MeasurementStats struct {
+		Dimension Dimension `json:"dimension"`
+		Key       string    `json:"key"`
+		Label     string    `json:"label"`
+		Count     int       `json:"count"`
+		Min       float64   `json:"min"`
+		Median    float64   `json:"median"`
+		Max       float64   `json:"max"`
+		Mean      float64   `json:"mean"`
+		StdDev    float64   `json:"std_dev"`
+	}
+
 	Note struct {
 		UUID  `json:"id,omitempty"`
 		Note  string    `json:"note,omitempty"`
@@ -90,6 +495,15 @@ type (
 		CTime time.Time `json:"ctime,omitempty"`
 	}
 
+	// Occupancy is what's at a location now, see NewOccupancy; Fill is nil
+	// when the location has no capacity to fill
+	Occupancy struct {
+		Location   Location    `json:"location"`
+		Lifecycles []Lifecycle `json:"lifecycles"`
+		Occupied   int         `json:"occupied"`
+		Fill       *float32    `json:"fill,omitempty"`
+	}
+
 	Photo struct {
 		UUID     `json:"id"`
 		Filename string      `json:"image"`
@@ -106,6 +520,68 @@ type (
 		Label      string     `json:"label"`
 	}
 
+	// PromoteOptions are everything PromoteGeneration needs besides the
+	// generation. Species defaults to whatever the parents agree on, and
+	// Vendor to SelfVendor; Note is added to the one that says where the
+	// strain came from
+	PromoteOptions struct {
+		Name    string        `json:"name"`
+		Species string        `json:"species,omitempty"`
+		Vendor  *UUID         `json:"vendor,omitempty"`
+		Inherit InheritPolicy `json:"inherit,omitempty"`
+		Note    string        `json:"note,omitempty"`
+	}
+
+	// Protocol is the written plan for a grow: which events should happen and
+	// when, in day order
+	Protocol struct {
+		UUID  `json:"id"`
+		Name  string         `json:"name"`
+		Steps []ProtocolStep `json:"steps"`
+	}
+
+	// ProtocolStep is due Day days after the protocol's first step, give or
+	// take Tolerance days
+	ProtocolStep struct {
+		UUID      `json:"id"`
+		EventType EventType `json:"event_type"`
+		Day       int       `json:"day"`
+		Tolerance int       `json:"tolerance"`
+	}
+
+	// Reading is one sample from one sensor; a sensor belongs to a location,
+	// and to a lifecycle too if it's dedicated to one. Measurements the sensor
+	// doesn't have are nil
+	Reading struct {
+		Sensor      string    `json:"sensor"`
+		Location    string    `json:"location"`
+		Lifecycle   *UUID     `json:"lifecycle_id,omitempty"`
+		At          time.Time `json:"at"`
+		Temperature *float32  `json:"temperature,omitempty"`
+		Humidity    *float32  `json:"humidity,omitempty"`
+		CO2         *float32  `json:"co2,omitempty"`
+		Light       *float32  `json:"light,omitempty"`
+	}
+
+	// ReadingRollup summarizes every reading in [Bucket, Bucket+Resolution)
+	ReadingRollup struct {
+		Bucket      time.Time    `json:"bucket"`
+		Count       int          `json:"count"`
+		Temperature ReadingStats `json:"temperature"`
+		Humidity    ReadingStats `json:"humidity"`
+		CO2         ReadingStats `json:"co2"`
+		Light       ReadingStats `json:"light"`
+	}
+
+	// ReadingStats only count the readings that had the measurement; the rest
+	// are nil when none did
+	ReadingStats struct {
+		Count int      `json:"count"`
+		Min   *float32 `json:"min,omitempty"`
+		Max   *float32 `json:"max,omitempty"`
+		Mean  *float32 `json:"mean,omitempty"`
+	}
+
 	Source struct {
 		UUID      `json:"id"`
 		Type      string     `json:"type"`
@@ -113,17 +589,50 @@ type (
 		Strain    `json:"strain"`
 	}
 
+	// SourceType is the policy for one kind of source: a generation can have
+	// up to Max of them, and only mixes them with other types when both are
+	// Mixable. A Clonal type is a copy of its parent, not a new filial
+	// generation
+	SourceType struct {
+		Name    string `json:"name"`
+		Max     int    `json:"max"`
+		Mixable bool   `json:"mixable"`
+		Clonal  bool   `json:"clonal"`
+	}
+
 	Stage struct {
 		UUID `json:"id"`
 		Name string `json:"name"`
 	}
 
+	// StageSpan is open (End is nil) until the observable moves on to another
+	// stage or is retired
+	StageSpan struct {
+		Stage `json:"stage"`
+		Begin time.Time  `json:"begin"`
+		End   *time.Time `json:"end,omitempty"`
+	}
+
+	StageReadings struct {
+		StageSpan `json:"span"`
+		Readings  []Reading `json:"readings"`
+	}
+
+	StageDurations struct {
+		Dimension    Dimension     `json:"dimension"`
+		Key          string        `json:"key"`
+		Label        string        `json:"label"`
+		Colonization DurationStats `json:"colonization"`
+		Fruiting     DurationStats `json:"fruiting"`
+	}
+
 	Strain struct {
 		UUID       `json:"id"`
 		Species    string `json:"species,omitempty"`
 		Name       string `json:"name"`
 		Vendor     `json:"vendor"`
 		Generation *Generation       `json:"generation,omitempty"`
+		Filiation  *Filiation        `json:"filiation,omitempty"`
 		Attributes []StrainAttribute `json:"attributes,omitempty"`
 		CTime      time.Time         `json:"ctime"`
 		DTime      *time.Time        `json:"dtime,omitempty"`
@@ -143,6 +652,27 @@ type (
 		Ingredients []Ingredient `json:"ingredients,omitempty"`
 	}
 
+	// Window is half-open, [From, To); either end may be nil for unbounded
+	Window struct {
+		From *time.Time `json:"from,omitempty"`
+		To   *time.Time `json:"to,omitempty"`
+	}
+
+	// Task is one follow-up owed by one lifecycle or generation, created when
+	// the event that calls for it is recorded; Result is the event that was
+	// recorded when it was done, if any
+	Task struct {
+		UUID           `json:"id"`
+		FollowUp       FollowUp   `json:"follow_up"`
+		ObservableType ParentType `json:"observable_type"`
+		ObservableUUID UUID       `json:"observable_id"`
+		EventUUID      UUID       `json:"event_id"`
+		Due            time.Time  `json:"due"`
+		Done           *time.Time `json:"done,omitempty"`
+		Result         *UUID      `json:"result_id,omitempty"`
+		Overdue        bool       `json:"overdue"`
+	}
+
 	Vendor struct {
 		UUID    `json:"id"`
 		Name    string `json:"name"`
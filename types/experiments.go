@@ -0,0 +1,192 @@
+package types
+
+import (
+	"math"
+)
+
+// NewExperimentReport compares every arm of e to its control arm:
+//   - yield only counts lifecycles that yielded something, the ones that
+//     didn't are what contamination is for
+//   - colonization only counts lifecycles that finished colonizing
+//   - contamination counts lifecycles that are contaminated or dead, see
+//     NewStatus, out of every lifecycle in the arm
+//
+// the arms in the report don't carry their lifecycles
+func NewExperimentReport(e Experiment) ExperimentReport {
+	result := ExperimentReport{Arms: make([]ArmSummary, len(e.Arms))}
+
+	type samples struct {
+		yield, colonization []float64
+		contaminated        int
+	}
+
+	all := make([]samples, len(e.Arms))
+	control := -1
+	for i, a := range e.Arms {
+		if a.Control && control < 0 {
+			control = i
+		}
+		for _, lc := range a.Lifecycles {
+			if result.Units == "" {
+				result.Units = lc.Units
+			}
+			if lc.Yield > 0 {
+				all[i].yield = append(all[i].yield, float64(lc.Yield))
+			}
+			if d, ok := TimeIn(NewStageSpans(lc.Events), ColonizationStage); ok {
+				all[i].colonization = append(all[i].colonization, d.Hours()/24)
+			}
+			switch NewStatus(lc.Events) {
+			case ContaminatedStatus, DeadStatus:
+				all[i].contaminated++
+			}
+		}
+	}
+
+	for i, a := range e.Arms {
+		s := &result.Arms[i]
+		s.Arm = ExperimentArm{UUID: a.UUID, Name: a.Name, Control: a.Control}
+		s.Yield = newSampleSummary(all[i].yield)
+		s.Colonization = newSampleSummary(all[i].colonization)
+		s.Contamination = newProportionSummary(all[i].contaminated, len(a.Lifecycles))
+	}
+
+	// the control arm's summary has to be done before anything's compared to it
+	for i := range e.Arms {
+		if control < 0 || i == control {
+			continue
+		}
+		s := &result.Arms[i]
+		s.Yield.P = welch(all[control].yield, all[i].yield)
+		s.Colonization.P = welch(all[control].colonization, all[i].colonization)
+		s.Contamination.P = twoProportions(result.Arms[control].Contamination, s.Contamination)
+	}
+
+	e.Arms = nil
+	result.Experiment = e
+
+	return result
+}
+
+func newSampleSummary(xs []float64) SampleSummary {
+	m, v := meanVariance(xs)
+	return SampleSummary{N: len(xs), Mean: m, StdDev: math.Sqrt(v)}
+}
+
+func newProportionSummary(count, n int) ProportionSummary {
+	result := ProportionSummary{N: n, Count: count}
+	if n > 0 {
+		result.Rate = float64(count) / float64(n)
+	}
+	return result
+}
+
+// meanVariance uses the sample variance, which is zero with fewer than 2
+// samples
+func meanVariance(xs []float64) (mean, variance float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(xs)-1)
+}
+
+// welch is nil without at least 2 samples on each side, or when neither side
+// varies at all
+func welch(a, b []float64) *float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return nil
+	}
+
+	ma, va := meanVariance(a)
+	mb, vb := meanVariance(b)
+	sa, sb := va/float64(len(a)), vb/float64(len(b))
+	if sa+sb == 0 {
+		return nil
+	}
+
+	t := (ma - mb) / math.Sqrt(sa+sb)
+	df := (sa + sb) * (sa + sb) / (sa*sa/float64(len(a)-1) + sb*sb/float64(len(b)-1))
+	p := regularizedBeta(df/2, 0.5, df/(df+t*t))
+
+	return &p
+}
+
+// twoProportions pools both rates for the standard error, so it's nil when
+// either side is empty or nothing (or everything) was contaminated
+func twoProportions(a, b ProportionSummary) *float64 {
+	if a.N == 0 || b.N == 0 {
+		return nil
+	}
+
+	pooled := float64(a.Count+b.Count) / float64(a.N+b.N)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(a.N) + 1/float64(b.N)))
+	if se == 0 {
+		return nil
+	}
+
+	p := math.Erfc(math.Abs(a.Rate-b.Rate) / se / math.Sqrt2)
+
+	return &p
+}
+
+// regularizedBeta is I_x(a, b), by way of the continued fraction in
+// Numerical Recipes (betai/betacf)
+func regularizedBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	} else if x >= 1 {
+		return 1
+	}
+
+	lab, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+func betaFraction(a, b, x float64) float64 {
+	const eps, tiny = 1e-14, 1e-300
+
+	clamp := func(f float64) float64 {
+		if math.Abs(f) < tiny {
+			return tiny
+		}
+		return f
+	}
+
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1.0; m <= 300; m++ {
+		aa := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 / clamp(1+aa*d)
+		c = clamp(1 + aa/c)
+		h *= d * c
+
+		aa = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 / clamp(1+aa*d)
+		c = clamp(1 + aa/c)
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,182 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Welch(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		a, b []float64
+		p    *float64
+	}{
+		"too_few": {
+			a: []float64{1},
+			b: []float64{1, 2, 3},
+		},
+		"no_variance": {
+			a: []float64{2, 2, 2},
+			b: []float64{2, 2},
+		},
+		"same": {
+			a: []float64{1, 2, 3, 4, 5},
+			b: []float64{1, 2, 3, 4, 5},
+			p: f64(1),
+		},
+		// t = -1.8974, df = 5.8824
+		"different": {
+			a: []float64{1, 2, 3, 4, 5},
+			b: []float64{2, 4, 6, 8, 10},
+			p: f64(0.1075),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			p := welch(tc.a, tc.b)
+			if tc.p == nil {
+				require.Nil(t, p)
+				return
+			}
+			require.NotNil(t, p)
+			require.InDelta(t, *tc.p, *p, 0.0005)
+		})
+	}
+}
+
+func Test_RegularizedBeta(t *testing.T) {
+	t.Parallel()
+
+	// two-sided t-test p-values from a t table: I_{df/(df+t^2)}(df/2, 1/2)
+	tcs := map[string]struct {
+		t, df, p float64
+	}{
+		"df_1":  {t: 12.706, df: 1, p: 0.05},
+		"df_10": {t: 2.228, df: 10, p: 0.05},
+		"df_30": {t: 2.750, df: 30, p: 0.01},
+		"zero":  {t: 0, df: 5, p: 1},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.InDelta(t, tc.p, regularizedBeta(tc.df/2, 0.5, tc.df/(tc.df+tc.t*tc.t)), 0.0005)
+		})
+	}
+}
+
+func Test_TwoProportions(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		a, b ProportionSummary
+		p    *float64
+	}{
+		"empty": {
+			a: newProportionSummary(0, 0),
+			b: newProportionSummary(1, 10),
+		},
+		"nothing_contaminated": {
+			a: newProportionSummary(0, 10),
+			b: newProportionSummary(0, 10),
+		},
+		// z = 1.9803
+		"different": {
+			a: newProportionSummary(10, 100),
+			b: newProportionSummary(20, 100),
+			p: f64(0.0477),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			p := twoProportions(tc.a, tc.b)
+			if tc.p == nil {
+				require.Nil(t, p)
+				return
+			}
+			require.NotNil(t, p)
+			require.InDelta(t, *tc.p, *p, 0.0005)
+		})
+	}
+}
+
+func Test_NewExperimentReport(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	colonization := Stage{UUID: "1", Name: ColonizationStage}
+	majority := Stage{UUID: "2", Name: "Majority"}
+	lc := func(yield float32, days int, severity string) Lifecycle {
+		events := []Event{{
+			EventType:  EventType{Name: "Innoculation", Severity: BeginSeverity, Stage: colonization},
+			OccurredAt: start,
+		}}
+		if days > 0 {
+			events = append(events, Event{
+				EventType:  EventType{Name: "Binning", Severity: BeginSeverity, Stage: majority},
+				OccurredAt: start.AddDate(0, 0, days),
+			})
+		}
+		if stage := majority; severity != "" {
+			if days == 0 {
+				stage = colonization
+			}
+			events = append(events, Event{
+				EventType:  EventType{Name: "Mold", Severity: severity, Stage: stage},
+				OccurredAt: start.AddDate(0, 0, days+1),
+			})
+		}
+		return Lifecycle{Yield: yield, Events: events}
+	}
+
+	e := Experiment{UUID: "0", Name: "rye vs millet", Variable: "grain", Arms: []ExperimentArm{
+		{UUID: "treatment", Name: "millet", Lifecycles: []Lifecycle{
+			lc(120, 12, ""),
+			lc(140, 14, ""),
+			lc(0, 0, FatalSeverity),
+		}},
+		{UUID: "control", Name: "rye", Control: true, Lifecycles: []Lifecycle{
+			lc(100, 10, ""),
+			lc(110, 12, ""),
+			lc(0, 11, ErrorSeverity),
+		}},
+	}}
+
+	result := NewExperimentReport(e)
+
+	require.Equal(t, Experiment{UUID: "0", Name: "rye vs millet", Variable: "grain"}, result.Experiment)
+	require.Len(t, result.Arms, 2)
+
+	treatment, control := result.Arms[0], result.Arms[1]
+	require.Equal(t, ExperimentArm{UUID: "control", Name: "rye", Control: true}, control.Arm)
+	require.Equal(t, ExperimentArm{UUID: "treatment", Name: "millet"}, treatment.Arm)
+
+	require.Nil(t, control.Yield.P)
+	require.Nil(t, control.Colonization.P)
+	require.Nil(t, control.Contamination.P)
+
+	require.Equal(t, 2, treatment.Yield.N)
+	require.Equal(t, float64(130), treatment.Yield.Mean)
+	require.NotNil(t, treatment.Yield.P)
+
+	require.Equal(t, 2, treatment.Colonization.N)
+	require.Equal(t, float64(13), treatment.Colonization.Mean)
+	require.Equal(t, 3, control.Colonization.N)
+	require.Equal(t, float64(11), control.Colonization.Mean)
+	require.NotNil(t, treatment.Colonization.P)
+
+	require.Equal(t, ProportionSummary{N: 3, Count: 1, Rate: 1.0 / 3, P: treatment.Contamination.P}, treatment.Contamination)
+	require.InDelta(t, 1, *treatment.Contamination.P, 0.0005)
+}
+
+func f64(f float64) *float64 { return &f }
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const cloneOf = "clone of "
+
+// NewFiliation derives a promoted strain's filiation from the sources of the
+// generation it was promoted from. The deepest parent decides everything,
+// the first one found if there's a tie; any source that isn't a clonal type
+// (e.g. spores) makes it the next filial generation, F1 from a vendor
+// original, otherwise it's a clone of whatever the parent was. A generation
+// without sources didn't come from anything this library knows about, so
+// it's an original
+func NewFiliation(sources []FilialSource) Filiation {
+	if len(sources) == 0 {
+		return Filiation{Notation: OriginalNotation}
+	}
+
+	parent, spore := sources[0], false
+	for _, s := range sources {
+		if s.Depth > parent.Depth {
+			parent = s
+		}
+		spore = spore || !s.Clonal
+	}
+
+	result := Filiation{Depth: parent.Depth + 1, Root: parent.Root}
+	if result.Root == nil {
+		result.Root = &parent.Parent
+	}
+
+	if spore {
+		result.Notation = fmt.Sprintf("F%d", parent.Filial()+1)
+	} else {
+		result.Notation = cloneOf + parent.Notation
+	}
+
+	return result
+}
+
+// Filial is the number of spore generations since the vendor original, the
+// n in Fn, no matter how many times it's been cloned since
+func (f Filiation) Filial() int {
+	var result int
+	_, _ = fmt.Sscanf(strings.ReplaceAll(f.Notation, cloneOf, ""), "F%d", &result)
+	return result
+}
+
+// WithFilial asks SelectAllStrains to only return strains that match f
+func WithFilial(ctx context.Context, f FilialFilter) context.Context {
+	return context.WithValue(ctx, Filial, f)
+}
+
+func GetContextFilial(ctx context.Context) FilialFilter {
+	result, _ := ctx.Value(Filial).(FilialFilter)
+	return result
+}
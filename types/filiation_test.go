@@ -0,0 +1,73 @@
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFiliation(t *testing.T) {
+	t.Parallel()
+
+	root := UUID("0")
+	tcs := map[string]struct {
+		sources []FilialSource
+		result  Filiation
+	}{
+		"no_sources": {
+			result: Filiation{Notation: OriginalNotation},
+		},
+		"spore_from_an_original": {
+			sources: []FilialSource{{Type: SporeSource, Parent: "0", Filiation: Filiation{Notation: OriginalNotation}}},
+			result:  Filiation{Depth: 1, Notation: "F1", Root: &root},
+		},
+		"clone_of_an_original": {
+			sources: []FilialSource{{Type: CloneSource, Clonal: true, Parent: "0", Filiation: Filiation{Notation: OriginalNotation}}},
+			result:  Filiation{Depth: 1, Notation: "clone of P", Root: &root},
+		},
+		"clone_of_clone": {
+			sources: []FilialSource{{Type: CloneSource, Clonal: true, Parent: "2", Filiation: Filiation{Depth: 2, Notation: "clone of F1", Root: &root}}},
+			result:  Filiation{Depth: 3, Notation: "clone of clone of F1", Root: &root},
+		},
+		"spore_from_a_clone": {
+			sources: []FilialSource{{Type: SporeSource, Parent: "2", Filiation: Filiation{Depth: 2, Notation: "clone of F1", Root: &root}}},
+			result:  Filiation{Depth: 3, Notation: "F2", Root: &root},
+		},
+		"deepest_parent_wins": {
+			sources: []FilialSource{
+				{Type: CloneSource, Clonal: true, Parent: "1", Filiation: Filiation{Notation: OriginalNotation}},
+				{Type: SporeSource, Parent: "2", Filiation: Filiation{Depth: 2, Notation: "F2", Root: &root}},
+			},
+			result: Filiation{Depth: 3, Notation: "F3", Root: &root},
+		},
+		"clonal_transfer": {
+			sources: []FilialSource{{Type: "Tissue", Clonal: true, Parent: "2", Filiation: Filiation{Depth: 2, Notation: "F2", Root: &root}}},
+			result:  Filiation{Depth: 3, Notation: "clone of F2", Root: &root},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewFiliation(tc.sources))
+		})
+	}
+}
+
+func Test_Filial(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 0, Filiation{Notation: OriginalNotation}.Filial())
+	require.Equal(t, 0, Filiation{Notation: "clone of P"}.Filial())
+	require.Equal(t, 12, Filiation{Notation: "clone of clone of F12"}.Filial())
+}
+
+func Test_GetContextFilial(t *testing.T) {
+	t.Parallel()
+
+	depth := 2
+	require.Equal(t, FilialFilter{}, GetContextFilial(context.Background()))
+	require.Equal(t, FilialFilter{Depth: &depth}, GetContextFilial(WithFilial(context.Background(), FilialFilter{Depth: &depth})))
+}
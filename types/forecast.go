@@ -0,0 +1,182 @@
+package types
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	milestones struct {
+		inoculated                  bool
+		anchor                      time.Time
+		colonized, pinning, harvest *time.Time
+	}
+
+	// forecastBasis narrows history down to lifecycles that have the named
+	// dimensions in common with the one being forecast
+	forecastBasis struct {
+		dims  []Dimension
+		match func(lc, other Lifecycle) bool
+	}
+)
+
+const (
+	// ForecastConfidence is the coverage of every Estimate's Low/High
+	ForecastConfidence = 0.9
+
+	// MinForecastSamples is how many comparable lifecycles it takes before a
+	// narrower basis is preferred over a broader one
+	MinForecastSamples = 3
+)
+
+// narrowest first; strain is implied since that's how history was selected
+var forecastBases = []forecastBasis{
+	{
+		dims: []Dimension{StrainDimension, GrainDimension, BulkDimension, LocationDimension},
+		match: func(lc, other Lifecycle) bool {
+			return lc.GrainSubstrate.UUID == other.GrainSubstrate.UUID &&
+				lc.BulkSubstrate.UUID == other.BulkSubstrate.UUID &&
+				lc.Location.UUID == other.Location.UUID
+		},
+	},
+	{
+		dims: []Dimension{StrainDimension, GrainDimension, BulkDimension},
+		match: func(lc, other Lifecycle) bool {
+			return lc.GrainSubstrate.UUID == other.GrainSubstrate.UUID &&
+				lc.BulkSubstrate.UUID == other.BulkSubstrate.UUID
+		},
+	},
+	{
+		dims: []Dimension{StrainDimension, GrainDimension},
+		match: func(lc, other Lifecycle) bool {
+			return lc.GrainSubstrate.UUID == other.GrainSubstrate.UUID
+		},
+	},
+	{
+		dims:  []Dimension{StrainDimension},
+		match: func(Lifecycle, Lifecycle) bool { return true },
+	},
+}
+
+// two-sided t quantiles at ForecastConfidence, indexed by degrees of freedom
+var tQuantiles = []float64{
+	math.NaN(), 6.314, 2.920, 2.353, 2.132, 2.015, 1.943, 1.895, 1.860, 1.833,
+	1.812, 1.796, 1.782, 1.771, 1.761, 1.753, 1.746, 1.740, 1.734, 1.729,
+	1.725, 1.721, 1.717, 1.714, 1.711, 1.708, 1.706, 1.703, 1.701, 1.699,
+	1.697,
+}
+
+// NewForecast measures every milestone in history as an offset from the start
+// of colonization and applies it to lc. The narrowest basis with at least
+// MinForecastSamples comparable lifecycles wins; failing that, whichever has
+// the most (the narrowest of those, on a tie). Estimates are rounded to the
+// second, anything finer is false precision
+func NewForecast(lc Lifecycle, history []Lifecycle) Forecast {
+	ms := newMilestones(lc)
+	result := Forecast{Anchor: ms.anchor, Basis: []Dimension{}}
+
+	var samples []milestones
+	for _, b := range forecastBases {
+		var matched []milestones
+		for _, h := range history {
+			if h.UUID == lc.UUID || !b.match(lc, h) {
+				continue
+			} else if hms := newMilestones(h); hms.inoculated {
+				matched = append(matched, hms)
+			}
+		}
+
+		if len(matched) > len(samples) {
+			result.Basis, samples = b.dims, matched
+		}
+		if len(matched) >= MinForecastSamples {
+			break
+		}
+	}
+
+	result.Colonized = newEstimate(ms.anchor, ms.colonized, samples, func(m milestones) *time.Time { return m.colonized })
+	result.Pinning = newEstimate(ms.anchor, ms.pinning, samples, func(m milestones) *time.Time { return m.pinning })
+	result.Harvest = newEstimate(ms.anchor, ms.harvest, samples, func(m milestones) *time.Time { return m.harvest })
+
+	return result
+}
+
+// the anchor is when colonization began, or when the lifecycle was created if
+// it hasn't been inoculated yet
+func newMilestones(lc Lifecycle) milestones {
+	spans := NewStageSpans(lc.Events)
+	result := milestones{anchor: lc.CTime}
+
+	for _, s := range spans {
+		if s.Stage.Name != ColonizationStage {
+			continue
+		} else if !result.inoculated {
+			result.inoculated, result.anchor = true, s.Begin
+		}
+		result.colonized = s.End
+	}
+
+	for _, e := range lc.Events {
+		at := e.OccurredAt
+		switch e.EventType.Name {
+		case PinningEvent:
+			if result.pinning == nil || at.Before(*result.pinning) {
+				result.pinning = &at
+			}
+		case HarvestingEvent:
+			if result.harvest == nil || at.Before(*result.harvest) {
+				result.harvest = &at
+			}
+		}
+	}
+
+	return result
+}
+
+func newEstimate(anchor time.Time, actual *time.Time, samples []milestones, get func(milestones) *time.Time) *Estimate {
+	offsets := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if t := get(s); t != nil {
+			offsets = append(offsets, float64(t.Sub(s.anchor)))
+		}
+	}
+
+	n := len(offsets)
+	if n == 0 {
+		return nil
+	}
+
+	var mean, ss float64
+	for _, o := range offsets {
+		mean += o / float64(n)
+	}
+
+	spread := 0.0
+	if n > 1 {
+		for _, o := range offsets {
+			ss += (o - mean) * (o - mean)
+		}
+		t := 1.645
+		if n-1 < len(tQuantiles) {
+			t = tQuantiles[n-1]
+		}
+		// a prediction interval for one more lifecycle, not the mean's
+		spread = t * math.Sqrt(ss/float64(n-1)) * math.Sqrt(1+1/float64(n))
+	}
+
+	expected := anchor.Add(time.Duration(mean).Round(time.Second))
+	d := time.Duration(spread).Round(time.Second)
+
+	low := expected.Add(-d)
+	if low.Before(anchor) {
+		low = anchor
+	}
+
+	return &Estimate{
+		Samples:  n,
+		Expected: expected,
+		Low:      low,
+		High:     expected.Add(d),
+		Actual:   actual,
+	}
+}
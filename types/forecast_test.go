@@ -0,0 +1,136 @@
+package types
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// a lifecycle inoculated on day `start` that colonizes, pins and gets
+// harvested `col`, `pin` and `harv` days later; zero means it hasn't yet
+func fcLC(id, location UUID, start, col, pin, harv int) Lifecycle {
+	lc := Lifecycle{
+		UUID:           id,
+		Location:       Location{UUID: location},
+		GrainSubstrate: Substrate{UUID: "g0"},
+		BulkSubstrate:  Substrate{UUID: "b0"},
+		CTime:          day(start),
+		Events:         []Event{ev(start, BeginSeverity, _colonization)},
+	}
+	if col > 0 {
+		lc.Events = append(lc.Events, ev(start+col, BeginSeverity, _majority))
+	}
+	if pin > 0 {
+		e := ev(start+pin, InfoSeverity, _majority)
+		e.EventType.Name = PinningEvent
+		lc.Events = append(lc.Events, e)
+	}
+	if harv > 0 {
+		e := ev(start+harv, InfoSeverity, _majority)
+		e.EventType.Name = HarvestingEvent
+		lc.Events = append(lc.Events, e)
+	}
+	return lc
+}
+
+func Test_NewForecast(t *testing.T) {
+	t.Parallel()
+
+	// 3 samples 2 days apart: t(2) * sd * sqrt(1 + 1/n)
+	spread := time.Duration(2.920 * float64(48*time.Hour) * math.Sqrt(1+1.0/3)).Round(time.Second)
+	est := func(anchor time.Time, days int, spread time.Duration, n int) *Estimate {
+		expected := anchor.AddDate(0, 0, days)
+		return &Estimate{Samples: n, Expected: expected, Low: expected.Add(-spread), High: expected.Add(spread)}
+	}
+
+	history := []Lifecycle{
+		fcLC("h0", "shelf 0", -100, 10, 20, 25),
+		fcLC("h1", "shelf 0", -90, 12, 22, 27),
+		fcLC("h2", "shelf 0", -80, 14, 24, 29),
+		fcLC("h3", "shelf 1", -70, 30, 40, 50),
+		{UUID: "never inoculated", Location: Location{UUID: "shelf 0"}, GrainSubstrate: Substrate{UUID: "g0"}, BulkSubstrate: Substrate{UUID: "b0"}},
+	}
+
+	tcs := map[string]struct {
+		lc      Lifecycle
+		history []Lifecycle
+		result  Forecast
+	}{
+		"narrowest_basis": {
+			lc:      fcLC("lc0", "shelf 0", 0, 0, 0, 0),
+			history: history,
+			result: Forecast{
+				Basis:     []Dimension{StrainDimension, GrainDimension, BulkDimension, LocationDimension},
+				Anchor:    day(0),
+				Colonized: est(day(0), 12, spread, 3),
+				Pinning:   est(day(0), 22, spread, 3),
+				Harvest:   est(day(0), 27, spread, 3),
+			},
+		},
+		"actual": {
+			lc:      fcLC("lc0", "shelf 0", 0, 11, 0, 0),
+			history: history,
+			result: Forecast{
+				Basis:  []Dimension{StrainDimension, GrainDimension, BulkDimension, LocationDimension},
+				Anchor: day(0),
+				Colonized: func(e *Estimate) *Estimate {
+					e.Actual = tp(day(11))
+					return e
+				}(est(day(0), 12, spread, 3)),
+				Pinning: est(day(0), 22, spread, 3),
+				Harvest: est(day(0), 27, spread, 3),
+			},
+		},
+		// nothing else has been grown on shelf 2, so location gets dropped
+		"broader_basis": {
+			lc:      fcLC("lc0", "shelf 2", 0, 0, 0, 0),
+			history: history[3:],
+			result: Forecast{
+				Basis:     []Dimension{StrainDimension, GrainDimension, BulkDimension},
+				Anchor:    day(0),
+				Colonized: est(day(0), 30, 0, 1),
+				Pinning:   est(day(0), 40, 0, 1),
+				Harvest:   est(day(0), 50, 0, 1),
+			},
+		},
+		"skips_itself": {
+			lc:      fcLC("h3", "shelf 1", 0, 0, 0, 0),
+			history: history[3:],
+			result:  Forecast{Basis: []Dimension{}, Anchor: day(0)},
+		},
+		"not_inoculated": {
+			lc:     Lifecycle{UUID: "lc0", CTime: day(5)},
+			result: Forecast{Basis: []Dimension{}, Anchor: day(5)},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewForecast(tc.lc, tc.history))
+		})
+	}
+}
+
+func Test_NewForecastBasis(t *testing.T) {
+	t.Parallel()
+
+	history := []Lifecycle{
+		fcLC("h0", "shelf 0", -100, 10, 20, 25),
+		fcLC("h1", "shelf 0", -90, 12, 22, 27),
+		fcLC("h2", "shelf 1", -80, 14, 24, 29),
+		fcLC("h3", "shelf 1", -70, 30, 40, 0),
+	}
+	// neither location has enough history on its own
+	result := NewForecast(fcLC("lc0", "shelf 0", 0, 0, 0, 0), history)
+
+	require.Equal(t, []Dimension{StrainDimension, GrainDimension, BulkDimension}, result.Basis)
+	require.Equal(t, 4, result.Colonized.Samples)
+	require.Equal(t, day(0).Add(396*time.Hour), result.Colonized.Expected) // 16.5 days
+	require.Equal(t, 3, result.Harvest.Samples)
+	require.True(t, result.Colonized.Low.Before(result.Colonized.Expected))
+	require.True(t, result.Colonized.High.After(result.Colonized.Expected))
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// NewFlushes numbers a lifecycle's harvests in date order and works out how
+// each one compares to the rest; start is when the lifecycle began
+func NewFlushes(start time.Time, harvests []Harvest) []Flush {
+	harvests = append([]Harvest{}, harvests...)
+	SortHarvests(harvests)
+
+	var gross float32
+	for _, h := range harvests {
+		gross += h.FreshWeight
+	}
+
+	result := make([]Flush, len(harvests))
+	for i, h := range harvests {
+		result[i] = Flush{
+			Number:     i + 1,
+			Harvest:    h,
+			Interval:   h.Date.Sub(start),
+			Share:      ratio(h.FreshWeight, gross),
+			MeanWeight: ratio(h.FreshWeight, float32(h.Count)),
+		}
+		if h.DryWeight != 0 {
+			// worked out the same way as Costs.Moisture, one flush at a time
+			if r := ratio(h.DryWeight, h.FreshWeight); r != nil {
+				*r = 1 - *r
+				result[i].Moisture = r
+			}
+		}
+		start = h.Date
+	}
+
+	return result
+}
+
+// SortHarvests puts harvests in the order they were picked; ties keep the
+// order they were recorded in
+func SortHarvests(harvests []Harvest) {
+	sort.SliceStable(harvests, func(i, j int) bool {
+		return harvests[i].Date.Before(harvests[j].Date)
+	})
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFlushes(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := Harvest{UUID: "first", Date: start.Add(240 * time.Hour), FreshWeight: 300, DryWeight: 30, Count: 10}
+	second := Harvest{UUID: "second", Date: start.Add(360 * time.Hour), FreshWeight: 100}
+
+	tcs := map[string]struct {
+		harvests []Harvest
+		result   []Flush
+	}{
+		"no_harvests": {
+			result: []Flush{},
+		},
+		"out_of_order": {
+			harvests: []Harvest{second, first},
+			result: []Flush{
+				{
+					Number:     1,
+					Harvest:    first,
+					Interval:   240 * time.Hour,
+					Share:      f32(0.75),
+					Moisture:   f32(0.9),
+					MeanWeight: f32(30),
+				},
+				{
+					Number:   2,
+					Harvest:  second,
+					Interval: 120 * time.Hour,
+					Share:    f32(0.25),
+				},
+			},
+		},
+		"nothing_weighed": {
+			harvests: []Harvest{{UUID: "empty", Date: start}},
+			result: []Flush{
+				{Number: 1, Harvest: Harvest{UUID: "empty", Date: start}},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewFlushes(start, tc.harvests))
+		})
+	}
+}
@@ -16,6 +16,10 @@ const (
 	Cid     ctxkey = "cid"
 	Metrics ctxkey = "metrics"
 	Log     ctxkey = "log"
+	Units   ctxkey = "units"
+	Tags    ctxkey = "tags"
+	Filial  ctxkey = "filial"
+	Attrs   ctxkey = "attributes"
 )
 
 func GetContextCID(ctx context.Context) CID {
@@ -0,0 +1,205 @@
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewLineageID is kind#uuid, the same as the report trees use, since uuids
+// are only unique within their own table
+func NewLineageID(kind LineageKind, id UUID) string {
+	return fmt.Sprintf("%s#%s", kind, id)
+}
+
+// Parents is the IDs of the nodes with an edge into id, in the order the
+// edges were found
+func (l Lineage) Parents(id string) []string {
+	result := []string{}
+	for _, e := range l.Edges {
+		if e.To == id {
+			result = append(result, e.From)
+		}
+	}
+	return result
+}
+
+// Export writes the lineage in a graph language other tools can draw;
+// sources aren't nodes in any of them, they're the label on the edge from
+// whatever they came from to the generation they started
+func (l Lineage) Export(w io.Writer, f LineageFormat) error {
+	var b strings.Builder
+
+	nodes, edges := l.exportable()
+	switch f {
+	case GraphMLFormat:
+		graphML(&b, nodes, edges)
+	case DOTFormat:
+		dot(&b, nodes, edges)
+	case MermaidFormat:
+		mermaid(&b, nodes, edges)
+	default:
+		return fmt.Errorf("unknown lineage format: '%s'", f)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type exportEdge struct {
+	from, to, label string
+}
+
+func (l Lineage) exportable() ([]LineageNode, []exportEdge) {
+	sources := map[string]string{}
+	nodes := make([]LineageNode, 0, len(l.Nodes))
+	for _, n := range l.Nodes {
+		if n.Kind == SourceNode {
+			sources[n.ID] = n.Label
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+
+	edges := make([]exportEdge, 0, len(l.Edges))
+	for _, e := range l.Edges {
+		if _, ok := sources[e.To]; ok {
+			continue
+		} else if label, ok := sources[e.From]; !ok {
+			edges = append(edges, exportEdge{from: e.From, to: e.To})
+		} else {
+			for _, p := range l.Parents(e.From) {
+				edges = append(edges, exportEdge{from: p, to: e.To, label: label})
+			}
+		}
+	}
+
+	return nodes, edges
+}
+
+// caption is what's drawn inside a node, one line per thing that's known
+// about it
+func (n LineageNode) caption() []string {
+	result := []string{n.Label}
+	if n.Kind != StrainNode {
+		result[0] = fmt.Sprintf("%s %s", n.Kind, n.Label)
+	}
+	for _, s := range []string{n.Species, n.Vendor, string(n.Outcome)} {
+		if s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func graphML(b *strings.Builder, nodes []LineageNode, edges []exportEdge) {
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, k := range []string{"kind", "label", "species", "vendor", "outcome"} {
+		fmt.Fprintf(b, "  <key id=%q for=\"node\" attr.name=%q attr.type=\"string\"/>\n", k, k)
+	}
+	b.WriteString(`  <key id="depth" for="node" attr.name="depth" attr.type="int"/>` + "\n")
+	b.WriteString(`  <key id="source" for="edge" attr.name="source" attr.type="string"/>` + "\n")
+	b.WriteString(`  <graph id="lineage" edgedefault="directed">` + "\n")
+
+	for _, n := range nodes {
+		fmt.Fprintf(b, "    <node id=\"%s\">\n", xmlEscape(n.ID))
+		for _, d := range [][2]string{
+			{"kind", string(n.Kind)},
+			{"label", n.Label},
+			{"species", n.Species},
+			{"vendor", n.Vendor},
+			{"outcome", string(n.Outcome)},
+		} {
+			if d[1] != "" {
+				fmt.Fprintf(b, "      <data key=\"%s\">%s</data>\n", d[0], xmlEscape(d[1]))
+			}
+		}
+		fmt.Fprintf(b, "      <data key=\"depth\">%d</data>\n", n.Depth)
+		b.WriteString("    </node>\n")
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(b, "    <edge source=\"%s\" target=\"%s\"", xmlEscape(e.from), xmlEscape(e.to))
+		if e.label == "" {
+			b.WriteString("/>\n")
+			continue
+		}
+		fmt.Fprintf(b, ">\n      <data key=\"source\">%s</data>\n    </edge>\n", xmlEscape(e.label))
+	}
+
+	b.WriteString("  </graph>\n</graphml>\n")
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func dot(b *strings.Builder, nodes []LineageNode, edges []exportEdge) {
+	shapes := map[LineageKind]string{
+		StrainNode:     "box",
+		GenerationNode: "ellipse",
+		LifecycleNode:  "hexagon",
+	}
+
+	b.WriteString("digraph lineage {\n")
+	for _, n := range nodes {
+		fmt.Fprintf(b, "  %s [label=%s, shape=%s];\n",
+			dotQuote(n.ID),
+			dotQuote(n.caption()...),
+			shapes[n.Kind])
+	}
+	for _, e := range edges {
+		fmt.Fprintf(b, "  %s -> %s", dotQuote(e.from), dotQuote(e.to))
+		if e.label != "" {
+			fmt.Fprintf(b, " [label=%s]", dotQuote(e.label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+}
+
+// dotQuote joins lines with a DOT newline, which isn't a real one
+func dotQuote(lines ...string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i, l := range lines {
+		lines[i] = r.Replace(l)
+	}
+	return `"` + strings.Join(lines, `\n`) + `"`
+}
+
+// mermaid ids can't have the # that lineage ids do, so nodes are numbered
+// in the order they're drawn
+func mermaid(b *strings.Builder, nodes []LineageNode, edges []exportEdge) {
+	shapes := map[LineageKind][2]string{
+		StrainNode:     {"[", "]"},
+		GenerationNode: {"([", "])"},
+		LifecycleNode:  {"{{", "}}"},
+	}
+
+	ids := make(map[string]string, len(nodes))
+	b.WriteString("flowchart TD\n")
+	for i, n := range nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		s := shapes[n.Kind]
+		fmt.Fprintf(b, "  %s%s%s%s\n", ids[n.ID], s[0], mermaidQuote(n.caption()...), s[1])
+	}
+	for _, e := range edges {
+		if e.label == "" {
+			fmt.Fprintf(b, "  %s --> %s\n", ids[e.from], ids[e.to])
+		} else {
+			fmt.Fprintf(b, "  %s -->|%s| %s\n", ids[e.from], mermaidQuote(e.label), ids[e.to])
+		}
+	}
+}
+
+func mermaidQuote(lines ...string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	for i, l := range lines {
+		lines[i] = r.Replace(l)
+	}
+	return `"` + strings.Join(lines, "<br/>") + `"`
+}
@@ -0,0 +1,156 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewLineageID(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "strain#0", NewLineageID(StrainNode, "0"))
+	require.Equal(t, "generation#0", NewLineageID(GenerationNode, "0"))
+}
+
+func Test_LineageParents(t *testing.T) {
+	t.Parallel()
+
+	l := Lineage{Edges: []LineageEdge{
+		{From: "source#0", To: "generation#0"},
+		{From: "source#1", To: "generation#0"},
+		{From: "generation#0", To: "strain#1"},
+	}}
+
+	tcs := map[string]struct {
+		id     string
+		result []string
+	}{
+		"two_parents": {id: "generation#0", result: []string{"source#0", "source#1"}},
+		"one_parent":  {id: "strain#1", result: []string{"generation#0"}},
+		"root":        {id: "source#0", result: []string{}},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, l.Parents(tc.id))
+		})
+	}
+}
+
+func Test_LineageExport(t *testing.T) {
+	t.Parallel()
+
+	l := Lineage{
+		Root: "strain#1",
+		Nodes: []LineageNode{
+			{ID: "strain#1", Kind: StrainNode, UUID: "1", Label: `"Golden" <Teacher>`, Species: "P. cubensis", Vendor: "vendor 0"},
+			{ID: "generation#0", Kind: GenerationNode, UUID: "0", Label: "0", Depth: 1, Outcome: HarvestedStatus},
+			{ID: "source#0", Kind: SourceNode, UUID: "0", Label: "Spore", Depth: 1},
+			{ID: "lifecycle#0", Kind: LifecycleNode, UUID: "0", Label: "0", Depth: 1, Outcome: ContaminatedStatus},
+			{ID: "strain#0", Kind: StrainNode, UUID: "0", Label: "parent", Depth: 1},
+		},
+		Edges: []LineageEdge{
+			{From: "strain#0", To: "lifecycle#0"},
+			{From: "lifecycle#0", To: "source#0"},
+			{From: "source#0", To: "generation#0"},
+			{From: "generation#0", To: "strain#1"},
+		},
+	}
+
+	tcs := map[string]struct {
+		format LineageFormat
+		result string
+		err    error
+	}{
+		"graphml": {
+			format: GraphMLFormat,
+			result: `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="kind" for="node" attr.name="kind" attr.type="string"/>
+  <key id="label" for="node" attr.name="label" attr.type="string"/>
+  <key id="species" for="node" attr.name="species" attr.type="string"/>
+  <key id="vendor" for="node" attr.name="vendor" attr.type="string"/>
+  <key id="outcome" for="node" attr.name="outcome" attr.type="string"/>
+  <key id="depth" for="node" attr.name="depth" attr.type="int"/>
+  <key id="source" for="edge" attr.name="source" attr.type="string"/>
+  <graph id="lineage" edgedefault="directed">
+    <node id="strain#1">
+      <data key="kind">strain</data>
+      <data key="label">&#34;Golden&#34; &lt;Teacher&gt;</data>
+      <data key="species">P. cubensis</data>
+      <data key="vendor">vendor 0</data>
+      <data key="depth">0</data>
+    </node>
+    <node id="generation#0">
+      <data key="kind">generation</data>
+      <data key="label">0</data>
+      <data key="outcome">harvested</data>
+      <data key="depth">1</data>
+    </node>
+    <node id="lifecycle#0">
+      <data key="kind">lifecycle</data>
+      <data key="label">0</data>
+      <data key="outcome">contaminated</data>
+      <data key="depth">1</data>
+    </node>
+    <node id="strain#0">
+      <data key="kind">strain</data>
+      <data key="label">parent</data>
+      <data key="depth">1</data>
+    </node>
+    <edge source="strain#0" target="lifecycle#0"/>
+    <edge source="lifecycle#0" target="generation#0">
+      <data key="source">Spore</data>
+    </edge>
+    <edge source="generation#0" target="strain#1"/>
+  </graph>
+</graphml>
+`,
+		},
+		"dot": {
+			format: DOTFormat,
+			result: `digraph lineage {
+  "strain#1" [label="\"Golden\" <Teacher>\nP. cubensis\nvendor 0", shape=box];
+  "generation#0" [label="generation 0\nharvested", shape=ellipse];
+  "lifecycle#0" [label="lifecycle 0\ncontaminated", shape=hexagon];
+  "strain#0" [label="parent", shape=box];
+  "strain#0" -> "lifecycle#0";
+  "lifecycle#0" -> "generation#0" [label="Spore"];
+  "generation#0" -> "strain#1";
+}
+`,
+		},
+		"mermaid": {
+			format: MermaidFormat,
+			result: `flowchart TD
+  n0["#quot;Golden#quot; #lt;Teacher#gt;<br/>P. cubensis<br/>vendor 0"]
+  n1(["generation 0<br/>harvested"])
+  n2{{"lifecycle 0<br/>contaminated"}}
+  n3["parent"]
+  n3 --> n2
+  n2 -->|"Spore"| n1
+  n1 --> n0
+`,
+		},
+		"unknown_format": {
+			format: "svg",
+			err:    fmt.Errorf("unknown lineage format: 'svg'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var b strings.Builder
+			require.Equal(t, tc.err, l.Export(&b, tc.format))
+			require.Equal(t, tc.result, b.String())
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package types
+
+// NewOccupancy keeps the lifecycles that are still taking up room at loc:
+// anything that hasn't had a RIP or a Fatal event, so a lifecycle between
+// flushes counts, and so does one that's contaminated but not thrown out yet
+func NewOccupancy(loc Location, lcs []Lifecycle) Occupancy {
+	result := Occupancy{Location: loc, Lifecycles: []Lifecycle{}}
+
+	for _, lc := range lcs {
+		if occupying(lc.Events) {
+			result.Lifecycles = append(result.Lifecycles, lc)
+		}
+	}
+
+	result.Occupied = len(result.Lifecycles)
+	result.Fill = ratio(float32(result.Occupied), float32(loc.Capacity))
+
+	return result
+}
+
+func occupying(events []Event) bool {
+	for _, e := range events {
+		if e.EventType.Severity == RIPSeverity || e.EventType.Severity == FatalSeverity {
+			return false
+		}
+	}
+	return true
+}
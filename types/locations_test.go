@@ -0,0 +1,65 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewOccupancy(t *testing.T) {
+	t.Parallel()
+
+	event := func(severity, name string) Event {
+		return Event{EventType: EventType{Name: name, Severity: severity}}
+	}
+
+	pending := Lifecycle{UUID: "pending"}
+	flushing := Lifecycle{UUID: "flushing", Events: []Event{
+		event(BeginSeverity, "Inoculated"),
+		event(InfoSeverity, HarvestingEvent),
+	}}
+	moldy := Lifecycle{UUID: "moldy", Events: []Event{event(ErrorSeverity, "Mold")}}
+	dead := Lifecycle{UUID: "dead", Events: []Event{event(FatalSeverity, "Mold")}}
+	retired := Lifecycle{UUID: "retired", Events: []Event{
+		event(InfoSeverity, HarvestingEvent),
+		event(RIPSeverity, "Sunset"),
+	}}
+
+	tcs := map[string]struct {
+		loc    Location
+		lcs    []Lifecycle
+		result Occupancy
+	}{
+		"empty": {
+			loc:    Location{UUID: "b", Capacity: 4},
+			result: Occupancy{Location: Location{UUID: "b", Capacity: 4}, Lifecycles: []Lifecycle{}, Fill: f32(0)},
+		},
+		"mixed": {
+			loc: Location{UUID: "b", Capacity: 4},
+			lcs: []Lifecycle{pending, flushing, moldy, dead, retired},
+			result: Occupancy{
+				Location:   Location{UUID: "b", Capacity: 4},
+				Lifecycles: []Lifecycle{pending, flushing, moldy},
+				Occupied:   3,
+				Fill:       f32(0.75),
+			},
+		},
+		"no_capacity": {
+			loc: Location{UUID: "bed"},
+			lcs: []Lifecycle{pending},
+			result: Occupancy{
+				Location:   Location{UUID: "bed"},
+				Lifecycles: []Lifecycle{pending},
+				Occupied:   1,
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewOccupancy(tc.loc, tc.lcs))
+		})
+	}
+}
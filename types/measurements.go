@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CheckField is for a field that's about to be added or changed
+func CheckField(f Field) error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("a field needs a name")
+	}
+
+	switch f.Type {
+	case NumberField, IntegerField:
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("field '%s' has a min greater than its max", f.Name)
+		}
+	case TextField, BooleanField:
+		if f.Min != nil || f.Max != nil {
+			return fmt.Errorf("only number and integer fields can have bounds: '%s'", f.Name)
+		}
+	default:
+		return fmt.Errorf("unknown field type: '%s'", f.Type)
+	}
+
+	return nil
+}
+
+// NewMeasurements holds ms up to the fields an event type declares, and
+// returns them in the fields' order with every value converted to its field's
+// type and labeled with its unit; numbers and integers come back as float64.
+// A string is parsed for any type, so values can come straight from a command
+// line or a form. Fields that weren't measured are just left out
+func NewMeasurements(fields []Field, ms []Measurement) ([]Measurement, error) {
+	byName := make(map[string]Field, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	values := make(map[string]any, len(ms))
+	for _, m := range ms {
+		f, ok := byName[m.Field]
+		if !ok {
+			return nil, fmt.Errorf("'%s' isn't a field of this event type", m.Field)
+		} else if _, ok = values[m.Field]; ok {
+			return nil, fmt.Errorf("'%s' was measured more than once", m.Field)
+		}
+
+		v, err := f.convert(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		values[m.Field] = v
+	}
+
+	result := make([]Measurement, 0, len(values))
+	for _, f := range fields {
+		if v, ok := values[f.Name]; ok {
+			result = append(result, Measurement{Field: f.Name, Value: v, Unit: f.Unit})
+		}
+	}
+
+	return result, nil
+}
+
+// NewMeasurementStats only fills in the figures, it's up to the caller to say
+// which group they're for; the median interpolates the same way the
+// percentiles in DurationStats do
+func NewMeasurementStats(xs []float64) MeasurementStats {
+	if len(xs) == 0 {
+		return MeasurementStats{}
+	}
+
+	sorted := make([]float64, len(xs))
+	copy(sorted, xs)
+	sort.Float64s(sorted)
+
+	rank := .5 * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(rank)), int(math.Ceil(rank))
+
+	m, v := meanVariance(sorted)
+
+	return MeasurementStats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Median: sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo]),
+		Max:    sorted[len(sorted)-1],
+		Mean:   m,
+		StdDev: math.Sqrt(v),
+	}
+}
+
+// convert turns v into f's type and checks it against f's bounds
+func (f Field) convert(v any) (any, error) {
+	invalid := fmt.Errorf("%s value for '%s' isn't valid: '%v'", f.Type, f.Name, v)
+
+	switch f.Type {
+	case TextField:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case BooleanField:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if result, err := strconv.ParseBool(strings.TrimSpace(b)); err == nil {
+				return result, nil
+			}
+		}
+	case NumberField, IntegerField:
+		x, ok := number(v)
+		if !ok || math.IsNaN(x) || math.IsInf(x, 0) || (f.Type == IntegerField && x != math.Trunc(x)) {
+			return nil, invalid
+		} else if f.Min != nil && x < *f.Min {
+			return nil, fmt.Errorf("'%s' can't be less than %v: '%v'", f.Name, *f.Min, x)
+		} else if f.Max != nil && x > *f.Max {
+			return nil, fmt.Errorf("'%s' can't be more than %v: '%v'", f.Name, *f.Max, x)
+		}
+		return x, nil
+	}
+
+	return nil, invalid
+}
+
+func number(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case string:
+		result, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return result, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,139 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckField(t *testing.T) {
+	t.Parallel()
+
+	lo, hi := 1.0, 14.0
+
+	tcs := map[string]struct {
+		f   Field
+		err error
+	}{
+		"happy_path": {
+			f: Field{Name: "ph", Type: NumberField, Min: &lo, Max: &hi},
+		},
+		"no_name": {
+			f:   Field{Name: " ", Type: NumberField},
+			err: fmt.Errorf("a field needs a name"),
+		},
+		"unknown_type": {
+			f:   Field{Name: "ph", Type: "color"},
+			err: fmt.Errorf("unknown field type: 'color'"),
+		},
+		"min_over_max": {
+			f:   Field{Name: "ph", Type: IntegerField, Min: &hi, Max: &lo},
+			err: fmt.Errorf("field 'ph' has a min greater than its max"),
+		},
+		"bounded_text": {
+			f:   Field{Name: "color", Type: TextField, Max: &hi},
+			err: fmt.Errorf("only number and integer fields can have bounds: 'color'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.err, CheckField(tc.f))
+		})
+	}
+}
+
+func Test_NewMeasurements(t *testing.T) {
+	t.Parallel()
+
+	zero, ten := 0.0, 10.0
+	fields := []Field{
+		{Name: "diameter", Type: NumberField, Unit: "mm", Min: &zero},
+		{Name: "colonies", Type: IntegerField, Max: &ten},
+		{Name: "color", Type: TextField},
+		{Name: "sectored", Type: BooleanField},
+	}
+
+	tcs := map[string]struct {
+		ms     []Measurement
+		result []Measurement
+		err    error
+	}{
+		"happy_path": {
+			ms: []Measurement{
+				{Field: "sectored", Value: "true"},
+				{Field: "color", Value: "white"},
+				{Field: "diameter", Value: 12.5},
+				{Field: "colonies", Value: "3"},
+			},
+			result: []Measurement{
+				{Field: "diameter", Value: 12.5, Unit: "mm"},
+				{Field: "colonies", Value: 3.0},
+				{Field: "color", Value: "white"},
+				{Field: "sectored", Value: true},
+			},
+		},
+		"nothing_measured": {
+			result: []Measurement{},
+		},
+		"unknown_field": {
+			ms:  []Measurement{{Field: "weight", Value: 1}},
+			err: fmt.Errorf("'weight' isn't a field of this event type"),
+		},
+		"measured_twice": {
+			ms:  []Measurement{{Field: "diameter", Value: 1}, {Field: "diameter", Value: 2}},
+			err: fmt.Errorf("'diameter' was measured more than once"),
+		},
+		"not_a_number": {
+			ms:  []Measurement{{Field: "diameter", Value: "big"}},
+			err: fmt.Errorf("number value for 'diameter' isn't valid: 'big'"),
+		},
+		"not_an_integer": {
+			ms:  []Measurement{{Field: "colonies", Value: 2.5}},
+			err: fmt.Errorf("integer value for 'colonies' isn't valid: '2.5'"),
+		},
+		"not_text": {
+			ms:  []Measurement{{Field: "color", Value: 7}},
+			err: fmt.Errorf("text value for 'color' isn't valid: '7'"),
+		},
+		"not_a_boolean": {
+			ms:  []Measurement{{Field: "sectored", Value: "maybe"}},
+			err: fmt.Errorf("boolean value for 'sectored' isn't valid: 'maybe'"),
+		},
+		"too_small": {
+			ms:  []Measurement{{Field: "diameter", Value: -1}},
+			err: fmt.Errorf("'diameter' can't be less than 0: '-1'"),
+		},
+		"too_big": {
+			ms:  []Measurement{{Field: "colonies", Value: int64(11)}},
+			err: fmt.Errorf("'colonies' can't be more than 10: '11'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := NewMeasurements(fields, tc.ms)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_NewMeasurementStats(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, MeasurementStats{}, NewMeasurementStats(nil))
+
+	result := NewMeasurementStats([]float64{4, 1, 3, 2})
+	require.Equal(t, 4, result.Count)
+	require.Equal(t, 1.0, result.Min)
+	require.Equal(t, 2.5, result.Median)
+	require.Equal(t, 4.0, result.Max)
+	require.Equal(t, 2.5, result.Mean)
+	require.InDelta(t, 1.291, result.StdDev, .001)
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strain is the strain o promotes a generation to, before it's added. Its
+// species is o.Species, or else the one every parent has, if they agree
+func (o PromoteOptions) Strain(parents []Strain) (Strain, error) {
+	if strings.TrimSpace(o.Name) == "" {
+		return Strain{}, fmt.Errorf("a promoted strain needs a name")
+	}
+
+	result := Strain{Name: o.Name, Species: o.Species, Vendor: Vendor{UUID: SelfVendor}}
+	if o.Vendor != nil {
+		result.Vendor.UUID = *o.Vendor
+	}
+
+	if result.Species == "" && len(parents) > 0 {
+		result.Species = parents[0].Species
+		for _, p := range parents[1:] {
+			if p.Species != result.Species {
+				result.Species = ""
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// InheritAttributes is every attribute any of parents has, by name, with
+// conflicting values settled by p. Parents are in the order of the
+// generation's sources, which is what InheritFirst goes by
+func InheritAttributes(parents []Strain, p InheritPolicy) ([]StrainAttribute, error) {
+	switch p {
+	case InheritNone:
+		return nil, nil
+	case InheritAgreed, InheritFirst, InheritStrict:
+	default:
+		return nil, fmt.Errorf("unknown inherit policy: '%s'", p)
+	}
+
+	values, conflicts, names := map[string]string{}, map[string]bool{}, []string{}
+	for _, parent := range parents {
+		for _, a := range parent.Attributes {
+			if v, ok := values[a.Name]; !ok {
+				values[a.Name] = a.Value
+				names = append(names, a.Name)
+			} else if v != a.Value && p == InheritStrict {
+				return nil, fmt.Errorf("parents disagree about attribute '%s': '%s' and '%s'", a.Name, v, a.Value)
+			} else if v != a.Value {
+				conflicts[a.Name] = true
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	result := make([]StrainAttribute, 0, len(names))
+	for _, n := range names {
+		if p == InheritAgreed && conflicts[n] {
+			continue
+		}
+		result = append(result, StrainAttribute{Name: n, Value: values[n]})
+	}
+
+	return result, nil
+}
+
+// PromotionNote says where a promoted strain came from, followed by whatever
+// else the caller had to say about it
+func PromotionNote(s Strain, sources []Source, extra string) string {
+	from := make([]string, len(sources))
+	for i, src := range sources {
+		from[i] = fmt.Sprintf("%s of '%s'", src.Type, src.Strain.Name)
+		if src.Lifecycle != nil {
+			from[i] += fmt.Sprintf(" from lifecycle '%s'", src.Lifecycle.UUID)
+		}
+	}
+
+	result := fmt.Sprintf("promoted to strain '%s' without any sources", s.Name)
+	if len(from) > 0 {
+		result = fmt.Sprintf("promoted to strain '%s' from %s", s.Name, strings.Join(from, ", "))
+	}
+
+	if extra = strings.TrimSpace(extra); extra != "" {
+		result += "\n" + extra
+	}
+
+	return result
+}
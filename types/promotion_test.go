@@ -0,0 +1,171 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_cubensis = Strain{
+		UUID:    "0",
+		Name:    "golden teacher",
+		Species: "P.cubensis",
+		Attributes: []StrainAttribute{
+			{Name: "potency", Value: "high"},
+			{Name: "colonization", Value: "fast"},
+		},
+	}
+	_albino = Strain{
+		UUID:    "1",
+		Name:    "albino",
+		Species: "P.cubensis",
+		Attributes: []StrainAttribute{
+			{Name: "color", Value: "white"},
+			{Name: "potency", Value: "medium"},
+		},
+	}
+	_oyster = Strain{UUID: "2", Name: "blue oyster", Species: "P.ostreatus"}
+)
+
+func Test_PromoteOptionsStrain(t *testing.T) {
+	t.Parallel()
+
+	vendor := UUID("vendor 0")
+	tcs := map[string]struct {
+		opts    PromoteOptions
+		parents []Strain
+		result  Strain
+		err     error
+	}{
+		"parents_agree": {
+			opts:    PromoteOptions{Name: "ghost"},
+			parents: []Strain{_cubensis, _albino},
+			result:  Strain{Name: "ghost", Species: "P.cubensis", Vendor: Vendor{UUID: SelfVendor}},
+		},
+		"parents_disagree": {
+			opts:    PromoteOptions{Name: "chimera"},
+			parents: []Strain{_cubensis, _oyster},
+			result:  Strain{Name: "chimera", Vendor: Vendor{UUID: SelfVendor}},
+		},
+		"no_parents": {
+			opts:   PromoteOptions{Name: "orphan"},
+			result: Strain{Name: "orphan", Vendor: Vendor{UUID: SelfVendor}},
+		},
+		"overrides": {
+			opts:    PromoteOptions{Name: "ghost", Species: "P.hybrid", Vendor: &vendor},
+			parents: []Strain{_cubensis},
+			result:  Strain{Name: "ghost", Species: "P.hybrid", Vendor: Vendor{UUID: vendor}},
+		},
+		"no_name": {
+			opts: PromoteOptions{Name: " "},
+			err:  fmt.Errorf("a promoted strain needs a name"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := tc.opts.Strain(tc.parents)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_InheritAttributes(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		parents []Strain
+		policy  InheritPolicy
+		result  []StrainAttribute
+		err     error
+	}{
+		"none": {
+			parents: []Strain{_cubensis, _albino},
+		},
+		"agreed": {
+			parents: []Strain{_cubensis, _albino},
+			policy:  InheritAgreed,
+			result: []StrainAttribute{
+				{Name: "colonization", Value: "fast"},
+				{Name: "color", Value: "white"},
+			},
+		},
+		"first": {
+			parents: []Strain{_albino, _cubensis},
+			policy:  InheritFirst,
+			result: []StrainAttribute{
+				{Name: "colonization", Value: "fast"},
+				{Name: "color", Value: "white"},
+				{Name: "potency", Value: "medium"},
+			},
+		},
+		"strict_without_conflicts": {
+			parents: []Strain{_cubensis, _oyster},
+			policy:  InheritStrict,
+			result: []StrainAttribute{
+				{Name: "colonization", Value: "fast"},
+				{Name: "potency", Value: "high"},
+			},
+		},
+		"strict_with_conflicts": {
+			parents: []Strain{_cubensis, _albino},
+			policy:  InheritStrict,
+			err:     fmt.Errorf("parents disagree about attribute 'potency': 'high' and 'medium'"),
+		},
+		"no_parents": {
+			policy: InheritFirst,
+			result: []StrainAttribute{},
+		},
+		"unknown_policy": {
+			parents: []Strain{_cubensis},
+			policy:  "last",
+			err:     fmt.Errorf("unknown inherit policy: 'last'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := InheritAttributes(tc.parents, tc.policy)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_PromotionNote(t *testing.T) {
+	t.Parallel()
+
+	s := Strain{Name: "ghost"}
+	tcs := map[string]struct {
+		sources []Source
+		extra   string
+		result  string
+	}{
+		"no_sources": {
+			result: "promoted to strain 'ghost' without any sources",
+		},
+		"sources": {
+			sources: []Source{
+				{Type: SporeSource, Strain: _cubensis},
+				{Type: SporeSource, Strain: _albino, Lifecycle: &Lifecycle{UUID: "lc 0"}},
+			},
+			extra:  " best flush of the year\n",
+			result: "promoted to strain 'ghost' from Spore of 'golden teacher', Spore of 'albino' from lifecycle 'lc 0'\nbest flush of the year",
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, PromotionNote(s, tc.sources, tc.extra))
+		})
+	}
+}
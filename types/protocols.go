@@ -0,0 +1,79 @@
+package types
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// NewDeviationReport walks the protocol's steps in order and gives each one the
+// oldest event of its type that an earlier step didn't already claim. Days are
+// counted from the first step's event, less its own offset, or from start if
+// that step hasn't happened yet. A step that hasn't happened is pending until
+// its tolerance runs out on now, and then it's missing. Events left over at the
+// end are unexpected, except for ones in the Any stage, which no protocol is
+// expected to plan for
+func NewDeviationReport(p Protocol, start time.Time, events []Event, now time.Time) DeviationReport {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OccurredAt.Before(sorted[j].OccurredAt)
+	})
+
+	used := make([]bool, len(sorted))
+	matches := make([]int, len(p.Steps))
+	for i, s := range p.Steps {
+		matches[i] = -1
+		for j, e := range sorted {
+			if !used[j] && e.EventType.UUID == s.EventType.UUID {
+				used[j], matches[i] = true, j
+				break
+			}
+		}
+	}
+
+	result := DeviationReport{Protocol: p, Anchor: start, Deviations: []Deviation{}}
+	if len(p.Steps) > 0 && matches[0] >= 0 {
+		result.Anchor = sorted[matches[0]].OccurredAt.AddDate(0, 0, -p.Steps[0].Day)
+	}
+
+	for i := range p.Steps {
+		s := &p.Steps[i]
+		expected := result.Anchor.AddDate(0, 0, s.Day)
+		d := Deviation{Step: s, Expected: &expected}
+		if matches[i] < 0 {
+			if expected.AddDate(0, 0, s.Tolerance).Before(now) {
+				d.Kind = MissingDeviation
+			} else {
+				d.Kind = PendingDeviation
+			}
+			result.Deviations = append(result.Deviations, d)
+			continue
+		}
+
+		e := sorted[matches[i]]
+		days := int(math.Round(e.OccurredAt.Sub(expected).Hours() / 24))
+		d.Event, d.Days = &e, &days
+		if days < -s.Tolerance {
+			d.Kind = EarlyDeviation
+		} else if days > s.Tolerance {
+			d.Kind = LateDeviation
+		} else {
+			d.Kind = OnTimeDeviation
+		}
+		result.Deviations = append(result.Deviations, d)
+	}
+
+	for j := range sorted {
+		if used[j] || sorted[j].EventType.Stage.Name == AnyStage {
+			continue
+		}
+		e := sorted[j]
+		result.Deviations = append(result.Deviations, Deviation{
+			Kind:  UnexpectedDeviation,
+			Event: &e,
+		})
+	}
+
+	return result
+}
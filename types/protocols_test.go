@@ -0,0 +1,85 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewDeviationReport(t *testing.T) {
+	t.Parallel()
+
+	agar := EventType{UUID: "agar", Stage: _colonization}
+	lc := EventType{UUID: "lc", Stage: _colonization}
+	grain := EventType{UUID: "grain", Stage: _majority}
+	note := EventType{UUID: "note", Stage: _any}
+
+	p := Protocol{
+		UUID: "protocol",
+		Steps: []ProtocolStep{
+			{UUID: "0", EventType: agar, Day: 0},
+			{UUID: "1", EventType: lc, Day: 10, Tolerance: 2},
+			{UUID: "2", EventType: grain, Day: 20, Tolerance: 2},
+		},
+	}
+	at := func(n int, et EventType) Event { return Event{UUID: UUID(et.UUID), OccurredAt: day(n), EventType: et} }
+	ip := func(i int) *int { return &i }
+
+	tcs := map[string]struct {
+		start  int
+		now    int
+		events []Event
+		result []Deviation
+		anchor int
+	}{
+		"on_time": {
+			now:    30,
+			events: []Event{at(21, grain), at(11, lc), at(1, agar)},
+			anchor: 1,
+			result: []Deviation{
+				{Kind: OnTimeDeviation, Step: &p.Steps[0], Event: &Event{UUID: "agar", OccurredAt: day(1), EventType: agar}, Expected: tp(day(1)), Days: ip(0)},
+				{Kind: OnTimeDeviation, Step: &p.Steps[1], Event: &Event{UUID: "lc", OccurredAt: day(11), EventType: lc}, Expected: tp(day(11)), Days: ip(0)},
+				{Kind: OnTimeDeviation, Step: &p.Steps[2], Event: &Event{UUID: "grain", OccurredAt: day(21), EventType: grain}, Expected: tp(day(21)), Days: ip(0)},
+			},
+		},
+		"early_late_and_pending": {
+			now:    15,
+			events: []Event{at(0, agar), at(5, lc), at(6, note)},
+			result: []Deviation{
+				{Kind: OnTimeDeviation, Step: &p.Steps[0], Event: &Event{UUID: "agar", OccurredAt: day(0), EventType: agar}, Expected: tp(day(0)), Days: ip(0)},
+				{Kind: EarlyDeviation, Step: &p.Steps[1], Event: &Event{UUID: "lc", OccurredAt: day(5), EventType: lc}, Expected: tp(day(10)), Days: ip(-5)},
+				{Kind: PendingDeviation, Step: &p.Steps[2], Expected: tp(day(20))},
+			},
+		},
+		"late_missing_and_unexpected": {
+			now:    40,
+			events: []Event{at(0, agar), at(14, lc), at(15, lc)},
+			result: []Deviation{
+				{Kind: OnTimeDeviation, Step: &p.Steps[0], Event: &Event{UUID: "agar", OccurredAt: day(0), EventType: agar}, Expected: tp(day(0)), Days: ip(0)},
+				{Kind: LateDeviation, Step: &p.Steps[1], Event: &Event{UUID: "lc", OccurredAt: day(14), EventType: lc}, Expected: tp(day(10)), Days: ip(4)},
+				{Kind: MissingDeviation, Step: &p.Steps[2], Expected: tp(day(20))},
+				{Kind: UnexpectedDeviation, Event: &Event{UUID: "lc", OccurredAt: day(15), EventType: lc}},
+			},
+		},
+		"nothing_yet": {
+			start:  3,
+			now:    3,
+			anchor: 3,
+			result: []Deviation{
+				{Kind: PendingDeviation, Step: &p.Steps[0], Expected: tp(day(3))},
+				{Kind: PendingDeviation, Step: &p.Steps[1], Expected: tp(day(13))},
+				{Kind: PendingDeviation, Step: &p.Steps[2], Expected: tp(day(23))},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result := NewDeviationReport(p, day(tc.start), tc.events, day(tc.now))
+			require.Equal(t, day(tc.anchor), result.Anchor)
+			require.Equal(t, tc.result, result.Deviations)
+		})
+	}
+}
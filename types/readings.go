@@ -0,0 +1,34 @@
+package types
+
+// NewLifetime is the window a lifecycle's readings come from: its ctime, or
+// its first event if that was backdated, until the RIP event that retired it
+func NewLifetime(lc Lifecycle) Window {
+	from := lc.CTime
+	spans := NewStageSpans(lc.Events)
+	if len(spans) == 0 {
+		return Window{From: &from}
+	} else if spans[0].Begin.Before(from) {
+		from = spans[0].Begin
+	}
+	return Window{From: &from, To: spans[len(spans)-1].End}
+}
+
+// NewStageReadings sorts readings into whichever span was open when they were
+// taken, the same way StageAt does; readings outside every span are dropped
+func NewStageReadings(spans []StageSpan, readings []Reading) []StageReadings {
+	result := make([]StageReadings, len(spans))
+	for i, s := range spans {
+		result[i] = StageReadings{StageSpan: s, Readings: []Reading{}}
+	}
+
+	for _, r := range readings {
+		for i, s := range spans {
+			if !r.At.Before(s.Begin) && (s.End == nil || r.At.Before(*s.End)) {
+				result[i].Readings = append(result[i].Readings, r)
+				break
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewLifetime(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		lc     Lifecycle
+		result Window
+	}{
+		"no_events": {
+			lc:     Lifecycle{CTime: day(0)},
+			result: Window{From: tp(day(0))},
+		},
+		"still_going": {
+			lc:     Lifecycle{CTime: day(0), Events: []Event{ev(9, BeginSeverity, _majority), ev(1, BeginSeverity, _colonization)}},
+			result: Window{From: tp(day(0))},
+		},
+		"backdated": {
+			lc:     Lifecycle{CTime: day(2), Events: []Event{ev(1, BeginSeverity, _colonization)}},
+			result: Window{From: tp(day(1))},
+		},
+		"retired": {
+			lc: Lifecycle{CTime: day(0), Events: []Event{
+				{OccurredAt: day(20), EventType: EventType{Severity: RIPSeverity, Stage: _majority}},
+				ev(9, BeginSeverity, _majority),
+				ev(1, BeginSeverity, _colonization),
+			}},
+			result: Window{From: tp(day(0)), To: tp(day(20))},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewLifetime(tc.lc))
+		})
+	}
+}
+
+func Test_NewStageReadings(t *testing.T) {
+	t.Parallel()
+
+	at := func(n int) Reading { return Reading{Sensor: "s", At: day(n)} }
+	spans := []StageSpan{
+		{Stage: _colonization, Begin: day(1), End: tp(day(9))},
+		{Stage: _majority, Begin: day(9)},
+	}
+
+	tcs := map[string]struct {
+		spans    []StageSpan
+		readings []Reading
+		result   []StageReadings
+	}{
+		"no_spans": {
+			readings: []Reading{at(1)},
+			result:   []StageReadings{},
+		},
+		"no_readings": {
+			spans: spans,
+			result: []StageReadings{
+				{StageSpan: spans[0], Readings: []Reading{}},
+				{StageSpan: spans[1], Readings: []Reading{}},
+			},
+		},
+		"happy_path": {
+			spans:    spans,
+			readings: []Reading{at(0), at(1), at(8), at(9), at(30)},
+			result: []StageReadings{
+				{StageSpan: spans[0], Readings: []Reading{at(1), at(8)}},
+				{StageSpan: spans[1], Readings: []Reading{at(9), at(30)}},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewStageReadings(tc.spans, tc.readings))
+		})
+	}
+}
@@ -16,6 +16,9 @@ var validReportAttrs = map[string]struct{}{
 	"bulk-id":       {},
 	"eventtype-id":  {},
 	"vendor-id":     {},
+	"location-id":   {},
+	"batch-id":      {},
+	"experiment-id": {},
 }
 
 type reportAttrs map[string]UUID
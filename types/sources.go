@@ -0,0 +1,44 @@
+package types
+
+import "fmt"
+
+// SourceError is what CheckSource returns for a source that a generation's
+// source policies don't allow
+type SourceError struct {
+	Type   string
+	Reason string
+}
+
+func (e SourceError) Error() string {
+	return fmt.Sprintf("illegal source type '%s': %s", e.Type, e.Reason)
+}
+
+// CheckSource says whether a source of type next can join a generation that
+// already has sources of the types in siblings. Types only mix when both of
+// them are mixable, and a generation can't have more than Max of any one type
+func CheckSource(policies []SourceType, siblings []string, next string) error {
+	byName := make(map[string]SourceType, len(policies))
+	for _, p := range policies {
+		byName[p.Name] = p
+	}
+
+	policy, ok := byName[next]
+	if !ok {
+		return SourceError{Type: next, Reason: "unknown source type"}
+	}
+
+	count := 0
+	for _, s := range siblings {
+		if s == next {
+			count++
+		} else if !policy.Mixable || !byName[s].Mixable {
+			return SourceError{Type: next, Reason: fmt.Sprintf("can't be mixed with '%s'", s)}
+		}
+	}
+
+	if count >= policy.Max {
+		return SourceError{Type: next, Reason: fmt.Sprintf("a generation can only have %d", policy.Max)}
+	}
+
+	return nil
+}
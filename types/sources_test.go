@@ -0,0 +1,86 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _sourceTypes = []SourceType{
+	{Name: SporeSource, Max: 2},
+	{Name: CloneSource, Max: 1, Clonal: true},
+	{Name: "LC", Max: 3, Mixable: true, Clonal: true},
+	{Name: "Agar", Max: 2, Mixable: true, Clonal: true},
+}
+
+func Test_CheckSource(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		siblings []string
+		next     string
+		err      error
+	}{
+		"first_spore": {
+			next: SporeSource,
+		},
+		"second_spore": {
+			siblings: []string{SporeSource},
+			next:     SporeSource,
+		},
+		"third_spore": {
+			siblings: []string{SporeSource, SporeSource},
+			next:     SporeSource,
+			err:      SourceError{Type: SporeSource, Reason: "a generation can only have 2"},
+		},
+		"second_clone": {
+			siblings: []string{CloneSource},
+			next:     CloneSource,
+			err:      SourceError{Type: CloneSource, Reason: "a generation can only have 1"},
+		},
+		"clone_after_spore": {
+			siblings: []string{SporeSource},
+			next:     CloneSource,
+			err:      SourceError{Type: CloneSource, Reason: "can't be mixed with 'Spore'"},
+		},
+		"mixable_after_unmixable": {
+			siblings: []string{SporeSource},
+			next:     "LC",
+			err:      SourceError{Type: "LC", Reason: "can't be mixed with 'Spore'"},
+		},
+		"mixable_after_unknown": {
+			siblings: []string{"Retired"},
+			next:     "LC",
+			err:      SourceError{Type: "LC", Reason: "can't be mixed with 'Retired'"},
+		},
+		"mixables": {
+			siblings: []string{"LC", "Agar", "LC"},
+			next:     "Agar",
+		},
+		"too_many_mixables": {
+			siblings: []string{"LC", "Agar", "Agar"},
+			next:     "Agar",
+			err:      SourceError{Type: "Agar", Reason: "a generation can only have 2"},
+		},
+		"unknown": {
+			next: "Tissue",
+			err:  SourceError{Type: "Tissue", Reason: "unknown source type"},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.err, CheckSource(_sourceTypes, tc.siblings, tc.next))
+		})
+	}
+}
+
+func Test_SourceError(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t,
+		"illegal source type 'Clone': can't be mixed with 'Spore'",
+		SourceError{Type: CloneSource, Reason: "can't be mixed with 'Spore'"}.Error())
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// NewStageSpans walks events oldest first, the first recorded first for a tie,
+// and opens a new span every time the stage changes. Events in the Any stage
+// don't move the timeline, and a RIP event closes whatever span is open
+func NewStageSpans(events []Event) []StageSpan {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].OccurredAt.Equal(sorted[j].OccurredAt) {
+			return sorted[i].OccurredAt.Before(sorted[j].OccurredAt)
+		}
+		return sorted[i].CTime.Before(sorted[j].CTime)
+	})
+
+	result := []StageSpan{}
+	for _, e := range sorted {
+		curr := len(result) - 1
+		if e.EventType.Severity == RIPSeverity {
+			if curr >= 0 && result[curr].End == nil {
+				end := e.OccurredAt
+				result[curr].End = &end
+			}
+			break
+		} else if e.EventType.Stage.Name == AnyStage {
+			continue
+		} else if curr >= 0 && result[curr].Stage.UUID == e.EventType.Stage.UUID {
+			continue
+		} else if curr >= 0 {
+			end := e.OccurredAt
+			result[curr].End = &end
+		}
+		result = append(result, StageSpan{Stage: e.EventType.Stage, Begin: e.OccurredAt})
+	}
+
+	return result
+}
+
+// TimeIn adds up every closed span for the named stage; ok is false if the
+// stage was never reached or hasn't finished yet
+func TimeIn(spans []StageSpan, stage string) (d time.Duration, ok bool) {
+	for _, s := range spans {
+		if s.Stage.Name != stage {
+			continue
+		} else if s.End == nil {
+			return 0, false
+		}
+		d, ok = d+s.End.Sub(s.Begin), true
+	}
+	return d, ok
+}
+
+// StageAt finds the span that was open at t; a span's end belongs to the next span
+func StageAt(spans []StageSpan, t time.Time) (Stage, bool) {
+	for _, s := range spans {
+		if !t.Before(s.Begin) && (s.End == nil || t.Before(*s.End)) {
+			return s.Stage, true
+		}
+	}
+	return Stage{}, false
+}
+
+func NewDurationStats(ds []time.Duration) DurationStats {
+	if len(ds) == 0 {
+		return DurationStats{}
+	}
+
+	sorted := make([]time.Duration, len(ds))
+	copy(sorted, ds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	return DurationStats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		P25:    percentile(sorted, .25),
+		Median: percentile(sorted, .5),
+		P75:    percentile(sorted, .75),
+		P90:    percentile(sorted, .9),
+		Max:    sorted[len(sorted)-1],
+		Mean:   sum / time.Duration(len(sorted)),
+	}
+}
+
+// percentile expects sorted to be sorted and not empty
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := p * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(rank)), int(math.Ceil(rank))
+	frac := rank - float64(lo)
+	return sorted[lo] + time.Duration(frac*float64(sorted[hi]-sorted[lo]))
+}
@@ -0,0 +1,195 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_epoch        = time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	_colonization = Stage{UUID: "1", Name: ColonizationStage}
+	_majority     = Stage{UUID: "2", Name: MajorityStage}
+	_vacation     = Stage{UUID: "3", Name: "Vacation"}
+	_any          = Stage{UUID: "4", Name: AnyStage}
+)
+
+func day(n int) time.Time { return _epoch.AddDate(0, 0, n) }
+
+func tp(t time.Time) *time.Time { return &t }
+
+func ev(n int, sev string, st Stage) Event {
+	return Event{OccurredAt: day(n), EventType: EventType{Severity: sev, Stage: st}}
+}
+
+func Test_NewStageSpans(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		events []Event
+		result []StageSpan
+	}{
+		"happy_path": {
+			// newest first, the way SelectByObservable returns them
+			events: []Event{
+				ev(30, InfoSeverity, _majority),
+				ev(14, BeginSeverity, _majority),
+				ev(7, InfoSeverity, _any),
+				ev(0, BeginSeverity, _colonization),
+			},
+			result: []StageSpan{
+				{Stage: _colonization, Begin: day(0), End: tp(day(14))},
+				{Stage: _majority, Begin: day(14)},
+			},
+		},
+		"vacation_and_back": {
+			events: []Event{
+				ev(0, BeginSeverity, _colonization),
+				ev(5, BeginSeverity, _vacation),
+				ev(10, InfoSeverity, _colonization),
+				ev(12, BeginSeverity, _majority),
+			},
+			result: []StageSpan{
+				{Stage: _colonization, Begin: day(0), End: tp(day(5))},
+				{Stage: _vacation, Begin: day(5), End: tp(day(10))},
+				{Stage: _colonization, Begin: day(10), End: tp(day(12))},
+				{Stage: _majority, Begin: day(12)},
+			},
+		},
+		"rip": {
+			events: []Event{
+				ev(0, BeginSeverity, _colonization),
+				ev(3, RIPSeverity, _majority),
+				ev(4, InfoSeverity, _majority),
+			},
+			result: []StageSpan{
+				{Stage: _colonization, Begin: day(0), End: tp(day(3))},
+			},
+		},
+		// newest first again, so the two on day 10 have to be put back in the
+		// order they were recorded
+		"same_day": {
+			events: []Event{
+				func(e Event) Event { e.CTime = day(11); return e }(ev(10, InfoSeverity, _colonization)),
+				func(e Event) Event { e.CTime = day(10); return e }(ev(10, BeginSeverity, _vacation)),
+				ev(0, BeginSeverity, _colonization),
+			},
+			result: []StageSpan{
+				{Stage: _colonization, Begin: day(0), End: tp(day(10))},
+				{Stage: _vacation, Begin: day(10), End: tp(day(10))},
+				{Stage: _colonization, Begin: day(10)},
+			},
+		},
+		"only_any": {
+			events: []Event{ev(0, ErrorSeverity, _any)},
+			result: []StageSpan{},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewStageSpans(tc.events))
+		})
+	}
+}
+
+func Test_TimeIn(t *testing.T) {
+	t.Parallel()
+
+	spans := NewStageSpans([]Event{
+		ev(0, BeginSeverity, _colonization),
+		ev(5, BeginSeverity, _vacation),
+		ev(10, InfoSeverity, _colonization),
+		ev(12, BeginSeverity, _majority),
+	})
+
+	tcs := map[string]struct {
+		stage  string
+		result time.Duration
+		ok     bool
+	}{
+		"summed":     {stage: ColonizationStage, result: 7 * 24 * time.Hour, ok: true},
+		"still_open": {stage: MajorityStage},
+		"never":      {stage: "Gestation"},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, ok := TimeIn(spans, tc.stage)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_NewDurationStats(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		ds     []time.Duration
+		result DurationStats
+	}{
+		"happy_path": {
+			ds: []time.Duration{40, 10, 30, 20, 50},
+			result: DurationStats{
+				Count:  5,
+				Min:    10,
+				P25:    20,
+				Median: 30,
+				P75:    40,
+				P90:    46,
+				Max:    50,
+				Mean:   30,
+			},
+		},
+		"one": {
+			ds:     []time.Duration{10},
+			result: DurationStats{Count: 1, Min: 10, P25: 10, Median: 10, P75: 10, P90: 10, Max: 10, Mean: 10},
+		},
+		"none": {},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewDurationStats(tc.ds))
+		})
+	}
+}
+
+func Test_StageAt(t *testing.T) {
+	t.Parallel()
+
+	spans := NewStageSpans([]Event{
+		ev(0, BeginSeverity, _colonization),
+		ev(10, BeginSeverity, _majority),
+		ev(20, RIPSeverity, _majority),
+	})
+
+	tcs := map[string]struct {
+		at     time.Time
+		result Stage
+		ok     bool
+	}{
+		"first":     {at: day(0), result: _colonization, ok: true},
+		"boundary":  {at: day(10), result: _majority, ok: true},
+		"before":    {at: day(-1)},
+		"after_rip": {at: day(20)},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, ok := StageAt(spans, tc.at)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package types
+
+import "fmt"
+
+// TransitionError is what CheckTransition returns for an event that isn't
+// allowed to follow the ones an observable already has
+type TransitionError struct {
+	EventType EventType
+	Reason    string
+}
+
+func (e TransitionError) Error() string {
+	return fmt.Sprintf("illegal transition to '%s': %s", e.EventType.Name, e.Reason)
+}
+
+// NewStatus ranks what's happened to an observable, worst news first. A RIP
+// is the normal end for anything that was harvested, otherwise it's dead, the
+// same as a Fatal event. An Error that wasn't followed by either leaves it
+// contaminated
+func NewStatus(events []Event) Status {
+	if len(events) == 0 {
+		return PendingStatus
+	}
+
+	seen := map[string]bool{}
+	for _, e := range events {
+		seen[e.EventType.Severity] = true
+		if e.EventType.Name == HarvestingEvent {
+			seen[HarvestingEvent] = true
+		}
+	}
+
+	if seen[RIPSeverity] && seen[HarvestingEvent] {
+		return HarvestedStatus
+	} else if seen[RIPSeverity] || seen[FatalSeverity] {
+		return DeadStatus
+	} else if seen[ErrorSeverity] {
+		return ContaminatedStatus
+	} else if seen[HarvestingEvent] {
+		return HarvestedStatus
+	}
+
+	return ActiveStatus
+}
+
+// CheckTransition says whether next can be added to events: nothing follows a
+// RIP, and a stage only begins once
+func CheckTransition(events []Event, next EventType) error {
+	for _, e := range events {
+		if e.EventType.Severity == RIPSeverity {
+			return TransitionError{EventType: next, Reason: fmt.Sprintf("'%s' already ended it", e.EventType.Name)}
+		} else if next.Severity == BeginSeverity &&
+			e.EventType.Severity == BeginSeverity &&
+			e.EventType.Stage.UUID == next.Stage.UUID {
+			return TransitionError{EventType: next, Reason: fmt.Sprintf("stage '%s' already began", next.Stage.Name)}
+		}
+	}
+	return nil
+}
+
+// Matches is true when statuses is empty, so an unfiltered index returns everything
+func (s Status) Matches(statuses []Status) bool {
+	for _, want := range statuses {
+		if s == want {
+			return true
+		}
+	}
+	return len(statuses) == 0
+}
+
+// ParseTransitionPolicy is for flags, environment variables and the like; an
+// empty string is the canonical StrictTransitions
+func ParseTransitionPolicy(s string) (TransitionPolicy, error) {
+	switch TransitionPolicy(s) {
+	case "", StrictTransitions:
+		return StrictTransitions, nil
+	case LenientTransitions:
+		return LenientTransitions, nil
+	case IgnoreTransitions:
+		return IgnoreTransitions, nil
+	}
+	return "", fmt.Errorf("unknown transition policy: '%s'", s)
+}
@@ -0,0 +1,159 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_harvest = Event{EventType: EventType{Name: HarvestingEvent, Severity: InfoSeverity, Stage: _majority}}
+	_sunset  = Event{EventType: EventType{Name: "Sunset", Severity: RIPSeverity, Stage: _majority}}
+)
+
+func Test_NewStatus(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		events []Event
+		result Status
+	}{
+		"no_events": {
+			result: PendingStatus,
+		},
+		"active": {
+			events: []Event{ev(7, InfoSeverity, _any), ev(0, BeginSeverity, _colonization)},
+			result: ActiveStatus,
+		},
+		"contaminated": {
+			events: []Event{ev(7, ErrorSeverity, _any), ev(0, BeginSeverity, _colonization)},
+			result: ContaminatedStatus,
+		},
+		"fatal": {
+			events: []Event{ev(7, FatalSeverity, _any), ev(0, BeginSeverity, _colonization)},
+			result: DeadStatus,
+		},
+		"rip": {
+			events: []Event{_sunset, ev(0, BeginSeverity, _colonization)},
+			result: DeadStatus,
+		},
+		"harvested_then_contaminated": {
+			events: []Event{_harvest, ev(7, ErrorSeverity, _any), ev(0, BeginSeverity, _colonization)},
+			result: ContaminatedStatus,
+		},
+		"harvested": {
+			events: []Event{_harvest, ev(0, BeginSeverity, _colonization)},
+			result: HarvestedStatus,
+		},
+		"harvested_and_retired": {
+			events: []Event{_sunset, _harvest, ev(7, ErrorSeverity, _any)},
+			result: HarvestedStatus,
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, NewStatus(tc.events))
+		})
+	}
+}
+
+func Test_CheckTransition(t *testing.T) {
+	t.Parallel()
+
+	begin := EventType{Name: "Binning", Severity: BeginSeverity, Stage: _majority}
+
+	tcs := map[string]struct {
+		events []Event
+		next   EventType
+		err    error
+	}{
+		"first_event": {
+			next: begin,
+		},
+		"next_stage": {
+			events: []Event{ev(0, BeginSeverity, _colonization)},
+			next:   begin,
+		},
+		"info_after_begin": {
+			events: []Event{ev(0, BeginSeverity, _majority)},
+			next:   _harvest.EventType,
+		},
+		"after_rip": {
+			events: []Event{_sunset, _harvest},
+			next:   _harvest.EventType,
+			err:    TransitionError{EventType: _harvest.EventType, Reason: "'Sunset' already ended it"},
+		},
+		"second_begin": {
+			events: []Event{ev(0, BeginSeverity, _majority)},
+			next:   begin,
+			err:    TransitionError{EventType: begin, Reason: "stage 'Majority' already began"},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.err, CheckTransition(tc.events, tc.next))
+		})
+	}
+}
+
+func Test_TransitionError(t *testing.T) {
+	t.Parallel()
+
+	err := TransitionError{EventType: EventType{Name: "Pinning"}, Reason: "'Sunset' already ended it"}
+	require.Equal(t, "illegal transition to 'Pinning': 'Sunset' already ended it", err.Error())
+}
+
+func Test_Matches(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, ActiveStatus.Matches(nil))
+	require.True(t, ActiveStatus.Matches([]Status{DeadStatus, ActiveStatus}))
+	require.False(t, ActiveStatus.Matches([]Status{DeadStatus}))
+}
+
+func Test_ParseTransitionPolicy(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		s      string
+		result TransitionPolicy
+		err    error
+	}{
+		"empty": {
+			result: StrictTransitions,
+		},
+		"strict": {
+			s:      "strict",
+			result: StrictTransitions,
+		},
+		"lenient": {
+			s:      "lenient",
+			result: LenientTransitions,
+		},
+		"ignore": {
+			s:      "ignore",
+			result: IgnoreTransitions,
+		},
+		"unknown": {
+			s:   "whatever",
+			err: fmt.Errorf("unknown transition policy: 'whatever'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseTransitionPolicy(tc.s)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxTagLength is as long as the tags column allows
+const MaxTagLength = 128
+
+// NormalizeTag trims and lowercases t, so 'Keeper ' and 'keeper' are the same
+// tag
+func NormalizeTag(t string) (string, error) {
+	result := strings.ToLower(strings.TrimSpace(t))
+	if result == "" {
+		return "", fmt.Errorf("a tag can't be empty")
+	} else if len(result) > MaxTagLength {
+		return "", fmt.Errorf("a tag can't be longer than %d characters: '%s'", MaxTagLength, result)
+	}
+	return result, nil
+}
+
+// WithTags asks the index and select-all methods for taggables to only return
+// the ones that have every one of tags, see Tagger
+func WithTags(ctx context.Context, tags ...string) context.Context {
+	return context.WithValue(ctx, Tags, tags)
+}
+
+// GetContextTags is never nil, and every tag in it is normalized; a tag that
+// can't be normalized can't match anything, so it's kept as-is
+func GetContextTags(ctx context.Context) []string {
+	tags, _ := ctx.Value(Tags).([]string)
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		if n, err := NormalizeTag(t); err == nil {
+			result[i] = n
+		} else {
+			result[i] = t
+		}
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NormalizeTag(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		t      string
+		result string
+		err    error
+	}{
+		"happy_path": {
+			t:      "keeper",
+			result: "keeper",
+		},
+		"trimmed_and_lowercased": {
+			t:      "  Suspect Batch ",
+			result: "suspect batch",
+		},
+		"empty": {
+			t:   " ",
+			err: fmt.Errorf("a tag can't be empty"),
+		},
+		"too_long": {
+			t:   strings.Repeat("x", MaxTagLength+1),
+			err: fmt.Errorf("a tag can't be longer than %d characters: '%s'", MaxTagLength, strings.Repeat("x", MaxTagLength+1)),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := NormalizeTag(tc.t)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_GetContextTags(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{}, GetContextTags(context.Background()))
+	require.Equal(t,
+		[]string{"keeper", "competition"},
+		GetContextTags(WithTags(context.Background(), "Keeper", " competition")))
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+const gramsPerOunce = 28.349523125
+
+// ParseUnitSystem is for flags, headers and the like; an empty string is the
+// canonical MetricUnits
+func ParseUnitSystem(s string) (UnitSystem, error) {
+	switch UnitSystem(s) {
+	case "", MetricUnits:
+		return MetricUnits, nil
+	case ImperialUnits:
+		return ImperialUnits, nil
+	}
+	return "", fmt.Errorf("unknown unit system: '%s'", s)
+}
+
+// WithUnits asks the data layer to accept and return measurements in s for
+// everything done with ctx, instead of the default from Config.Units
+func WithUnits(ctx context.Context, s UnitSystem) context.Context {
+	return context.WithValue(ctx, Units, s)
+}
+
+func GetContextUnits(ctx context.Context) (UnitSystem, bool) {
+	result, ok := ctx.Value(Units).(UnitSystem)
+	return result, ok && result != ""
+}
+
+// Temperature converts celsius to s
+func (s UnitSystem) Temperature(celsius float32) float32 {
+	if s == ImperialUnits {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// Celsius converts a temperature in s back to celsius
+func (s UnitSystem) Celsius(t float32) float32 {
+	if s == ImperialUnits {
+		return (t - 32) * 5 / 9
+	}
+	return t
+}
+
+// Weight converts grams to s
+func (s UnitSystem) Weight(grams float32) float32 {
+	if s == ImperialUnits {
+		return grams / gramsPerOunce
+	}
+	return grams
+}
+
+// Grams converts a weight in s back to grams
+func (s UnitSystem) Grams(w float32) float32 {
+	if s == ImperialUnits {
+		return w * gramsPerOunce
+	}
+	return w
+}
+
+// InUnits converts e from whatever it's labeled with (unlabeled is canonical)
+// to s, and labels it; converting to an empty system changes nothing, which
+// also makes it safe to call more than once
+func (e Event) InUnits(s UnitSystem) Event {
+	if s == "" || s == e.Units {
+		return e
+	}
+	e.Temperature = s.Temperature(e.Units.Celsius(e.Temperature))
+	e.Units = s
+	return e
+}
+
+func (h Harvest) InUnits(s UnitSystem) Harvest {
+	if s == "" || s == h.Units {
+		return h
+	}
+	h.FreshWeight = s.Weight(h.Units.Grams(h.FreshWeight))
+	h.DryWeight = s.Weight(h.Units.Grams(h.DryWeight))
+	h.Units = s
+	return h
+}
+
+// InUnits converts the lifecycle's events and harvests along with it; costs
+// aren't measurements, so they're left alone
+func (lc Lifecycle) InUnits(s UnitSystem) Lifecycle {
+	if s == "" {
+		return lc
+	}
+	if s != lc.Units {
+		lc.Yield = s.Weight(lc.Units.Grams(lc.Yield))
+		lc.Gross = s.Weight(lc.Units.Grams(lc.Gross))
+		lc.Units = s
+	}
+	if lc.Events != nil {
+		events := make([]Event, len(lc.Events))
+		for i, e := range lc.Events {
+			events[i] = e.InUnits(s)
+		}
+		lc.Events = events
+	}
+	if lc.Harvests != nil {
+		harvests := make([]Harvest, len(lc.Harvests))
+		for i, h := range lc.Harvests {
+			harvests[i] = h.InUnits(s)
+		}
+		lc.Harvests = harvests
+	}
+	return lc
+}
@@ -0,0 +1,152 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseUnitSystem(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		s      string
+		result UnitSystem
+		err    error
+	}{
+		"empty": {
+			result: MetricUnits,
+		},
+		"metric": {
+			s:      "metric",
+			result: MetricUnits,
+		},
+		"imperial": {
+			s:      "imperial",
+			result: ImperialUnits,
+		},
+		"unknown": {
+			s:   "furlongs",
+			err: fmt.Errorf("unknown unit system: 'furlongs'"),
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseUnitSystem(tc.s)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func Test_GetContextUnits(t *testing.T) {
+	t.Parallel()
+
+	s, ok := GetContextUnits(context.Background())
+	require.False(t, ok)
+	require.Equal(t, UnitSystem(""), s)
+
+	s, ok = GetContextUnits(WithUnits(context.Background(), ImperialUnits))
+	require.True(t, ok)
+	require.Equal(t, ImperialUnits, s)
+}
+
+func Test_EventInUnits(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		e      Event
+		s      UnitSystem
+		result Event
+	}{
+		"no_system": {
+			e:      Event{Temperature: 20},
+			result: Event{Temperature: 20},
+		},
+		"canonical_to_metric": {
+			e:      Event{Temperature: 20},
+			s:      MetricUnits,
+			result: Event{Temperature: 20, Units: MetricUnits},
+		},
+		"canonical_to_imperial": {
+			e:      Event{Temperature: 20},
+			s:      ImperialUnits,
+			result: Event{Temperature: 68, Units: ImperialUnits},
+		},
+		"imperial_to_metric": {
+			e:      Event{Temperature: 212, Units: ImperialUnits},
+			s:      MetricUnits,
+			result: Event{Temperature: 100, Units: MetricUnits},
+		},
+		"imperial_to_imperial": {
+			e:      Event{Temperature: 68, Units: ImperialUnits},
+			s:      ImperialUnits,
+			result: Event{Temperature: 68, Units: ImperialUnits},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, tc.e.InUnits(tc.s))
+		})
+	}
+}
+
+func Test_LifecycleInUnits(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		lc     Lifecycle
+		s      UnitSystem
+		result Lifecycle
+	}{
+		"no_system": {
+			lc:     Lifecycle{Yield: 283.49524},
+			result: Lifecycle{Yield: 283.49524},
+		},
+		"canonical_to_imperial": {
+			lc: Lifecycle{
+				Yield:    283.49524,
+				Gross:    2834.9524,
+				Events:   []Event{{Temperature: 25}},
+				Harvests: []Harvest{{FreshWeight: 283.49524, DryWeight: 28.349524}},
+			},
+			s: ImperialUnits,
+			result: Lifecycle{
+				Yield:    10,
+				Gross:    100,
+				Events:   []Event{{Temperature: 77, Units: ImperialUnits}},
+				Harvests: []Harvest{{FreshWeight: 10, DryWeight: 1, Units: ImperialUnits}},
+				Units:    ImperialUnits,
+			},
+		},
+		"imperial_to_metric": {
+			lc: Lifecycle{
+				Yield:  1,
+				Events: []Event{{Temperature: 32, Units: ImperialUnits}},
+				Units:  ImperialUnits,
+			},
+			s: MetricUnits,
+			result: Lifecycle{
+				Yield:  28.349524,
+				Events: []Event{{Temperature: 0, Units: MetricUnits}},
+				Units:  MetricUnits,
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.result, tc.lc.InUnits(tc.s))
+		})
+	}
+}
@@ -1,8 +1,135 @@
 package types
 
+const (
+	LifecycleParent  ParentType = "lifecycle"
+	GenerationParent ParentType = "generation"
+)
+
 const (
 	PlatingType SubstrateType = "plating"
 	LiquidType  SubstrateType = "liquid"
 	GrainType   SubstrateType = "grain"
 	BulkType    SubstrateType = "bulk"
 )
+
+const (
+	OnTimeDeviation     DeviationKind = "on time"
+	EarlyDeviation      DeviationKind = "early"
+	LateDeviation       DeviationKind = "late"
+	MissingDeviation    DeviationKind = "missing"
+	PendingDeviation    DeviationKind = "pending"
+	UnexpectedDeviation DeviationKind = "unexpected"
+)
+
+const (
+	StrainDimension   Dimension = "strain"
+	VendorDimension   Dimension = "vendor"
+	GrainDimension    Dimension = "grain"
+	BulkDimension     Dimension = "bulk"
+	PlatingDimension  Dimension = "plating"
+	LiquidDimension   Dimension = "liquid"
+	StageDimension    Dimension = "stage"
+	LocationDimension Dimension = "location"
+	DayDimension      Dimension = "day"
+	WeekDimension     Dimension = "week"
+	MonthDimension    Dimension = "month"
+	YearDimension     Dimension = "year"
+)
+
+const (
+	NumberAttribute  AttributeType = "number"
+	EnumAttribute    AttributeType = "enum"
+	BooleanAttribute AttributeType = "boolean"
+	TextAttribute    AttributeType = "text"
+)
+
+const (
+	NumberField  FieldType = "number"
+	IntegerField FieldType = "integer"
+	TextField    FieldType = "text"
+	BooleanField FieldType = "boolean"
+)
+
+const (
+	SporeSource = "Spore"
+	CloneSource = "Clone"
+
+	// OriginalNotation is a strain that didn't come from any other strain
+	OriginalNotation = "P"
+)
+
+const (
+	// SelfVendor is who promoted strains come from unless they say
+	// otherwise; seed.sql adds it to every database
+	SelfVendor UUID = "localhost"
+
+	// InheritNone leaves a promoted strain without attributes, InheritAgreed
+	// leaves out the ones its parents disagree about, InheritFirst takes the
+	// first parent's value and InheritStrict refuses to promote at all
+	InheritNone   InheritPolicy = ""
+	InheritAgreed InheritPolicy = "agreed"
+	InheritFirst  InheritPolicy = "first"
+	InheritStrict InheritPolicy = "strict"
+)
+
+const (
+	StrainNode     LineageKind = "strain"
+	GenerationNode LineageKind = "generation"
+	SourceNode     LineageKind = "source"
+	LifecycleNode  LineageKind = "lifecycle"
+)
+
+const (
+	GraphMLFormat LineageFormat = "graphml"
+	DOTFormat     LineageFormat = "dot"
+	MermaidFormat LineageFormat = "mermaid"
+)
+
+const (
+	IncubatorLocation       LocationKind = "incubator"
+	FruitingChamberLocation LocationKind = "fruiting chamber"
+	OutdoorBedLocation      LocationKind = "outdoor bed"
+)
+
+const (
+	HourResolution Resolution = "hour"
+	DayResolution  Resolution = "day"
+)
+
+const (
+	PendingStatus      Status = "pending"
+	ActiveStatus       Status = "active"
+	ContaminatedStatus Status = "contaminated"
+	HarvestedStatus    Status = "harvested"
+	DeadStatus         Status = "dead"
+)
+
+const (
+	StrictTransitions  TransitionPolicy = "strict"
+	LenientTransitions TransitionPolicy = "lenient"
+	IgnoreTransitions  TransitionPolicy = "ignore"
+)
+
+const (
+	MetricUnits   UnitSystem = "metric"   // Celsius and grams
+	ImperialUnits UnitSystem = "imperial" // Fahrenheit and ounces
+)
+
+// stages, event types and severities the seed data relies on; stages and event
+// types can be renamed, but the analytics look them up by these names
+const (
+	AnyStage          = "Any"
+	ColonizationStage = "Colonization"
+	MajorityStage     = "Majority"
+
+	PinningEvent    = "Pinning"
+	HarvestingEvent = "Harvesting"
+
+	BeginSeverity      = "Begin"
+	InfoSeverity       = "Info"
+	WarnSeverity       = "Warn"
+	ErrorSeverity      = "Error"
+	FatalSeverity      = "Fatal"
+	RIPSeverity        = "RIP"
+	GenerationSeverity = "Generation"
+)